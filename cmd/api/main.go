@@ -1,16 +1,21 @@
 package main
 
 import (
+	"context"
+	"os"
+
 	"github.com/quangdangfit/gocommon/logger"
 	"github.com/quangdangfit/gocommon/validation"
 
-	orderModel "goshop/internal/order/model"
-	productModel "goshop/internal/product/model"
+	cartService "goshop/internal/cart/service"
+	paymentProvider "goshop/internal/payment/provider"
 	grpcServer "goshop/internal/server/grpc"
 	httpServer "goshop/internal/server/http"
-	userModel "goshop/internal/user/model"
+	userService "goshop/internal/user/service"
 	"goshop/pkg/config"
-	"goshop/pkg/dbs"
+	"goshop/pkg/jtoken"
+	"goshop/pkg/mailer"
+	"goshop/pkg/rbac"
 	"goshop/pkg/redis"
 )
 
@@ -31,20 +36,47 @@ import (
 
 //	@BasePath	/api/v1
 
+// mailOutboxSize is the number of emails waiting to be sent before new ones are refused
+const mailOutboxSize = 1000
+
 func main() {
 	cfg := config.LoadConfig()
 	logger.Initialize(cfg.Environment)
 
-	db, err := dbs.NewDatabase(cfg.DatabaseURI)
-	if err != nil {
-		logger.Fatal("Cannot connect to database", err)
+	if len(os.Args) > 1 && os.Args[1] == "migrate" {
+		runMigrate(cfg, os.Args[2:])
+		return
 	}
 
-	err = db.AutoMigrate(&userModel.User{}, &productModel.Product{}, orderModel.Order{}, orderModel.OrderLine{})
-	if err != nil {
+	db := newDatabase(cfg)
+	if _, err := newMigrator(db).Up(context.Background()); err != nil {
 		logger.Fatal("Database migration fail", err)
 	}
 
+	if cfg.RBACPolicy != "" {
+		policy, err := rbac.ParsePolicy(cfg.RBACPolicy)
+		if err != nil {
+			logger.Fatal("Invalid RBAC policy", err)
+		}
+		rbac.SetPolicy(policy)
+	}
+
+	if err := jtoken.LoadKeys(); err != nil {
+		logger.Fatal("Invalid signing keys", err)
+	}
+
+	if _, err := cartService.ParseMergePolicy(cfg.CartMergePolicy); err != nil {
+		logger.Fatal("Invalid cart merge policy", err)
+	}
+
+	if _, err := userService.ParseVerificationPolicy(cfg.EmailVerificationPolicy); err != nil {
+		logger.Fatal("Invalid email verification policy", err)
+	}
+
+	if _, err := paymentProvider.New(cfg.PaymentProvider, cfg.PaymentWebhookSecret); err != nil {
+		logger.Fatal("Invalid payment provider", err)
+	}
+
 	validator := validation.New()
 
 	cache := redis.New(redis.Config{
@@ -53,15 +85,29 @@ func main() {
 		Database: cfg.RedisDB,
 	})
 
+	mail, err := mailer.New(mailer.Config{
+		Provider:     cfg.MailProvider,
+		From:         cfg.MailFrom,
+		Dir:          cfg.MailDir,
+		SMTPHost:     cfg.SMTPHost,
+		SMTPPort:     cfg.SMTPPort,
+		SMTPUsername: cfg.SMTPUsername,
+		SMTPPassword: cfg.SMTPPassword,
+	})
+	if err != nil {
+		logger.Fatal("Invalid mailer", err)
+	}
+	outbox := mailer.NewOutbox(mail, mailOutboxSize)
+
 	go func() {
-		httpSvr := httpServer.NewServer(validator, db, cache)
-		if err = httpSvr.Run(); err != nil {
+		httpSvr := httpServer.NewServer(validator, db, cache, outbox)
+		if err := httpSvr.Run(); err != nil {
 			logger.Fatal(err)
 		}
 	}()
 
-	grpcSvr := grpcServer.NewServer(validator, db, cache)
-	if err = grpcSvr.Run(); err != nil {
+	grpcSvr := grpcServer.NewServer(validator, db, cache, outbox)
+	if err := grpcSvr.Run(); err != nil {
 		logger.Fatal(err)
 	}
 }
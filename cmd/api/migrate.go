@@ -0,0 +1,123 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"text/tabwriter"
+
+	"github.com/quangdangfit/gocommon/logger"
+
+	"goshop/pkg/config"
+	"goshop/pkg/dbs"
+)
+
+const migrateUsage = "usage: goshop migrate up | down [-steps n] | status | create [-dir path] <name>"
+
+// runMigrate handles the "migrate" subcommand
+func runMigrate(cfg *config.Schema, args []string) {
+	if len(args) == 0 {
+		logger.Fatal(migrateUsage)
+	}
+
+	command, args := args[0], args[1:]
+	if command == "create" {
+		flags := flag.NewFlagSet("create", flag.ExitOnError)
+		dir := flags.String("dir", dbs.MigrationsDir, "directory of migration files")
+		_ = flags.Parse(args)
+		if flags.NArg() != 1 {
+			logger.Fatal(migrateUsage)
+		}
+
+		upFile, downFile, err := dbs.CreateMigration(*dir, flags.Arg(0))
+		if err != nil {
+			logger.Fatal("Failed to create migration: ", err)
+		}
+		fmt.Println(upFile)
+		fmt.Println(downFile)
+		return
+	}
+
+	migrator := newMigrator(newDatabase(cfg))
+	ctx := context.Background()
+
+	switch command {
+	case "up":
+		migrations, err := migrator.Up(ctx)
+		if err != nil {
+			logger.Fatal("Failed to migrate up: ", err)
+		}
+		for _, migration := range migrations {
+			fmt.Printf("applied %06d_%s\n", migration.Version, migration.Name)
+		}
+	case "down":
+		flags := flag.NewFlagSet("down", flag.ExitOnError)
+		steps := flags.Int("steps", 1, "number of migrations to roll back")
+		_ = flags.Parse(args)
+
+		migrations, err := migrator.Down(ctx, *steps)
+		if err != nil {
+			logger.Fatal("Failed to migrate down: ", err)
+		}
+		for _, migration := range migrations {
+			fmt.Printf("rolled back %06d_%s\n", migration.Version, migration.Name)
+		}
+	case "status":
+		statuses, err := migrator.Status(ctx)
+		if err != nil {
+			logger.Fatal("Failed to get migration status: ", err)
+		}
+
+		applied := false
+		for _, status := range statuses {
+			applied = applied || status.AppliedAt != nil
+		}
+		if !applied {
+			fmt.Println("No migrations applied")
+		}
+
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "VERSION\tNAME\tAPPLIED AT")
+		for _, status := range statuses {
+			appliedAt := "pending"
+			if status.AppliedAt != nil {
+				appliedAt = status.AppliedAt.Format("2006-01-02 15:04:05")
+			}
+			if status.Missing {
+				appliedAt += " (missing file)"
+			}
+			fmt.Fprintf(w, "%06d\t%s\t%s\n", status.Version, status.Name, appliedAt)
+		}
+		_ = w.Flush()
+	default:
+		logger.Fatal(migrateUsage)
+	}
+}
+
+func newDatabase(cfg *config.Schema) *dbs.Database {
+	db, err := dbs.NewDatabase(dbs.Config{
+		URI:          cfg.DatabaseURI,
+		ReadTimeout:  cfg.DatabaseReadTimeout,
+		WriteTimeout: cfg.DatabaseWriteTimeout,
+	})
+	if err != nil {
+		logger.Fatal("Cannot connect to database", err)
+	}
+
+	return db
+}
+
+func newMigrator(db *dbs.Database) *dbs.Migrator {
+	sqlDB, err := db.GetDB().DB()
+	if err != nil {
+		logger.Fatal("Cannot connect to database", err)
+	}
+
+	migrator, err := dbs.NewMigrator(sqlDB, dbs.Migrations())
+	if err != nil {
+		logger.Fatal("Cannot load migrations", err)
+	}
+
+	return migrator
+}
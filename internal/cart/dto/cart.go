@@ -13,15 +13,30 @@ type CartLine struct {
 
 type CartLineReq struct {
 	ProductID string `json:"product_id" validate:"required"`
+	// VariantID is required to check out products with variants
+	VariantID string `json:"variant_id,omitempty"`
 	Quantity  uint   `json:"quantity" validate:"required"`
 }
 
+// AddProductReq changes the cart of the user, or the cart of the guest when UserID is empty,
+// like RemoveProductReq and UpdateQuantityReq
 type AddProductReq struct {
-	UserID string       `json:"user_id" validate:"required"`
-	Line   *CartLineReq `json:"line"  validate:"required,dive"`
+	UserID     string       `json:"user_id" validate:"required_without=GuestToken"`
+	GuestToken string       `json:"-"`
+	Line       *CartLineReq `json:"line"  validate:"required,dive"`
 }
 
 type RemoveProductReq struct {
-	UserID    string `json:"user_id" validate:"required"`
-	ProductID string `json:"product_id"  validate:"required"`
+	UserID     string `json:"user_id" validate:"required_without=GuestToken"`
+	GuestToken string `json:"-"`
+	ProductID  string `json:"product_id"  validate:"required"`
+	VariantID  string `json:"variant_id,omitempty"`
+}
+
+type UpdateQuantityReq struct {
+	UserID     string `json:"-" validate:"required_without=GuestToken"`
+	GuestToken string `json:"-"`
+	ProductID  string `json:"-" validate:"required"`
+	VariantID  string `json:"-"`
+	Quantity   uint   `json:"quantity" validate:"required"`
 }
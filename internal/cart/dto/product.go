@@ -1,9 +1,27 @@
 package dto
 
+import (
+	"goshop/pkg/money"
+)
+
 type Product struct {
-	ID          string  `json:"id"`
-	Code        string  `json:"code"`
-	Name        string  `json:"name"`
-	Description string  `json:"description"`
-	Price       float64 `json:"price"`
+	ID          string      `json:"id"`
+	Code        string      `json:"code"`
+	Name        string      `json:"name"`
+	Description string      `json:"description"`
+	Price       money.Money `json:"price"`
+	Variants    []*Variant  `json:"variants,omitempty"`
+}
+
+type Variant struct {
+	ID      string           `json:"id"`
+	SKU     string           `json:"sku"`
+	Price   money.Money      `json:"price"`
+	Active  bool             `json:"active"`
+	Options []*VariantOption `json:"options"`
+}
+
+type VariantOption struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
 }
@@ -14,16 +14,39 @@ type Cart struct {
 	DeletedAt *time.Time `json:"deleted_at" gorm:"index"`
 	UserID    string     `json:"user_id" gorm:"unique;not null;index"`
 	User      *User
-	Lines     []*CartLine `json:"lines"`
+	Lines     []*CartLine `json:"lines" gorm:"constraint:OnDelete:CASCADE"`
 }
 
 type CartLine struct {
-	ProductID string `json:"product_id"`
+	ID        string    `json:"id" gorm:"unique;not null;index;primary_key"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+	CartID    string    `json:"cart_id" gorm:"not null;uniqueIndex:idx_cart_line_product"`
+	ProductID string    `json:"product_id" gorm:"not null;uniqueIndex:idx_cart_line_product"`
 	Product   *Product
-	Quantity  uint `json:"quantity"`
+	// VariantID is the variant of the product in the cart, empty for products without variants
+	VariantID string `json:"variant_id,omitempty" gorm:"not null;default:'';uniqueIndex:idx_cart_line_product"`
+	Quantity  uint   `json:"quantity"`
+}
+
+// Line returns the line of the cart with the product and variant, nil when there is none
+func (cart *Cart) Line(productID, variantID string) *CartLine {
+	for _, line := range cart.Lines {
+		if line.ProductID == productID && line.VariantID == variantID {
+			return line
+		}
+	}
+	return nil
 }
 
 func (cart *Cart) BeforeCreate(tx *gorm.DB) error {
 	cart.ID = uuid.New().String()
 	return nil
 }
+
+func (line *CartLine) BeforeCreate(tx *gorm.DB) error {
+	if line.ID == "" {
+		line.ID = uuid.New().String()
+	}
+	return nil
+}
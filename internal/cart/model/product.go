@@ -1,9 +1,82 @@
 package model
 
+import (
+	"gorm.io/gorm"
+
+	"goshop/pkg/money"
+)
+
 type Product struct {
-	ID          string  `json:"id" gorm:"unique;not null;index;primary_key"`
-	Code        string  `json:"code" gorm:"uniqueIndex:idx_product_code,not null"`
-	Name        string  `json:"name" gorm:"uniqueIndex:idx_product_name,not null"`
-	Description string  `json:"description"`
-	Price       float64 `json:"price"`
+	ID          string            `json:"id" gorm:"unique;not null;index;primary_key"`
+	Code        string            `json:"code" gorm:"uniqueIndex:idx_product_code,not null"`
+	Name        string            `json:"name" gorm:"uniqueIndex:idx_product_name,not null"`
+	Description string            `json:"description"`
+	Price       money.Money       `json:"price" gorm:"embedded;embeddedPrefix:price_"`
+	Active      bool              `json:"active" gorm:"default:true"`
+	Stock       *ProductStock     `json:"stock,omitempty"`
+	Variants    []*ProductVariant `json:"variants,omitempty" gorm:"foreignKey:ProductID"`
+}
+
+// ProductVariant is a variant of a product, products with variants are added to carts by variant
+type ProductVariant struct {
+	ID        string `json:"id" gorm:"unique;not null;index;primary_key"`
+	ProductID string `json:"-"`
+	SKU       string `json:"sku" gorm:"column:sku"`
+	// PriceOverride is the price of the variant, the product price is used when it is zero
+	PriceOverride money.Money      `json:"-" gorm:"embedded;embeddedPrefix:price_"`
+	Price         money.Money      `json:"price" gorm:"-"`
+	Active        bool             `json:"active"`
+	Options       []*VariantOption `json:"options" gorm:"foreignKey:VariantID"`
+	Stock         *VariantStock    `json:"-" gorm:"foreignKey:VariantID"`
+}
+
+type VariantOption struct {
+	VariantID string `json:"-" gorm:"primary_key"`
+	Name      string `json:"name" gorm:"primary_key"`
+	Value     string `json:"value"`
+}
+
+// VariantStock is read to tell whether cart lines of variants can be checked out
+type VariantStock struct {
+	VariantID string `gorm:"primary_key"`
+	OnHand    int64
+	Reserved  int64
+}
+
+// AfterFind prices the variants of the product
+func (m *Product) AfterFind(tx *gorm.DB) error {
+	for _, variant := range m.Variants {
+		variant.Price = variant.PriceOverride
+		if variant.PriceOverride.IsZero() {
+			variant.Price = m.Price
+		}
+	}
+	return nil
+}
+
+// Variant returns the variant of the product with id, nil when there is none
+func (m *Product) Variant(id string) *ProductVariant {
+	for _, variant := range m.Variants {
+		if variant.ID == id {
+			return variant
+		}
+	}
+	return nil
+}
+
+// ProductStock is read to tell whether cart lines can be checked out
+type ProductStock struct {
+	ProductID string `json:"-" gorm:"primary_key"`
+	OnHand    int64  `json:"on_hand"`
+	Reserved  int64  `json:"reserved"`
+}
+
+// Available is the stock which can still be ordered
+func (m *ProductStock) Available() int64 {
+	return m.OnHand - m.Reserved
+}
+
+// Available is the stock which can still be ordered
+func (m *VariantStock) Available() int64 {
+	return m.OnHand - m.Reserved
 }
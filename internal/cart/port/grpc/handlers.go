@@ -5,9 +5,15 @@ import (
 	"errors"
 
 	"github.com/quangdangfit/gocommon/logger"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 
 	"goshop/internal/cart/dto"
+	"goshop/internal/cart/model"
 	"goshop/internal/cart/service"
+	orderRepository "goshop/internal/order/repository"
+	orderService "goshop/internal/order/service"
 	"goshop/pkg/utils"
 	pb "goshop/proto/gen/go/cart"
 )
@@ -25,15 +31,17 @@ func NewCartHandler(service service.ICartService) *CartHandler {
 }
 
 func (h *CartHandler) AddProduct(ctx context.Context, req *pb.AddProductReq) (*pb.AddProductRes, error) {
-	userID, _ := ctx.Value("userId").(string)
-	if userID == "" {
+	userID, guestToken := cartOwner(ctx)
+	if userID == "" && guestToken == "" {
 		return nil, errors.New("unauthorized")
 	}
 
 	cart, err := h.service.AddProduct(ctx, &dto.AddProductReq{
-		UserID: userID,
+		UserID:     userID,
+		GuestToken: guestToken,
 		Line: &dto.CartLineReq{
 			ProductID: req.ProductId,
+			VariantID: req.VariantId,
 			Quantity:  uint(req.Quantity),
 		},
 	})
@@ -48,14 +56,16 @@ func (h *CartHandler) AddProduct(ctx context.Context, req *pb.AddProductReq) (*p
 }
 
 func (h *CartHandler) RemoveProduct(ctx context.Context, req *pb.RemoveProductReq) (*pb.RemoveProductRes, error) {
-	userID, _ := ctx.Value("userId").(string)
-	if userID == "" {
+	userID, guestToken := cartOwner(ctx)
+	if userID == "" && guestToken == "" {
 		return nil, errors.New("unauthorized")
 	}
 
 	cart, err := h.service.RemoveProduct(ctx, &dto.RemoveProductReq{
-		UserID:    userID,
-		ProductID: req.ProductId,
+		UserID:     userID,
+		GuestToken: guestToken,
+		ProductID:  req.ProductId,
+		VariantID:  req.VariantId,
 	})
 	if err != nil {
 		logger.Error("Failed to remove product ", err)
@@ -68,12 +78,18 @@ func (h *CartHandler) RemoveProduct(ctx context.Context, req *pb.RemoveProductRe
 }
 
 func (h *CartHandler) GetCart(ctx context.Context, req *pb.GetCartReq) (*pb.GetCartRes, error) {
-	userID, _ := ctx.Value("userId").(string)
-	if userID == "" {
+	userID, guestToken := cartOwner(ctx)
+	if userID == "" && guestToken == "" {
 		return nil, errors.New("unauthorized")
 	}
 
-	cart, err := h.service.GetCartByUserID(ctx, userID)
+	var cart *model.Cart
+	var err error
+	if userID != "" {
+		cart, err = h.service.GetCartByUserID(ctx, userID)
+	} else {
+		cart, err = h.service.GetGuestCart(ctx, guestToken)
+	}
 	if err != nil {
 		logger.Error("Failed to get cart ", err)
 		return nil, err
@@ -83,3 +99,66 @@ func (h *CartHandler) GetCart(ctx context.Context, req *pb.GetCartReq) (*pb.GetC
 	utils.Copy(&res.Cart, &cart)
 	return &res, nil
 }
+
+func (h *CartHandler) Checkout(ctx context.Context, req *pb.CheckoutReq) (*pb.CheckoutRes, error) {
+	userID, _ := ctx.Value("userId").(string)
+	if userID == "" {
+		return nil, errors.New("unauthorized")
+	}
+
+	order, err := h.service.Checkout(ctx, userID)
+	if err != nil {
+		logger.Error("Failed to checkout ", err)
+		return nil, checkoutStatus(err)
+	}
+
+	var res pb.CheckoutRes
+	utils.Copy(&res.Order, &order)
+	return &res, nil
+}
+
+// checkoutStatus converts checkout errors to statuses, listing unavailable lines as violations
+func checkoutStatus(err error) error {
+	var checkoutErr *service.CheckoutError
+	switch {
+	case errors.As(err, &checkoutErr):
+		failure := &errdetails.PreconditionFailure{}
+		for _, line := range checkoutErr.Lines {
+			violation := &errdetails.PreconditionFailure_Violation{
+				Type:        "product",
+				Subject:     line.ProductID,
+				Description: line.Reason,
+			}
+			if line.VariantID != "" {
+				violation.Type = "variant"
+				violation.Subject = line.VariantID
+			}
+			failure.Violations = append(failure.Violations, violation)
+		}
+		st, detailsErr := status.New(codes.FailedPrecondition, err.Error()).WithDetails(failure)
+		if detailsErr != nil {
+			return status.Error(codes.FailedPrecondition, err.Error())
+		}
+		return st.Err()
+	case errors.Is(err, service.ErrEmptyCart),
+		errors.Is(err, service.ErrCartTooLarge),
+		errors.Is(err, orderRepository.ErrInsufficientStock),
+		errors.Is(err, orderRepository.ErrProductInactive),
+		errors.Is(err, orderRepository.ErrVariantRequired),
+		errors.Is(err, orderRepository.ErrVariantNotFound):
+		return status.Error(codes.FailedPrecondition, err.Error())
+	case errors.Is(err, service.ErrCartChanged):
+		return status.Error(codes.Aborted, err.Error())
+	case errors.Is(err, orderService.ErrEmailNotVerified):
+		return status.Error(codes.PermissionDenied, err.Error())
+	}
+
+	return err
+}
+
+// cartOwner returns the user, or for guests the guest token, whose cart is requested
+func cartOwner(ctx context.Context) (userID, guestToken string) {
+	userID, _ = ctx.Value("userId").(string)
+	guestToken, _ = ctx.Value("guestToken").(string)
+	return userID, guestToken
+}
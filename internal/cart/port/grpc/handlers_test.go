@@ -8,11 +8,17 @@ import (
 	"github.com/quangdangfit/gocommon/logger"
 	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/suite"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 
 	"goshop/internal/cart/dto"
 	"goshop/internal/cart/model"
+	"goshop/internal/cart/service"
 	"goshop/internal/cart/service/mocks"
+	orderModel "goshop/internal/order/model"
 	"goshop/pkg/config"
+	"goshop/pkg/money"
 	pb "goshop/proto/gen/go/cart"
 )
 
@@ -212,3 +218,62 @@ func (suite *CartHandlerTestSuite) TestCartAPI_GetCartUnauthorized() {
 	suite.Nil(res)
 	suite.NotNil(err)
 }
+
+// Checkout
+// =================================================================================================
+
+func (suite *CartHandlerTestSuite) TestCartAPI_CheckoutSuccess() {
+	suite.mockService.On("Checkout", mock.Anything, "userID").Return(
+		&orderModel.Order{
+			ID:         "orderID",
+			TotalPrice: money.New(400, "USD"),
+			Status:     orderModel.OrderStatusNew,
+			Lines: []*orderModel.OrderLine{
+				{ProductID: "productId", Quantity: 2, Price: money.New(400, "USD")},
+			},
+		},
+		nil,
+	).Times(1)
+
+	ctx := context.WithValue(context.Background(), "userId", "userID")
+	res, err := suite.handler.Checkout(ctx, &pb.CheckoutReq{})
+	suite.Nil(err)
+	suite.Equal("orderID", res.Order.Id)
+	suite.Equal("new", res.Order.Status)
+	suite.Equal(1, len(res.Order.Lines))
+	suite.Equal("4.00", res.Order.Lines[0].Price.Amount)
+	suite.Equal("USD", res.Order.Lines[0].Price.Currency)
+}
+
+func (suite *CartHandlerTestSuite) TestCartAPI_CheckoutUnavailableLines() {
+	suite.mockService.On("Checkout", mock.Anything, "userID").
+		Return(nil, &service.CheckoutError{Lines: []*service.LineError{
+			{ProductID: "productId", Reason: service.LineReasonInsufficientStock},
+		}}).Times(1)
+
+	ctx := context.WithValue(context.Background(), "userId", "userID")
+	res, err := suite.handler.Checkout(ctx, &pb.CheckoutReq{})
+	suite.Nil(res)
+
+	st := status.Convert(err)
+	suite.Equal(codes.FailedPrecondition, st.Code())
+	suite.Equal(1, len(st.Details()))
+	failure := st.Details()[0].(*errdetails.PreconditionFailure)
+	suite.Equal("productId", failure.Violations[0].Subject)
+	suite.Equal("insufficient stock", failure.Violations[0].Description)
+}
+
+func (suite *CartHandlerTestSuite) TestCartAPI_CheckoutCartChanged() {
+	suite.mockService.On("Checkout", mock.Anything, "userID").Return(nil, service.ErrCartChanged).Times(1)
+
+	ctx := context.WithValue(context.Background(), "userId", "userID")
+	res, err := suite.handler.Checkout(ctx, &pb.CheckoutReq{})
+	suite.Nil(res)
+	suite.Equal(codes.Aborted, status.Code(err))
+}
+
+func (suite *CartHandlerTestSuite) TestCartAPI_CheckoutUnauthorized() {
+	res, err := suite.handler.Checkout(context.Background(), &pb.CheckoutReq{})
+	suite.Nil(res)
+	suite.NotNil(err)
+}
@@ -6,13 +6,28 @@ import (
 
 	"goshop/internal/cart/repository"
 	"goshop/internal/cart/service"
+	orderRepository "goshop/internal/order/repository"
+	orderService "goshop/internal/order/service"
+	promotionRepository "goshop/internal/promotion/repository"
+	promotionService "goshop/internal/promotion/service"
+	userService "goshop/internal/user/service"
+	"goshop/pkg/config"
 	"goshop/pkg/dbs"
+	"goshop/pkg/redis"
 	pb "goshop/proto/gen/go/cart"
 )
 
-func RegisterHandlers(svr *grpc.Server, db dbs.IDatabase, validator validation.Validation) {
+func RegisterHandlers(svr *grpc.Server, db dbs.IDatabase, validator validation.Validation, cache redis.IRedis) {
 	cartRepo := repository.NewCartRepository(db)
-	cartSvc := service.NewCartService(validator, cartRepo)
+	guestRepo := repository.NewGuestCartRepository(cache, config.GetConfig().GuestCartTTL)
+	orderRepo := orderRepository.NewOrderRepository(db)
+	productRepo := orderRepository.NewProductRepository(db)
+	promotionSvc := promotionService.NewPromotionService(validator, promotionRepository.NewPromotionRepository(db))
+	orderSvc := orderService.NewOrderService(validator, db, orderRepo, productRepo, promotionSvc)
+	if userService.VerificationPolicy(config.GetConfig().EmailVerificationPolicy) == userService.VerificationPolicyOrder {
+		orderSvc.RequireVerifiedEmail(orderRepository.NewUserRepository(db))
+	}
+	cartSvc := service.NewCartService(validator, db, cartRepo, guestRepo, orderSvc)
 	cartHandler := NewCartHandler(cartSvc)
 
 	pb.RegisterCartServiceServer(svr, cartHandler)
@@ -7,9 +7,10 @@ import (
 	goGRPC "google.golang.org/grpc"
 
 	"goshop/pkg/dbs/mocks"
+	redisMocks "goshop/pkg/redis/mocks"
 )
 
 func TestRegisterHandlers(t *testing.T) {
 	mockDB := mocks.NewIDatabase(t)
-	RegisterHandlers(goGRPC.NewServer(), mockDB, validation.New())
+	RegisterHandlers(goGRPC.NewServer(), mockDB, validation.New(), redisMocks.NewIRedis(t))
 }
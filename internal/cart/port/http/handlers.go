@@ -0,0 +1,241 @@
+package http
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/quangdangfit/gocommon/logger"
+
+	"goshop/internal/cart/dto"
+	"goshop/internal/cart/model"
+	"goshop/internal/cart/service"
+	orderDto "goshop/internal/order/dto"
+	orderRepository "goshop/internal/order/repository"
+	orderService "goshop/internal/order/service"
+	"goshop/pkg/response"
+	"goshop/pkg/utils"
+)
+
+type CartHandler struct {
+	service service.ICartService
+}
+
+func NewCartHandler(service service.ICartService) *CartHandler {
+	return &CartHandler{
+		service: service,
+	}
+}
+
+// GetCart godoc
+//
+//	@Summary	get my cart
+//	@Tags		cart
+//	@Produce	json
+//	@Security	ApiKeyAuth
+//	@Param		X-Guest-Token	header		string	false	"Guest token, when not logged in"
+//	@Success	200				{object}	dto.Cart
+//	@Router		/api/v1/cart [get]
+func (h *CartHandler) GetCart(c *gin.Context) {
+	userID, guestToken := cartOwner(c)
+	if userID == "" && guestToken == "" {
+		response.Error(c, http.StatusUnauthorized, errors.New("unauthorized"), "Unauthorized")
+		return
+	}
+
+	var cart *model.Cart
+	var err error
+	if userID != "" {
+		cart, err = h.service.GetCartByUserID(c, userID)
+	} else {
+		cart, err = h.service.GetGuestCart(c, guestToken)
+	}
+	if err != nil {
+		logger.Error("Failed to get cart: ", err)
+		response.Error(c, http.StatusInternalServerError, err, "Something went wrong")
+		return
+	}
+
+	var res dto.Cart
+	utils.Copy(&res, &cart)
+	response.JSON(c, http.StatusOK, res)
+}
+
+// AddProduct godoc
+//
+//	@Summary	add product to my cart
+//	@Tags		cart
+//	@Produce	json
+//	@Security	ApiKeyAuth
+//	@Param		X-Guest-Token	header		string			false	"Guest token, when not logged in"
+//	@Param		_				body		dto.CartLineReq	true	"Body"
+//	@Success	200				{object}	dto.Cart
+//	@Router		/api/v1/cart/lines [post]
+func (h *CartHandler) AddProduct(c *gin.Context) {
+	var line dto.CartLineReq
+	if err := c.ShouldBindJSON(&line); err != nil {
+		logger.Error("Failed to get body", err)
+		response.Error(c, http.StatusBadRequest, err, "Invalid parameters")
+		return
+	}
+
+	userID, guestToken := cartOwner(c)
+	if userID == "" && guestToken == "" {
+		response.Error(c, http.StatusUnauthorized, errors.New("unauthorized"), "Unauthorized")
+		return
+	}
+
+	cart, err := h.service.AddProduct(c, &dto.AddProductReq{
+		UserID:     userID,
+		GuestToken: guestToken,
+		Line:       &line,
+	})
+	if err != nil {
+		logger.Error("Failed to add product: ", err)
+		response.Error(c, http.StatusInternalServerError, err, "Something went wrong")
+		return
+	}
+
+	var res dto.Cart
+	utils.Copy(&res, &cart)
+	response.JSON(c, http.StatusOK, res)
+}
+
+// UpdateQuantity godoc
+//
+//	@Summary	set the quantity of a product in my cart
+//	@Tags		cart
+//	@Produce	json
+//	@Security	ApiKeyAuth
+//	@Param		X-Guest-Token	header		string					false	"Guest token, when not logged in"
+//	@Param		product_id		path		string					true	"Product ID"
+//	@Param		variant_id		query		string					false	"Variant ID, for products with variants"
+//	@Param		_				body		dto.UpdateQuantityReq	true	"Body"
+//	@Success	200				{object}	dto.Cart
+//	@Router		/api/v1/cart/lines/{product_id} [put]
+func (h *CartHandler) UpdateQuantity(c *gin.Context) {
+	var req dto.UpdateQuantityReq
+	if err := c.ShouldBindJSON(&req); err != nil {
+		logger.Error("Failed to get body", err)
+		response.Error(c, http.StatusBadRequest, err, "Invalid parameters")
+		return
+	}
+
+	req.UserID, req.GuestToken = cartOwner(c)
+	if req.UserID == "" && req.GuestToken == "" {
+		response.Error(c, http.StatusUnauthorized, errors.New("unauthorized"), "Unauthorized")
+		return
+	}
+
+	req.ProductID = c.Param("product_id")
+	req.VariantID = c.Query("variant_id")
+	if req.ProductID == "" {
+		response.Error(c, http.StatusBadRequest, errors.New("bad request"), "Miss Product ID")
+		return
+	}
+
+	cart, err := h.service.UpdateQuantity(c, &req)
+	if err != nil {
+		logger.Error("Failed to update quantity: ", err)
+		if errors.Is(err, service.ErrProductNotInCart) {
+			response.Error(c, http.StatusNotFound, err, err.Error())
+			return
+		}
+		response.Error(c, http.StatusInternalServerError, err, "Something went wrong")
+		return
+	}
+
+	var res dto.Cart
+	utils.Copy(&res, &cart)
+	response.JSON(c, http.StatusOK, res)
+}
+
+// RemoveProduct godoc
+//
+//	@Summary	remove product from my cart
+//	@Tags		cart
+//	@Produce	json
+//	@Security	ApiKeyAuth
+//	@Param		X-Guest-Token	header		string	false	"Guest token, when not logged in"
+//	@Param		product_id		path		string	true	"Product ID"
+//	@Param		variant_id		query		string	false	"Variant ID, for products with variants"
+//	@Success	200				{object}	dto.Cart
+//	@Router		/api/v1/cart/lines/{product_id} [delete]
+func (h *CartHandler) RemoveProduct(c *gin.Context) {
+	userID, guestToken := cartOwner(c)
+	if userID == "" && guestToken == "" {
+		response.Error(c, http.StatusUnauthorized, errors.New("unauthorized"), "Unauthorized")
+		return
+	}
+
+	productID := c.Param("product_id")
+	if productID == "" {
+		response.Error(c, http.StatusBadRequest, errors.New("bad request"), "Miss Product ID")
+		return
+	}
+
+	cart, err := h.service.RemoveProduct(c, &dto.RemoveProductReq{
+		UserID:     userID,
+		GuestToken: guestToken,
+		ProductID:  productID,
+		VariantID:  c.Query("variant_id"),
+	})
+	if err != nil {
+		logger.Error("Failed to remove product: ", err)
+		response.Error(c, http.StatusInternalServerError, err, "Something went wrong")
+		return
+	}
+
+	var res dto.Cart
+	utils.Copy(&res, &cart)
+	response.JSON(c, http.StatusOK, res)
+}
+
+// Checkout godoc
+//
+//	@Summary	place an order for my cart and empty it
+//	@Tags		cart
+//	@Produce	json
+//	@Security	ApiKeyAuth
+//	@Success	200	{object}	orderDto.Order
+//	@Router		/api/v1/cart/checkout [post]
+func (h *CartHandler) Checkout(c *gin.Context) {
+	userID := c.GetString("userId")
+	if userID == "" {
+		response.Error(c, http.StatusUnauthorized, errors.New("unauthorized"), "Unauthorized")
+		return
+	}
+
+	order, err := h.service.Checkout(c, userID)
+	if err != nil {
+		logger.Error("Failed to checkout: ", err)
+		var checkoutErr *service.CheckoutError
+		switch {
+		case errors.As(err, &checkoutErr):
+			response.ErrorWithDetails(c, http.StatusBadRequest, err, "Some products are no longer available", checkoutErr.Lines)
+		case errors.Is(err, service.ErrEmptyCart),
+			errors.Is(err, service.ErrCartTooLarge),
+			errors.Is(err, orderRepository.ErrInsufficientStock),
+			errors.Is(err, orderRepository.ErrProductInactive),
+			errors.Is(err, orderRepository.ErrVariantRequired),
+			errors.Is(err, orderRepository.ErrVariantNotFound):
+			response.Error(c, http.StatusBadRequest, err, err.Error())
+		case errors.Is(err, service.ErrCartChanged):
+			response.Error(c, http.StatusConflict, err, err.Error())
+		case errors.Is(err, orderService.ErrEmailNotVerified):
+			response.Error(c, http.StatusForbidden, err, "Email not verified")
+		default:
+			response.Error(c, http.StatusInternalServerError, err, "Something went wrong")
+		}
+		return
+	}
+
+	var res orderDto.Order
+	utils.Copy(&res, &order)
+	response.JSON(c, http.StatusOK, res)
+}
+
+// cartOwner returns the user, or for guests the guest token, whose cart is requested
+func cartOwner(c *gin.Context) (userID, guestToken string) {
+	return c.GetString("userId"), c.GetString("guestToken")
+}
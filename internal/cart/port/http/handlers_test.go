@@ -0,0 +1,309 @@
+package http
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/quangdangfit/gocommon/logger"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+
+	"goshop/internal/cart/dto"
+	"goshop/internal/cart/model"
+	"goshop/internal/cart/service"
+	"goshop/internal/cart/service/mocks"
+	orderDto "goshop/internal/order/dto"
+	orderModel "goshop/internal/order/model"
+	"goshop/pkg/config"
+	"goshop/pkg/money"
+)
+
+type CartHandlerTestSuite struct {
+	suite.Suite
+	mockService *mocks.ICartService
+	handler     *CartHandler
+}
+
+func (suite *CartHandlerTestSuite) SetupTest() {
+	logger.Initialize(config.ProductionEnv)
+
+	suite.mockService = mocks.NewICartService(suite.T())
+	suite.handler = NewCartHandler(suite.mockService)
+}
+
+func TestCartHandlerTestSuite(t *testing.T) {
+	suite.Run(t, new(CartHandlerTestSuite))
+}
+
+func (suite *CartHandlerTestSuite) prepareContext(body any) (*gin.Context, *httptest.ResponseRecorder) {
+	requestBody, _ := json.Marshal(body)
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest("", "/", bytes.NewBuffer(requestBody))
+	c, _ := gin.CreateTestContext(w)
+	c.Request = r
+
+	return c, w
+}
+
+func cartWithLine(quantity uint) *model.Cart {
+	return &model.Cart{
+		ID:     "cartId",
+		UserID: "userId",
+		Lines: []*model.CartLine{
+			{
+				ProductID: "productId",
+				Quantity:  quantity,
+			},
+		},
+	}
+}
+
+// GetCart
+// =================================================================================================
+
+func (suite *CartHandlerTestSuite) TestCartAPI_GetCartSuccess() {
+	ctx, writer := suite.prepareContext(nil)
+	ctx.Set("userId", "userId")
+
+	suite.mockService.On("GetCartByUserID", mock.Anything, "userId").
+		Return(cartWithLine(2), nil).Times(1)
+
+	suite.handler.GetCart(ctx)
+
+	var res map[string]dto.Cart
+	_ = json.Unmarshal(writer.Body.Bytes(), &res)
+	suite.Equal(http.StatusOK, writer.Code)
+	suite.Equal("cartId", res["result"].ID)
+	suite.Equal(1, len(res["result"].Lines))
+	suite.Equal(uint(2), res["result"].Lines[0].Quantity)
+}
+
+func (suite *CartHandlerTestSuite) TestCartAPI_GetCartUnauthorized() {
+	ctx, writer := suite.prepareContext(nil)
+
+	suite.handler.GetCart(ctx)
+	suite.Equal(http.StatusUnauthorized, writer.Code)
+}
+
+func (suite *CartHandlerTestSuite) TestCartAPI_GetCartFail() {
+	ctx, writer := suite.prepareContext(nil)
+	ctx.Set("userId", "userId")
+
+	suite.mockService.On("GetCartByUserID", mock.Anything, "userId").
+		Return(nil, errors.New("error")).Times(1)
+
+	suite.handler.GetCart(ctx)
+	suite.Equal(http.StatusInternalServerError, writer.Code)
+}
+
+// AddProduct
+// =================================================================================================
+
+func (suite *CartHandlerTestSuite) TestCartAPI_AddProductSuccess() {
+	req := &dto.CartLineReq{ProductID: "productId", Quantity: 2}
+	ctx, writer := suite.prepareContext(req)
+	ctx.Set("userId", "userId")
+
+	suite.mockService.On("AddProduct", mock.Anything, &dto.AddProductReq{UserID: "userId", Line: req}).
+		Return(cartWithLine(2), nil).Times(1)
+
+	suite.handler.AddProduct(ctx)
+	suite.Equal(http.StatusOK, writer.Code)
+}
+
+func (suite *CartHandlerTestSuite) TestCartAPI_AddProductInvalidFieldType() {
+	req := map[string]interface{}{"product_id": "productId", "quantity": "2"}
+	ctx, writer := suite.prepareContext(req)
+	ctx.Set("userId", "userId")
+
+	suite.handler.AddProduct(ctx)
+	suite.Equal(http.StatusBadRequest, writer.Code)
+}
+
+func (suite *CartHandlerTestSuite) TestCartAPI_AddProductUnauthorized() {
+	req := &dto.CartLineReq{ProductID: "productId", Quantity: 2}
+	ctx, writer := suite.prepareContext(req)
+
+	suite.handler.AddProduct(ctx)
+	suite.Equal(http.StatusUnauthorized, writer.Code)
+}
+
+func (suite *CartHandlerTestSuite) TestCartAPI_AddProductFail() {
+	req := &dto.CartLineReq{ProductID: "productId", Quantity: 2}
+	ctx, writer := suite.prepareContext(req)
+	ctx.Set("userId", "userId")
+
+	suite.mockService.On("AddProduct", mock.Anything, &dto.AddProductReq{UserID: "userId", Line: req}).
+		Return(nil, errors.New("error")).Times(1)
+
+	suite.handler.AddProduct(ctx)
+	suite.Equal(http.StatusInternalServerError, writer.Code)
+}
+
+// UpdateQuantity
+// =================================================================================================
+
+func (suite *CartHandlerTestSuite) TestCartAPI_UpdateQuantitySuccess() {
+	ctx, writer := suite.prepareContext(&dto.UpdateQuantityReq{Quantity: 5})
+	ctx.Set("userId", "userId")
+	ctx.Params = gin.Params{{Key: "product_id", Value: "productId"}}
+
+	suite.mockService.On("UpdateQuantity", mock.Anything, &dto.UpdateQuantityReq{
+		UserID:    "userId",
+		ProductID: "productId",
+		Quantity:  5,
+	}).Return(cartWithLine(5), nil).Times(1)
+
+	suite.handler.UpdateQuantity(ctx)
+
+	var res map[string]dto.Cart
+	_ = json.Unmarshal(writer.Body.Bytes(), &res)
+	suite.Equal(http.StatusOK, writer.Code)
+	suite.Equal(uint(5), res["result"].Lines[0].Quantity)
+}
+
+func (suite *CartHandlerTestSuite) TestCartAPI_UpdateQuantityMissProductID() {
+	ctx, writer := suite.prepareContext(&dto.UpdateQuantityReq{Quantity: 5})
+	ctx.Set("userId", "userId")
+
+	suite.handler.UpdateQuantity(ctx)
+	suite.Equal(http.StatusBadRequest, writer.Code)
+}
+
+func (suite *CartHandlerTestSuite) TestCartAPI_UpdateQuantityNotInCart() {
+	ctx, writer := suite.prepareContext(&dto.UpdateQuantityReq{Quantity: 5})
+	ctx.Set("userId", "userId")
+	ctx.Params = gin.Params{{Key: "product_id", Value: "productId"}}
+
+	suite.mockService.On("UpdateQuantity", mock.Anything, mock.Anything).
+		Return(nil, service.ErrProductNotInCart).Times(1)
+
+	suite.handler.UpdateQuantity(ctx)
+	suite.Equal(http.StatusNotFound, writer.Code)
+}
+
+func (suite *CartHandlerTestSuite) TestCartAPI_UpdateQuantityUnauthorized() {
+	ctx, writer := suite.prepareContext(&dto.UpdateQuantityReq{Quantity: 5})
+	ctx.Params = gin.Params{{Key: "product_id", Value: "productId"}}
+
+	suite.handler.UpdateQuantity(ctx)
+	suite.Equal(http.StatusUnauthorized, writer.Code)
+}
+
+// RemoveProduct
+// =================================================================================================
+
+func (suite *CartHandlerTestSuite) TestCartAPI_RemoveProductSuccess() {
+	ctx, writer := suite.prepareContext(nil)
+	ctx.Set("userId", "userId")
+	ctx.Params = gin.Params{{Key: "product_id", Value: "productId"}}
+
+	suite.mockService.On("RemoveProduct", mock.Anything, &dto.RemoveProductReq{
+		UserID:    "userId",
+		ProductID: "productId",
+	}).Return(&model.Cart{ID: "cartId", UserID: "userId"}, nil).Times(1)
+
+	suite.handler.RemoveProduct(ctx)
+	suite.Equal(http.StatusOK, writer.Code)
+}
+
+func (suite *CartHandlerTestSuite) TestCartAPI_RemoveProductMissProductID() {
+	ctx, writer := suite.prepareContext(nil)
+	ctx.Set("userId", "userId")
+
+	suite.handler.RemoveProduct(ctx)
+	suite.Equal(http.StatusBadRequest, writer.Code)
+}
+
+func (suite *CartHandlerTestSuite) TestCartAPI_RemoveProductFail() {
+	ctx, writer := suite.prepareContext(nil)
+	ctx.Set("userId", "userId")
+	ctx.Params = gin.Params{{Key: "product_id", Value: "productId"}}
+
+	suite.mockService.On("RemoveProduct", mock.Anything, mock.Anything).
+		Return(nil, errors.New("error")).Times(1)
+
+	suite.handler.RemoveProduct(ctx)
+	suite.Equal(http.StatusInternalServerError, writer.Code)
+}
+
+// Checkout
+// =================================================================================================
+
+func (suite *CartHandlerTestSuite) TestCartAPI_CheckoutSuccess() {
+	ctx, writer := suite.prepareContext(nil)
+	ctx.Set("userId", "userId")
+
+	suite.mockService.On("Checkout", mock.Anything, "userId").
+		Return(&orderModel.Order{ID: "orderId", TotalPrice: money.New(400, "USD"), Status: orderModel.OrderStatusNew}, nil).Times(1)
+
+	suite.handler.Checkout(ctx)
+
+	var res map[string]orderDto.Order
+	_ = json.Unmarshal(writer.Body.Bytes(), &res)
+	suite.Equal(http.StatusOK, writer.Code)
+	suite.Equal("orderId", res["result"].ID)
+	suite.Equal("new", res["result"].Status)
+}
+
+func (suite *CartHandlerTestSuite) TestCartAPI_CheckoutUnauthorized() {
+	ctx, writer := suite.prepareContext(nil)
+
+	suite.handler.Checkout(ctx)
+	suite.Equal(http.StatusUnauthorized, writer.Code)
+}
+
+func (suite *CartHandlerTestSuite) TestCartAPI_CheckoutUnavailableLines() {
+	ctx, writer := suite.prepareContext(nil)
+	ctx.Set("userId", "userId")
+
+	suite.mockService.On("Checkout", mock.Anything, "userId").
+		Return(nil, &service.CheckoutError{Lines: []*service.LineError{
+			{ProductID: "productId", Reason: service.LineReasonInactive},
+		}}).Times(1)
+
+	suite.handler.Checkout(ctx)
+
+	var res map[string]struct {
+		Message string               `json:"message"`
+		Details []*service.LineError `json:"details"`
+	}
+	_ = json.Unmarshal(writer.Body.Bytes(), &res)
+	suite.Equal(http.StatusBadRequest, writer.Code)
+	suite.Equal([]*service.LineError{{ProductID: "productId", Reason: "product is inactive"}}, res["error"].Details)
+}
+
+func (suite *CartHandlerTestSuite) TestCartAPI_CheckoutEmptyCart() {
+	ctx, writer := suite.prepareContext(nil)
+	ctx.Set("userId", "userId")
+
+	suite.mockService.On("Checkout", mock.Anything, "userId").Return(nil, service.ErrEmptyCart).Times(1)
+
+	suite.handler.Checkout(ctx)
+	suite.Equal(http.StatusBadRequest, writer.Code)
+}
+
+func (suite *CartHandlerTestSuite) TestCartAPI_CheckoutCartChanged() {
+	ctx, writer := suite.prepareContext(nil)
+	ctx.Set("userId", "userId")
+
+	suite.mockService.On("Checkout", mock.Anything, "userId").Return(nil, service.ErrCartChanged).Times(1)
+
+	suite.handler.Checkout(ctx)
+	suite.Equal(http.StatusConflict, writer.Code)
+}
+
+func (suite *CartHandlerTestSuite) TestCartAPI_CheckoutFail() {
+	ctx, writer := suite.prepareContext(nil)
+	ctx.Set("userId", "userId")
+
+	suite.mockService.On("Checkout", mock.Anything, "userId").Return(nil, errors.New("error")).Times(1)
+
+	suite.handler.Checkout(ctx)
+	suite.Equal(http.StatusInternalServerError, writer.Code)
+}
@@ -0,0 +1,53 @@
+package http
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/quangdangfit/gocommon/validation"
+
+	"goshop/internal/cart/repository"
+	"goshop/internal/cart/service"
+	orderRepository "goshop/internal/order/repository"
+	orderService "goshop/internal/order/service"
+	promotionRepository "goshop/internal/promotion/repository"
+	promotionService "goshop/internal/promotion/service"
+	userService "goshop/internal/user/service"
+	"goshop/pkg/config"
+	"goshop/pkg/dbs"
+	"goshop/pkg/idempotency"
+	"goshop/pkg/middleware"
+	"goshop/pkg/rbac"
+	"goshop/pkg/redis"
+)
+
+func Routes(
+	r *gin.RouterGroup,
+	db dbs.IDatabase,
+	validator validation.Validation,
+	cache redis.IRedis,
+	idempotencyStore idempotency.Store,
+) {
+	cartRepo := repository.NewCartRepository(db)
+	guestRepo := repository.NewGuestCartRepository(cache, config.GetConfig().GuestCartTTL)
+	orderRepo := orderRepository.NewOrderRepository(db)
+	productRepo := orderRepository.NewProductRepository(db)
+	promotionSvc := promotionService.NewPromotionService(validator, promotionRepository.NewPromotionRepository(db))
+	orderSvc := orderService.NewOrderService(validator, db, orderRepo, productRepo, promotionSvc)
+	if userService.VerificationPolicy(config.GetConfig().EmailVerificationPolicy) == userService.VerificationPolicyOrder {
+		orderSvc.RequireVerifiedEmail(orderRepository.NewUserRepository(db))
+	}
+	cartSvc := service.NewCartService(validator, db, cartRepo, guestRepo, orderSvc)
+	cartHandler := NewCartHandler(cartSvc)
+
+	authMiddleware := middleware.JWTAuth()
+	guestMiddleware := middleware.JWTOrGuestAuth()
+	idempotencyMiddleware := middleware.Idempotency(idempotencyStore)
+
+	cartRoute := r.Group("/cart")
+	{
+		cartRoute.GET("", guestMiddleware, middleware.RequirePermission(rbac.PermissionCartRead), cartHandler.GetCart)
+		cartRoute.POST("/lines", guestMiddleware, middleware.RequirePermission(rbac.PermissionCartWrite), cartHandler.AddProduct)
+		cartRoute.PUT("/lines/:product_id", guestMiddleware, middleware.RequirePermission(rbac.PermissionCartWrite), cartHandler.UpdateQuantity)
+		cartRoute.DELETE("/lines/:product_id", guestMiddleware, middleware.RequirePermission(rbac.PermissionCartWrite), cartHandler.RemoveProduct)
+		cartRoute.POST("/checkout", authMiddleware, idempotencyMiddleware, middleware.RequirePermission(rbac.PermissionOrderWrite), cartHandler.Checkout)
+	}
+}
@@ -2,6 +2,7 @@ package repository
 
 import (
 	"context"
+	"time"
 
 	"goshop/internal/cart/model"
 	"goshop/pkg/dbs"
@@ -12,6 +13,8 @@ type ICartRepository interface {
 	Create(ctx context.Context, cart *model.Cart) error
 	Update(ctx context.Context, cart *model.Cart) error
 	GetCartByUserID(ctx context.Context, userID string) (*model.Cart, error)
+	ClaimCart(ctx context.Context, cart *model.Cart) (bool, error)
+	GetProductsByIDs(ctx context.Context, ids []string) ([]*model.Product, error)
 }
 
 type CartRepo struct {
@@ -26,8 +29,50 @@ func (r *CartRepo) Create(ctx context.Context, cart *model.Cart) error {
 	return r.db.Create(ctx, cart)
 }
 
+// Update persists the lines of the cart: lines are upserted by (cart_id, product_id, variant_id)
+// and lines of products no longer in the cart are deleted, in a single transaction. It bumps
+// updated_at of the cart first, so that a checkout claiming the cart it read fails instead of
+// missing the change, and concurrent updates of the cart wait for the transaction.
 func (r *CartRepo) Update(ctx context.Context, cart *model.Cart) error {
-	return r.db.Update(ctx, cart)
+	handler := func(ctx context.Context) error {
+		updatedAt := time.Now()
+		_, err := r.db.UpdateColumns(ctx, &model.Cart{}, map[string]interface{}{"updated_at": updatedAt},
+			dbs.WithQuery(dbs.NewQuery("id = ?", cart.ID)))
+		if err != nil {
+			return err
+		}
+		cart.UpdatedAt = updatedAt
+
+		return r.updateLines(ctx, cart)
+	}
+
+	return r.db.WithTransaction(ctx, handler)
+}
+
+func (r *CartRepo) updateLines(ctx context.Context, cart *model.Cart) error {
+	keys := make([][]any, 0, len(cart.Lines))
+	for _, line := range cart.Lines {
+		line.CartID = cart.ID
+		keys = append(keys, []any{line.ProductID, line.VariantID})
+	}
+
+	// Delete removed lines
+	query := []dbs.Query{
+		dbs.NewQuery("cart_id = ?", cart.ID),
+	}
+	if len(keys) > 0 {
+		query = append(query, dbs.NewQuery("(product_id, variant_id) NOT IN ?", keys))
+	}
+	if err := r.db.Delete(ctx, &model.CartLine{}, dbs.WithQuery(query...)); err != nil {
+		return err
+	}
+
+	if len(cart.Lines) == 0 {
+		return nil
+	}
+
+	// Insert new lines and update quantity of existing lines
+	return r.db.Upsert(ctx, &cart.Lines, []string{"cart_id", "product_id", "variant_id"}, []string{"quantity", "updated_at"})
 }
 
 func (r *CartRepo) GetCartByUserID(ctx context.Context, userID string) (*model.Cart, error) {
@@ -35,7 +80,7 @@ func (r *CartRepo) GetCartByUserID(ctx context.Context, userID string) (*model.C
 	opts := []dbs.FindOption{
 		dbs.WithQuery(dbs.NewQuery("user_id = ?", userID)),
 	}
-	opts = append(opts, dbs.WithPreload([]string{"User", "Lines.Product"}))
+	opts = append(opts, dbs.WithPreload([]string{"User", "Lines.Product.Stock", "Lines.Product.Variants.Options", "Lines.Product.Variants.Stock"}))
 
 	if err := r.db.FindOne(ctx, &order, opts...); err != nil {
 		return nil, err
@@ -43,3 +88,36 @@ func (r *CartRepo) GetCartByUserID(ctx context.Context, userID string) (*model.C
 
 	return &order, nil
 }
+
+// ClaimCart bumps updated_at of cart unless the cart was changed since it was read,
+// reporting whether it did. Within a transaction it keeps a cart from being checked
+// out twice: a concurrent claim waits for the transaction and then matches no row.
+func (r *CartRepo) ClaimCart(ctx context.Context, cart *model.Cart) (bool, error) {
+	updatedAt := time.Now()
+	rows, err := r.db.UpdateColumns(ctx, &model.Cart{}, map[string]interface{}{"updated_at": updatedAt}, dbs.WithQuery(
+		dbs.NewQuery("id = ?", cart.ID),
+		dbs.NewQuery("updated_at = ?", cart.UpdatedAt),
+	))
+	if err != nil {
+		return false, err
+	}
+	if rows == 0 {
+		return false, nil
+	}
+
+	cart.UpdatedAt = updatedAt
+	return true, nil
+}
+
+func (r *CartRepo) GetProductsByIDs(ctx context.Context, ids []string) ([]*model.Product, error) {
+	var products []*model.Product
+	opts := []dbs.FindOption{
+		dbs.WithQuery(dbs.NewQuery("id IN ?", ids)),
+		dbs.WithPreload([]string{"Variants.Options"}),
+	}
+	if err := r.db.Find(ctx, &products, opts...); err != nil {
+		return nil, err
+	}
+
+	return products, nil
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"errors"
 	"testing"
+	"time"
 
 	"github.com/quangdangfit/gocommon/logger"
 	"github.com/stretchr/testify/mock"
@@ -11,6 +12,7 @@ import (
 
 	"goshop/internal/cart/model"
 	"goshop/pkg/config"
+	"goshop/pkg/dbs"
 	"goshop/pkg/dbs/mocks"
 )
 
@@ -94,13 +96,108 @@ func (suite *CartRepositoryTestSuite) TestUpdateCartSuccessfully() {
 			},
 		},
 	}
-	suite.mockDB.On("Update", mock.Anything, cart).
+	suite.mockDB.On("WithTransaction", mock.Anything, mock.Anything).
+		Return(func(ctx context.Context, function func(ctx context.Context) error) error {
+			return function(ctx)
+		}).Times(1)
+	suite.mockDB.On("UpdateColumns", mock.Anything, &model.Cart{}, mock.Anything,
+		dbs.WithQuery(dbs.NewQuery("id = ?", "cartId1"))).Return(int64(1), nil).Times(1)
+	suite.mockDB.On("Delete", mock.Anything, &model.CartLine{}, mock.Anything).
+		Return(nil).Times(1)
+	suite.mockDB.On("Upsert", mock.Anything, &cart.Lines, []string{"cart_id", "product_id", "variant_id"}, []string{"quantity", "updated_at"}).
+		Return(nil).Times(1)
+
+	err := suite.repo.Update(context.Background(), cart)
+	suite.Nil(err)
+	suite.Equal("cartId1", cart.Lines[0].CartID)
+	suite.Equal("cartId1", cart.Lines[1].CartID)
+	suite.False(cart.UpdatedAt.IsZero())
+}
+
+func (suite *CartRepositoryTestSuite) TestUpdateCartWithoutLinesSuccessfully() {
+	cart := &model.Cart{
+		ID:     "cartId1",
+		UserID: "userID",
+	}
+	suite.mockDB.On("WithTransaction", mock.Anything, mock.Anything).
+		Return(func(ctx context.Context, function func(ctx context.Context) error) error {
+			return function(ctx)
+		}).Times(1)
+	suite.mockDB.On("UpdateColumns", mock.Anything, &model.Cart{}, mock.Anything,
+		dbs.WithQuery(dbs.NewQuery("id = ?", "cartId1"))).Return(int64(1), nil).Times(1)
+	suite.mockDB.On("Delete", mock.Anything, &model.CartLine{}, mock.Anything).
 		Return(nil).Times(1)
 
 	err := suite.repo.Update(context.Background(), cart)
 	suite.Nil(err)
 }
 
+func (suite *CartRepositoryTestSuite) TestUpdateCartDeleteLinesFail() {
+	cart := &model.Cart{
+		ID:     "cartId1",
+		UserID: "userID",
+		Lines: []*model.CartLine{
+			{
+				ProductID: "productID1",
+				Quantity:  4,
+			},
+		},
+	}
+	suite.mockDB.On("WithTransaction", mock.Anything, mock.Anything).
+		Return(func(ctx context.Context, function func(ctx context.Context) error) error {
+			return function(ctx)
+		}).Times(1)
+	suite.mockDB.On("UpdateColumns", mock.Anything, &model.Cart{}, mock.Anything,
+		dbs.WithQuery(dbs.NewQuery("id = ?", "cartId1"))).Return(int64(1), nil).Times(1)
+	suite.mockDB.On("Delete", mock.Anything, &model.CartLine{}, mock.Anything).
+		Return(errors.New("error")).Times(1)
+
+	err := suite.repo.Update(context.Background(), cart)
+	suite.NotNil(err)
+}
+
+func (suite *CartRepositoryTestSuite) TestUpdateCartUpsertLinesFail() {
+	cart := &model.Cart{
+		ID:     "cartId1",
+		UserID: "userID",
+		Lines: []*model.CartLine{
+			{
+				ProductID: "productID1",
+				Quantity:  4,
+			},
+		},
+	}
+	suite.mockDB.On("WithTransaction", mock.Anything, mock.Anything).
+		Return(func(ctx context.Context, function func(ctx context.Context) error) error {
+			return function(ctx)
+		}).Times(1)
+	suite.mockDB.On("UpdateColumns", mock.Anything, &model.Cart{}, mock.Anything,
+		dbs.WithQuery(dbs.NewQuery("id = ?", "cartId1"))).Return(int64(1), nil).Times(1)
+	suite.mockDB.On("Delete", mock.Anything, &model.CartLine{}, mock.Anything).
+		Return(nil).Times(1)
+	suite.mockDB.On("Upsert", mock.Anything, &cart.Lines, mock.Anything, mock.Anything).
+		Return(errors.New("error")).Times(1)
+
+	err := suite.repo.Update(context.Background(), cart)
+	suite.NotNil(err)
+}
+
+func (suite *CartRepositoryTestSuite) TestUpdateCartBumpUpdatedAtFail() {
+	cart := &model.Cart{
+		ID:     "cartId1",
+		UserID: "userID",
+	}
+	suite.mockDB.On("WithTransaction", mock.Anything, mock.Anything).
+		Return(func(ctx context.Context, function func(ctx context.Context) error) error {
+			return function(ctx)
+		}).Times(1)
+	suite.mockDB.On("UpdateColumns", mock.Anything, &model.Cart{}, mock.Anything, mock.Anything).
+		Return(int64(0), errors.New("error")).Times(1)
+
+	err := suite.repo.Update(context.Background(), cart)
+	suite.NotNil(err)
+}
+
 func (suite *CartRepositoryTestSuite) TestUpdateCartFail() {
 	cart := &model.Cart{
 		ID:     "cartId1",
@@ -116,7 +213,7 @@ func (suite *CartRepositoryTestSuite) TestUpdateCartFail() {
 			},
 		},
 	}
-	suite.mockDB.On("Update", mock.Anything, cart).
+	suite.mockDB.On("WithTransaction", mock.Anything, mock.Anything).
 		Return(errors.New("error")).Times(1)
 
 	err := suite.repo.Update(context.Background(), cart)
@@ -143,3 +240,41 @@ func (suite *CartRepositoryTestSuite) TestGetCartByUserIDFail() {
 	suite.NotNil(err)
 	suite.Nil(cart)
 }
+
+// ClaimCart
+// =================================================================
+
+func (suite *CartRepositoryTestSuite) TestClaimCartSuccessfully() {
+	cart := &model.Cart{ID: "cartId", UpdatedAt: time.Now().Add(-time.Minute)}
+	readAt := cart.UpdatedAt
+
+	suite.mockDB.On("UpdateColumns", mock.Anything, &model.Cart{}, mock.Anything, mock.Anything).
+		Return(int64(1), nil).Times(1)
+
+	claimed, err := suite.repo.ClaimCart(context.Background(), cart)
+	suite.Nil(err)
+	suite.True(claimed)
+	suite.True(cart.UpdatedAt.After(readAt))
+}
+
+func (suite *CartRepositoryTestSuite) TestClaimCartChanged() {
+	cart := &model.Cart{ID: "cartId", UpdatedAt: time.Now().Add(-time.Minute)}
+	readAt := cart.UpdatedAt
+
+	suite.mockDB.On("UpdateColumns", mock.Anything, &model.Cart{}, mock.Anything, mock.Anything).
+		Return(int64(0), nil).Times(1)
+
+	claimed, err := suite.repo.ClaimCart(context.Background(), cart)
+	suite.Nil(err)
+	suite.False(claimed)
+	suite.Equal(readAt, cart.UpdatedAt)
+}
+
+func (suite *CartRepositoryTestSuite) TestClaimCartFail() {
+	suite.mockDB.On("UpdateColumns", mock.Anything, &model.Cart{}, mock.Anything, mock.Anything).
+		Return(int64(0), errors.New("error")).Times(1)
+
+	claimed, err := suite.repo.ClaimCart(context.Background(), &model.Cart{ID: "cartId"})
+	suite.NotNil(err)
+	suite.False(claimed)
+}
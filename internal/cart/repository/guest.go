@@ -0,0 +1,76 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"goshop/internal/cart/model"
+	"goshop/pkg/redis"
+)
+
+const guestCartKeyPrefix = "guest_cart:"
+
+//go:generate mockery --name=IGuestCartRepository
+type IGuestCartRepository interface {
+	GetGuestCart(ctx context.Context, token string) (*model.Cart, error)
+	SaveGuestCart(ctx context.Context, token string, cart *model.Cart) error
+	DeleteGuestCart(ctx context.Context, token string) error
+}
+
+// guestCartLine is what is kept in redis of a guest cart line
+type guestCartLine struct {
+	ProductID string `json:"product_id"`
+	VariantID string `json:"variant_id,omitempty"`
+	Quantity  uint   `json:"quantity"`
+}
+
+type GuestCartRepo struct {
+	cache redis.IRedis
+	ttl   time.Duration
+}
+
+// NewGuestCartRepository returns a repository keeping guest carts in redis until ttl
+// passed without change
+func NewGuestCartRepository(cache redis.IRedis, ttl time.Duration) *GuestCartRepo {
+	return &GuestCartRepo{cache: cache, ttl: ttl}
+}
+
+// GetGuestCart returns the cart of the guest, empty when it expired or was never saved
+func (r *GuestCartRepo) GetGuestCart(ctx context.Context, token string) (*model.Cart, error) {
+	var lines []*guestCartLine
+	if err := r.cache.Get(guestCartKeyPrefix+token, &lines); err != nil {
+		if errors.Is(err, redis.ErrNotFound) {
+			return &model.Cart{Lines: []*model.CartLine{}}, nil
+		}
+		return nil, err
+	}
+
+	cart := &model.Cart{Lines: make([]*model.CartLine, 0, len(lines))}
+	for _, line := range lines {
+		cart.Lines = append(cart.Lines, &model.CartLine{
+			ProductID: line.ProductID,
+			VariantID: line.VariantID,
+			Quantity:  line.Quantity,
+		})
+	}
+
+	return cart, nil
+}
+
+func (r *GuestCartRepo) SaveGuestCart(ctx context.Context, token string, cart *model.Cart) error {
+	lines := make([]*guestCartLine, 0, len(cart.Lines))
+	for _, line := range cart.Lines {
+		lines = append(lines, &guestCartLine{
+			ProductID: line.ProductID,
+			VariantID: line.VariantID,
+			Quantity:  line.Quantity,
+		})
+	}
+
+	return r.cache.SetWithExpiration(guestCartKeyPrefix+token, lines, r.ttl)
+}
+
+func (r *GuestCartRepo) DeleteGuestCart(ctx context.Context, token string) error {
+	return r.cache.Remove(guestCartKeyPrefix + token)
+}
@@ -0,0 +1,68 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	"goshop/internal/cart/model"
+	"goshop/pkg/redis"
+	redisMocks "goshop/pkg/redis/mocks"
+)
+
+func TestGetGuestCart(t *testing.T) {
+	mockRedis := redisMocks.NewIRedis(t)
+	mockRedis.On("Get", "guest_cart:token", mock.Anything).
+		Run(func(args mock.Arguments) {
+			lines := args.Get(1).(*[]*guestCartLine)
+			*lines = []*guestCartLine{{ProductID: "productID", Quantity: 2}}
+		}).
+		Return(nil).Times(1)
+
+	cart, err := NewGuestCartRepository(mockRedis, time.Hour).GetGuestCart(context.Background(), "token")
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(cart.Lines))
+	assert.Equal(t, "productID", cart.Lines[0].ProductID)
+	assert.Equal(t, uint(2), cart.Lines[0].Quantity)
+}
+
+func TestGetGuestCartNotFound(t *testing.T) {
+	mockRedis := redisMocks.NewIRedis(t)
+	mockRedis.On("Get", "guest_cart:token", mock.Anything).Return(redis.ErrNotFound).Times(1)
+
+	cart, err := NewGuestCartRepository(mockRedis, time.Hour).GetGuestCart(context.Background(), "token")
+	assert.Nil(t, err)
+	assert.Equal(t, 0, len(cart.Lines))
+}
+
+func TestGetGuestCartFail(t *testing.T) {
+	mockRedis := redisMocks.NewIRedis(t)
+	mockRedis.On("Get", "guest_cart:token", mock.Anything).Return(errors.New("error")).Times(1)
+
+	cart, err := NewGuestCartRepository(mockRedis, time.Hour).GetGuestCart(context.Background(), "token")
+	assert.Nil(t, cart)
+	assert.NotNil(t, err)
+}
+
+func TestSaveGuestCart(t *testing.T) {
+	mockRedis := redisMocks.NewIRedis(t)
+	mockRedis.On("SetWithExpiration", "guest_cart:token", mock.MatchedBy(func(lines []*guestCartLine) bool {
+		return len(lines) == 1 && lines[0].ProductID == "productID" && lines[0].Quantity == 2
+	}), time.Hour).Return(nil).Times(1)
+
+	cart := &model.Cart{Lines: []*model.CartLine{{ProductID: "productID", Quantity: 2}}}
+	err := NewGuestCartRepository(mockRedis, time.Hour).SaveGuestCart(context.Background(), "token", cart)
+	assert.Nil(t, err)
+}
+
+func TestDeleteGuestCart(t *testing.T) {
+	mockRedis := redisMocks.NewIRedis(t)
+	mockRedis.On("Remove", "guest_cart:token").Return(nil).Times(1)
+
+	err := NewGuestCartRepository(mockRedis, time.Hour).DeleteGuestCart(context.Background(), "token")
+	assert.Nil(t, err)
+}
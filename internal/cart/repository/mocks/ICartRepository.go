@@ -14,6 +14,30 @@ type ICartRepository struct {
 	mock.Mock
 }
 
+// ClaimCart provides a mock function with given fields: ctx, cart
+func (_m *ICartRepository) ClaimCart(ctx context.Context, cart *model.Cart) (bool, error) {
+	ret := _m.Called(ctx, cart)
+
+	var r0 bool
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, *model.Cart) (bool, error)); ok {
+		return rf(ctx, cart)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, *model.Cart) bool); ok {
+		r0 = rf(ctx, cart)
+	} else {
+		r0 = ret.Get(0).(bool)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, *model.Cart) error); ok {
+		r1 = rf(ctx, cart)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // Create provides a mock function with given fields: ctx, cart
 func (_m *ICartRepository) Create(ctx context.Context, cart *model.Cart) error {
 	ret := _m.Called(ctx, cart)
@@ -54,6 +78,32 @@ func (_m *ICartRepository) GetCartByUserID(ctx context.Context, userID string) (
 	return r0, r1
 }
 
+// GetProductsByIDs provides a mock function with given fields: ctx, ids
+func (_m *ICartRepository) GetProductsByIDs(ctx context.Context, ids []string) ([]*model.Product, error) {
+	ret := _m.Called(ctx, ids)
+
+	var r0 []*model.Product
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, []string) ([]*model.Product, error)); ok {
+		return rf(ctx, ids)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, []string) []*model.Product); ok {
+		r0 = rf(ctx, ids)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*model.Product)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, []string) error); ok {
+		r1 = rf(ctx, ids)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // Update provides a mock function with given fields: ctx, cart
 func (_m *ICartRepository) Update(ctx context.Context, cart *model.Cart) error {
 	ret := _m.Called(ctx, cart)
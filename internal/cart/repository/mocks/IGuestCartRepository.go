@@ -0,0 +1,83 @@
+// Code generated by mockery v2.32.4. DO NOT EDIT.
+
+package mocks
+
+import (
+	context "context"
+	model "goshop/internal/cart/model"
+
+	mock "github.com/stretchr/testify/mock"
+)
+
+// IGuestCartRepository is an autogenerated mock type for the IGuestCartRepository type
+type IGuestCartRepository struct {
+	mock.Mock
+}
+
+// DeleteGuestCart provides a mock function with given fields: ctx, token
+func (_m *IGuestCartRepository) DeleteGuestCart(ctx context.Context, token string) error {
+	ret := _m.Called(ctx, token)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) error); ok {
+		r0 = rf(ctx, token)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// GetGuestCart provides a mock function with given fields: ctx, token
+func (_m *IGuestCartRepository) GetGuestCart(ctx context.Context, token string) (*model.Cart, error) {
+	ret := _m.Called(ctx, token)
+
+	var r0 *model.Cart
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) (*model.Cart, error)); ok {
+		return rf(ctx, token)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string) *model.Cart); ok {
+		r0 = rf(ctx, token)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*model.Cart)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, token)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// SaveGuestCart provides a mock function with given fields: ctx, token, cart
+func (_m *IGuestCartRepository) SaveGuestCart(ctx context.Context, token string, cart *model.Cart) error {
+	ret := _m.Called(ctx, token, cart)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, *model.Cart) error); ok {
+		r0 = rf(ctx, token, cart)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// NewIGuestCartRepository creates a new instance of IGuestCartRepository. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewIGuestCartRepository(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *IGuestCartRepository {
+	mock := &IGuestCartRepository{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
@@ -2,6 +2,7 @@ package service
 
 import (
 	"context"
+	"errors"
 
 	"github.com/quangdangfit/gocommon/logger"
 	"github.com/quangdangfit/gocommon/validation"
@@ -9,27 +10,44 @@ import (
 	"goshop/internal/cart/dto"
 	"goshop/internal/cart/model"
 	"goshop/internal/cart/repository"
+	orderModel "goshop/internal/order/model"
+	orderService "goshop/internal/order/service"
+	"goshop/pkg/dbs"
 )
 
+var ErrProductNotInCart = errors.New("product is not in the cart")
+
 //go:generate mockery --name=ICartService
 type ICartService interface {
 	AddProduct(ctx context.Context, req *dto.AddProductReq) (*model.Cart, error)
 	GetCartByUserID(ctx context.Context, userID string) (*model.Cart, error)
+	GetGuestCart(ctx context.Context, guestToken string) (*model.Cart, error)
 	RemoveProduct(ctx context.Context, req *dto.RemoveProductReq) (*model.Cart, error)
+	UpdateQuantity(ctx context.Context, req *dto.UpdateQuantityReq) (*model.Cart, error)
+	Checkout(ctx context.Context, userID string) (*orderModel.Order, error)
 }
 
 type CartService struct {
 	validator validation.Validation
+	db        dbs.IDatabase
 	repo      repository.ICartRepository
+	guestRepo repository.IGuestCartRepository
+	orderSvc  orderService.IOrderService
 }
 
 func NewCartService(
 	validator validation.Validation,
+	db dbs.IDatabase,
 	repo repository.ICartRepository,
+	guestRepo repository.IGuestCartRepository,
+	orderSvc orderService.IOrderService,
 ) *CartService {
 	return &CartService{
 		validator: validator,
+		db:        db,
 		repo:      repo,
+		guestRepo: guestRepo,
+		orderSvc:  orderSvc,
 	}
 }
 
@@ -54,12 +72,27 @@ func (p *CartService) AddProduct(ctx context.Context, req *dto.AddProductReq) (*
 		return nil, err
 	}
 
+	if req.UserID == "" {
+		return p.updateGuestCart(ctx, req.GuestToken, func(cart *model.Cart) error {
+			if cart.Line(req.Line.ProductID, req.Line.VariantID) != nil {
+				return nil
+			}
+			cart.Lines = append(cart.Lines, &model.CartLine{
+				ProductID: req.Line.ProductID,
+				VariantID: req.Line.VariantID,
+				Quantity:  req.Line.Quantity,
+			})
+			return nil
+		})
+	}
+
 	cart, err := p.repo.GetCartByUserID(ctx, req.UserID)
 	if err != nil {
 		cart = &model.Cart{
 			UserID: req.UserID,
 			Lines: []*model.CartLine{{
 				ProductID: req.Line.ProductID,
+				VariantID: req.Line.VariantID,
 				Quantity:  req.Line.Quantity,
 			}},
 		}
@@ -70,14 +103,13 @@ func (p *CartService) AddProduct(ctx context.Context, req *dto.AddProductReq) (*
 		return cart, err
 	}
 
-	for _, line := range cart.Lines {
-		if line.ProductID == req.Line.ProductID {
-			return cart, nil
-		}
+	if cart.Line(req.Line.ProductID, req.Line.VariantID) != nil {
+		return cart, nil
 	}
 
 	cart.Lines = append(cart.Lines, &model.CartLine{
 		ProductID: req.Line.ProductID,
+		VariantID: req.Line.VariantID,
 		Quantity:  req.Line.Quantity,
 	})
 
@@ -95,6 +127,13 @@ func (p *CartService) RemoveProduct(ctx context.Context, req *dto.RemoveProductR
 		return nil, err
 	}
 
+	if req.UserID == "" {
+		return p.updateGuestCart(ctx, req.GuestToken, func(cart *model.Cart) error {
+			removeLine(cart, req.ProductID, req.VariantID)
+			return nil
+		})
+	}
+
 	cart, err := p.repo.GetCartByUserID(ctx, req.UserID)
 	if err != nil {
 		cart = &model.Cart{
@@ -107,12 +146,7 @@ func (p *CartService) RemoveProduct(ctx context.Context, req *dto.RemoveProductR
 		return cart, err
 	}
 
-	for i, line := range cart.Lines {
-		if line.ProductID == req.ProductID {
-			cart.Lines = append(cart.Lines[:i], cart.Lines[i+1:]...)
-			break
-		}
-	}
+	removeLine(cart, req.ProductID, req.VariantID)
 
 	err = p.repo.Update(ctx, cart)
 	if err != nil {
@@ -122,3 +156,52 @@ func (p *CartService) RemoveProduct(ctx context.Context, req *dto.RemoveProductR
 
 	return cart, nil
 }
+
+// UpdateQuantity sets the quantity of a product already in the cart
+func (p *CartService) UpdateQuantity(ctx context.Context, req *dto.UpdateQuantityReq) (*model.Cart, error) {
+	if err := p.validator.ValidateStruct(req); err != nil {
+		return nil, err
+	}
+
+	if req.UserID == "" {
+		return p.updateGuestCart(ctx, req.GuestToken, func(cart *model.Cart) error {
+			return setQuantity(cart, req.ProductID, req.VariantID, req.Quantity)
+		})
+	}
+
+	cart, err := p.repo.GetCartByUserID(ctx, req.UserID)
+	if err != nil {
+		return nil, ErrProductNotInCart
+	}
+
+	if err := setQuantity(cart, req.ProductID, req.VariantID, req.Quantity); err != nil {
+		return nil, err
+	}
+
+	err = p.repo.Update(ctx, cart)
+	if err != nil {
+		logger.Errorf("UpdateQuantityReq.Update fail, userID: %s, error: %s", req.UserID, err)
+		return nil, err
+	}
+
+	return cart, nil
+}
+
+func removeLine(cart *model.Cart, productID, variantID string) {
+	for i, line := range cart.Lines {
+		if line.ProductID == productID && line.VariantID == variantID {
+			cart.Lines = append(cart.Lines[:i], cart.Lines[i+1:]...)
+			return
+		}
+	}
+}
+
+func setQuantity(cart *model.Cart, productID, variantID string, quantity uint) error {
+	line := cart.Line(productID, variantID)
+	if line == nil {
+		return ErrProductNotInCart
+	}
+
+	line.Quantity = quantity
+	return nil
+}
@@ -13,21 +13,29 @@ import (
 	"goshop/internal/cart/dto"
 	"goshop/internal/cart/model"
 	"goshop/internal/cart/repository/mocks"
+	orderMocks "goshop/internal/order/service/mocks"
 	"goshop/pkg/config"
+	dbMocks "goshop/pkg/dbs/mocks"
 )
 
 type CartServiceTestSuite struct {
 	suite.Suite
-	mockRepo *mocks.ICartRepository
-	service  ICartService
+	mockDB           *dbMocks.IDatabase
+	mockRepo         *mocks.ICartRepository
+	mockGuestRepo    *mocks.IGuestCartRepository
+	mockOrderService *orderMocks.IOrderService
+	service          ICartService
 }
 
 func (suite *CartServiceTestSuite) SetupTest() {
 	logger.Initialize(config.ProductionEnv)
 
 	validator := validation.New()
+	suite.mockDB = dbMocks.NewIDatabase(suite.T())
 	suite.mockRepo = mocks.NewICartRepository(suite.T())
-	suite.service = NewCartService(validator, suite.mockRepo)
+	suite.mockGuestRepo = mocks.NewIGuestCartRepository(suite.T())
+	suite.mockOrderService = orderMocks.NewIOrderService(suite.T())
+	suite.service = NewCartService(validator, suite.mockDB, suite.mockRepo, suite.mockGuestRepo, suite.mockOrderService)
 }
 
 func TestCartServiceTestSuite(t *testing.T) {
@@ -275,6 +283,30 @@ func (suite *CartServiceTestSuite) TestAddProductAlreadyExistInCart() {
 	suite.Nil(err)
 }
 
+func (suite *CartServiceTestSuite) TestAddProductOtherVariant() {
+	req := &dto.AddProductReq{
+		UserID: "userID",
+		Line: &dto.CartLineReq{
+			ProductID: "productID1",
+			VariantID: "variantM",
+			Quantity:  1,
+		},
+	}
+
+	suite.mockRepo.On("GetCartByUserID", mock.Anything, "userID").
+		Return(&model.Cart{
+			ID:     "cartId1",
+			UserID: "userID",
+			Lines:  []*model.CartLine{{ProductID: "productID1", VariantID: "variantS", Quantity: 2}},
+		}, nil).Times(1)
+	suite.mockRepo.On("Update", mock.Anything, mock.Anything).Return(nil).Times(1)
+
+	cart, err := suite.service.AddProduct(context.Background(), req)
+	suite.Nil(err)
+	suite.Equal(2, len(cart.Lines))
+	suite.Equal("variantM", cart.Lines[1].VariantID)
+}
+
 func (suite *CartServiceTestSuite) TestAddProductUpdateFail() {
 	req := &dto.AddProductReq{
 		UserID: "userID",
@@ -441,3 +473,126 @@ func (suite *CartServiceTestSuite) TestRemoveProductUpdateFail() {
 	suite.Nil(cart)
 	suite.NotNil(err)
 }
+
+// UpdateQuantity
+// =================================================================
+
+func (suite *CartServiceTestSuite) TestUpdateQuantitySuccessfully() {
+	req := &dto.UpdateQuantityReq{
+		UserID:    "userID",
+		ProductID: "productID1",
+		Quantity:  7,
+	}
+
+	suite.mockRepo.On("GetCartByUserID", mock.Anything, "userID").
+		Return(
+			&model.Cart{
+				ID:     "cartId1",
+				UserID: "userID",
+				Lines: []*model.CartLine{
+					{
+						ProductID: "productID1",
+						Quantity:  4,
+					},
+				},
+			},
+			nil,
+		).Times(1)
+
+	suite.mockRepo.On("Update", mock.Anything, &model.Cart{
+		ID:     "cartId1",
+		UserID: "userID",
+		Lines: []*model.CartLine{
+			{
+				ProductID: "productID1",
+				Quantity:  7,
+			},
+		},
+	}).Return(nil).Times(1)
+
+	cart, err := suite.service.UpdateQuantity(context.Background(), req)
+	suite.Nil(err)
+	suite.Equal(uint(7), cart.Lines[0].Quantity)
+}
+
+func (suite *CartServiceTestSuite) TestUpdateQuantityMissQuantity() {
+	req := &dto.UpdateQuantityReq{
+		UserID:    "userID",
+		ProductID: "productID1",
+	}
+
+	cart, err := suite.service.UpdateQuantity(context.Background(), req)
+	suite.Nil(cart)
+	suite.NotNil(err)
+}
+
+func (suite *CartServiceTestSuite) TestUpdateQuantityCartNotFound() {
+	req := &dto.UpdateQuantityReq{
+		UserID:    "userID",
+		ProductID: "productID1",
+		Quantity:  7,
+	}
+
+	suite.mockRepo.On("GetCartByUserID", mock.Anything, "userID").
+		Return(nil, errors.New("error")).Times(1)
+
+	cart, err := suite.service.UpdateQuantity(context.Background(), req)
+	suite.Nil(cart)
+	suite.ErrorIs(err, ErrProductNotInCart)
+}
+
+func (suite *CartServiceTestSuite) TestUpdateQuantityProductNotInCart() {
+	req := &dto.UpdateQuantityReq{
+		UserID:    "userID",
+		ProductID: "productID2",
+		Quantity:  7,
+	}
+
+	suite.mockRepo.On("GetCartByUserID", mock.Anything, "userID").
+		Return(
+			&model.Cart{
+				ID:     "cartId1",
+				UserID: "userID",
+				Lines: []*model.CartLine{
+					{
+						ProductID: "productID1",
+						Quantity:  4,
+					},
+				},
+			},
+			nil,
+		).Times(1)
+
+	cart, err := suite.service.UpdateQuantity(context.Background(), req)
+	suite.Nil(cart)
+	suite.ErrorIs(err, ErrProductNotInCart)
+}
+
+func (suite *CartServiceTestSuite) TestUpdateQuantityUpdateFail() {
+	req := &dto.UpdateQuantityReq{
+		UserID:    "userID",
+		ProductID: "productID1",
+		Quantity:  7,
+	}
+
+	suite.mockRepo.On("GetCartByUserID", mock.Anything, "userID").
+		Return(
+			&model.Cart{
+				ID:     "cartId1",
+				UserID: "userID",
+				Lines: []*model.CartLine{
+					{
+						ProductID: "productID1",
+						Quantity:  4,
+					},
+				},
+			},
+			nil,
+		).Times(1)
+
+	suite.mockRepo.On("Update", mock.Anything, mock.Anything).Return(errors.New("error")).Times(1)
+
+	cart, err := suite.service.UpdateQuantity(context.Background(), req)
+	suite.Nil(cart)
+	suite.NotNil(err)
+}
@@ -0,0 +1,166 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/quangdangfit/gocommon/logger"
+	"gorm.io/gorm"
+
+	"goshop/internal/cart/model"
+	orderDto "goshop/internal/order/dto"
+	orderModel "goshop/internal/order/model"
+)
+
+// maxCheckoutLines is the number of lines an order may have
+const maxCheckoutLines = 5
+
+var (
+	ErrEmptyCart    = errors.New("cart is empty")
+	ErrCartTooLarge = fmt.Errorf("cart has more than %d products", maxCheckoutLines)
+	ErrCartChanged  = errors.New("cart was changed during checkout")
+)
+
+// Reasons for a cart line not to be available
+const (
+	LineReasonNotFound          = "product not found"
+	LineReasonInactive          = "product is inactive"
+	LineReasonInsufficientStock = "insufficient stock"
+	LineReasonVariantRequired   = "a variant of the product must be chosen"
+	LineReasonVariantNotFound   = "variant not found"
+	LineReasonVariantInactive   = "variant is inactive"
+)
+
+// LineError tells why the product of a cart line cannot be ordered
+type LineError struct {
+	ProductID string `json:"product_id"`
+	VariantID string `json:"variant_id,omitempty"`
+	Reason    string `json:"reason"`
+}
+
+// CheckoutError is returned by Checkout when some lines of the cart cannot be ordered
+type CheckoutError struct {
+	Lines []*LineError
+}
+
+func (e *CheckoutError) Error() string {
+	reasons := make([]string, 0, len(e.Lines))
+	for _, line := range e.Lines {
+		subject := "product " + line.ProductID
+		if line.VariantID != "" {
+			subject += " variant " + line.VariantID
+		}
+		reasons = append(reasons, fmt.Sprintf("%s: %s", subject, line.Reason))
+	}
+
+	return "cart cannot be checked out: " + strings.Join(reasons, "; ")
+}
+
+// Checkout places an order for the lines of the user's cart, at the current price of their
+// products, and empties the cart. Both happen in one transaction, nothing changes on failure.
+func (p *CartService) Checkout(ctx context.Context, userID string) (*orderModel.Order, error) {
+	var order *orderModel.Order
+	err := p.db.WithTransaction(ctx, func(ctx context.Context) error {
+		cart, err := p.repo.GetCartByUserID(ctx, userID)
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrEmptyCart
+		}
+		if err != nil {
+			logger.Errorf("Checkout.GetCartByUserID fail, userID: %s, error: %s", userID, err)
+			return err
+		}
+		if len(cart.Lines) == 0 {
+			return ErrEmptyCart
+		}
+		if len(cart.Lines) > maxCheckoutLines {
+			return ErrCartTooLarge
+		}
+		if err := checkLines(cart.Lines); err != nil {
+			return err
+		}
+
+		claimed, err := p.repo.ClaimCart(ctx, cart)
+		if err != nil {
+			return err
+		}
+		if !claimed {
+			return ErrCartChanged
+		}
+
+		req := &orderDto.PlaceOrderReq{UserID: userID}
+		for _, line := range cart.Lines {
+			req.Lines = append(req.Lines, orderDto.PlaceOrderLineReq{
+				ProductID: line.ProductID,
+				VariantID: line.VariantID,
+				Quantity:  line.Quantity,
+			})
+		}
+
+		// The order service prices the lines from the products and reserves their stock
+		order, err = p.orderSvc.PlaceOrder(ctx, req)
+		if err != nil {
+			return err
+		}
+
+		cart.Lines = []*model.CartLine{}
+		return p.repo.Update(ctx, cart)
+	})
+	if err != nil {
+		logger.Errorf("Checkout fail, userID: %s, error: %s", userID, err)
+		return nil, err
+	}
+
+	return order, nil
+}
+
+func checkLines(lines []*model.CartLine) error {
+	var lineErrors []*LineError
+	for _, line := range lines {
+		reason := ""
+		switch {
+		case line.Product == nil:
+			reason = LineReasonNotFound
+		case !line.Product.Active:
+			reason = LineReasonInactive
+		case line.VariantID != "" || len(line.Product.Variants) > 0:
+			reason = checkVariant(line)
+		case line.Product.Stock == nil || line.Product.Stock.Available() < int64(line.Quantity):
+			reason = LineReasonInsufficientStock
+		}
+
+		if reason != "" {
+			lineErrors = append(lineErrors, &LineError{
+				ProductID: line.ProductID,
+				VariantID: line.VariantID,
+				Reason:    reason,
+			})
+		}
+	}
+
+	if len(lineErrors) > 0 {
+		return &CheckoutError{Lines: lineErrors}
+	}
+
+	return nil
+}
+
+// checkVariant returns why the variant of the line cannot be ordered, empty when it can
+func checkVariant(line *model.CartLine) string {
+	if line.VariantID == "" {
+		return LineReasonVariantRequired
+	}
+
+	variant := line.Product.Variant(line.VariantID)
+	switch {
+	case variant == nil:
+		return LineReasonVariantNotFound
+	case !variant.Active:
+		return LineReasonVariantInactive
+	case variant.Stock == nil || variant.Stock.Available() < int64(line.Quantity):
+		return LineReasonInsufficientStock
+	}
+
+	return ""
+}
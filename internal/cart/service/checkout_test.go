@@ -0,0 +1,242 @@
+package service
+
+import (
+	"context"
+	"errors"
+
+	"github.com/stretchr/testify/mock"
+	"gorm.io/gorm"
+
+	"goshop/internal/cart/model"
+	orderDto "goshop/internal/order/dto"
+	orderModel "goshop/internal/order/model"
+	"goshop/pkg/dbs"
+	"goshop/pkg/money"
+)
+
+func (suite *CartServiceTestSuite) mockTransaction() {
+	suite.mockDB.On("WithTransaction", mock.Anything, mock.Anything).
+		Return(func(ctx context.Context, fn func(context.Context) error) error {
+			return fn(ctx)
+		}).Times(1)
+}
+
+func checkoutCart(lines ...*model.CartLine) *model.Cart {
+	return &model.Cart{
+		ID:     "cartId1",
+		UserID: "userID",
+		Lines:  lines,
+	}
+}
+
+func availableLine(productID string, quantity uint) *model.CartLine {
+	return &model.CartLine{
+		ProductID: productID,
+		Quantity:  quantity,
+		Product: &model.Product{
+			ID:     productID,
+			Price:  money.New(200, "USD"),
+			Active: true,
+			Stock:  &model.ProductStock{ProductID: productID, OnHand: 10, Reserved: 2},
+		},
+	}
+}
+
+// variantLine is a line of a variant of a product sold by variant
+func variantLine(productID, variantID string, quantity uint) *model.CartLine {
+	return &model.CartLine{
+		ProductID: productID,
+		VariantID: variantID,
+		Quantity:  quantity,
+		Product: &model.Product{
+			ID:     productID,
+			Price:  money.New(200, "USD"),
+			Active: true,
+			Stock:  &model.ProductStock{ProductID: productID},
+			Variants: []*model.ProductVariant{{
+				ID:     variantID,
+				Active: true,
+				Stock:  &model.VariantStock{VariantID: variantID, OnHand: 5, Reserved: 1},
+			}},
+		},
+	}
+}
+
+// Checkout
+// =================================================================
+
+func (suite *CartServiceTestSuite) TestCheckoutSuccessfully() {
+	cart := checkoutCart(availableLine("productID1", 2), availableLine("productID2", 8))
+
+	suite.mockTransaction()
+	suite.mockRepo.On("GetCartByUserID", mock.Anything, "userID").Return(cart, nil).Times(1)
+	suite.mockRepo.On("ClaimCart", mock.Anything, cart).Return(true, nil).Times(1)
+	suite.mockOrderService.On("PlaceOrder", mock.Anything, &orderDto.PlaceOrderReq{
+		UserID: "userID",
+		Lines: []orderDto.PlaceOrderLineReq{
+			{ProductID: "productID1", Quantity: 2},
+			{ProductID: "productID2", Quantity: 8},
+		},
+	}).Return(&orderModel.Order{ID: "orderId1", TotalPrice: money.New(2000, "USD")}, nil).Times(1)
+	suite.mockRepo.On("Update", mock.Anything, &model.Cart{
+		ID:     "cartId1",
+		UserID: "userID",
+		Lines:  []*model.CartLine{},
+	}).Return(nil).Times(1)
+
+	order, err := suite.service.Checkout(context.Background(), "userID")
+	suite.Nil(err)
+	suite.Equal("orderId1", order.ID)
+}
+
+func (suite *CartServiceTestSuite) TestCheckoutEmptyCart() {
+	suite.mockTransaction()
+	suite.mockRepo.On("GetCartByUserID", mock.Anything, "userID").Return(checkoutCart(), nil).Times(1)
+
+	order, err := suite.service.Checkout(context.Background(), "userID")
+	suite.Nil(order)
+	suite.ErrorIs(err, ErrEmptyCart)
+}
+
+func (suite *CartServiceTestSuite) TestCheckoutCartNotFound() {
+	suite.mockTransaction()
+	suite.mockRepo.On("GetCartByUserID", mock.Anything, "userID").Return(nil, gorm.ErrRecordNotFound).Times(1)
+
+	order, err := suite.service.Checkout(context.Background(), "userID")
+	suite.Nil(order)
+	suite.ErrorIs(err, ErrEmptyCart)
+}
+
+func (suite *CartServiceTestSuite) TestCheckoutGetCartFail() {
+	cancelled := &dbs.CancelledError{Err: context.DeadlineExceeded}
+	suite.mockTransaction()
+	suite.mockRepo.On("GetCartByUserID", mock.Anything, "userID").Return(nil, cancelled).Times(1)
+
+	order, err := suite.service.Checkout(context.Background(), "userID")
+	suite.Nil(order)
+	suite.ErrorIs(err, cancelled)
+	suite.NotErrorIs(err, ErrEmptyCart)
+}
+
+func (suite *CartServiceTestSuite) TestCheckoutCartTooLarge() {
+	var lines []*model.CartLine
+	for _, id := range []string{"p1", "p2", "p3", "p4", "p5", "p6"} {
+		lines = append(lines, availableLine(id, 1))
+	}
+
+	suite.mockTransaction()
+	suite.mockRepo.On("GetCartByUserID", mock.Anything, "userID").Return(checkoutCart(lines...), nil).Times(1)
+
+	order, err := suite.service.Checkout(context.Background(), "userID")
+	suite.Nil(order)
+	suite.ErrorIs(err, ErrCartTooLarge)
+}
+
+func (suite *CartServiceTestSuite) TestCheckoutUnavailableLines() {
+	inactive := availableLine("productID2", 1)
+	inactive.Product.Active = false
+	noStock := availableLine("productID3", 9)
+	cart := checkoutCart(
+		availableLine("productID1", 1),
+		inactive,
+		noStock,
+		&model.CartLine{ProductID: "productID4", Quantity: 1},
+	)
+
+	suite.mockTransaction()
+	suite.mockRepo.On("GetCartByUserID", mock.Anything, "userID").Return(cart, nil).Times(1)
+
+	order, err := suite.service.Checkout(context.Background(), "userID")
+	suite.Nil(order)
+
+	var checkoutErr *CheckoutError
+	suite.True(errors.As(err, &checkoutErr))
+	suite.Equal([]*LineError{
+		{ProductID: "productID2", Reason: LineReasonInactive},
+		{ProductID: "productID3", Reason: LineReasonInsufficientStock},
+		{ProductID: "productID4", Reason: LineReasonNotFound},
+	}, checkoutErr.Lines)
+}
+
+func (suite *CartServiceTestSuite) TestCheckoutVariantsSuccessfully() {
+	cart := checkoutCart(variantLine("productID1", "variantID1", 4))
+
+	suite.mockTransaction()
+	suite.mockRepo.On("GetCartByUserID", mock.Anything, "userID").Return(cart, nil).Times(1)
+	suite.mockRepo.On("ClaimCart", mock.Anything, cart).Return(true, nil).Times(1)
+	suite.mockOrderService.On("PlaceOrder", mock.Anything, &orderDto.PlaceOrderReq{
+		UserID: "userID",
+		Lines:  []orderDto.PlaceOrderLineReq{{ProductID: "productID1", VariantID: "variantID1", Quantity: 4}},
+	}).Return(&orderModel.Order{ID: "orderId1"}, nil).Times(1)
+	suite.mockRepo.On("Update", mock.Anything, mock.Anything).Return(nil).Times(1)
+
+	order, err := suite.service.Checkout(context.Background(), "userID")
+	suite.Nil(err)
+	suite.Equal("orderId1", order.ID)
+}
+
+func (suite *CartServiceTestSuite) TestCheckoutUnavailableVariants() {
+	noVariant := variantLine("productID1", "variantID1", 1)
+	noVariant.VariantID = ""
+	unknown := variantLine("productID2", "variantID2", 1)
+	unknown.VariantID = "variantID9"
+	inactive := variantLine("productID3", "variantID3", 1)
+	inactive.Product.Variants[0].Active = false
+	noStock := variantLine("productID4", "variantID4", 5)
+	cart := checkoutCart(noVariant, unknown, inactive, noStock)
+
+	suite.mockTransaction()
+	suite.mockRepo.On("GetCartByUserID", mock.Anything, "userID").Return(cart, nil).Times(1)
+
+	order, err := suite.service.Checkout(context.Background(), "userID")
+	suite.Nil(order)
+
+	var checkoutErr *CheckoutError
+	suite.True(errors.As(err, &checkoutErr))
+	suite.Equal([]*LineError{
+		{ProductID: "productID1", Reason: LineReasonVariantRequired},
+		{ProductID: "productID2", VariantID: "variantID9", Reason: LineReasonVariantNotFound},
+		{ProductID: "productID3", VariantID: "variantID3", Reason: LineReasonVariantInactive},
+		{ProductID: "productID4", VariantID: "variantID4", Reason: LineReasonInsufficientStock},
+	}, checkoutErr.Lines)
+}
+
+func (suite *CartServiceTestSuite) TestCheckoutCartChanged() {
+	cart := checkoutCart(availableLine("productID1", 2))
+
+	suite.mockTransaction()
+	suite.mockRepo.On("GetCartByUserID", mock.Anything, "userID").Return(cart, nil).Times(1)
+	suite.mockRepo.On("ClaimCart", mock.Anything, cart).Return(false, nil).Times(1)
+
+	order, err := suite.service.Checkout(context.Background(), "userID")
+	suite.Nil(order)
+	suite.ErrorIs(err, ErrCartChanged)
+}
+
+func (suite *CartServiceTestSuite) TestCheckoutPlaceOrderFail() {
+	cart := checkoutCart(availableLine("productID1", 2))
+
+	suite.mockTransaction()
+	suite.mockRepo.On("GetCartByUserID", mock.Anything, "userID").Return(cart, nil).Times(1)
+	suite.mockRepo.On("ClaimCart", mock.Anything, cart).Return(true, nil).Times(1)
+	suite.mockOrderService.On("PlaceOrder", mock.Anything, mock.Anything).Return(nil, errors.New("error")).Times(1)
+
+	order, err := suite.service.Checkout(context.Background(), "userID")
+	suite.Nil(order)
+	suite.NotNil(err)
+}
+
+func (suite *CartServiceTestSuite) TestCheckoutUpdateFail() {
+	cart := checkoutCart(availableLine("productID1", 2))
+
+	suite.mockTransaction()
+	suite.mockRepo.On("GetCartByUserID", mock.Anything, "userID").Return(cart, nil).Times(1)
+	suite.mockRepo.On("ClaimCart", mock.Anything, cart).Return(true, nil).Times(1)
+	suite.mockOrderService.On("PlaceOrder", mock.Anything, mock.Anything).
+		Return(&orderModel.Order{ID: "orderId1"}, nil).Times(1)
+	suite.mockRepo.On("Update", mock.Anything, mock.Anything).Return(errors.New("error")).Times(1)
+
+	order, err := suite.service.Checkout(context.Background(), "userID")
+	suite.Nil(order)
+	suite.NotNil(err)
+}
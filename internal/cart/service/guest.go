@@ -0,0 +1,68 @@
+package service
+
+import (
+	"context"
+
+	"github.com/quangdangfit/gocommon/logger"
+
+	"goshop/internal/cart/model"
+)
+
+// GetGuestCart returns the cart of the guest identified by guestToken
+func (p *CartService) GetGuestCart(ctx context.Context, guestToken string) (*model.Cart, error) {
+	cart, err := p.guestRepo.GetGuestCart(ctx, guestToken)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := p.loadProducts(ctx, cart); err != nil {
+		return nil, err
+	}
+
+	return cart, nil
+}
+
+// updateGuestCart applies change to the cart of the guest and saves it
+func (p *CartService) updateGuestCart(
+	ctx context.Context,
+	guestToken string,
+	change func(cart *model.Cart) error,
+) (*model.Cart, error) {
+	cart, err := p.guestRepo.GetGuestCart(ctx, guestToken)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := change(cart); err != nil {
+		return nil, err
+	}
+
+	if err := p.guestRepo.SaveGuestCart(ctx, guestToken, cart); err != nil {
+		logger.Errorf("SaveGuestCart fail, error: %s", err)
+		return nil, err
+	}
+
+	if err := p.loadProducts(ctx, cart); err != nil {
+		return nil, err
+	}
+
+	return cart, nil
+}
+
+// loadProducts sets the products of the lines of a guest cart, which only keeps their ids
+func (p *CartService) loadProducts(ctx context.Context, cart *model.Cart) error {
+	if len(cart.Lines) == 0 {
+		return nil
+	}
+
+	products, err := productsOf(ctx, p.repo, cart)
+	if err != nil {
+		return err
+	}
+
+	for _, line := range cart.Lines {
+		line.Product = products[line.ProductID]
+	}
+
+	return nil
+}
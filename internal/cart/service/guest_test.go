@@ -0,0 +1,109 @@
+package service
+
+import (
+	"context"
+	"errors"
+
+	"github.com/stretchr/testify/mock"
+
+	"goshop/internal/cart/dto"
+	"goshop/internal/cart/model"
+)
+
+// Guest carts
+// =================================================================
+
+func (suite *CartServiceTestSuite) TestGetGuestCartSuccessfully() {
+	suite.mockGuestRepo.On("GetGuestCart", mock.Anything, "guestToken").
+		Return(&model.Cart{Lines: []*model.CartLine{{ProductID: "productID1", Quantity: 2}}}, nil).Times(1)
+	suite.mockRepo.On("GetProductsByIDs", mock.Anything, []string{"productID1"}).
+		Return([]*model.Product{{ID: "productID1", Name: "product"}}, nil).Times(1)
+
+	cart, err := suite.service.GetGuestCart(context.Background(), "guestToken")
+	suite.Nil(err)
+	suite.Equal(1, len(cart.Lines))
+	suite.Equal("product", cart.Lines[0].Product.Name)
+}
+
+func (suite *CartServiceTestSuite) TestGetGuestCartFail() {
+	suite.mockGuestRepo.On("GetGuestCart", mock.Anything, "guestToken").
+		Return(nil, errors.New("error")).Times(1)
+
+	cart, err := suite.service.GetGuestCart(context.Background(), "guestToken")
+	suite.Nil(cart)
+	suite.NotNil(err)
+}
+
+func (suite *CartServiceTestSuite) TestAddProductGuestSuccessfully() {
+	req := &dto.AddProductReq{
+		GuestToken: "guestToken",
+		Line: &dto.CartLineReq{
+			ProductID: "productID2",
+			Quantity:  3,
+		},
+	}
+
+	suite.mockGuestRepo.On("GetGuestCart", mock.Anything, "guestToken").
+		Return(&model.Cart{Lines: []*model.CartLine{{ProductID: "productID1", Quantity: 2}}}, nil).Times(1)
+	suite.mockGuestRepo.On("SaveGuestCart", mock.Anything, "guestToken", mock.MatchedBy(func(cart *model.Cart) bool {
+		return len(cart.Lines) == 2 && cart.Lines[1].ProductID == "productID2" && cart.Lines[1].Quantity == 3
+	})).Return(nil).Times(1)
+	suite.mockRepo.On("GetProductsByIDs", mock.Anything, []string{"productID1", "productID2"}).
+		Return([]*model.Product{{ID: "productID1"}, {ID: "productID2"}}, nil).Times(1)
+
+	cart, err := suite.service.AddProduct(context.Background(), req)
+	suite.Nil(err)
+	suite.Equal(2, len(cart.Lines))
+	suite.NotNil(cart.Lines[1].Product)
+}
+
+func (suite *CartServiceTestSuite) TestAddProductGuestSaveFail() {
+	req := &dto.AddProductReq{
+		GuestToken: "guestToken",
+		Line: &dto.CartLineReq{
+			ProductID: "productID2",
+			Quantity:  3,
+		},
+	}
+
+	suite.mockGuestRepo.On("GetGuestCart", mock.Anything, "guestToken").
+		Return(&model.Cart{Lines: []*model.CartLine{}}, nil).Times(1)
+	suite.mockGuestRepo.On("SaveGuestCart", mock.Anything, "guestToken", mock.Anything).
+		Return(errors.New("error")).Times(1)
+
+	cart, err := suite.service.AddProduct(context.Background(), req)
+	suite.Nil(cart)
+	suite.NotNil(err)
+}
+
+func (suite *CartServiceTestSuite) TestRemoveProductGuestSuccessfully() {
+	req := &dto.RemoveProductReq{
+		GuestToken: "guestToken",
+		ProductID:  "productID1",
+	}
+
+	suite.mockGuestRepo.On("GetGuestCart", mock.Anything, "guestToken").
+		Return(&model.Cart{Lines: []*model.CartLine{{ProductID: "productID1", Quantity: 2}}}, nil).Times(1)
+	suite.mockGuestRepo.On("SaveGuestCart", mock.Anything, "guestToken", mock.MatchedBy(func(cart *model.Cart) bool {
+		return len(cart.Lines) == 0
+	})).Return(nil).Times(1)
+
+	cart, err := suite.service.RemoveProduct(context.Background(), req)
+	suite.Nil(err)
+	suite.Equal(0, len(cart.Lines))
+}
+
+func (suite *CartServiceTestSuite) TestUpdateQuantityGuestProductNotInCart() {
+	req := &dto.UpdateQuantityReq{
+		GuestToken: "guestToken",
+		ProductID:  "productID2",
+		Quantity:   5,
+	}
+
+	suite.mockGuestRepo.On("GetGuestCart", mock.Anything, "guestToken").
+		Return(&model.Cart{Lines: []*model.CartLine{{ProductID: "productID1", Quantity: 2}}}, nil).Times(1)
+
+	cart, err := suite.service.UpdateQuantity(context.Background(), req)
+	suite.Nil(cart)
+	suite.ErrorIs(err, ErrProductNotInCart)
+}
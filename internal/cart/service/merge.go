@@ -0,0 +1,142 @@
+package service
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/quangdangfit/gocommon/logger"
+
+	"goshop/internal/cart/model"
+	"goshop/internal/cart/repository"
+)
+
+// MergePolicy decides the quantity of a product found in both carts merged
+type MergePolicy string
+
+const (
+	// MergePolicySum adds the quantities of both carts
+	MergePolicySum MergePolicy = "sum"
+	// MergePolicyMax keeps the larger quantity
+	MergePolicyMax MergePolicy = "max"
+	// MergePolicyGuest keeps the quantity of the guest cart
+	MergePolicyGuest MergePolicy = "guest"
+)
+
+func ParseMergePolicy(text string) (MergePolicy, error) {
+	switch policy := MergePolicy(text); policy {
+	case MergePolicySum, MergePolicyMax, MergePolicyGuest:
+		return policy, nil
+	}
+
+	return "", fmt.Errorf("invalid cart merge policy: %q", text)
+}
+
+func (policy MergePolicy) quantity(user, guest uint) uint {
+	switch policy {
+	case MergePolicyMax:
+		if guest > user {
+			return guest
+		}
+		return user
+	case MergePolicyGuest:
+		return guest
+	default:
+		return user + guest
+	}
+}
+
+// GuestCartMerger moves guest carts into the carts of users signing in
+type GuestCartMerger struct {
+	repo      repository.ICartRepository
+	guestRepo repository.IGuestCartRepository
+	policy    MergePolicy
+}
+
+func NewGuestCartMerger(
+	repo repository.ICartRepository,
+	guestRepo repository.IGuestCartRepository,
+	policy MergePolicy,
+) *GuestCartMerger {
+	return &GuestCartMerger{
+		repo:      repo,
+		guestRepo: guestRepo,
+		policy:    policy,
+	}
+}
+
+// MergeGuestCart adds the lines of the guest cart to the cart of the user, following the merge
+// policy for products in both, then deletes the guest cart. Lines of products or variants that
+// no longer exist are dropped. Nothing happens without guestToken.
+func (m *GuestCartMerger) MergeGuestCart(ctx context.Context, userID, guestToken string) error {
+	if guestToken == "" {
+		return nil
+	}
+
+	guestCart, err := m.guestRepo.GetGuestCart(ctx, guestToken)
+	if err != nil {
+		return err
+	}
+	if len(guestCart.Lines) == 0 {
+		return nil
+	}
+
+	products, err := productsOf(ctx, m.repo, guestCart)
+	if err != nil {
+		return err
+	}
+
+	cart, err := m.repo.GetCartByUserID(ctx, userID)
+	create := err != nil
+	if create {
+		cart = &model.Cart{UserID: userID}
+	}
+
+	for _, guestLine := range guestCart.Lines {
+		product := products[guestLine.ProductID]
+		if product == nil || (guestLine.VariantID != "" && product.Variant(guestLine.VariantID) == nil) {
+			continue
+		}
+
+		if line := cart.Line(guestLine.ProductID, guestLine.VariantID); line != nil {
+			line.Quantity = m.policy.quantity(line.Quantity, guestLine.Quantity)
+		} else {
+			cart.Lines = append(cart.Lines, &model.CartLine{
+				ProductID: guestLine.ProductID,
+				VariantID: guestLine.VariantID,
+				Quantity:  guestLine.Quantity,
+			})
+		}
+	}
+
+	if create {
+		err = m.repo.Create(ctx, cart)
+	} else {
+		err = m.repo.Update(ctx, cart)
+	}
+	if err != nil {
+		logger.Errorf("MergeGuestCart fail, userID: %s, error: %s", userID, err)
+		return err
+	}
+
+	return m.guestRepo.DeleteGuestCart(ctx, guestToken)
+}
+
+// productsOf returns the existing products of the cart lines by id
+func productsOf(ctx context.Context, repo repository.ICartRepository, cart *model.Cart) (map[string]*model.Product, error) {
+	ids := make([]string, 0, len(cart.Lines))
+	for _, line := range cart.Lines {
+		ids = append(ids, line.ProductID)
+	}
+
+	products, err := repo.GetProductsByIDs(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+
+	productMap := make(map[string]*model.Product, len(products))
+	for _, product := range products {
+		productMap[product.ID] = product
+	}
+
+	return productMap, nil
+}
@@ -0,0 +1,130 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	"goshop/internal/cart/model"
+	"goshop/internal/cart/repository/mocks"
+)
+
+func TestParseMergePolicy(t *testing.T) {
+	policy, err := ParseMergePolicy("max")
+	assert.Nil(t, err)
+	assert.Equal(t, MergePolicyMax, policy)
+
+	_, err = ParseMergePolicy("min")
+	assert.NotNil(t, err)
+}
+
+func TestMergePolicyQuantity(t *testing.T) {
+	assert.Equal(t, uint(5), MergePolicySum.quantity(2, 3))
+	assert.Equal(t, uint(5), MergePolicy("").quantity(2, 3))
+	assert.Equal(t, uint(3), MergePolicyMax.quantity(2, 3))
+	assert.Equal(t, uint(4), MergePolicyMax.quantity(4, 3))
+	assert.Equal(t, uint(1), MergePolicyGuest.quantity(4, 1))
+}
+
+func TestMergeGuestCartIntoExistingCart(t *testing.T) {
+	mockRepo := mocks.NewICartRepository(t)
+	mockGuestRepo := mocks.NewIGuestCartRepository(t)
+	mockGuestRepo.On("GetGuestCart", mock.Anything, "guestToken").
+		Return(&model.Cart{Lines: []*model.CartLine{
+			{ProductID: "productID1", Quantity: 2},
+			{ProductID: "productID2", Quantity: 1},
+			{ProductID: "deletedID", Quantity: 1},
+		}}, nil).Times(1)
+	mockRepo.On("GetProductsByIDs", mock.Anything, []string{"productID1", "productID2", "deletedID"}).
+		Return([]*model.Product{{ID: "productID1"}, {ID: "productID2"}}, nil).Times(1)
+	mockRepo.On("GetCartByUserID", mock.Anything, "userID").
+		Return(&model.Cart{ID: "cartID", UserID: "userID", Lines: []*model.CartLine{
+			{ProductID: "productID1", Quantity: 3},
+		}}, nil).Times(1)
+	mockRepo.On("Update", mock.Anything, mock.MatchedBy(func(cart *model.Cart) bool {
+		return len(cart.Lines) == 2 &&
+			cart.Lines[0].ProductID == "productID1" && cart.Lines[0].Quantity == 5 &&
+			cart.Lines[1].ProductID == "productID2" && cart.Lines[1].Quantity == 1
+	})).Return(nil).Times(1)
+	mockGuestRepo.On("DeleteGuestCart", mock.Anything, "guestToken").Return(nil).Times(1)
+
+	merger := NewGuestCartMerger(mockRepo, mockGuestRepo, MergePolicySum)
+	err := merger.MergeGuestCart(context.Background(), "userID", "guestToken")
+	assert.Nil(t, err)
+}
+
+func TestMergeGuestCartWithVariants(t *testing.T) {
+	mockRepo := mocks.NewICartRepository(t)
+	mockGuestRepo := mocks.NewIGuestCartRepository(t)
+	mockGuestRepo.On("GetGuestCart", mock.Anything, "guestToken").
+		Return(&model.Cart{Lines: []*model.CartLine{
+			{ProductID: "productID1", VariantID: "variantS", Quantity: 2},
+			{ProductID: "productID1", VariantID: "variantM", Quantity: 1},
+			{ProductID: "productID1", VariantID: "deletedID", Quantity: 1},
+		}}, nil).Times(1)
+	mockRepo.On("GetProductsByIDs", mock.Anything, mock.Anything).
+		Return([]*model.Product{{ID: "productID1", Variants: []*model.ProductVariant{{ID: "variantS"}, {ID: "variantM"}}}}, nil).Times(1)
+	mockRepo.On("GetCartByUserID", mock.Anything, "userID").
+		Return(&model.Cart{ID: "cartID", UserID: "userID", Lines: []*model.CartLine{
+			{ProductID: "productID1", VariantID: "variantS", Quantity: 3},
+		}}, nil).Times(1)
+	mockRepo.On("Update", mock.Anything, mock.MatchedBy(func(cart *model.Cart) bool {
+		return len(cart.Lines) == 2 &&
+			cart.Lines[0].VariantID == "variantS" && cart.Lines[0].Quantity == 3 &&
+			cart.Lines[1].VariantID == "variantM" && cart.Lines[1].Quantity == 1
+	})).Return(nil).Times(1)
+	mockGuestRepo.On("DeleteGuestCart", mock.Anything, "guestToken").Return(nil).Times(1)
+
+	merger := NewGuestCartMerger(mockRepo, mockGuestRepo, MergePolicyMax)
+	err := merger.MergeGuestCart(context.Background(), "userID", "guestToken")
+	assert.Nil(t, err)
+}
+
+func TestMergeGuestCartCreatesCart(t *testing.T) {
+	mockRepo := mocks.NewICartRepository(t)
+	mockGuestRepo := mocks.NewIGuestCartRepository(t)
+	mockGuestRepo.On("GetGuestCart", mock.Anything, "guestToken").
+		Return(&model.Cart{Lines: []*model.CartLine{{ProductID: "productID1", Quantity: 2}}}, nil).Times(1)
+	mockRepo.On("GetProductsByIDs", mock.Anything, []string{"productID1"}).
+		Return([]*model.Product{{ID: "productID1"}}, nil).Times(1)
+	mockRepo.On("GetCartByUserID", mock.Anything, "userID").
+		Return(nil, errors.New("record not found")).Times(1)
+	mockRepo.On("Create", mock.Anything, mock.MatchedBy(func(cart *model.Cart) bool {
+		return cart.UserID == "userID" && len(cart.Lines) == 1 && cart.Lines[0].Quantity == 2
+	})).Return(nil).Times(1)
+	mockGuestRepo.On("DeleteGuestCart", mock.Anything, "guestToken").Return(nil).Times(1)
+
+	merger := NewGuestCartMerger(mockRepo, mockGuestRepo, MergePolicyMax)
+	err := merger.MergeGuestCart(context.Background(), "userID", "guestToken")
+	assert.Nil(t, err)
+}
+
+func TestMergeGuestCartEmpty(t *testing.T) {
+	mockRepo := mocks.NewICartRepository(t)
+	mockGuestRepo := mocks.NewIGuestCartRepository(t)
+	mockGuestRepo.On("GetGuestCart", mock.Anything, "guestToken").
+		Return(&model.Cart{Lines: []*model.CartLine{}}, nil).Times(1)
+
+	merger := NewGuestCartMerger(mockRepo, mockGuestRepo, MergePolicySum)
+	assert.Nil(t, merger.MergeGuestCart(context.Background(), "userID", "guestToken"))
+	assert.Nil(t, merger.MergeGuestCart(context.Background(), "userID", ""))
+}
+
+func TestMergeGuestCartUpdateFail(t *testing.T) {
+	mockRepo := mocks.NewICartRepository(t)
+	mockGuestRepo := mocks.NewIGuestCartRepository(t)
+	mockGuestRepo.On("GetGuestCart", mock.Anything, "guestToken").
+		Return(&model.Cart{Lines: []*model.CartLine{{ProductID: "productID1", Quantity: 2}}}, nil).Times(1)
+	mockRepo.On("GetProductsByIDs", mock.Anything, mock.Anything).
+		Return([]*model.Product{{ID: "productID1"}}, nil).Times(1)
+	mockRepo.On("GetCartByUserID", mock.Anything, "userID").
+		Return(&model.Cart{ID: "cartID", UserID: "userID"}, nil).Times(1)
+	mockRepo.On("Update", mock.Anything, mock.Anything).Return(errors.New("error")).Times(1)
+
+	merger := NewGuestCartMerger(mockRepo, mockGuestRepo, MergePolicyGuest)
+	err := merger.MergeGuestCart(context.Background(), "userID", "guestToken")
+	assert.NotNil(t, err)
+}
@@ -9,6 +9,8 @@ import (
 	mock "github.com/stretchr/testify/mock"
 
 	model "goshop/internal/cart/model"
+
+	ordermodel "goshop/internal/order/model"
 )
 
 // ICartService is an autogenerated mock type for the ICartService type
@@ -42,6 +44,32 @@ func (_m *ICartService) AddProduct(ctx context.Context, req *dto.AddProductReq)
 	return r0, r1
 }
 
+// Checkout provides a mock function with given fields: ctx, userID
+func (_m *ICartService) Checkout(ctx context.Context, userID string) (*ordermodel.Order, error) {
+	ret := _m.Called(ctx, userID)
+
+	var r0 *ordermodel.Order
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) (*ordermodel.Order, error)); ok {
+		return rf(ctx, userID)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string) *ordermodel.Order); ok {
+		r0 = rf(ctx, userID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*ordermodel.Order)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, userID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // GetCartByUserID provides a mock function with given fields: ctx, userID
 func (_m *ICartService) GetCartByUserID(ctx context.Context, userID string) (*model.Cart, error) {
 	ret := _m.Called(ctx, userID)
@@ -68,6 +96,32 @@ func (_m *ICartService) GetCartByUserID(ctx context.Context, userID string) (*mo
 	return r0, r1
 }
 
+// GetGuestCart provides a mock function with given fields: ctx, guestToken
+func (_m *ICartService) GetGuestCart(ctx context.Context, guestToken string) (*model.Cart, error) {
+	ret := _m.Called(ctx, guestToken)
+
+	var r0 *model.Cart
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) (*model.Cart, error)); ok {
+		return rf(ctx, guestToken)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string) *model.Cart); ok {
+		r0 = rf(ctx, guestToken)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*model.Cart)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, guestToken)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // RemoveProduct provides a mock function with given fields: ctx, req
 func (_m *ICartService) RemoveProduct(ctx context.Context, req *dto.RemoveProductReq) (*model.Cart, error) {
 	ret := _m.Called(ctx, req)
@@ -94,6 +148,32 @@ func (_m *ICartService) RemoveProduct(ctx context.Context, req *dto.RemoveProduc
 	return r0, r1
 }
 
+// UpdateQuantity provides a mock function with given fields: ctx, req
+func (_m *ICartService) UpdateQuantity(ctx context.Context, req *dto.UpdateQuantityReq) (*model.Cart, error) {
+	ret := _m.Called(ctx, req)
+
+	var r0 *model.Cart
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, *dto.UpdateQuantityReq) (*model.Cart, error)); ok {
+		return rf(ctx, req)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, *dto.UpdateQuantityReq) *model.Cart); ok {
+		r0 = rf(ctx, req)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*model.Cart)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, *dto.UpdateQuantityReq) error); ok {
+		r1 = rf(ctx, req)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // NewICartService creates a new instance of ICartService. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
 // The first argument is typically a *testing.T value.
 func NewICartService(t interface {
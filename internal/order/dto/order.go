@@ -1,39 +1,73 @@
 package dto
 
 import (
+	"time"
+
+	"goshop/pkg/money"
 	"goshop/pkg/paging"
 )
 
 type Order struct {
-	ID         string       `json:"id"`
-	Code       string       `json:"code"`
-	Lines      []*OrderLine `json:"lines"`
-	TotalPrice float64      `json:"total_price"`
-	Status     string       `json:"status"`
+	ID            string           `json:"id"`
+	Code          string           `json:"code"`
+	Lines         []*OrderLine     `json:"lines"`
+	Discount      money.Money      `json:"discount"`
+	Discounts     []*OrderDiscount `json:"discounts,omitempty"`
+	TotalPrice    money.Money      `json:"total_price"`
+	Status        string           `json:"status"`
+	PaymentStatus string           `json:"payment_status"`
 }
 
 type OrderLine struct {
-	Product  Product `json:"product,omitempty"`
-	Quantity uint    `json:"quantity"`
-	Price    float64 `json:"price"`
+	Product  Product     `json:"product,omitempty"`
+	Variant  *Variant    `json:"variant,omitempty"`
+	Quantity uint        `json:"quantity"`
+	Price    money.Money `json:"price"`
+	Discount money.Money `json:"discount"`
+}
+
+type OrderDiscount struct {
+	PromotionID string      `json:"promotion_id"`
+	Code        string      `json:"code"`
+	Amount      money.Money `json:"amount"`
 }
 
 type PlaceOrderReq struct {
 	UserID string              `json:"user_id" validate:"required"`
 	Lines  []PlaceOrderLineReq `json:"lines,omitempty" validate:"required,gt=0,lte=5,dive"`
+	// CouponCodes are the codes of the promotions to apply, in order
+	CouponCodes []string `json:"coupon_codes,omitempty" validate:"lte=5,dive,required"`
 }
 
 type PlaceOrderLineReq struct {
 	ProductID string `json:"product_id,omitempty" validate:"required"`
+	// VariantID is required for products with variants
+	VariantID string `json:"variant_id,omitempty"`
 	Quantity  uint   `json:"quantity,omitempty" validate:"required"`
 }
 
+type UpdateOrderStatusReq struct {
+	Status string `json:"status" validate:"required,oneof=in-progress done cancelled"`
+	Note   string `json:"note,omitempty"`
+}
+
+type OrderStatusHistory struct {
+	FromStatus string    `json:"from_status,omitempty"`
+	ToStatus   string    `json:"to_status"`
+	ChangedBy  string    `json:"changed_by,omitempty"`
+	Note       string    `json:"note,omitempty"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
 type ListOrderReq struct {
-	UserID    string `json:"-"`
-	Code      string `json:"code,omitempty" form:"code"`
-	Status    string `json:"status,omitempty" form:"status"`
-	Page      int64  `json:"-" form:"page"`
-	Limit     int64  `json:"-" form:"limit"`
+	UserID string `json:"-"`
+	Code   string `json:"code,omitempty" form:"code"`
+	Status string `json:"status,omitempty" form:"status"`
+	// Cursor is the next_cursor or prev_cursor of a page, it replaces Page
+	Cursor string `json:"-" form:"cursor"`
+	Page   int64  `json:"-" form:"page"`
+	Limit  int64  `json:"-" form:"limit"`
+	// OrderBy is one of created_at, updated_at and total_price
 	OrderBy   string `json:"-" form:"order_by"`
 	OrderDesc bool   `json:"-" form:"order_desc"`
 }
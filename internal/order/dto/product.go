@@ -1,8 +1,17 @@
 package dto
 
+import (
+	"goshop/pkg/money"
+)
+
 type Product struct {
-	ID    string  `json:"id"`
-	Code  string  `json:"code"`
-	Name  string  `json:"name"`
-	Price float64 `json:"price"`
+	ID    string      `json:"id"`
+	Code  string      `json:"code"`
+	Name  string      `json:"name"`
+	Price money.Money `json:"price"`
+}
+
+type Variant struct {
+	ID  string `json:"id"`
+	SKU string `json:"sku"`
 }
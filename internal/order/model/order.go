@@ -6,6 +6,7 @@ import (
 	"github.com/google/uuid"
 	"gorm.io/gorm"
 
+	"goshop/pkg/money"
 	"goshop/pkg/utils"
 )
 
@@ -19,16 +20,21 @@ const (
 )
 
 type Order struct {
-	ID         string     `json:"id" gorm:"unique;not null;index;primary_key"`
-	CreatedAt  time.Time  `json:"created_at"`
-	UpdatedAt  time.Time  `json:"updated_at"`
-	DeletedAt  *time.Time `json:"deleted_at" gorm:"index"`
-	Code       string     `json:"code"`
-	UserID     string     `json:"user_id"`
-	User       *User
-	Lines      []*OrderLine `json:"lines"`
-	TotalPrice float64      `json:"total_price"`
-	Status     OrderStatus  `json:"status"`
+	ID        string     `json:"id" gorm:"unique;not null;index;primary_key"`
+	CreatedAt time.Time  `json:"created_at"`
+	UpdatedAt time.Time  `json:"updated_at"`
+	DeletedAt *time.Time `json:"deleted_at" gorm:"index"`
+	Code      string     `json:"code"`
+	UserID    string     `json:"user_id"`
+	User      *User
+	Lines     []*OrderLine `json:"lines"`
+	// Discount is the sum of the discounts of the lines, already taken off TotalPrice
+	Discount   money.Money      `json:"discount" gorm:"embedded;embeddedPrefix:discount_"`
+	Discounts  []*OrderDiscount `json:"discounts"`
+	TotalPrice money.Money      `json:"total_price" gorm:"embedded;embeddedPrefix:total_price_"`
+	Status     OrderStatus      `json:"status"`
+	// PaymentStatus is maintained by the payment module, it is never written by order updates
+	PaymentStatus string `json:"payment_status" gorm:"<-:create;default:unpaid"`
 }
 
 func (order *Order) BeforeCreate(tx *gorm.DB) error {
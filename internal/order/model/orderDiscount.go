@@ -0,0 +1,25 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+
+	"goshop/pkg/money"
+)
+
+// OrderDiscount is the amount a promotion took off an order
+type OrderDiscount struct {
+	ID          string      `json:"id" gorm:"unique;not null;index;primary_key"`
+	CreatedAt   time.Time   `json:"created_at"`
+	OrderID     string      `json:"order_id" gorm:"not null;index"`
+	PromotionID string      `json:"promotion_id"`
+	Code        string      `json:"code"`
+	Amount      money.Money `json:"amount" gorm:"embedded"`
+}
+
+func (discount *OrderDiscount) BeforeCreate(tx *gorm.DB) error {
+	discount.ID = uuid.New().String()
+	return nil
+}
@@ -5,6 +5,8 @@ import (
 
 	"github.com/google/uuid"
 	"gorm.io/gorm"
+
+	"goshop/pkg/money"
 )
 
 type OrderLine struct {
@@ -15,8 +17,13 @@ type OrderLine struct {
 	OrderID   string     `json:"order_id"`
 	ProductID string     `json:"product_id"`
 	Product   *Product
-	Quantity  uint    `json:"quantity"`
-	Price     float64 `json:"price"`
+	// VariantID is the variant of the product ordered, empty for products without variants
+	VariantID string          `json:"variant_id,omitempty"`
+	Variant   *ProductVariant `json:"variant,omitempty"`
+	Quantity  uint            `json:"quantity"`
+	Price     money.Money     `json:"price" gorm:"embedded;embeddedPrefix:price_"`
+	// Discount is taken off Price by the promotions of the order
+	Discount money.Money `json:"discount" gorm:"embedded;embeddedPrefix:discount_"`
 }
 
 func (line *OrderLine) BeforeCreate(tx *gorm.DB) error {
@@ -0,0 +1,28 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// OrderStatusHistory records a change of the status of an order
+type OrderStatusHistory struct {
+	ID         string      `json:"id" gorm:"unique;not null;index;primary_key"`
+	CreatedAt  time.Time   `json:"created_at"`
+	OrderID    string      `json:"order_id" gorm:"not null;index"`
+	FromStatus OrderStatus `json:"from_status"`
+	ToStatus   OrderStatus `json:"to_status" gorm:"not null"`
+	ChangedBy  string      `json:"changed_by"`
+	Note       string      `json:"note"`
+}
+
+func (OrderStatusHistory) TableName() string {
+	return "order_status_history"
+}
+
+func (h *OrderStatusHistory) BeforeCreate(tx *gorm.DB) error {
+	h.ID = uuid.New().String()
+	return nil
+}
@@ -2,16 +2,64 @@ package model
 
 import (
 	"time"
+
+	"goshop/pkg/money"
 )
 
 type Product struct {
-	ID          string     `json:"id" gorm:"unique;not null;index;primary_key"`
-	CreatedAt   time.Time  `json:"created_at"`
-	UpdatedAt   time.Time  `json:"updated_at"`
-	DeletedAt   *time.Time `json:"deleted_at" gorm:"index"`
-	Code        string     `json:"code" gorm:"uniqueIndex:idx_product_code,not null"`
-	Name        string     `json:"name" gorm:"uniqueIndex:idx_product_name,not null"`
-	Description string     `json:"description"`
-	Price       float64    `json:"price"`
-	Active      bool       `json:"active" gorm:"default:true"`
+	ID          string      `json:"id" gorm:"unique;not null;index;primary_key"`
+	CreatedAt   time.Time   `json:"created_at"`
+	UpdatedAt   time.Time   `json:"updated_at"`
+	DeletedAt   *time.Time  `json:"deleted_at" gorm:"index"`
+	Code        string      `json:"code" gorm:"uniqueIndex:idx_product_code,not null"`
+	Name        string      `json:"name" gorm:"uniqueIndex:idx_product_name,not null"`
+	Description string      `json:"description"`
+	Price       money.Money `json:"price" gorm:"embedded;embeddedPrefix:price_"`
+	Active      bool        `json:"active" gorm:"default:true"`
+	// Categories are only loaded to check the scope of promotions
+	Categories []*ProductCategory `json:"categories,omitempty" gorm:"foreignKey:ProductID"`
+	Variants   []*ProductVariant  `json:"variants,omitempty" gorm:"foreignKey:ProductID"`
+}
+
+// ProductVariant is a variant of a product, ordered instead of the product when it has variants
+type ProductVariant struct {
+	ID        string `json:"id" gorm:"unique;not null;index;primary_key"`
+	ProductID string `json:"product_id"`
+	SKU       string `json:"sku" gorm:"column:sku"`
+	// PriceOverride is the price of the variant, the product price is used when it is zero
+	PriceOverride money.Money `json:"-" gorm:"embedded;embeddedPrefix:price_"`
+	Active        bool        `json:"active"`
+}
+
+// ProductCategory assigns a product to a category
+type ProductCategory struct {
+	ProductID  string `json:"product_id" gorm:"primaryKey"`
+	CategoryID string `json:"category_id" gorm:"primaryKey"`
+}
+
+// Variant returns the variant of the product with id, nil when there is none
+func (p *Product) Variant(id string) *ProductVariant {
+	for _, variant := range p.Variants {
+		if variant.ID == id {
+			return variant
+		}
+	}
+	return nil
+}
+
+// VariantPrice returns the unit price of variant of the product
+func (p *Product) VariantPrice(variant *ProductVariant) money.Money {
+	if variant.PriceOverride.IsZero() {
+		return p.Price
+	}
+	return variant.PriceOverride
+}
+
+// CategoryIDs returns the ids of the categories of the product
+func (p *Product) CategoryIDs() []string {
+	ids := make([]string, 0, len(p.Categories))
+	for _, category := range p.Categories {
+		ids = append(ids, category.CategoryID)
+	}
+	return ids
 }
@@ -0,0 +1,48 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type ProductStock struct {
+	ProductID string `gorm:"primary_key"`
+	UpdatedAt time.Time
+	OnHand    int64
+	Reserved  int64
+}
+
+type VariantStock struct {
+	VariantID string `gorm:"primary_key"`
+	UpdatedAt time.Time
+	OnHand    int64
+	Reserved  int64
+}
+
+type StockMovementType string
+
+const (
+	StockMovementReservation StockMovementType = "reservation"
+	StockMovementRelease     StockMovementType = "release"
+	StockMovementCommit      StockMovementType = "commit"
+)
+
+const StockReasonOrder = "order"
+
+type StockMovement struct {
+	ID        string `gorm:"unique;not null;index;primary_key"`
+	CreatedAt time.Time
+	ProductID string
+	VariantID string
+	OrderID   string
+	Type      StockMovementType
+	Reason    string
+	Quantity  int64
+}
+
+func (m *StockMovement) BeforeCreate(tx *gorm.DB) error {
+	m.ID = uuid.New().String()
+	return nil
+}
@@ -8,7 +8,11 @@ import (
 	"github.com/quangdangfit/gocommon/logger"
 
 	"goshop/internal/order/dto"
+	"goshop/internal/order/repository"
 	"goshop/internal/order/service"
+	promotionService "goshop/internal/promotion/service"
+	"goshop/pkg/paging"
+	"goshop/pkg/rbac"
 	"goshop/pkg/response"
 	"goshop/pkg/utils"
 )
@@ -49,6 +53,16 @@ func (a *OrderHandler) PlaceOrder(c *gin.Context) {
 	order, err := a.service.PlaceOrder(c, &req)
 	if err != nil {
 		logger.Error("Failed to create OrderHandler: ", err.Error())
+		if errors.Is(err, service.ErrEmailNotVerified) {
+			response.Error(c, http.StatusForbidden, err, "Email not verified")
+			return
+		}
+		if errors.Is(err, repository.ErrInsufficientStock) || errors.Is(err, repository.ErrProductInactive) ||
+			errors.Is(err, repository.ErrVariantRequired) || errors.Is(err, repository.ErrVariantNotFound) ||
+			isCouponError(err) {
+			response.Error(c, http.StatusBadRequest, err, err.Error())
+			return
+		}
 		response.Error(c, http.StatusInternalServerError, err, "Something went wrong")
 		return
 	}
@@ -58,6 +72,15 @@ func (a *OrderHandler) PlaceOrder(c *gin.Context) {
 	response.JSON(c, http.StatusOK, res)
 }
 
+// isCouponError reports whether err is a coupon code the order cannot use
+func isCouponError(err error) bool {
+	return errors.Is(err, promotionService.ErrPromotionNotFound) ||
+		errors.Is(err, promotionService.ErrPromotionInactive) ||
+		errors.Is(err, promotionService.ErrPromotionNotApplicable) ||
+		errors.Is(err, promotionService.ErrMinOrderValue) ||
+		errors.Is(err, promotionService.ErrUsageLimitReached)
+}
+
 // GetOrders godoc
 //
 //	@Summary	get my orders
@@ -84,6 +107,10 @@ func (a *OrderHandler) GetOrders(c *gin.Context) {
 	orders, pagination, err := a.service.GetMyOrders(c, &req)
 	if err != nil {
 		logger.Error("Failed to get orders: ", err)
+		if errors.Is(err, paging.ErrInvalidCursor) {
+			response.Error(c, http.StatusBadRequest, err, err.Error())
+			return
+		}
 		response.Error(c, http.StatusInternalServerError, err, "Something went wrong")
 		return
 	}
@@ -152,6 +179,50 @@ func (a *OrderHandler) CancelOrder(c *gin.Context) {
 	order, err := a.service.CancelOrder(c, orderID, userID)
 	if err != nil {
 		logger.Errorf("Failed to cancel order, id: %s, error: %s", orderID, err)
+		if errors.Is(err, service.ErrInvalidTransition) {
+			response.Error(c, http.StatusBadRequest, err, err.Error())
+			return
+		}
+		response.Error(c, http.StatusInternalServerError, err, "Something went wrong")
+		return
+	}
+
+	var res dto.Order
+	utils.Copy(&res, &order)
+	response.JSON(c, http.StatusOK, res)
+}
+
+// UpdateOrderStatus godoc
+//
+//	@Summary	move order to another status
+//	@Tags		orders
+//	@Produce	json
+//	@Security	ApiKeyAuth
+//	@Param		id	path		string						true	"Order ID"
+//	@Param		_	body		dto.UpdateOrderStatusReq	true	"Body"
+//	@Success	200	{object}	dto.Order
+//	@Router		/api/v1/orders/{id}/status [put]
+func (a *OrderHandler) UpdateOrderStatus(c *gin.Context) {
+	orderID := c.Param("id")
+	if orderID == "" {
+		response.Error(c, http.StatusBadRequest, errors.New("bad request"), "Miss Order ID")
+		return
+	}
+
+	var req dto.UpdateOrderStatusReq
+	if err := c.ShouldBindJSON(&req); err != nil {
+		logger.Error("Failed to get body", err)
+		response.Error(c, http.StatusBadRequest, err, "Invalid parameters")
+		return
+	}
+
+	order, err := a.service.UpdateStatus(c, orderID, c.GetString("userId"), &req)
+	if err != nil {
+		logger.Errorf("Failed to update order status, id: %s, error: %s", orderID, err)
+		if errors.Is(err, service.ErrInvalidTransition) {
+			response.Error(c, http.StatusBadRequest, err, err.Error())
+			return
+		}
 		response.Error(c, http.StatusInternalServerError, err, "Something went wrong")
 		return
 	}
@@ -160,3 +231,42 @@ func (a *OrderHandler) CancelOrder(c *gin.Context) {
 	utils.Copy(&res, &order)
 	response.JSON(c, http.StatusOK, res)
 }
+
+// GetOrderHistory godoc
+//
+//	@Summary	get order status history
+//	@Tags		orders
+//	@Produce	json
+//	@Security	ApiKeyAuth
+//	@Param		id	path		string	true	"Order ID"
+//	@Success	200	{object}	[]dto.OrderStatusHistory
+//	@Router		/api/v1/orders/{id}/history [get]
+func (a *OrderHandler) GetOrderHistory(c *gin.Context) {
+	userID := c.GetString("userId")
+	if userID == "" {
+		response.Error(c, http.StatusUnauthorized, errors.New("unauthorized"), "Unauthorized")
+		return
+	}
+
+	orderID := c.Param("id")
+	if orderID == "" {
+		response.Error(c, http.StatusBadRequest, errors.New("bad request"), "Miss Order ID")
+		return
+	}
+
+	// Users managing orders may see the history of every order
+	if rbac.GetPolicy().Can(c.GetString("role"), rbac.PermissionOrderManage) {
+		userID = ""
+	}
+
+	history, err := a.service.GetStatusHistory(c, orderID, userID)
+	if err != nil {
+		logger.Errorf("Failed to get order history, id: %s, error: %s", orderID, err)
+		response.Error(c, http.StatusNotFound, err, "Not found")
+		return
+	}
+
+	var res []*dto.OrderStatusHistory
+	utils.Copy(&res, &history)
+	response.JSON(c, http.StatusOK, res)
+}
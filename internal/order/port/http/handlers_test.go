@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"encoding/json"
 	"errors"
+	"fmt"
 	"net/http"
 	"net/http/httptest"
 	"net/url"
@@ -16,9 +17,13 @@ import (
 
 	"goshop/internal/order/dto"
 	"goshop/internal/order/model"
+	"goshop/internal/order/repository"
+	"goshop/internal/order/service"
 	"goshop/internal/order/service/mocks"
 	productMocks "goshop/internal/product/service/mocks"
+	promotionService "goshop/internal/promotion/service"
 	"goshop/pkg/config"
+	"goshop/pkg/money"
 	"goshop/pkg/paging"
 	"goshop/pkg/response"
 	"goshop/pkg/utils"
@@ -79,7 +84,7 @@ func (suite *OrderHandlerTestSuite) TestOrderAPI_PlaceOrderSuccess() {
 			&model.Order{
 				ID:         "orderId1",
 				Code:       "orderCode1",
-				TotalPrice: 8,
+				TotalPrice: money.New(800, "USD"),
 				Status:     model.OrderStatusNew,
 				Lines: []*model.OrderLine{
 					{
@@ -103,7 +108,7 @@ func (suite *OrderHandlerTestSuite) TestOrderAPI_PlaceOrderSuccess() {
 	_ = json.Unmarshal(writer.Body.Bytes(), &res)
 	utils.Copy(&orderRes, &res.Result)
 	suite.Equal(http.StatusOK, writer.Code)
-	suite.Equal(float64(8), orderRes.TotalPrice)
+	suite.Equal(money.New(800, "USD"), orderRes.TotalPrice)
 	suite.Equal(string(model.OrderStatusNew), orderRes.Status)
 	suite.Equal(2, len(orderRes.Lines))
 }
@@ -201,6 +206,82 @@ func (suite *OrderHandlerTestSuite) TestOrderAPI_PlaceOrderFail() {
 	suite.Equal("Something went wrong", res["error"]["message"])
 }
 
+func (suite *OrderHandlerTestSuite) TestOrderAPI_PlaceOrderInsufficientStock() {
+	req := &dto.PlaceOrderReq{
+		Lines: []dto.PlaceOrderLineReq{
+			{
+				ProductID: "productId1",
+				Quantity:  2,
+			},
+		},
+	}
+
+	ctx, writer := suite.prepareContext(req)
+	ctx.Set("userId", "123456")
+	req.UserID = "123456"
+
+	suite.mockService.On("PlaceOrder", mock.Anything, req).
+		Return(nil, fmt.Errorf("%w: product productId1", repository.ErrInsufficientStock)).Times(1)
+
+	suite.handler.PlaceOrder(ctx)
+
+	var res map[string]map[string]string
+	_ = json.Unmarshal(writer.Body.Bytes(), &res)
+	suite.Equal(http.StatusBadRequest, writer.Code)
+	suite.Equal("insufficient stock: product productId1", res["error"]["message"])
+}
+
+func (suite *OrderHandlerTestSuite) TestOrderAPI_PlaceOrderEmailNotVerified() {
+	req := &dto.PlaceOrderReq{
+		Lines: []dto.PlaceOrderLineReq{
+			{
+				ProductID: "productId1",
+				Quantity:  2,
+			},
+		},
+	}
+
+	ctx, writer := suite.prepareContext(req)
+	ctx.Set("userId", "123456")
+	req.UserID = "123456"
+
+	suite.mockService.On("PlaceOrder", mock.Anything, req).
+		Return(nil, service.ErrEmailNotVerified).Times(1)
+
+	suite.handler.PlaceOrder(ctx)
+
+	var res map[string]map[string]string
+	_ = json.Unmarshal(writer.Body.Bytes(), &res)
+	suite.Equal(http.StatusForbidden, writer.Code)
+	suite.Equal("Email not verified", res["error"]["message"])
+}
+
+func (suite *OrderHandlerTestSuite) TestOrderAPI_PlaceOrderInvalidCoupon() {
+	req := &dto.PlaceOrderReq{
+		Lines: []dto.PlaceOrderLineReq{
+			{
+				ProductID: "productId1",
+				Quantity:  2,
+			},
+		},
+		CouponCodes: []string{"expired"},
+	}
+
+	ctx, writer := suite.prepareContext(req)
+	ctx.Set("userId", "123456")
+	req.UserID = "123456"
+
+	suite.mockService.On("PlaceOrder", mock.Anything, req).
+		Return(nil, fmt.Errorf("%w: EXPIRED", promotionService.ErrPromotionInactive)).Times(1)
+
+	suite.handler.PlaceOrder(ctx)
+
+	var res map[string]map[string]string
+	_ = json.Unmarshal(writer.Body.Bytes(), &res)
+	suite.Equal(http.StatusBadRequest, writer.Code)
+	suite.Equal("promotion is not active: EXPIRED", res["error"]["message"])
+}
+
 // Get Order Detail
 // =================================================================================================
 
@@ -214,7 +295,7 @@ func (suite *OrderHandlerTestSuite) TestOrderAPI_GetOrderByIDSuccess() {
 			&model.Order{
 				ID:         "orderId1",
 				UserID:     "123456",
-				TotalPrice: 5,
+				TotalPrice: money.New(500, "USD"),
 				Status:     model.OrderStatusNew,
 			},
 			nil,
@@ -228,7 +309,7 @@ func (suite *OrderHandlerTestSuite) TestOrderAPI_GetOrderByIDSuccess() {
 	_ = json.Unmarshal(writer.Body.Bytes(), &res)
 	utils.Copy(&orderRes, &res.Result)
 	suite.Equal(http.StatusOK, writer.Code)
-	suite.Equal(float64(5), orderRes.TotalPrice)
+	suite.Equal(money.New(500, "USD"), orderRes.TotalPrice)
 	suite.Equal(string(model.OrderStatusNew), orderRes.Status)
 	suite.Equal(0, len(orderRes.Lines))
 }
@@ -286,7 +367,7 @@ func (suite *OrderHandlerTestSuite) TestOrderAPI_GetMyOrdersSuccess() {
 				{
 					ID:         "orderId1",
 					UserID:     "123456",
-					TotalPrice: 5,
+					TotalPrice: money.New(500, "USD"),
 					Status:     model.OrderStatusNew,
 				},
 			},
@@ -307,7 +388,7 @@ func (suite *OrderHandlerTestSuite) TestOrderAPI_GetMyOrdersSuccess() {
 	suite.Equal(http.StatusOK, writer.Code)
 	suite.Equal(1, len(orderRes.Orders))
 	suite.Equal("orderId1", orderRes.Orders[0].ID)
-	suite.Equal(float64(5), orderRes.Orders[0].TotalPrice)
+	suite.Equal(money.New(500, "USD"), orderRes.Orders[0].TotalPrice)
 	suite.Equal(string(model.OrderStatusNew), orderRes.Orders[0].Status)
 }
 
@@ -349,6 +430,18 @@ func (suite *OrderHandlerTestSuite) TestOrderAPI_GetMyOrdersFail() {
 	suite.NotNil(res.Error)
 }
 
+func (suite *OrderHandlerTestSuite) TestOrderAPI_GetMyOrdersInvalidCursor() {
+	ctx, writer := suite.prepareContext(nil)
+	ctx.Set("userId", "123456")
+	ctx.Request.URL, _ = url.Parse("?cursor=abc")
+
+	suite.mockService.On("GetMyOrders", mock.Anything, &dto.ListOrderReq{UserID: "123456", Cursor: "abc"}).
+		Return(nil, nil, paging.ErrInvalidCursor).Times(1)
+
+	suite.handler.GetOrders(ctx)
+	suite.Equal(http.StatusBadRequest, writer.Code)
+}
+
 // CancelOrder
 // =================================================================================================
 
@@ -362,7 +455,7 @@ func (suite *OrderHandlerTestSuite) TestOrderAPI_CancelOrderSuccess() {
 			&model.Order{
 				ID:         "orderId1",
 				UserID:     "123456",
-				TotalPrice: 5,
+				TotalPrice: money.New(500, "USD"),
 				Status:     model.OrderStatusNew,
 			},
 			nil,
@@ -377,7 +470,7 @@ func (suite *OrderHandlerTestSuite) TestOrderAPI_CancelOrderSuccess() {
 	utils.Copy(&orderRes, &res.Result)
 	suite.Equal(http.StatusOK, writer.Code)
 	suite.Equal("orderId1", orderRes.ID)
-	suite.Equal(float64(5), orderRes.TotalPrice)
+	suite.Equal(money.New(500, "USD"), orderRes.TotalPrice)
 	suite.Equal(string(model.OrderStatusNew), orderRes.Status)
 }
 
@@ -420,3 +513,127 @@ func (suite *OrderHandlerTestSuite) TestOrderAPI_CancelOrderFail() {
 	suite.Equal(http.StatusInternalServerError, writer.Code)
 	suite.NotNil(res.Error)
 }
+
+// UpdateOrderStatus
+// =================================================================================================
+
+func (suite *OrderHandlerTestSuite) TestOrderAPI_UpdateOrderStatusSuccess() {
+	req := &dto.UpdateOrderStatusReq{Status: "done", Note: "delivered"}
+	ctx, writer := suite.prepareContext(req)
+	ctx.Set("userId", "adminId")
+	ctx.AddParam("id", "orderId1")
+
+	suite.mockService.On("UpdateStatus", mock.Anything, "orderId1", "adminId", req).
+		Return(&model.Order{ID: "orderId1", Status: model.OrderStatusDone}, nil).Times(1)
+
+	suite.handler.UpdateOrderStatus(ctx)
+
+	var res response.Response
+	var orderRes dto.Order
+
+	_ = json.Unmarshal(writer.Body.Bytes(), &res)
+	utils.Copy(&orderRes, &res.Result)
+	suite.Equal(http.StatusOK, writer.Code)
+	suite.Equal("orderId1", orderRes.ID)
+	suite.Equal(string(model.OrderStatusDone), orderRes.Status)
+}
+
+func (suite *OrderHandlerTestSuite) TestOrderAPI_UpdateOrderStatusMissID() {
+	ctx, writer := suite.prepareContext(&dto.UpdateOrderStatusReq{Status: "done"})
+
+	suite.handler.UpdateOrderStatus(ctx)
+	suite.Equal(http.StatusBadRequest, writer.Code)
+}
+
+func (suite *OrderHandlerTestSuite) TestOrderAPI_UpdateOrderStatusInvalidFieldType() {
+	ctx, writer := suite.prepareContext(map[string]interface{}{"status": 1})
+	ctx.AddParam("id", "orderId1")
+
+	suite.handler.UpdateOrderStatus(ctx)
+	suite.Equal(http.StatusBadRequest, writer.Code)
+}
+
+func (suite *OrderHandlerTestSuite) TestOrderAPI_UpdateOrderStatusInvalidTransition() {
+	req := &dto.UpdateOrderStatusReq{Status: "done"}
+	ctx, writer := suite.prepareContext(req)
+	ctx.AddParam("id", "orderId1")
+
+	suite.mockService.On("UpdateStatus", mock.Anything, "orderId1", mock.Anything, req).
+		Return(nil, fmt.Errorf("%w: new to done", service.ErrInvalidTransition)).Times(1)
+
+	suite.handler.UpdateOrderStatus(ctx)
+	suite.Equal(http.StatusBadRequest, writer.Code)
+}
+
+func (suite *OrderHandlerTestSuite) TestOrderAPI_UpdateOrderStatusFail() {
+	req := &dto.UpdateOrderStatusReq{Status: "done"}
+	ctx, writer := suite.prepareContext(req)
+	ctx.AddParam("id", "orderId1")
+
+	suite.mockService.On("UpdateStatus", mock.Anything, "orderId1", mock.Anything, req).
+		Return(nil, errors.New("error")).Times(1)
+
+	suite.handler.UpdateOrderStatus(ctx)
+	suite.Equal(http.StatusInternalServerError, writer.Code)
+}
+
+// GetOrderHistory
+// =================================================================================================
+
+func (suite *OrderHandlerTestSuite) TestOrderAPI_GetOrderHistorySuccess() {
+	ctx, writer := suite.prepareContext(nil)
+	ctx.Set("userId", "123456")
+	ctx.Set("role", "customer")
+	ctx.AddParam("id", "orderId1")
+
+	suite.mockService.On("GetStatusHistory", mock.Anything, "orderId1", "123456").
+		Return([]*model.OrderStatusHistory{
+			{OrderID: "orderId1", ToStatus: model.OrderStatusNew, ChangedBy: "123456"},
+			{OrderID: "orderId1", FromStatus: model.OrderStatusNew, ToStatus: model.OrderStatusCancelled, ChangedBy: "123456"},
+		}, nil).Times(1)
+
+	suite.handler.GetOrderHistory(ctx)
+
+	var res response.Response
+	var history []*dto.OrderStatusHistory
+
+	_ = json.Unmarshal(writer.Body.Bytes(), &res)
+	utils.Copy(&history, &res.Result)
+	suite.Equal(http.StatusOK, writer.Code)
+	suite.Equal(2, len(history))
+	suite.Equal("new", history[1].FromStatus)
+	suite.Equal("cancelled", history[1].ToStatus)
+}
+
+func (suite *OrderHandlerTestSuite) TestOrderAPI_GetOrderHistoryAsAdmin() {
+	ctx, writer := suite.prepareContext(nil)
+	ctx.Set("userId", "adminId")
+	ctx.Set("role", "admin")
+	ctx.AddParam("id", "orderId1")
+
+	suite.mockService.On("GetStatusHistory", mock.Anything, "orderId1", "").
+		Return([]*model.OrderStatusHistory{}, nil).Times(1)
+
+	suite.handler.GetOrderHistory(ctx)
+	suite.Equal(http.StatusOK, writer.Code)
+}
+
+func (suite *OrderHandlerTestSuite) TestOrderAPI_GetOrderHistoryUnauthorized() {
+	ctx, writer := suite.prepareContext(nil)
+	ctx.AddParam("id", "orderId1")
+
+	suite.handler.GetOrderHistory(ctx)
+	suite.Equal(http.StatusUnauthorized, writer.Code)
+}
+
+func (suite *OrderHandlerTestSuite) TestOrderAPI_GetOrderHistoryFail() {
+	ctx, writer := suite.prepareContext(nil)
+	ctx.Set("userId", "123456")
+	ctx.AddParam("id", "orderId1")
+
+	suite.mockService.On("GetStatusHistory", mock.Anything, "orderId1", "123456").
+		Return(nil, errors.New("error")).Times(1)
+
+	suite.handler.GetOrderHistory(ctx)
+	suite.Equal(http.StatusNotFound, writer.Code)
+}
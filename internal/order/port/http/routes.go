@@ -6,23 +6,36 @@ import (
 
 	"goshop/internal/order/repository"
 	"goshop/internal/order/service"
+	promotionRepository "goshop/internal/promotion/repository"
+	promotionService "goshop/internal/promotion/service"
+	userService "goshop/internal/user/service"
+	"goshop/pkg/config"
 	"goshop/pkg/dbs"
+	"goshop/pkg/idempotency"
 	"goshop/pkg/middleware"
+	"goshop/pkg/rbac"
 )
 
-func Routes(r *gin.RouterGroup, db dbs.IDatabase, validator validation.Validation) {
+func Routes(r *gin.RouterGroup, db dbs.IDatabase, validator validation.Validation, idempotencyStore idempotency.Store) {
 	productRepo := repository.NewProductRepository(db)
 	orderRepo := repository.NewOrderRepository(db)
-	productSvc := service.NewOrderService(validator, orderRepo, productRepo)
-	orderHandler := NewOrderHandler(productSvc)
+	promotionSvc := promotionService.NewPromotionService(validator, promotionRepository.NewPromotionRepository(db))
+	orderSvc := service.NewOrderService(validator, db, orderRepo, productRepo, promotionSvc)
+	if userService.VerificationPolicy(config.GetConfig().EmailVerificationPolicy) == userService.VerificationPolicyOrder {
+		orderSvc.RequireVerifiedEmail(repository.NewUserRepository(db))
+	}
+	orderHandler := NewOrderHandler(orderSvc)
 
 	authMiddleware := middleware.JWTAuth()
+	idempotencyMiddleware := middleware.Idempotency(idempotencyStore)
 
-	orderRoute := r.Group("/orders", authMiddleware)
+	orderRoute := r.Group("/orders", authMiddleware, idempotencyMiddleware)
 	{
-		orderRoute.POST("", orderHandler.PlaceOrder)
-		orderRoute.GET("/:id", orderHandler.GetOrderByID)
-		orderRoute.GET("", orderHandler.GetOrders)
-		orderRoute.PUT("/:id/cancel", orderHandler.CancelOrder)
+		orderRoute.POST("", middleware.RequirePermission(rbac.PermissionOrderWrite), orderHandler.PlaceOrder)
+		orderRoute.GET("/:id", middleware.RequirePermission(rbac.PermissionOrderRead), orderHandler.GetOrderByID)
+		orderRoute.GET("", middleware.RequirePermission(rbac.PermissionOrderRead), orderHandler.GetOrders)
+		orderRoute.PUT("/:id/cancel", middleware.RequirePermission(rbac.PermissionOrderWrite), orderHandler.CancelOrder)
+		orderRoute.GET("/:id/history", middleware.RequirePermission(rbac.PermissionOrderRead), orderHandler.GetOrderHistory)
+		orderRoute.PUT("/:id/status", middleware.RequirePermission(rbac.PermissionOrderManage), orderHandler.UpdateOrderStatus)
 	}
 }
@@ -7,9 +7,10 @@ import (
 	"github.com/quangdangfit/gocommon/validation"
 
 	dbMocks "goshop/pkg/dbs/mocks"
+	idempotencyMocks "goshop/pkg/idempotency/mocks"
 )
 
 func TestRoutes(t *testing.T) {
 	mockDB := dbMocks.NewIDatabase(t)
-	Routes(gin.New().Group("/"), mockDB, validation.New())
+	Routes(gin.New().Group("/"), mockDB, validation.New(), idempotencyMocks.NewStore(t))
 }
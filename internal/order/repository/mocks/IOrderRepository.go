@@ -18,25 +18,25 @@ type IOrderRepository struct {
 	mock.Mock
 }
 
-// CreateOrder provides a mock function with given fields: ctx, userID, lines
-func (_m *IOrderRepository) CreateOrder(ctx context.Context, userID string, lines []*model.OrderLine) (*model.Order, error) {
-	ret := _m.Called(ctx, userID, lines)
+// CreateOrder provides a mock function with given fields: ctx, userID, lines, discounts
+func (_m *IOrderRepository) CreateOrder(ctx context.Context, userID string, lines []*model.OrderLine, discounts []*model.OrderDiscount) (*model.Order, error) {
+	ret := _m.Called(ctx, userID, lines, discounts)
 
 	var r0 *model.Order
 	var r1 error
-	if rf, ok := ret.Get(0).(func(context.Context, string, []*model.OrderLine) (*model.Order, error)); ok {
-		return rf(ctx, userID, lines)
+	if rf, ok := ret.Get(0).(func(context.Context, string, []*model.OrderLine, []*model.OrderDiscount) (*model.Order, error)); ok {
+		return rf(ctx, userID, lines, discounts)
 	}
-	if rf, ok := ret.Get(0).(func(context.Context, string, []*model.OrderLine) *model.Order); ok {
-		r0 = rf(ctx, userID, lines)
+	if rf, ok := ret.Get(0).(func(context.Context, string, []*model.OrderLine, []*model.OrderDiscount) *model.Order); ok {
+		r0 = rf(ctx, userID, lines, discounts)
 	} else {
 		if ret.Get(0) != nil {
 			r0 = ret.Get(0).(*model.Order)
 		}
 	}
 
-	if rf, ok := ret.Get(1).(func(context.Context, string, []*model.OrderLine) error); ok {
-		r1 = rf(ctx, userID, lines)
+	if rf, ok := ret.Get(1).(func(context.Context, string, []*model.OrderLine, []*model.OrderDiscount) error); ok {
+		r1 = rf(ctx, userID, lines, discounts)
 	} else {
 		r1 = ret.Error(1)
 	}
@@ -44,6 +44,20 @@ func (_m *IOrderRepository) CreateOrder(ctx context.Context, userID string, line
 	return r0, r1
 }
 
+// CreateStatusHistory provides a mock function with given fields: ctx, history
+func (_m *IOrderRepository) CreateStatusHistory(ctx context.Context, history *model.OrderStatusHistory) error {
+	ret := _m.Called(ctx, history)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *model.OrderStatusHistory) error); ok {
+		r0 = rf(ctx, history)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // GetMyOrders provides a mock function with given fields: ctx, req
 func (_m *IOrderRepository) GetMyOrders(ctx context.Context, req *dto.ListOrderReq) ([]*model.Order, *paging.Pagination, error) {
 	ret := _m.Called(ctx, req)
@@ -105,6 +119,32 @@ func (_m *IOrderRepository) GetOrderByID(ctx context.Context, id string, preload
 	return r0, r1
 }
 
+// GetStatusHistory provides a mock function with given fields: ctx, orderID
+func (_m *IOrderRepository) GetStatusHistory(ctx context.Context, orderID string) ([]*model.OrderStatusHistory, error) {
+	ret := _m.Called(ctx, orderID)
+
+	var r0 []*model.OrderStatusHistory
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) ([]*model.OrderStatusHistory, error)); ok {
+		return rf(ctx, orderID)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string) []*model.OrderStatusHistory); ok {
+		r0 = rf(ctx, orderID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*model.OrderStatusHistory)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, orderID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // UpdateOrder provides a mock function with given fields: ctx, order
 func (_m *IOrderRepository) UpdateOrder(ctx context.Context, order *model.Order) error {
 	ret := _m.Called(ctx, order)
@@ -119,6 +159,30 @@ func (_m *IOrderRepository) UpdateOrder(ctx context.Context, order *model.Order)
 	return r0
 }
 
+// UpdateOrderStatus provides a mock function with given fields: ctx, orderID, from, to
+func (_m *IOrderRepository) UpdateOrderStatus(ctx context.Context, orderID string, from model.OrderStatus, to model.OrderStatus) (bool, error) {
+	ret := _m.Called(ctx, orderID, from, to)
+
+	var r0 bool
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, model.OrderStatus, model.OrderStatus) (bool, error)); ok {
+		return rf(ctx, orderID, from, to)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, model.OrderStatus, model.OrderStatus) bool); ok {
+		r0 = rf(ctx, orderID, from, to)
+	} else {
+		r0 = ret.Get(0).(bool)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, model.OrderStatus, model.OrderStatus) error); ok {
+		r1 = rf(ctx, orderID, from, to)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // NewIOrderRepository creates a new instance of IOrderRepository. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
 // The first argument is typically a *testing.T value.
 func NewIOrderRepository(t interface {
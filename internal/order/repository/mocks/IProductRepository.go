@@ -14,6 +14,20 @@ type IProductRepository struct {
 	mock.Mock
 }
 
+// CommitStock provides a mock function with given fields: ctx, orderID, lines
+func (_m *IProductRepository) CommitStock(ctx context.Context, orderID string, lines []*model.OrderLine) error {
+	ret := _m.Called(ctx, orderID, lines)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, []*model.OrderLine) error); ok {
+		r0 = rf(ctx, orderID, lines)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // GetProductByID provides a mock function with given fields: ctx, id
 func (_m *IProductRepository) GetProductByID(ctx context.Context, id string) (*model.Product, error) {
 	ret := _m.Called(ctx, id)
@@ -40,6 +54,34 @@ func (_m *IProductRepository) GetProductByID(ctx context.Context, id string) (*m
 	return r0, r1
 }
 
+// ReleaseStock provides a mock function with given fields: ctx, orderID, lines
+func (_m *IProductRepository) ReleaseStock(ctx context.Context, orderID string, lines []*model.OrderLine) error {
+	ret := _m.Called(ctx, orderID, lines)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, []*model.OrderLine) error); ok {
+		r0 = rf(ctx, orderID, lines)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// ReserveStock provides a mock function with given fields: ctx, orderID, lines
+func (_m *IProductRepository) ReserveStock(ctx context.Context, orderID string, lines []*model.OrderLine) error {
+	ret := _m.Called(ctx, orderID, lines)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, []*model.OrderLine) error); ok {
+		r0 = rf(ctx, orderID, lines)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // NewIProductRepository creates a new instance of IProductRepository. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
 // The first argument is typically a *testing.T value.
 func NewIProductRepository(t interface {
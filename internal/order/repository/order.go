@@ -2,20 +2,26 @@ package repository
 
 import (
 	"context"
+	"strconv"
+	"time"
 
 	"goshop/internal/order/dto"
 	"goshop/internal/order/model"
 	"goshop/pkg/dbs"
+	"goshop/pkg/money"
 	"goshop/pkg/paging"
 	"goshop/pkg/utils"
 )
 
 //go:generate mockery --name=IOrderRepository
 type IOrderRepository interface {
-	CreateOrder(ctx context.Context, userID string, lines []*model.OrderLine) (*model.Order, error)
+	CreateOrder(ctx context.Context, userID string, lines []*model.OrderLine, discounts []*model.OrderDiscount) (*model.Order, error)
 	GetOrderByID(ctx context.Context, id string, preload bool) (*model.Order, error)
 	GetMyOrders(ctx context.Context, req *dto.ListOrderReq) ([]*model.Order, *paging.Pagination, error)
 	UpdateOrder(ctx context.Context, order *model.Order) error
+	UpdateOrderStatus(ctx context.Context, orderID string, from, to model.OrderStatus) (bool, error)
+	CreateStatusHistory(ctx context.Context, history *model.OrderStatusHistory) error
+	GetStatusHistory(ctx context.Context, orderID string) ([]*model.OrderStatusHistory, error)
 }
 
 type OrderRepo struct {
@@ -26,21 +32,35 @@ func NewOrderRepository(db dbs.IDatabase) *OrderRepo {
 	return &OrderRepo{db: db}
 }
 
-func (r *OrderRepo) CreateOrder(ctx context.Context, userID string, lines []*model.OrderLine) (*model.Order, error) {
+// CreateOrder creates the order of lines, whose discounts are taken off the total price
+func (r *OrderRepo) CreateOrder(ctx context.Context, userID string, lines []*model.OrderLine, discounts []*model.OrderDiscount) (*model.Order, error) {
 	order := new(model.Order)
 
-	var totalPrice float64
+	prices := make([]money.Money, 0, len(lines))
+	lineDiscounts := make([]money.Money, 0, len(lines))
 	for _, line := range lines {
-		totalPrice += line.Price
+		prices = append(prices, line.Price)
+		lineDiscounts = append(lineDiscounts, line.Discount)
 	}
-	order.TotalPrice = totalPrice
+	totalPrice, err := money.Sum(prices...)
+	if err != nil {
+		return nil, err
+	}
+	discount, err := money.Sum(lineDiscounts...)
+	if err != nil {
+		return nil, err
+	}
+	if order.TotalPrice, err = totalPrice.Sub(discount); err != nil {
+		return nil, err
+	}
+	order.Discount = discount
 	order.UserID = userID
 
-	handler := func() error {
-		return r.createOrder(ctx, order, lines)
+	handler := func(ctx context.Context) error {
+		return r.createOrder(ctx, order, lines, discounts)
 	}
 
-	err := r.db.WithTransaction(handler)
+	err = r.db.WithTransaction(ctx, handler)
 	if err != nil {
 		return nil, err
 	}
@@ -48,7 +68,7 @@ func (r *OrderRepo) CreateOrder(ctx context.Context, userID string, lines []*mod
 	return order, nil
 }
 
-func (r *OrderRepo) createOrder(ctx context.Context, order *model.Order, lines []*model.OrderLine) error {
+func (r *OrderRepo) createOrder(ctx context.Context, order *model.Order, lines []*model.OrderLine, discounts []*model.OrderDiscount) error {
 	// Create Order
 	if err := r.db.Create(ctx, order); err != nil {
 		return err
@@ -63,6 +83,18 @@ func (r *OrderRepo) createOrder(ctx context.Context, order *model.Order, lines [
 	}
 
 	utils.Copy(&order.Lines, &lines)
+
+	if len(discounts) == 0 {
+		return nil
+	}
+	for _, discount := range discounts {
+		discount.OrderID = order.ID
+	}
+	if err := r.db.CreateInBatches(ctx, &discounts, len(discounts)); err != nil {
+		return err
+	}
+
+	order.Discounts = discounts
 	return nil
 }
 
@@ -72,7 +104,7 @@ func (r *OrderRepo) GetOrderByID(ctx context.Context, id string, preload bool) (
 		dbs.WithQuery(dbs.NewQuery("id = ?", id)),
 	}
 	if preload {
-		opts = append(opts, dbs.WithPreload([]string{"Lines", "Lines.Product"}))
+		opts = append(opts, dbs.WithPreload([]string{"Lines", "Lines.Product", "Lines.Variant", "Discounts"}))
 	}
 
 	if err := r.db.FindOne(ctx, &order, opts...); err != nil {
@@ -93,12 +125,22 @@ func (r *OrderRepo) GetMyOrders(ctx context.Context, req *dto.ListOrderReq) ([]*
 		query = append(query, dbs.NewQuery("status = ?", req.Status))
 	}
 
-	order := "created_at"
-	if req.OrderBy != "" {
-		order = req.OrderBy
-		if req.OrderDesc {
-			order += " DESC"
+	column, sort := "created_at", "created_at"
+	if c, ok := orderSortColumns[req.OrderBy]; ok {
+		column, sort = c, req.OrderBy
+	}
+	position := dbs.Cursor{Column: column, Desc: req.OrderBy != "" && req.OrderDesc}
+	if position.Desc {
+		sort += " desc"
+	}
+
+	var cursor *paging.Cursor
+	if req.Cursor != "" {
+		var err error
+		if cursor, err = paging.DecodeCursor(req.Cursor, sort); err != nil {
+			return nil, nil, err
 		}
+		position.Key, position.ID, position.Before = cursor.Key, cursor.ID, cursor.Before
 	}
 
 	var total int64
@@ -107,23 +149,83 @@ func (r *OrderRepo) GetMyOrders(ctx context.Context, req *dto.ListOrderReq) ([]*
 	}
 
 	pagination := paging.New(req.Page, req.Limit, total)
+	opts := []dbs.FindOption{
+		dbs.WithPreload([]string{"Lines", "Lines.Product", "Lines.Variant", "Discounts"}),
+		dbs.WithQuery(query...),
+		dbs.WithLimit(int(pagination.Limit) + 1),
+		dbs.WithCursor(position),
+	}
+	if cursor != nil {
+		pagination = paging.NewCursor(req.Limit, total)
+	} else {
+		opts = append(opts, dbs.WithOffset(int(pagination.Skip)))
+	}
 
 	var orders []*model.Order
-	if err := r.db.Find(
-		ctx,
-		&orders,
-		dbs.WithPreload([]string{"Lines", "Lines.Product"}),
-		dbs.WithQuery(query...),
-		dbs.WithLimit(int(pagination.Limit)),
-		dbs.WithOffset(int(pagination.Skip)),
-		dbs.WithOrder(order),
-	); err != nil {
+	if err := r.db.Find(ctx, &orders, opts...); err != nil {
 		return nil, nil, err
 	}
 
+	orders = paging.Page(pagination, orders, sort, cursor, func(order *model.Order) (string, string) {
+		return orderSortKey(order, column), order.ID
+	})
 	return orders, pagination, nil
 }
 
+// orderSortColumns are the columns orders can be sorted by, by their name in order_by
+var orderSortColumns = map[string]string{
+	"created_at":  "created_at",
+	"updated_at":  "updated_at",
+	"total_price": "total_price_amount",
+}
+
+// orderSortKey returns the value of the sort column of order, as cursors keep it
+func orderSortKey(order *model.Order, column string) string {
+	switch column {
+	case "updated_at":
+		return order.UpdatedAt.Format(time.RFC3339Nano)
+	case "total_price_amount":
+		return strconv.FormatInt(order.TotalPrice.Amount, 10)
+	default:
+		return order.CreatedAt.Format(time.RFC3339Nano)
+	}
+}
+
 func (r *OrderRepo) UpdateOrder(ctx context.Context, order *model.Order) error {
 	return r.db.Update(ctx, order)
 }
+
+// UpdateOrderStatus moves the order to status to, only when it still is in status from. It
+// reports whether the order was updated.
+func (r *OrderRepo) UpdateOrderStatus(ctx context.Context, orderID string, from, to model.OrderStatus) (bool, error) {
+	updated, err := r.db.UpdateColumns(
+		ctx,
+		&model.Order{},
+		map[string]any{"status": to},
+		dbs.WithQuery(dbs.NewQuery("id = ?", orderID), dbs.NewQuery("status = ?", from)),
+	)
+	if err != nil {
+		return false, err
+	}
+
+	return updated > 0, nil
+}
+
+func (r *OrderRepo) CreateStatusHistory(ctx context.Context, history *model.OrderStatusHistory) error {
+	return r.db.Create(ctx, history)
+}
+
+// GetStatusHistory returns the status changes of the order, oldest first
+func (r *OrderRepo) GetStatusHistory(ctx context.Context, orderID string) ([]*model.OrderStatusHistory, error) {
+	var history []*model.OrderStatusHistory
+	if err := r.db.Find(
+		ctx,
+		&history,
+		dbs.WithQuery(dbs.NewQuery("order_id = ?", orderID)),
+		dbs.WithOrder("created_at"),
+	); err != nil {
+		return nil, err
+	}
+
+	return history, nil
+}
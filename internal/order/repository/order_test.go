@@ -12,7 +12,10 @@ import (
 	"goshop/internal/order/dto"
 	"goshop/internal/order/model"
 	"goshop/pkg/config"
+	"goshop/pkg/dbs"
 	"goshop/pkg/dbs/mocks"
+	"goshop/pkg/money"
+	"goshop/pkg/paging"
 )
 
 type OrderRepositoryTestSuite struct {
@@ -44,9 +47,9 @@ func (suite *OrderRepositoryTestSuite) TestCreateOrderSuccessfully() {
 		},
 	}
 
-	suite.mockDB.On("WithTransaction", mock.Anything).Return(nil).Times(1)
+	suite.mockDB.On("WithTransaction", mock.Anything, mock.Anything).Return(nil).Times(1)
 
-	order, err := suite.repo.CreateOrder(context.Background(), userID, orderLines)
+	order, err := suite.repo.CreateOrder(context.Background(), userID, orderLines, nil)
 	suite.NotNil(order)
 	suite.Nil(err)
 }
@@ -60,9 +63,91 @@ func (suite *OrderRepositoryTestSuite) TestCreateOrderFail() {
 		},
 	}
 
-	suite.mockDB.On("WithTransaction", mock.Anything).Return(errors.New("error")).Times(1)
+	suite.mockDB.On("WithTransaction", mock.Anything, mock.Anything).Return(errors.New("error")).Times(1)
 
-	order, err := suite.repo.CreateOrder(context.Background(), userID, orderLines)
+	order, err := suite.repo.CreateOrder(context.Background(), userID, orderLines, nil)
+	suite.Nil(order)
+	suite.NotNil(err)
+}
+
+func (suite *OrderRepositoryTestSuite) TestCreateOrderUsesTransactionContext() {
+	userID := "userID"
+	orderLines := []*model.OrderLine{
+		{
+			ProductID: "productID",
+			Quantity:  2,
+			Price:     money.New(1000, "USD"),
+		},
+	}
+
+	type txKey struct{}
+	txCtx := context.WithValue(context.Background(), txKey{}, "tx")
+	suite.mockDB.On("WithTransaction", mock.Anything, mock.Anything).
+		Return(func(ctx context.Context, function func(ctx context.Context) error) error {
+			return function(txCtx)
+		}).Times(1)
+	suite.mockDB.On("Create", txCtx, mock.Anything).Return(nil).Times(1)
+	suite.mockDB.On("CreateInBatches", txCtx, mock.Anything, 1).Return(nil).Times(1)
+
+	order, err := suite.repo.CreateOrder(context.Background(), userID, orderLines, nil)
+	suite.Nil(err)
+	suite.NotNil(order)
+	suite.Equal(money.New(1000, "USD"), order.TotalPrice)
+	suite.Equal(1, len(order.Lines))
+}
+
+func (suite *OrderRepositoryTestSuite) TestCreateOrderWithDiscounts() {
+	userID := "userID"
+	orderLines := []*model.OrderLine{
+		{
+			ProductID: "productID1",
+			Quantity:  2,
+			Price:     money.New(1000, "USD"),
+			Discount:  money.New(300, "USD"),
+		},
+		{
+			ProductID: "productID2",
+			Quantity:  1,
+			Price:     money.New(500, "USD"),
+		},
+	}
+	discounts := []*model.OrderDiscount{
+		{PromotionID: "promotionID", Code: "SAVE", Amount: money.New(300, "USD")},
+	}
+
+	suite.mockDB.On("WithTransaction", mock.Anything, mock.Anything).
+		Return(func(ctx context.Context, function func(ctx context.Context) error) error {
+			return function(ctx)
+		}).Times(1)
+	suite.mockDB.On("Create", mock.Anything, mock.Anything).Return(nil).Times(1)
+	suite.mockDB.On("CreateInBatches", mock.Anything, &orderLines, 2).Return(nil).Times(1)
+	suite.mockDB.On("CreateInBatches", mock.Anything, &discounts, 1).Return(nil).Times(1)
+
+	order, err := suite.repo.CreateOrder(context.Background(), userID, orderLines, discounts)
+	suite.Nil(err)
+	suite.NotNil(order)
+	suite.Equal(money.New(1200, "USD"), order.TotalPrice)
+	suite.Equal(money.New(300, "USD"), order.Discount)
+	suite.Equal(1, len(order.Discounts))
+}
+
+func (suite *OrderRepositoryTestSuite) TestCreateOrderCreateLinesFail() {
+	userID := "userID"
+	orderLines := []*model.OrderLine{
+		{
+			ProductID: "productID",
+			Quantity:  2,
+		},
+	}
+
+	suite.mockDB.On("WithTransaction", mock.Anything, mock.Anything).
+		Return(func(ctx context.Context, function func(ctx context.Context) error) error {
+			return function(ctx)
+		}).Times(1)
+	suite.mockDB.On("Create", mock.Anything, mock.Anything).Return(nil).Times(1)
+	suite.mockDB.On("CreateInBatches", mock.Anything, mock.Anything, 1).Return(errors.New("error")).Times(1)
+
+	order, err := suite.repo.CreateOrder(context.Background(), userID, orderLines, nil)
 	suite.Nil(order)
 	suite.NotNil(err)
 }
@@ -94,6 +179,44 @@ func (suite *OrderRepositoryTestSuite) TestUpdateOrderFail() {
 	suite.NotNil(err)
 }
 
+// UpdateOrderStatus
+// =================================================================
+
+func (suite *OrderRepositoryTestSuite) TestUpdateOrderStatusSuccessfully() {
+	suite.mockDB.On(
+		"UpdateColumns",
+		mock.Anything,
+		&model.Order{},
+		map[string]any{"status": model.OrderStatusDone},
+		dbs.WithQuery(dbs.NewQuery("id = ?", "orderId1"), dbs.NewQuery("status = ?", model.OrderStatusInProgress)),
+	).Return(int64(1), nil).Times(1)
+
+	updated, err := suite.repo.UpdateOrderStatus(context.Background(), "orderId1",
+		model.OrderStatusInProgress, model.OrderStatusDone)
+	suite.Nil(err)
+	suite.True(updated)
+}
+
+func (suite *OrderRepositoryTestSuite) TestUpdateOrderStatusChanged() {
+	suite.mockDB.On("UpdateColumns", mock.Anything, &model.Order{}, mock.Anything, mock.Anything).
+		Return(int64(0), nil).Times(1)
+
+	updated, err := suite.repo.UpdateOrderStatus(context.Background(), "orderId1",
+		model.OrderStatusInProgress, model.OrderStatusDone)
+	suite.Nil(err)
+	suite.False(updated)
+}
+
+func (suite *OrderRepositoryTestSuite) TestUpdateOrderStatusFail() {
+	suite.mockDB.On("UpdateColumns", mock.Anything, &model.Order{}, mock.Anything, mock.Anything).
+		Return(int64(0), errors.New("error")).Times(1)
+
+	updated, err := suite.repo.UpdateOrderStatus(context.Background(), "orderId1",
+		model.OrderStatusInProgress, model.OrderStatusDone)
+	suite.NotNil(err)
+	suite.False(updated)
+}
+
 // GetOrderByID
 // =================================================================
 
@@ -141,6 +264,50 @@ func (suite *OrderRepositoryTestSuite) TestListOrdersSuccessfully() {
 	suite.NotNil(pagination)
 }
 
+func (suite *OrderRepositoryTestSuite) TestListOrdersNextPageByCursor() {
+	cursor := paging.EncodeCursor(paging.Cursor{Sort: "total_price desc", Key: "500", ID: "orderId1"})
+	req := &dto.ListOrderReq{UserID: "userId", Cursor: cursor, Limit: 2, OrderBy: "total_price", OrderDesc: true}
+
+	suite.mockDB.On("Count", mock.Anything, mock.Anything, mock.Anything, mock.Anything).
+		Run(func(args mock.Arguments) {
+			*args.Get(2).(*int64) = 5
+		}).
+		Return(nil).Times(1)
+
+	suite.mockDB.On("Find", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything).
+		Run(func(args mock.Arguments) {
+			orders := args.Get(1).(*[]*model.Order)
+			*orders = []*model.Order{
+				{ID: "orderId2", TotalPrice: money.New(400, "USD")},
+				{ID: "orderId3", TotalPrice: money.New(300, "USD")},
+				{ID: "orderId4", TotalPrice: money.New(200, "USD")},
+			}
+		}).
+		Return(nil).Times(1)
+
+	orders, pagination, err := suite.repo.GetMyOrders(context.Background(), req)
+	suite.Nil(err)
+	suite.Equal(2, len(orders))
+	suite.Equal(int64(5), pagination.Total)
+	suite.Equal(int64(0), pagination.CurrentPage)
+
+	next, err := paging.DecodeCursor(pagination.NextCursor, "total_price desc")
+	suite.Nil(err)
+	suite.Equal(&paging.Cursor{Sort: "total_price desc", Key: "300", ID: "orderId3"}, next)
+	prev, err := paging.DecodeCursor(pagination.PrevCursor, "total_price desc")
+	suite.Nil(err)
+	suite.Equal("orderId2", prev.ID)
+}
+
+func (suite *OrderRepositoryTestSuite) TestListOrdersInvalidCursor() {
+	cursor := paging.EncodeCursor(paging.Cursor{Sort: "created_at", Key: "2024-01-02T00:00:00Z", ID: "orderId1"})
+
+	orders, pagination, err := suite.repo.GetMyOrders(context.Background(), &dto.ListOrderReq{Cursor: cursor, OrderBy: "updated_at"})
+	suite.ErrorIs(err, paging.ErrInvalidCursor)
+	suite.Nil(orders)
+	suite.Nil(pagination)
+}
+
 func (suite *OrderRepositoryTestSuite) TestListOrdersCountFail() {
 	suite.mockDB.On("Count", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything).
 		Return(errors.New("error")).Times(1)
@@ -163,3 +330,39 @@ func (suite *OrderRepositoryTestSuite) TestListOrdersFindFail() {
 	suite.Nil(orders)
 	suite.Nil(pagination)
 }
+
+// CreateStatusHistory
+// =================================================================
+
+func (suite *OrderRepositoryTestSuite) TestCreateStatusHistorySuccessfully() {
+	history := &model.OrderStatusHistory{
+		OrderID:    "orderId1",
+		FromStatus: model.OrderStatusNew,
+		ToStatus:   model.OrderStatusInProgress,
+	}
+	suite.mockDB.On("Create", mock.Anything, history).Return(nil).Times(1)
+
+	err := suite.repo.CreateStatusHistory(context.Background(), history)
+	suite.Nil(err)
+}
+
+// GetStatusHistory
+// =================================================================
+
+func (suite *OrderRepositoryTestSuite) TestGetStatusHistorySuccessfully() {
+	suite.mockDB.On("Find", mock.Anything, mock.Anything, mock.Anything, mock.Anything).
+		Return(nil).Times(1)
+
+	history, err := suite.repo.GetStatusHistory(context.Background(), "orderId1")
+	suite.Nil(err)
+	suite.Equal(0, len(history))
+}
+
+func (suite *OrderRepositoryTestSuite) TestGetStatusHistoryFail() {
+	suite.mockDB.On("Find", mock.Anything, mock.Anything, mock.Anything, mock.Anything).
+		Return(errors.New("error")).Times(1)
+
+	history, err := suite.repo.GetStatusHistory(context.Background(), "orderId1")
+	suite.NotNil(err)
+	suite.Nil(history)
+}
@@ -2,14 +2,26 @@ package repository
 
 import (
 	"context"
+	"errors"
+	"fmt"
 
 	"goshop/internal/order/model"
 	"goshop/pkg/dbs"
 )
 
+var (
+	ErrInsufficientStock = errors.New("insufficient stock")
+	ErrProductInactive   = errors.New("product is inactive")
+	ErrVariantRequired   = errors.New("product has variants, a variant must be chosen")
+	ErrVariantNotFound   = errors.New("variant not found")
+)
+
 //go:generate mockery --name=IProductRepository
 type IProductRepository interface {
 	GetProductByID(ctx context.Context, id string) (*model.Product, error)
+	ReserveStock(ctx context.Context, orderID string, lines []*model.OrderLine) error
+	ReleaseStock(ctx context.Context, orderID string, lines []*model.OrderLine) error
+	CommitStock(ctx context.Context, orderID string, lines []*model.OrderLine) error
 }
 
 type ProductRepo struct {
@@ -22,9 +34,111 @@ func NewProductRepository(db dbs.IDatabase) *ProductRepo {
 
 func (r *ProductRepo) GetProductByID(ctx context.Context, id string) (*model.Product, error) {
 	var product model.Product
-	if err := r.db.FindById(ctx, id, &product); err != nil {
+	opts := []dbs.FindOption{
+		dbs.WithQuery(dbs.NewQuery("id = ?", id)),
+		dbs.WithPreload([]string{"Categories", "Variants"}),
+	}
+	if err := r.db.FindOne(ctx, &product, opts...); err != nil {
 		return nil, err
 	}
 
 	return &product, nil
 }
+
+// ReserveStock reserves the quantity of every line, failing with ErrInsufficientStock
+// when a product does not have enough available stock. The stock of lines with a variant
+// is kept by the variant.
+func (r *ProductRepo) ReserveStock(ctx context.Context, orderID string, lines []*model.OrderLine) error {
+	return r.moveStock(ctx, orderID, lines, model.StockMovementReservation)
+}
+
+// ReleaseStock releases the quantity reserved for every line
+func (r *ProductRepo) ReleaseStock(ctx context.Context, orderID string, lines []*model.OrderLine) error {
+	return r.moveStock(ctx, orderID, lines, model.StockMovementRelease)
+}
+
+// CommitStock removes the quantity reserved for every line from stock on hand
+func (r *ProductRepo) CommitStock(ctx context.Context, orderID string, lines []*model.OrderLine) error {
+	return r.moveStock(ctx, orderID, lines, model.StockMovementCommit)
+}
+
+func (r *ProductRepo) moveStock(ctx context.Context, orderID string, lines []*model.OrderLine, movementType model.StockMovementType) error {
+	if len(lines) == 0 {
+		return nil
+	}
+
+	handler := func(ctx context.Context) error {
+		if movementType != model.StockMovementReservation {
+			// Orders placed before stock was tracked have nothing reserved to release or commit
+			var reservations int64
+			if err := r.db.Count(
+				ctx,
+				&model.StockMovement{},
+				&reservations,
+				dbs.WithQuery(
+					dbs.NewQuery("order_id = ?", orderID),
+					dbs.NewQuery("type = ?", model.StockMovementReservation),
+				),
+			); err != nil {
+				return err
+			}
+			if reservations == 0 {
+				return nil
+			}
+		}
+
+		movements := make([]*model.StockMovement, 0, len(lines))
+		for _, line := range lines {
+			quantity := int64(line.Quantity)
+			values, condition := stockUpdate(movementType, quantity)
+
+			var stock any = &model.ProductStock{}
+			key := dbs.NewQuery("product_id = ?", line.ProductID)
+			item := "product " + line.ProductID
+			if line.VariantID != "" {
+				stock = &model.VariantStock{}
+				key = dbs.NewQuery("variant_id = ?", line.VariantID)
+				item = "variant " + line.VariantID
+			}
+
+			// The condition guards the update so concurrent orders can never oversell
+			updated, err := r.db.UpdateColumns(ctx, stock, values, dbs.WithQuery(key, condition))
+			if err != nil {
+				return err
+			}
+			if updated == 0 {
+				if movementType == model.StockMovementReservation {
+					return fmt.Errorf("%w: %s", ErrInsufficientStock, item)
+				}
+				return fmt.Errorf("no stock reserved for %s", item)
+			}
+
+			movements = append(movements, &model.StockMovement{
+				ProductID: line.ProductID,
+				VariantID: line.VariantID,
+				OrderID:   orderID,
+				Type:      movementType,
+				Reason:    model.StockReasonOrder,
+				Quantity:  quantity,
+			})
+		}
+
+		return r.db.CreateInBatches(ctx, &movements, len(movements))
+	}
+
+	return r.db.WithTransaction(ctx, handler)
+}
+
+func stockUpdate(movementType model.StockMovementType, quantity int64) (map[string]any, dbs.Query) {
+	switch movementType {
+	case model.StockMovementReservation:
+		return map[string]any{"reserved": dbs.Expr("reserved + ?", quantity)},
+			dbs.NewQuery("on_hand - reserved >= ?", quantity)
+	case model.StockMovementCommit:
+		return map[string]any{"on_hand": dbs.Expr("on_hand - ?", quantity), "reserved": dbs.Expr("reserved - ?", quantity)},
+			dbs.NewQuery("reserved >= ?", quantity)
+	default:
+		return map[string]any{"reserved": dbs.Expr("reserved - ?", quantity)},
+			dbs.NewQuery("reserved >= ?", quantity)
+	}
+}
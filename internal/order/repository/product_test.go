@@ -11,6 +11,7 @@ import (
 
 	"goshop/internal/order/model"
 	"goshop/pkg/config"
+	"goshop/pkg/dbs"
 	"goshop/pkg/dbs/mocks"
 )
 
@@ -35,7 +36,7 @@ func TestProductRepositoryTestSuite(t *testing.T) {
 // =================================================================
 
 func (suite *ProductRepositoryTestSuite) TestGetProductByIDSuccessfully() {
-	suite.mockDB.On("FindById", mock.Anything, "productId1", &model.Product{}).
+	suite.mockDB.On("FindOne", mock.Anything, &model.Product{}, mock.Anything, mock.Anything).
 		Return(nil).Times(1)
 
 	product, err := suite.repo.GetProductByID(context.Background(), "productId1")
@@ -44,10 +45,139 @@ func (suite *ProductRepositoryTestSuite) TestGetProductByIDSuccessfully() {
 }
 
 func (suite *ProductRepositoryTestSuite) TestGetProductByIDFail() {
-	suite.mockDB.On("FindById", mock.Anything, "productId1", &model.Product{}).
+	suite.mockDB.On("FindOne", mock.Anything, &model.Product{}, mock.Anything, mock.Anything).
 		Return(errors.New("error")).Times(1)
 
 	product, err := suite.repo.GetProductByID(context.Background(), "productId1")
 	suite.NotNil(err)
 	suite.Nil(product)
 }
+
+// ReserveStock
+// =================================================================
+
+func (suite *ProductRepositoryTestSuite) expectTransaction() {
+	suite.mockDB.On("WithTransaction", mock.Anything, mock.Anything).
+		Return(func(ctx context.Context, function func(ctx context.Context) error) error {
+			return function(ctx)
+		}).Times(1)
+}
+
+func (suite *ProductRepositoryTestSuite) TestReserveStockSuccessfully() {
+	lines := []*model.OrderLine{
+		{ProductID: "productId1", Quantity: 2},
+		{ProductID: "productId2", Quantity: 1},
+	}
+
+	suite.expectTransaction()
+	suite.mockDB.On("UpdateColumns", mock.Anything, &model.ProductStock{}, mock.Anything, mock.Anything).
+		Return(int64(1), nil).Times(2)
+	suite.mockDB.On("CreateInBatches", mock.Anything, mock.Anything, 2).
+		Return(func(ctx context.Context, docs any, batchSize int) error {
+			movements := *docs.(*[]*model.StockMovement)
+			suite.Equal(2, len(movements))
+			suite.Equal("orderId1", movements[0].OrderID)
+			suite.Equal(model.StockMovementReservation, movements[0].Type)
+			suite.Equal(int64(2), movements[0].Quantity)
+			return nil
+		}).Times(1)
+
+	err := suite.repo.ReserveStock(context.Background(), "orderId1", lines)
+	suite.Nil(err)
+}
+
+func (suite *ProductRepositoryTestSuite) TestReserveStockInsufficient() {
+	lines := []*model.OrderLine{{ProductID: "productId1", Quantity: 2}}
+
+	suite.expectTransaction()
+	suite.mockDB.On("UpdateColumns", mock.Anything, &model.ProductStock{}, mock.Anything, mock.Anything).
+		Return(int64(0), nil).Times(1)
+
+	err := suite.repo.ReserveStock(context.Background(), "orderId1", lines)
+	suite.ErrorIs(err, ErrInsufficientStock)
+}
+
+func (suite *ProductRepositoryTestSuite) TestReserveStockOfVariant() {
+	lines := []*model.OrderLine{{ProductID: "productId1", VariantID: "variantId1", Quantity: 2}}
+
+	suite.expectTransaction()
+	suite.mockDB.On("UpdateColumns", mock.Anything, &model.VariantStock{}, mock.Anything, mock.Anything).
+		Return(int64(1), nil).Times(1)
+	suite.mockDB.On("CreateInBatches", mock.Anything, mock.Anything, 1).
+		Return(func(ctx context.Context, docs any, batchSize int) error {
+			movements := *docs.(*[]*model.StockMovement)
+			suite.Equal("productId1", movements[0].ProductID)
+			suite.Equal("variantId1", movements[0].VariantID)
+			return nil
+		}).Times(1)
+
+	err := suite.repo.ReserveStock(context.Background(), "orderId1", lines)
+	suite.Nil(err)
+}
+
+func (suite *ProductRepositoryTestSuite) TestReserveStockOfVariantInsufficient() {
+	lines := []*model.OrderLine{{ProductID: "productId1", VariantID: "variantId1", Quantity: 2}}
+
+	suite.expectTransaction()
+	suite.mockDB.On("UpdateColumns", mock.Anything, &model.VariantStock{}, mock.Anything, mock.Anything).
+		Return(int64(0), nil).Times(1)
+
+	err := suite.repo.ReserveStock(context.Background(), "orderId1", lines)
+	suite.ErrorIs(err, ErrInsufficientStock)
+	suite.ErrorContains(err, "variant variantId1")
+}
+
+func (suite *ProductRepositoryTestSuite) TestReserveStockNoLines() {
+	err := suite.repo.ReserveStock(context.Background(), "orderId1", nil)
+	suite.Nil(err)
+}
+
+// ReleaseStock
+// =================================================================
+
+func (suite *ProductRepositoryTestSuite) TestReleaseStockSuccessfully() {
+	lines := []*model.OrderLine{{ProductID: "productId1", Quantity: 2}}
+
+	suite.expectTransaction()
+	suite.mockDB.On("Count", mock.Anything, &model.StockMovement{}, mock.Anything, mock.Anything).
+		Return(func(ctx context.Context, model any, total *int64, opts ...dbs.FindOption) error {
+			*total = 1
+			return nil
+		}).Times(1)
+	suite.mockDB.On("UpdateColumns", mock.Anything, &model.ProductStock{}, mock.Anything, mock.Anything).
+		Return(int64(1), nil).Times(1)
+	suite.mockDB.On("CreateInBatches", mock.Anything, mock.Anything, 1).Return(nil).Times(1)
+
+	err := suite.repo.ReleaseStock(context.Background(), "orderId1", lines)
+	suite.Nil(err)
+}
+
+func (suite *ProductRepositoryTestSuite) TestReleaseStockWithoutReservation() {
+	lines := []*model.OrderLine{{ProductID: "productId1", Quantity: 2}}
+
+	suite.expectTransaction()
+	suite.mockDB.On("Count", mock.Anything, &model.StockMovement{}, mock.Anything, mock.Anything).
+		Return(nil).Times(1)
+
+	err := suite.repo.ReleaseStock(context.Background(), "orderId1", lines)
+	suite.Nil(err)
+}
+
+// CommitStock
+// =================================================================
+
+func (suite *ProductRepositoryTestSuite) TestCommitStockFail() {
+	lines := []*model.OrderLine{{ProductID: "productId1", Quantity: 2}}
+
+	suite.expectTransaction()
+	suite.mockDB.On("Count", mock.Anything, &model.StockMovement{}, mock.Anything, mock.Anything).
+		Return(func(ctx context.Context, model any, total *int64, opts ...dbs.FindOption) error {
+			*total = 1
+			return nil
+		}).Times(1)
+	suite.mockDB.On("UpdateColumns", mock.Anything, &model.ProductStock{}, mock.Anything, mock.Anything).
+		Return(int64(0), errors.New("error")).Times(1)
+
+	err := suite.repo.CommitStock(context.Background(), "orderId1", lines)
+	suite.NotNil(err)
+}
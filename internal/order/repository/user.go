@@ -0,0 +1,30 @@
+package repository
+
+import (
+	"context"
+
+	"goshop/internal/order/model"
+	"goshop/pkg/dbs"
+)
+
+//go:generate mockery --name=IUserRepository
+type IUserRepository interface {
+	GetUserByID(ctx context.Context, id string) (*model.User, error)
+}
+
+type UserRepo struct {
+	db dbs.IDatabase
+}
+
+func NewUserRepository(db dbs.IDatabase) *UserRepo {
+	return &UserRepo{db: db}
+}
+
+func (r *UserRepo) GetUserByID(ctx context.Context, id string) (*model.User, error) {
+	var user model.User
+	if err := r.db.FindById(ctx, id, &user); err != nil {
+		return nil, err
+	}
+
+	return &user, nil
+}
@@ -0,0 +1,53 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/quangdangfit/gocommon/logger"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+
+	"goshop/internal/order/model"
+	"goshop/pkg/config"
+	"goshop/pkg/dbs/mocks"
+)
+
+type UserRepositoryTestSuite struct {
+	suite.Suite
+	mockDB *mocks.IDatabase
+	repo   IUserRepository
+}
+
+func (suite *UserRepositoryTestSuite) SetupTest() {
+	logger.Initialize(config.ProductionEnv)
+
+	suite.mockDB = mocks.NewIDatabase(suite.T())
+	suite.repo = NewUserRepository(suite.mockDB)
+}
+
+func TestUserRepositoryTestSuite(t *testing.T) {
+	suite.Run(t, new(UserRepositoryTestSuite))
+}
+
+// GetUserByID
+// =================================================================
+
+func (suite *UserRepositoryTestSuite) TestGetUserByIDSuccessfully() {
+	suite.mockDB.On("FindById", mock.Anything, "userId1", &model.User{}).
+		Return(nil).Times(1)
+
+	user, err := suite.repo.GetUserByID(context.Background(), "userId1")
+	suite.Nil(err)
+	suite.NotNil(user)
+}
+
+func (suite *UserRepositoryTestSuite) TestGetUserByIDFail() {
+	suite.mockDB.On("FindById", mock.Anything, "userId1", &model.User{}).
+		Return(errors.New("error")).Times(1)
+
+	user, err := suite.repo.GetUserByID(context.Background(), "userId1")
+	suite.NotNil(err)
+	suite.Nil(user)
+}
@@ -105,6 +105,32 @@ func (_m *IOrderService) GetOrderByID(ctx context.Context, id string) (*model.Or
 	return r0, r1
 }
 
+// GetStatusHistory provides a mock function with given fields: ctx, orderID, userID
+func (_m *IOrderService) GetStatusHistory(ctx context.Context, orderID string, userID string) ([]*model.OrderStatusHistory, error) {
+	ret := _m.Called(ctx, orderID, userID)
+
+	var r0 []*model.OrderStatusHistory
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) ([]*model.OrderStatusHistory, error)); ok {
+		return rf(ctx, orderID, userID)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) []*model.OrderStatusHistory); ok {
+		r0 = rf(ctx, orderID, userID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*model.OrderStatusHistory)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, string) error); ok {
+		r1 = rf(ctx, orderID, userID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // PlaceOrder provides a mock function with given fields: ctx, req
 func (_m *IOrderService) PlaceOrder(ctx context.Context, req *dto.PlaceOrderReq) (*model.Order, error) {
 	ret := _m.Called(ctx, req)
@@ -131,6 +157,32 @@ func (_m *IOrderService) PlaceOrder(ctx context.Context, req *dto.PlaceOrderReq)
 	return r0, r1
 }
 
+// UpdateStatus provides a mock function with given fields: ctx, orderID, changedBy, req
+func (_m *IOrderService) UpdateStatus(ctx context.Context, orderID string, changedBy string, req *dto.UpdateOrderStatusReq) (*model.Order, error) {
+	ret := _m.Called(ctx, orderID, changedBy, req)
+
+	var r0 *model.Order
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, string, *dto.UpdateOrderStatusReq) (*model.Order, error)); ok {
+		return rf(ctx, orderID, changedBy, req)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, string, *dto.UpdateOrderStatusReq) *model.Order); ok {
+		r0 = rf(ctx, orderID, changedBy, req)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*model.Order)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, string, *dto.UpdateOrderStatusReq) error); ok {
+		r1 = rf(ctx, orderID, changedBy, req)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // NewIOrderService creates a new instance of IOrderService. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
 // The first argument is typically a *testing.T value.
 func NewIOrderService(t interface {
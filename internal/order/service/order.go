@@ -3,40 +3,70 @@ package service
 import (
 	"context"
 	"errors"
+	"fmt"
 
 	"github.com/quangdangfit/gocommon/validation"
 
 	"goshop/internal/order/dto"
 	"goshop/internal/order/model"
 	"goshop/internal/order/repository"
+	promotionService "goshop/internal/promotion/service"
+	"goshop/pkg/dbs"
+	"goshop/pkg/money"
 	"goshop/pkg/paging"
 	"goshop/pkg/utils"
 )
 
+var ErrEmailNotVerified = errors.New("email not verified")
+
 //go:generate mockery --name=IOrderService
 type IOrderService interface {
 	PlaceOrder(ctx context.Context, req *dto.PlaceOrderReq) (*model.Order, error)
 	GetOrderByID(ctx context.Context, id string) (*model.Order, error)
 	GetMyOrders(ctx context.Context, req *dto.ListOrderReq) ([]*model.Order, *paging.Pagination, error)
 	CancelOrder(ctx context.Context, orderID, userID string) (*model.Order, error)
+	UpdateStatus(ctx context.Context, orderID, changedBy string, req *dto.UpdateOrderStatusReq) (*model.Order, error)
+	GetStatusHistory(ctx context.Context, orderID, userID string) ([]*model.OrderStatusHistory, error)
 }
 
 type OrderService struct {
-	validator   validation.Validation
-	repo        repository.IOrderRepository
-	productRepo repository.IProductRepository
+	validator    validation.Validation
+	db           dbs.IDatabase
+	repo         repository.IOrderRepository
+	productRepo  repository.IProductRepository
+	promotionSvc promotionService.IPromotionService
+	userRepo     repository.IUserRepository
+	hooks        []StatusHook
 }
 
 func NewOrderService(
 	validator validation.Validation,
+	db dbs.IDatabase,
 	repo repository.IOrderRepository,
 	productRepo repository.IProductRepository,
+	promotionSvc promotionService.IPromotionService,
 ) *OrderService {
-	return &OrderService{
-		validator:   validator,
-		repo:        repo,
-		productRepo: productRepo,
+	s := &OrderService{
+		validator:    validator,
+		db:           db,
+		repo:         repo,
+		productRepo:  productRepo,
+		promotionSvc: promotionSvc,
 	}
+	s.OnStatusChange(s.updateStock)
+
+	return s
+}
+
+// OnStatusChange registers hook to be called on every status change, in registration order
+func (s *OrderService) OnStatusChange(hook StatusHook) {
+	s.hooks = append(s.hooks, hook)
+}
+
+// RequireVerifiedEmail refuses the orders of users who did not verify their email, looking them
+// up in userRepo
+func (s *OrderService) RequireVerifiedEmail(userRepo repository.IUserRepository) {
+	s.userRepo = userRepo
 }
 
 func (s *OrderService) PlaceOrder(ctx context.Context, req *dto.PlaceOrderReq) (*model.Order, error) {
@@ -44,6 +74,16 @@ func (s *OrderService) PlaceOrder(ctx context.Context, req *dto.PlaceOrderReq) (
 		return nil, err
 	}
 
+	if s.userRepo != nil {
+		user, err := s.userRepo.GetUserByID(ctx, req.UserID)
+		if err != nil {
+			return nil, err
+		}
+		if user.EmailVerifiedAt == nil {
+			return nil, ErrEmailNotVerified
+		}
+	}
+
 	var lines []*model.OrderLine
 	utils.Copy(&lines, &req.Lines)
 
@@ -53,22 +93,118 @@ func (s *OrderService) PlaceOrder(ctx context.Context, req *dto.PlaceOrderReq) (
 		if err != nil {
 			return nil, err
 		}
-		line.Price = product.Price * float64(line.Quantity)
+		if !product.Active {
+			return nil, fmt.Errorf("%w: product %s", repository.ErrProductInactive, product.ID)
+		}
+		price, err := linePrice(product, line.VariantID)
+		if err != nil {
+			return nil, err
+		}
+		line.Price = price.Mul(int64(line.Quantity))
 		productMap[line.ProductID] = product
 	}
 
-	order, err := s.repo.CreateOrder(ctx, req.UserID, lines)
+	discounts, err := s.applyPromotions(ctx, req, lines, productMap)
+	if err != nil {
+		return nil, err
+	}
+
+	// The order is only created when all of its stock could be reserved and its promotions
+	// redeemed
+	var order *model.Order
+	err = s.db.WithTransaction(ctx, func(ctx context.Context) error {
+		var orderDiscounts []*model.OrderDiscount
+		for _, discount := range discounts {
+			orderDiscounts = append(orderDiscounts, &model.OrderDiscount{
+				PromotionID: discount.Promotion.ID,
+				Code:        discount.Promotion.Code,
+				Amount:      discount.Amount,
+			})
+		}
+
+		var err error
+		order, err = s.repo.CreateOrder(ctx, req.UserID, lines, orderDiscounts)
+		if err != nil {
+			return err
+		}
+
+		if len(discounts) > 0 {
+			if err := s.promotionSvc.Redeem(ctx, req.UserID, order.ID, discounts); err != nil {
+				return err
+			}
+		}
+
+		return s.recordStatusChange(ctx, &StatusChange{
+			Order:     order,
+			To:        model.OrderStatusNew,
+			ChangedBy: req.UserID,
+		})
+	})
 	if err != nil {
 		return nil, err
 	}
 
 	for _, line := range order.Lines {
 		line.Product = productMap[line.ProductID]
+		line.Variant = line.Product.Variant(line.VariantID)
 	}
 
 	return order, nil
 }
 
+// linePrice returns the unit price of the variant variantID of product. Products with
+// variants are sold by variant, those without only by themselves.
+func linePrice(product *model.Product, variantID string) (money.Money, error) {
+	if variantID == "" {
+		if len(product.Variants) > 0 {
+			return money.Money{}, fmt.Errorf("%w: product %s", repository.ErrVariantRequired, product.ID)
+		}
+		return product.Price, nil
+	}
+
+	variant := product.Variant(variantID)
+	if variant == nil {
+		return money.Money{}, fmt.Errorf("%w: %s of product %s", repository.ErrVariantNotFound, variantID, product.ID)
+	}
+	if !variant.Active {
+		return money.Money{}, fmt.Errorf("%w: variant %s", repository.ErrProductInactive, variant.SKU)
+	}
+	return product.VariantPrice(variant), nil
+}
+
+// applyPromotions discounts lines with the coupons of req
+func (s *OrderService) applyPromotions(
+	ctx context.Context,
+	req *dto.PlaceOrderReq,
+	lines []*model.OrderLine,
+	productMap map[string]*model.Product,
+) ([]*promotionService.Discount, error) {
+	if len(req.CouponCodes) == 0 {
+		return nil, nil
+	}
+
+	promotionLines := make([]*promotionService.Line, 0, len(lines))
+	for _, line := range lines {
+		promotionLines = append(promotionLines, &promotionService.Line{
+			ProductID:   line.ProductID,
+			CategoryIDs: productMap[line.ProductID].CategoryIDs(),
+			Quantity:    line.Quantity,
+			Price:       line.Price,
+		})
+	}
+
+	discounts, err := s.promotionSvc.Apply(ctx, req.UserID, req.CouponCodes, promotionLines)
+	if err != nil {
+		return nil, err
+	}
+
+	for i, line := range lines {
+		line.Discount = promotionLines[i].Discount
+	}
+
+	return discounts, nil
+}
+
 func (s *OrderService) GetOrderByID(ctx context.Context, id string) (*model.Order, error) {
 	order, err := s.repo.GetOrderByID(ctx, id, true)
 	if err != nil {
@@ -88,7 +224,7 @@ func (s *OrderService) GetMyOrders(ctx context.Context, req *dto.ListOrderReq) (
 }
 
 func (s *OrderService) CancelOrder(ctx context.Context, orderID, userID string) (*model.Order, error) {
-	order, err := s.repo.GetOrderByID(ctx, orderID, false)
+	order, err := s.repo.GetOrderByID(ctx, orderID, true)
 	if err != nil {
 		return nil, err
 	}
@@ -97,15 +233,112 @@ func (s *OrderService) CancelOrder(ctx context.Context, orderID, userID string)
 		return nil, errors.New("permission denied")
 	}
 
-	if order.Status == model.OrderStatusDone || order.Status == model.OrderStatusCancelled {
-		return nil, errors.New("invalid order status")
+	if err := s.transition(ctx, order, model.OrderStatusCancelled, userID, ""); err != nil {
+		return nil, err
+	}
+
+	return order, nil
+}
+
+// UpdateStatus moves the order to req.Status on behalf of changedBy, usually an admin
+func (s *OrderService) UpdateStatus(ctx context.Context, orderID, changedBy string, req *dto.UpdateOrderStatusReq) (*model.Order, error) {
+	if err := s.validator.ValidateStruct(req); err != nil {
+		return nil, err
 	}
 
-	order.Status = model.OrderStatusCancelled
-	err = s.repo.UpdateOrder(ctx, order)
+	order, err := s.repo.GetOrderByID(ctx, orderID, true)
 	if err != nil {
 		return nil, err
 	}
 
+	if err := s.transition(ctx, order, model.OrderStatus(req.Status), changedBy, req.Note); err != nil {
+		return nil, err
+	}
+
 	return order, nil
 }
+
+// GetStatusHistory returns the status timeline of the order. An empty userID skips the
+// ownership check, for callers allowed to manage every order.
+func (s *OrderService) GetStatusHistory(ctx context.Context, orderID, userID string) ([]*model.OrderStatusHistory, error) {
+	order, err := s.repo.GetOrderByID(ctx, orderID, false)
+	if err != nil {
+		return nil, err
+	}
+
+	if userID != "" && userID != order.UserID {
+		return nil, errors.New("permission denied")
+	}
+
+	return s.repo.GetStatusHistory(ctx, orderID)
+}
+
+func (s *OrderService) transition(ctx context.Context, order *model.Order, to model.OrderStatus, changedBy, note string) error {
+	from := order.Status
+	if err := validateTransition(from, to); err != nil {
+		return err
+	}
+
+	order.Status = to
+	err := s.db.WithTransaction(ctx, func(ctx context.Context) error {
+		// The status is only changed if no concurrent transition changed it first
+		updated, err := s.repo.UpdateOrderStatus(ctx, order.ID, from, to)
+		if err != nil {
+			return err
+		}
+		if !updated {
+			return fmt.Errorf("%w: order is no longer %s", ErrInvalidTransition, from)
+		}
+
+		return s.recordStatusChange(ctx, &StatusChange{
+			Order:     order,
+			From:      from,
+			To:        to,
+			ChangedBy: changedBy,
+			Note:      note,
+		})
+	})
+	if err != nil {
+		order.Status = from
+		return err
+	}
+
+	return nil
+}
+
+// recordStatusChange adds the change to the order history and runs the hooks.
+// It must be called in the transaction changing the order.
+func (s *OrderService) recordStatusChange(ctx context.Context, change *StatusChange) error {
+	if err := s.repo.CreateStatusHistory(ctx, &model.OrderStatusHistory{
+		OrderID:    change.Order.ID,
+		FromStatus: change.From,
+		ToStatus:   change.To,
+		ChangedBy:  change.ChangedBy,
+		Note:       change.Note,
+	}); err != nil {
+		return err
+	}
+
+	for _, hook := range s.hooks {
+		if err := hook(ctx, change); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// updateStock reserves the stock of placed orders, releases it when they are cancelled
+// and removes it from stock on hand when they are done
+func (s *OrderService) updateStock(ctx context.Context, change *StatusChange) error {
+	switch change.To {
+	case model.OrderStatusNew:
+		return s.productRepo.ReserveStock(ctx, change.Order.ID, change.Order.Lines)
+	case model.OrderStatusCancelled:
+		return s.productRepo.ReleaseStock(ctx, change.Order.ID, change.Order.Lines)
+	case model.OrderStatusDone:
+		return s.productRepo.CommitStock(ctx, change.Order.ID, change.Order.Lines)
+	default:
+		return nil
+	}
+}
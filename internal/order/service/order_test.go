@@ -12,15 +12,23 @@ import (
 
 	"goshop/internal/order/dto"
 	"goshop/internal/order/model"
+	"goshop/internal/order/repository"
 	"goshop/internal/order/repository/mocks"
+	promotionModel "goshop/internal/promotion/model"
+	promotionService "goshop/internal/promotion/service"
+	promotionMocks "goshop/internal/promotion/service/mocks"
 	"goshop/pkg/config"
+	dbMocks "goshop/pkg/dbs/mocks"
+	"goshop/pkg/money"
 	"goshop/pkg/paging"
 )
 
 type OrderServiceTestSuite struct {
 	suite.Suite
+	mockDB          *dbMocks.IDatabase
 	mockRepo        *mocks.IOrderRepository
 	mockProductRepo *mocks.IProductRepository
+	mockPromotion   *promotionMocks.IPromotionService
 	service         IOrderService
 }
 
@@ -28,9 +36,18 @@ func (suite *OrderServiceTestSuite) SetupTest() {
 	logger.Initialize(config.ProductionEnv)
 
 	validator := validation.New()
+	suite.mockDB = dbMocks.NewIDatabase(suite.T())
 	suite.mockRepo = mocks.NewIOrderRepository(suite.T())
 	suite.mockProductRepo = mocks.NewIProductRepository(suite.T())
-	suite.service = NewOrderService(validator, suite.mockRepo, suite.mockProductRepo)
+	suite.mockPromotion = promotionMocks.NewIPromotionService(suite.T())
+	suite.service = NewOrderService(validator, suite.mockDB, suite.mockRepo, suite.mockProductRepo, suite.mockPromotion)
+}
+
+func (suite *OrderServiceTestSuite) expectTransaction() {
+	suite.mockDB.On("WithTransaction", mock.Anything, mock.Anything).
+		Return(func(ctx context.Context, function func(ctx context.Context) error) error {
+			return function(ctx)
+		}).Times(1)
 }
 
 func TestOrderServiceTestSuite(t *testing.T) {
@@ -45,14 +62,14 @@ func (suite *OrderServiceTestSuite) TestGetOrderByIDSuccess() {
 	suite.mockRepo.On("GetOrderByID", mock.Anything, orderID, true).
 		Return(&model.Order{
 			UserID:     "userID",
-			TotalPrice: 111.1,
+			TotalPrice: money.New(11110, "USD"),
 			Status:     model.OrderStatusNew,
 		}, nil).Times(1)
 
 	order, err := suite.service.GetOrderByID(context.Background(), orderID)
 	suite.NotNil(order)
 	suite.Equal("userID", order.UserID)
-	suite.Equal(111.1, order.TotalPrice)
+	suite.Equal(money.New(11110, "USD"), order.TotalPrice)
 	suite.Equal(model.OrderStatusNew, order.Status)
 	suite.Nil(err)
 }
@@ -80,7 +97,7 @@ func (suite *OrderServiceTestSuite) TestListOrdersSuccess() {
 			[]*model.Order{
 				{
 					UserID:     "userID",
-					TotalPrice: 111.2,
+					TotalPrice: money.New(11120, "USD"),
 					Status:     model.OrderStatusNew,
 				},
 			},
@@ -96,7 +113,7 @@ func (suite *OrderServiceTestSuite) TestListOrdersSuccess() {
 	suite.NotNil(orders)
 	suite.Equal(1, len(orders))
 	suite.Equal("userID", orders[0].UserID)
-	suite.Equal(111.2, orders[0].TotalPrice)
+	suite.Equal(money.New(11120, "USD"), orders[0].TotalPrice)
 	suite.Equal(model.OrderStatusNew, orders[0].Status)
 	suite.NotNil(pagination)
 	suite.Equal(int64(1), pagination.Total)
@@ -137,10 +154,11 @@ func (suite *OrderServiceTestSuite) TestPlaceOrderSuccess() {
 		Return(&model.Product{
 			Name:        "product",
 			Description: "product description",
-			Price:       1.1,
+			Price:       money.New(110, "USD"),
+			Active:      true,
 		}, nil).Times(1)
 
-	suite.mockRepo.On("CreateOrder", mock.Anything, "userID", mock.Anything).
+	suite.mockRepo.On("CreateOrder", mock.Anything, "userID", mock.Anything, mock.Anything).
 		Return(&model.Order{
 			UserID: "userID",
 			Lines: []*model.OrderLine{
@@ -150,6 +168,10 @@ func (suite *OrderServiceTestSuite) TestPlaceOrderSuccess() {
 				},
 			},
 		}, nil).Times(1)
+	suite.mockRepo.On("CreateStatusHistory", mock.Anything, mock.Anything).Return(nil).Times(1)
+	suite.mockProductRepo.On("ReserveStock", mock.Anything, mock.Anything, mock.Anything).
+		Return(nil).Times(1)
+	suite.expectTransaction()
 
 	order, err := suite.service.PlaceOrder(context.Background(), req)
 	suite.NotNil(order)
@@ -160,6 +182,120 @@ func (suite *OrderServiceTestSuite) TestPlaceOrderSuccess() {
 	suite.Nil(err)
 }
 
+func (suite *OrderServiceTestSuite) TestPlaceOrderEmailNotVerified() {
+	mockUserRepo := mocks.NewIUserRepository(suite.T())
+	suite.service.(*OrderService).RequireVerifiedEmail(mockUserRepo)
+	req := &dto.PlaceOrderReq{
+		UserID: "userID",
+		Lines:  []dto.PlaceOrderLineReq{{ProductID: "productID", Quantity: 2}},
+	}
+	mockUserRepo.On("GetUserByID", mock.Anything, "userID").
+		Return(&model.User{ID: "userID"}, nil).Times(1)
+
+	order, err := suite.service.PlaceOrder(context.Background(), req)
+	suite.Nil(order)
+	suite.ErrorIs(err, ErrEmailNotVerified)
+}
+
+func (suite *OrderServiceTestSuite) TestPlaceOrderGetUserFail() {
+	mockUserRepo := mocks.NewIUserRepository(suite.T())
+	suite.service.(*OrderService).RequireVerifiedEmail(mockUserRepo)
+	req := &dto.PlaceOrderReq{
+		UserID: "userID",
+		Lines:  []dto.PlaceOrderLineReq{{ProductID: "productID", Quantity: 2}},
+	}
+	mockUserRepo.On("GetUserByID", mock.Anything, "userID").
+		Return(nil, errors.New("error")).Times(1)
+
+	order, err := suite.service.PlaceOrder(context.Background(), req)
+	suite.Nil(order)
+	suite.NotNil(err)
+}
+
+func (suite *OrderServiceTestSuite) TestPlaceOrderWithCoupon() {
+	req := &dto.PlaceOrderReq{
+		UserID:      "userID",
+		Lines:       []dto.PlaceOrderLineReq{{ProductID: "productID", Quantity: 2}},
+		CouponCodes: []string{"save10"},
+	}
+	promotion := &promotionModel.Promotion{ID: "promotionID", Code: "SAVE10"}
+	discounts := []*promotionService.Discount{{Promotion: promotion, Amount: money.New(22, "USD")}}
+
+	suite.mockProductRepo.On("GetProductByID", mock.Anything, "productID").
+		Return(&model.Product{
+			ID:         "productID",
+			Price:      money.New(110, "USD"),
+			Active:     true,
+			Categories: []*model.ProductCategory{{ProductID: "productID", CategoryID: "categoryID"}},
+		}, nil).Times(1)
+	suite.mockPromotion.On("Apply", mock.Anything, "userID", req.CouponCodes, mock.Anything).
+		Return(func(ctx context.Context, userID string, codes []string, lines []*promotionService.Line) ([]*promotionService.Discount, error) {
+			suite.Equal(money.New(220, "USD"), lines[0].Price)
+			suite.Equal([]string{"categoryID"}, lines[0].CategoryIDs)
+			lines[0].Discount = money.New(22, "USD")
+			return discounts, nil
+		}).Times(1)
+	suite.mockRepo.On("CreateOrder", mock.Anything, "userID", mock.Anything, mock.Anything).
+		Return(func(ctx context.Context, userID string, lines []*model.OrderLine, orderDiscounts []*model.OrderDiscount) (*model.Order, error) {
+			suite.Equal(money.New(22, "USD"), lines[0].Discount)
+			suite.Equal(1, len(orderDiscounts))
+			suite.Equal("SAVE10", orderDiscounts[0].Code)
+			suite.Equal(money.New(22, "USD"), orderDiscounts[0].Amount)
+			return &model.Order{ID: "orderID", UserID: userID, Lines: lines, Discounts: orderDiscounts}, nil
+		}).Times(1)
+	suite.mockPromotion.On("Redeem", mock.Anything, "userID", "orderID", discounts).Return(nil).Times(1)
+	suite.mockRepo.On("CreateStatusHistory", mock.Anything, mock.Anything).Return(nil).Times(1)
+	suite.mockProductRepo.On("ReserveStock", mock.Anything, mock.Anything, mock.Anything).Return(nil).Times(1)
+	suite.expectTransaction()
+
+	order, err := suite.service.PlaceOrder(context.Background(), req)
+	suite.Nil(err)
+	suite.NotNil(order)
+	suite.Equal(1, len(order.Discounts))
+}
+
+func (suite *OrderServiceTestSuite) TestPlaceOrderApplyCouponFail() {
+	req := &dto.PlaceOrderReq{
+		UserID:      "userID",
+		Lines:       []dto.PlaceOrderLineReq{{ProductID: "productID", Quantity: 2}},
+		CouponCodes: []string{"unknown"},
+	}
+
+	suite.mockProductRepo.On("GetProductByID", mock.Anything, "productID").
+		Return(&model.Product{ID: "productID", Price: money.New(110, "USD"), Active: true}, nil).Times(1)
+	suite.mockPromotion.On("Apply", mock.Anything, "userID", req.CouponCodes, mock.Anything).
+		Return(nil, promotionService.ErrPromotionNotFound).Times(1)
+
+	order, err := suite.service.PlaceOrder(context.Background(), req)
+	suite.Nil(order)
+	suite.ErrorIs(err, promotionService.ErrPromotionNotFound)
+}
+
+func (suite *OrderServiceTestSuite) TestPlaceOrderRedeemFail() {
+	req := &dto.PlaceOrderReq{
+		UserID:      "userID",
+		Lines:       []dto.PlaceOrderLineReq{{ProductID: "productID", Quantity: 2}},
+		CouponCodes: []string{"save10"},
+	}
+	discounts := []*promotionService.Discount{
+		{Promotion: &promotionModel.Promotion{ID: "promotionID", Code: "SAVE10"}, Amount: money.New(22, "USD")},
+	}
+
+	suite.mockProductRepo.On("GetProductByID", mock.Anything, "productID").
+		Return(&model.Product{ID: "productID", Price: money.New(110, "USD"), Active: true}, nil).Times(1)
+	suite.mockPromotion.On("Apply", mock.Anything, "userID", req.CouponCodes, mock.Anything).
+		Return(discounts, nil).Times(1)
+	suite.mockRepo.On("CreateOrder", mock.Anything, "userID", mock.Anything, mock.Anything).
+		Return(&model.Order{ID: "orderID", UserID: "userID"}, nil).Times(1)
+	suite.mockPromotion.On("Redeem", mock.Anything, "userID", "orderID", discounts).
+		Return(promotionService.ErrUsageLimitReached).Times(1)
+	suite.expectTransaction()
+
+	order, err := suite.service.PlaceOrder(context.Background(), req)
+	suite.Nil(order)
+	suite.ErrorIs(err, promotionService.ErrUsageLimitReached)
+}
+
 func (suite *OrderServiceTestSuite) TestPlaceOrderGetProductByIDFail() {
 	req := &dto.PlaceOrderReq{
 		UserID: "userID",
@@ -209,17 +345,151 @@ func (suite *OrderServiceTestSuite) TestPlaceOrderCreateFail() {
 		Return(&model.Product{
 			Name:        "product",
 			Description: "product description",
-			Price:       1.1,
+			Price:       money.New(110, "USD"),
+			Active:      true,
 		}, nil).Times(1)
 
-	suite.mockRepo.On("CreateOrder", mock.Anything, "userID", mock.Anything).
+	suite.mockRepo.On("CreateOrder", mock.Anything, "userID", mock.Anything, mock.Anything).
 		Return(nil, errors.New("error")).Times(1)
+	suite.expectTransaction()
 
 	order, err := suite.service.PlaceOrder(context.Background(), req)
 	suite.Nil(order)
 	suite.NotNil(err)
 }
 
+func (suite *OrderServiceTestSuite) TestPlaceOrderInactiveProduct() {
+	req := &dto.PlaceOrderReq{
+		UserID: "userID",
+		Lines: []dto.PlaceOrderLineReq{
+			{
+				ProductID: "productID",
+				Quantity:  2,
+			},
+		},
+	}
+
+	suite.mockProductRepo.On("GetProductByID", mock.Anything, "productID").
+		Return(&model.Product{
+			ID:     "productID",
+			Price:  money.New(110, "USD"),
+			Active: false,
+		}, nil).Times(1)
+
+	order, err := suite.service.PlaceOrder(context.Background(), req)
+	suite.Nil(order)
+	suite.ErrorIs(err, repository.ErrProductInactive)
+}
+
+func (suite *OrderServiceTestSuite) TestPlaceOrderInsufficientStock() {
+	req := &dto.PlaceOrderReq{
+		UserID: "userID",
+		Lines: []dto.PlaceOrderLineReq{
+			{
+				ProductID: "productID",
+				Quantity:  2,
+			},
+		},
+	}
+
+	suite.mockProductRepo.On("GetProductByID", mock.Anything, "productID").
+		Return(&model.Product{
+			ID:     "productID",
+			Price:  money.New(110, "USD"),
+			Active: true,
+		}, nil).Times(1)
+	suite.mockRepo.On("CreateOrder", mock.Anything, "userID", mock.Anything, mock.Anything).
+		Return(&model.Order{ID: "orderID", UserID: "userID", Status: model.OrderStatusNew}, nil).Times(1)
+	suite.mockRepo.On("CreateStatusHistory", mock.Anything, mock.Anything).Return(nil).Times(1)
+	suite.mockProductRepo.On("ReserveStock", mock.Anything, "orderID", mock.Anything).
+		Return(repository.ErrInsufficientStock).Times(1)
+	suite.expectTransaction()
+
+	order, err := suite.service.PlaceOrder(context.Background(), req)
+	suite.Nil(order)
+	suite.ErrorIs(err, repository.ErrInsufficientStock)
+}
+
+// shirt is a product sold by variant, the variant L costing more than the product
+func shirt() *model.Product {
+	return &model.Product{
+		ID:     "productID",
+		Price:  money.New(110, "USD"),
+		Active: true,
+		Variants: []*model.ProductVariant{
+			{ID: "variantS", ProductID: "productID", SKU: "SHIRT-S", Active: true},
+			{ID: "variantL", ProductID: "productID", SKU: "SHIRT-L", PriceOverride: money.New(150, "USD"), Active: true},
+			{ID: "variantXL", ProductID: "productID", SKU: "SHIRT-XL", Active: false},
+		},
+	}
+}
+
+func (suite *OrderServiceTestSuite) TestPlaceOrderWithVariants() {
+	req := &dto.PlaceOrderReq{
+		UserID: "userID",
+		Lines: []dto.PlaceOrderLineReq{
+			{ProductID: "productID", VariantID: "variantS", Quantity: 2},
+			{ProductID: "productID", VariantID: "variantL", Quantity: 1},
+		},
+	}
+
+	suite.mockProductRepo.On("GetProductByID", mock.Anything, "productID").Return(shirt(), nil).Times(2)
+	suite.mockRepo.On("CreateOrder", mock.Anything, "userID", mock.Anything, mock.Anything).
+		Return(func(ctx context.Context, userID string, lines []*model.OrderLine, discounts []*model.OrderDiscount) (*model.Order, error) {
+			suite.Equal("variantS", lines[0].VariantID)
+			suite.Equal(money.New(220, "USD"), lines[0].Price)
+			suite.Equal("variantL", lines[1].VariantID)
+			suite.Equal(money.New(150, "USD"), lines[1].Price)
+			return &model.Order{ID: "orderID", UserID: userID, Lines: lines}, nil
+		}).Times(1)
+	suite.mockRepo.On("CreateStatusHistory", mock.Anything, mock.Anything).Return(nil).Times(1)
+	suite.mockProductRepo.On("ReserveStock", mock.Anything, "orderID", mock.Anything).Return(nil).Times(1)
+	suite.expectTransaction()
+
+	order, err := suite.service.PlaceOrder(context.Background(), req)
+	suite.Nil(err)
+	suite.Equal("SHIRT-L", order.Lines[1].Variant.SKU)
+}
+
+func (suite *OrderServiceTestSuite) TestPlaceOrderVariantRequired() {
+	req := &dto.PlaceOrderReq{
+		UserID: "userID",
+		Lines:  []dto.PlaceOrderLineReq{{ProductID: "productID", Quantity: 1}},
+	}
+
+	suite.mockProductRepo.On("GetProductByID", mock.Anything, "productID").Return(shirt(), nil).Times(1)
+
+	order, err := suite.service.PlaceOrder(context.Background(), req)
+	suite.Nil(order)
+	suite.ErrorIs(err, repository.ErrVariantRequired)
+}
+
+func (suite *OrderServiceTestSuite) TestPlaceOrderVariantNotFound() {
+	req := &dto.PlaceOrderReq{
+		UserID: "userID",
+		Lines:  []dto.PlaceOrderLineReq{{ProductID: "productID", VariantID: "variantM", Quantity: 1}},
+	}
+
+	suite.mockProductRepo.On("GetProductByID", mock.Anything, "productID").Return(shirt(), nil).Times(1)
+
+	order, err := suite.service.PlaceOrder(context.Background(), req)
+	suite.Nil(order)
+	suite.ErrorIs(err, repository.ErrVariantNotFound)
+}
+
+func (suite *OrderServiceTestSuite) TestPlaceOrderInactiveVariant() {
+	req := &dto.PlaceOrderReq{
+		UserID: "userID",
+		Lines:  []dto.PlaceOrderLineReq{{ProductID: "productID", VariantID: "variantXL", Quantity: 1}},
+	}
+
+	suite.mockProductRepo.On("GetProductByID", mock.Anything, "productID").Return(shirt(), nil).Times(1)
+
+	order, err := suite.service.PlaceOrder(context.Background(), req)
+	suite.Nil(order)
+	suite.ErrorIs(err, repository.ErrProductInactive)
+}
+
 // Cancel Order
 // =================================================================
 
@@ -227,23 +497,24 @@ func (suite *OrderServiceTestSuite) TestCancelOrderSuccess() {
 	userID := "userID"
 	orderID := "orderID"
 
-	suite.mockRepo.On("GetOrderByID", mock.Anything, orderID, false).
+	suite.mockRepo.On("GetOrderByID", mock.Anything, orderID, true).
 		Return(&model.Order{
 			UserID:     userID,
-			TotalPrice: 111.1,
+			TotalPrice: money.New(11110, "USD"),
 			Status:     model.OrderStatusNew,
 		}, nil).Times(1)
 
-	suite.mockRepo.On("UpdateOrder", mock.Anything, &model.Order{
-		UserID:     userID,
-		TotalPrice: 111.1,
-		Status:     model.OrderStatusCancelled,
-	}).Return(nil).Times(1)
+	suite.mockRepo.On("UpdateOrderStatus", mock.Anything, "", model.OrderStatusNew, model.OrderStatusCancelled).
+		Return(true, nil).Times(1)
+	suite.mockRepo.On("CreateStatusHistory", mock.Anything, mock.Anything).Return(nil).Times(1)
+	suite.mockProductRepo.On("ReleaseStock", mock.Anything, mock.Anything, mock.Anything).
+		Return(nil).Times(1)
+	suite.expectTransaction()
 
 	order, err := suite.service.CancelOrder(context.Background(), orderID, userID)
 	suite.NotNil(order)
 	suite.Equal(userID, order.UserID)
-	suite.Equal(111.1, order.TotalPrice)
+	suite.Equal(money.New(11110, "USD"), order.TotalPrice)
 	suite.Equal(model.OrderStatusCancelled, order.Status)
 	suite.Nil(err)
 }
@@ -252,18 +523,16 @@ func (suite *OrderServiceTestSuite) TestCancelOrderFail() {
 	userID := "userID"
 	orderID := "orderID"
 
-	suite.mockRepo.On("GetOrderByID", mock.Anything, orderID, false).
+	suite.mockRepo.On("GetOrderByID", mock.Anything, orderID, true).
 		Return(&model.Order{
 			UserID:     userID,
-			TotalPrice: 111.1,
+			TotalPrice: money.New(11110, "USD"),
 			Status:     model.OrderStatusNew,
 		}, nil).Times(1)
 
-	suite.mockRepo.On("UpdateOrder", mock.Anything, &model.Order{
-		UserID:     userID,
-		TotalPrice: 111.1,
-		Status:     model.OrderStatusCancelled,
-	}).Return(errors.New("error")).Times(1)
+	suite.mockRepo.On("UpdateOrderStatus", mock.Anything, "", model.OrderStatusNew, model.OrderStatusCancelled).
+		Return(false, errors.New("error")).Times(1)
+	suite.expectTransaction()
 
 	order, err := suite.service.CancelOrder(context.Background(), orderID, userID)
 	suite.Nil(order)
@@ -274,10 +543,10 @@ func (suite *OrderServiceTestSuite) TestCancelOrderDifferenceUserId() {
 	userID := "userID"
 	orderID := "orderID"
 
-	suite.mockRepo.On("GetOrderByID", mock.Anything, orderID, false).
+	suite.mockRepo.On("GetOrderByID", mock.Anything, orderID, true).
 		Return(&model.Order{
 			UserID:     "userID1",
-			TotalPrice: 111.1,
+			TotalPrice: money.New(11110, "USD"),
 			Status:     model.OrderStatusNew,
 		}, nil).Times(1)
 
@@ -290,10 +559,10 @@ func (suite *OrderServiceTestSuite) TestCancelOrderInvalidStatus() {
 	userID := "userID"
 	orderID := "orderID"
 
-	suite.mockRepo.On("GetOrderByID", mock.Anything, orderID, false).
+	suite.mockRepo.On("GetOrderByID", mock.Anything, orderID, true).
 		Return(&model.Order{
 			UserID:     userID,
-			TotalPrice: 111.1,
+			TotalPrice: money.New(11110, "USD"),
 			Status:     model.OrderStatusCancelled,
 		}, nil).Times(1)
 
@@ -306,10 +575,199 @@ func (suite *OrderServiceTestSuite) TestCancelOrderGetOrderByIDFail() {
 	userID := "userID"
 	orderID := "orderID"
 
-	suite.mockRepo.On("GetOrderByID", mock.Anything, orderID, false).
+	suite.mockRepo.On("GetOrderByID", mock.Anything, orderID, true).
 		Return(nil, errors.New("error")).Times(1)
 
 	order, err := suite.service.CancelOrder(context.Background(), orderID, userID)
 	suite.Nil(order)
 	suite.NotNil(err)
 }
+
+// Update Status
+// =================================================================
+
+func (suite *OrderServiceTestSuite) TestUpdateStatusDoneSuccess() {
+	orderID := "orderID"
+	lines := []*model.OrderLine{{ProductID: "productID", Quantity: 2}}
+
+	suite.mockRepo.On("GetOrderByID", mock.Anything, orderID, true).
+		Return(&model.Order{
+			ID:     orderID,
+			Lines:  lines,
+			Status: model.OrderStatusInProgress,
+		}, nil).Times(1)
+	suite.mockRepo.On("UpdateOrderStatus", mock.Anything, orderID, mock.Anything, mock.Anything).Return(true, nil).Times(1)
+	suite.mockRepo.On("CreateStatusHistory", mock.Anything, &model.OrderStatusHistory{
+		OrderID:    orderID,
+		FromStatus: model.OrderStatusInProgress,
+		ToStatus:   model.OrderStatusDone,
+		ChangedBy:  "adminID",
+		Note:       "delivered",
+	}).Return(nil).Times(1)
+	suite.mockProductRepo.On("CommitStock", mock.Anything, orderID, lines).Return(nil).Times(1)
+	suite.expectTransaction()
+
+	req := &dto.UpdateOrderStatusReq{Status: "done", Note: "delivered"}
+	order, err := suite.service.UpdateStatus(context.Background(), orderID, "adminID", req)
+	suite.Nil(err)
+	suite.Equal(model.OrderStatusDone, order.Status)
+}
+
+func (suite *OrderServiceTestSuite) TestUpdateStatusInProgressSuccess() {
+	orderID := "orderID"
+
+	suite.mockRepo.On("GetOrderByID", mock.Anything, orderID, true).
+		Return(&model.Order{
+			ID:     orderID,
+			Status: model.OrderStatusNew,
+		}, nil).Times(1)
+	suite.mockRepo.On("UpdateOrderStatus", mock.Anything, orderID, mock.Anything, mock.Anything).Return(true, nil).Times(1)
+	suite.mockRepo.On("CreateStatusHistory", mock.Anything, mock.Anything).Return(nil).Times(1)
+	suite.expectTransaction()
+
+	req := &dto.UpdateOrderStatusReq{Status: "in-progress"}
+	order, err := suite.service.UpdateStatus(context.Background(), orderID, "adminID", req)
+	suite.Nil(err)
+	suite.Equal(model.OrderStatusInProgress, order.Status)
+}
+
+func (suite *OrderServiceTestSuite) TestUpdateStatusHookFail() {
+	orderID := "orderID"
+
+	suite.mockRepo.On("GetOrderByID", mock.Anything, orderID, true).
+		Return(&model.Order{
+			ID:     orderID,
+			Status: model.OrderStatusInProgress,
+		}, nil).Times(1)
+	suite.mockRepo.On("UpdateOrderStatus", mock.Anything, orderID, mock.Anything, mock.Anything).Return(true, nil).Times(1)
+	suite.mockRepo.On("CreateStatusHistory", mock.Anything, mock.Anything).Return(nil).Times(1)
+	suite.mockProductRepo.On("CommitStock", mock.Anything, orderID, mock.Anything).
+		Return(errors.New("error")).Times(1)
+	suite.expectTransaction()
+
+	req := &dto.UpdateOrderStatusReq{Status: "done"}
+	order, err := suite.service.UpdateStatus(context.Background(), orderID, "adminID", req)
+	suite.Nil(order)
+	suite.NotNil(err)
+}
+
+func (suite *OrderServiceTestSuite) TestUpdateStatusInvalidTransition() {
+	orderID := "orderID"
+
+	suite.mockRepo.On("GetOrderByID", mock.Anything, orderID, true).
+		Return(&model.Order{
+			ID:     orderID,
+			Status: model.OrderStatusNew,
+		}, nil).Times(1)
+
+	req := &dto.UpdateOrderStatusReq{Status: "done"}
+	order, err := suite.service.UpdateStatus(context.Background(), orderID, "adminID", req)
+	suite.Nil(order)
+	suite.ErrorIs(err, ErrInvalidTransition)
+}
+
+func (suite *OrderServiceTestSuite) TestUpdateStatusConcurrentTransition() {
+	orderID := "orderID"
+
+	suite.mockRepo.On("GetOrderByID", mock.Anything, orderID, true).
+		Return(&model.Order{
+			ID:     orderID,
+			Status: model.OrderStatusInProgress,
+		}, nil).Times(1)
+	suite.mockRepo.On("UpdateOrderStatus", mock.Anything, orderID, model.OrderStatusInProgress, model.OrderStatusDone).
+		Return(false, nil).Times(1)
+	suite.expectTransaction()
+
+	req := &dto.UpdateOrderStatusReq{Status: "done"}
+	order, err := suite.service.UpdateStatus(context.Background(), orderID, "adminID", req)
+	suite.Nil(order)
+	suite.ErrorIs(err, ErrInvalidTransition)
+}
+
+func (suite *OrderServiceTestSuite) TestUpdateStatusInvalidStatus() {
+	req := &dto.UpdateOrderStatusReq{Status: "new"}
+	order, err := suite.service.UpdateStatus(context.Background(), "orderID", "adminID", req)
+	suite.Nil(order)
+	suite.NotNil(err)
+}
+
+func (suite *OrderServiceTestSuite) TestUpdateStatusRunsHooks() {
+	orderID := "orderID"
+
+	suite.mockRepo.On("GetOrderByID", mock.Anything, orderID, true).
+		Return(&model.Order{
+			ID:     orderID,
+			Status: model.OrderStatusNew,
+		}, nil).Times(1)
+	suite.mockRepo.On("UpdateOrderStatus", mock.Anything, orderID, mock.Anything, mock.Anything).Return(true, nil).Times(1)
+	suite.mockRepo.On("CreateStatusHistory", mock.Anything, mock.Anything).Return(nil).Times(1)
+	suite.mockProductRepo.On("ReleaseStock", mock.Anything, orderID, mock.Anything).Return(nil).Times(1)
+	suite.expectTransaction()
+
+	var changes []*StatusChange
+	suite.service.(*OrderService).OnStatusChange(func(ctx context.Context, change *StatusChange) error {
+		changes = append(changes, change)
+		return nil
+	})
+
+	req := &dto.UpdateOrderStatusReq{Status: "cancelled", Note: "out of stock"}
+	_, err := suite.service.UpdateStatus(context.Background(), orderID, "adminID", req)
+	suite.Nil(err)
+	suite.Equal(1, len(changes))
+	suite.Equal(model.OrderStatusNew, changes[0].From)
+	suite.Equal(model.OrderStatusCancelled, changes[0].To)
+	suite.Equal("adminID", changes[0].ChangedBy)
+	suite.Equal("out of stock", changes[0].Note)
+}
+
+// Get Status History
+// =================================================================
+
+func (suite *OrderServiceTestSuite) TestGetStatusHistorySuccess() {
+	orderID := "orderID"
+
+	suite.mockRepo.On("GetOrderByID", mock.Anything, orderID, false).
+		Return(&model.Order{ID: orderID, UserID: "userID"}, nil).Times(1)
+	suite.mockRepo.On("GetStatusHistory", mock.Anything, orderID).
+		Return([]*model.OrderStatusHistory{
+			{OrderID: orderID, ToStatus: model.OrderStatusNew},
+			{OrderID: orderID, FromStatus: model.OrderStatusNew, ToStatus: model.OrderStatusInProgress},
+		}, nil).Times(1)
+
+	history, err := suite.service.GetStatusHistory(context.Background(), orderID, "userID")
+	suite.Nil(err)
+	suite.Equal(2, len(history))
+}
+
+func (suite *OrderServiceTestSuite) TestGetStatusHistoryWithoutOwnerCheck() {
+	orderID := "orderID"
+
+	suite.mockRepo.On("GetOrderByID", mock.Anything, orderID, false).
+		Return(&model.Order{ID: orderID, UserID: "userID"}, nil).Times(1)
+	suite.mockRepo.On("GetStatusHistory", mock.Anything, orderID).
+		Return([]*model.OrderStatusHistory{}, nil).Times(1)
+
+	history, err := suite.service.GetStatusHistory(context.Background(), orderID, "")
+	suite.Nil(err)
+	suite.NotNil(history)
+}
+
+func (suite *OrderServiceTestSuite) TestGetStatusHistoryNotMine() {
+	orderID := "orderID"
+
+	suite.mockRepo.On("GetOrderByID", mock.Anything, orderID, false).
+		Return(&model.Order{ID: orderID, UserID: "userID1"}, nil).Times(1)
+
+	history, err := suite.service.GetStatusHistory(context.Background(), orderID, "userID")
+	suite.Nil(history)
+	suite.NotNil(err)
+}
+
+func (suite *OrderServiceTestSuite) TestGetStatusHistoryGetOrderByIDFail() {
+	suite.mockRepo.On("GetOrderByID", mock.Anything, "orderID", false).
+		Return(nil, errors.New("error")).Times(1)
+
+	history, err := suite.service.GetStatusHistory(context.Background(), "orderID", "userID")
+	suite.Nil(history)
+	suite.NotNil(err)
+}
@@ -0,0 +1,41 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"goshop/internal/order/model"
+)
+
+var ErrInvalidTransition = errors.New("invalid order status transition")
+
+// orderTransitions lists the statuses an order can move to from each status.
+// Done and cancelled orders are final.
+var orderTransitions = map[model.OrderStatus][]model.OrderStatus{
+	model.OrderStatusNew:        {model.OrderStatusInProgress, model.OrderStatusCancelled},
+	model.OrderStatusInProgress: {model.OrderStatusDone, model.OrderStatusCancelled},
+}
+
+// StatusChange describes a transition of an order, From is empty when the order is placed
+type StatusChange struct {
+	Order     *model.Order
+	From      model.OrderStatus
+	To        model.OrderStatus
+	ChangedBy string
+	Note      string
+}
+
+// StatusHook is called in the transaction changing the status of an order.
+// Returning an error aborts the change.
+type StatusHook func(ctx context.Context, change *StatusChange) error
+
+func validateTransition(from, to model.OrderStatus) error {
+	for _, status := range orderTransitions[from] {
+		if status == to {
+			return nil
+		}
+	}
+
+	return fmt.Errorf("%w: %s to %s", ErrInvalidTransition, from, to)
+}
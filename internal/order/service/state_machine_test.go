@@ -0,0 +1,33 @@
+package service
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"goshop/internal/order/model"
+)
+
+func TestValidateTransition(t *testing.T) {
+	allowed := [][2]model.OrderStatus{
+		{model.OrderStatusNew, model.OrderStatusInProgress},
+		{model.OrderStatusNew, model.OrderStatusCancelled},
+		{model.OrderStatusInProgress, model.OrderStatusDone},
+		{model.OrderStatusInProgress, model.OrderStatusCancelled},
+	}
+	for _, transition := range allowed {
+		assert.Nil(t, validateTransition(transition[0], transition[1]), transition)
+	}
+
+	rejected := [][2]model.OrderStatus{
+		{model.OrderStatusNew, model.OrderStatusNew},
+		{model.OrderStatusNew, model.OrderStatusDone},
+		{model.OrderStatusInProgress, model.OrderStatusNew},
+		{model.OrderStatusDone, model.OrderStatusCancelled},
+		{model.OrderStatusCancelled, model.OrderStatusInProgress},
+		{model.OrderStatusDone, model.OrderStatusDone},
+	}
+	for _, transition := range rejected {
+		assert.ErrorIs(t, validateTransition(transition[0], transition[1]), ErrInvalidTransition, transition)
+	}
+}
@@ -0,0 +1,31 @@
+package dto
+
+import (
+	"time"
+
+	"goshop/pkg/money"
+)
+
+type Payment struct {
+	ID             string      `json:"id"`
+	OrderID        string      `json:"order_id"`
+	Provider       string      `json:"provider"`
+	Reference      string      `json:"reference,omitempty"`
+	Status         string      `json:"status"`
+	Amount         money.Money `json:"amount"`
+	CapturedAmount money.Money `json:"captured_amount"`
+	RefundedAmount money.Money `json:"refunded_amount"`
+	FailureReason  string      `json:"failure_reason,omitempty"`
+	CreatedAt      time.Time   `json:"created_at"`
+	UpdatedAt      time.Time   `json:"updated_at"`
+}
+
+type AuthorizePaymentReq struct {
+	UserID  string `json:"-" validate:"required"`
+	OrderID string `json:"order_id" validate:"required"`
+}
+
+type RefundPaymentReq struct {
+	// Amount to refund, everything not refunded yet when empty
+	Amount *money.Money `json:"amount,omitempty"`
+}
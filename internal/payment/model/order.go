@@ -0,0 +1,32 @@
+package model
+
+import (
+	"goshop/pkg/money"
+)
+
+type OrderStatus string
+
+const (
+	OrderStatusNew        OrderStatus = "new"
+	OrderStatusInProgress OrderStatus = "in-progress"
+)
+
+type OrderPaymentStatus string
+
+const (
+	OrderPaymentStatusUnpaid            OrderPaymentStatus = "unpaid"
+	OrderPaymentStatusPending           OrderPaymentStatus = "pending"
+	OrderPaymentStatusAuthorized        OrderPaymentStatus = "authorized"
+	OrderPaymentStatusPaid              OrderPaymentStatus = "paid"
+	OrderPaymentStatusPartiallyRefunded OrderPaymentStatus = "partially-refunded"
+	OrderPaymentStatusRefunded          OrderPaymentStatus = "refunded"
+)
+
+type Order struct {
+	ID            string             `json:"id" gorm:"unique;not null;index;primary_key"`
+	Code          string             `json:"code"`
+	UserID        string             `json:"user_id"`
+	TotalPrice    money.Money        `json:"total_price" gorm:"embedded;embeddedPrefix:total_price_"`
+	Status        OrderStatus        `json:"status"`
+	PaymentStatus OrderPaymentStatus `json:"payment_status"`
+}
@@ -0,0 +1,60 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+
+	"goshop/pkg/money"
+)
+
+type PaymentStatus string
+
+const (
+	PaymentStatusPending           PaymentStatus = "pending"
+	PaymentStatusAuthorized        PaymentStatus = "authorized"
+	PaymentStatusCaptured          PaymentStatus = "captured"
+	PaymentStatusVoided            PaymentStatus = "voided"
+	PaymentStatusPartiallyRefunded PaymentStatus = "partially-refunded"
+	PaymentStatusRefunded          PaymentStatus = "refunded"
+	PaymentStatusFailed            PaymentStatus = "failed"
+)
+
+type Payment struct {
+	ID             string        `json:"id" gorm:"unique;not null;index;primary_key"`
+	CreatedAt      time.Time     `json:"created_at"`
+	UpdatedAt      time.Time     `json:"updated_at"`
+	OrderID        string        `json:"order_id" gorm:"not null;index"`
+	UserID         string        `json:"user_id"`
+	Provider       string        `json:"provider" gorm:"not null"`
+	Reference      string        `json:"reference"`
+	Status         PaymentStatus `json:"status" gorm:"not null"`
+	Amount         money.Money   `json:"amount" gorm:"embedded"`
+	CapturedAmount money.Money   `json:"captured_amount" gorm:"embedded;embeddedPrefix:captured_"`
+	RefundedAmount money.Money   `json:"refunded_amount" gorm:"embedded;embeddedPrefix:refunded_"`
+	FailureReason  string        `json:"failure_reason"`
+}
+
+func (payment *Payment) BeforeCreate(tx *gorm.DB) error {
+	payment.ID = uuid.New().String()
+	return nil
+}
+
+// OrderPaymentStatus is the payment status of the order the payment belongs to
+func (payment *Payment) OrderPaymentStatus() OrderPaymentStatus {
+	switch payment.Status {
+	case PaymentStatusPending:
+		return OrderPaymentStatusPending
+	case PaymentStatusAuthorized:
+		return OrderPaymentStatusAuthorized
+	case PaymentStatusCaptured:
+		return OrderPaymentStatusPaid
+	case PaymentStatusPartiallyRefunded:
+		return OrderPaymentStatusPartiallyRefunded
+	case PaymentStatusRefunded:
+		return OrderPaymentStatusRefunded
+	default:
+		return OrderPaymentStatusUnpaid
+	}
+}
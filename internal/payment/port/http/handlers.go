@@ -0,0 +1,240 @@
+package http
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/quangdangfit/gocommon/logger"
+
+	"goshop/internal/payment/dto"
+	"goshop/internal/payment/provider"
+	"goshop/internal/payment/service"
+	"goshop/pkg/rbac"
+	"goshop/pkg/response"
+	"goshop/pkg/utils"
+)
+
+// SignatureHeader carries the signature of webhook payloads
+const SignatureHeader = "X-Payment-Signature"
+
+type PaymentHandler struct {
+	service service.IPaymentService
+}
+
+func NewPaymentHandler(service service.IPaymentService) *PaymentHandler {
+	return &PaymentHandler{
+		service: service,
+	}
+}
+
+// AuthorizePayment godoc
+//
+//	@Summary	pay an order
+//	@Tags		payments
+//	@Produce	json
+//	@Security	ApiKeyAuth
+//	@Param		_	body		dto.AuthorizePaymentReq	true	"Body"
+//	@Success	200	{object}	dto.Payment
+//	@Router		/api/v1/payments [post]
+func (h *PaymentHandler) AuthorizePayment(c *gin.Context) {
+	var req dto.AuthorizePaymentReq
+	if err := c.ShouldBindJSON(&req); err != nil {
+		logger.Error("Failed to get body", err)
+		response.Error(c, http.StatusBadRequest, err, "Invalid parameters")
+		return
+	}
+
+	req.UserID = c.GetString("userId")
+	if req.UserID == "" {
+		response.Error(c, http.StatusUnauthorized, errors.New("unauthorized"), "Unauthorized")
+		return
+	}
+
+	payment, err := h.service.Authorize(c, &req)
+	if err != nil {
+		logger.Errorf("Failed to authorize payment, order id: %s, error: %s", req.OrderID, err)
+		h.error(c, err)
+		return
+	}
+
+	var res dto.Payment
+	utils.Copy(&res, &payment)
+	response.JSON(c, http.StatusOK, res)
+}
+
+// GetPaymentByID godoc
+//
+//	@Summary	get payment details
+//	@Tags		payments
+//	@Produce	json
+//	@Security	ApiKeyAuth
+//	@Param		id	path		string	true	"Payment ID"
+//	@Success	200	{object}	dto.Payment
+//	@Router		/api/v1/payments/{id} [get]
+func (h *PaymentHandler) GetPaymentByID(c *gin.Context) {
+	userID := c.GetString("userId")
+	if userID == "" {
+		response.Error(c, http.StatusUnauthorized, errors.New("unauthorized"), "Unauthorized")
+		return
+	}
+
+	paymentID := c.Param("id")
+	if paymentID == "" {
+		response.Error(c, http.StatusBadRequest, errors.New("bad request"), "Miss Payment ID")
+		return
+	}
+
+	// Users managing payments may see every payment
+	if rbac.GetPolicy().Can(c.GetString("role"), rbac.PermissionPaymentManage) {
+		userID = ""
+	}
+
+	payment, err := h.service.GetPaymentByID(c, paymentID, userID)
+	if err != nil {
+		logger.Errorf("Failed to get payment, id: %s, error: %s", paymentID, err)
+		response.Error(c, http.StatusNotFound, err, "Not found")
+		return
+	}
+
+	var res dto.Payment
+	utils.Copy(&res, &payment)
+	response.JSON(c, http.StatusOK, res)
+}
+
+// CapturePayment godoc
+//
+//	@Summary	capture an authorized payment
+//	@Tags		payments
+//	@Produce	json
+//	@Security	ApiKeyAuth
+//	@Param		id	path		string	true	"Payment ID"
+//	@Success	200	{object}	dto.Payment
+//	@Router		/api/v1/payments/{id}/capture [put]
+func (h *PaymentHandler) CapturePayment(c *gin.Context) {
+	paymentID := c.Param("id")
+	if paymentID == "" {
+		response.Error(c, http.StatusBadRequest, errors.New("bad request"), "Miss Payment ID")
+		return
+	}
+
+	payment, err := h.service.Capture(c, paymentID)
+	if err != nil {
+		logger.Errorf("Failed to capture payment, id: %s, error: %s", paymentID, err)
+		h.error(c, err)
+		return
+	}
+
+	var res dto.Payment
+	utils.Copy(&res, &payment)
+	response.JSON(c, http.StatusOK, res)
+}
+
+// VoidPayment godoc
+//
+//	@Summary	void an authorized payment
+//	@Tags		payments
+//	@Produce	json
+//	@Security	ApiKeyAuth
+//	@Param		id	path		string	true	"Payment ID"
+//	@Success	200	{object}	dto.Payment
+//	@Router		/api/v1/payments/{id}/void [put]
+func (h *PaymentHandler) VoidPayment(c *gin.Context) {
+	paymentID := c.Param("id")
+	if paymentID == "" {
+		response.Error(c, http.StatusBadRequest, errors.New("bad request"), "Miss Payment ID")
+		return
+	}
+
+	payment, err := h.service.Void(c, paymentID)
+	if err != nil {
+		logger.Errorf("Failed to void payment, id: %s, error: %s", paymentID, err)
+		h.error(c, err)
+		return
+	}
+
+	var res dto.Payment
+	utils.Copy(&res, &payment)
+	response.JSON(c, http.StatusOK, res)
+}
+
+// RefundPayment godoc
+//
+//	@Summary	refund a captured payment
+//	@Tags		payments
+//	@Produce	json
+//	@Security	ApiKeyAuth
+//	@Param		id	path		string					true	"Payment ID"
+//	@Param		_	body		dto.RefundPaymentReq	true	"Body"
+//	@Success	200	{object}	dto.Payment
+//	@Router		/api/v1/payments/{id}/refund [put]
+func (h *PaymentHandler) RefundPayment(c *gin.Context) {
+	paymentID := c.Param("id")
+	if paymentID == "" {
+		response.Error(c, http.StatusBadRequest, errors.New("bad request"), "Miss Payment ID")
+		return
+	}
+
+	var req dto.RefundPaymentReq
+	if err := c.ShouldBindJSON(&req); err != nil {
+		logger.Error("Failed to get body", err)
+		response.Error(c, http.StatusBadRequest, err, "Invalid parameters")
+		return
+	}
+
+	payment, err := h.service.Refund(c, paymentID, &req)
+	if err != nil {
+		logger.Errorf("Failed to refund payment, id: %s, error: %s", paymentID, err)
+		h.error(c, err)
+		return
+	}
+
+	var res dto.Payment
+	utils.Copy(&res, &payment)
+	response.JSON(c, http.StatusOK, res)
+}
+
+// Webhook godoc
+//
+//	@Summary	receive a payment provider callback
+//	@Tags		payments
+//	@Produce	json
+//	@Param		X-Payment-Signature	header		string	true	"Signature of the body"
+//	@Success	200					{object}	dto.Payment
+//	@Router		/api/v1/payments/webhook [post]
+func (h *PaymentHandler) Webhook(c *gin.Context) {
+	payload, err := c.GetRawData()
+	if err != nil {
+		logger.Error("Failed to get body", err)
+		response.Error(c, http.StatusBadRequest, err, "Invalid parameters")
+		return
+	}
+
+	payment, err := h.service.HandleWebhook(c, payload, c.GetHeader(SignatureHeader))
+	if err != nil {
+		logger.Error("Failed to handle payment webhook: ", err)
+		if errors.Is(err, provider.ErrInvalidSignature) {
+			response.Error(c, http.StatusUnauthorized, err, "Invalid signature")
+			return
+		}
+		h.error(c, err)
+		return
+	}
+
+	var res dto.Payment
+	utils.Copy(&res, &payment)
+	response.JSON(c, http.StatusOK, res)
+}
+
+func (h *PaymentHandler) error(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, provider.ErrDeclined):
+		response.Error(c, http.StatusPaymentRequired, err, err.Error())
+	case errors.Is(err, service.ErrOrderNotPayable),
+		errors.Is(err, service.ErrInvalidTransition),
+		errors.Is(err, service.ErrRefundExceedsCaptured):
+		response.Error(c, http.StatusBadRequest, err, err.Error())
+	default:
+		response.Error(c, http.StatusInternalServerError, err, "Something went wrong")
+	}
+}
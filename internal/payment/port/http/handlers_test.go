@@ -0,0 +1,312 @@
+package http
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/quangdangfit/gocommon/logger"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+
+	"goshop/internal/payment/dto"
+	"goshop/internal/payment/model"
+	"goshop/internal/payment/provider"
+	"goshop/internal/payment/service"
+	"goshop/internal/payment/service/mocks"
+	"goshop/pkg/config"
+	"goshop/pkg/money"
+	"goshop/pkg/response"
+	"goshop/pkg/utils"
+)
+
+type PaymentHandlerTestSuite struct {
+	suite.Suite
+	mockService *mocks.IPaymentService
+	handler     *PaymentHandler
+}
+
+func (suite *PaymentHandlerTestSuite) SetupTest() {
+	logger.Initialize(config.ProductionEnv)
+
+	suite.mockService = mocks.NewIPaymentService(suite.T())
+	suite.handler = NewPaymentHandler(suite.mockService)
+}
+
+func TestPaymentHandlerTestSuite(t *testing.T) {
+	suite.Run(t, new(PaymentHandlerTestSuite))
+}
+
+func (suite *PaymentHandlerTestSuite) prepareContext(body any) (*gin.Context, *httptest.ResponseRecorder) {
+	requestBody, _ := json.Marshal(body)
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest("", "/", bytes.NewBuffer(requestBody))
+	c, _ := gin.CreateTestContext(w)
+	c.Request = r
+
+	return c, w
+}
+
+// AuthorizePayment
+// =================================================================================================
+
+func (suite *PaymentHandlerTestSuite) TestPaymentAPI_AuthorizePaymentSuccess() {
+	req := &dto.AuthorizePaymentReq{OrderID: "orderID"}
+	ctx, writer := suite.prepareContext(req)
+	ctx.Set("userId", "userID")
+	req.UserID = "userID"
+
+	suite.mockService.On("Authorize", mock.Anything, req).
+		Return(&model.Payment{
+			ID:      "paymentID",
+			OrderID: "orderID",
+			Status:  model.PaymentStatusAuthorized,
+			Amount:  money.New(1000, "USD"),
+		}, nil).Times(1)
+
+	suite.handler.AuthorizePayment(ctx)
+
+	var res response.Response
+	var payment dto.Payment
+
+	_ = json.Unmarshal(writer.Body.Bytes(), &res)
+	utils.Copy(&payment, &res.Result)
+	suite.Equal(http.StatusOK, writer.Code)
+	suite.Equal("paymentID", payment.ID)
+	suite.Equal("authorized", payment.Status)
+	suite.Equal(money.New(1000, "USD"), payment.Amount)
+}
+
+func (suite *PaymentHandlerTestSuite) TestPaymentAPI_AuthorizePaymentInvalidFieldType() {
+	ctx, writer := suite.prepareContext(map[string]interface{}{"order_id": 1})
+	ctx.Set("userId", "userID")
+
+	suite.handler.AuthorizePayment(ctx)
+	suite.Equal(http.StatusBadRequest, writer.Code)
+}
+
+func (suite *PaymentHandlerTestSuite) TestPaymentAPI_AuthorizePaymentUnauthorized() {
+	ctx, writer := suite.prepareContext(&dto.AuthorizePaymentReq{OrderID: "orderID"})
+
+	suite.handler.AuthorizePayment(ctx)
+	suite.Equal(http.StatusUnauthorized, writer.Code)
+}
+
+func (suite *PaymentHandlerTestSuite) TestPaymentAPI_AuthorizePaymentDeclined() {
+	req := &dto.AuthorizePaymentReq{OrderID: "orderID", UserID: "userID"}
+	ctx, writer := suite.prepareContext(req)
+	ctx.Set("userId", "userID")
+
+	suite.mockService.On("Authorize", mock.Anything, req).
+		Return(nil, fmt.Errorf("%w: insufficient funds", provider.ErrDeclined)).Times(1)
+
+	suite.handler.AuthorizePayment(ctx)
+	suite.Equal(http.StatusPaymentRequired, writer.Code)
+}
+
+func (suite *PaymentHandlerTestSuite) TestPaymentAPI_AuthorizePaymentOrderNotPayable() {
+	req := &dto.AuthorizePaymentReq{OrderID: "orderID", UserID: "userID"}
+	ctx, writer := suite.prepareContext(req)
+	ctx.Set("userId", "userID")
+
+	suite.mockService.On("Authorize", mock.Anything, req).
+		Return(nil, fmt.Errorf("%w: payment is paid", service.ErrOrderNotPayable)).Times(1)
+
+	suite.handler.AuthorizePayment(ctx)
+	suite.Equal(http.StatusBadRequest, writer.Code)
+}
+
+func (suite *PaymentHandlerTestSuite) TestPaymentAPI_AuthorizePaymentFail() {
+	req := &dto.AuthorizePaymentReq{OrderID: "orderID", UserID: "userID"}
+	ctx, writer := suite.prepareContext(req)
+	ctx.Set("userId", "userID")
+
+	suite.mockService.On("Authorize", mock.Anything, req).Return(nil, errors.New("error")).Times(1)
+
+	suite.handler.AuthorizePayment(ctx)
+	suite.Equal(http.StatusInternalServerError, writer.Code)
+}
+
+// GetPaymentByID
+// =================================================================================================
+
+func (suite *PaymentHandlerTestSuite) TestPaymentAPI_GetPaymentByIDSuccess() {
+	ctx, writer := suite.prepareContext(nil)
+	ctx.Set("userId", "userID")
+	ctx.Set("role", "customer")
+	ctx.AddParam("id", "paymentID")
+
+	suite.mockService.On("GetPaymentByID", mock.Anything, "paymentID", "userID").
+		Return(&model.Payment{ID: "paymentID"}, nil).Times(1)
+
+	suite.handler.GetPaymentByID(ctx)
+	suite.Equal(http.StatusOK, writer.Code)
+}
+
+func (suite *PaymentHandlerTestSuite) TestPaymentAPI_GetPaymentByIDAsAdmin() {
+	ctx, writer := suite.prepareContext(nil)
+	ctx.Set("userId", "adminID")
+	ctx.Set("role", "admin")
+	ctx.AddParam("id", "paymentID")
+
+	suite.mockService.On("GetPaymentByID", mock.Anything, "paymentID", "").
+		Return(&model.Payment{ID: "paymentID"}, nil).Times(1)
+
+	suite.handler.GetPaymentByID(ctx)
+	suite.Equal(http.StatusOK, writer.Code)
+}
+
+func (suite *PaymentHandlerTestSuite) TestPaymentAPI_GetPaymentByIDUnauthorized() {
+	ctx, writer := suite.prepareContext(nil)
+	ctx.AddParam("id", "paymentID")
+
+	suite.handler.GetPaymentByID(ctx)
+	suite.Equal(http.StatusUnauthorized, writer.Code)
+}
+
+func (suite *PaymentHandlerTestSuite) TestPaymentAPI_GetPaymentByIDNotFound() {
+	ctx, writer := suite.prepareContext(nil)
+	ctx.Set("userId", "userID")
+	ctx.AddParam("id", "paymentID")
+
+	suite.mockService.On("GetPaymentByID", mock.Anything, "paymentID", "userID").
+		Return(nil, errors.New("error")).Times(1)
+
+	suite.handler.GetPaymentByID(ctx)
+	suite.Equal(http.StatusNotFound, writer.Code)
+}
+
+// CapturePayment
+// =================================================================================================
+
+func (suite *PaymentHandlerTestSuite) TestPaymentAPI_CapturePaymentSuccess() {
+	ctx, writer := suite.prepareContext(nil)
+	ctx.AddParam("id", "paymentID")
+
+	suite.mockService.On("Capture", mock.Anything, "paymentID").
+		Return(&model.Payment{ID: "paymentID", Status: model.PaymentStatusCaptured}, nil).Times(1)
+
+	suite.handler.CapturePayment(ctx)
+	suite.Equal(http.StatusOK, writer.Code)
+}
+
+func (suite *PaymentHandlerTestSuite) TestPaymentAPI_CapturePaymentMissID() {
+	ctx, writer := suite.prepareContext(nil)
+
+	suite.handler.CapturePayment(ctx)
+	suite.Equal(http.StatusBadRequest, writer.Code)
+}
+
+func (suite *PaymentHandlerTestSuite) TestPaymentAPI_CapturePaymentInvalidTransition() {
+	ctx, writer := suite.prepareContext(nil)
+	ctx.AddParam("id", "paymentID")
+
+	suite.mockService.On("Capture", mock.Anything, "paymentID").
+		Return(nil, fmt.Errorf("%w: voided to captured", service.ErrInvalidTransition)).Times(1)
+
+	suite.handler.CapturePayment(ctx)
+	suite.Equal(http.StatusBadRequest, writer.Code)
+}
+
+// VoidPayment
+// =================================================================================================
+
+func (suite *PaymentHandlerTestSuite) TestPaymentAPI_VoidPaymentSuccess() {
+	ctx, writer := suite.prepareContext(nil)
+	ctx.AddParam("id", "paymentID")
+
+	suite.mockService.On("Void", mock.Anything, "paymentID").
+		Return(&model.Payment{ID: "paymentID", Status: model.PaymentStatusVoided}, nil).Times(1)
+
+	suite.handler.VoidPayment(ctx)
+	suite.Equal(http.StatusOK, writer.Code)
+}
+
+func (suite *PaymentHandlerTestSuite) TestPaymentAPI_VoidPaymentFail() {
+	ctx, writer := suite.prepareContext(nil)
+	ctx.AddParam("id", "paymentID")
+
+	suite.mockService.On("Void", mock.Anything, "paymentID").Return(nil, errors.New("error")).Times(1)
+
+	suite.handler.VoidPayment(ctx)
+	suite.Equal(http.StatusInternalServerError, writer.Code)
+}
+
+// RefundPayment
+// =================================================================================================
+
+func (suite *PaymentHandlerTestSuite) TestPaymentAPI_RefundPaymentSuccess() {
+	amount := money.New(500, "USD")
+	req := &dto.RefundPaymentReq{Amount: &amount}
+	ctx, writer := suite.prepareContext(req)
+	ctx.AddParam("id", "paymentID")
+
+	suite.mockService.On("Refund", mock.Anything, "paymentID", req).
+		Return(&model.Payment{ID: "paymentID", Status: model.PaymentStatusPartiallyRefunded}, nil).Times(1)
+
+	suite.handler.RefundPayment(ctx)
+	suite.Equal(http.StatusOK, writer.Code)
+}
+
+func (suite *PaymentHandlerTestSuite) TestPaymentAPI_RefundPaymentInvalidFieldType() {
+	ctx, writer := suite.prepareContext(map[string]interface{}{"amount": "5"})
+	ctx.AddParam("id", "paymentID")
+
+	suite.handler.RefundPayment(ctx)
+	suite.Equal(http.StatusBadRequest, writer.Code)
+}
+
+func (suite *PaymentHandlerTestSuite) TestPaymentAPI_RefundPaymentExceedsCaptured() {
+	amount := money.New(5000, "USD")
+	req := &dto.RefundPaymentReq{Amount: &amount}
+	ctx, writer := suite.prepareContext(req)
+	ctx.AddParam("id", "paymentID")
+
+	suite.mockService.On("Refund", mock.Anything, "paymentID", req).
+		Return(nil, fmt.Errorf("%w: 10 left to refund", service.ErrRefundExceedsCaptured)).Times(1)
+
+	suite.handler.RefundPayment(ctx)
+	suite.Equal(http.StatusBadRequest, writer.Code)
+}
+
+// Webhook
+// =================================================================================================
+
+func (suite *PaymentHandlerTestSuite) TestPaymentAPI_WebhookSuccess() {
+	event := &provider.Event{Type: provider.EventCaptured, Reference: "fake_paymentID"}
+	payload, _ := json.Marshal(event)
+	ctx, writer := suite.prepareContext(event)
+	ctx.Request.Header.Set(SignatureHeader, "signature")
+
+	suite.mockService.On("HandleWebhook", mock.Anything, payload, "signature").
+		Return(&model.Payment{ID: "paymentID", Status: model.PaymentStatusCaptured}, nil).Times(1)
+
+	suite.handler.Webhook(ctx)
+	suite.Equal(http.StatusOK, writer.Code)
+}
+
+func (suite *PaymentHandlerTestSuite) TestPaymentAPI_WebhookInvalidSignature() {
+	ctx, writer := suite.prepareContext(&provider.Event{Type: provider.EventCaptured})
+
+	suite.mockService.On("HandleWebhook", mock.Anything, mock.Anything, "").
+		Return(nil, provider.ErrInvalidSignature).Times(1)
+
+	suite.handler.Webhook(ctx)
+	suite.Equal(http.StatusUnauthorized, writer.Code)
+}
+
+func (suite *PaymentHandlerTestSuite) TestPaymentAPI_WebhookFail() {
+	ctx, writer := suite.prepareContext(&provider.Event{Type: provider.EventCaptured})
+	ctx.Request.Header.Set(SignatureHeader, "signature")
+
+	suite.mockService.On("HandleWebhook", mock.Anything, mock.Anything, "signature").
+		Return(nil, errors.New("error")).Times(1)
+
+	suite.handler.Webhook(ctx)
+	suite.Equal(http.StatusInternalServerError, writer.Code)
+}
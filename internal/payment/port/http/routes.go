@@ -0,0 +1,41 @@
+package http
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/quangdangfit/gocommon/validation"
+
+	"goshop/internal/payment/provider"
+	"goshop/internal/payment/repository"
+	"goshop/internal/payment/service"
+	"goshop/pkg/dbs"
+	"goshop/pkg/idempotency"
+	"goshop/pkg/middleware"
+	"goshop/pkg/rbac"
+)
+
+func Routes(
+	r *gin.RouterGroup,
+	db dbs.IDatabase,
+	validator validation.Validation,
+	paymentProvider provider.PaymentProvider,
+	idempotencyStore idempotency.Store,
+) {
+	paymentRepo := repository.NewPaymentRepository(db)
+	paymentSvc := service.NewPaymentService(validator, db, paymentRepo, paymentProvider)
+	paymentHandler := NewPaymentHandler(paymentSvc)
+
+	authMiddleware := middleware.JWTAuth()
+	idempotencyMiddleware := middleware.Idempotency(idempotencyStore)
+
+	// The provider authenticates its callbacks by signing them
+	r.POST("/payments/webhook", paymentHandler.Webhook)
+
+	paymentRoute := r.Group("/payments", authMiddleware, idempotencyMiddleware)
+	{
+		paymentRoute.POST("", middleware.RequirePermission(rbac.PermissionPaymentWrite), paymentHandler.AuthorizePayment)
+		paymentRoute.GET("/:id", middleware.RequirePermission(rbac.PermissionPaymentRead), paymentHandler.GetPaymentByID)
+		paymentRoute.PUT("/:id/capture", middleware.RequirePermission(rbac.PermissionPaymentManage), paymentHandler.CapturePayment)
+		paymentRoute.PUT("/:id/void", middleware.RequirePermission(rbac.PermissionPaymentManage), paymentHandler.VoidPayment)
+		paymentRoute.PUT("/:id/refund", middleware.RequirePermission(rbac.PermissionPaymentManage), paymentHandler.RefundPayment)
+	}
+}
@@ -0,0 +1,17 @@
+package http
+
+import (
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/quangdangfit/gocommon/validation"
+
+	"goshop/internal/payment/provider"
+	dbMocks "goshop/pkg/dbs/mocks"
+	idempotencyMocks "goshop/pkg/idempotency/mocks"
+)
+
+func TestRoutes(t *testing.T) {
+	mockDB := dbMocks.NewIDatabase(t)
+	Routes(gin.New().Group("/"), mockDB, validation.New(), provider.NewFakeProvider("secret"), idempotencyMocks.NewStore(t))
+}
@@ -0,0 +1,86 @@
+package provider
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"goshop/pkg/money"
+)
+
+const (
+	FakeProviderName = "fake"
+
+	// FakeDeclinedCents makes the fake provider decline every amount ending with these minor units, e.g. 10.13 USD
+	FakeDeclinedCents = 13
+
+	fakeReferencePrefix = "fake_"
+)
+
+// FakeProvider is an in-process provider for development and tests. It keeps no state
+// and behaves deterministically: references are derived from payment IDs, amounts ending
+// with FakeDeclinedCents are declined and every other operation on one of its references
+// succeeds. Webhooks are signed with the hex HMAC-SHA256 of the payload.
+type FakeProvider struct {
+	webhookSecret string
+}
+
+func NewFakeProvider(webhookSecret string) *FakeProvider {
+	return &FakeProvider{webhookSecret: webhookSecret}
+}
+
+func (p *FakeProvider) Name() string {
+	return FakeProviderName
+}
+
+func (p *FakeProvider) Authorize(ctx context.Context, req *AuthorizeRequest) (string, error) {
+	if req.Amount.Amount%100 == FakeDeclinedCents {
+		return "", fmt.Errorf("%w: insufficient funds", ErrDeclined)
+	}
+
+	return fakeReferencePrefix + req.PaymentID, nil
+}
+
+func (p *FakeProvider) Capture(ctx context.Context, reference string, amount money.Money) error {
+	return p.check(reference)
+}
+
+func (p *FakeProvider) Void(ctx context.Context, reference string) error {
+	return p.check(reference)
+}
+
+func (p *FakeProvider) Refund(ctx context.Context, reference string, amount money.Money) error {
+	return p.check(reference)
+}
+
+func (p *FakeProvider) ParseWebhook(payload []byte, signature string) (*Event, error) {
+	if !hmac.Equal([]byte(signature), []byte(p.Sign(payload))) {
+		return nil, ErrInvalidSignature
+	}
+
+	var event Event
+	if err := json.Unmarshal(payload, &event); err != nil {
+		return nil, err
+	}
+
+	return &event, nil
+}
+
+// Sign returns the signature the fake provider sends along with payload
+func (p *FakeProvider) Sign(payload []byte) string {
+	mac := hmac.New(sha256.New, []byte(p.webhookSecret))
+	mac.Write(payload)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+func (p *FakeProvider) check(reference string) error {
+	if !strings.HasPrefix(reference, fakeReferencePrefix) {
+		return fmt.Errorf("%w: %s", ErrNotFound, reference)
+	}
+
+	return nil
+}
@@ -0,0 +1,63 @@
+package provider
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"goshop/pkg/money"
+)
+
+func TestFakeProvider_Authorize(t *testing.T) {
+	p := NewFakeProvider("secret")
+
+	reference, err := p.Authorize(context.Background(), &AuthorizeRequest{PaymentID: "paymentID", Amount: money.New(1050, "USD")})
+	assert.Nil(t, err)
+	assert.Equal(t, "fake_paymentID", reference)
+
+	reference, err = p.Authorize(context.Background(), &AuthorizeRequest{PaymentID: "paymentID", Amount: money.New(1013, "USD")})
+	assert.ErrorIs(t, err, ErrDeclined)
+	assert.Equal(t, "", reference)
+}
+
+func TestFakeProvider_Operations(t *testing.T) {
+	p := NewFakeProvider("secret")
+
+	assert.Nil(t, p.Capture(context.Background(), "fake_paymentID", money.New(1000, "USD")))
+	assert.Nil(t, p.Void(context.Background(), "fake_paymentID"))
+	assert.Nil(t, p.Refund(context.Background(), "fake_paymentID", money.New(1000, "USD")))
+	assert.ErrorIs(t, p.Capture(context.Background(), "other", money.New(1000, "USD")), ErrNotFound)
+}
+
+func TestFakeProvider_ParseWebhook(t *testing.T) {
+	p := NewFakeProvider("secret")
+	payload := []byte(`{"id":"evt1","type":"payment.captured","reference":"fake_paymentID","amount":{"amount":"10.00","currency":"USD"}}`)
+
+	event, err := p.ParseWebhook(payload, p.Sign(payload))
+	assert.Nil(t, err)
+	assert.Equal(t, EventCaptured, event.Type)
+	assert.Equal(t, "fake_paymentID", event.Reference)
+	assert.Equal(t, money.New(1000, "USD"), event.Amount)
+
+	_, err = p.ParseWebhook(payload, NewFakeProvider("other").Sign(payload))
+	assert.ErrorIs(t, err, ErrInvalidSignature)
+
+	_, err = p.ParseWebhook([]byte("invalid"), p.Sign([]byte("invalid")))
+	assert.NotNil(t, err)
+}
+
+func TestNew(t *testing.T) {
+	p, err := New(FakeProviderName, "secret")
+	assert.Nil(t, err)
+	assert.Equal(t, FakeProviderName, p.Name())
+
+	_, err = New("unknown", "secret")
+	assert.NotNil(t, err)
+}
+
+func TestNewMissingWebhookSecret(t *testing.T) {
+	p, err := New(FakeProviderName, "")
+	assert.Nil(t, p)
+	assert.ErrorIs(t, err, ErrMissingWebhookSecret)
+}
@@ -0,0 +1,137 @@
+// Code generated by mockery v2.32.4. DO NOT EDIT.
+
+package mocks
+
+import (
+	context "context"
+	money "goshop/pkg/money"
+
+	mock "github.com/stretchr/testify/mock"
+
+	provider "goshop/internal/payment/provider"
+)
+
+// PaymentProvider is an autogenerated mock type for the PaymentProvider type
+type PaymentProvider struct {
+	mock.Mock
+}
+
+// Authorize provides a mock function with given fields: ctx, req
+func (_m *PaymentProvider) Authorize(ctx context.Context, req *provider.AuthorizeRequest) (string, error) {
+	ret := _m.Called(ctx, req)
+
+	var r0 string
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, *provider.AuthorizeRequest) (string, error)); ok {
+		return rf(ctx, req)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, *provider.AuthorizeRequest) string); ok {
+		r0 = rf(ctx, req)
+	} else {
+		r0 = ret.Get(0).(string)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, *provider.AuthorizeRequest) error); ok {
+		r1 = rf(ctx, req)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Capture provides a mock function with given fields: ctx, reference, amount
+func (_m *PaymentProvider) Capture(ctx context.Context, reference string, amount money.Money) error {
+	ret := _m.Called(ctx, reference, amount)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, money.Money) error); ok {
+		r0 = rf(ctx, reference, amount)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// Name provides a mock function with given fields:
+func (_m *PaymentProvider) Name() string {
+	ret := _m.Called()
+
+	var r0 string
+	if rf, ok := ret.Get(0).(func() string); ok {
+		r0 = rf()
+	} else {
+		r0 = ret.Get(0).(string)
+	}
+
+	return r0
+}
+
+// ParseWebhook provides a mock function with given fields: payload, signature
+func (_m *PaymentProvider) ParseWebhook(payload []byte, signature string) (*provider.Event, error) {
+	ret := _m.Called(payload, signature)
+
+	var r0 *provider.Event
+	var r1 error
+	if rf, ok := ret.Get(0).(func([]byte, string) (*provider.Event, error)); ok {
+		return rf(payload, signature)
+	}
+	if rf, ok := ret.Get(0).(func([]byte, string) *provider.Event); ok {
+		r0 = rf(payload, signature)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*provider.Event)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func([]byte, string) error); ok {
+		r1 = rf(payload, signature)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Refund provides a mock function with given fields: ctx, reference, amount
+func (_m *PaymentProvider) Refund(ctx context.Context, reference string, amount money.Money) error {
+	ret := _m.Called(ctx, reference, amount)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, money.Money) error); ok {
+		r0 = rf(ctx, reference, amount)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// Void provides a mock function with given fields: ctx, reference
+func (_m *PaymentProvider) Void(ctx context.Context, reference string) error {
+	ret := _m.Called(ctx, reference)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) error); ok {
+		r0 = rf(ctx, reference)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// NewPaymentProvider creates a new instance of PaymentProvider. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewPaymentProvider(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *PaymentProvider {
+	mock := &PaymentProvider{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
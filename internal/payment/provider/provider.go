@@ -0,0 +1,74 @@
+package provider
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"goshop/pkg/money"
+)
+
+var (
+	ErrDeclined         = errors.New("payment declined")
+	ErrInvalidSignature = errors.New("invalid webhook signature")
+	ErrNotFound         = errors.New("transaction not found")
+
+	ErrMissingWebhookSecret = errors.New("payment webhook secret is required")
+)
+
+type EventType string
+
+const (
+	EventAuthorized EventType = "payment.authorized"
+	EventCaptured   EventType = "payment.captured"
+	EventVoided     EventType = "payment.voided"
+	EventRefunded   EventType = "payment.refunded"
+	EventFailed     EventType = "payment.failed"
+)
+
+type AuthorizeRequest struct {
+	PaymentID string
+	OrderID   string
+	Amount    money.Money
+}
+
+// Event is a callback sent by the provider when a transaction changes.
+// Amount is the total amount captured or refunded on the transaction so far,
+// so that receiving the same event twice has no further effect.
+type Event struct {
+	ID        string      `json:"id"`
+	Type      EventType   `json:"type"`
+	Reference string      `json:"reference"`
+	Amount    money.Money `json:"amount"`
+	Reason    string      `json:"reason,omitempty"`
+}
+
+// PaymentProvider takes payments from a payment service provider. Transactions are
+// authorized first, then either captured and later refunded, or voided.
+//
+//go:generate mockery --name=PaymentProvider
+type PaymentProvider interface {
+	Name() string
+	// Authorize holds the amount and returns the provider reference of the transaction
+	Authorize(ctx context.Context, req *AuthorizeRequest) (string, error)
+	Capture(ctx context.Context, reference string, amount money.Money) error
+	Void(ctx context.Context, reference string) error
+	Refund(ctx context.Context, reference string, amount money.Money) error
+	// ParseWebhook verifies the signature of a callback and decodes it
+	ParseWebhook(payload []byte, signature string) (*Event, error)
+}
+
+// New returns the provider called name, the fake provider when name is empty. Webhooks are only
+// authenticated by their signature, so an empty webhookSecret is refused.
+func New(name, webhookSecret string) (PaymentProvider, error) {
+	if webhookSecret == "" {
+		return nil, ErrMissingWebhookSecret
+	}
+
+	switch name {
+	case "", FakeProviderName:
+		return NewFakeProvider(webhookSecret), nil
+	default:
+		return nil, fmt.Errorf("unknown payment provider: %s", name)
+	}
+}
@@ -0,0 +1,185 @@
+// Code generated by mockery v2.32.4. DO NOT EDIT.
+
+package mocks
+
+import (
+	context "context"
+	model "goshop/internal/payment/model"
+
+	mock "github.com/stretchr/testify/mock"
+)
+
+// IPaymentRepository is an autogenerated mock type for the IPaymentRepository type
+type IPaymentRepository struct {
+	mock.Mock
+}
+
+// CreatePayment provides a mock function with given fields: ctx, payment
+func (_m *IPaymentRepository) CreatePayment(ctx context.Context, payment *model.Payment) error {
+	ret := _m.Called(ctx, payment)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *model.Payment) error); ok {
+		r0 = rf(ctx, payment)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// GetOrderByID provides a mock function with given fields: ctx, id
+func (_m *IPaymentRepository) GetOrderByID(ctx context.Context, id string) (*model.Order, error) {
+	ret := _m.Called(ctx, id)
+
+	var r0 *model.Order
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) (*model.Order, error)); ok {
+		return rf(ctx, id)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string) *model.Order); ok {
+		r0 = rf(ctx, id)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*model.Order)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, id)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetPaymentByID provides a mock function with given fields: ctx, id
+func (_m *IPaymentRepository) GetPaymentByID(ctx context.Context, id string) (*model.Payment, error) {
+	ret := _m.Called(ctx, id)
+
+	var r0 *model.Payment
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) (*model.Payment, error)); ok {
+		return rf(ctx, id)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string) *model.Payment); ok {
+		r0 = rf(ctx, id)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*model.Payment)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, id)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetPaymentByIDForUpdate provides a mock function with given fields: ctx, id
+func (_m *IPaymentRepository) GetPaymentByIDForUpdate(ctx context.Context, id string) (*model.Payment, error) {
+	ret := _m.Called(ctx, id)
+
+	var r0 *model.Payment
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) (*model.Payment, error)); ok {
+		return rf(ctx, id)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string) *model.Payment); ok {
+		r0 = rf(ctx, id)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*model.Payment)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, id)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetPaymentByReferenceForUpdate provides a mock function with given fields: ctx, provider, reference
+func (_m *IPaymentRepository) GetPaymentByReferenceForUpdate(ctx context.Context, provider string, reference string) (*model.Payment, error) {
+	ret := _m.Called(ctx, provider, reference)
+
+	var r0 *model.Payment
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) (*model.Payment, error)); ok {
+		return rf(ctx, provider, reference)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) *model.Payment); ok {
+		r0 = rf(ctx, provider, reference)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*model.Payment)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, string) error); ok {
+		r1 = rf(ctx, provider, reference)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// UpdateOrderPaymentStatus provides a mock function with given fields: ctx, orderID, status, from
+func (_m *IPaymentRepository) UpdateOrderPaymentStatus(ctx context.Context, orderID string, status model.OrderPaymentStatus, from []model.OrderPaymentStatus) (bool, error) {
+	ret := _m.Called(ctx, orderID, status, from)
+
+	var r0 bool
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, model.OrderPaymentStatus, []model.OrderPaymentStatus) (bool, error)); ok {
+		return rf(ctx, orderID, status, from)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, model.OrderPaymentStatus, []model.OrderPaymentStatus) bool); ok {
+		r0 = rf(ctx, orderID, status, from)
+	} else {
+		r0 = ret.Get(0).(bool)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, model.OrderPaymentStatus, []model.OrderPaymentStatus) error); ok {
+		r1 = rf(ctx, orderID, status, from)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// UpdatePayment provides a mock function with given fields: ctx, payment
+func (_m *IPaymentRepository) UpdatePayment(ctx context.Context, payment *model.Payment) error {
+	ret := _m.Called(ctx, payment)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *model.Payment) error); ok {
+		r0 = rf(ctx, payment)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// NewIPaymentRepository creates a new instance of IPaymentRepository. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewIPaymentRepository(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *IPaymentRepository {
+	mock := &IPaymentRepository{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
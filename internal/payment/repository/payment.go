@@ -0,0 +1,99 @@
+package repository
+
+import (
+	"context"
+
+	"goshop/internal/payment/model"
+	"goshop/pkg/dbs"
+)
+
+//go:generate mockery --name=IPaymentRepository
+type IPaymentRepository interface {
+	CreatePayment(ctx context.Context, payment *model.Payment) error
+	UpdatePayment(ctx context.Context, payment *model.Payment) error
+	GetPaymentByID(ctx context.Context, id string) (*model.Payment, error)
+	GetPaymentByIDForUpdate(ctx context.Context, id string) (*model.Payment, error)
+	GetPaymentByReferenceForUpdate(ctx context.Context, provider, reference string) (*model.Payment, error)
+	GetOrderByID(ctx context.Context, id string) (*model.Order, error)
+	UpdateOrderPaymentStatus(ctx context.Context, orderID string, status model.OrderPaymentStatus, from []model.OrderPaymentStatus) (bool, error)
+}
+
+type PaymentRepo struct {
+	db dbs.IDatabase
+}
+
+func NewPaymentRepository(db dbs.IDatabase) *PaymentRepo {
+	return &PaymentRepo{db: db}
+}
+
+func (r *PaymentRepo) CreatePayment(ctx context.Context, payment *model.Payment) error {
+	return r.db.Create(ctx, payment)
+}
+
+func (r *PaymentRepo) UpdatePayment(ctx context.Context, payment *model.Payment) error {
+	return r.db.Update(ctx, payment)
+}
+
+func (r *PaymentRepo) GetPaymentByID(ctx context.Context, id string) (*model.Payment, error) {
+	var payment model.Payment
+	if err := r.db.FindById(ctx, id, &payment); err != nil {
+		return nil, err
+	}
+
+	return &payment, nil
+}
+
+// GetPaymentByIDForUpdate returns the payment locked until the end of the transaction in ctx
+func (r *PaymentRepo) GetPaymentByIDForUpdate(ctx context.Context, id string) (*model.Payment, error) {
+	var payment model.Payment
+	if err := r.db.FindOne(ctx, &payment, dbs.WithQuery(dbs.NewQuery("id = ?", id)), dbs.WithLock()); err != nil {
+		return nil, err
+	}
+
+	return &payment, nil
+}
+
+// GetPaymentByReferenceForUpdate returns the payment having the reference of the provider,
+// locked until the end of the transaction in ctx
+func (r *PaymentRepo) GetPaymentByReferenceForUpdate(ctx context.Context, provider, reference string) (*model.Payment, error) {
+	var payment model.Payment
+	query := dbs.WithQuery(
+		dbs.NewQuery("provider = ?", provider),
+		dbs.NewQuery("reference = ?", reference),
+	)
+	if err := r.db.FindOne(ctx, &payment, query, dbs.WithLock()); err != nil {
+		return nil, err
+	}
+
+	return &payment, nil
+}
+
+func (r *PaymentRepo) GetOrderByID(ctx context.Context, id string) (*model.Order, error) {
+	var order model.Order
+	if err := r.db.FindById(ctx, id, &order); err != nil {
+		return nil, err
+	}
+
+	return &order, nil
+}
+
+// UpdateOrderPaymentStatus sets the payment status of the order, only when it is one of from
+// if from is not empty. It reports whether the order was updated.
+func (r *PaymentRepo) UpdateOrderPaymentStatus(
+	ctx context.Context,
+	orderID string,
+	status model.OrderPaymentStatus,
+	from []model.OrderPaymentStatus,
+) (bool, error) {
+	query := []dbs.Query{dbs.NewQuery("id = ?", orderID)}
+	if len(from) > 0 {
+		query = append(query, dbs.NewQuery("payment_status IN ?", from))
+	}
+
+	updated, err := r.db.UpdateColumns(ctx, &model.Order{}, map[string]any{"payment_status": status}, dbs.WithQuery(query...))
+	if err != nil {
+		return false, err
+	}
+
+	return updated > 0, nil
+}
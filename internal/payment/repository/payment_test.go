@@ -0,0 +1,178 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/quangdangfit/gocommon/logger"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+
+	"goshop/internal/payment/model"
+	"goshop/pkg/config"
+	"goshop/pkg/dbs"
+	"goshop/pkg/dbs/mocks"
+	"goshop/pkg/money"
+)
+
+type PaymentRepositoryTestSuite struct {
+	suite.Suite
+	mockDB *mocks.IDatabase
+	repo   IPaymentRepository
+}
+
+func (suite *PaymentRepositoryTestSuite) SetupTest() {
+	logger.Initialize(config.ProductionEnv)
+
+	suite.mockDB = mocks.NewIDatabase(suite.T())
+	suite.repo = NewPaymentRepository(suite.mockDB)
+}
+
+func TestPaymentRepositoryTestSuite(t *testing.T) {
+	suite.Run(t, new(PaymentRepositoryTestSuite))
+}
+
+// CreatePayment
+// =================================================================
+
+func (suite *PaymentRepositoryTestSuite) TestCreatePaymentSuccessfully() {
+	payment := &model.Payment{OrderID: "orderID", Amount: money.New(1000, "USD")}
+	suite.mockDB.On("Create", mock.Anything, payment).Return(nil).Times(1)
+
+	err := suite.repo.CreatePayment(context.Background(), payment)
+	suite.Nil(err)
+}
+
+func (suite *PaymentRepositoryTestSuite) TestCreatePaymentFail() {
+	payment := &model.Payment{OrderID: "orderID", Amount: money.New(1000, "USD")}
+	suite.mockDB.On("Create", mock.Anything, payment).Return(errors.New("error")).Times(1)
+
+	err := suite.repo.CreatePayment(context.Background(), payment)
+	suite.NotNil(err)
+}
+
+// UpdatePayment
+// =================================================================
+
+func (suite *PaymentRepositoryTestSuite) TestUpdatePaymentSuccessfully() {
+	payment := &model.Payment{ID: "paymentID", Status: model.PaymentStatusCaptured}
+	suite.mockDB.On("Update", mock.Anything, payment).Return(nil).Times(1)
+
+	err := suite.repo.UpdatePayment(context.Background(), payment)
+	suite.Nil(err)
+}
+
+// GetPaymentByID
+// =================================================================
+
+func (suite *PaymentRepositoryTestSuite) TestGetPaymentByIDSuccessfully() {
+	suite.mockDB.On("FindById", mock.Anything, "paymentID", mock.Anything).Return(nil).Times(1)
+
+	payment, err := suite.repo.GetPaymentByID(context.Background(), "paymentID")
+	suite.Nil(err)
+	suite.NotNil(payment)
+}
+
+func (suite *PaymentRepositoryTestSuite) TestGetPaymentByIDFail() {
+	suite.mockDB.On("FindById", mock.Anything, "paymentID", mock.Anything).Return(errors.New("error")).Times(1)
+
+	payment, err := suite.repo.GetPaymentByID(context.Background(), "paymentID")
+	suite.NotNil(err)
+	suite.Nil(payment)
+}
+
+// GetPaymentByIDForUpdate
+// =================================================================
+
+func (suite *PaymentRepositoryTestSuite) TestGetPaymentByIDForUpdateSuccessfully() {
+	suite.mockDB.On("FindOne", mock.Anything, mock.Anything,
+		dbs.WithQuery(dbs.NewQuery("id = ?", "paymentID")), dbs.WithLock()).Return(nil).Times(1)
+
+	payment, err := suite.repo.GetPaymentByIDForUpdate(context.Background(), "paymentID")
+	suite.Nil(err)
+	suite.NotNil(payment)
+}
+
+func (suite *PaymentRepositoryTestSuite) TestGetPaymentByIDForUpdateFail() {
+	suite.mockDB.On("FindOne", mock.Anything, mock.Anything, mock.Anything, mock.Anything).
+		Return(errors.New("error")).Times(1)
+
+	payment, err := suite.repo.GetPaymentByIDForUpdate(context.Background(), "paymentID")
+	suite.NotNil(err)
+	suite.Nil(payment)
+}
+
+// GetPaymentByReferenceForUpdate
+// =================================================================
+
+func (suite *PaymentRepositoryTestSuite) TestGetPaymentByReferenceForUpdateSuccessfully() {
+	query := dbs.WithQuery(dbs.NewQuery("provider = ?", "fake"), dbs.NewQuery("reference = ?", "fake_paymentID"))
+	suite.mockDB.On("FindOne", mock.Anything, mock.Anything, query, dbs.WithLock()).Return(nil).Times(1)
+
+	payment, err := suite.repo.GetPaymentByReferenceForUpdate(context.Background(), "fake", "fake_paymentID")
+	suite.Nil(err)
+	suite.NotNil(payment)
+}
+
+func (suite *PaymentRepositoryTestSuite) TestGetPaymentByReferenceForUpdateFail() {
+	suite.mockDB.On("FindOne", mock.Anything, mock.Anything, mock.Anything, mock.Anything).
+		Return(errors.New("error")).Times(1)
+
+	payment, err := suite.repo.GetPaymentByReferenceForUpdate(context.Background(), "fake", "fake_paymentID")
+	suite.NotNil(err)
+	suite.Nil(payment)
+}
+
+// GetOrderByID
+// =================================================================
+
+func (suite *PaymentRepositoryTestSuite) TestGetOrderByIDSuccessfully() {
+	suite.mockDB.On("FindById", mock.Anything, "orderID", mock.Anything).Return(nil).Times(1)
+
+	order, err := suite.repo.GetOrderByID(context.Background(), "orderID")
+	suite.Nil(err)
+	suite.NotNil(order)
+}
+
+func (suite *PaymentRepositoryTestSuite) TestGetOrderByIDFail() {
+	suite.mockDB.On("FindById", mock.Anything, "orderID", mock.Anything).Return(errors.New("error")).Times(1)
+
+	order, err := suite.repo.GetOrderByID(context.Background(), "orderID")
+	suite.NotNil(err)
+	suite.Nil(order)
+}
+
+// UpdateOrderPaymentStatus
+// =================================================================
+
+func (suite *PaymentRepositoryTestSuite) TestUpdateOrderPaymentStatusSuccessfully() {
+	suite.mockDB.On("UpdateColumns", mock.Anything, mock.Anything,
+		map[string]any{"payment_status": model.OrderPaymentStatusPending}, mock.Anything).
+		Return(int64(1), nil).Times(1)
+
+	updated, err := suite.repo.UpdateOrderPaymentStatus(context.Background(), "orderID",
+		model.OrderPaymentStatusPending, []model.OrderPaymentStatus{model.OrderPaymentStatusUnpaid})
+	suite.Nil(err)
+	suite.True(updated)
+}
+
+func (suite *PaymentRepositoryTestSuite) TestUpdateOrderPaymentStatusNotMatched() {
+	suite.mockDB.On("UpdateColumns", mock.Anything, mock.Anything, mock.Anything, mock.Anything).
+		Return(int64(0), nil).Times(1)
+
+	updated, err := suite.repo.UpdateOrderPaymentStatus(context.Background(), "orderID",
+		model.OrderPaymentStatusPending, []model.OrderPaymentStatus{model.OrderPaymentStatusUnpaid})
+	suite.Nil(err)
+	suite.False(updated)
+}
+
+func (suite *PaymentRepositoryTestSuite) TestUpdateOrderPaymentStatusFail() {
+	suite.mockDB.On("UpdateColumns", mock.Anything, mock.Anything, mock.Anything, mock.Anything).
+		Return(int64(0), errors.New("error")).Times(1)
+
+	updated, err := suite.repo.UpdateOrderPaymentStatus(context.Background(), "orderID",
+		model.OrderPaymentStatusPaid, nil)
+	suite.NotNil(err)
+	suite.False(updated)
+}
@@ -0,0 +1,187 @@
+// Code generated by mockery v2.32.4. DO NOT EDIT.
+
+package mocks
+
+import (
+	context "context"
+	dto "goshop/internal/payment/dto"
+
+	mock "github.com/stretchr/testify/mock"
+
+	model "goshop/internal/payment/model"
+)
+
+// IPaymentService is an autogenerated mock type for the IPaymentService type
+type IPaymentService struct {
+	mock.Mock
+}
+
+// Authorize provides a mock function with given fields: ctx, req
+func (_m *IPaymentService) Authorize(ctx context.Context, req *dto.AuthorizePaymentReq) (*model.Payment, error) {
+	ret := _m.Called(ctx, req)
+
+	var r0 *model.Payment
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, *dto.AuthorizePaymentReq) (*model.Payment, error)); ok {
+		return rf(ctx, req)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, *dto.AuthorizePaymentReq) *model.Payment); ok {
+		r0 = rf(ctx, req)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*model.Payment)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, *dto.AuthorizePaymentReq) error); ok {
+		r1 = rf(ctx, req)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Capture provides a mock function with given fields: ctx, id
+func (_m *IPaymentService) Capture(ctx context.Context, id string) (*model.Payment, error) {
+	ret := _m.Called(ctx, id)
+
+	var r0 *model.Payment
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) (*model.Payment, error)); ok {
+		return rf(ctx, id)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string) *model.Payment); ok {
+		r0 = rf(ctx, id)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*model.Payment)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, id)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetPaymentByID provides a mock function with given fields: ctx, id, userID
+func (_m *IPaymentService) GetPaymentByID(ctx context.Context, id string, userID string) (*model.Payment, error) {
+	ret := _m.Called(ctx, id, userID)
+
+	var r0 *model.Payment
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) (*model.Payment, error)); ok {
+		return rf(ctx, id, userID)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) *model.Payment); ok {
+		r0 = rf(ctx, id, userID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*model.Payment)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, string) error); ok {
+		r1 = rf(ctx, id, userID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// HandleWebhook provides a mock function with given fields: ctx, payload, signature
+func (_m *IPaymentService) HandleWebhook(ctx context.Context, payload []byte, signature string) (*model.Payment, error) {
+	ret := _m.Called(ctx, payload, signature)
+
+	var r0 *model.Payment
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, []byte, string) (*model.Payment, error)); ok {
+		return rf(ctx, payload, signature)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, []byte, string) *model.Payment); ok {
+		r0 = rf(ctx, payload, signature)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*model.Payment)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, []byte, string) error); ok {
+		r1 = rf(ctx, payload, signature)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Refund provides a mock function with given fields: ctx, id, req
+func (_m *IPaymentService) Refund(ctx context.Context, id string, req *dto.RefundPaymentReq) (*model.Payment, error) {
+	ret := _m.Called(ctx, id, req)
+
+	var r0 *model.Payment
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, *dto.RefundPaymentReq) (*model.Payment, error)); ok {
+		return rf(ctx, id, req)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, *dto.RefundPaymentReq) *model.Payment); ok {
+		r0 = rf(ctx, id, req)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*model.Payment)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, *dto.RefundPaymentReq) error); ok {
+		r1 = rf(ctx, id, req)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Void provides a mock function with given fields: ctx, id
+func (_m *IPaymentService) Void(ctx context.Context, id string) (*model.Payment, error) {
+	ret := _m.Called(ctx, id)
+
+	var r0 *model.Payment
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) (*model.Payment, error)); ok {
+		return rf(ctx, id)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string) *model.Payment); ok {
+		r0 = rf(ctx, id)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*model.Payment)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, id)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// NewIPaymentService creates a new instance of IPaymentService. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewIPaymentService(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *IPaymentService {
+	mock := &IPaymentService{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
@@ -0,0 +1,347 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/quangdangfit/gocommon/validation"
+
+	"goshop/internal/payment/dto"
+	"goshop/internal/payment/model"
+	"goshop/internal/payment/provider"
+	"goshop/internal/payment/repository"
+	"goshop/pkg/dbs"
+	"goshop/pkg/money"
+)
+
+var (
+	ErrOrderNotPayable       = errors.New("order cannot be paid")
+	ErrInvalidTransition     = errors.New("invalid payment status transition")
+	ErrRefundExceedsCaptured = errors.New("refund exceeds captured amount")
+)
+
+// paymentTransitions lists the statuses each payment status may move to
+var paymentTransitions = map[model.PaymentStatus][]model.PaymentStatus{
+	model.PaymentStatusPending:           {model.PaymentStatusAuthorized, model.PaymentStatusFailed},
+	model.PaymentStatusAuthorized:        {model.PaymentStatusCaptured, model.PaymentStatusVoided, model.PaymentStatusFailed},
+	model.PaymentStatusCaptured:          {model.PaymentStatusPartiallyRefunded, model.PaymentStatusRefunded},
+	model.PaymentStatusPartiallyRefunded: {model.PaymentStatusPartiallyRefunded, model.PaymentStatusRefunded},
+}
+
+//go:generate mockery --name=IPaymentService
+type IPaymentService interface {
+	Authorize(ctx context.Context, req *dto.AuthorizePaymentReq) (*model.Payment, error)
+	Capture(ctx context.Context, id string) (*model.Payment, error)
+	Void(ctx context.Context, id string) (*model.Payment, error)
+	Refund(ctx context.Context, id string, req *dto.RefundPaymentReq) (*model.Payment, error)
+	GetPaymentByID(ctx context.Context, id, userID string) (*model.Payment, error)
+	HandleWebhook(ctx context.Context, payload []byte, signature string) (*model.Payment, error)
+}
+
+type PaymentService struct {
+	validator validation.Validation
+	db        dbs.IDatabase
+	repo      repository.IPaymentRepository
+	provider  provider.PaymentProvider
+}
+
+func NewPaymentService(
+	validator validation.Validation,
+	db dbs.IDatabase,
+	repo repository.IPaymentRepository,
+	provider provider.PaymentProvider,
+) *PaymentService {
+	return &PaymentService{
+		validator: validator,
+		db:        db,
+		repo:      repo,
+		provider:  provider,
+	}
+}
+
+// Authorize holds the total price of the order with the provider. An order has at most one
+// payment in progress, a new one may be authorized once the previous one failed or was voided.
+func (s *PaymentService) Authorize(ctx context.Context, req *dto.AuthorizePaymentReq) (*model.Payment, error) {
+	if err := s.validator.ValidateStruct(req); err != nil {
+		return nil, err
+	}
+
+	order, err := s.repo.GetOrderByID(ctx, req.OrderID)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.UserID != order.UserID {
+		return nil, errors.New("permission denied")
+	}
+
+	if order.Status != model.OrderStatusNew && order.Status != model.OrderStatusInProgress {
+		return nil, fmt.Errorf("%w: order is %s", ErrOrderNotPayable, order.Status)
+	}
+
+	// Mark the order as being paid first, so that concurrent requests cannot pay it twice
+	claimed, err := s.repo.UpdateOrderPaymentStatus(ctx, order.ID, model.OrderPaymentStatusPending,
+		[]model.OrderPaymentStatus{model.OrderPaymentStatusUnpaid})
+	if err != nil {
+		return nil, err
+	}
+	if !claimed {
+		return nil, fmt.Errorf("%w: payment is %s", ErrOrderNotPayable, order.PaymentStatus)
+	}
+
+	payment := &model.Payment{
+		OrderID:  order.ID,
+		UserID:   order.UserID,
+		Provider: s.provider.Name(),
+		Status:   model.PaymentStatusPending,
+		Amount:   order.TotalPrice,
+	}
+	if err := s.repo.CreatePayment(ctx, payment); err != nil {
+		_, _ = s.repo.UpdateOrderPaymentStatus(ctx, order.ID, model.OrderPaymentStatusUnpaid, nil)
+		return nil, err
+	}
+
+	reference, err := s.provider.Authorize(ctx, &provider.AuthorizeRequest{
+		PaymentID: payment.ID,
+		OrderID:   order.ID,
+		Amount:    payment.Amount,
+	})
+	if err != nil {
+		_, saveErr := s.update(ctx, s.paymentByID(payment.ID), func(ctx context.Context, payment *model.Payment) (bool, error) {
+			if err := validateTransition(payment.Status, model.PaymentStatusFailed); err != nil {
+				return false, err
+			}
+
+			payment.Status = model.PaymentStatusFailed
+			payment.FailureReason = err.Error()
+			return true, nil
+		})
+		if saveErr != nil {
+			return nil, saveErr
+		}
+		return nil, err
+	}
+
+	return s.update(ctx, s.paymentByID(payment.ID), func(ctx context.Context, payment *model.Payment) (bool, error) {
+		if err := validateTransition(payment.Status, model.PaymentStatusAuthorized); err != nil {
+			return false, err
+		}
+
+		payment.Reference = reference
+		payment.Status = model.PaymentStatusAuthorized
+		return true, nil
+	})
+}
+
+// Capture collects the authorized amount of the payment
+func (s *PaymentService) Capture(ctx context.Context, id string) (*model.Payment, error) {
+	return s.update(ctx, s.paymentByID(id), func(ctx context.Context, payment *model.Payment) (bool, error) {
+		if err := validateTransition(payment.Status, model.PaymentStatusCaptured); err != nil {
+			return false, err
+		}
+
+		if err := s.provider.Capture(ctx, payment.Reference, payment.Amount); err != nil {
+			return false, err
+		}
+
+		payment.Status = model.PaymentStatusCaptured
+		payment.CapturedAmount = payment.Amount
+		return true, nil
+	})
+}
+
+// Void releases the authorized amount of the payment, the order may then be paid again
+func (s *PaymentService) Void(ctx context.Context, id string) (*model.Payment, error) {
+	return s.update(ctx, s.paymentByID(id), func(ctx context.Context, payment *model.Payment) (bool, error) {
+		if err := validateTransition(payment.Status, model.PaymentStatusVoided); err != nil {
+			return false, err
+		}
+
+		if err := s.provider.Void(ctx, payment.Reference); err != nil {
+			return false, err
+		}
+
+		payment.Status = model.PaymentStatusVoided
+		return true, nil
+	})
+}
+
+// Refund gives back req.Amount of the captured amount, or all of it not refunded yet
+func (s *PaymentService) Refund(ctx context.Context, id string, req *dto.RefundPaymentReq) (*model.Payment, error) {
+	if err := s.validator.ValidateStruct(req); err != nil {
+		return nil, err
+	}
+
+	return s.update(ctx, s.paymentByID(id), func(ctx context.Context, payment *model.Payment) (bool, error) {
+		// Only captured payments can be refunded, whatever the amount
+		if err := validateTransition(payment.Status, model.PaymentStatusRefunded); err != nil {
+			return false, err
+		}
+
+		remaining, err := payment.CapturedAmount.Sub(payment.RefundedAmount)
+		if err != nil {
+			return false, err
+		}
+		amount := remaining
+		if req.Amount != nil && !req.Amount.IsZero() {
+			amount = *req.Amount
+		}
+		if cmp, err := amount.Cmp(remaining); err != nil {
+			return false, err
+		} else if cmp > 0 {
+			return false, fmt.Errorf("%w: %s left to refund", ErrRefundExceedsCaptured, remaining)
+		}
+
+		if err := s.provider.Refund(ctx, payment.Reference, amount); err != nil {
+			return false, err
+		}
+
+		if payment.RefundedAmount, err = payment.RefundedAmount.Add(amount); err != nil {
+			return false, err
+		}
+		payment.Status = refundStatus(payment.CapturedAmount, payment.RefundedAmount)
+		return true, nil
+	})
+}
+
+// GetPaymentByID returns the payment. An empty userID skips the ownership check,
+// for callers allowed to manage every payment.
+func (s *PaymentService) GetPaymentByID(ctx context.Context, id, userID string) (*model.Payment, error) {
+	payment, err := s.repo.GetPaymentByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if userID != "" && userID != payment.UserID {
+		return nil, errors.New("permission denied")
+	}
+
+	return payment, nil
+}
+
+// HandleWebhook applies a signed callback of the provider to the payment it is about.
+// Events already applied are ignored, so the provider may deliver them more than once.
+func (s *PaymentService) HandleWebhook(ctx context.Context, payload []byte, signature string) (*model.Payment, error) {
+	event, err := s.provider.ParseWebhook(payload, signature)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.update(ctx, func(ctx context.Context) (*model.Payment, error) {
+		return s.repo.GetPaymentByReferenceForUpdate(ctx, s.provider.Name(), event.Reference)
+	}, func(ctx context.Context, payment *model.Payment) (bool, error) {
+		return applyEvent(payment, event)
+	})
+}
+
+// update reads the payment with get and applies change to it in one transaction, holding the
+// lock on the payment until it is saved. Requests and webhooks about the same payment thus
+// check its status and call the provider one at a time. change reports whether to save the
+// payment, along with the payment status of its order.
+func (s *PaymentService) update(
+	ctx context.Context,
+	get func(ctx context.Context) (*model.Payment, error),
+	change func(ctx context.Context, payment *model.Payment) (bool, error),
+) (*model.Payment, error) {
+	var payment *model.Payment
+	err := s.db.WithTransaction(ctx, func(ctx context.Context) error {
+		var err error
+		if payment, err = get(ctx); err != nil {
+			return err
+		}
+
+		changed, err := change(ctx, payment)
+		if err != nil || !changed {
+			return err
+		}
+
+		if err := s.repo.UpdatePayment(ctx, payment); err != nil {
+			return err
+		}
+
+		_, err = s.repo.UpdateOrderPaymentStatus(ctx, payment.OrderID, payment.OrderPaymentStatus(), nil)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return payment, nil
+}
+
+func (s *PaymentService) paymentByID(id string) func(ctx context.Context) (*model.Payment, error) {
+	return func(ctx context.Context) (*model.Payment, error) {
+		return s.repo.GetPaymentByIDForUpdate(ctx, id)
+	}
+}
+
+// applyEvent moves payment to the state described by event and reports whether it changed
+func applyEvent(payment *model.Payment, event *provider.Event) (bool, error) {
+	var to model.PaymentStatus
+	switch event.Type {
+	case provider.EventAuthorized:
+		to = model.PaymentStatusAuthorized
+	case provider.EventCaptured:
+		to = model.PaymentStatusCaptured
+	case provider.EventVoided:
+		to = model.PaymentStatusVoided
+	case provider.EventFailed:
+		to = model.PaymentStatusFailed
+	case provider.EventRefunded:
+		refunded := event.Amount
+		if refunded.IsZero() {
+			refunded = payment.CapturedAmount
+		}
+		if cmp, err := refunded.Cmp(payment.RefundedAmount); err != nil || cmp <= 0 {
+			return false, err
+		}
+		to = refundStatus(payment.CapturedAmount, refunded)
+		if err := validateTransition(payment.Status, to); err != nil {
+			return false, err
+		}
+		payment.Status = to
+		payment.RefundedAmount = refunded
+		return true, nil
+	default:
+		return false, nil
+	}
+
+	if payment.Status == to {
+		return false, nil
+	}
+	if err := validateTransition(payment.Status, to); err != nil {
+		return false, err
+	}
+
+	payment.Status = to
+	switch to {
+	case model.PaymentStatusCaptured:
+		payment.CapturedAmount = payment.Amount
+		if !event.Amount.IsZero() {
+			payment.CapturedAmount = event.Amount
+		}
+	case model.PaymentStatusFailed:
+		payment.FailureReason = event.Reason
+	}
+
+	return true, nil
+}
+
+func validateTransition(from, to model.PaymentStatus) error {
+	for _, status := range paymentTransitions[from] {
+		if status == to {
+			return nil
+		}
+	}
+
+	return fmt.Errorf("%w: %s to %s", ErrInvalidTransition, from, to)
+}
+
+func refundStatus(captured, refunded money.Money) model.PaymentStatus {
+	if cmp, err := refunded.Cmp(captured); err == nil && cmp >= 0 {
+		return model.PaymentStatusRefunded
+	}
+
+	return model.PaymentStatusPartiallyRefunded
+}
@@ -0,0 +1,543 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/quangdangfit/gocommon/logger"
+	"github.com/quangdangfit/gocommon/validation"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+
+	"goshop/internal/payment/dto"
+	"goshop/internal/payment/model"
+	"goshop/internal/payment/provider"
+	providerMocks "goshop/internal/payment/provider/mocks"
+	"goshop/internal/payment/repository/mocks"
+	"goshop/pkg/config"
+	dbMocks "goshop/pkg/dbs/mocks"
+	"goshop/pkg/money"
+)
+
+type txKey struct{}
+
+// inTransaction matches the contexts of the transaction started by expectTransaction
+var inTransaction = mock.MatchedBy(func(ctx context.Context) bool {
+	return ctx.Value(txKey{}) != nil
+})
+
+type PaymentServiceTestSuite struct {
+	suite.Suite
+	mockDB   *dbMocks.IDatabase
+	mockRepo *mocks.IPaymentRepository
+	provider *provider.FakeProvider
+	service  IPaymentService
+}
+
+func (suite *PaymentServiceTestSuite) SetupTest() {
+	logger.Initialize(config.ProductionEnv)
+
+	suite.mockDB = dbMocks.NewIDatabase(suite.T())
+	suite.mockRepo = mocks.NewIPaymentRepository(suite.T())
+	suite.provider = provider.NewFakeProvider("secret")
+	suite.service = NewPaymentService(validation.New(), suite.mockDB, suite.mockRepo, suite.provider)
+}
+
+func (suite *PaymentServiceTestSuite) expectTransaction() {
+	suite.mockDB.On("WithTransaction", mock.Anything, mock.Anything).
+		Return(func(ctx context.Context, function func(ctx context.Context) error) error {
+			return function(context.WithValue(ctx, txKey{}, true))
+		}).Times(1)
+}
+
+func (suite *PaymentServiceTestSuite) expectSave(orderStatus model.OrderPaymentStatus) {
+	suite.mockRepo.On("UpdatePayment", inTransaction, mock.Anything).Return(nil).Times(1)
+	suite.mockRepo.On("UpdateOrderPaymentStatus", inTransaction, "orderID", orderStatus,
+		[]model.OrderPaymentStatus(nil)).Return(true, nil).Times(1)
+}
+
+func (suite *PaymentServiceTestSuite) webhook(event *provider.Event) ([]byte, string) {
+	payload, _ := json.Marshal(event)
+	return payload, suite.provider.Sign(payload)
+}
+
+func TestPaymentServiceTestSuite(t *testing.T) {
+	suite.Run(t, new(PaymentServiceTestSuite))
+}
+
+// Authorize
+// =================================================================
+
+func (suite *PaymentServiceTestSuite) TestAuthorizeSuccess() {
+	req := &dto.AuthorizePaymentReq{UserID: "userID", OrderID: "orderID"}
+
+	suite.mockRepo.On("GetOrderByID", mock.Anything, "orderID").
+		Return(&model.Order{
+			ID:            "orderID",
+			UserID:        "userID",
+			TotalPrice:    money.New(1250, "USD"),
+			Status:        model.OrderStatusNew,
+			PaymentStatus: model.OrderPaymentStatusUnpaid,
+		}, nil).Times(1)
+	suite.mockRepo.On("UpdateOrderPaymentStatus", mock.Anything, "orderID", model.OrderPaymentStatusPending,
+		[]model.OrderPaymentStatus{model.OrderPaymentStatusUnpaid}).Return(true, nil).Times(1)
+	suite.mockRepo.On("CreatePayment", mock.Anything, mock.Anything).
+		Run(func(args mock.Arguments) {
+			args.Get(1).(*model.Payment).ID = "paymentID"
+		}).Return(nil).Times(1)
+	suite.expectTransaction()
+	suite.mockRepo.On("GetPaymentByIDForUpdate", inTransaction, "paymentID").
+		Return(&model.Payment{
+			ID:       "paymentID",
+			OrderID:  "orderID",
+			UserID:   "userID",
+			Provider: provider.FakeProviderName,
+			Status:   model.PaymentStatusPending,
+			Amount:   money.New(1250, "USD"),
+		}, nil).Times(1)
+	suite.expectSave(model.OrderPaymentStatusAuthorized)
+
+	payment, err := suite.service.Authorize(context.Background(), req)
+	suite.Nil(err)
+	suite.Equal(model.PaymentStatusAuthorized, payment.Status)
+	suite.Equal(money.New(1250, "USD"), payment.Amount)
+	suite.Equal("fake_paymentID", payment.Reference)
+	suite.Equal(provider.FakeProviderName, payment.Provider)
+}
+
+func (suite *PaymentServiceTestSuite) TestAuthorizeInvalidReq() {
+	payment, err := suite.service.Authorize(context.Background(), &dto.AuthorizePaymentReq{UserID: "userID"})
+	suite.Nil(payment)
+	suite.NotNil(err)
+}
+
+func (suite *PaymentServiceTestSuite) TestAuthorizeOrderNotMine() {
+	req := &dto.AuthorizePaymentReq{UserID: "userID", OrderID: "orderID"}
+
+	suite.mockRepo.On("GetOrderByID", mock.Anything, "orderID").
+		Return(&model.Order{ID: "orderID", UserID: "otherID", Status: model.OrderStatusNew}, nil).Times(1)
+
+	payment, err := suite.service.Authorize(context.Background(), req)
+	suite.Nil(payment)
+	suite.NotNil(err)
+}
+
+func (suite *PaymentServiceTestSuite) TestAuthorizeOrderCancelled() {
+	req := &dto.AuthorizePaymentReq{UserID: "userID", OrderID: "orderID"}
+
+	suite.mockRepo.On("GetOrderByID", mock.Anything, "orderID").
+		Return(&model.Order{ID: "orderID", UserID: "userID", Status: "cancelled"}, nil).Times(1)
+
+	payment, err := suite.service.Authorize(context.Background(), req)
+	suite.Nil(payment)
+	suite.ErrorIs(err, ErrOrderNotPayable)
+}
+
+func (suite *PaymentServiceTestSuite) TestAuthorizeOrderAlreadyPaid() {
+	req := &dto.AuthorizePaymentReq{UserID: "userID", OrderID: "orderID"}
+
+	suite.mockRepo.On("GetOrderByID", mock.Anything, "orderID").
+		Return(&model.Order{
+			ID:            "orderID",
+			UserID:        "userID",
+			Status:        model.OrderStatusNew,
+			PaymentStatus: model.OrderPaymentStatusPaid,
+		}, nil).Times(1)
+	suite.mockRepo.On("UpdateOrderPaymentStatus", mock.Anything, "orderID", model.OrderPaymentStatusPending,
+		[]model.OrderPaymentStatus{model.OrderPaymentStatusUnpaid}).Return(false, nil).Times(1)
+
+	payment, err := suite.service.Authorize(context.Background(), req)
+	suite.Nil(payment)
+	suite.ErrorIs(err, ErrOrderNotPayable)
+}
+
+func (suite *PaymentServiceTestSuite) TestAuthorizeDeclined() {
+	req := &dto.AuthorizePaymentReq{UserID: "userID", OrderID: "orderID"}
+
+	suite.mockRepo.On("GetOrderByID", mock.Anything, "orderID").
+		Return(&model.Order{
+			ID:         "orderID",
+			UserID:     "userID",
+			TotalPrice: money.New(1013, "USD"),
+			Status:     model.OrderStatusNew,
+		}, nil).Times(1)
+	suite.mockRepo.On("UpdateOrderPaymentStatus", mock.Anything, "orderID", model.OrderPaymentStatusPending,
+		[]model.OrderPaymentStatus{model.OrderPaymentStatusUnpaid}).Return(true, nil).Times(1)
+	suite.mockRepo.On("CreatePayment", mock.Anything, mock.Anything).Return(nil).Times(1)
+	suite.expectTransaction()
+	suite.mockRepo.On("GetPaymentByIDForUpdate", inTransaction, mock.Anything).
+		Return(&model.Payment{OrderID: "orderID", Status: model.PaymentStatusPending}, nil).Times(1)
+	// A declined payment leaves the order unpaid, so that it can be paid again
+	suite.expectSave(model.OrderPaymentStatusUnpaid)
+
+	payment, err := suite.service.Authorize(context.Background(), req)
+	suite.Nil(payment)
+	suite.ErrorIs(err, provider.ErrDeclined)
+}
+
+func (suite *PaymentServiceTestSuite) TestAuthorizeCreatePaymentFail() {
+	req := &dto.AuthorizePaymentReq{UserID: "userID", OrderID: "orderID"}
+
+	suite.mockRepo.On("GetOrderByID", mock.Anything, "orderID").
+		Return(&model.Order{ID: "orderID", UserID: "userID", Status: model.OrderStatusNew}, nil).Times(1)
+	suite.mockRepo.On("UpdateOrderPaymentStatus", mock.Anything, "orderID", model.OrderPaymentStatusPending,
+		[]model.OrderPaymentStatus{model.OrderPaymentStatusUnpaid}).Return(true, nil).Times(1)
+	suite.mockRepo.On("CreatePayment", mock.Anything, mock.Anything).Return(errors.New("error")).Times(1)
+	suite.mockRepo.On("UpdateOrderPaymentStatus", mock.Anything, "orderID", model.OrderPaymentStatusUnpaid,
+		[]model.OrderPaymentStatus(nil)).Return(true, nil).Times(1)
+
+	payment, err := suite.service.Authorize(context.Background(), req)
+	suite.Nil(payment)
+	suite.NotNil(err)
+}
+
+// Capture
+// =================================================================
+
+func (suite *PaymentServiceTestSuite) TestCaptureSuccess() {
+	suite.expectTransaction()
+	suite.mockRepo.On("GetPaymentByIDForUpdate", inTransaction, "paymentID").
+		Return(&model.Payment{
+			ID:        "paymentID",
+			OrderID:   "orderID",
+			Reference: "fake_paymentID",
+			Status:    model.PaymentStatusAuthorized,
+			Amount:    money.New(1250, "USD"),
+		}, nil).Times(1)
+	suite.expectSave(model.OrderPaymentStatusPaid)
+
+	payment, err := suite.service.Capture(context.Background(), "paymentID")
+	suite.Nil(err)
+	suite.Equal(model.PaymentStatusCaptured, payment.Status)
+	suite.Equal(money.New(1250, "USD"), payment.CapturedAmount)
+}
+
+func (suite *PaymentServiceTestSuite) TestCaptureInvalidTransition() {
+	suite.expectTransaction()
+	suite.mockRepo.On("GetPaymentByIDForUpdate", inTransaction, "paymentID").
+		Return(&model.Payment{ID: "paymentID", Status: model.PaymentStatusVoided}, nil).Times(1)
+
+	payment, err := suite.service.Capture(context.Background(), "paymentID")
+	suite.Nil(payment)
+	suite.ErrorIs(err, ErrInvalidTransition)
+}
+
+func (suite *PaymentServiceTestSuite) TestCaptureProviderFail() {
+	mockProvider := providerMocks.NewPaymentProvider(suite.T())
+	suite.service = NewPaymentService(validation.New(), suite.mockDB, suite.mockRepo, mockProvider)
+
+	suite.expectTransaction()
+	suite.mockRepo.On("GetPaymentByIDForUpdate", inTransaction, "paymentID").
+		Return(&model.Payment{
+			ID:        "paymentID",
+			Reference: "ref",
+			Status:    model.PaymentStatusAuthorized,
+			Amount:    money.New(1250, "USD"),
+		}, nil).Times(1)
+	mockProvider.On("Capture", mock.Anything, "ref", money.New(1250, "USD")).Return(errors.New("error")).Times(1)
+
+	payment, err := suite.service.Capture(context.Background(), "paymentID")
+	suite.Nil(payment)
+	suite.NotNil(err)
+}
+
+func (suite *PaymentServiceTestSuite) TestCaptureGetPaymentFail() {
+	suite.expectTransaction()
+	suite.mockRepo.On("GetPaymentByIDForUpdate", inTransaction, "paymentID").
+		Return(nil, errors.New("error")).Times(1)
+
+	payment, err := suite.service.Capture(context.Background(), "paymentID")
+	suite.Nil(payment)
+	suite.NotNil(err)
+}
+
+// Void
+// =================================================================
+
+func (suite *PaymentServiceTestSuite) TestVoidSuccess() {
+	suite.expectTransaction()
+	suite.mockRepo.On("GetPaymentByIDForUpdate", inTransaction, "paymentID").
+		Return(&model.Payment{
+			ID:        "paymentID",
+			OrderID:   "orderID",
+			Reference: "fake_paymentID",
+			Status:    model.PaymentStatusAuthorized,
+		}, nil).Times(1)
+	suite.expectSave(model.OrderPaymentStatusUnpaid)
+
+	payment, err := suite.service.Void(context.Background(), "paymentID")
+	suite.Nil(err)
+	suite.Equal(model.PaymentStatusVoided, payment.Status)
+}
+
+func (suite *PaymentServiceTestSuite) TestVoidCapturedPayment() {
+	suite.expectTransaction()
+	suite.mockRepo.On("GetPaymentByIDForUpdate", inTransaction, "paymentID").
+		Return(&model.Payment{ID: "paymentID", Status: model.PaymentStatusCaptured}, nil).Times(1)
+
+	payment, err := suite.service.Void(context.Background(), "paymentID")
+	suite.Nil(payment)
+	suite.ErrorIs(err, ErrInvalidTransition)
+}
+
+// Refund
+// =================================================================
+
+func (suite *PaymentServiceTestSuite) TestRefundPartially() {
+	suite.expectTransaction()
+	suite.mockRepo.On("GetPaymentByIDForUpdate", inTransaction, "paymentID").
+		Return(&model.Payment{
+			ID:             "paymentID",
+			OrderID:        "orderID",
+			Reference:      "fake_paymentID",
+			Status:         model.PaymentStatusCaptured,
+			Amount:         money.New(1000, "USD"),
+			CapturedAmount: money.New(1000, "USD"),
+		}, nil).Times(1)
+	suite.expectSave(model.OrderPaymentStatusPartiallyRefunded)
+
+	amount := money.New(400, "USD")
+	payment, err := suite.service.Refund(context.Background(), "paymentID", &dto.RefundPaymentReq{Amount: &amount})
+	suite.Nil(err)
+	suite.Equal(model.PaymentStatusPartiallyRefunded, payment.Status)
+	suite.Equal(money.New(400, "USD"), payment.RefundedAmount)
+}
+
+func (suite *PaymentServiceTestSuite) TestRefundRemaining() {
+	suite.expectTransaction()
+	suite.mockRepo.On("GetPaymentByIDForUpdate", inTransaction, "paymentID").
+		Return(&model.Payment{
+			ID:             "paymentID",
+			OrderID:        "orderID",
+			Reference:      "fake_paymentID",
+			Status:         model.PaymentStatusPartiallyRefunded,
+			Amount:         money.New(1000, "USD"),
+			CapturedAmount: money.New(1000, "USD"),
+			RefundedAmount: money.New(400, "USD"),
+		}, nil).Times(1)
+	suite.expectSave(model.OrderPaymentStatusRefunded)
+
+	payment, err := suite.service.Refund(context.Background(), "paymentID", &dto.RefundPaymentReq{})
+	suite.Nil(err)
+	suite.Equal(model.PaymentStatusRefunded, payment.Status)
+	suite.Equal(money.New(1000, "USD"), payment.RefundedAmount)
+}
+
+func (suite *PaymentServiceTestSuite) TestRefundCallsProviderWithPaymentLocked() {
+	mockProvider := providerMocks.NewPaymentProvider(suite.T())
+	suite.service = NewPaymentService(validation.New(), suite.mockDB, suite.mockRepo, mockProvider)
+
+	suite.expectTransaction()
+	suite.mockRepo.On("GetPaymentByIDForUpdate", inTransaction, "paymentID").
+		Return(&model.Payment{
+			ID:             "paymentID",
+			OrderID:        "orderID",
+			Reference:      "ref",
+			Status:         model.PaymentStatusCaptured,
+			CapturedAmount: money.New(1000, "USD"),
+		}, nil).Times(1)
+	// A concurrent refund waits for the lock, then sees the amount refunded here
+	mockProvider.On("Refund", inTransaction, "ref", money.New(1000, "USD")).Return(nil).Times(1)
+	suite.expectSave(model.OrderPaymentStatusRefunded)
+
+	payment, err := suite.service.Refund(context.Background(), "paymentID", &dto.RefundPaymentReq{})
+	suite.Nil(err)
+	suite.Equal(model.PaymentStatusRefunded, payment.Status)
+}
+
+func (suite *PaymentServiceTestSuite) TestRefundExceedsCaptured() {
+	suite.expectTransaction()
+	suite.mockRepo.On("GetPaymentByIDForUpdate", inTransaction, "paymentID").
+		Return(&model.Payment{
+			ID:             "paymentID",
+			Status:         model.PaymentStatusPartiallyRefunded,
+			CapturedAmount: money.New(1000, "USD"),
+			RefundedAmount: money.New(400, "USD"),
+		}, nil).Times(1)
+
+	amount := money.New(700, "USD")
+	payment, err := suite.service.Refund(context.Background(), "paymentID", &dto.RefundPaymentReq{Amount: &amount})
+	suite.Nil(payment)
+	suite.ErrorIs(err, ErrRefundExceedsCaptured)
+}
+
+func (suite *PaymentServiceTestSuite) TestRefundNotCaptured() {
+	suite.expectTransaction()
+	suite.mockRepo.On("GetPaymentByIDForUpdate", inTransaction, "paymentID").
+		Return(&model.Payment{ID: "paymentID", Status: model.PaymentStatusAuthorized, Amount: money.New(1000, "USD")}, nil).Times(1)
+
+	amount := money.New(100, "USD")
+	payment, err := suite.service.Refund(context.Background(), "paymentID", &dto.RefundPaymentReq{Amount: &amount})
+	suite.Nil(payment)
+	suite.ErrorIs(err, ErrInvalidTransition)
+}
+
+func (suite *PaymentServiceTestSuite) TestRefundInvalidAmount() {
+	amount := money.New(-100, "USD")
+	payment, err := suite.service.Refund(context.Background(), "paymentID", &dto.RefundPaymentReq{Amount: &amount})
+	suite.Nil(payment)
+	suite.NotNil(err)
+}
+
+// GetPaymentByID
+// =================================================================
+
+func (suite *PaymentServiceTestSuite) TestGetPaymentByIDSuccess() {
+	suite.mockRepo.On("GetPaymentByID", mock.Anything, "paymentID").
+		Return(&model.Payment{ID: "paymentID", UserID: "userID"}, nil).Times(1)
+
+	payment, err := suite.service.GetPaymentByID(context.Background(), "paymentID", "userID")
+	suite.Nil(err)
+	suite.Equal("paymentID", payment.ID)
+}
+
+func (suite *PaymentServiceTestSuite) TestGetPaymentByIDNotMine() {
+	suite.mockRepo.On("GetPaymentByID", mock.Anything, "paymentID").
+		Return(&model.Payment{ID: "paymentID", UserID: "otherID"}, nil).Times(1)
+
+	payment, err := suite.service.GetPaymentByID(context.Background(), "paymentID", "userID")
+	suite.Nil(payment)
+	suite.NotNil(err)
+}
+
+func (suite *PaymentServiceTestSuite) TestGetPaymentByIDWithoutOwnerCheck() {
+	suite.mockRepo.On("GetPaymentByID", mock.Anything, "paymentID").
+		Return(&model.Payment{ID: "paymentID", UserID: "otherID"}, nil).Times(1)
+
+	payment, err := suite.service.GetPaymentByID(context.Background(), "paymentID", "")
+	suite.Nil(err)
+	suite.Equal("paymentID", payment.ID)
+}
+
+// HandleWebhook
+// =================================================================
+
+func (suite *PaymentServiceTestSuite) TestHandleWebhookCaptured() {
+	payload, signature := suite.webhook(&provider.Event{
+		ID:        "evt1",
+		Type:      provider.EventCaptured,
+		Reference: "fake_paymentID",
+		Amount:    money.New(1250, "USD"),
+	})
+
+	suite.expectTransaction()
+	suite.mockRepo.On("GetPaymentByReferenceForUpdate", inTransaction, provider.FakeProviderName, "fake_paymentID").
+		Return(&model.Payment{
+			ID:      "paymentID",
+			OrderID: "orderID",
+			Status:  model.PaymentStatusAuthorized,
+			Amount:  money.New(1250, "USD"),
+		}, nil).Times(1)
+	suite.expectSave(model.OrderPaymentStatusPaid)
+
+	payment, err := suite.service.HandleWebhook(context.Background(), payload, signature)
+	suite.Nil(err)
+	suite.Equal(model.PaymentStatusCaptured, payment.Status)
+	suite.Equal(money.New(1250, "USD"), payment.CapturedAmount)
+}
+
+func (suite *PaymentServiceTestSuite) TestHandleWebhookRefunded() {
+	payload, signature := suite.webhook(&provider.Event{
+		Type:      provider.EventRefunded,
+		Reference: "fake_paymentID",
+		Amount:    money.New(300, "USD"),
+	})
+
+	suite.expectTransaction()
+	suite.mockRepo.On("GetPaymentByReferenceForUpdate", inTransaction, provider.FakeProviderName, "fake_paymentID").
+		Return(&model.Payment{
+			ID:             "paymentID",
+			OrderID:        "orderID",
+			Status:         model.PaymentStatusCaptured,
+			Amount:         money.New(1000, "USD"),
+			CapturedAmount: money.New(1000, "USD"),
+		}, nil).Times(1)
+	suite.expectSave(model.OrderPaymentStatusPartiallyRefunded)
+
+	payment, err := suite.service.HandleWebhook(context.Background(), payload, signature)
+	suite.Nil(err)
+	suite.Equal(model.PaymentStatusPartiallyRefunded, payment.Status)
+	suite.Equal(money.New(300, "USD"), payment.RefundedAmount)
+}
+
+func (suite *PaymentServiceTestSuite) TestHandleWebhookFailed() {
+	payload, signature := suite.webhook(&provider.Event{
+		Type:      provider.EventFailed,
+		Reference: "fake_paymentID",
+		Reason:    "fraud suspected",
+	})
+
+	suite.expectTransaction()
+	suite.mockRepo.On("GetPaymentByReferenceForUpdate", inTransaction, provider.FakeProviderName, "fake_paymentID").
+		Return(&model.Payment{ID: "paymentID", OrderID: "orderID", Status: model.PaymentStatusAuthorized}, nil).Times(1)
+	suite.expectSave(model.OrderPaymentStatusUnpaid)
+
+	payment, err := suite.service.HandleWebhook(context.Background(), payload, signature)
+	suite.Nil(err)
+	suite.Equal(model.PaymentStatusFailed, payment.Status)
+	suite.Equal("fraud suspected", payment.FailureReason)
+}
+
+func (suite *PaymentServiceTestSuite) TestHandleWebhookAlreadyApplied() {
+	payload, signature := suite.webhook(&provider.Event{
+		Type:      provider.EventRefunded,
+		Reference: "fake_paymentID",
+		Amount:    money.New(1000, "USD"),
+	})
+
+	suite.expectTransaction()
+	suite.mockRepo.On("GetPaymentByReferenceForUpdate", inTransaction, provider.FakeProviderName, "fake_paymentID").
+		Return(&model.Payment{
+			ID:             "paymentID",
+			Status:         model.PaymentStatusRefunded,
+			CapturedAmount: money.New(1000, "USD"),
+			RefundedAmount: money.New(1000, "USD"),
+		}, nil).Times(1)
+
+	payment, err := suite.service.HandleWebhook(context.Background(), payload, signature)
+	suite.Nil(err)
+	suite.Equal(model.PaymentStatusRefunded, payment.Status)
+}
+
+func (suite *PaymentServiceTestSuite) TestHandleWebhookInvalidTransition() {
+	payload, signature := suite.webhook(&provider.Event{
+		Type:      provider.EventCaptured,
+		Reference: "fake_paymentID",
+	})
+
+	suite.expectTransaction()
+	suite.mockRepo.On("GetPaymentByReferenceForUpdate", inTransaction, provider.FakeProviderName, "fake_paymentID").
+		Return(&model.Payment{ID: "paymentID", Status: model.PaymentStatusVoided}, nil).Times(1)
+
+	payment, err := suite.service.HandleWebhook(context.Background(), payload, signature)
+	suite.Nil(payment)
+	suite.ErrorIs(err, ErrInvalidTransition)
+}
+
+func (suite *PaymentServiceTestSuite) TestHandleWebhookInvalidSignature() {
+	payload, _ := suite.webhook(&provider.Event{
+		Type:      provider.EventCaptured,
+		Reference: "fake_paymentID",
+	})
+
+	payment, err := suite.service.HandleWebhook(context.Background(), payload, "invalid")
+	suite.Nil(payment)
+	suite.ErrorIs(err, provider.ErrInvalidSignature)
+}
+
+func (suite *PaymentServiceTestSuite) TestHandleWebhookUnknownPayment() {
+	payload, signature := suite.webhook(&provider.Event{
+		Type:      provider.EventCaptured,
+		Reference: "fake_unknown",
+	})
+
+	suite.expectTransaction()
+	suite.mockRepo.On("GetPaymentByReferenceForUpdate", inTransaction, provider.FakeProviderName, "fake_unknown").
+		Return(nil, errors.New("error")).Times(1)
+
+	payment, err := suite.service.HandleWebhook(context.Background(), payload, signature)
+	suite.Nil(payment)
+	suite.NotNil(err)
+}
@@ -0,0 +1,41 @@
+package dto
+
+import (
+	"time"
+)
+
+type Category struct {
+	ID        string      `json:"id"`
+	ParentID  *string     `json:"parent_id,omitempty"`
+	Name      string      `json:"name"`
+	Slug      string      `json:"slug"`
+	Position  int         `json:"position"`
+	Children  []*Category `json:"children,omitempty"`
+	CreatedAt time.Time   `json:"created_at"`
+	UpdatedAt time.Time   `json:"updated_at"`
+}
+
+// CreateCategoryReq creates a category under ParentID, at the top level when it is empty.
+// Slug is made from Name when it is empty.
+type CreateCategoryReq struct {
+	ParentID *string `json:"parent_id,omitempty"`
+	Name     string  `json:"name" validate:"required,max=100"`
+	Slug     string  `json:"slug,omitempty" validate:"max=100"`
+	Position int     `json:"position,omitempty"`
+}
+
+// UpdateCategoryReq changes the fields it has. An empty ParentID moves the category to the top level.
+type UpdateCategoryReq struct {
+	ParentID *string `json:"parent_id,omitempty"`
+	Name     string  `json:"name,omitempty" validate:"max=100"`
+	Slug     string  `json:"slug,omitempty" validate:"max=100"`
+	Position *int    `json:"position,omitempty"`
+}
+
+type SetProductCategoriesReq struct {
+	CategoryIDs []string `json:"category_ids" validate:"lte=20,dive,required"`
+}
+
+type ListCategoryRes struct {
+	Categories []*Category `json:"categories"`
+}
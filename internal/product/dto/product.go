@@ -3,42 +3,95 @@ package dto
 import (
 	"time"
 
+	"goshop/pkg/money"
 	"goshop/pkg/paging"
 )
 
 type Product struct {
-	ID          string    `json:"id"`
-	Code        string    `json:"code"`
-	Name        string    `json:"name"`
-	Description string    `json:"description"`
-	Price       float64   `json:"price"`
-	Active      bool      `json:"active"`
-	CreatedAt   time.Time `json:"created_at"`
-	UpdatedAt   time.Time `json:"updated_at"`
+	ID          string           `json:"id"`
+	Code        string           `json:"code"`
+	Name        string           `json:"name"`
+	Description string           `json:"description"`
+	Price       money.Money      `json:"price"`
+	Active      bool             `json:"active"`
+	Stock       *ProductStock    `json:"stock,omitempty"`
+	Categories  []*Category      `json:"categories,omitempty"`
+	Options     []*ProductOption `json:"options,omitempty"`
+	Variants    []*Variant       `json:"variants,omitempty"`
+	CreatedAt   time.Time        `json:"created_at"`
+	UpdatedAt   time.Time        `json:"updated_at"`
 }
 
+// ListProductReq filters, sorts and paginates products. MinPrice and MaxPrice are decimal amounts
+// in the major unit of Currency, which they require.
 type ListProductReq struct {
-	Name      string `json:"name,omitempty" form:"name"`
-	Code      string `json:"code,omitempty" form:"code"`
+	Name string `json:"name,omitempty" form:"name"`
+	Code string `json:"code,omitempty" form:"code"`
+	// Category is the slug of a category of the products
+	Category string `json:"category,omitempty" form:"category"`
+	// IncludeSubcategories also lists the products of the descendants of Category
+	IncludeSubcategories bool       `json:"include_subcategories,omitempty" form:"include_subcategories"`
+	MinPrice             string     `json:"min_price,omitempty" form:"min_price"`
+	MaxPrice             string     `json:"max_price,omitempty" form:"max_price"`
+	Currency             string     `json:"currency,omitempty" form:"currency" validate:"required_with=MinPrice MaxPrice"`
+	Active               *bool      `json:"active,omitempty" form:"active"`
+	CreatedAfter         *time.Time `json:"created_after,omitempty" form:"created_after" time_format:"2006-01-02T15:04:05Z07:00"`
+	// Options are "name:value" pairs the products must have a variant with. Products having any
+	// of the values given for a name match it.
+	Options []string `json:"options,omitempty" form:"option" validate:"lte=20,dive,contains=:"`
+	// Cursor is the next_cursor or prev_cursor of a page, it replaces Page
+	Cursor    string `json:"-" form:"cursor"`
 	Page      int64  `json:"-" form:"page"`
 	Limit     int64  `json:"-" form:"limit"`
-	OrderBy   string `json:"-" form:"order_by"`
+	OrderBy   string `json:"-" form:"order_by" validate:"omitempty,oneof=created_at updated_at name price"`
 	OrderDesc bool   `json:"-" form:"order_desc"`
 }
 
+type SearchProductReq struct {
+	Query string `json:"q" form:"q" validate:"required,max=200"`
+	Page  int64  `json:"-" form:"page"`
+	Limit int64  `json:"-" form:"limit"`
+}
+
 type ListProductRes struct {
 	Products   []*Product         `json:"products"`
 	Pagination *paging.Pagination `json:"pagination"`
+	Facets     *ProductFacets     `json:"facets,omitempty"`
+}
+
+// ProductFacets counts the products of a listing by category and by option value
+type ProductFacets struct {
+	Categories []*CategoryFacet `json:"categories"`
+	Options    []*OptionFacet   `json:"options"`
+}
+
+type CategoryFacet struct {
+	Slug  string `json:"slug"`
+	Name  string `json:"name"`
+	Count int64  `json:"count"`
+}
+
+type OptionFacet struct {
+	Name   string              `json:"name"`
+	Values []*OptionValueFacet `json:"values"`
+}
+
+type OptionValueFacet struct {
+	Value string `json:"value"`
+	Count int64  `json:"count"`
 }
 
+// CreateProductReq creates a product with its options and variants
 type CreateProductReq struct {
-	Name        string  `json:"name" validate:"required"`
-	Description string  `json:"description" validate:"required"`
-	Price       float64 `json:"price" validate:"gt=0"`
+	Name        string              `json:"name" validate:"required"`
+	Description string              `json:"description" validate:"required"`
+	Price       money.Money         `json:"price"`
+	Options     []*CreateOptionReq  `json:"options,omitempty" validate:"lte=5,dive"`
+	Variants    []*CreateVariantReq `json:"variants,omitempty" validate:"lte=100,dive"`
 }
 
 type UpdateProductReq struct {
-	Name        string  `json:"name,omitempty"`
-	Description string  `json:"description,omitempty"`
-	Price       float64 `json:"price,omitempty" validate:"gte=0"`
+	Name        string       `json:"name,omitempty"`
+	Description string       `json:"description,omitempty"`
+	Price       *money.Money `json:"price,omitempty"`
 }
@@ -0,0 +1,44 @@
+package dto
+
+import (
+	"time"
+
+	"goshop/pkg/paging"
+)
+
+type ProductStock struct {
+	OnHand    int64     `json:"on_hand"`
+	Reserved  int64     `json:"reserved"`
+	Available int64     `json:"available"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+type StockMovement struct {
+	ID        string    `json:"id"`
+	ProductID string    `json:"product_id"`
+	VariantID string    `json:"variant_id,omitempty"`
+	OrderID   string    `json:"order_id,omitempty"`
+	Type      string    `json:"type"`
+	Reason    string    `json:"reason"`
+	Quantity  int64     `json:"quantity"`
+	Note      string    `json:"note,omitempty"`
+	CreatedBy string    `json:"created_by,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type AdjustStockReq struct {
+	Quantity int64  `json:"quantity" validate:"required"`
+	Reason   string `json:"reason" validate:"required,oneof=restock damaged correction return"`
+	Note     string `json:"note,omitempty"`
+}
+
+type ListStockMovementReq struct {
+	Type  string `json:"type,omitempty" form:"type"`
+	Page  int64  `json:"-" form:"page"`
+	Limit int64  `json:"-" form:"limit"`
+}
+
+type ListStockMovementRes struct {
+	Movements  []*StockMovement   `json:"movements"`
+	Pagination *paging.Pagination `json:"pagination"`
+}
@@ -0,0 +1,59 @@
+package dto
+
+import (
+	"time"
+
+	"goshop/pkg/money"
+)
+
+type ProductOption struct {
+	ID       string                `json:"id"`
+	Name     string                `json:"name"`
+	Position int                   `json:"position"`
+	Values   []*ProductOptionValue `json:"values"`
+}
+
+type ProductOptionValue struct {
+	Value    string `json:"value"`
+	Position int    `json:"position"`
+}
+
+// Variant is a combination of option values of a product, Price is its selling price
+type Variant struct {
+	ID        string           `json:"id"`
+	SKU       string           `json:"sku"`
+	Price     money.Money      `json:"price"`
+	Active    bool             `json:"active"`
+	Options   []*VariantOption `json:"options"`
+	Stock     *ProductStock    `json:"stock,omitempty"`
+	CreatedAt time.Time        `json:"created_at"`
+	UpdatedAt time.Time        `json:"updated_at"`
+}
+
+type VariantOption struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+// CreateOptionReq adds an option type to a product, or values to an option type it has
+type CreateOptionReq struct {
+	Name   string   `json:"name" validate:"required,max=50"`
+	Values []string `json:"values" validate:"required,lte=50,dive,required,max=50"`
+}
+
+// CreateVariantReq creates a variant taking a value of every option of the product.
+// The variant sells at the product price when Price is nil.
+type CreateVariantReq struct {
+	SKU     string            `json:"sku" validate:"required,max=64"`
+	Price   *money.Money      `json:"price,omitempty"`
+	Active  *bool             `json:"active,omitempty"`
+	Options map[string]string `json:"options"`
+}
+
+// UpdateVariantReq changes the fields it has, a zero Price makes the variant sell at the
+// product price
+type UpdateVariantReq struct {
+	SKU    string       `json:"sku,omitempty" validate:"max=64"`
+	Price  *money.Money `json:"price,omitempty"`
+	Active *bool        `json:"active,omitempty"`
+}
@@ -0,0 +1,34 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Category is a node of the category tree products are classified in
+type Category struct {
+	ID        string     `json:"id" gorm:"unique;not null;index;primary_key"`
+	CreatedAt time.Time  `json:"created_at"`
+	UpdatedAt time.Time  `json:"updated_at"`
+	DeletedAt *time.Time `json:"deleted_at" gorm:"index"`
+	// ParentID is nil for the top level categories
+	ParentID *string `json:"parent_id" gorm:"index"`
+	Name     string  `json:"name" gorm:"not null"`
+	Slug     string  `json:"slug" gorm:"uniqueIndex:idx_category_slug,not null"`
+	// Position orders the children of a parent, lowest first
+	Position int         `json:"position" gorm:"not null;default:0"`
+	Children []*Category `json:"children,omitempty" gorm:"-"`
+}
+
+func (m *Category) BeforeCreate(tx *gorm.DB) error {
+	m.ID = uuid.New().String()
+	return nil
+}
+
+// ProductCategory assigns a product to a category
+type ProductCategory struct {
+	ProductID  string `gorm:"primary_key"`
+	CategoryID string `gorm:"primary_key"`
+}
@@ -1,29 +1,88 @@
 package model
 
 import (
+	"sort"
 	"time"
 
 	"github.com/google/uuid"
 	"gorm.io/gorm"
 
+	"goshop/pkg/money"
 	"goshop/pkg/utils"
 )
 
 type Product struct {
-	ID          string     `json:"id" gorm:"unique;not null;index;primary_key"`
-	CreatedAt   time.Time  `json:"created_at"`
-	UpdatedAt   time.Time  `json:"updated_at"`
-	DeletedAt   *time.Time `json:"deleted_at" gorm:"index"`
-	Code        string     `json:"code" gorm:"uniqueIndex:idx_product_code,not null"`
-	Name        string     `json:"name" gorm:"uniqueIndex:idx_product_name,not null"`
-	Description string     `json:"description"`
-	Price       float64    `json:"price"`
-	Active      bool       `json:"active" gorm:"default:true"`
+	ID          string        `json:"id" gorm:"unique;not null;index;primary_key"`
+	CreatedAt   time.Time     `json:"created_at"`
+	UpdatedAt   time.Time     `json:"updated_at"`
+	DeletedAt   *time.Time    `json:"deleted_at" gorm:"index"`
+	Code        string        `json:"code" gorm:"uniqueIndex:idx_product_code,not null"`
+	Name        string        `json:"name" gorm:"uniqueIndex:idx_product_name,not null"`
+	Description string        `json:"description"`
+	Price       money.Money   `json:"price" gorm:"embedded;embeddedPrefix:price_"`
+	Active      bool          `json:"active" gorm:"default:true"`
+	Stock       *ProductStock `json:"stock,omitempty"`
+	Categories  []*Category   `json:"categories,omitempty" gorm:"many2many:product_categories"`
+	// Options must be declared before Variants, creating a product creates them in this order
+	Options  []*ProductOption  `json:"options,omitempty"`
+	Variants []*ProductVariant `json:"variants,omitempty"`
 }
 
 func (m *Product) BeforeCreate(tx *gorm.DB) error {
 	m.ID = uuid.New().String()
 	m.Code = utils.GenerateCode("P")
 	m.Active = true
+	if m.Stock == nil {
+		m.Stock = &ProductStock{}
+	}
 	return nil
 }
+
+// AfterFind orders the options of the product and prices its variants
+func (m *Product) AfterFind(tx *gorm.DB) error {
+	sort.SliceStable(m.Options, func(i, j int) bool {
+		return m.Options[i].Position < m.Options[j].Position
+	})
+	for _, option := range m.Options {
+		values := option.Values
+		sort.SliceStable(values, func(i, j int) bool {
+			return values[i].Position < values[j].Position
+		})
+	}
+	for _, variant := range m.Variants {
+		variant.ResolvePrice(m)
+	}
+	return nil
+}
+
+// Variant returns the variant of the product with id, nil when there is none
+func (m *Product) Variant(id string) *ProductVariant {
+	for _, variant := range m.Variants {
+		if variant.ID == id {
+			return variant
+		}
+	}
+	return nil
+}
+
+// ProductFacets counts the products of a listing by category and by option value
+type ProductFacets struct {
+	Categories []*CategoryFacet `json:"categories"`
+	Options    []*OptionFacet   `json:"options"`
+}
+
+type CategoryFacet struct {
+	Slug  string `json:"slug"`
+	Name  string `json:"name"`
+	Count int64  `json:"count"`
+}
+
+type OptionFacet struct {
+	Name   string              `json:"name"`
+	Values []*OptionValueFacet `json:"values"`
+}
+
+type OptionValueFacet struct {
+	Value string `json:"value"`
+	Count int64  `json:"count"`
+}
@@ -0,0 +1,79 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// ProductStock is kept apart from Product so that saving a product never overwrites
+// stock changed concurrently by orders. It is only changed through atomic updates.
+type ProductStock struct {
+	ProductID string    `json:"-" gorm:"primary_key"`
+	UpdatedAt time.Time `json:"updated_at"`
+	OnHand    int64     `json:"on_hand" gorm:"not null;default:0"`
+	Reserved  int64     `json:"reserved" gorm:"not null;default:0"`
+	Available int64     `json:"available" gorm:"-"`
+}
+
+func (m *ProductStock) AfterFind(tx *gorm.DB) error {
+	m.Available = m.OnHand - m.Reserved
+	return nil
+}
+
+// VariantStock is the stock of a variant, products with variants keep their stock by variant
+type VariantStock struct {
+	VariantID string    `json:"-" gorm:"primary_key"`
+	UpdatedAt time.Time `json:"updated_at"`
+	OnHand    int64     `json:"on_hand" gorm:"not null;default:0"`
+	Reserved  int64     `json:"reserved" gorm:"not null;default:0"`
+	Available int64     `json:"available" gorm:"-"`
+}
+
+func (m *VariantStock) AfterFind(tx *gorm.DB) error {
+	m.Available = m.OnHand - m.Reserved
+	return nil
+}
+
+type StockMovementType string
+
+const (
+	// StockMovementAdjustment changes stock on hand by Quantity, which may be negative
+	StockMovementAdjustment StockMovementType = "adjustment"
+	// StockMovementReservation reserves Quantity for an order
+	StockMovementReservation StockMovementType = "reservation"
+	// StockMovementRelease releases Quantity reserved for a cancelled order
+	StockMovementRelease StockMovementType = "release"
+	// StockMovementCommit removes Quantity reserved for a done order from stock on hand
+	StockMovementCommit StockMovementType = "commit"
+)
+
+type StockReason string
+
+const (
+	StockReasonRestock    StockReason = "restock"
+	StockReasonDamaged    StockReason = "damaged"
+	StockReasonCorrection StockReason = "correction"
+	StockReasonReturn     StockReason = "return"
+	StockReasonOrder      StockReason = "order"
+)
+
+// StockMovement is an entry of the append-only stock ledger
+type StockMovement struct {
+	ID        string            `json:"id" gorm:"unique;not null;index;primary_key"`
+	CreatedAt time.Time         `json:"created_at"`
+	ProductID string            `json:"product_id" gorm:"not null;index"`
+	VariantID string            `json:"variant_id,omitempty" gorm:"index"`
+	OrderID   string            `json:"order_id,omitempty" gorm:"index"`
+	Type      StockMovementType `json:"type" gorm:"not null"`
+	Reason    StockReason       `json:"reason" gorm:"not null"`
+	Quantity  int64             `json:"quantity" gorm:"not null"`
+	Note      string            `json:"note,omitempty"`
+	CreatedBy string            `json:"created_by,omitempty"`
+}
+
+func (m *StockMovement) BeforeCreate(tx *gorm.DB) error {
+	m.ID = uuid.New().String()
+	return nil
+}
@@ -0,0 +1,83 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+
+	"goshop/pkg/money"
+)
+
+// ProductOption is an option type of a product, such as size or colour, and the values
+// its variants may take
+type ProductOption struct {
+	ID        string                `json:"id" gorm:"unique;not null;index;primary_key"`
+	ProductID string                `json:"product_id" gorm:"not null;uniqueIndex:idx_product_option_name"`
+	Name      string                `json:"name" gorm:"not null;uniqueIndex:idx_product_option_name"`
+	Position  int                   `json:"position" gorm:"not null;default:0"`
+	Values    []*ProductOptionValue `json:"values" gorm:"foreignKey:OptionID"`
+}
+
+type ProductOptionValue struct {
+	OptionID string `json:"-" gorm:"primary_key"`
+	Value    string `json:"value" gorm:"primary_key"`
+	Position int    `json:"position" gorm:"not null;default:0"`
+}
+
+// ProductVariant is a sellable combination of option values of a product
+type ProductVariant struct {
+	ID        string     `json:"id" gorm:"unique;not null;index;primary_key"`
+	CreatedAt time.Time  `json:"created_at"`
+	UpdatedAt time.Time  `json:"updated_at"`
+	DeletedAt *time.Time `json:"deleted_at" gorm:"index"`
+	ProductID string     `json:"product_id" gorm:"not null;index"`
+	SKU       string     `json:"sku" gorm:"column:sku;uniqueIndex:idx_product_variant_sku;not null"`
+	// PriceOverride is the price of the variant, the product price is used when it is zero
+	PriceOverride money.Money `json:"-" gorm:"embedded;embeddedPrefix:price_"`
+	// Price is the price the variant sells at, set when the variant is read with its product
+	Price   money.Money      `json:"price" gorm:"-"`
+	Active  bool             `json:"active" gorm:"default:true"`
+	Options []*VariantOption `json:"options" gorm:"foreignKey:VariantID"`
+	Stock   *VariantStock    `json:"stock,omitempty" gorm:"foreignKey:VariantID"`
+}
+
+// VariantOption is the value of an option of the product taken by a variant
+type VariantOption struct {
+	VariantID string `json:"-" gorm:"primary_key"`
+	Name      string `json:"name" gorm:"primary_key"`
+	Value     string `json:"value" gorm:"not null"`
+}
+
+func (m *ProductOption) BeforeCreate(tx *gorm.DB) error {
+	if m.ID == "" {
+		m.ID = uuid.New().String()
+	}
+	return nil
+}
+
+func (m *ProductVariant) BeforeCreate(tx *gorm.DB) error {
+	m.ID = uuid.New().String()
+	if m.Stock == nil {
+		m.Stock = &VariantStock{}
+	}
+	return nil
+}
+
+// ResolvePrice sets the price of the variant of product
+func (m *ProductVariant) ResolvePrice(product *Product) {
+	m.Price = m.PriceOverride
+	if m.PriceOverride.IsZero() {
+		m.Price = product.Price
+	}
+}
+
+// Option returns the value of the option name of the variant
+func (m *ProductVariant) Option(name string) string {
+	for _, option := range m.Options {
+		if option.Name == name {
+			return option.Value
+		}
+	}
+	return ""
+}
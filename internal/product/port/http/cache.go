@@ -0,0 +1,16 @@
+package http
+
+// Tags of the cached responses, invalidated by the writes changing them
+const (
+	// productTag tags every cached response showing products
+	productTag = "product"
+	// productListTag tags the cached lists and searches of products
+	productListTag = "product:list"
+	// categoryTag tags the cached category tree and category product lists
+	categoryTag = "category"
+)
+
+// productIDTag tags the cached details of the product with id
+func productIDTag(id string) string {
+	return "product:" + id
+}
@@ -0,0 +1,237 @@
+package http
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/quangdangfit/gocommon/logger"
+
+	"goshop/internal/product/dto"
+	"goshop/internal/product/repository"
+	"goshop/internal/product/service"
+	"goshop/pkg/config"
+	"goshop/pkg/paging"
+	"goshop/pkg/redis"
+	"goshop/pkg/response"
+	"goshop/pkg/utils"
+)
+
+type CategoryHandler struct {
+	cache   redis.IRedis
+	service service.ICategoryService
+}
+
+func NewCategoryHandler(
+	cache redis.IRedis,
+	service service.ICategoryService,
+) *CategoryHandler {
+	return &CategoryHandler{
+		cache:   cache,
+		service: service,
+	}
+}
+
+// ListCategories godoc
+//
+//	@Summary	Get the category tree
+//	@Tags		categories
+//	@Produce	json
+//	@Success	200	{object}	dto.ListCategoryRes
+//	@Router		/api/v1/categories [get]
+func (h *CategoryHandler) ListCategories(c *gin.Context) {
+	var res dto.ListCategoryRes
+	cacheKey := c.Request.URL.RequestURI()
+	err := h.cache.Get(cacheKey, &res)
+	if err == nil {
+		response.JSON(c, http.StatusOK, res)
+		return
+	}
+
+	categories, err := h.service.ListCategories(c)
+	if err != nil {
+		logger.Error("Failed to get list categories: ", err)
+		response.Error(c, http.StatusInternalServerError, err, "Something went wrong")
+		return
+	}
+
+	utils.Copy(&res.Categories, &categories)
+	response.JSON(c, http.StatusOK, res)
+	_ = h.cache.SetWithTags(cacheKey, res, config.ProductCachingTime, categoryTag)
+}
+
+// GetCategoryBySlug godoc
+//
+//	@Summary	Get category by slug
+//	@Tags		categories
+//	@Produce	json
+//	@Param		slug	path		string	true	"Category slug"
+//	@Success	200		{object}	dto.Category
+//	@Router		/api/v1/categories/{slug} [get]
+func (h *CategoryHandler) GetCategoryBySlug(c *gin.Context) {
+	category, err := h.service.GetCategoryBySlug(c, c.Param("slug"))
+	if err != nil {
+		logger.Error("Failed to get category detail: ", err)
+		response.Error(c, http.StatusNotFound, err, "Not found")
+		return
+	}
+
+	var res dto.Category
+	utils.Copy(&res, &category)
+	response.JSON(c, http.StatusOK, res)
+}
+
+// ListCategoryProducts godoc
+//
+//	@Summary	Get list products of a category and its subcategories
+//	@Tags		categories
+//	@Produce	json
+//	@Param		slug	path		string				true	"Category slug"
+//	@Param		_		query		dto.ListProductReq	true	"Query"
+//	@Success	200		{object}	dto.ListProductRes
+//	@Router		/api/v1/categories/{slug}/products [get]
+func (h *CategoryHandler) ListCategoryProducts(c *gin.Context) {
+	var req dto.ListProductReq
+	if err := c.ShouldBindQuery(&req); err != nil {
+		logger.Error("Failed to parse request query: ", err)
+		response.Error(c, http.StatusBadRequest, err, "Invalid parameters")
+		return
+	}
+
+	var res dto.ListProductRes
+	cacheKey := c.Request.URL.RequestURI()
+	err := h.cache.Get(cacheKey, &res)
+	if err == nil {
+		response.JSON(c, http.StatusOK, res)
+		return
+	}
+
+	products, pagination, err := h.service.ListProducts(c, c.Param("slug"), &req)
+	if err != nil {
+		logger.Error("Failed to get list products: ", err)
+		if errors.Is(err, repository.ErrCategoryNotFound) {
+			response.Error(c, http.StatusNotFound, err, "Not found")
+			return
+		}
+		if errors.Is(err, service.ErrInvalidFilter) || errors.Is(err, paging.ErrInvalidCursor) {
+			response.Error(c, http.StatusBadRequest, err, err.Error())
+			return
+		}
+		response.Error(c, http.StatusInternalServerError, err, "Something went wrong")
+		return
+	}
+
+	utils.Copy(&res.Products, &products)
+	res.Pagination = pagination
+	response.JSON(c, http.StatusOK, res)
+	_ = h.cache.SetWithTags(cacheKey, res, config.ProductCachingTime, categoryTag, productTag, productListTag)
+}
+
+// CreateCategory godoc
+//
+//	@Summary	create category
+//	@Tags		categories
+//	@Produce	json
+//	@Security	ApiKeyAuth
+//	@Param		_	body		dto.CreateCategoryReq	true	"Body"
+//	@Success	200	{object}	dto.Category
+//	@Router		/api/v1/categories [post]
+func (h *CategoryHandler) CreateCategory(c *gin.Context) {
+	var req dto.CreateCategoryReq
+	if err := c.ShouldBindJSON(&req); c.Request.Body == nil || err != nil {
+		logger.Error("Failed to get body", err)
+		response.Error(c, http.StatusBadRequest, err, "Invalid parameters")
+		return
+	}
+
+	category, err := h.service.Create(c, &req)
+	if err != nil {
+		logger.Error("Failed to create category", err.Error())
+		if isCategoryError(err) {
+			response.Error(c, http.StatusBadRequest, err, err.Error())
+			return
+		}
+		response.Error(c, http.StatusInternalServerError, err, "Something went wrong")
+		return
+	}
+
+	var res dto.Category
+	utils.Copy(&res, &category)
+	response.JSON(c, http.StatusOK, res)
+	_ = h.cache.InvalidateTags(categoryTag)
+}
+
+// UpdateCategory godoc
+//
+//	@Summary	update category
+//	@Tags		categories
+//	@Produce	json
+//	@Security	ApiKeyAuth
+//	@Param		id	path		string					true	"Category ID"
+//	@Param		_	body		dto.UpdateCategoryReq	true	"Body"
+//	@Success	200	{object}	dto.Category
+//	@Router		/api/v1/categories/{id} [put]
+func (h *CategoryHandler) UpdateCategory(c *gin.Context) {
+	var req dto.UpdateCategoryReq
+	if err := c.ShouldBindJSON(&req); c.Request.Body == nil || err != nil {
+		logger.Error("Failed to get body", err)
+		response.Error(c, http.StatusBadRequest, err, "Invalid parameters")
+		return
+	}
+
+	category, err := h.service.Update(c, c.Param("id"), &req)
+	if err != nil {
+		logger.Error("Failed to update category", err.Error())
+		if errors.Is(err, repository.ErrCategoryNotFound) {
+			response.Error(c, http.StatusNotFound, err, "Not found")
+			return
+		}
+		if isCategoryError(err) {
+			response.Error(c, http.StatusBadRequest, err, err.Error())
+			return
+		}
+		response.Error(c, http.StatusInternalServerError, err, "Something went wrong")
+		return
+	}
+
+	var res dto.Category
+	utils.Copy(&res, &category)
+	response.JSON(c, http.StatusOK, res)
+	_ = h.cache.InvalidateTags(categoryTag, productTag)
+}
+
+// DeleteCategory godoc
+//
+//	@Summary	delete category without subcategories
+//	@Tags		categories
+//	@Produce	json
+//	@Security	ApiKeyAuth
+//	@Param		id	path	string	true	"Category ID"
+//	@Router		/api/v1/categories/{id} [delete]
+func (h *CategoryHandler) DeleteCategory(c *gin.Context) {
+	err := h.service.Delete(c, c.Param("id"))
+	if err != nil {
+		logger.Error("Failed to delete category", err.Error())
+		if errors.Is(err, repository.ErrCategoryNotFound) {
+			response.Error(c, http.StatusNotFound, err, "Not found")
+			return
+		}
+		if isCategoryError(err) {
+			response.Error(c, http.StatusBadRequest, err, err.Error())
+			return
+		}
+		response.Error(c, http.StatusInternalServerError, err, "Something went wrong")
+		return
+	}
+
+	response.JSON(c, http.StatusOK, nil)
+	_ = h.cache.InvalidateTags(categoryTag, productTag)
+}
+
+func isCategoryError(err error) bool {
+	return errors.Is(err, service.ErrInvalidSlug) ||
+		errors.Is(err, service.ErrSlugTaken) ||
+		errors.Is(err, service.ErrParentNotFound) ||
+		errors.Is(err, service.ErrCategoryCycle) ||
+		errors.Is(err, service.ErrCategoryHasChildren)
+}
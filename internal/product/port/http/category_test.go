@@ -0,0 +1,319 @@
+package http
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/quangdangfit/gocommon/logger"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+
+	"goshop/internal/product/dto"
+	"goshop/internal/product/model"
+	"goshop/internal/product/repository"
+	"goshop/internal/product/service"
+	srvMocks "goshop/internal/product/service/mocks"
+	"goshop/pkg/config"
+	"goshop/pkg/paging"
+	redisMocks "goshop/pkg/redis/mocks"
+	"goshop/pkg/response"
+	"goshop/pkg/utils"
+)
+
+type CategoryHandlerTestSuite struct {
+	suite.Suite
+	mockService *srvMocks.ICategoryService
+	mockRedis   *redisMocks.IRedis
+	handler     *CategoryHandler
+}
+
+func (suite *CategoryHandlerTestSuite) SetupTest() {
+	logger.Initialize(config.ProductionEnv)
+
+	suite.mockService = srvMocks.NewICategoryService(suite.T())
+	suite.mockRedis = redisMocks.NewIRedis(suite.T())
+	suite.handler = NewCategoryHandler(suite.mockRedis, suite.mockService)
+}
+
+func TestCategoryHandlerTestSuite(t *testing.T) {
+	suite.Run(t, new(CategoryHandlerTestSuite))
+}
+
+func (suite *CategoryHandlerTestSuite) prepareContext(path string, body any) (*gin.Context, *httptest.ResponseRecorder) {
+	requestBody, _ := json.Marshal(body)
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest("GET", path, bytes.NewBuffer(requestBody))
+	c, _ := gin.CreateTestContext(w)
+	c.Request = r
+
+	return c, w
+}
+
+// ListCategories
+// =================================================================================================
+
+func (suite *CategoryHandlerTestSuite) TestListCategoriesSuccessfullyFromDatabase() {
+	ctx, writer := suite.prepareContext("/api/v1/categories", nil)
+
+	suite.mockRedis.On("Get", mock.Anything, &dto.ListCategoryRes{}).Return(errors.New("not found")).Times(1)
+	suite.mockService.On("ListCategories", mock.Anything).
+		Return([]*model.Category{
+			{
+				ID:       "shoes",
+				Name:     "Shoes",
+				Slug:     "shoes",
+				Children: []*model.Category{{ID: "boots", Name: "Boots", Slug: "boots"}},
+			},
+		}, nil).Times(1)
+	suite.mockRedis.On("SetWithTags", "/api/v1/categories", mock.Anything, config.ProductCachingTime, categoryTag).Return(nil).Times(1)
+
+	suite.handler.ListCategories(ctx)
+
+	var res response.Response
+	var resData dto.ListCategoryRes
+
+	_ = json.Unmarshal(writer.Body.Bytes(), &res)
+	utils.Copy(&resData, &res.Result)
+	suite.Equal(http.StatusOK, writer.Code)
+	suite.Equal(1, len(resData.Categories))
+	suite.Equal("boots", resData.Categories[0].Children[0].Slug)
+}
+
+func (suite *CategoryHandlerTestSuite) TestListCategoriesSuccessfullyFromCache() {
+	ctx, writer := suite.prepareContext("/api/v1/categories", nil)
+
+	suite.mockRedis.On("Get", mock.Anything, &dto.ListCategoryRes{}).Return(nil).Times(1)
+
+	suite.handler.ListCategories(ctx)
+	suite.Equal(http.StatusOK, writer.Code)
+}
+
+func (suite *CategoryHandlerTestSuite) TestListCategoriesFail() {
+	ctx, writer := suite.prepareContext("/api/v1/categories", nil)
+
+	suite.mockRedis.On("Get", mock.Anything, &dto.ListCategoryRes{}).Return(errors.New("not found")).Times(1)
+	suite.mockService.On("ListCategories", mock.Anything).Return(nil, errors.New("error")).Times(1)
+
+	suite.handler.ListCategories(ctx)
+	suite.Equal(http.StatusInternalServerError, writer.Code)
+}
+
+// GetCategoryBySlug
+// =================================================================================================
+
+func (suite *CategoryHandlerTestSuite) TestGetCategoryBySlugSuccess() {
+	ctx, writer := suite.prepareContext("/api/v1/categories/shoes", nil)
+	ctx.AddParam("slug", "shoes")
+
+	suite.mockService.On("GetCategoryBySlug", mock.Anything, "shoes").
+		Return(&model.Category{ID: "shoes", Name: "Shoes", Slug: "shoes"}, nil).Times(1)
+
+	suite.handler.GetCategoryBySlug(ctx)
+
+	var res response.Response
+	var resData dto.Category
+
+	_ = json.Unmarshal(writer.Body.Bytes(), &res)
+	utils.Copy(&resData, &res.Result)
+	suite.Equal(http.StatusOK, writer.Code)
+	suite.Equal("Shoes", resData.Name)
+}
+
+func (suite *CategoryHandlerTestSuite) TestGetCategoryBySlugNotFound() {
+	ctx, writer := suite.prepareContext("/api/v1/categories/shoes", nil)
+	ctx.AddParam("slug", "shoes")
+
+	suite.mockService.On("GetCategoryBySlug", mock.Anything, "shoes").
+		Return(nil, errors.New("error")).Times(1)
+
+	suite.handler.GetCategoryBySlug(ctx)
+	suite.Equal(http.StatusNotFound, writer.Code)
+}
+
+// ListCategoryProducts
+// =================================================================================================
+
+func (suite *CategoryHandlerTestSuite) TestListCategoryProductsSuccess() {
+	ctx, writer := suite.prepareContext("/api/v1/categories/shoes/products", nil)
+	ctx.AddParam("slug", "shoes")
+
+	suite.mockRedis.On("Get", mock.Anything, &dto.ListProductRes{}).Return(errors.New("not found")).Times(1)
+	suite.mockService.On("ListProducts", mock.Anything, "shoes", &dto.ListProductReq{}).
+		Return([]*model.Product{{ID: "productId", Name: "Boot"}}, &paging.Pagination{Total: 1}, nil).Times(1)
+	suite.mockRedis.On("SetWithTags", mock.Anything, mock.Anything, config.ProductCachingTime, categoryTag, productTag, productListTag).Return(nil).Times(1)
+
+	suite.handler.ListCategoryProducts(ctx)
+
+	var res response.Response
+	var resData dto.ListProductRes
+
+	_ = json.Unmarshal(writer.Body.Bytes(), &res)
+	utils.Copy(&resData, &res.Result)
+	suite.Equal(http.StatusOK, writer.Code)
+	suite.Equal(1, len(resData.Products))
+	suite.Equal("Boot", resData.Products[0].Name)
+}
+
+func (suite *CategoryHandlerTestSuite) TestListCategoryProductsCategoryNotFound() {
+	ctx, writer := suite.prepareContext("/api/v1/categories/shoes/products", nil)
+	ctx.AddParam("slug", "shoes")
+
+	suite.mockRedis.On("Get", mock.Anything, &dto.ListProductRes{}).Return(errors.New("not found")).Times(1)
+	suite.mockService.On("ListProducts", mock.Anything, "shoes", &dto.ListProductReq{}).
+		Return(nil, nil, fmt.Errorf("%w: shoes", repository.ErrCategoryNotFound)).Times(1)
+
+	suite.handler.ListCategoryProducts(ctx)
+	suite.Equal(http.StatusNotFound, writer.Code)
+}
+
+func (suite *CategoryHandlerTestSuite) TestListCategoryProductsFail() {
+	ctx, writer := suite.prepareContext("/api/v1/categories/shoes/products", nil)
+	ctx.AddParam("slug", "shoes")
+
+	suite.mockRedis.On("Get", mock.Anything, &dto.ListProductRes{}).Return(errors.New("not found")).Times(1)
+	suite.mockService.On("ListProducts", mock.Anything, "shoes", &dto.ListProductReq{}).
+		Return(nil, nil, errors.New("error")).Times(1)
+
+	suite.handler.ListCategoryProducts(ctx)
+	suite.Equal(http.StatusInternalServerError, writer.Code)
+}
+
+// CreateCategory
+// =================================================================================================
+
+func (suite *CategoryHandlerTestSuite) TestCreateCategorySuccess() {
+	req := &dto.CreateCategoryReq{Name: "Shoes"}
+
+	ctx, writer := suite.prepareContext("/api/v1/categories", req)
+
+	suite.mockService.On("Create", mock.Anything, req).
+		Return(&model.Category{ID: "shoes", Name: "Shoes", Slug: "shoes"}, nil).Times(1)
+	suite.mockRedis.On("InvalidateTags", categoryTag).Return(nil).Times(1)
+
+	suite.handler.CreateCategory(ctx)
+
+	var res response.Response
+	var resData dto.Category
+
+	_ = json.Unmarshal(writer.Body.Bytes(), &res)
+	utils.Copy(&resData, &res.Result)
+	suite.Equal(http.StatusOK, writer.Code)
+	suite.Equal("shoes", resData.Slug)
+}
+
+func (suite *CategoryHandlerTestSuite) TestCreateCategoryInvalidBody() {
+	ctx, writer := suite.prepareContext("/api/v1/categories", map[string]any{"name": 1})
+
+	suite.handler.CreateCategory(ctx)
+	suite.Equal(http.StatusBadRequest, writer.Code)
+}
+
+func (suite *CategoryHandlerTestSuite) TestCreateCategorySlugTaken() {
+	req := &dto.CreateCategoryReq{Name: "Shoes"}
+
+	ctx, writer := suite.prepareContext("/api/v1/categories", req)
+
+	suite.mockService.On("Create", mock.Anything, req).
+		Return(nil, fmt.Errorf("%w: shoes", service.ErrSlugTaken)).Times(1)
+
+	suite.handler.CreateCategory(ctx)
+	suite.Equal(http.StatusBadRequest, writer.Code)
+}
+
+func (suite *CategoryHandlerTestSuite) TestCreateCategoryFail() {
+	req := &dto.CreateCategoryReq{Name: "Shoes"}
+
+	ctx, writer := suite.prepareContext("/api/v1/categories", req)
+
+	suite.mockService.On("Create", mock.Anything, req).Return(nil, errors.New("error")).Times(1)
+
+	suite.handler.CreateCategory(ctx)
+	suite.Equal(http.StatusInternalServerError, writer.Code)
+}
+
+// UpdateCategory
+// =================================================================================================
+
+func (suite *CategoryHandlerTestSuite) TestUpdateCategorySuccess() {
+	req := &dto.UpdateCategoryReq{Name: "Boots"}
+
+	ctx, writer := suite.prepareContext("/api/v1/categories/boots", req)
+	ctx.AddParam("id", "boots")
+
+	suite.mockService.On("Update", mock.Anything, "boots", req).
+		Return(&model.Category{ID: "boots", Name: "Boots", Slug: "boots"}, nil).Times(1)
+	suite.mockRedis.On("InvalidateTags", categoryTag, productTag).Return(nil).Times(1)
+
+	suite.handler.UpdateCategory(ctx)
+	suite.Equal(http.StatusOK, writer.Code)
+}
+
+func (suite *CategoryHandlerTestSuite) TestUpdateCategoryCycle() {
+	req := &dto.UpdateCategoryReq{ParentID: strPtr("hiking")}
+
+	ctx, writer := suite.prepareContext("/api/v1/categories/shoes", req)
+	ctx.AddParam("id", "shoes")
+
+	suite.mockService.On("Update", mock.Anything, "shoes", req).
+		Return(nil, service.ErrCategoryCycle).Times(1)
+
+	suite.handler.UpdateCategory(ctx)
+	suite.Equal(http.StatusBadRequest, writer.Code)
+}
+
+func (suite *CategoryHandlerTestSuite) TestUpdateCategoryNotFound() {
+	req := &dto.UpdateCategoryReq{Name: "Boots"}
+
+	ctx, writer := suite.prepareContext("/api/v1/categories/boots", req)
+	ctx.AddParam("id", "boots")
+
+	suite.mockService.On("Update", mock.Anything, "boots", req).
+		Return(nil, fmt.Errorf("%w: boots", repository.ErrCategoryNotFound)).Times(1)
+
+	suite.handler.UpdateCategory(ctx)
+	suite.Equal(http.StatusNotFound, writer.Code)
+}
+
+// DeleteCategory
+// =================================================================================================
+
+func (suite *CategoryHandlerTestSuite) TestDeleteCategorySuccess() {
+	ctx, writer := suite.prepareContext("/api/v1/categories/boots", nil)
+	ctx.AddParam("id", "boots")
+
+	suite.mockService.On("Delete", mock.Anything, "boots").Return(nil).Times(1)
+	suite.mockRedis.On("InvalidateTags", categoryTag, productTag).Return(nil).Times(1)
+
+	suite.handler.DeleteCategory(ctx)
+	suite.Equal(http.StatusOK, writer.Code)
+}
+
+func (suite *CategoryHandlerTestSuite) TestDeleteCategoryHasChildren() {
+	ctx, writer := suite.prepareContext("/api/v1/categories/shoes", nil)
+	ctx.AddParam("id", "shoes")
+
+	suite.mockService.On("Delete", mock.Anything, "shoes").Return(service.ErrCategoryHasChildren).Times(1)
+
+	suite.handler.DeleteCategory(ctx)
+	suite.Equal(http.StatusBadRequest, writer.Code)
+}
+
+func (suite *CategoryHandlerTestSuite) TestDeleteCategoryFail() {
+	ctx, writer := suite.prepareContext("/api/v1/categories/shoes", nil)
+	ctx.AddParam("id", "shoes")
+
+	suite.mockService.On("Delete", mock.Anything, "shoes").Return(errors.New("error")).Times(1)
+
+	suite.handler.DeleteCategory(ctx)
+	suite.Equal(http.StatusInternalServerError, writer.Code)
+}
+
+func strPtr(s string) *string {
+	return &s
+}
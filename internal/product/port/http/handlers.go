@@ -1,14 +1,17 @@
 package http
 
 import (
+	"errors"
 	"net/http"
 
 	"github.com/gin-gonic/gin"
 	"github.com/quangdangfit/gocommon/logger"
 
 	"goshop/internal/product/dto"
+	"goshop/internal/product/repository"
 	"goshop/internal/product/service"
 	"goshop/pkg/config"
+	"goshop/pkg/paging"
 	"goshop/pkg/redis"
 	"goshop/pkg/response"
 	"goshop/pkg/utils"
@@ -55,7 +58,7 @@ func (p *ProductHandler) GetProductByID(c *gin.Context) {
 
 	utils.Copy(&res, &product)
 	response.JSON(c, http.StatusOK, res)
-	_ = p.cache.SetWithExpiration(cacheKey, res, config.ProductCachingTime)
+	_ = p.cache.SetWithTags(cacheKey, res, config.ProductCachingTime, productTag, productIDTag(productId))
 }
 
 // ListProducts godoc
@@ -63,6 +66,7 @@ func (p *ProductHandler) GetProductByID(c *gin.Context) {
 //	@Summary	Get list products
 //	@Tags		products
 //	@Produce	json
+//	@Param		_	query		dto.ListProductReq	true	"Query"
 //	@Success	200	{object}	dto.ListProductRes
 //	@Router		/api/v1/products [get]
 func (p *ProductHandler) ListProducts(c *gin.Context) {
@@ -81,17 +85,59 @@ func (p *ProductHandler) ListProducts(c *gin.Context) {
 		return
 	}
 
-	products, pagination, err := p.service.ListProducts(c, &req)
+	products, facets, pagination, err := p.service.ListProducts(c, &req)
 	if err != nil {
 		logger.Error("Failed to get list products: ", err)
+		if errors.Is(err, service.ErrInvalidFilter) || errors.Is(err, paging.ErrInvalidCursor) {
+			response.Error(c, http.StatusBadRequest, err, err.Error())
+			return
+		}
 		response.Error(c, http.StatusInternalServerError, err, "Something went wrong")
 		return
 	}
 
 	utils.Copy(&res.Products, &products)
 	res.Pagination = pagination
+	utils.Copy(&res.Facets, &facets)
 	response.JSON(c, http.StatusOK, res)
-	_ = p.cache.SetWithExpiration(cacheKey, res, config.ProductCachingTime)
+	_ = p.cache.SetWithTags(cacheKey, res, config.ProductCachingTime, productTag, productListTag)
+}
+
+// SearchProducts godoc
+//
+//	@Summary	Search products by name and description, most relevant first
+//	@Tags		products
+//	@Produce	json
+//	@Param		_	query		dto.SearchProductReq	true	"Query"
+//	@Success	200	{object}	dto.ListProductRes
+//	@Router		/api/v1/products/search [get]
+func (p *ProductHandler) SearchProducts(c *gin.Context) {
+	var req dto.SearchProductReq
+	if err := c.ShouldBindQuery(&req); err != nil {
+		logger.Error("Failed to parse request query: ", err)
+		response.Error(c, http.StatusBadRequest, err, "Invalid parameters")
+		return
+	}
+
+	var res dto.ListProductRes
+	cacheKey := c.Request.URL.RequestURI()
+	err := p.cache.Get(cacheKey, &res)
+	if err == nil {
+		response.JSON(c, http.StatusOK, res)
+		return
+	}
+
+	products, pagination, err := p.service.SearchProducts(c, &req)
+	if err != nil {
+		logger.Error("Failed to search products: ", err)
+		response.Error(c, http.StatusInternalServerError, err, "Something went wrong")
+		return
+	}
+
+	utils.Copy(&res.Products, &products)
+	res.Pagination = pagination
+	response.JSON(c, http.StatusOK, res)
+	_ = p.cache.SetWithTags(cacheKey, res, config.ProductCachingTime, productTag, productListTag)
 }
 
 // CreateProduct godoc
@@ -113,6 +159,10 @@ func (p *ProductHandler) CreateProduct(c *gin.Context) {
 	product, err := p.service.Create(c, &req)
 	if err != nil {
 		logger.Error("Failed to create product", err.Error())
+		if isVariantError(err) {
+			response.Error(c, http.StatusBadRequest, err, err.Error())
+			return
+		}
 		response.Error(c, http.StatusInternalServerError, err, "Something went wrong")
 		return
 	}
@@ -120,7 +170,7 @@ func (p *ProductHandler) CreateProduct(c *gin.Context) {
 	var res dto.Product
 	utils.Copy(&res, &product)
 	response.JSON(c, http.StatusOK, res)
-	_ = p.cache.RemovePattern("*product*")
+	_ = p.cache.InvalidateTags(productListTag)
 }
 
 // UpdateProduct godoc
@@ -151,5 +201,109 @@ func (p *ProductHandler) UpdateProduct(c *gin.Context) {
 	var res dto.Product
 	utils.Copy(&res, &product)
 	response.JSON(c, http.StatusOK, res)
-	_ = p.cache.RemovePattern("*product*")
+	_ = p.cache.InvalidateTags(productIDTag(productId), productListTag)
+}
+
+// AdjustStock godoc
+//
+//	@Summary	adjust product stock on hand
+//	@Tags		products
+//	@Produce	json
+//	@Security	ApiKeyAuth
+//	@Param		id	path		string				true	"Product ID"
+//	@Param		_	body		dto.AdjustStockReq	true	"Body"
+//	@Success	200	{object}	dto.ProductStock
+//	@Router		/api/v1/products/{id}/stock [put]
+func (p *ProductHandler) AdjustStock(c *gin.Context) {
+	productId := c.Param("id")
+	var req dto.AdjustStockReq
+	if err := c.ShouldBindJSON(&req); c.Request.Body == nil || err != nil {
+		logger.Error("Failed to get body", err)
+		response.Error(c, http.StatusBadRequest, err, "Invalid parameters")
+		return
+	}
+
+	stock, err := p.service.AdjustStock(c, productId, c.GetString("userId"), &req)
+	if err != nil {
+		logger.Error("Failed to adjust stock", err.Error())
+		if errors.Is(err, repository.ErrStockBelowReserved) || errors.Is(err, service.ErrHasVariants) {
+			response.Error(c, http.StatusBadRequest, err, err.Error())
+			return
+		}
+		response.Error(c, http.StatusInternalServerError, err, "Something went wrong")
+		return
+	}
+
+	var res dto.ProductStock
+	utils.Copy(&res, &stock)
+	response.JSON(c, http.StatusOK, res)
+	_ = p.cache.InvalidateTags(productIDTag(productId), productListTag)
+}
+
+// ListStockMovements godoc
+//
+//	@Summary	list stock movements of a product
+//	@Tags		products
+//	@Produce	json
+//	@Security	ApiKeyAuth
+//	@Param		id	path		string						true	"Product ID"
+//	@Param		_	query		dto.ListStockMovementReq	true	"Query"
+//	@Success	200	{object}	dto.ListStockMovementRes
+//	@Router		/api/v1/products/{id}/stock/movements [get]
+func (p *ProductHandler) ListStockMovements(c *gin.Context) {
+	productId := c.Param("id")
+	var req dto.ListStockMovementReq
+	if err := c.ShouldBindQuery(&req); err != nil {
+		logger.Error("Failed to parse request query: ", err)
+		response.Error(c, http.StatusBadRequest, err, "Invalid parameters")
+		return
+	}
+
+	movements, pagination, err := p.service.ListStockMovements(c, productId, &req)
+	if err != nil {
+		logger.Error("Failed to get list stock movements: ", err)
+		response.Error(c, http.StatusInternalServerError, err, "Something went wrong")
+		return
+	}
+
+	var res dto.ListStockMovementRes
+	utils.Copy(&res.Movements, &movements)
+	res.Pagination = pagination
+	response.JSON(c, http.StatusOK, res)
+}
+
+// SetProductCategories godoc
+//
+//	@Summary	replace the categories of a product
+//	@Tags		products
+//	@Produce	json
+//	@Security	ApiKeyAuth
+//	@Param		id	path		string						true	"Product ID"
+//	@Param		_	body		dto.SetProductCategoriesReq	true	"Body"
+//	@Success	200	{object}	dto.Product
+//	@Router		/api/v1/products/{id}/categories [put]
+func (p *ProductHandler) SetProductCategories(c *gin.Context) {
+	productId := c.Param("id")
+	var req dto.SetProductCategoriesReq
+	if err := c.ShouldBindJSON(&req); c.Request.Body == nil || err != nil {
+		logger.Error("Failed to get body", err)
+		response.Error(c, http.StatusBadRequest, err, "Invalid parameters")
+		return
+	}
+
+	product, err := p.service.SetCategories(c, productId, &req)
+	if err != nil {
+		logger.Error("Failed to set product categories", err.Error())
+		if errors.Is(err, repository.ErrCategoryNotFound) {
+			response.Error(c, http.StatusBadRequest, err, err.Error())
+			return
+		}
+		response.Error(c, http.StatusInternalServerError, err, "Something went wrong")
+		return
+	}
+
+	var res dto.Product
+	utils.Copy(&res, &product)
+	response.JSON(c, http.StatusOK, res)
+	_ = p.cache.InvalidateTags(productIDTag(productId), productListTag)
 }
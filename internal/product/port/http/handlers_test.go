@@ -4,9 +4,11 @@ import (
 	"bytes"
 	"encoding/json"
 	"errors"
+	"fmt"
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/quangdangfit/gocommon/logger"
@@ -15,8 +17,11 @@ import (
 
 	"goshop/internal/product/dto"
 	"goshop/internal/product/model"
+	"goshop/internal/product/repository"
+	"goshop/internal/product/service"
 	srvMocks "goshop/internal/product/service/mocks"
 	"goshop/pkg/config"
+	"goshop/pkg/money"
 	"goshop/pkg/paging"
 	redisMocks "goshop/pkg/redis/mocks"
 	"goshop/pkg/response"
@@ -57,6 +62,7 @@ func (suite *ProductHandlerTestSuite) prepareContext(path string, body any) (*gi
 
 func (suite *ProductHandlerTestSuite) TestGetProductByIDSuccessfullyFromDatabase() {
 	ctx, writer := suite.prepareContext("/api/v1/products/123456", nil)
+	ctx.AddParam("id", "123456")
 
 	suite.mockRedis.On("Get", mock.Anything, &dto.Product{}).Return(errors.New("not found")).Times(1)
 	suite.mockService.On("GetProductByID", mock.Anything, mock.Anything).
@@ -68,7 +74,7 @@ func (suite *ProductHandlerTestSuite) TestGetProductByIDSuccessfullyFromDatabase
 			},
 			nil,
 		).Times(1)
-	suite.mockRedis.On("SetWithExpiration", mock.Anything, mock.Anything, mock.Anything).Return(nil).Times(1)
+	suite.mockRedis.On("SetWithTags", "/api/v1/products/123456", mock.Anything, config.ProductCachingTime, productTag, productIDTag("123456")).Return(nil).Times(1)
 
 	suite.handler.GetProductByID(ctx)
 
@@ -128,10 +134,13 @@ func (suite *ProductHandlerTestSuite) TestListProductsSuccessfullyFromDatabase()
 					Description: "description",
 				},
 			},
+			&model.ProductFacets{
+				Options: []*model.OptionFacet{{Name: "Size", Values: []*model.OptionValueFacet{{Value: "M", Count: 1}}}},
+			},
 			&paging.Pagination{},
 			nil,
 		).Times(1)
-	suite.mockRedis.On("SetWithExpiration", mock.Anything, mock.Anything, mock.Anything).Return(nil).Times(1)
+	suite.mockRedis.On("SetWithTags", mock.Anything, mock.Anything, config.ProductCachingTime, productTag, productListTag).Return(nil).Times(1)
 
 	suite.handler.ListProducts(ctx)
 
@@ -145,6 +154,8 @@ func (suite *ProductHandlerTestSuite) TestListProductsSuccessfullyFromDatabase()
 	suite.Equal("123456", products.Products[0].ID)
 	suite.Equal("product", products.Products[0].Name)
 	suite.Equal("description", products.Products[0].Description)
+	suite.Equal("M", products.Facets.Options[0].Values[0].Value)
+	suite.Equal(int64(1), products.Facets.Options[0].Values[0].Count)
 }
 
 func (suite *ProductHandlerTestSuite) TestListProductsSuccessfullyFromCache() {
@@ -174,19 +185,108 @@ func (suite *ProductHandlerTestSuite) TestListProductsFail() {
 
 	suite.mockRedis.On("Get", mock.Anything, &dto.ListProductRes{}).Return(errors.New("not found")).Times(1)
 	suite.mockService.On("ListProducts", mock.Anything, mock.Anything).
-		Return(nil, nil, errors.New("error")).Times(1)
+		Return(nil, nil, nil, errors.New("error")).Times(1)
 
 	suite.handler.ListProducts(ctx)
 	suite.Equal(http.StatusInternalServerError, writer.Code)
 }
 
+func (suite *ProductHandlerTestSuite) TestListProductsWithFilters() {
+	ctx, writer := suite.prepareContext("/api/v1/products?min_price=10&currency=USD&active=true"+
+		"&created_after=2024-01-02T00:00:00Z&option=Size:M&option=Color:Red&order_by=price", nil)
+
+	createdAfter := time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC)
+	suite.mockRedis.On("Get", mock.Anything, &dto.ListProductRes{}).Return(errors.New("not found")).Times(1)
+	suite.mockService.On("ListProducts", mock.Anything, mock.MatchedBy(func(req *dto.ListProductReq) bool {
+		return req.MinPrice == "10" && req.Currency == "USD" && *req.Active &&
+			req.CreatedAfter.Equal(createdAfter) && len(req.Options) == 2 && req.OrderBy == "price"
+	})).
+		Return([]*model.Product{}, &model.ProductFacets{}, &paging.Pagination{}, nil).Times(1)
+	suite.mockRedis.On("SetWithTags", mock.Anything, mock.Anything, config.ProductCachingTime, productTag, productListTag).Return(nil).Times(1)
+
+	suite.handler.ListProducts(ctx)
+	suite.Equal(http.StatusOK, writer.Code)
+}
+
+func (suite *ProductHandlerTestSuite) TestListProductsInvalidCursor() {
+	ctx, writer := suite.prepareContext("/api/v1/products?cursor=abc", nil)
+
+	suite.mockRedis.On("Get", mock.Anything, &dto.ListProductRes{}).Return(errors.New("not found")).Times(1)
+	suite.mockService.On("ListProducts", mock.Anything, &dto.ListProductReq{Cursor: "abc"}).
+		Return(nil, nil, nil, paging.ErrInvalidCursor).Times(1)
+
+	suite.handler.ListProducts(ctx)
+	suite.Equal(http.StatusBadRequest, writer.Code)
+}
+
+func (suite *ProductHandlerTestSuite) TestListProductsInvalidFilter() {
+	ctx, writer := suite.prepareContext("/api/v1/products?order_by=code", nil)
+
+	suite.mockRedis.On("Get", mock.Anything, &dto.ListProductRes{}).Return(errors.New("not found")).Times(1)
+	suite.mockService.On("ListProducts", mock.Anything, &dto.ListProductReq{OrderBy: "code"}).
+		Return(nil, nil, nil, fmt.Errorf("%w: order_by", service.ErrInvalidFilter)).Times(1)
+
+	suite.handler.ListProducts(ctx)
+	suite.Equal(http.StatusBadRequest, writer.Code)
+}
+
+// SearchProducts
+// =================================================================================================
+
+func (suite *ProductHandlerTestSuite) TestSearchProductsSuccessfullyFromDatabase() {
+	ctx, writer := suite.prepareContext("/api/v1/products/search?q=prod", nil)
+
+	suite.mockRedis.On("Get", mock.Anything, &dto.ListProductRes{}).Return(errors.New("not found")).Times(1)
+	suite.mockService.On("SearchProducts", mock.Anything, &dto.SearchProductReq{Query: "prod"}).
+		Return([]*model.Product{{ID: "123456", Name: "product"}}, &paging.Pagination{Total: 1}, nil).Times(1)
+	suite.mockRedis.On("SetWithTags", mock.Anything, mock.Anything, config.ProductCachingTime, productTag, productListTag).Return(nil).Times(1)
+
+	suite.handler.SearchProducts(ctx)
+
+	var res response.Response
+	var products dto.ListProductRes
+
+	_ = json.Unmarshal(writer.Body.Bytes(), &res)
+	utils.Copy(&products, &res.Result)
+	suite.Equal(http.StatusOK, writer.Code)
+	suite.Equal(1, len(products.Products))
+	suite.Equal("123456", products.Products[0].ID)
+	suite.Equal(int64(1), products.Pagination.Total)
+}
+
+func (suite *ProductHandlerTestSuite) TestSearchProductsSuccessfullyFromCache() {
+	ctx, writer := suite.prepareContext("/api/v1/products/search?q=prod", nil)
+
+	suite.mockRedis.On("Get", mock.Anything, &dto.ListProductRes{}).Return(nil).Times(1)
+
+	suite.handler.SearchProducts(ctx)
+	suite.Equal(http.StatusOK, writer.Code)
+}
+
+func (suite *ProductHandlerTestSuite) TestSearchProductsInvalidQuery() {
+	ctx, writer := suite.prepareContext("/api/v1/products/search?q=prod&page=a", nil)
+	suite.handler.SearchProducts(ctx)
+	suite.Equal(http.StatusBadRequest, writer.Code)
+}
+
+func (suite *ProductHandlerTestSuite) TestSearchProductsFail() {
+	ctx, writer := suite.prepareContext("/api/v1/products/search", nil)
+
+	suite.mockRedis.On("Get", mock.Anything, &dto.ListProductRes{}).Return(errors.New("not found")).Times(1)
+	suite.mockService.On("SearchProducts", mock.Anything, mock.Anything).
+		Return(nil, nil, errors.New("error")).Times(1)
+
+	suite.handler.SearchProducts(ctx)
+	suite.Equal(http.StatusInternalServerError, writer.Code)
+}
+
 // CreateProduct
 // =================================================================================================
 func (suite *ProductHandlerTestSuite) TestCreateProductSuccess() {
 	req := &dto.CreateProductReq{
 		Name:        "product",
 		Description: "description",
-		Price:       10.5,
+		Price:       money.New(1050, "USD"),
 	}
 
 	ctx, writer := suite.prepareContext("/api/v1/products", req)
@@ -196,11 +296,11 @@ func (suite *ProductHandlerTestSuite) TestCreateProductSuccess() {
 			&model.Product{
 				Name:        "product",
 				Description: "description",
-				Price:       10.5,
+				Price:       money.New(1050, "USD"),
 			},
 			nil,
 		).Times(1)
-	suite.mockRedis.On("RemovePattern", "*product*").Return(nil).Times(1)
+	suite.mockRedis.On("InvalidateTags", productListTag).Return(nil).Times(1)
 
 	suite.handler.CreateProduct(ctx)
 
@@ -235,7 +335,7 @@ func (suite *ProductHandlerTestSuite) TestCreateProductFail() {
 	req := &dto.CreateProductReq{
 		Name:        "product",
 		Description: "description",
-		Price:       10.5,
+		Price:       money.New(1050, "USD"),
 	}
 
 	ctx, writer := suite.prepareContext("/api/v1/products", req)
@@ -251,29 +351,48 @@ func (suite *ProductHandlerTestSuite) TestCreateProductFail() {
 	suite.Equal("Something went wrong", res["error"]["message"])
 }
 
+func (suite *ProductHandlerTestSuite) TestCreateProductInvalidVariant() {
+	req := &dto.CreateProductReq{
+		Name:        "product",
+		Description: "description",
+		Price:       money.New(1050, "USD"),
+		Variants:    []*dto.CreateVariantReq{{SKU: "SKU-1"}},
+	}
+
+	ctx, writer := suite.prepareContext("/api/v1/products", req)
+
+	suite.mockService.On("Create", mock.Anything, req).
+		Return(nil, fmt.Errorf("%w: SKU-1", service.ErrInvalidVariant)).Times(1)
+
+	suite.handler.CreateProduct(ctx)
+	suite.Equal(http.StatusBadRequest, writer.Code)
+}
+
 // UpdateProduct
 // =================================================================================================
 
 func (suite *ProductHandlerTestSuite) TestUpdateProductSuccess() {
+	price := money.New(1050, "USD")
 	req := &dto.UpdateProductReq{
 		Name:        "product",
 		Description: "description",
-		Price:       10.5,
+		Price:       &price,
 	}
 
 	ctx, writer := suite.prepareContext("/api/v1/products/123456", req)
+	ctx.AddParam("id", "123456")
 
-	suite.mockService.On("Update", mock.Anything, mock.Anything, req).
+	suite.mockService.On("Update", mock.Anything, "123456", req).
 		Return(
 			&model.Product{
 				ID:          "123456",
 				Name:        "product",
 				Description: "description",
-				Price:       10.5,
+				Price:       money.New(1050, "USD"),
 			},
 			nil,
 		).Times(1)
-	suite.mockRedis.On("RemovePattern", "*product*").Return(nil).Times(1)
+	suite.mockRedis.On("InvalidateTags", productIDTag("123456"), productListTag).Return(nil).Times(1)
 
 	suite.handler.UpdateProduct(ctx)
 
@@ -286,7 +405,7 @@ func (suite *ProductHandlerTestSuite) TestUpdateProductSuccess() {
 	suite.Equal("123456", resData.ID)
 	suite.Equal(req.Name, resData.Name)
 	suite.Equal(req.Description, resData.Description)
-	suite.Equal(req.Price, resData.Price)
+	suite.Equal(*req.Price, resData.Price)
 }
 
 func (suite *ProductHandlerTestSuite) TestUpdateProductInvalidPriceType() {
@@ -306,10 +425,11 @@ func (suite *ProductHandlerTestSuite) TestUpdateProductInvalidPriceType() {
 }
 
 func (suite *ProductHandlerTestSuite) TestUpdateProductFail() {
+	price := money.New(1050, "USD")
 	req := &dto.UpdateProductReq{
 		Name:        "product",
 		Description: "description",
-		Price:       10.5,
+		Price:       &price,
 	}
 
 	ctx, writer := suite.prepareContext("/api/v1/products/123456", req)
@@ -324,3 +444,179 @@ func (suite *ProductHandlerTestSuite) TestUpdateProductFail() {
 	suite.Equal(http.StatusInternalServerError, writer.Code)
 	suite.Equal("Something went wrong", res["error"]["message"])
 }
+
+// AdjustStock
+// =================================================================================================
+
+func (suite *ProductHandlerTestSuite) TestAdjustStockSuccess() {
+	req := &dto.AdjustStockReq{
+		Quantity: 5,
+		Reason:   "restock",
+	}
+
+	ctx, writer := suite.prepareContext("/api/v1/products/123456/stock", req)
+	ctx.AddParam("id", "123456")
+	ctx.Set("userId", "admin1")
+
+	suite.mockService.On("AdjustStock", mock.Anything, "123456", "admin1", req).
+		Return(&model.ProductStock{ProductID: "123456", OnHand: 7, Reserved: 2, Available: 5}, nil).Times(1)
+	suite.mockRedis.On("InvalidateTags", productIDTag("123456"), productListTag).Return(nil).Times(1)
+
+	suite.handler.AdjustStock(ctx)
+
+	var res response.Response
+	var resData dto.ProductStock
+
+	_ = json.Unmarshal(writer.Body.Bytes(), &res)
+	utils.Copy(&resData, &res.Result)
+	suite.Equal(http.StatusOK, writer.Code)
+	suite.Equal(int64(7), resData.OnHand)
+	suite.Equal(int64(2), resData.Reserved)
+	suite.Equal(int64(5), resData.Available)
+}
+
+func (suite *ProductHandlerTestSuite) TestAdjustStockInvalidQuantityType() {
+	req := map[string]any{
+		"quantity": "5",
+		"reason":   "restock",
+	}
+
+	ctx, writer := suite.prepareContext("/api/v1/products/123456/stock", req)
+
+	suite.handler.AdjustStock(ctx)
+
+	var res map[string]map[string]string
+	_ = json.Unmarshal(writer.Body.Bytes(), &res)
+	suite.Equal(http.StatusBadRequest, writer.Code)
+	suite.Equal("Invalid parameters", res["error"]["message"])
+}
+
+func (suite *ProductHandlerTestSuite) TestAdjustStockBelowReserved() {
+	req := &dto.AdjustStockReq{
+		Quantity: -5,
+		Reason:   "damaged",
+	}
+
+	ctx, writer := suite.prepareContext("/api/v1/products/123456/stock", req)
+
+	suite.mockService.On("AdjustStock", mock.Anything, mock.Anything, mock.Anything, req).
+		Return(nil, repository.ErrStockBelowReserved).Times(1)
+
+	suite.handler.AdjustStock(ctx)
+	suite.Equal(http.StatusBadRequest, writer.Code)
+}
+
+func (suite *ProductHandlerTestSuite) TestAdjustStockFail() {
+	req := &dto.AdjustStockReq{
+		Quantity: 5,
+		Reason:   "restock",
+	}
+
+	ctx, writer := suite.prepareContext("/api/v1/products/123456/stock", req)
+
+	suite.mockService.On("AdjustStock", mock.Anything, mock.Anything, mock.Anything, req).
+		Return(nil, errors.New("error")).Times(1)
+
+	suite.handler.AdjustStock(ctx)
+
+	var res map[string]map[string]string
+	_ = json.Unmarshal(writer.Body.Bytes(), &res)
+	suite.Equal(http.StatusInternalServerError, writer.Code)
+	suite.Equal("Something went wrong", res["error"]["message"])
+}
+
+// ListStockMovements
+// =================================================================================================
+
+func (suite *ProductHandlerTestSuite) TestListStockMovementsSuccess() {
+	ctx, writer := suite.prepareContext("/api/v1/products/123456/stock/movements", nil)
+	ctx.AddParam("id", "123456")
+
+	suite.mockService.On("ListStockMovements", mock.Anything, "123456", mock.Anything).
+		Return(
+			[]*model.StockMovement{{ID: "movement1", ProductID: "123456", Type: model.StockMovementAdjustment, Quantity: 5}},
+			&paging.Pagination{Total: 1, CurrentPage: 1, Limit: 10},
+			nil,
+		).Times(1)
+
+	suite.handler.ListStockMovements(ctx)
+
+	var res response.Response
+	var resData dto.ListStockMovementRes
+
+	_ = json.Unmarshal(writer.Body.Bytes(), &res)
+	utils.Copy(&resData, &res.Result)
+	suite.Equal(http.StatusOK, writer.Code)
+	suite.Equal(1, len(resData.Movements))
+	suite.Equal("adjustment", resData.Movements[0].Type)
+	suite.Equal(int64(1), resData.Pagination.Total)
+}
+
+func (suite *ProductHandlerTestSuite) TestListStockMovementsFail() {
+	ctx, writer := suite.prepareContext("/api/v1/products/123456/stock/movements", nil)
+
+	suite.mockService.On("ListStockMovements", mock.Anything, mock.Anything, mock.Anything).
+		Return(nil, nil, errors.New("error")).Times(1)
+
+	suite.handler.ListStockMovements(ctx)
+	suite.Equal(http.StatusInternalServerError, writer.Code)
+}
+
+// SetProductCategories
+// =================================================================================================
+
+func (suite *ProductHandlerTestSuite) TestSetProductCategoriesSuccess() {
+	req := &dto.SetProductCategoriesReq{CategoryIDs: []string{"categoryId1"}}
+
+	ctx, writer := suite.prepareContext("/api/v1/products/123456/categories", req)
+	ctx.AddParam("id", "123456")
+
+	suite.mockService.On("SetCategories", mock.Anything, "123456", req).
+		Return(&model.Product{
+			ID:         "123456",
+			Categories: []*model.Category{{ID: "categoryId1", Name: "Shoes", Slug: "shoes"}},
+		}, nil).Times(1)
+	suite.mockRedis.On("InvalidateTags", productIDTag("123456"), productListTag).Return(nil).Times(1)
+
+	suite.handler.SetProductCategories(ctx)
+
+	var res response.Response
+	var resData dto.Product
+
+	_ = json.Unmarshal(writer.Body.Bytes(), &res)
+	utils.Copy(&resData, &res.Result)
+	suite.Equal(http.StatusOK, writer.Code)
+	suite.Equal(1, len(resData.Categories))
+	suite.Equal("shoes", resData.Categories[0].Slug)
+}
+
+func (suite *ProductHandlerTestSuite) TestSetProductCategoriesInvalidBody() {
+	ctx, writer := suite.prepareContext("/api/v1/products/123456/categories", map[string]any{"category_ids": "categoryId1"})
+
+	suite.handler.SetProductCategories(ctx)
+	suite.Equal(http.StatusBadRequest, writer.Code)
+}
+
+func (suite *ProductHandlerTestSuite) TestSetProductCategoriesCategoryNotFound() {
+	req := &dto.SetProductCategoriesReq{CategoryIDs: []string{"categoryId1"}}
+
+	ctx, writer := suite.prepareContext("/api/v1/products/123456/categories", req)
+
+	suite.mockService.On("SetCategories", mock.Anything, mock.Anything, req).
+		Return(nil, repository.ErrCategoryNotFound).Times(1)
+
+	suite.handler.SetProductCategories(ctx)
+	suite.Equal(http.StatusBadRequest, writer.Code)
+}
+
+func (suite *ProductHandlerTestSuite) TestSetProductCategoriesFail() {
+	req := &dto.SetProductCategoriesReq{CategoryIDs: []string{"categoryId1"}}
+
+	ctx, writer := suite.prepareContext("/api/v1/products/123456/categories", req)
+
+	suite.mockService.On("SetCategories", mock.Anything, mock.Anything, req).
+		Return(nil, errors.New("error")).Times(1)
+
+	suite.handler.SetProductCategories(ctx)
+	suite.Equal(http.StatusInternalServerError, writer.Code)
+}
@@ -7,22 +7,54 @@ import (
 	"goshop/internal/product/repository"
 	"goshop/internal/product/service"
 	"goshop/pkg/dbs"
+	"goshop/pkg/idempotency"
 	"goshop/pkg/middleware"
+	"goshop/pkg/rbac"
 	"goshop/pkg/redis"
 )
 
-func Routes(r *gin.RouterGroup, db dbs.IDatabase, validator validation.Validation, cache redis.IRedis) {
+func Routes(
+	r *gin.RouterGroup,
+	db dbs.IDatabase,
+	validator validation.Validation,
+	cache redis.IRedis,
+	idempotencyStore idempotency.Store,
+) {
 	productRepo := repository.NewProductRepository(db)
-	productSvc := service.NewProductService(validator, productRepo)
+	variantRepo := repository.NewVariantRepository(db)
+	productSvc := service.NewProductService(validator, productRepo, variantRepo)
 	productHandler := NewProductHandler(cache, productSvc)
+	variantSvc := service.NewVariantService(validator, variantRepo, productRepo)
+	variantHandler := NewVariantHandler(cache, variantSvc)
+	categorySvc := service.NewCategoryService(validator, db, repository.NewCategoryRepository(db), productRepo)
+	categoryHandler := NewCategoryHandler(cache, categorySvc)
 
 	authMiddleware := middleware.JWTAuth()
+	idempotencyMiddleware := middleware.Idempotency(idempotencyStore)
 
 	productRoute := r.Group("/products")
 	{
 		productRoute.GET("", productHandler.ListProducts)
-		productRoute.POST("", authMiddleware, productHandler.CreateProduct)
-		productRoute.PUT("/:id", authMiddleware, productHandler.UpdateProduct)
+		productRoute.GET("/search", productHandler.SearchProducts)
+		productRoute.POST("", authMiddleware, idempotencyMiddleware, middleware.RequirePermission(rbac.PermissionProductWrite), productHandler.CreateProduct)
+		productRoute.PUT("/:id", authMiddleware, idempotencyMiddleware, middleware.RequirePermission(rbac.PermissionProductWrite), productHandler.UpdateProduct)
 		productRoute.GET("/:id", productHandler.GetProductByID)
+		productRoute.PUT("/:id/stock", authMiddleware, idempotencyMiddleware, middleware.RequirePermission(rbac.PermissionStockWrite), productHandler.AdjustStock)
+		productRoute.GET("/:id/stock/movements", authMiddleware, middleware.RequirePermission(rbac.PermissionStockRead), productHandler.ListStockMovements)
+		productRoute.PUT("/:id/categories", authMiddleware, idempotencyMiddleware, middleware.RequirePermission(rbac.PermissionProductWrite), productHandler.SetProductCategories)
+		productRoute.POST("/:id/options", authMiddleware, idempotencyMiddleware, middleware.RequirePermission(rbac.PermissionProductWrite), variantHandler.AddOption)
+		productRoute.POST("/:id/variants", authMiddleware, idempotencyMiddleware, middleware.RequirePermission(rbac.PermissionProductWrite), variantHandler.CreateVariant)
+		productRoute.PUT("/:id/variants/:variant_id", authMiddleware, idempotencyMiddleware, middleware.RequirePermission(rbac.PermissionProductWrite), variantHandler.UpdateVariant)
+		productRoute.PUT("/:id/variants/:variant_id/stock", authMiddleware, idempotencyMiddleware, middleware.RequirePermission(rbac.PermissionStockWrite), variantHandler.AdjustStock)
+	}
+
+	categoryRoute := r.Group("/categories")
+	{
+		categoryRoute.GET("", categoryHandler.ListCategories)
+		categoryRoute.GET("/:slug", categoryHandler.GetCategoryBySlug)
+		categoryRoute.GET("/:slug/products", categoryHandler.ListCategoryProducts)
+		categoryRoute.POST("", authMiddleware, idempotencyMiddleware, middleware.RequirePermission(rbac.PermissionCategoryWrite), categoryHandler.CreateCategory)
+		categoryRoute.PUT("/:id", authMiddleware, idempotencyMiddleware, middleware.RequirePermission(rbac.PermissionCategoryWrite), categoryHandler.UpdateCategory)
+		categoryRoute.DELETE("/:id", authMiddleware, idempotencyMiddleware, middleware.RequirePermission(rbac.PermissionCategoryWrite), categoryHandler.DeleteCategory)
 	}
 }
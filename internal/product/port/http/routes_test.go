@@ -7,11 +7,12 @@ import (
 	"github.com/quangdangfit/gocommon/validation"
 
 	dbMocks "goshop/pkg/dbs/mocks"
+	idempotencyMocks "goshop/pkg/idempotency/mocks"
 	redisMocks "goshop/pkg/redis/mocks"
 )
 
 func TestRoutes(t *testing.T) {
 	mockDB := dbMocks.NewIDatabase(t)
 	mockRedis := redisMocks.NewIRedis(t)
-	Routes(gin.New().Group("/"), mockDB, validation.New(), mockRedis)
+	Routes(gin.New().Group("/"), mockDB, validation.New(), mockRedis, idempotencyMocks.NewStore(t))
 }
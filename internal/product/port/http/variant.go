@@ -0,0 +1,189 @@
+package http
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/quangdangfit/gocommon/logger"
+
+	"goshop/internal/product/dto"
+	"goshop/internal/product/repository"
+	"goshop/internal/product/service"
+	"goshop/pkg/redis"
+	"goshop/pkg/response"
+	"goshop/pkg/utils"
+)
+
+type VariantHandler struct {
+	cache   redis.IRedis
+	service service.IVariantService
+}
+
+func NewVariantHandler(
+	cache redis.IRedis,
+	service service.IVariantService,
+) *VariantHandler {
+	return &VariantHandler{
+		cache:   cache,
+		service: service,
+	}
+}
+
+// AddOption godoc
+//
+//	@Summary	add an option type to a product, or values to one of its option types
+//	@Tags		products
+//	@Produce	json
+//	@Security	ApiKeyAuth
+//	@Param		id	path		string				true	"Product ID"
+//	@Param		_	body		dto.CreateOptionReq	true	"Body"
+//	@Success	200	{object}	dto.Product
+//	@Router		/api/v1/products/{id}/options [post]
+func (h *VariantHandler) AddOption(c *gin.Context) {
+	var req dto.CreateOptionReq
+	if err := c.ShouldBindJSON(&req); c.Request.Body == nil || err != nil {
+		logger.Error("Failed to get body", err)
+		response.Error(c, http.StatusBadRequest, err, "Invalid parameters")
+		return
+	}
+
+	product, err := h.service.AddOption(c, c.Param("id"), &req)
+	if err != nil {
+		logger.Error("Failed to add product option", err.Error())
+		if isVariantError(err) {
+			response.Error(c, http.StatusBadRequest, err, err.Error())
+			return
+		}
+		response.Error(c, http.StatusInternalServerError, err, "Something went wrong")
+		return
+	}
+
+	var res dto.Product
+	utils.Copy(&res, &product)
+	response.JSON(c, http.StatusOK, res)
+	_ = h.cache.InvalidateTags(productIDTag(c.Param("id")), productListTag)
+}
+
+// CreateVariant godoc
+//
+//	@Summary	create a variant of a product
+//	@Tags		products
+//	@Produce	json
+//	@Security	ApiKeyAuth
+//	@Param		id	path		string					true	"Product ID"
+//	@Param		_	body		dto.CreateVariantReq	true	"Body"
+//	@Success	200	{object}	dto.Variant
+//	@Router		/api/v1/products/{id}/variants [post]
+func (h *VariantHandler) CreateVariant(c *gin.Context) {
+	var req dto.CreateVariantReq
+	if err := c.ShouldBindJSON(&req); c.Request.Body == nil || err != nil {
+		logger.Error("Failed to get body", err)
+		response.Error(c, http.StatusBadRequest, err, "Invalid parameters")
+		return
+	}
+
+	variant, err := h.service.CreateVariant(c, c.Param("id"), &req)
+	if err != nil {
+		logger.Error("Failed to create variant", err.Error())
+		if isVariantError(err) {
+			response.Error(c, http.StatusBadRequest, err, err.Error())
+			return
+		}
+		response.Error(c, http.StatusInternalServerError, err, "Something went wrong")
+		return
+	}
+
+	var res dto.Variant
+	utils.Copy(&res, &variant)
+	response.JSON(c, http.StatusOK, res)
+	_ = h.cache.InvalidateTags(productIDTag(c.Param("id")), productListTag)
+}
+
+// UpdateVariant godoc
+//
+//	@Summary	update a variant of a product
+//	@Tags		products
+//	@Produce	json
+//	@Security	ApiKeyAuth
+//	@Param		id			path		string					true	"Product ID"
+//	@Param		variant_id	path		string					true	"Variant ID"
+//	@Param		_			body		dto.UpdateVariantReq	true	"Body"
+//	@Success	200			{object}	dto.Variant
+//	@Router		/api/v1/products/{id}/variants/{variant_id} [put]
+func (h *VariantHandler) UpdateVariant(c *gin.Context) {
+	var req dto.UpdateVariantReq
+	if err := c.ShouldBindJSON(&req); c.Request.Body == nil || err != nil {
+		logger.Error("Failed to get body", err)
+		response.Error(c, http.StatusBadRequest, err, "Invalid parameters")
+		return
+	}
+
+	variant, err := h.service.UpdateVariant(c, c.Param("id"), c.Param("variant_id"), &req)
+	if err != nil {
+		logger.Error("Failed to update variant", err.Error())
+		if errors.Is(err, service.ErrVariantNotFound) {
+			response.Error(c, http.StatusNotFound, err, "Not found")
+			return
+		}
+		if isVariantError(err) {
+			response.Error(c, http.StatusBadRequest, err, err.Error())
+			return
+		}
+		response.Error(c, http.StatusInternalServerError, err, "Something went wrong")
+		return
+	}
+
+	var res dto.Variant
+	utils.Copy(&res, &variant)
+	response.JSON(c, http.StatusOK, res)
+	_ = h.cache.InvalidateTags(productIDTag(c.Param("id")), productListTag)
+}
+
+// AdjustStock godoc
+//
+//	@Summary	adjust variant stock on hand
+//	@Tags		products
+//	@Produce	json
+//	@Security	ApiKeyAuth
+//	@Param		id			path		string				true	"Product ID"
+//	@Param		variant_id	path		string				true	"Variant ID"
+//	@Param		_			body		dto.AdjustStockReq	true	"Body"
+//	@Success	200			{object}	dto.ProductStock
+//	@Router		/api/v1/products/{id}/variants/{variant_id}/stock [put]
+func (h *VariantHandler) AdjustStock(c *gin.Context) {
+	var req dto.AdjustStockReq
+	if err := c.ShouldBindJSON(&req); c.Request.Body == nil || err != nil {
+		logger.Error("Failed to get body", err)
+		response.Error(c, http.StatusBadRequest, err, "Invalid parameters")
+		return
+	}
+
+	stock, err := h.service.AdjustStock(c, c.Param("id"), c.Param("variant_id"), c.GetString("userId"), &req)
+	if err != nil {
+		logger.Error("Failed to adjust variant stock", err.Error())
+		if errors.Is(err, service.ErrVariantNotFound) {
+			response.Error(c, http.StatusNotFound, err, "Not found")
+			return
+		}
+		if errors.Is(err, repository.ErrStockBelowReserved) {
+			response.Error(c, http.StatusBadRequest, err, err.Error())
+			return
+		}
+		response.Error(c, http.StatusInternalServerError, err, "Something went wrong")
+		return
+	}
+
+	var res dto.ProductStock
+	utils.Copy(&res, &stock)
+	response.JSON(c, http.StatusOK, res)
+	_ = h.cache.InvalidateTags(productIDTag(c.Param("id")), productListTag)
+}
+
+func isVariantError(err error) bool {
+	return errors.Is(err, service.ErrInvalidOption) ||
+		errors.Is(err, service.ErrInvalidVariant) ||
+		errors.Is(err, service.ErrDuplicateVariant) ||
+		errors.Is(err, service.ErrSKUTaken) ||
+		errors.Is(err, service.ErrInvalidPrice)
+}
@@ -0,0 +1,238 @@
+package http
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/quangdangfit/gocommon/logger"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+
+	"goshop/internal/product/dto"
+	"goshop/internal/product/model"
+	"goshop/internal/product/repository"
+	"goshop/internal/product/service"
+	srvMocks "goshop/internal/product/service/mocks"
+	"goshop/pkg/config"
+	"goshop/pkg/money"
+	redisMocks "goshop/pkg/redis/mocks"
+	"goshop/pkg/response"
+	"goshop/pkg/utils"
+)
+
+type VariantHandlerTestSuite struct {
+	suite.Suite
+	mockService *srvMocks.IVariantService
+	mockRedis   *redisMocks.IRedis
+	handler     *VariantHandler
+}
+
+func (suite *VariantHandlerTestSuite) SetupTest() {
+	logger.Initialize(config.ProductionEnv)
+
+	suite.mockService = srvMocks.NewIVariantService(suite.T())
+	suite.mockRedis = redisMocks.NewIRedis(suite.T())
+	suite.handler = NewVariantHandler(suite.mockRedis, suite.mockService)
+}
+
+func TestVariantHandlerTestSuite(t *testing.T) {
+	suite.Run(t, new(VariantHandlerTestSuite))
+}
+
+func (suite *VariantHandlerTestSuite) prepareContext(path string, body any) (*gin.Context, *httptest.ResponseRecorder) {
+	requestBody, _ := json.Marshal(body)
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest("POST", path, bytes.NewBuffer(requestBody))
+	c, _ := gin.CreateTestContext(w)
+	c.Request = r
+
+	return c, w
+}
+
+// AddOption
+// =================================================================================================
+
+func (suite *VariantHandlerTestSuite) TestAddOptionSuccess() {
+	req := &dto.CreateOptionReq{Name: "Size", Values: []string{"S", "M"}}
+	ctx, writer := suite.prepareContext("/api/v1/products/productId1/options", req)
+	ctx.AddParam("id", "productId1")
+
+	suite.mockService.On("AddOption", mock.Anything, "productId1", req).
+		Return(&model.Product{
+			ID: "productId1",
+			Options: []*model.ProductOption{{
+				ID:     "optionId1",
+				Name:   "Size",
+				Values: []*model.ProductOptionValue{{Value: "S"}, {Value: "M", Position: 1}},
+			}},
+		}, nil).Times(1)
+	suite.mockRedis.On("InvalidateTags", productIDTag("productId1"), productListTag).Return(nil).Times(1)
+
+	suite.handler.AddOption(ctx)
+
+	var res response.Response
+	var resData dto.Product
+
+	_ = json.Unmarshal(writer.Body.Bytes(), &res)
+	utils.Copy(&resData, &res.Result)
+	suite.Equal(http.StatusOK, writer.Code)
+	suite.Equal("Size", resData.Options[0].Name)
+	suite.Equal("M", resData.Options[0].Values[1].Value)
+}
+
+func (suite *VariantHandlerTestSuite) TestAddOptionInvalidOption() {
+	req := &dto.CreateOptionReq{Name: "Colour", Values: []string{"Red"}}
+	ctx, writer := suite.prepareContext("/api/v1/products/productId1/options", req)
+	ctx.AddParam("id", "productId1")
+
+	suite.mockService.On("AddOption", mock.Anything, "productId1", req).
+		Return(nil, fmt.Errorf("%w: Colour", service.ErrInvalidOption)).Times(1)
+
+	suite.handler.AddOption(ctx)
+	suite.Equal(http.StatusBadRequest, writer.Code)
+}
+
+func (suite *VariantHandlerTestSuite) TestAddOptionInvalidBody() {
+	ctx, writer := suite.prepareContext("/api/v1/products/productId1/options", map[string]any{"values": "S"})
+	ctx.AddParam("id", "productId1")
+
+	suite.handler.AddOption(ctx)
+	suite.Equal(http.StatusBadRequest, writer.Code)
+}
+
+// CreateVariant
+// =================================================================================================
+
+func (suite *VariantHandlerTestSuite) TestCreateVariantSuccess() {
+	req := &dto.CreateVariantReq{SKU: "TEE-S", Options: map[string]string{"Size": "S"}}
+	ctx, writer := suite.prepareContext("/api/v1/products/productId1/variants", req)
+	ctx.AddParam("id", "productId1")
+
+	suite.mockService.On("CreateVariant", mock.Anything, "productId1", req).
+		Return(&model.ProductVariant{
+			ID:      "variantId1",
+			SKU:     "TEE-S",
+			Price:   money.New(1000, "USD"),
+			Active:  true,
+			Options: []*model.VariantOption{{Name: "Size", Value: "S"}},
+		}, nil).Times(1)
+	suite.mockRedis.On("InvalidateTags", productIDTag("productId1"), productListTag).Return(nil).Times(1)
+
+	suite.handler.CreateVariant(ctx)
+
+	var res response.Response
+	var resData dto.Variant
+
+	_ = json.Unmarshal(writer.Body.Bytes(), &res)
+	utils.Copy(&resData, &res.Result)
+	suite.Equal(http.StatusOK, writer.Code)
+	suite.Equal("TEE-S", resData.SKU)
+	suite.Equal(money.New(1000, "USD"), resData.Price)
+	suite.Equal("S", resData.Options[0].Value)
+}
+
+func (suite *VariantHandlerTestSuite) TestCreateVariantSKUTaken() {
+	req := &dto.CreateVariantReq{SKU: "TEE-S", Options: map[string]string{"Size": "S"}}
+	ctx, writer := suite.prepareContext("/api/v1/products/productId1/variants", req)
+	ctx.AddParam("id", "productId1")
+
+	suite.mockService.On("CreateVariant", mock.Anything, "productId1", req).
+		Return(nil, fmt.Errorf("%w: TEE-S", service.ErrSKUTaken)).Times(1)
+
+	suite.handler.CreateVariant(ctx)
+	suite.Equal(http.StatusBadRequest, writer.Code)
+}
+
+func (suite *VariantHandlerTestSuite) TestCreateVariantFail() {
+	req := &dto.CreateVariantReq{SKU: "TEE-S", Options: map[string]string{"Size": "S"}}
+	ctx, writer := suite.prepareContext("/api/v1/products/productId1/variants", req)
+	ctx.AddParam("id", "productId1")
+
+	suite.mockService.On("CreateVariant", mock.Anything, "productId1", req).
+		Return(nil, errors.New("error")).Times(1)
+
+	suite.handler.CreateVariant(ctx)
+	suite.Equal(http.StatusInternalServerError, writer.Code)
+}
+
+// UpdateVariant
+// =================================================================================================
+
+func (suite *VariantHandlerTestSuite) TestUpdateVariantSuccess() {
+	active := false
+	req := &dto.UpdateVariantReq{Active: &active}
+	ctx, writer := suite.prepareContext("/api/v1/products/productId1/variants/variantId1", req)
+	ctx.AddParam("id", "productId1")
+	ctx.AddParam("variant_id", "variantId1")
+
+	suite.mockService.On("UpdateVariant", mock.Anything, "productId1", "variantId1", req).
+		Return(&model.ProductVariant{ID: "variantId1", SKU: "TEE-S"}, nil).Times(1)
+	suite.mockRedis.On("InvalidateTags", productIDTag("productId1"), productListTag).Return(nil).Times(1)
+
+	suite.handler.UpdateVariant(ctx)
+
+	var res response.Response
+	var resData dto.Variant
+
+	_ = json.Unmarshal(writer.Body.Bytes(), &res)
+	utils.Copy(&resData, &res.Result)
+	suite.Equal(http.StatusOK, writer.Code)
+	suite.False(resData.Active)
+}
+
+func (suite *VariantHandlerTestSuite) TestUpdateVariantNotFound() {
+	req := &dto.UpdateVariantReq{SKU: "TEE-S"}
+	ctx, writer := suite.prepareContext("/api/v1/products/productId1/variants/variantId2", req)
+	ctx.AddParam("id", "productId1")
+	ctx.AddParam("variant_id", "variantId2")
+
+	suite.mockService.On("UpdateVariant", mock.Anything, "productId1", "variantId2", req).
+		Return(nil, fmt.Errorf("%w: variantId2", service.ErrVariantNotFound)).Times(1)
+
+	suite.handler.UpdateVariant(ctx)
+	suite.Equal(http.StatusNotFound, writer.Code)
+}
+
+// AdjustStock
+// =================================================================================================
+
+func (suite *VariantHandlerTestSuite) TestAdjustStockSuccess() {
+	req := &dto.AdjustStockReq{Quantity: 5, Reason: "restock"}
+	ctx, writer := suite.prepareContext("/api/v1/products/productId1/variants/variantId1/stock", req)
+	ctx.AddParam("id", "productId1")
+	ctx.AddParam("variant_id", "variantId1")
+	ctx.Set("userId", "userId1")
+
+	suite.mockService.On("AdjustStock", mock.Anything, "productId1", "variantId1", "userId1", req).
+		Return(&model.VariantStock{VariantID: "variantId1", OnHand: 5, Available: 5}, nil).Times(1)
+	suite.mockRedis.On("InvalidateTags", productIDTag("productId1"), productListTag).Return(nil).Times(1)
+
+	suite.handler.AdjustStock(ctx)
+
+	var res response.Response
+	var resData dto.ProductStock
+
+	_ = json.Unmarshal(writer.Body.Bytes(), &res)
+	utils.Copy(&resData, &res.Result)
+	suite.Equal(http.StatusOK, writer.Code)
+	suite.Equal(int64(5), resData.Available)
+}
+
+func (suite *VariantHandlerTestSuite) TestAdjustStockBelowReserved() {
+	req := &dto.AdjustStockReq{Quantity: -5, Reason: "damaged"}
+	ctx, writer := suite.prepareContext("/api/v1/products/productId1/variants/variantId1/stock", req)
+	ctx.AddParam("id", "productId1")
+	ctx.AddParam("variant_id", "variantId1")
+
+	suite.mockService.On("AdjustStock", mock.Anything, "productId1", "variantId1", "", req).
+		Return(nil, repository.ErrStockBelowReserved).Times(1)
+
+	suite.handler.AdjustStock(ctx)
+	suite.Equal(http.StatusBadRequest, writer.Code)
+}
@@ -0,0 +1,125 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"goshop/internal/product/model"
+	"goshop/pkg/dbs"
+)
+
+// ErrCategoryNotFound is returned when products are assigned to a category that does not exist
+var ErrCategoryNotFound = errors.New("category not found")
+
+// categoryTreeLockID is the key of the advisory lock held while moving a category,
+// so that moves are checked for cycles one at a time
+const categoryTreeLockID = 7311299632
+
+// descendantsQuery selects the ids of a category and of its descendants. UNION drops the rows
+// already selected, so the query ends even if the categories somehow form a cycle.
+const descendantsQuery = "WITH RECURSIVE tree AS (" +
+	"SELECT id FROM categories WHERE %s " +
+	"UNION SELECT categories.id FROM categories JOIN tree ON categories.parent_id = tree.id" +
+	") SELECT id FROM tree"
+
+//go:generate mockery --name=ICategoryRepository
+type ICategoryRepository interface {
+	Create(ctx context.Context, category *model.Category) error
+	Update(ctx context.Context, category *model.Category) error
+	Delete(ctx context.Context, category *model.Category) error
+	GetCategoryByID(ctx context.Context, id string) (*model.Category, error)
+	GetCategoryBySlug(ctx context.Context, slug string) (*model.Category, error)
+	ListCategories(ctx context.Context) ([]*model.Category, error)
+	GetDescendantIDs(ctx context.Context, id string) ([]string, error)
+	CountChildren(ctx context.Context, id string) (int64, error)
+	SlugExists(ctx context.Context, slug, excludeID string) (bool, error)
+	LockTree(ctx context.Context) error
+}
+
+type CategoryRepo struct {
+	db dbs.IDatabase
+}
+
+func NewCategoryRepository(db dbs.IDatabase) *CategoryRepo {
+	return &CategoryRepo{db: db}
+}
+
+func (r *CategoryRepo) Create(ctx context.Context, category *model.Category) error {
+	return r.db.Create(ctx, category)
+}
+
+func (r *CategoryRepo) Update(ctx context.Context, category *model.Category) error {
+	return r.db.Update(ctx, category)
+}
+
+func (r *CategoryRepo) Delete(ctx context.Context, category *model.Category) error {
+	return r.db.Delete(ctx, category, dbs.WithQuery(dbs.NewQuery("id = ?", category.ID)))
+}
+
+func (r *CategoryRepo) GetCategoryByID(ctx context.Context, id string) (*model.Category, error) {
+	var category model.Category
+	if err := r.db.FindOne(ctx, &category, dbs.WithQuery(dbs.NewQuery("id = ?", id))); err != nil {
+		return nil, err
+	}
+	return &category, nil
+}
+
+func (r *CategoryRepo) GetCategoryBySlug(ctx context.Context, slug string) (*model.Category, error) {
+	var category model.Category
+	if err := r.db.FindOne(ctx, &category, dbs.WithQuery(dbs.NewQuery("slug = ?", slug))); err != nil {
+		return nil, err
+	}
+	return &category, nil
+}
+
+// ListCategories returns every category, ordered by position then name
+func (r *CategoryRepo) ListCategories(ctx context.Context) ([]*model.Category, error) {
+	var categories []*model.Category
+	if err := r.db.Find(ctx, &categories, dbs.WithOrder("position, name"), dbs.WithLimit(-1)); err != nil {
+		return nil, err
+	}
+	return categories, nil
+}
+
+// GetDescendantIDs returns the id of the category and the ids of all of its descendants
+func (r *CategoryRepo) GetDescendantIDs(ctx context.Context, id string) ([]string, error) {
+	var categories []*model.Category
+	query := dbs.NewQuery("id IN ("+fmt.Sprintf(descendantsQuery, "id = ?")+")", id)
+	if err := r.db.Find(ctx, &categories, dbs.WithQuery(query), dbs.WithLimit(-1)); err != nil {
+		return nil, err
+	}
+
+	ids := make([]string, 0, len(categories))
+	for _, category := range categories {
+		ids = append(ids, category.ID)
+	}
+	return ids, nil
+}
+
+func (r *CategoryRepo) CountChildren(ctx context.Context, id string) (int64, error) {
+	var total int64
+	if err := r.db.Count(ctx, &model.Category{}, &total, dbs.WithQuery(dbs.NewQuery("parent_id = ?", id))); err != nil {
+		return 0, err
+	}
+	return total, nil
+}
+
+// SlugExists reports whether a category other than excludeID has slug
+func (r *CategoryRepo) SlugExists(ctx context.Context, slug, excludeID string) (bool, error) {
+	var total int64
+	query := dbs.WithQuery(
+		dbs.NewQuery("slug = ?", slug),
+		dbs.NewQuery("id <> ?", excludeID),
+	)
+	if err := r.db.Count(ctx, &model.Category{}, &total, query); err != nil {
+		return false, err
+	}
+	return total > 0, nil
+}
+
+// LockTree keeps the other transactions from moving categories until the end of the
+// transaction in ctx
+func (r *CategoryRepo) LockTree(ctx context.Context) error {
+	return r.db.Lock(ctx, categoryTreeLockID)
+}
@@ -0,0 +1,210 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/quangdangfit/gocommon/logger"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+
+	"goshop/internal/product/model"
+	"goshop/pkg/config"
+	"goshop/pkg/dbs"
+	"goshop/pkg/dbs/mocks"
+)
+
+type CategoryRepositoryTestSuite struct {
+	suite.Suite
+	mockDB *mocks.IDatabase
+	repo   ICategoryRepository
+}
+
+func (suite *CategoryRepositoryTestSuite) SetupTest() {
+	logger.Initialize(config.ProductionEnv)
+
+	suite.mockDB = mocks.NewIDatabase(suite.T())
+	suite.repo = NewCategoryRepository(suite.mockDB)
+}
+
+func TestCategoryRepositoryTestSuite(t *testing.T) {
+	suite.Run(t, new(CategoryRepositoryTestSuite))
+}
+
+// Create
+// =================================================================
+
+func (suite *CategoryRepositoryTestSuite) TestCreateCategorySuccessfully() {
+	category := &model.Category{Name: "Shoes", Slug: "shoes"}
+	suite.mockDB.On("Create", mock.Anything, category).Return(nil).Times(1)
+
+	err := suite.repo.Create(context.Background(), category)
+	suite.Nil(err)
+}
+
+func (suite *CategoryRepositoryTestSuite) TestCreateCategoryFail() {
+	category := &model.Category{Name: "Shoes", Slug: "shoes"}
+	suite.mockDB.On("Create", mock.Anything, category).Return(errors.New("error")).Times(1)
+
+	err := suite.repo.Create(context.Background(), category)
+	suite.NotNil(err)
+}
+
+// Update
+// =================================================================
+
+func (suite *CategoryRepositoryTestSuite) TestUpdateCategorySuccessfully() {
+	category := &model.Category{ID: "categoryId1", Name: "Shoes", Slug: "shoes"}
+	suite.mockDB.On("Update", mock.Anything, category).Return(nil).Times(1)
+
+	err := suite.repo.Update(context.Background(), category)
+	suite.Nil(err)
+}
+
+// Delete
+// =================================================================
+
+func (suite *CategoryRepositoryTestSuite) TestDeleteCategorySuccessfully() {
+	category := &model.Category{ID: "categoryId1"}
+	suite.mockDB.On("Delete", mock.Anything, category, mock.Anything).Return(nil).Times(1)
+
+	err := suite.repo.Delete(context.Background(), category)
+	suite.Nil(err)
+}
+
+// GetCategoryByID
+// =================================================================
+
+func (suite *CategoryRepositoryTestSuite) TestGetCategoryByIDSuccessfully() {
+	suite.mockDB.On("FindOne", mock.Anything, &model.Category{}, mock.Anything).
+		Return(nil).Times(1)
+
+	category, err := suite.repo.GetCategoryByID(context.Background(), "categoryId1")
+	suite.Nil(err)
+	suite.NotNil(category)
+}
+
+func (suite *CategoryRepositoryTestSuite) TestGetCategoryByIDFail() {
+	suite.mockDB.On("FindOne", mock.Anything, &model.Category{}, mock.Anything).
+		Return(errors.New("error")).Times(1)
+
+	category, err := suite.repo.GetCategoryByID(context.Background(), "categoryId1")
+	suite.NotNil(err)
+	suite.Nil(category)
+}
+
+// GetCategoryBySlug
+// =================================================================
+
+func (suite *CategoryRepositoryTestSuite) TestGetCategoryBySlugSuccessfully() {
+	suite.mockDB.On("FindOne", mock.Anything, &model.Category{}, mock.Anything).
+		Return(nil).Times(1)
+
+	category, err := suite.repo.GetCategoryBySlug(context.Background(), "shoes")
+	suite.Nil(err)
+	suite.NotNil(category)
+}
+
+func (suite *CategoryRepositoryTestSuite) TestGetCategoryBySlugFail() {
+	suite.mockDB.On("FindOne", mock.Anything, &model.Category{}, mock.Anything).
+		Return(errors.New("error")).Times(1)
+
+	category, err := suite.repo.GetCategoryBySlug(context.Background(), "shoes")
+	suite.NotNil(err)
+	suite.Nil(category)
+}
+
+// ListCategories
+// =================================================================
+
+func (suite *CategoryRepositoryTestSuite) TestListCategoriesSuccessfully() {
+	suite.mockDB.On("Find", mock.Anything, mock.Anything, mock.Anything, mock.Anything).
+		Return(nil).Times(1)
+
+	categories, err := suite.repo.ListCategories(context.Background())
+	suite.Nil(err)
+	suite.Equal(0, len(categories))
+}
+
+func (suite *CategoryRepositoryTestSuite) TestListCategoriesFail() {
+	suite.mockDB.On("Find", mock.Anything, mock.Anything, mock.Anything, mock.Anything).
+		Return(errors.New("error")).Times(1)
+
+	categories, err := suite.repo.ListCategories(context.Background())
+	suite.NotNil(err)
+	suite.Nil(categories)
+}
+
+// GetDescendantIDs
+// =================================================================
+
+func (suite *CategoryRepositoryTestSuite) TestGetDescendantIDsSuccessfully() {
+	suite.mockDB.On("Find", mock.Anything, mock.Anything, mock.Anything, mock.Anything).
+		Return(func(ctx context.Context, dest any, opts ...dbs.FindOption) error {
+			*dest.(*[]*model.Category) = []*model.Category{{ID: "categoryId1"}, {ID: "categoryId2"}}
+			return nil
+		}).Times(1)
+
+	ids, err := suite.repo.GetDescendantIDs(context.Background(), "categoryId1")
+	suite.Nil(err)
+	suite.Equal([]string{"categoryId1", "categoryId2"}, ids)
+}
+
+func (suite *CategoryRepositoryTestSuite) TestGetDescendantIDsFail() {
+	suite.mockDB.On("Find", mock.Anything, mock.Anything, mock.Anything, mock.Anything).
+		Return(errors.New("error")).Times(1)
+
+	ids, err := suite.repo.GetDescendantIDs(context.Background(), "categoryId1")
+	suite.NotNil(err)
+	suite.Nil(ids)
+}
+
+// CountChildren
+// =================================================================
+
+func (suite *CategoryRepositoryTestSuite) TestCountChildren() {
+	suite.mockDB.On("Count", mock.Anything, &model.Category{}, mock.Anything, mock.Anything).
+		Return(func(ctx context.Context, model any, total *int64, opts ...dbs.FindOption) error {
+			*total = 3
+			return nil
+		}).Times(1)
+
+	total, err := suite.repo.CountChildren(context.Background(), "categoryId1")
+	suite.Nil(err)
+	suite.Equal(int64(3), total)
+}
+
+// SlugExists
+// =================================================================
+
+func (suite *CategoryRepositoryTestSuite) TestSlugExists() {
+	suite.mockDB.On("Count", mock.Anything, &model.Category{}, mock.Anything, mock.Anything).
+		Return(func(ctx context.Context, model any, total *int64, opts ...dbs.FindOption) error {
+			*total = 1
+			return nil
+		}).Times(1)
+
+	exists, err := suite.repo.SlugExists(context.Background(), "shoes", "categoryId1")
+	suite.Nil(err)
+	suite.True(exists)
+}
+
+func (suite *CategoryRepositoryTestSuite) TestSlugExistsFail() {
+	suite.mockDB.On("Count", mock.Anything, &model.Category{}, mock.Anything, mock.Anything).
+		Return(errors.New("error")).Times(1)
+
+	exists, err := suite.repo.SlugExists(context.Background(), "shoes", "")
+	suite.NotNil(err)
+	suite.False(exists)
+}
+
+// LockTree
+// =================================================================
+
+func (suite *CategoryRepositoryTestSuite) TestLockTree() {
+	suite.mockDB.On("Lock", mock.Anything, int64(categoryTreeLockID)).Return(nil).Times(1)
+
+	err := suite.repo.LockTree(context.Background())
+	suite.Nil(err)
+}
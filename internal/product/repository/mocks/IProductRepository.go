@@ -18,6 +18,32 @@ type IProductRepository struct {
 	mock.Mock
 }
 
+// AdjustStock provides a mock function with given fields: ctx, movement
+func (_m *IProductRepository) AdjustStock(ctx context.Context, movement *model.StockMovement) (*model.ProductStock, error) {
+	ret := _m.Called(ctx, movement)
+
+	var r0 *model.ProductStock
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, *model.StockMovement) (*model.ProductStock, error)); ok {
+		return rf(ctx, movement)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, *model.StockMovement) *model.ProductStock); ok {
+		r0 = rf(ctx, movement)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*model.ProductStock)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, *model.StockMovement) error); ok {
+		r1 = rf(ctx, movement)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // Create provides a mock function with given fields: ctx, product
 func (_m *IProductRepository) Create(ctx context.Context, product *model.Product) error {
 	ret := _m.Called(ctx, product)
@@ -58,6 +84,32 @@ func (_m *IProductRepository) GetProductByID(ctx context.Context, id string) (*m
 	return r0, r1
 }
 
+// ListProductFacets provides a mock function with given fields: ctx, req
+func (_m *IProductRepository) ListProductFacets(ctx context.Context, req *dto.ListProductReq) (*model.ProductFacets, error) {
+	ret := _m.Called(ctx, req)
+
+	var r0 *model.ProductFacets
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, *dto.ListProductReq) (*model.ProductFacets, error)); ok {
+		return rf(ctx, req)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, *dto.ListProductReq) *model.ProductFacets); ok {
+		r0 = rf(ctx, req)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*model.ProductFacets)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, *dto.ListProductReq) error); ok {
+		r1 = rf(ctx, req)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // ListProducts provides a mock function with given fields: ctx, req
 func (_m *IProductRepository) ListProducts(ctx context.Context, req *dto.ListProductReq) ([]*model.Product, *paging.Pagination, error) {
 	ret := _m.Called(ctx, req)
@@ -93,6 +145,90 @@ func (_m *IProductRepository) ListProducts(ctx context.Context, req *dto.ListPro
 	return r0, r1, r2
 }
 
+// ListStockMovements provides a mock function with given fields: ctx, productID, req
+func (_m *IProductRepository) ListStockMovements(ctx context.Context, productID string, req *dto.ListStockMovementReq) ([]*model.StockMovement, *paging.Pagination, error) {
+	ret := _m.Called(ctx, productID, req)
+
+	var r0 []*model.StockMovement
+	var r1 *paging.Pagination
+	var r2 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, *dto.ListStockMovementReq) ([]*model.StockMovement, *paging.Pagination, error)); ok {
+		return rf(ctx, productID, req)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, *dto.ListStockMovementReq) []*model.StockMovement); ok {
+		r0 = rf(ctx, productID, req)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*model.StockMovement)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, *dto.ListStockMovementReq) *paging.Pagination); ok {
+		r1 = rf(ctx, productID, req)
+	} else {
+		if ret.Get(1) != nil {
+			r1 = ret.Get(1).(*paging.Pagination)
+		}
+	}
+
+	if rf, ok := ret.Get(2).(func(context.Context, string, *dto.ListStockMovementReq) error); ok {
+		r2 = rf(ctx, productID, req)
+	} else {
+		r2 = ret.Error(2)
+	}
+
+	return r0, r1, r2
+}
+
+// SearchProducts provides a mock function with given fields: ctx, req
+func (_m *IProductRepository) SearchProducts(ctx context.Context, req *dto.SearchProductReq) ([]*model.Product, *paging.Pagination, error) {
+	ret := _m.Called(ctx, req)
+
+	var r0 []*model.Product
+	var r1 *paging.Pagination
+	var r2 error
+	if rf, ok := ret.Get(0).(func(context.Context, *dto.SearchProductReq) ([]*model.Product, *paging.Pagination, error)); ok {
+		return rf(ctx, req)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, *dto.SearchProductReq) []*model.Product); ok {
+		r0 = rf(ctx, req)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*model.Product)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, *dto.SearchProductReq) *paging.Pagination); ok {
+		r1 = rf(ctx, req)
+	} else {
+		if ret.Get(1) != nil {
+			r1 = ret.Get(1).(*paging.Pagination)
+		}
+	}
+
+	if rf, ok := ret.Get(2).(func(context.Context, *dto.SearchProductReq) error); ok {
+		r2 = rf(ctx, req)
+	} else {
+		r2 = ret.Error(2)
+	}
+
+	return r0, r1, r2
+}
+
+// SetCategories provides a mock function with given fields: ctx, productID, categoryIDs
+func (_m *IProductRepository) SetCategories(ctx context.Context, productID string, categoryIDs []string) error {
+	ret := _m.Called(ctx, productID, categoryIDs)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, []string) error); ok {
+		r0 = rf(ctx, productID, categoryIDs)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // Update provides a mock function with given fields: ctx, product
 func (_m *IProductRepository) Update(ctx context.Context, product *model.Product) error {
 	ret := _m.Called(ctx, product)
@@ -0,0 +1,161 @@
+// Code generated by mockery v2.32.4. DO NOT EDIT.
+
+package mocks
+
+import (
+	context "context"
+	model "goshop/internal/product/model"
+
+	mock "github.com/stretchr/testify/mock"
+)
+
+// IVariantRepository is an autogenerated mock type for the IVariantRepository type
+type IVariantRepository struct {
+	mock.Mock
+}
+
+// AdjustStock provides a mock function with given fields: ctx, movement
+func (_m *IVariantRepository) AdjustStock(ctx context.Context, movement *model.StockMovement) (*model.VariantStock, error) {
+	ret := _m.Called(ctx, movement)
+
+	var r0 *model.VariantStock
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, *model.StockMovement) (*model.VariantStock, error)); ok {
+		return rf(ctx, movement)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, *model.StockMovement) *model.VariantStock); ok {
+		r0 = rf(ctx, movement)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*model.VariantStock)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, *model.StockMovement) error); ok {
+		r1 = rf(ctx, movement)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// CreateOption provides a mock function with given fields: ctx, option
+func (_m *IVariantRepository) CreateOption(ctx context.Context, option *model.ProductOption) error {
+	ret := _m.Called(ctx, option)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *model.ProductOption) error); ok {
+		r0 = rf(ctx, option)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// CreateOptionValues provides a mock function with given fields: ctx, values
+func (_m *IVariantRepository) CreateOptionValues(ctx context.Context, values []*model.ProductOptionValue) error {
+	ret := _m.Called(ctx, values)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, []*model.ProductOptionValue) error); ok {
+		r0 = rf(ctx, values)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// CreateVariant provides a mock function with given fields: ctx, variant
+func (_m *IVariantRepository) CreateVariant(ctx context.Context, variant *model.ProductVariant) error {
+	ret := _m.Called(ctx, variant)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *model.ProductVariant) error); ok {
+		r0 = rf(ctx, variant)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// GetVariantByID provides a mock function with given fields: ctx, productID, id
+func (_m *IVariantRepository) GetVariantByID(ctx context.Context, productID string, id string) (*model.ProductVariant, error) {
+	ret := _m.Called(ctx, productID, id)
+
+	var r0 *model.ProductVariant
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) (*model.ProductVariant, error)); ok {
+		return rf(ctx, productID, id)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) *model.ProductVariant); ok {
+		r0 = rf(ctx, productID, id)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*model.ProductVariant)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, string) error); ok {
+		r1 = rf(ctx, productID, id)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// SKUExists provides a mock function with given fields: ctx, sku, excludeID
+func (_m *IVariantRepository) SKUExists(ctx context.Context, sku string, excludeID string) (bool, error) {
+	ret := _m.Called(ctx, sku, excludeID)
+
+	var r0 bool
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) (bool, error)); ok {
+		return rf(ctx, sku, excludeID)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) bool); ok {
+		r0 = rf(ctx, sku, excludeID)
+	} else {
+		r0 = ret.Get(0).(bool)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, string) error); ok {
+		r1 = rf(ctx, sku, excludeID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// UpdateVariant provides a mock function with given fields: ctx, variant
+func (_m *IVariantRepository) UpdateVariant(ctx context.Context, variant *model.ProductVariant) error {
+	ret := _m.Called(ctx, variant)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *model.ProductVariant) error); ok {
+		r0 = rf(ctx, variant)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// NewIVariantRepository creates a new instance of IVariantRepository. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewIVariantRepository(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *IVariantRepository {
+	mock := &IVariantRepository{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
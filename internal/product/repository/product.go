@@ -2,20 +2,42 @@ package repository
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
 
 	"goshop/internal/product/dto"
 	"goshop/internal/product/model"
-	"goshop/pkg/config"
 	"goshop/pkg/dbs"
+	"goshop/pkg/money"
 	"goshop/pkg/paging"
 )
 
+// ErrStockBelowReserved is returned when an adjustment would leave less stock on hand than is reserved
+var ErrStockBelowReserved = errors.New("stock on hand cannot be less than reserved stock")
+
+// searchDocument is the text search vector of a product, names weigh more than descriptions.
+// It is kept in the search_vector column by Create and Update.
+const searchDocument = "setweight(to_tsvector('simple', coalesce(name, '')), 'A') || " +
+	"setweight(to_tsvector('simple', coalesce(description, '')), 'B')"
+
+// productPreloads are the associations read with products
+var productPreloads = []string{"Stock", "Categories", "Options.Values", "Variants.Options", "Variants.Stock"}
+
 //go:generate mockery --name=IProductRepository
 type IProductRepository interface {
 	Create(ctx context.Context, product *model.Product) error
 	Update(ctx context.Context, product *model.Product) error
 	ListProducts(ctx context.Context, req *dto.ListProductReq) ([]*model.Product, *paging.Pagination, error)
+	ListProductFacets(ctx context.Context, req *dto.ListProductReq) (*model.ProductFacets, error)
+	SearchProducts(ctx context.Context, req *dto.SearchProductReq) ([]*model.Product, *paging.Pagination, error)
+	SetCategories(ctx context.Context, productID string, categoryIDs []string) error
 	GetProductByID(ctx context.Context, id string) (*model.Product, error)
+	AdjustStock(ctx context.Context, movement *model.StockMovement) (*model.ProductStock, error)
+	ListStockMovements(ctx context.Context, productID string, req *dto.ListStockMovementReq) ([]*model.StockMovement, *paging.Pagination, error)
 }
 
 type ProductRepo struct {
@@ -27,9 +49,141 @@ func NewProductRepository(db dbs.IDatabase) *ProductRepo {
 }
 
 func (r *ProductRepo) ListProducts(ctx context.Context, req *dto.ListProductReq) ([]*model.Product, *paging.Pagination, error) {
-	ctx, cancel := context.WithTimeout(ctx, config.DatabaseTimeout)
-	defer cancel()
+	query, err := productFilters(req)
+	if err != nil {
+		return nil, nil, err
+	}
 
+	column, sort := "created_at", "created_at"
+	if c, ok := productSortColumns[req.OrderBy]; ok {
+		column, sort = c, req.OrderBy
+	}
+	position := dbs.Cursor{Column: column, Desc: req.OrderBy != "" && req.OrderDesc}
+	if position.Desc {
+		sort += " desc"
+	}
+
+	var cursor *paging.Cursor
+	if req.Cursor != "" {
+		if cursor, err = paging.DecodeCursor(req.Cursor, sort); err != nil {
+			return nil, nil, err
+		}
+		position.Key, position.ID, position.Before = cursor.Key, cursor.ID, cursor.Before
+	}
+
+	var total int64
+	if err := r.db.Count(ctx, &model.Product{}, &total, dbs.WithQuery(query...)); err != nil {
+		return nil, nil, err
+	}
+
+	pagination := paging.New(req.Page, req.Limit, total)
+	opts := []dbs.FindOption{
+		dbs.WithQuery(query...),
+		dbs.WithLimit(int(pagination.Limit) + 1),
+		dbs.WithCursor(position),
+		dbs.WithPreload(productPreloads),
+	}
+	if cursor != nil {
+		pagination = paging.NewCursor(req.Limit, total)
+	} else {
+		opts = append(opts, dbs.WithOffset(int(pagination.Skip)))
+	}
+
+	var products []*model.Product
+	if err := r.db.Find(ctx, &products, opts...); err != nil {
+		return nil, nil, err
+	}
+
+	products = paging.Page(pagination, products, sort, cursor, func(product *model.Product) (string, string) {
+		return productSortKey(product, column), product.ID
+	})
+	return products, pagination, nil
+}
+
+// ListProductFacets counts the products matching the filters of req by category, and by option
+// value of their active variants
+func (r *ProductRepo) ListProductFacets(ctx context.Context, req *dto.ListProductReq) (*model.ProductFacets, error) {
+	query, err := productFilters(req)
+	if err != nil {
+		return nil, err
+	}
+
+	var categories []*model.CategoryFacet
+	if err := r.db.Find(
+		ctx,
+		&categories,
+		dbs.WithTable("categories"),
+		dbs.WithSelect("categories.slug, categories.name, COUNT(*) AS count"),
+		dbs.WithJoins(dbs.NewQuery("JOIN product_categories ON product_categories.category_id = categories.id")),
+		dbs.WithQuery(inProducts("product_categories.product_id", query)),
+		dbs.WithGroup("categories.slug, categories.name"),
+		dbs.WithOrder("count DESC, categories.name"),
+	); err != nil {
+		return nil, err
+	}
+
+	var values []*optionValueCount
+	if err := r.db.Find(
+		ctx,
+		&values,
+		dbs.WithTable("variant_options"),
+		dbs.WithSelect("variant_options.name, variant_options.value, COUNT(DISTINCT product_variants.product_id) AS count"),
+		dbs.WithJoins(dbs.NewQuery("JOIN product_variants ON product_variants.id = variant_options.variant_id")),
+		dbs.WithQuery(
+			dbs.NewQuery("product_variants.active AND product_variants.deleted_at IS NULL"),
+			inProducts("product_variants.product_id", query),
+		),
+		dbs.WithGroup("variant_options.name, variant_options.value"),
+		dbs.WithOrder("variant_options.name, count DESC, variant_options.value"),
+	); err != nil {
+		return nil, err
+	}
+
+	facets := &model.ProductFacets{Categories: categories}
+	for _, value := range values {
+		n := len(facets.Options)
+		if n == 0 || facets.Options[n-1].Name != value.Name {
+			facets.Options = append(facets.Options, &model.OptionFacet{Name: value.Name})
+			n++
+		}
+		option := facets.Options[n-1]
+		option.Values = append(option.Values, &model.OptionValueFacet{Value: value.Value, Count: value.Count})
+	}
+
+	return facets, nil
+}
+
+// optionValueCount is the number of products having a variant with an option value
+type optionValueCount struct {
+	Name  string
+	Value string
+	Count int64
+}
+
+// productSortColumns are the columns products can be sorted by, by their name in order_by
+var productSortColumns = map[string]string{
+	"created_at": "created_at",
+	"updated_at": "updated_at",
+	"name":       "name",
+	"price":      "price_amount",
+}
+
+// productSortKey returns the value of the sort column of product, as cursors keep it
+func productSortKey(product *model.Product, column string) string {
+	switch column {
+	case "updated_at":
+		return product.UpdatedAt.Format(time.RFC3339Nano)
+	case "name":
+		return product.Name
+	case "price_amount":
+		return strconv.FormatInt(product.Price.Amount, 10)
+	default:
+		return product.CreatedAt.Format(time.RFC3339Nano)
+	}
+}
+
+// productFilters returns the conditions on products of the filters of req
+func productFilters(req *dto.ListProductReq) ([]dbs.Query, error) {
 	query := make([]dbs.Query, 0)
 	if req.Name != "" {
 		query = append(query, dbs.NewQuery("name LIKE ?", "%"+req.Name+"%"))
@@ -37,17 +191,89 @@ func (r *ProductRepo) ListProducts(ctx context.Context, req *dto.ListProductReq)
 	if req.Code != "" {
 		query = append(query, dbs.NewQuery("code = ?", req.Code))
 	}
+	if req.Category != "" {
+		categories := "SELECT id FROM categories WHERE slug = ?"
+		if req.IncludeSubcategories {
+			categories = fmt.Sprintf(descendantsQuery, "slug = ?")
+		}
+		query = append(query, dbs.NewQuery(
+			"id IN (SELECT product_id FROM product_categories WHERE category_id IN ("+categories+"))",
+			req.Category,
+		))
+	}
+	if req.MinPrice != "" || req.MaxPrice != "" {
+		query = append(query, dbs.NewQuery("price_currency = ?", req.Currency))
+	}
+	if req.MinPrice != "" {
+		price, err := money.Parse(req.MinPrice, req.Currency)
+		if err != nil {
+			return nil, err
+		}
+		query = append(query, dbs.NewQuery("price_amount >= ?", price.Amount))
+	}
+	if req.MaxPrice != "" {
+		price, err := money.Parse(req.MaxPrice, req.Currency)
+		if err != nil {
+			return nil, err
+		}
+		query = append(query, dbs.NewQuery("price_amount <= ?", price.Amount))
+	}
+	if req.Active != nil {
+		query = append(query, dbs.NewQuery("active = ?", *req.Active))
+	}
+	if req.CreatedAfter != nil {
+		query = append(query, dbs.NewQuery("created_at > ?", *req.CreatedAfter))
+	}
 
-	order := "created_at"
-	if req.OrderBy != "" {
-		order = req.OrderBy
-		if req.OrderDesc {
-			order += " DESC"
+	// The values given for an option name are alternatives, the names must all match
+	var names []string
+	values := make(map[string][]string)
+	for _, option := range req.Options {
+		name, value, _ := strings.Cut(option, ":")
+		name, value = strings.TrimSpace(name), strings.TrimSpace(value)
+		if _, ok := values[name]; !ok {
+			names = append(names, name)
 		}
+		values[name] = append(values[name], value)
+	}
+	for _, name := range names {
+		query = append(query, dbs.NewQuery(
+			"id IN (SELECT product_variants.product_id FROM product_variants "+
+				"JOIN variant_options ON variant_options.variant_id = product_variants.id "+
+				"WHERE product_variants.active AND product_variants.deleted_at IS NULL "+
+				"AND variant_options.name = ? AND variant_options.value IN ?)",
+			name, values[name],
+		))
 	}
 
+	return query, nil
+}
+
+// inProducts matches the rows whose column is the id of a product meeting the conditions of query
+func inProducts(column string, query []dbs.Query) dbs.Query {
+	products := "SELECT id FROM products"
+	args := make([]any, 0)
+	for i, q := range query {
+		if i == 0 {
+			products += " WHERE "
+		} else {
+			products += " AND "
+		}
+		products += "(" + q.Query + ")"
+		args = append(args, q.Args...)
+	}
+	return dbs.NewQuery(column+" IN ("+products+")", args...)
+}
+
+// SearchProducts finds the products matching the words of req.Query, most relevant first. Every
+// word matches the words of the name or description it starts, and the whole query also matches
+// names it is similar to, so that typos still find products.
+func (r *ProductRepo) SearchProducts(ctx context.Context, req *dto.SearchProductReq) ([]*model.Product, *paging.Pagination, error) {
+	tsQuery := prefixQuery(req.Query)
+	query := dbs.NewQuery("search_vector @@ to_tsquery('simple', ?) OR ? <% name", tsQuery, req.Query)
+
 	var total int64
-	if err := r.db.Count(ctx, &model.Product{}, &total, dbs.WithQuery(query...)); err != nil {
+	if err := r.db.Count(ctx, &model.Product{}, &total, dbs.WithQuery(query)); err != nil {
 		return nil, nil, err
 	}
 
@@ -57,10 +283,14 @@ func (r *ProductRepo) ListProducts(ctx context.Context, req *dto.ListProductReq)
 	if err := r.db.Find(
 		ctx,
 		&products,
-		dbs.WithQuery(query...),
+		dbs.WithQuery(query),
 		dbs.WithLimit(int(pagination.Limit)),
 		dbs.WithOffset(int(pagination.Skip)),
-		dbs.WithOrder(order),
+		dbs.WithOrder(dbs.OrderExpr(
+			"ts_rank(search_vector, to_tsquery('simple', ?)) + word_similarity(?, name) DESC, created_at DESC",
+			tsQuery, req.Query,
+		)),
+		dbs.WithPreload(productPreloads),
 	); err != nil {
 		return nil, nil, err
 	}
@@ -68,18 +298,161 @@ func (r *ProductRepo) ListProducts(ctx context.Context, req *dto.ListProductReq)
 	return products, pagination, nil
 }
 
+// prefixQuery turns the words of text into a tsquery matching the words they start
+func prefixQuery(text string) string {
+	words := strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	for i, word := range words {
+		words[i] = word + ":*"
+	}
+	return strings.Join(words, " & ")
+}
+
+// indexProduct updates the search vector of the product from its name and description
+func (r *ProductRepo) indexProduct(ctx context.Context, id string) error {
+	_, err := r.db.UpdateColumns(
+		ctx,
+		&model.Product{},
+		map[string]any{"search_vector": dbs.Expr(searchDocument)},
+		dbs.WithQuery(dbs.NewQuery("id = ?", id)),
+	)
+	return err
+}
+
+// SetCategories replaces the categories of the product with categoryIDs, failing with
+// ErrCategoryNotFound when one of them does not exist
+func (r *ProductRepo) SetCategories(ctx context.Context, productID string, categoryIDs []string) error {
+	return r.db.WithTransaction(ctx, func(ctx context.Context) error {
+		err := r.db.Delete(ctx, &model.ProductCategory{}, dbs.WithQuery(dbs.NewQuery("product_id = ?", productID)))
+		if err != nil {
+			return err
+		}
+		if len(categoryIDs) == 0 {
+			return nil
+		}
+
+		var total int64
+		if err := r.db.Count(ctx, &model.Category{}, &total, dbs.WithQuery(dbs.NewQuery("id IN ?", categoryIDs))); err != nil {
+			return err
+		}
+		if total != int64(len(categoryIDs)) {
+			return ErrCategoryNotFound
+		}
+
+		assignments := make([]*model.ProductCategory, 0, len(categoryIDs))
+		for _, categoryID := range categoryIDs {
+			assignments = append(assignments, &model.ProductCategory{ProductID: productID, CategoryID: categoryID})
+		}
+		return r.db.CreateInBatches(ctx, assignments, len(assignments))
+	})
+}
+
 func (r *ProductRepo) GetProductByID(ctx context.Context, id string) (*model.Product, error) {
 	var product model.Product
-	if err := r.db.FindById(ctx, id, &product); err != nil {
+	if err := r.db.FindOne(
+		ctx,
+		&product,
+		dbs.WithQuery(dbs.NewQuery("id = ?", id)),
+		dbs.WithPreload(productPreloads),
+	); err != nil {
 		return nil, err
 	}
 	return &product, nil
 }
 
+// Create creates the product and indexes it for search in one transaction
 func (r *ProductRepo) Create(ctx context.Context, product *model.Product) error {
-	return r.db.Create(ctx, product)
+	return r.db.WithTransaction(ctx, func(ctx context.Context) error {
+		if err := r.db.Create(ctx, product); err != nil {
+			return err
+		}
+
+		return r.indexProduct(ctx, product.ID)
+	})
 }
 
+// Update saves the product and indexes it again for search in one transaction
 func (r *ProductRepo) Update(ctx context.Context, product *model.Product) error {
-	return r.db.Update(ctx, product)
+	return r.db.WithTransaction(ctx, func(ctx context.Context) error {
+		if err := r.db.Update(ctx, product); err != nil {
+			return err
+		}
+
+		return r.indexProduct(ctx, product.ID)
+	})
+}
+
+// AdjustStock changes the stock on hand of movement.ProductID by movement.Quantity and
+// records the movement in the stock ledger
+func (r *ProductRepo) AdjustStock(ctx context.Context, movement *model.StockMovement) (*model.ProductStock, error) {
+	var stock model.ProductStock
+	err := adjustStock(ctx, r.db, movement, &stock, "product_id", movement.ProductID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &stock, nil
+}
+
+// adjustStock adds movement.Quantity to the stock on hand of the stock row having id in the key
+// column, records the movement and reads the row into stock, a *model.ProductStock or
+// *model.VariantStock. Stock tables have no id column, the row is read in the order of the key.
+func adjustStock(ctx context.Context, db dbs.IDatabase, movement *model.StockMovement, stock any, column, id string) error {
+	movement.Type = model.StockMovementAdjustment
+	key := dbs.NewQuery(column+" = ?", id)
+
+	handler := func(ctx context.Context) error {
+		updated, err := db.UpdateColumns(
+			ctx,
+			stock,
+			map[string]any{"on_hand": dbs.Expr("on_hand + ?", movement.Quantity)},
+			dbs.WithQuery(
+				key,
+				dbs.NewQuery("on_hand + ? >= reserved", movement.Quantity),
+			),
+		)
+		if err != nil {
+			return err
+		}
+		if updated == 0 {
+			return ErrStockBelowReserved
+		}
+
+		if err := db.Create(ctx, movement); err != nil {
+			return err
+		}
+
+		return db.FindOne(ctx, stock, dbs.WithQuery(key), dbs.WithOrder(column))
+	}
+
+	return db.WithTransaction(ctx, handler)
+}
+
+func (r *ProductRepo) ListStockMovements(ctx context.Context, productID string, req *dto.ListStockMovementReq) ([]*model.StockMovement, *paging.Pagination, error) {
+	query := []dbs.Query{dbs.NewQuery("product_id = ?", productID)}
+	if req.Type != "" {
+		query = append(query, dbs.NewQuery("type = ?", req.Type))
+	}
+
+	var total int64
+	if err := r.db.Count(ctx, &model.StockMovement{}, &total, dbs.WithQuery(query...)); err != nil {
+		return nil, nil, err
+	}
+
+	pagination := paging.New(req.Page, req.Limit, total)
+
+	var movements []*model.StockMovement
+	if err := r.db.Find(
+		ctx,
+		&movements,
+		dbs.WithQuery(query...),
+		dbs.WithLimit(int(pagination.Limit)),
+		dbs.WithOffset(int(pagination.Skip)),
+		dbs.WithOrder("created_at DESC"),
+	); err != nil {
+		return nil, nil, err
+	}
+
+	return movements, pagination, nil
 }
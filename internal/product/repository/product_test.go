@@ -2,6 +2,7 @@ package repository
 
 import (
 	"context"
+	"database/sql"
 	"errors"
 	"testing"
 
@@ -12,7 +13,10 @@ import (
 	"goshop/internal/product/dto"
 	"goshop/internal/product/model"
 	"goshop/pkg/config"
+	"goshop/pkg/dbs"
 	"goshop/pkg/dbs/mocks"
+	"goshop/pkg/money"
+	"goshop/pkg/paging"
 )
 
 type ProductRepositoryTestSuite struct {
@@ -37,12 +41,21 @@ func TestProductRepositoryTestSuite(t *testing.T) {
 
 func (suite *ProductRepositoryTestSuite) TestCreateProductSuccessfully() {
 	product := &model.Product{
+		ID:          "productId1",
 		Name:        "product name",
 		Description: "product description",
-		Price:       10.5,
+		Price:       money.New(1050, "USD"),
 	}
+	suite.expectTransaction()
 	suite.mockDB.On("Create", mock.Anything, product).
 		Return(nil).Times(1)
+	suite.mockDB.On(
+		"UpdateColumns",
+		mock.Anything,
+		&model.Product{},
+		map[string]any{"search_vector": dbs.Expr(searchDocument)},
+		dbs.WithQuery(dbs.NewQuery("id = ?", "productId1")),
+	).Return(int64(1), nil).Times(1)
 
 	err := suite.repo.Create(context.Background(), product)
 	suite.Nil(err)
@@ -52,8 +65,9 @@ func (suite *ProductRepositoryTestSuite) TestCreateProductFail() {
 	product := &model.Product{
 		Name:        "product name",
 		Description: "product description",
-		Price:       10.5,
+		Price:       money.New(1050, "USD"),
 	}
+	suite.expectTransaction()
 	suite.mockDB.On("Create", mock.Anything, product).
 		Return(errors.New("error")).Times(1)
 
@@ -69,10 +83,18 @@ func (suite *ProductRepositoryTestSuite) TestUpdateProductSuccessfully() {
 		ID:          "productId1",
 		Name:        "product name",
 		Description: "product description",
-		Price:       10.5,
+		Price:       money.New(1050, "USD"),
 	}
+	suite.expectTransaction()
 	suite.mockDB.On("Update", mock.Anything, product).
 		Return(nil).Times(1)
+	suite.mockDB.On(
+		"UpdateColumns",
+		mock.Anything,
+		&model.Product{},
+		map[string]any{"search_vector": dbs.Expr(searchDocument)},
+		dbs.WithQuery(dbs.NewQuery("id = ?", "productId1")),
+	).Return(int64(1), nil).Times(1)
 
 	err := suite.repo.Update(context.Background(), product)
 	suite.Nil(err)
@@ -83,8 +105,9 @@ func (suite *ProductRepositoryTestSuite) TestUpdateProductFail() {
 		ID:          "productId1",
 		Name:        "product name",
 		Description: "product description",
-		Price:       10.5,
+		Price:       money.New(1050, "USD"),
 	}
+	suite.expectTransaction()
 	suite.mockDB.On("Update", mock.Anything, product).
 		Return(errors.New("error")).Times(1)
 
@@ -96,7 +119,7 @@ func (suite *ProductRepositoryTestSuite) TestUpdateProductFail() {
 // =================================================================
 
 func (suite *ProductRepositoryTestSuite) TestGetProductByIDSuccessfully() {
-	suite.mockDB.On("FindById", mock.Anything, "productId1", &model.Product{}).
+	suite.mockDB.On("FindOne", mock.Anything, &model.Product{}, mock.Anything, mock.Anything).
 		Return(nil).Times(1)
 
 	product, err := suite.repo.GetProductByID(context.Background(), "productId1")
@@ -105,7 +128,7 @@ func (suite *ProductRepositoryTestSuite) TestGetProductByIDSuccessfully() {
 }
 
 func (suite *ProductRepositoryTestSuite) TestGetProductByIDFail() {
-	suite.mockDB.On("FindById", mock.Anything, "productId1", &model.Product{}).
+	suite.mockDB.On("FindOne", mock.Anything, &model.Product{}, mock.Anything, mock.Anything).
 		Return(errors.New("error")).Times(1)
 
 	product, err := suite.repo.GetProductByID(context.Background(), "productId1")
@@ -129,7 +152,25 @@ func (suite *ProductRepositoryTestSuite) TestListProductsSuccessfully() {
 	suite.mockDB.On("Count", mock.Anything, mock.Anything, mock.Anything, mock.Anything).
 		Return(nil).Times(1)
 
-	suite.mockDB.On("Find", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything).
+	suite.mockDB.On("Find", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything).
+		Return(nil).Times(1)
+
+	products, pagination, err := suite.repo.ListProducts(context.Background(), req)
+	suite.Nil(err)
+	suite.Equal(0, len(products))
+	suite.NotNil(pagination)
+}
+
+func (suite *ProductRepositoryTestSuite) TestListProductsByCategory() {
+	req := &dto.ListProductReq{
+		Category:             "shoes",
+		IncludeSubcategories: true,
+	}
+
+	suite.mockDB.On("Count", mock.Anything, mock.Anything, mock.Anything, mock.Anything).
+		Return(nil).Times(1)
+
+	suite.mockDB.On("Find", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything).
 		Return(nil).Times(1)
 
 	products, pagination, err := suite.repo.ListProducts(context.Background(), req)
@@ -138,6 +179,99 @@ func (suite *ProductRepositoryTestSuite) TestListProductsSuccessfully() {
 	suite.NotNil(pagination)
 }
 
+func (suite *ProductRepositoryTestSuite) TestListProductsWithFilters() {
+	active := true
+	req := &dto.ListProductReq{
+		MinPrice: "10",
+		MaxPrice: "20.50",
+		Currency: "USD",
+		Active:   &active,
+		Options:  []string{"Size:M", "Color:Red", "Size:L"},
+		OrderBy:  "price",
+	}
+
+	suite.mockDB.On("Count", mock.Anything, mock.Anything, mock.Anything, mock.Anything).
+		Return(nil).Times(1)
+
+	suite.mockDB.On("Find", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything).
+		Return(nil).Times(1)
+
+	products, pagination, err := suite.repo.ListProducts(context.Background(), req)
+	suite.Nil(err)
+	suite.Equal(0, len(products))
+	suite.NotNil(pagination)
+}
+
+func (suite *ProductRepositoryTestSuite) TestProductFilters() {
+	active := false
+	query, err := productFilters(&dto.ListProductReq{
+		MinPrice: "10",
+		MaxPrice: "20.50",
+		Currency: "USD",
+		Active:   &active,
+		Options:  []string{"Size:M", "Color: Red", "Size:L"},
+	})
+	suite.Nil(err)
+	suite.Equal(6, len(query))
+	suite.Equal([]any{"USD"}, query[0].Args)
+	suite.Equal([]any{int64(1000)}, query[1].Args)
+	suite.Equal([]any{int64(2050)}, query[2].Args)
+	suite.Equal([]any{false}, query[3].Args)
+	suite.Equal([]any{"Size", []string{"M", "L"}}, query[4].Args)
+	suite.Equal([]any{"Color", []string{"Red"}}, query[5].Args)
+}
+
+func (suite *ProductRepositoryTestSuite) TestProductFiltersInvalidPrice() {
+	query, err := productFilters(&dto.ListProductReq{MinPrice: "ten", Currency: "USD"})
+	suite.NotNil(err)
+	suite.Nil(query)
+}
+
+func (suite *ProductRepositoryTestSuite) TestInProducts() {
+	query := inProducts("product_id", []dbs.Query{
+		dbs.NewQuery("active = ?", true),
+		dbs.NewQuery("price_amount >= ?", 100),
+	})
+	suite.Equal("product_id IN (SELECT id FROM products WHERE (active = ?) AND (price_amount >= ?))", query.Query)
+	suite.Equal([]any{true, 100}, query.Args)
+
+	query = inProducts("product_id", nil)
+	suite.Equal("product_id IN (SELECT id FROM products)", query.Query)
+}
+
+func (suite *ProductRepositoryTestSuite) TestListProductsPreviousPageByCursor() {
+	cursor := paging.EncodeCursor(paging.Cursor{Sort: "name", Key: "d", ID: "p4", Before: true})
+	req := &dto.ListProductReq{Cursor: cursor, Limit: 2, OrderBy: "name"}
+
+	suite.mockDB.On("Count", mock.Anything, mock.Anything, mock.Anything, mock.Anything).
+		Return(nil).Times(1)
+
+	suite.mockDB.On("Find", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything).
+		Run(func(args mock.Arguments) {
+			products := args.Get(1).(*[]*model.Product)
+			*products = []*model.Product{{ID: "p3", Name: "c"}, {ID: "p2", Name: "b"}}
+		}).
+		Return(nil).Times(1)
+
+	products, pagination, err := suite.repo.ListProducts(context.Background(), req)
+	suite.Nil(err)
+	suite.Equal(2, len(products))
+	suite.Equal("p2", products[0].ID)
+	suite.Equal("p3", products[1].ID)
+	suite.Empty(pagination.PrevCursor)
+
+	next, err := paging.DecodeCursor(pagination.NextCursor, "name")
+	suite.Nil(err)
+	suite.Equal(&paging.Cursor{Sort: "name", Key: "c", ID: "p3"}, next)
+}
+
+func (suite *ProductRepositoryTestSuite) TestListProductsInvalidCursor() {
+	products, pagination, err := suite.repo.ListProducts(context.Background(), &dto.ListProductReq{Cursor: "abc"})
+	suite.ErrorIs(err, paging.ErrInvalidCursor)
+	suite.Nil(products)
+	suite.Nil(pagination)
+}
+
 func (suite *ProductRepositoryTestSuite) TestListProductsCountFail() {
 	suite.mockDB.On("Count", mock.Anything, mock.Anything, mock.Anything, mock.Anything).
 		Return(errors.New("error")).Times(1)
@@ -152,7 +286,7 @@ func (suite *ProductRepositoryTestSuite) TestListProductsFindFail() {
 	suite.mockDB.On("Count", mock.Anything, mock.Anything, mock.Anything, mock.Anything).
 		Return(nil).Times(1)
 
-	suite.mockDB.On("Find", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything).
+	suite.mockDB.On("Find", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything).
 		Return(errors.New("error")).Times(1)
 
 	product, pagination, err := suite.repo.ListProducts(context.Background(), &dto.ListProductReq{})
@@ -160,3 +294,235 @@ func (suite *ProductRepositoryTestSuite) TestListProductsFindFail() {
 	suite.Nil(product)
 	suite.Nil(pagination)
 }
+
+// ListProductFacets
+// =================================================================
+
+func (suite *ProductRepositoryTestSuite) TestListProductFacetsSuccessfully() {
+	suite.mockDB.On("Find", mock.Anything, mock.AnythingOfType("*[]*model.CategoryFacet"), mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything).
+		Run(func(args mock.Arguments) {
+			categories := args.Get(1).(*[]*model.CategoryFacet)
+			*categories = []*model.CategoryFacet{{Slug: "shoes", Name: "Shoes", Count: 2}}
+		}).
+		Return(nil).Times(1)
+	suite.mockDB.On("Find", mock.Anything, mock.AnythingOfType("*[]*repository.optionValueCount"), mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything).
+		Run(func(args mock.Arguments) {
+			values := args.Get(1).(*[]*optionValueCount)
+			*values = []*optionValueCount{
+				{Name: "Color", Value: "Red", Count: 1},
+				{Name: "Size", Value: "M", Count: 2},
+				{Name: "Size", Value: "S", Count: 1},
+			}
+		}).
+		Return(nil).Times(1)
+
+	facets, err := suite.repo.ListProductFacets(context.Background(), &dto.ListProductReq{Name: "shoe"})
+	suite.Nil(err)
+	suite.Equal(1, len(facets.Categories))
+	suite.Equal(int64(2), facets.Categories[0].Count)
+	suite.Equal(2, len(facets.Options))
+	suite.Equal("Color", facets.Options[0].Name)
+	suite.Equal("Size", facets.Options[1].Name)
+	suite.Equal(2, len(facets.Options[1].Values))
+	suite.Equal("S", facets.Options[1].Values[1].Value)
+}
+
+func (suite *ProductRepositoryTestSuite) TestListProductFacetsFail() {
+	suite.mockDB.On("Find", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything).
+		Return(errors.New("error")).Times(1)
+
+	facets, err := suite.repo.ListProductFacets(context.Background(), &dto.ListProductReq{})
+	suite.NotNil(err)
+	suite.Nil(facets)
+}
+
+// SearchProducts
+// =================================================================
+
+func (suite *ProductRepositoryTestSuite) TestSearchProductsSuccessfully() {
+	req := &dto.SearchProductReq{Query: "red shoe", Page: 1, Limit: 10}
+
+	suite.mockDB.On("Count", mock.Anything, mock.Anything, mock.Anything, mock.Anything).
+		Return(nil).Times(1)
+	suite.mockDB.On("Find", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything).
+		Return(nil).Times(1)
+
+	products, pagination, err := suite.repo.SearchProducts(context.Background(), req)
+	suite.Nil(err)
+	suite.Equal(0, len(products))
+	suite.NotNil(pagination)
+}
+
+func (suite *ProductRepositoryTestSuite) TestSearchProductsCountFail() {
+	suite.mockDB.On("Count", mock.Anything, mock.Anything, mock.Anything, mock.Anything).
+		Return(errors.New("error")).Times(1)
+
+	products, pagination, err := suite.repo.SearchProducts(context.Background(), &dto.SearchProductReq{Query: "shoe"})
+	suite.NotNil(err)
+	suite.Nil(products)
+	suite.Nil(pagination)
+}
+
+func (suite *ProductRepositoryTestSuite) TestPrefixQuery() {
+	suite.Equal("red:* & shoe:*", prefixQuery("Red  shoe!"))
+	suite.Equal("café:* & 42:*", prefixQuery("café & 42"))
+	suite.Equal("", prefixQuery("'&|!"))
+}
+
+// Search index
+// =================================================================
+
+func (suite *ProductRepositoryTestSuite) TestCreateProductIndexFail() {
+	product := &model.Product{
+		ID:   "productId1",
+		Name: "product name",
+	}
+	suite.expectTransaction()
+	suite.mockDB.On("Create", mock.Anything, product).Return(nil).Times(1)
+	suite.mockDB.On("UpdateColumns", mock.Anything, &model.Product{}, mock.Anything, mock.Anything).
+		Return(int64(0), errors.New("error")).Times(1)
+
+	// The error rolls back the transaction creating the product
+	err := suite.repo.Create(context.Background(), product)
+	suite.NotNil(err)
+}
+
+// SetCategories
+// =================================================================
+
+func (suite *ProductRepositoryTestSuite) TestSetCategoriesSuccessfully() {
+	suite.expectTransaction()
+	suite.mockDB.On("Delete", mock.Anything, &model.ProductCategory{}, mock.Anything).
+		Return(nil).Times(1)
+	suite.mockDB.On("Count", mock.Anything, &model.Category{}, mock.Anything, mock.Anything).
+		Return(func(ctx context.Context, model any, total *int64, opts ...dbs.FindOption) error {
+			*total = 2
+			return nil
+		}).Times(1)
+	suite.mockDB.On("CreateInBatches", mock.Anything, []*model.ProductCategory{
+		{ProductID: "productId1", CategoryID: "categoryId1"},
+		{ProductID: "productId1", CategoryID: "categoryId2"},
+	}, 2).Return(nil).Times(1)
+
+	err := suite.repo.SetCategories(context.Background(), "productId1", []string{"categoryId1", "categoryId2"})
+	suite.Nil(err)
+}
+
+func (suite *ProductRepositoryTestSuite) TestSetCategoriesEmpty() {
+	suite.expectTransaction()
+	suite.mockDB.On("Delete", mock.Anything, &model.ProductCategory{}, mock.Anything).
+		Return(nil).Times(1)
+
+	err := suite.repo.SetCategories(context.Background(), "productId1", nil)
+	suite.Nil(err)
+}
+
+func (suite *ProductRepositoryTestSuite) TestSetCategoriesCategoryNotFound() {
+	suite.expectTransaction()
+	suite.mockDB.On("Delete", mock.Anything, &model.ProductCategory{}, mock.Anything).
+		Return(nil).Times(1)
+	suite.mockDB.On("Count", mock.Anything, &model.Category{}, mock.Anything, mock.Anything).
+		Return(func(ctx context.Context, model any, total *int64, opts ...dbs.FindOption) error {
+			*total = 1
+			return nil
+		}).Times(1)
+
+	err := suite.repo.SetCategories(context.Background(), "productId1", []string{"categoryId1", "categoryId2"})
+	suite.ErrorIs(err, ErrCategoryNotFound)
+}
+
+func (suite *ProductRepositoryTestSuite) TestSetCategoriesDeleteFail() {
+	suite.expectTransaction()
+	suite.mockDB.On("Delete", mock.Anything, &model.ProductCategory{}, mock.Anything).
+		Return(errors.New("error")).Times(1)
+
+	err := suite.repo.SetCategories(context.Background(), "productId1", []string{"categoryId1"})
+	suite.NotNil(err)
+}
+
+// AdjustStock
+// =================================================================
+
+func (suite *ProductRepositoryTestSuite) expectTransaction() {
+	suite.mockDB.On("WithTransaction", mock.Anything, mock.Anything).
+		Return(func(ctx context.Context, function func(ctx context.Context) error) error {
+			return function(ctx)
+		}).Times(1)
+}
+
+func (suite *ProductRepositoryTestSuite) TestAdjustStockSuccessfully() {
+	movement := &model.StockMovement{
+		ProductID: "productId1",
+		Reason:    model.StockReasonRestock,
+		Quantity:  10,
+	}
+
+	suite.expectTransaction()
+	suite.mockDB.On("UpdateColumns", mock.Anything, &model.ProductStock{}, mock.Anything, mock.Anything).
+		Return(int64(1), nil).Times(1)
+	suite.mockDB.On("Create", mock.Anything, movement).Return(nil).Times(1)
+	suite.mockDB.On(
+		"FindOne",
+		mock.Anything,
+		&model.ProductStock{},
+		dbs.WithQuery(dbs.NewQuery("product_id = ?", "productId1")),
+		dbs.WithOrder("product_id"),
+	).
+		Return(nil).Times(1)
+
+	stock, err := suite.repo.AdjustStock(context.Background(), movement)
+	suite.Nil(err)
+	suite.NotNil(stock)
+	suite.Equal(model.StockMovementAdjustment, movement.Type)
+}
+
+func (suite *ProductRepositoryTestSuite) TestAdjustStockBelowReserved() {
+	movement := &model.StockMovement{
+		ProductID: "productId1",
+		Reason:    model.StockReasonDamaged,
+		Quantity:  -10,
+	}
+
+	suite.expectTransaction()
+	suite.mockDB.On("UpdateColumns", mock.Anything, &model.ProductStock{}, mock.Anything, mock.Anything).
+		Return(int64(0), nil).Times(1)
+
+	stock, err := suite.repo.AdjustStock(context.Background(), movement)
+	suite.ErrorIs(err, ErrStockBelowReserved)
+	suite.Nil(stock)
+}
+
+func (suite *ProductRepositoryTestSuite) TestAdjustStockUpdateFail() {
+	suite.expectTransaction()
+	suite.mockDB.On("UpdateColumns", mock.Anything, &model.ProductStock{}, mock.Anything, mock.Anything).
+		Return(int64(0), sql.ErrConnDone).Times(1)
+
+	stock, err := suite.repo.AdjustStock(context.Background(), &model.StockMovement{ProductID: "productId1", Quantity: 1})
+	suite.ErrorIs(err, sql.ErrConnDone)
+	suite.Nil(stock)
+}
+
+// ListStockMovements
+// =================================================================
+
+func (suite *ProductRepositoryTestSuite) TestListStockMovementsSuccessfully() {
+	suite.mockDB.On("Count", mock.Anything, &model.StockMovement{}, mock.Anything, mock.Anything).
+		Return(nil).Times(1)
+	suite.mockDB.On("Find", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything).
+		Return(nil).Times(1)
+
+	movements, pagination, err := suite.repo.ListStockMovements(context.Background(), "productId1", &dto.ListStockMovementReq{Type: "adjustment"})
+	suite.Nil(err)
+	suite.Equal(0, len(movements))
+	suite.NotNil(pagination)
+}
+
+func (suite *ProductRepositoryTestSuite) TestListStockMovementsCountFail() {
+	suite.mockDB.On("Count", mock.Anything, &model.StockMovement{}, mock.Anything, mock.Anything).
+		Return(errors.New("error")).Times(1)
+
+	movements, pagination, err := suite.repo.ListStockMovements(context.Background(), "productId1", &dto.ListStockMovementReq{})
+	suite.NotNil(err)
+	suite.Nil(movements)
+	suite.Nil(pagination)
+}
@@ -0,0 +1,83 @@
+package repository
+
+import (
+	"context"
+
+	"goshop/internal/product/model"
+	"goshop/pkg/dbs"
+)
+
+//go:generate mockery --name=IVariantRepository
+type IVariantRepository interface {
+	CreateOption(ctx context.Context, option *model.ProductOption) error
+	CreateOptionValues(ctx context.Context, values []*model.ProductOptionValue) error
+	CreateVariant(ctx context.Context, variant *model.ProductVariant) error
+	UpdateVariant(ctx context.Context, variant *model.ProductVariant) error
+	GetVariantByID(ctx context.Context, productID, id string) (*model.ProductVariant, error)
+	SKUExists(ctx context.Context, sku, excludeID string) (bool, error)
+	AdjustStock(ctx context.Context, movement *model.StockMovement) (*model.VariantStock, error)
+}
+
+type VariantRepo struct {
+	db dbs.IDatabase
+}
+
+func NewVariantRepository(db dbs.IDatabase) *VariantRepo {
+	return &VariantRepo{db: db}
+}
+
+// CreateOption creates the option with its values
+func (r *VariantRepo) CreateOption(ctx context.Context, option *model.ProductOption) error {
+	return r.db.Create(ctx, option)
+}
+
+func (r *VariantRepo) CreateOptionValues(ctx context.Context, values []*model.ProductOptionValue) error {
+	return r.db.CreateInBatches(ctx, values, len(values))
+}
+
+// CreateVariant creates the variant with its options and an empty stock
+func (r *VariantRepo) CreateVariant(ctx context.Context, variant *model.ProductVariant) error {
+	return r.db.Create(ctx, variant)
+}
+
+func (r *VariantRepo) UpdateVariant(ctx context.Context, variant *model.ProductVariant) error {
+	return r.db.Update(ctx, variant)
+}
+
+func (r *VariantRepo) GetVariantByID(ctx context.Context, productID, id string) (*model.ProductVariant, error) {
+	var variant model.ProductVariant
+	opts := []dbs.FindOption{
+		dbs.WithQuery(dbs.NewQuery("id = ?", id), dbs.NewQuery("product_id = ?", productID)),
+		dbs.WithPreload([]string{"Options", "Stock"}),
+	}
+	if err := r.db.FindOne(ctx, &variant, opts...); err != nil {
+		return nil, err
+	}
+
+	return &variant, nil
+}
+
+// SKUExists reports whether a variant other than excludeID has sku
+func (r *VariantRepo) SKUExists(ctx context.Context, sku, excludeID string) (bool, error) {
+	var total int64
+	query := dbs.WithQuery(
+		dbs.NewQuery("sku = ?", sku),
+		dbs.NewQuery("id <> ?", excludeID),
+	)
+	if err := r.db.Count(ctx, &model.ProductVariant{}, &total, query); err != nil {
+		return false, err
+	}
+	return total > 0, nil
+}
+
+// AdjustStock changes the stock on hand of movement.VariantID by movement.Quantity and
+// records the movement in the stock ledger
+func (r *VariantRepo) AdjustStock(ctx context.Context, movement *model.StockMovement) (*model.VariantStock, error) {
+	var stock model.VariantStock
+	err := adjustStock(ctx, r.db, movement, &stock, "variant_id", movement.VariantID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &stock, nil
+}
@@ -0,0 +1,192 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+
+	"github.com/quangdangfit/gocommon/logger"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+
+	"goshop/internal/product/model"
+	"goshop/pkg/config"
+	"goshop/pkg/dbs"
+	"goshop/pkg/dbs/mocks"
+)
+
+type VariantRepositoryTestSuite struct {
+	suite.Suite
+	mockDB *mocks.IDatabase
+	repo   IVariantRepository
+}
+
+func (suite *VariantRepositoryTestSuite) SetupTest() {
+	logger.Initialize(config.ProductionEnv)
+
+	suite.mockDB = mocks.NewIDatabase(suite.T())
+	suite.repo = NewVariantRepository(suite.mockDB)
+}
+
+func TestVariantRepositoryTestSuite(t *testing.T) {
+	suite.Run(t, new(VariantRepositoryTestSuite))
+}
+
+// CreateOption
+// =================================================================
+
+func (suite *VariantRepositoryTestSuite) TestCreateOptionSuccessfully() {
+	option := &model.ProductOption{
+		ProductID: "productId1",
+		Name:      "Size",
+		Values:    []*model.ProductOptionValue{{Value: "S"}, {Value: "M", Position: 1}},
+	}
+	suite.mockDB.On("Create", mock.Anything, option).Return(nil).Times(1)
+
+	err := suite.repo.CreateOption(context.Background(), option)
+	suite.Nil(err)
+}
+
+func (suite *VariantRepositoryTestSuite) TestCreateOptionValuesSuccessfully() {
+	values := []*model.ProductOptionValue{{OptionID: "optionId1", Value: "L", Position: 2}}
+	suite.mockDB.On("CreateInBatches", mock.Anything, values, 1).Return(nil).Times(1)
+
+	err := suite.repo.CreateOptionValues(context.Background(), values)
+	suite.Nil(err)
+}
+
+// CreateVariant
+// =================================================================
+
+func (suite *VariantRepositoryTestSuite) TestCreateVariantSuccessfully() {
+	variant := &model.ProductVariant{ProductID: "productId1", SKU: "TEE-S"}
+	suite.mockDB.On("Create", mock.Anything, variant).Return(nil).Times(1)
+
+	err := suite.repo.CreateVariant(context.Background(), variant)
+	suite.Nil(err)
+}
+
+func (suite *VariantRepositoryTestSuite) TestCreateVariantFail() {
+	variant := &model.ProductVariant{ProductID: "productId1", SKU: "TEE-S"}
+	suite.mockDB.On("Create", mock.Anything, variant).Return(errors.New("error")).Times(1)
+
+	err := suite.repo.CreateVariant(context.Background(), variant)
+	suite.NotNil(err)
+}
+
+// UpdateVariant
+// =================================================================
+
+func (suite *VariantRepositoryTestSuite) TestUpdateVariantSuccessfully() {
+	variant := &model.ProductVariant{ID: "variantId1", ProductID: "productId1", SKU: "TEE-S"}
+	suite.mockDB.On("Update", mock.Anything, variant).Return(nil).Times(1)
+
+	err := suite.repo.UpdateVariant(context.Background(), variant)
+	suite.Nil(err)
+}
+
+// GetVariantByID
+// =================================================================
+
+func (suite *VariantRepositoryTestSuite) TestGetVariantByIDSuccessfully() {
+	suite.mockDB.On("FindOne", mock.Anything, &model.ProductVariant{}, mock.Anything, mock.Anything).
+		Return(nil).Times(1)
+
+	variant, err := suite.repo.GetVariantByID(context.Background(), "productId1", "variantId1")
+	suite.Nil(err)
+	suite.NotNil(variant)
+}
+
+func (suite *VariantRepositoryTestSuite) TestGetVariantByIDFail() {
+	suite.mockDB.On("FindOne", mock.Anything, &model.ProductVariant{}, mock.Anything, mock.Anything).
+		Return(errors.New("error")).Times(1)
+
+	variant, err := suite.repo.GetVariantByID(context.Background(), "productId1", "variantId1")
+	suite.NotNil(err)
+	suite.Nil(variant)
+}
+
+// SKUExists
+// =================================================================
+
+func (suite *VariantRepositoryTestSuite) TestSKUExists() {
+	suite.mockDB.On("Count", mock.Anything, &model.ProductVariant{}, mock.Anything, mock.Anything).
+		Return(func(ctx context.Context, model any, total *int64, opts ...dbs.FindOption) error {
+			*total = 1
+			return nil
+		}).Times(1)
+
+	exists, err := suite.repo.SKUExists(context.Background(), "TEE-S", "")
+	suite.Nil(err)
+	suite.True(exists)
+}
+
+func (suite *VariantRepositoryTestSuite) TestSKUExistsNot() {
+	suite.mockDB.On("Count", mock.Anything, &model.ProductVariant{}, mock.Anything, mock.Anything).
+		Return(nil).Times(1)
+
+	exists, err := suite.repo.SKUExists(context.Background(), "TEE-S", "variantId1")
+	suite.Nil(err)
+	suite.False(exists)
+}
+
+func (suite *VariantRepositoryTestSuite) TestSKUExistsFail() {
+	suite.mockDB.On("Count", mock.Anything, &model.ProductVariant{}, mock.Anything, mock.Anything).
+		Return(sql.ErrConnDone).Times(1)
+
+	exists, err := suite.repo.SKUExists(context.Background(), "TEE-S", "")
+	suite.ErrorIs(err, sql.ErrConnDone)
+	suite.False(exists)
+}
+
+// AdjustStock
+// =================================================================
+
+func (suite *VariantRepositoryTestSuite) expectTransaction() {
+	suite.mockDB.On("WithTransaction", mock.Anything, mock.Anything).
+		Return(func(ctx context.Context, function func(ctx context.Context) error) error {
+			return function(ctx)
+		}).Times(1)
+}
+
+func (suite *VariantRepositoryTestSuite) TestAdjustStockSuccessfully() {
+	movement := &model.StockMovement{
+		ProductID: "productId1",
+		VariantID: "variantId1",
+		Reason:    model.StockReasonRestock,
+		Quantity:  10,
+	}
+
+	suite.expectTransaction()
+	suite.mockDB.On("UpdateColumns", mock.Anything, &model.VariantStock{}, mock.Anything, mock.Anything).
+		Return(int64(1), nil).Times(1)
+	suite.mockDB.On("Create", mock.Anything, movement).Return(nil).Times(1)
+	suite.mockDB.On(
+		"FindOne",
+		mock.Anything,
+		&model.VariantStock{},
+		dbs.WithQuery(dbs.NewQuery("variant_id = ?", "variantId1")),
+		dbs.WithOrder("variant_id"),
+	).
+		Return(nil).Times(1)
+
+	stock, err := suite.repo.AdjustStock(context.Background(), movement)
+	suite.Nil(err)
+	suite.NotNil(stock)
+	suite.Equal(model.StockMovementAdjustment, movement.Type)
+}
+
+func (suite *VariantRepositoryTestSuite) TestAdjustStockBelowReserved() {
+	suite.expectTransaction()
+	suite.mockDB.On("UpdateColumns", mock.Anything, &model.VariantStock{}, mock.Anything, mock.Anything).
+		Return(int64(0), nil).Times(1)
+
+	stock, err := suite.repo.AdjustStock(context.Background(), &model.StockMovement{
+		ProductID: "productId1",
+		VariantID: "variantId1",
+		Quantity:  -10,
+	})
+	suite.ErrorIs(err, ErrStockBelowReserved)
+	suite.Nil(stock)
+}
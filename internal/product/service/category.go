@@ -0,0 +1,260 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/quangdangfit/gocommon/logger"
+	"github.com/quangdangfit/gocommon/validation"
+
+	"goshop/internal/product/dto"
+	"goshop/internal/product/model"
+	"goshop/internal/product/repository"
+	"goshop/pkg/dbs"
+	"goshop/pkg/paging"
+	"goshop/pkg/utils"
+)
+
+var (
+	ErrInvalidSlug         = errors.New("slug must contain a letter or a digit")
+	ErrSlugTaken           = errors.New("slug is used by another category")
+	ErrParentNotFound      = errors.New("parent category not found")
+	ErrCategoryCycle       = errors.New("category cannot be moved under itself or its descendants")
+	ErrCategoryHasChildren = errors.New("category has subcategories")
+)
+
+//go:generate mockery --name=ICategoryService
+type ICategoryService interface {
+	ListCategories(ctx context.Context) ([]*model.Category, error)
+	GetCategoryBySlug(ctx context.Context, slug string) (*model.Category, error)
+	ListProducts(ctx context.Context, slug string, req *dto.ListProductReq) ([]*model.Product, *paging.Pagination, error)
+	Create(ctx context.Context, req *dto.CreateCategoryReq) (*model.Category, error)
+	Update(ctx context.Context, id string, req *dto.UpdateCategoryReq) (*model.Category, error)
+	Delete(ctx context.Context, id string) error
+}
+
+type CategoryService struct {
+	validator   validation.Validation
+	db          dbs.IDatabase
+	repo        repository.ICategoryRepository
+	productRepo repository.IProductRepository
+}
+
+func NewCategoryService(
+	validator validation.Validation,
+	db dbs.IDatabase,
+	repo repository.ICategoryRepository,
+	productRepo repository.IProductRepository,
+) *CategoryService {
+	return &CategoryService{
+		validator:   validator,
+		db:          db,
+		repo:        repo,
+		productRepo: productRepo,
+	}
+}
+
+// ListCategories returns the category tree, the top level categories holding their children
+func (s *CategoryService) ListCategories(ctx context.Context) ([]*model.Category, error) {
+	categories, err := s.repo.ListCategories(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	nodes := make(map[string]*model.Category, len(categories))
+	for _, category := range categories {
+		nodes[category.ID] = category
+	}
+
+	roots := make([]*model.Category, 0)
+	for _, category := range categories {
+		if category.ParentID == nil || nodes[*category.ParentID] == nil {
+			roots = append(roots, category)
+			continue
+		}
+		parent := nodes[*category.ParentID]
+		parent.Children = append(parent.Children, category)
+	}
+
+	return roots, nil
+}
+
+func (s *CategoryService) GetCategoryBySlug(ctx context.Context, slug string) (*model.Category, error) {
+	category, err := s.repo.GetCategoryBySlug(ctx, slug)
+	if err != nil {
+		return nil, err
+	}
+
+	return category, nil
+}
+
+// ListProducts lists the products of the category of slug and of its descendants
+func (s *CategoryService) ListProducts(ctx context.Context, slug string, req *dto.ListProductReq) ([]*model.Product, *paging.Pagination, error) {
+	category, err := s.repo.GetCategoryBySlug(ctx, slug)
+	if err != nil {
+		return nil, nil, fmt.Errorf("%w: %s", repository.ErrCategoryNotFound, slug)
+	}
+
+	req.Category = category.Slug
+	req.IncludeSubcategories = true
+	if err := validateFilters(s.validator, req); err != nil {
+		return nil, nil, err
+	}
+	products, pagination, err := s.productRepo.ListProducts(ctx, req)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return products, pagination, nil
+}
+
+func (s *CategoryService) Create(ctx context.Context, req *dto.CreateCategoryReq) (*model.Category, error) {
+	if err := s.validator.ValidateStruct(req); err != nil {
+		return nil, err
+	}
+
+	category := &model.Category{
+		Name:     req.Name,
+		Slug:     req.Slug,
+		Position: req.Position,
+	}
+	if category.Slug == "" {
+		category.Slug = category.Name
+	}
+	if err := s.setSlug(ctx, category, category.Slug); err != nil {
+		return nil, err
+	}
+	if err := s.setParent(ctx, category, req.ParentID); err != nil {
+		return nil, err
+	}
+
+	if err := s.repo.Create(ctx, category); err != nil {
+		logger.Errorf("Create fail, error: %s", err)
+		return nil, err
+	}
+
+	return category, nil
+}
+
+func (s *CategoryService) Update(ctx context.Context, id string, req *dto.UpdateCategoryReq) (*model.Category, error) {
+	if err := s.validator.ValidateStruct(req); err != nil {
+		return nil, err
+	}
+
+	var category *model.Category
+	err := s.db.WithTransaction(ctx, func(ctx context.Context) error {
+		// A move is checked against the descendants of the category, which concurrent
+		// moves would change: moves happen one at a time
+		if req.ParentID != nil {
+			if err := s.repo.LockTree(ctx); err != nil {
+				return err
+			}
+		}
+
+		var err error
+		category, err = s.repo.GetCategoryByID(ctx, id)
+		if err != nil {
+			logger.Errorf("Update.GetCategoryByID fail, id: %s, error: %s", id, err)
+			return fmt.Errorf("%w: %s", repository.ErrCategoryNotFound, id)
+		}
+
+		if req.Name != "" {
+			category.Name = req.Name
+		}
+		if req.Position != nil {
+			category.Position = *req.Position
+		}
+		if req.Slug != "" {
+			if err := s.setSlug(ctx, category, req.Slug); err != nil {
+				return err
+			}
+		}
+		if req.ParentID != nil {
+			if err := s.setParent(ctx, category, req.ParentID); err != nil {
+				return err
+			}
+		}
+
+		if err := s.repo.Update(ctx, category); err != nil {
+			logger.Errorf("Update fail, id: %s, error: %s", id, err)
+			return err
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return category, nil
+}
+
+// Delete deletes a category without subcategories, its products are only unassigned from it
+func (s *CategoryService) Delete(ctx context.Context, id string) error {
+	category, err := s.repo.GetCategoryByID(ctx, id)
+	if err != nil {
+		return fmt.Errorf("%w: %s", repository.ErrCategoryNotFound, id)
+	}
+
+	children, err := s.repo.CountChildren(ctx, id)
+	if err != nil {
+		return err
+	}
+	if children > 0 {
+		return ErrCategoryHasChildren
+	}
+
+	if err := s.repo.Delete(ctx, category); err != nil {
+		logger.Errorf("Delete fail, id: %s, error: %s", id, err)
+		return err
+	}
+
+	return nil
+}
+
+func (s *CategoryService) setSlug(ctx context.Context, category *model.Category, text string) error {
+	slug := utils.Slugify(text)
+	if slug == "" {
+		return ErrInvalidSlug
+	}
+
+	exists, err := s.repo.SlugExists(ctx, slug, category.ID)
+	if err != nil {
+		return err
+	}
+	if exists {
+		return fmt.Errorf("%w: %s", ErrSlugTaken, slug)
+	}
+
+	category.Slug = slug
+	return nil
+}
+
+// setParent moves category under parentID, to the top level when it is empty
+func (s *CategoryService) setParent(ctx context.Context, category *model.Category, parentID *string) error {
+	if parentID == nil || *parentID == "" {
+		category.ParentID = nil
+		return nil
+	}
+
+	if _, err := s.repo.GetCategoryByID(ctx, *parentID); err != nil {
+		return fmt.Errorf("%w: %s", ErrParentNotFound, *parentID)
+	}
+
+	// A new category has no descendants yet
+	if category.ID != "" {
+		descendants, err := s.repo.GetDescendantIDs(ctx, category.ID)
+		if err != nil {
+			return err
+		}
+		for _, id := range descendants {
+			if id == *parentID {
+				return ErrCategoryCycle
+			}
+		}
+	}
+
+	category.ParentID = parentID
+	return nil
+}
@@ -0,0 +1,302 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/quangdangfit/gocommon/logger"
+	"github.com/quangdangfit/gocommon/validation"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+
+	"goshop/internal/product/dto"
+	"goshop/internal/product/model"
+	"goshop/internal/product/repository"
+	"goshop/internal/product/repository/mocks"
+	"goshop/pkg/config"
+	dbMocks "goshop/pkg/dbs/mocks"
+	"goshop/pkg/paging"
+)
+
+type CategoryServiceTestSuite struct {
+	suite.Suite
+	mockDB          *dbMocks.IDatabase
+	mockRepo        *mocks.ICategoryRepository
+	mockProductRepo *mocks.IProductRepository
+	service         ICategoryService
+}
+
+func (suite *CategoryServiceTestSuite) SetupTest() {
+	logger.Initialize(config.ProductionEnv)
+
+	validator := validation.New()
+	suite.mockDB = dbMocks.NewIDatabase(suite.T())
+	suite.mockRepo = mocks.NewICategoryRepository(suite.T())
+	suite.mockProductRepo = mocks.NewIProductRepository(suite.T())
+	suite.service = NewCategoryService(validator, suite.mockDB, suite.mockRepo, suite.mockProductRepo)
+}
+
+func (suite *CategoryServiceTestSuite) expectTransaction() {
+	suite.mockDB.On("WithTransaction", mock.Anything, mock.Anything).
+		Return(func(ctx context.Context, function func(ctx context.Context) error) error {
+			return function(ctx)
+		}).Times(1)
+}
+
+func TestCategoryServiceTestSuite(t *testing.T) {
+	suite.Run(t, new(CategoryServiceTestSuite))
+}
+
+func strPtr(s string) *string {
+	return &s
+}
+
+// ListCategories
+// =================================================================
+
+func (suite *CategoryServiceTestSuite) TestListCategoriesBuildsTree() {
+	suite.mockRepo.On("ListCategories", mock.Anything).
+		Return([]*model.Category{
+			{ID: "shoes", Name: "Shoes"},
+			{ID: "boots", Name: "Boots", ParentID: strPtr("shoes")},
+			{ID: "hiking", Name: "Hiking", ParentID: strPtr("boots")},
+			{ID: "hats", Name: "Hats"},
+			{ID: "sneakers", Name: "Sneakers", ParentID: strPtr("shoes")},
+		}, nil).Times(1)
+
+	categories, err := suite.service.ListCategories(context.Background())
+	suite.Nil(err)
+	suite.Equal(2, len(categories))
+	suite.Equal("shoes", categories[0].ID)
+	suite.Equal("hats", categories[1].ID)
+	suite.Equal(2, len(categories[0].Children))
+	suite.Equal("boots", categories[0].Children[0].ID)
+	suite.Equal("sneakers", categories[0].Children[1].ID)
+	suite.Equal("hiking", categories[0].Children[0].Children[0].ID)
+}
+
+func (suite *CategoryServiceTestSuite) TestListCategoriesFail() {
+	suite.mockRepo.On("ListCategories", mock.Anything).
+		Return(nil, errors.New("error")).Times(1)
+
+	categories, err := suite.service.ListCategories(context.Background())
+	suite.NotNil(err)
+	suite.Nil(categories)
+}
+
+// GetCategoryBySlug
+// =================================================================
+
+func (suite *CategoryServiceTestSuite) TestGetCategoryBySlugSuccess() {
+	suite.mockRepo.On("GetCategoryBySlug", mock.Anything, "shoes").
+		Return(&model.Category{ID: "shoes", Slug: "shoes"}, nil).Times(1)
+
+	category, err := suite.service.GetCategoryBySlug(context.Background(), "shoes")
+	suite.Nil(err)
+	suite.Equal("shoes", category.Slug)
+}
+
+func (suite *CategoryServiceTestSuite) TestGetCategoryBySlugFail() {
+	suite.mockRepo.On("GetCategoryBySlug", mock.Anything, "shoes").
+		Return(nil, errors.New("error")).Times(1)
+
+	category, err := suite.service.GetCategoryBySlug(context.Background(), "shoes")
+	suite.NotNil(err)
+	suite.Nil(category)
+}
+
+// ListProducts
+// =================================================================
+
+func (suite *CategoryServiceTestSuite) TestListProductsIncludesSubcategories() {
+	req := &dto.ListProductReq{}
+	suite.mockRepo.On("GetCategoryBySlug", mock.Anything, "shoes").
+		Return(&model.Category{ID: "shoes", Slug: "shoes"}, nil).Times(1)
+	suite.mockProductRepo.On("ListProducts", mock.Anything, &dto.ListProductReq{Category: "shoes", IncludeSubcategories: true}).
+		Return([]*model.Product{{ID: "productId"}}, &paging.Pagination{Total: 1}, nil).Times(1)
+
+	products, pagination, err := suite.service.ListProducts(context.Background(), "shoes", req)
+	suite.Nil(err)
+	suite.Equal(1, len(products))
+	suite.NotNil(pagination)
+}
+
+func (suite *CategoryServiceTestSuite) TestListProductsCategoryNotFound() {
+	suite.mockRepo.On("GetCategoryBySlug", mock.Anything, "shoes").
+		Return(nil, errors.New("error")).Times(1)
+
+	products, pagination, err := suite.service.ListProducts(context.Background(), "shoes", &dto.ListProductReq{})
+	suite.ErrorIs(err, repository.ErrCategoryNotFound)
+	suite.Nil(products)
+	suite.Nil(pagination)
+}
+
+// Create
+// =================================================================
+
+func (suite *CategoryServiceTestSuite) TestCreateSlugFromName() {
+	req := &dto.CreateCategoryReq{Name: "Running Shoes", ParentID: strPtr("shoes")}
+	suite.mockRepo.On("SlugExists", mock.Anything, "running-shoes", "").Return(false, nil).Times(1)
+	suite.mockRepo.On("GetCategoryByID", mock.Anything, "shoes").
+		Return(&model.Category{ID: "shoes"}, nil).Times(1)
+	suite.mockRepo.On("Create", mock.Anything, mock.Anything).Return(nil).Times(1)
+
+	category, err := suite.service.Create(context.Background(), req)
+	suite.Nil(err)
+	suite.Equal("running-shoes", category.Slug)
+	suite.Equal("shoes", *category.ParentID)
+}
+
+func (suite *CategoryServiceTestSuite) TestCreateInvalidRequest() {
+	category, err := suite.service.Create(context.Background(), &dto.CreateCategoryReq{})
+	suite.NotNil(err)
+	suite.Nil(category)
+}
+
+func (suite *CategoryServiceTestSuite) TestCreateInvalidSlug() {
+	category, err := suite.service.Create(context.Background(), &dto.CreateCategoryReq{Name: "Shoes", Slug: "--"})
+	suite.ErrorIs(err, ErrInvalidSlug)
+	suite.Nil(category)
+}
+
+func (suite *CategoryServiceTestSuite) TestCreateSlugTaken() {
+	suite.mockRepo.On("SlugExists", mock.Anything, "shoes", "").Return(true, nil).Times(1)
+
+	category, err := suite.service.Create(context.Background(), &dto.CreateCategoryReq{Name: "Shoes"})
+	suite.ErrorIs(err, ErrSlugTaken)
+	suite.Nil(category)
+}
+
+func (suite *CategoryServiceTestSuite) TestCreateParentNotFound() {
+	suite.mockRepo.On("SlugExists", mock.Anything, "boots", "").Return(false, nil).Times(1)
+	suite.mockRepo.On("GetCategoryByID", mock.Anything, "shoes").
+		Return(nil, errors.New("error")).Times(1)
+
+	category, err := suite.service.Create(context.Background(), &dto.CreateCategoryReq{Name: "Boots", ParentID: strPtr("shoes")})
+	suite.ErrorIs(err, ErrParentNotFound)
+	suite.Nil(category)
+}
+
+func (suite *CategoryServiceTestSuite) TestCreateFail() {
+	suite.mockRepo.On("SlugExists", mock.Anything, "shoes", "").Return(false, nil).Times(1)
+	suite.mockRepo.On("Create", mock.Anything, mock.Anything).Return(errors.New("error")).Times(1)
+
+	category, err := suite.service.Create(context.Background(), &dto.CreateCategoryReq{Name: "Shoes"})
+	suite.NotNil(err)
+	suite.Nil(category)
+}
+
+// Update
+// =================================================================
+
+func (suite *CategoryServiceTestSuite) TestUpdateSuccess() {
+	position := 2
+	req := &dto.UpdateCategoryReq{
+		Name:     "Boots",
+		Slug:     "Boots",
+		Position: &position,
+		ParentID: strPtr(""),
+	}
+	suite.expectTransaction()
+	suite.mockRepo.On("LockTree", mock.Anything).Return(nil).Times(1)
+	suite.mockRepo.On("GetCategoryByID", mock.Anything, "boots").
+		Return(&model.Category{ID: "boots", Name: "Old", Slug: "old", ParentID: strPtr("shoes")}, nil).Times(1)
+	suite.mockRepo.On("SlugExists", mock.Anything, "boots", "boots").Return(false, nil).Times(1)
+	suite.mockRepo.On("Update", mock.Anything, mock.Anything).Return(nil).Times(1)
+
+	category, err := suite.service.Update(context.Background(), "boots", req)
+	suite.Nil(err)
+	suite.Equal("Boots", category.Name)
+	suite.Equal("boots", category.Slug)
+	suite.Equal(2, category.Position)
+	suite.Nil(category.ParentID)
+}
+
+func (suite *CategoryServiceTestSuite) TestUpdateMoveUnderDescendant() {
+	suite.expectTransaction()
+	suite.mockRepo.On("LockTree", mock.Anything).Return(nil).Times(1)
+	suite.mockRepo.On("GetCategoryByID", mock.Anything, "shoes").
+		Return(&model.Category{ID: "shoes"}, nil).Times(1)
+	suite.mockRepo.On("GetCategoryByID", mock.Anything, "hiking").
+		Return(&model.Category{ID: "hiking"}, nil).Times(1)
+	suite.mockRepo.On("GetDescendantIDs", mock.Anything, "shoes").
+		Return([]string{"shoes", "boots", "hiking"}, nil).Times(1)
+
+	category, err := suite.service.Update(context.Background(), "shoes", &dto.UpdateCategoryReq{ParentID: strPtr("hiking")})
+	suite.ErrorIs(err, ErrCategoryCycle)
+	suite.Nil(category)
+}
+
+func (suite *CategoryServiceTestSuite) TestUpdateMoveUnderItself() {
+	suite.expectTransaction()
+	suite.mockRepo.On("LockTree", mock.Anything).Return(nil).Times(1)
+	suite.mockRepo.On("GetCategoryByID", mock.Anything, "shoes").
+		Return(&model.Category{ID: "shoes"}, nil).Times(2)
+	suite.mockRepo.On("GetDescendantIDs", mock.Anything, "shoes").
+		Return([]string{"shoes"}, nil).Times(1)
+
+	category, err := suite.service.Update(context.Background(), "shoes", &dto.UpdateCategoryReq{ParentID: strPtr("shoes")})
+	suite.ErrorIs(err, ErrCategoryCycle)
+	suite.Nil(category)
+}
+
+func (suite *CategoryServiceTestSuite) TestUpdateCategoryNotFound() {
+	suite.expectTransaction()
+	suite.mockRepo.On("GetCategoryByID", mock.Anything, "shoes").
+		Return(nil, errors.New("error")).Times(1)
+
+	category, err := suite.service.Update(context.Background(), "shoes", &dto.UpdateCategoryReq{Name: "Shoes"})
+	suite.ErrorIs(err, repository.ErrCategoryNotFound)
+	suite.Nil(category)
+}
+
+func (suite *CategoryServiceTestSuite) TestUpdateLockTreeFail() {
+	suite.expectTransaction()
+	suite.mockRepo.On("LockTree", mock.Anything).Return(errors.New("error")).Times(1)
+
+	category, err := suite.service.Update(context.Background(), "shoes", &dto.UpdateCategoryReq{ParentID: strPtr("hats")})
+	suite.NotNil(err)
+	suite.Nil(category)
+}
+
+func (suite *CategoryServiceTestSuite) TestUpdateFail() {
+	suite.expectTransaction()
+	suite.mockRepo.On("GetCategoryByID", mock.Anything, "shoes").
+		Return(&model.Category{ID: "shoes"}, nil).Times(1)
+	suite.mockRepo.On("Update", mock.Anything, mock.Anything).Return(errors.New("error")).Times(1)
+
+	category, err := suite.service.Update(context.Background(), "shoes", &dto.UpdateCategoryReq{Name: "Shoes"})
+	suite.NotNil(err)
+	suite.Nil(category)
+}
+
+// Delete
+// =================================================================
+
+func (suite *CategoryServiceTestSuite) TestDeleteSuccess() {
+	category := &model.Category{ID: "boots"}
+	suite.mockRepo.On("GetCategoryByID", mock.Anything, "boots").Return(category, nil).Times(1)
+	suite.mockRepo.On("CountChildren", mock.Anything, "boots").Return(int64(0), nil).Times(1)
+	suite.mockRepo.On("Delete", mock.Anything, category).Return(nil).Times(1)
+
+	err := suite.service.Delete(context.Background(), "boots")
+	suite.Nil(err)
+}
+
+func (suite *CategoryServiceTestSuite) TestDeleteHasChildren() {
+	suite.mockRepo.On("GetCategoryByID", mock.Anything, "shoes").
+		Return(&model.Category{ID: "shoes"}, nil).Times(1)
+	suite.mockRepo.On("CountChildren", mock.Anything, "shoes").Return(int64(2), nil).Times(1)
+
+	err := suite.service.Delete(context.Background(), "shoes")
+	suite.ErrorIs(err, ErrCategoryHasChildren)
+}
+
+func (suite *CategoryServiceTestSuite) TestDeleteCategoryNotFound() {
+	suite.mockRepo.On("GetCategoryByID", mock.Anything, "shoes").
+		Return(nil, errors.New("error")).Times(1)
+
+	err := suite.service.Delete(context.Background(), "shoes")
+	suite.ErrorIs(err, repository.ErrCategoryNotFound)
+}
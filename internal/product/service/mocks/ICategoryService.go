@@ -0,0 +1,186 @@
+// Code generated by mockery v2.32.4. DO NOT EDIT.
+
+package mocks
+
+import (
+	context "context"
+	dto "goshop/internal/product/dto"
+
+	mock "github.com/stretchr/testify/mock"
+
+	model "goshop/internal/product/model"
+
+	paging "goshop/pkg/paging"
+)
+
+// ICategoryService is an autogenerated mock type for the ICategoryService type
+type ICategoryService struct {
+	mock.Mock
+}
+
+// Create provides a mock function with given fields: ctx, req
+func (_m *ICategoryService) Create(ctx context.Context, req *dto.CreateCategoryReq) (*model.Category, error) {
+	ret := _m.Called(ctx, req)
+
+	var r0 *model.Category
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, *dto.CreateCategoryReq) (*model.Category, error)); ok {
+		return rf(ctx, req)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, *dto.CreateCategoryReq) *model.Category); ok {
+		r0 = rf(ctx, req)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*model.Category)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, *dto.CreateCategoryReq) error); ok {
+		r1 = rf(ctx, req)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Delete provides a mock function with given fields: ctx, id
+func (_m *ICategoryService) Delete(ctx context.Context, id string) error {
+	ret := _m.Called(ctx, id)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) error); ok {
+		r0 = rf(ctx, id)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// GetCategoryBySlug provides a mock function with given fields: ctx, slug
+func (_m *ICategoryService) GetCategoryBySlug(ctx context.Context, slug string) (*model.Category, error) {
+	ret := _m.Called(ctx, slug)
+
+	var r0 *model.Category
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) (*model.Category, error)); ok {
+		return rf(ctx, slug)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string) *model.Category); ok {
+		r0 = rf(ctx, slug)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*model.Category)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, slug)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// ListCategories provides a mock function with given fields: ctx
+func (_m *ICategoryService) ListCategories(ctx context.Context) ([]*model.Category, error) {
+	ret := _m.Called(ctx)
+
+	var r0 []*model.Category
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context) ([]*model.Category, error)); ok {
+		return rf(ctx)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context) []*model.Category); ok {
+		r0 = rf(ctx)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*model.Category)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context) error); ok {
+		r1 = rf(ctx)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// ListProducts provides a mock function with given fields: ctx, slug, req
+func (_m *ICategoryService) ListProducts(ctx context.Context, slug string, req *dto.ListProductReq) ([]*model.Product, *paging.Pagination, error) {
+	ret := _m.Called(ctx, slug, req)
+
+	var r0 []*model.Product
+	var r1 *paging.Pagination
+	var r2 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, *dto.ListProductReq) ([]*model.Product, *paging.Pagination, error)); ok {
+		return rf(ctx, slug, req)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, *dto.ListProductReq) []*model.Product); ok {
+		r0 = rf(ctx, slug, req)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*model.Product)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, *dto.ListProductReq) *paging.Pagination); ok {
+		r1 = rf(ctx, slug, req)
+	} else {
+		if ret.Get(1) != nil {
+			r1 = ret.Get(1).(*paging.Pagination)
+		}
+	}
+
+	if rf, ok := ret.Get(2).(func(context.Context, string, *dto.ListProductReq) error); ok {
+		r2 = rf(ctx, slug, req)
+	} else {
+		r2 = ret.Error(2)
+	}
+
+	return r0, r1, r2
+}
+
+// Update provides a mock function with given fields: ctx, id, req
+func (_m *ICategoryService) Update(ctx context.Context, id string, req *dto.UpdateCategoryReq) (*model.Category, error) {
+	ret := _m.Called(ctx, id, req)
+
+	var r0 *model.Category
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, *dto.UpdateCategoryReq) (*model.Category, error)); ok {
+		return rf(ctx, id, req)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, *dto.UpdateCategoryReq) *model.Category); ok {
+		r0 = rf(ctx, id, req)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*model.Category)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, *dto.UpdateCategoryReq) error); ok {
+		r1 = rf(ctx, id, req)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// NewICategoryService creates a new instance of ICategoryService. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewICategoryService(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *ICategoryService {
+	mock := &ICategoryService{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
@@ -18,6 +18,32 @@ type IProductService struct {
 	mock.Mock
 }
 
+// AdjustStock provides a mock function with given fields: ctx, id, userID, req
+func (_m *IProductService) AdjustStock(ctx context.Context, id string, userID string, req *dto.AdjustStockReq) (*model.ProductStock, error) {
+	ret := _m.Called(ctx, id, userID, req)
+
+	var r0 *model.ProductStock
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, string, *dto.AdjustStockReq) (*model.ProductStock, error)); ok {
+		return rf(ctx, id, userID, req)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, string, *dto.AdjustStockReq) *model.ProductStock); ok {
+		r0 = rf(ctx, id, userID, req)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*model.ProductStock)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, string, *dto.AdjustStockReq) error); ok {
+		r1 = rf(ctx, id, userID, req)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // Create provides a mock function with given fields: ctx, req
 func (_m *IProductService) Create(ctx context.Context, req *dto.CreateProductReq) (*model.Product, error) {
 	ret := _m.Called(ctx, req)
@@ -71,13 +97,14 @@ func (_m *IProductService) GetProductByID(ctx context.Context, id string) (*mode
 }
 
 // ListProducts provides a mock function with given fields: c, req
-func (_m *IProductService) ListProducts(c context.Context, req *dto.ListProductReq) ([]*model.Product, *paging.Pagination, error) {
+func (_m *IProductService) ListProducts(c context.Context, req *dto.ListProductReq) ([]*model.Product, *model.ProductFacets, *paging.Pagination, error) {
 	ret := _m.Called(c, req)
 
 	var r0 []*model.Product
-	var r1 *paging.Pagination
-	var r2 error
-	if rf, ok := ret.Get(0).(func(context.Context, *dto.ListProductReq) ([]*model.Product, *paging.Pagination, error)); ok {
+	var r1 *model.ProductFacets
+	var r2 *paging.Pagination
+	var r3 error
+	if rf, ok := ret.Get(0).(func(context.Context, *dto.ListProductReq) ([]*model.Product, *model.ProductFacets, *paging.Pagination, error)); ok {
 		return rf(c, req)
 	}
 	if rf, ok := ret.Get(0).(func(context.Context, *dto.ListProductReq) []*model.Product); ok {
@@ -88,16 +115,59 @@ func (_m *IProductService) ListProducts(c context.Context, req *dto.ListProductR
 		}
 	}
 
-	if rf, ok := ret.Get(1).(func(context.Context, *dto.ListProductReq) *paging.Pagination); ok {
+	if rf, ok := ret.Get(1).(func(context.Context, *dto.ListProductReq) *model.ProductFacets); ok {
 		r1 = rf(c, req)
 	} else {
 		if ret.Get(1) != nil {
-			r1 = ret.Get(1).(*paging.Pagination)
+			r1 = ret.Get(1).(*model.ProductFacets)
 		}
 	}
 
-	if rf, ok := ret.Get(2).(func(context.Context, *dto.ListProductReq) error); ok {
+	if rf, ok := ret.Get(2).(func(context.Context, *dto.ListProductReq) *paging.Pagination); ok {
 		r2 = rf(c, req)
+	} else {
+		if ret.Get(2) != nil {
+			r2 = ret.Get(2).(*paging.Pagination)
+		}
+	}
+
+	if rf, ok := ret.Get(3).(func(context.Context, *dto.ListProductReq) error); ok {
+		r3 = rf(c, req)
+	} else {
+		r3 = ret.Error(3)
+	}
+
+	return r0, r1, r2, r3
+}
+
+// ListStockMovements provides a mock function with given fields: ctx, id, req
+func (_m *IProductService) ListStockMovements(ctx context.Context, id string, req *dto.ListStockMovementReq) ([]*model.StockMovement, *paging.Pagination, error) {
+	ret := _m.Called(ctx, id, req)
+
+	var r0 []*model.StockMovement
+	var r1 *paging.Pagination
+	var r2 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, *dto.ListStockMovementReq) ([]*model.StockMovement, *paging.Pagination, error)); ok {
+		return rf(ctx, id, req)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, *dto.ListStockMovementReq) []*model.StockMovement); ok {
+		r0 = rf(ctx, id, req)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*model.StockMovement)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, *dto.ListStockMovementReq) *paging.Pagination); ok {
+		r1 = rf(ctx, id, req)
+	} else {
+		if ret.Get(1) != nil {
+			r1 = ret.Get(1).(*paging.Pagination)
+		}
+	}
+
+	if rf, ok := ret.Get(2).(func(context.Context, string, *dto.ListStockMovementReq) error); ok {
+		r2 = rf(ctx, id, req)
 	} else {
 		r2 = ret.Error(2)
 	}
@@ -105,6 +175,67 @@ func (_m *IProductService) ListProducts(c context.Context, req *dto.ListProductR
 	return r0, r1, r2
 }
 
+// SearchProducts provides a mock function with given fields: ctx, req
+func (_m *IProductService) SearchProducts(ctx context.Context, req *dto.SearchProductReq) ([]*model.Product, *paging.Pagination, error) {
+	ret := _m.Called(ctx, req)
+
+	var r0 []*model.Product
+	var r1 *paging.Pagination
+	var r2 error
+	if rf, ok := ret.Get(0).(func(context.Context, *dto.SearchProductReq) ([]*model.Product, *paging.Pagination, error)); ok {
+		return rf(ctx, req)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, *dto.SearchProductReq) []*model.Product); ok {
+		r0 = rf(ctx, req)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*model.Product)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, *dto.SearchProductReq) *paging.Pagination); ok {
+		r1 = rf(ctx, req)
+	} else {
+		if ret.Get(1) != nil {
+			r1 = ret.Get(1).(*paging.Pagination)
+		}
+	}
+
+	if rf, ok := ret.Get(2).(func(context.Context, *dto.SearchProductReq) error); ok {
+		r2 = rf(ctx, req)
+	} else {
+		r2 = ret.Error(2)
+	}
+
+	return r0, r1, r2
+}
+
+// SetCategories provides a mock function with given fields: ctx, id, req
+func (_m *IProductService) SetCategories(ctx context.Context, id string, req *dto.SetProductCategoriesReq) (*model.Product, error) {
+	ret := _m.Called(ctx, id, req)
+
+	var r0 *model.Product
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, *dto.SetProductCategoriesReq) (*model.Product, error)); ok {
+		return rf(ctx, id, req)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, *dto.SetProductCategoriesReq) *model.Product); ok {
+		r0 = rf(ctx, id, req)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*model.Product)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, *dto.SetProductCategoriesReq) error); ok {
+		r1 = rf(ctx, id, req)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // Update provides a mock function with given fields: ctx, id, req
 func (_m *IProductService) Update(ctx context.Context, id string, req *dto.UpdateProductReq) (*model.Product, error) {
 	ret := _m.Called(ctx, id, req)
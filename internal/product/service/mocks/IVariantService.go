@@ -0,0 +1,135 @@
+// Code generated by mockery v2.32.4. DO NOT EDIT.
+
+package mocks
+
+import (
+	context "context"
+	dto "goshop/internal/product/dto"
+
+	mock "github.com/stretchr/testify/mock"
+
+	model "goshop/internal/product/model"
+)
+
+// IVariantService is an autogenerated mock type for the IVariantService type
+type IVariantService struct {
+	mock.Mock
+}
+
+// AddOption provides a mock function with given fields: ctx, productID, req
+func (_m *IVariantService) AddOption(ctx context.Context, productID string, req *dto.CreateOptionReq) (*model.Product, error) {
+	ret := _m.Called(ctx, productID, req)
+
+	var r0 *model.Product
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, *dto.CreateOptionReq) (*model.Product, error)); ok {
+		return rf(ctx, productID, req)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, *dto.CreateOptionReq) *model.Product); ok {
+		r0 = rf(ctx, productID, req)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*model.Product)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, *dto.CreateOptionReq) error); ok {
+		r1 = rf(ctx, productID, req)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// AdjustStock provides a mock function with given fields: ctx, productID, id, userID, req
+func (_m *IVariantService) AdjustStock(ctx context.Context, productID string, id string, userID string, req *dto.AdjustStockReq) (*model.VariantStock, error) {
+	ret := _m.Called(ctx, productID, id, userID, req)
+
+	var r0 *model.VariantStock
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, string, string, *dto.AdjustStockReq) (*model.VariantStock, error)); ok {
+		return rf(ctx, productID, id, userID, req)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, string, string, *dto.AdjustStockReq) *model.VariantStock); ok {
+		r0 = rf(ctx, productID, id, userID, req)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*model.VariantStock)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, string, string, *dto.AdjustStockReq) error); ok {
+		r1 = rf(ctx, productID, id, userID, req)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// CreateVariant provides a mock function with given fields: ctx, productID, req
+func (_m *IVariantService) CreateVariant(ctx context.Context, productID string, req *dto.CreateVariantReq) (*model.ProductVariant, error) {
+	ret := _m.Called(ctx, productID, req)
+
+	var r0 *model.ProductVariant
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, *dto.CreateVariantReq) (*model.ProductVariant, error)); ok {
+		return rf(ctx, productID, req)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, *dto.CreateVariantReq) *model.ProductVariant); ok {
+		r0 = rf(ctx, productID, req)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*model.ProductVariant)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, *dto.CreateVariantReq) error); ok {
+		r1 = rf(ctx, productID, req)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// UpdateVariant provides a mock function with given fields: ctx, productID, id, req
+func (_m *IVariantService) UpdateVariant(ctx context.Context, productID string, id string, req *dto.UpdateVariantReq) (*model.ProductVariant, error) {
+	ret := _m.Called(ctx, productID, id, req)
+
+	var r0 *model.ProductVariant
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, string, *dto.UpdateVariantReq) (*model.ProductVariant, error)); ok {
+		return rf(ctx, productID, id, req)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, string, *dto.UpdateVariantReq) *model.ProductVariant); ok {
+		r0 = rf(ctx, productID, id, req)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*model.ProductVariant)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, string, *dto.UpdateVariantReq) error); ok {
+		r1 = rf(ctx, productID, id, req)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// NewIVariantService creates a new instance of IVariantService. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewIVariantService(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *IVariantService {
+	mock := &IVariantService{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
@@ -2,6 +2,8 @@ package service
 
 import (
 	"context"
+	"errors"
+	"fmt"
 
 	"github.com/quangdangfit/gocommon/logger"
 	"github.com/quangdangfit/gocommon/validation"
@@ -9,30 +11,43 @@ import (
 	"goshop/internal/product/dto"
 	"goshop/internal/product/model"
 	"goshop/internal/product/repository"
+	"goshop/pkg/money"
 	"goshop/pkg/paging"
 	"goshop/pkg/utils"
 )
 
+var (
+	ErrInvalidPrice  = errors.New("price must be greater than 0 in a known currency")
+	ErrInvalidFilter = errors.New("invalid filter")
+)
+
 //go:generate mockery --name=IProductService
 type IProductService interface {
-	ListProducts(c context.Context, req *dto.ListProductReq) ([]*model.Product, *paging.Pagination, error)
+	ListProducts(c context.Context, req *dto.ListProductReq) ([]*model.Product, *model.ProductFacets, *paging.Pagination, error)
+	SearchProducts(ctx context.Context, req *dto.SearchProductReq) ([]*model.Product, *paging.Pagination, error)
 	GetProductByID(ctx context.Context, id string) (*model.Product, error)
 	Create(ctx context.Context, req *dto.CreateProductReq) (*model.Product, error)
 	Update(ctx context.Context, id string, req *dto.UpdateProductReq) (*model.Product, error)
+	AdjustStock(ctx context.Context, id, userID string, req *dto.AdjustStockReq) (*model.ProductStock, error)
+	ListStockMovements(ctx context.Context, id string, req *dto.ListStockMovementReq) ([]*model.StockMovement, *paging.Pagination, error)
+	SetCategories(ctx context.Context, id string, req *dto.SetProductCategoriesReq) (*model.Product, error)
 }
 
 type ProductService struct {
-	validator validation.Validation
-	repo      repository.IProductRepository
+	validator   validation.Validation
+	repo        repository.IProductRepository
+	variantRepo repository.IVariantRepository
 }
 
 func NewProductService(
 	validator validation.Validation,
 	repo repository.IProductRepository,
+	variantRepo repository.IVariantRepository,
 ) *ProductService {
 	return &ProductService{
-		validator: validator,
-		repo:      repo,
+		validator:   validator,
+		repo:        repo,
+		variantRepo: variantRepo,
 	}
 }
 
@@ -45,8 +60,49 @@ func (p *ProductService) GetProductByID(ctx context.Context, id string) (*model.
 	return product, nil
 }
 
-func (p *ProductService) ListProducts(ctx context.Context, req *dto.ListProductReq) ([]*model.Product, *paging.Pagination, error) {
+// ListProducts lists the products matching the filters of req along with their facets
+func (p *ProductService) ListProducts(ctx context.Context, req *dto.ListProductReq) ([]*model.Product, *model.ProductFacets, *paging.Pagination, error) {
+	if err := validateFilters(p.validator, req); err != nil {
+		return nil, nil, nil, err
+	}
+
 	products, pagination, err := p.repo.ListProducts(ctx, req)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	facets, err := p.repo.ListProductFacets(ctx, req)
+	if err != nil {
+		logger.Errorf("ListProducts.ListProductFacets fail, error: %s", err)
+		return nil, nil, nil, err
+	}
+
+	return products, facets, pagination, nil
+}
+
+// validateFilters checks the filters of req, failing with ErrInvalidFilter
+func validateFilters(validator validation.Validation, req *dto.ListProductReq) error {
+	if err := validator.ValidateStruct(req); err != nil {
+		return fmt.Errorf("%w: %s", ErrInvalidFilter, err)
+	}
+	for _, price := range []string{req.MinPrice, req.MaxPrice} {
+		if price == "" {
+			continue
+		}
+		if _, err := money.Parse(price, req.Currency); err != nil {
+			return fmt.Errorf("%w: %s", ErrInvalidFilter, err)
+		}
+	}
+
+	return nil
+}
+
+func (p *ProductService) SearchProducts(ctx context.Context, req *dto.SearchProductReq) ([]*model.Product, *paging.Pagination, error) {
+	if err := p.validator.ValidateStruct(req); err != nil {
+		return nil, nil, err
+	}
+
+	products, pagination, err := p.repo.SearchProducts(ctx, req)
 	if err != nil {
 		return nil, nil, err
 	}
@@ -58,9 +114,18 @@ func (p *ProductService) Create(ctx context.Context, req *dto.CreateProductReq)
 	if err := p.validator.ValidateStruct(req); err != nil {
 		return nil, err
 	}
+	if _, err := money.Exponent(req.Price.Currency); err != nil || req.Price.Amount <= 0 {
+		return nil, ErrInvalidPrice
+	}
 
-	var product model.Product
-	utils.Copy(&product, req)
+	product := model.Product{
+		Name:        req.Name,
+		Description: req.Description,
+		Price:       req.Price,
+	}
+	if err := p.newVariants(ctx, &product, req); err != nil {
+		return nil, err
+	}
 
 	err := p.repo.Create(ctx, &product)
 	if err != nil {
@@ -68,9 +133,48 @@ func (p *ProductService) Create(ctx context.Context, req *dto.CreateProductReq)
 		return nil, err
 	}
 
+	for _, variant := range product.Variants {
+		variant.ResolvePrice(&product)
+	}
 	return &product, nil
 }
 
+// newVariants sets the options and variants of req on product
+func (p *ProductService) newVariants(ctx context.Context, product *model.Product, req *dto.CreateProductReq) error {
+	if len(req.Options) == 0 && len(req.Variants) == 0 {
+		return nil
+	}
+
+	options, err := newOptions(req.Options)
+	if err != nil {
+		return err
+	}
+	product.Options = options
+
+	skus := make(map[string]bool, len(req.Variants))
+	for _, variantReq := range req.Variants {
+		if skus[variantReq.SKU] {
+			return fmt.Errorf("%w: %s", ErrSKUTaken, variantReq.SKU)
+		}
+		skus[variantReq.SKU] = true
+
+		variant, err := newVariant(product, variantReq)
+		if err != nil {
+			return err
+		}
+		exists, err := p.variantRepo.SKUExists(ctx, variant.SKU, "")
+		if err != nil {
+			return err
+		}
+		if exists {
+			return fmt.Errorf("%w: %s", ErrSKUTaken, variant.SKU)
+		}
+		product.Variants = append(product.Variants, variant)
+	}
+
+	return nil
+}
+
 func (p *ProductService) Update(ctx context.Context, id string, req *dto.UpdateProductReq) (*model.Product, error) {
 	if err := p.validator.ValidateStruct(req); err != nil {
 		return nil, err
@@ -91,3 +195,71 @@ func (p *ProductService) Update(ctx context.Context, id string, req *dto.UpdateP
 
 	return product, nil
 }
+
+func (p *ProductService) AdjustStock(ctx context.Context, id, userID string, req *dto.AdjustStockReq) (*model.ProductStock, error) {
+	if err := p.validator.ValidateStruct(req); err != nil {
+		return nil, err
+	}
+
+	product, err := p.repo.GetProductByID(ctx, id)
+	if err != nil {
+		logger.Errorf("AdjustStock.GetProductByID fail, id: %s, error: %s", id, err)
+		return nil, err
+	}
+	if len(product.Variants) > 0 {
+		return nil, ErrHasVariants
+	}
+
+	movement := &model.StockMovement{
+		ProductID: id,
+		Reason:    model.StockReason(req.Reason),
+		Quantity:  req.Quantity,
+		Note:      req.Note,
+		CreatedBy: userID,
+	}
+	stock, err := p.repo.AdjustStock(ctx, movement)
+	if err != nil {
+		logger.Errorf("AdjustStock fail, id: %s, error: %s", id, err)
+		return nil, err
+	}
+
+	return stock, nil
+}
+
+func (p *ProductService) ListStockMovements(ctx context.Context, id string, req *dto.ListStockMovementReq) ([]*model.StockMovement, *paging.Pagination, error) {
+	movements, pagination, err := p.repo.ListStockMovements(ctx, id, req)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return movements, pagination, nil
+}
+
+// SetCategories replaces the categories of the product
+func (p *ProductService) SetCategories(ctx context.Context, id string, req *dto.SetProductCategoriesReq) (*model.Product, error) {
+	if err := p.validator.ValidateStruct(req); err != nil {
+		return nil, err
+	}
+
+	if _, err := p.repo.GetProductByID(ctx, id); err != nil {
+		logger.Errorf("SetCategories.GetProductByID fail, id: %s, error: %s", id, err)
+		return nil, err
+	}
+
+	seen := make(map[string]bool, len(req.CategoryIDs))
+	categoryIDs := make([]string, 0, len(req.CategoryIDs))
+	for _, categoryID := range req.CategoryIDs {
+		if seen[categoryID] {
+			continue
+		}
+		seen[categoryID] = true
+		categoryIDs = append(categoryIDs, categoryID)
+	}
+
+	if err := p.repo.SetCategories(ctx, id, categoryIDs); err != nil {
+		logger.Errorf("SetCategories fail, id: %s, error: %s", id, err)
+		return nil, err
+	}
+
+	return p.repo.GetProductByID(ctx, id)
+}
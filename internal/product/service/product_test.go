@@ -12,15 +12,18 @@ import (
 
 	"goshop/internal/product/dto"
 	"goshop/internal/product/model"
+	"goshop/internal/product/repository"
 	"goshop/internal/product/repository/mocks"
 	"goshop/pkg/config"
+	"goshop/pkg/money"
 	"goshop/pkg/paging"
 )
 
 type ProductServiceTestSuite struct {
 	suite.Suite
-	mockRepo *mocks.IProductRepository
-	service  IProductService
+	mockRepo        *mocks.IProductRepository
+	mockVariantRepo *mocks.IVariantRepository
+	service         IProductService
 }
 
 func (suite *ProductServiceTestSuite) SetupTest() {
@@ -28,7 +31,8 @@ func (suite *ProductServiceTestSuite) SetupTest() {
 
 	validator := validation.New()
 	suite.mockRepo = mocks.NewIProductRepository(suite.T())
-	suite.service = NewProductService(validator, suite.mockRepo)
+	suite.mockVariantRepo = mocks.NewIVariantRepository(suite.T())
+	suite.service = NewProductService(validator, suite.mockRepo, suite.mockVariantRepo)
 }
 
 func TestProductServiceTestSuite(t *testing.T) {
@@ -46,7 +50,7 @@ func (suite *ProductServiceTestSuite) TestGetProductByIDSuccess() {
 			&model.Product{
 				Name:        "product",
 				Description: "product description",
-				Price:       1.1,
+				Price:       money.New(110, "USD"),
 			},
 			nil,
 		).Times(1)
@@ -55,7 +59,7 @@ func (suite *ProductServiceTestSuite) TestGetProductByIDSuccess() {
 	suite.NotNil(product)
 	suite.Equal("product", product.Name)
 	suite.Equal("product description", product.Description)
-	suite.Equal(1.1, product.Price)
+	suite.Equal(money.New(110, "USD"), product.Price)
 	suite.Nil(err)
 }
 
@@ -83,7 +87,7 @@ func (suite *ProductServiceTestSuite) TestListProductsSuccess() {
 				{
 					Name:        "product",
 					Description: "product description",
-					Price:       1.1,
+					Price:       money.New(110, "USD"),
 				},
 			},
 			&paging.Pagination{
@@ -93,13 +97,16 @@ func (suite *ProductServiceTestSuite) TestListProductsSuccess() {
 			},
 			nil,
 		).Times(1)
+	suite.mockRepo.On("ListProductFacets", mock.Anything, req).
+		Return(&model.ProductFacets{Categories: []*model.CategoryFacet{{Slug: "shoes", Count: 1}}}, nil).Times(1)
 
-	products, pagination, err := suite.service.ListProducts(context.Background(), req)
+	products, facets, pagination, err := suite.service.ListProducts(context.Background(), req)
 	suite.NotNil(products)
 	suite.Equal(1, len(products))
 	suite.Equal("product", products[0].Name)
 	suite.Equal("product description", products[0].Description)
-	suite.Equal(1.1, products[0].Price)
+	suite.Equal(money.New(110, "USD"), products[0].Price)
+	suite.Equal("shoes", facets.Categories[0].Slug)
 	suite.NotNil(pagination)
 	suite.Equal(int64(1), pagination.Total)
 	suite.Equal(int64(1), pagination.CurrentPage)
@@ -115,12 +122,79 @@ func (suite *ProductServiceTestSuite) TestListProductsFail() {
 	suite.mockRepo.On("ListProducts", mock.Anything, req).
 		Return(nil, nil, errors.New("error")).Times(1)
 
-	products, pagination, err := suite.service.ListProducts(context.Background(), req)
+	products, facets, pagination, err := suite.service.ListProducts(context.Background(), req)
 	suite.Nil(products)
+	suite.Nil(facets)
 	suite.Nil(pagination)
 	suite.NotNil(err)
 }
 
+func (suite *ProductServiceTestSuite) TestListProductsFacetsFail() {
+	req := &dto.ListProductReq{}
+
+	suite.mockRepo.On("ListProducts", mock.Anything, req).
+		Return([]*model.Product{{Name: "product"}}, &paging.Pagination{Total: 1}, nil).Times(1)
+	suite.mockRepo.On("ListProductFacets", mock.Anything, req).
+		Return(nil, errors.New("error")).Times(1)
+
+	products, facets, pagination, err := suite.service.ListProducts(context.Background(), req)
+	suite.Nil(products)
+	suite.Nil(facets)
+	suite.Nil(pagination)
+	suite.NotNil(err)
+}
+
+func (suite *ProductServiceTestSuite) TestListProductsInvalidFilters() {
+	tests := []*dto.ListProductReq{
+		{OrderBy: "id; DROP TABLE products"},
+		{MinPrice: "10"},
+		{MinPrice: "10.123", Currency: "USD"},
+		{MaxPrice: "ten", Currency: "USD"},
+		{Options: []string{"Size"}},
+	}
+
+	for _, req := range tests {
+		products, _, _, err := suite.service.ListProducts(context.Background(), req)
+		suite.Nil(products)
+		suite.ErrorIs(err, ErrInvalidFilter)
+	}
+	suite.mockRepo.AssertNotCalled(suite.T(), "ListProducts", mock.Anything, mock.Anything)
+}
+
+// SearchProducts
+// =================================================================
+
+func (suite *ProductServiceTestSuite) TestSearchProductsSuccess() {
+	req := &dto.SearchProductReq{Query: "shoe"}
+
+	suite.mockRepo.On("SearchProducts", mock.Anything, req).
+		Return([]*model.Product{{Name: "Running shoes"}}, &paging.Pagination{Total: 1}, nil).Times(1)
+
+	products, pagination, err := suite.service.SearchProducts(context.Background(), req)
+	suite.Nil(err)
+	suite.Equal(1, len(products))
+	suite.Equal(int64(1), pagination.Total)
+}
+
+func (suite *ProductServiceTestSuite) TestSearchProductsMissQuery() {
+	products, pagination, err := suite.service.SearchProducts(context.Background(), &dto.SearchProductReq{})
+	suite.NotNil(err)
+	suite.Nil(products)
+	suite.Nil(pagination)
+}
+
+func (suite *ProductServiceTestSuite) TestSearchProductsFail() {
+	req := &dto.SearchProductReq{Query: "shoe"}
+
+	suite.mockRepo.On("SearchProducts", mock.Anything, req).
+		Return(nil, nil, errors.New("error")).Times(1)
+
+	products, pagination, err := suite.service.SearchProducts(context.Background(), req)
+	suite.NotNil(err)
+	suite.Nil(products)
+	suite.Nil(pagination)
+}
+
 // Create
 // =================================================================
 
@@ -128,13 +202,13 @@ func (suite *ProductServiceTestSuite) TestCreateSuccess() {
 	req := &dto.CreateProductReq{
 		Name:        "product",
 		Description: "product description",
-		Price:       1.1,
+		Price:       money.New(110, "USD"),
 	}
 
 	suite.mockRepo.On("Create", mock.Anything, &model.Product{
 		Name:        "product",
 		Description: "product description",
-		Price:       1.1,
+		Price:       money.New(110, "USD"),
 	}).Return(nil).Times(1)
 
 	product, err := suite.service.Create(context.Background(), req)
@@ -149,13 +223,13 @@ func (suite *ProductServiceTestSuite) TestCreateFail() {
 	req := &dto.CreateProductReq{
 		Name:        "product",
 		Description: "product description",
-		Price:       1.1,
+		Price:       money.New(110, "USD"),
 	}
 
 	suite.mockRepo.On("Create", mock.Anything, &model.Product{
 		Name:        "product",
 		Description: "product description",
-		Price:       1.1,
+		Price:       money.New(110, "USD"),
 	}).Return(errors.New("error")).Times(1)
 
 	product, err := suite.service.Create(context.Background(), req)
@@ -163,10 +237,70 @@ func (suite *ProductServiceTestSuite) TestCreateFail() {
 	suite.NotNil(err)
 }
 
+func (suite *ProductServiceTestSuite) TestCreateWithVariantsSuccess() {
+	req := &dto.CreateProductReq{
+		Name:        "Tee",
+		Description: "Cotton tee",
+		Price:       money.New(1000, "USD"),
+		Options: []*dto.CreateOptionReq{
+			{Name: "Size", Values: []string{"S", "M"}},
+			{Name: "Colour", Values: []string{"Red"}},
+		},
+		Variants: []*dto.CreateVariantReq{
+			{SKU: "TEE-S-RED", Options: map[string]string{"Size": "S", "Colour": "Red"}},
+			{SKU: "TEE-M-RED", Options: map[string]string{"Size": "M", "Colour": "Red"}},
+		},
+	}
+
+	suite.mockVariantRepo.On("SKUExists", mock.Anything, mock.Anything, "").Return(false, nil).Times(2)
+	suite.mockRepo.On("Create", mock.Anything, mock.Anything).Return(nil).Times(1)
+
+	product, err := suite.service.Create(context.Background(), req)
+	suite.Nil(err)
+	suite.Equal(2, len(product.Options))
+	suite.Equal(1, product.Options[1].Position)
+	suite.Equal(2, len(product.Variants))
+	suite.Equal("M", product.Variants[1].Option("Size"))
+	suite.Equal(req.Price, product.Variants[1].Price)
+}
+
+func (suite *ProductServiceTestSuite) TestCreateWithRepeatedSKU() {
+	req := &dto.CreateProductReq{
+		Name:        "Tee",
+		Description: "Cotton tee",
+		Price:       money.New(1000, "USD"),
+		Options:     []*dto.CreateOptionReq{{Name: "Size", Values: []string{"S", "M"}}},
+		Variants: []*dto.CreateVariantReq{
+			{SKU: "TEE", Options: map[string]string{"Size": "S"}},
+			{SKU: "TEE", Options: map[string]string{"Size": "M"}},
+		},
+	}
+
+	suite.mockVariantRepo.On("SKUExists", mock.Anything, "TEE", "").Return(false, nil).Times(1)
+
+	product, err := suite.service.Create(context.Background(), req)
+	suite.ErrorIs(err, ErrSKUTaken)
+	suite.Nil(product)
+}
+
+func (suite *ProductServiceTestSuite) TestCreateWithInvalidVariant() {
+	req := &dto.CreateProductReq{
+		Name:        "Tee",
+		Description: "Cotton tee",
+		Price:       money.New(1000, "USD"),
+		Options:     []*dto.CreateOptionReq{{Name: "Size", Values: []string{"S", "M"}}},
+		Variants:    []*dto.CreateVariantReq{{SKU: "TEE-L", Options: map[string]string{"Size": "L"}}},
+	}
+
+	product, err := suite.service.Create(context.Background(), req)
+	suite.ErrorIs(err, ErrInvalidVariant)
+	suite.Nil(product)
+}
+
 func (suite *ProductServiceTestSuite) TestCreateMissProductName() {
 	req := &dto.CreateProductReq{
 		Description: "product description",
-		Price:       1.1,
+		Price:       money.New(110, "USD"),
 	}
 
 	product, err := suite.service.Create(context.Background(), req)
@@ -179,54 +313,56 @@ func (suite *ProductServiceTestSuite) TestCreateMissProductName() {
 
 func (suite *ProductServiceTestSuite) TestUpdateSuccess() {
 	productID := "productID"
+	price := money.New(110, "USD")
 	req := &dto.UpdateProductReq{
 		Name:        "product",
 		Description: "product description",
-		Price:       1.1,
+		Price:       &price,
 	}
 
 	suite.mockRepo.On("GetProductByID", mock.Anything, productID).
 		Return(&model.Product{
 			Name:        "product",
 			Description: "product description",
-			Price:       1.1,
+			Price:       money.New(110, "USD"),
 		},
 			nil).Times(1)
 
 	suite.mockRepo.On("Update", mock.Anything, &model.Product{
 		Name:        "product",
 		Description: "product description",
-		Price:       1.1,
+		Price:       money.New(110, "USD"),
 	}).Return(nil).Times(1)
 
 	product, err := suite.service.Update(context.Background(), productID, req)
 	suite.NotNil(product)
 	suite.Equal(req.Name, product.Name)
 	suite.Equal(req.Description, product.Description)
-	suite.Equal(req.Price, product.Price)
+	suite.Equal(*req.Price, product.Price)
 	suite.Nil(err)
 }
 
 func (suite *ProductServiceTestSuite) TestUpdateFail() {
 	productID := "productID"
+	price := money.New(110, "USD")
 	req := &dto.UpdateProductReq{
 		Name:        "product",
 		Description: "product description",
-		Price:       1.1,
+		Price:       &price,
 	}
 
 	suite.mockRepo.On("GetProductByID", mock.Anything, productID).
 		Return(&model.Product{
 			Name:        "product",
 			Description: "product description",
-			Price:       1.1,
+			Price:       money.New(110, "USD"),
 		},
 			nil).Times(1)
 
 	suite.mockRepo.On("Update", mock.Anything, &model.Product{
 		Name:        "product",
 		Description: "product description",
-		Price:       1.1,
+		Price:       money.New(110, "USD"),
 	}).Return(errors.New("error")).Times(1)
 
 	product, err := suite.service.Update(context.Background(), productID, req)
@@ -236,10 +372,11 @@ func (suite *ProductServiceTestSuite) TestUpdateFail() {
 
 func (suite *ProductServiceTestSuite) TestUpdateInvalidPrice() {
 	productID := "productID"
+	price := money.New(-110, "USD")
 	req := &dto.UpdateProductReq{
 		Name:        "product",
 		Description: "product description",
-		Price:       -1.1,
+		Price:       &price,
 	}
 
 	product, err := suite.service.Update(context.Background(), productID, req)
@@ -249,10 +386,11 @@ func (suite *ProductServiceTestSuite) TestUpdateInvalidPrice() {
 
 func (suite *ProductServiceTestSuite) TestUpdateGetProductByIDFail() {
 	productID := "productID"
+	price := money.New(110, "USD")
 	req := &dto.UpdateProductReq{
 		Name:        "product",
 		Description: "product description",
-		Price:       1.1,
+		Price:       &price,
 	}
 
 	suite.mockRepo.On("GetProductByID", mock.Anything, productID).
@@ -262,3 +400,173 @@ func (suite *ProductServiceTestSuite) TestUpdateGetProductByIDFail() {
 	suite.Nil(product)
 	suite.NotNil(err)
 }
+
+// AdjustStock
+// =================================================================
+
+func (suite *ProductServiceTestSuite) TestAdjustStockSuccess() {
+	productID := "productID"
+	req := &dto.AdjustStockReq{
+		Quantity: 10,
+		Reason:   "restock",
+		Note:     "supplier delivery",
+	}
+
+	suite.mockRepo.On("GetProductByID", mock.Anything, productID).
+		Return(&model.Product{ID: productID}, nil).Times(1)
+	suite.mockRepo.On("AdjustStock", mock.Anything, &model.StockMovement{
+		ProductID: productID,
+		Reason:    model.StockReasonRestock,
+		Quantity:  10,
+		Note:      "supplier delivery",
+		CreatedBy: "userID",
+	}).Return(&model.ProductStock{ProductID: productID, OnHand: 10, Available: 10}, nil).Times(1)
+
+	stock, err := suite.service.AdjustStock(context.Background(), productID, "userID", req)
+	suite.Nil(err)
+	suite.Equal(int64(10), stock.OnHand)
+	suite.Equal(int64(10), stock.Available)
+}
+
+func (suite *ProductServiceTestSuite) TestAdjustStockProductWithVariants() {
+	productID := "productID"
+	req := &dto.AdjustStockReq{
+		Quantity: 10,
+		Reason:   "restock",
+	}
+
+	suite.mockRepo.On("GetProductByID", mock.Anything, productID).
+		Return(&model.Product{ID: productID, Variants: []*model.ProductVariant{{ID: "variantID"}}}, nil).Times(1)
+
+	stock, err := suite.service.AdjustStock(context.Background(), productID, "userID", req)
+	suite.Nil(stock)
+	suite.ErrorIs(err, ErrHasVariants)
+}
+
+func (suite *ProductServiceTestSuite) TestAdjustStockInvalidReason() {
+	req := &dto.AdjustStockReq{
+		Quantity: 10,
+		Reason:   "gift",
+	}
+
+	stock, err := suite.service.AdjustStock(context.Background(), "productID", "userID", req)
+	suite.Nil(stock)
+	suite.NotNil(err)
+}
+
+func (suite *ProductServiceTestSuite) TestAdjustStockZeroQuantity() {
+	req := &dto.AdjustStockReq{
+		Reason: "correction",
+	}
+
+	stock, err := suite.service.AdjustStock(context.Background(), "productID", "userID", req)
+	suite.Nil(stock)
+	suite.NotNil(err)
+}
+
+func (suite *ProductServiceTestSuite) TestAdjustStockGetProductByIDFail() {
+	req := &dto.AdjustStockReq{
+		Quantity: -1,
+		Reason:   "damaged",
+	}
+
+	suite.mockRepo.On("GetProductByID", mock.Anything, "productID").
+		Return(nil, errors.New("error")).Times(1)
+
+	stock, err := suite.service.AdjustStock(context.Background(), "productID", "userID", req)
+	suite.Nil(stock)
+	suite.NotNil(err)
+}
+
+func (suite *ProductServiceTestSuite) TestAdjustStockFail() {
+	req := &dto.AdjustStockReq{
+		Quantity: -1,
+		Reason:   "damaged",
+	}
+
+	suite.mockRepo.On("GetProductByID", mock.Anything, "productID").
+		Return(&model.Product{ID: "productID"}, nil).Times(1)
+	suite.mockRepo.On("AdjustStock", mock.Anything, mock.Anything).
+		Return(nil, errors.New("error")).Times(1)
+
+	stock, err := suite.service.AdjustStock(context.Background(), "productID", "userID", req)
+	suite.Nil(stock)
+	suite.NotNil(err)
+}
+
+// ListStockMovements
+// =================================================================
+
+func (suite *ProductServiceTestSuite) TestListStockMovementsSuccess() {
+	req := &dto.ListStockMovementReq{}
+	suite.mockRepo.On("ListStockMovements", mock.Anything, "productID", req).
+		Return([]*model.StockMovement{{ProductID: "productID", Quantity: 3}}, &paging.Pagination{Total: 1}, nil).Times(1)
+
+	movements, pagination, err := suite.service.ListStockMovements(context.Background(), "productID", req)
+	suite.Nil(err)
+	suite.Equal(1, len(movements))
+	suite.Equal(int64(1), pagination.Total)
+}
+
+func (suite *ProductServiceTestSuite) TestListStockMovementsFail() {
+	req := &dto.ListStockMovementReq{}
+	suite.mockRepo.On("ListStockMovements", mock.Anything, "productID", req).
+		Return(nil, nil, errors.New("error")).Times(1)
+
+	movements, pagination, err := suite.service.ListStockMovements(context.Background(), "productID", req)
+	suite.Nil(movements)
+	suite.Nil(pagination)
+	suite.NotNil(err)
+}
+
+// SetCategories
+// =================================================================
+
+func (suite *ProductServiceTestSuite) TestSetCategoriesSuccess() {
+	req := &dto.SetProductCategoriesReq{
+		CategoryIDs: []string{"categoryId1", "categoryId2", "categoryId1"},
+	}
+
+	suite.mockRepo.On("GetProductByID", mock.Anything, "productId").
+		Return(&model.Product{ID: "productId"}, nil).Times(2)
+	suite.mockRepo.On("SetCategories", mock.Anything, "productId", []string{"categoryId1", "categoryId2"}).
+		Return(nil).Times(1)
+
+	product, err := suite.service.SetCategories(context.Background(), "productId", req)
+	suite.Nil(err)
+	suite.NotNil(product)
+}
+
+func (suite *ProductServiceTestSuite) TestSetCategoriesInvalidCategoryID() {
+	req := &dto.SetProductCategoriesReq{
+		CategoryIDs: []string{""},
+	}
+
+	product, err := suite.service.SetCategories(context.Background(), "productId", req)
+	suite.NotNil(err)
+	suite.Nil(product)
+}
+
+func (suite *ProductServiceTestSuite) TestSetCategoriesGetProductByIDFail() {
+	suite.mockRepo.On("GetProductByID", mock.Anything, "productId").
+		Return(nil, errors.New("error")).Times(1)
+
+	product, err := suite.service.SetCategories(context.Background(), "productId", &dto.SetProductCategoriesReq{})
+	suite.NotNil(err)
+	suite.Nil(product)
+}
+
+func (suite *ProductServiceTestSuite) TestSetCategoriesFail() {
+	req := &dto.SetProductCategoriesReq{
+		CategoryIDs: []string{"categoryId1"},
+	}
+
+	suite.mockRepo.On("GetProductByID", mock.Anything, "productId").
+		Return(&model.Product{ID: "productId"}, nil).Times(1)
+	suite.mockRepo.On("SetCategories", mock.Anything, "productId", []string{"categoryId1"}).
+		Return(repository.ErrCategoryNotFound).Times(1)
+
+	product, err := suite.service.SetCategories(context.Background(), "productId", req)
+	suite.ErrorIs(err, repository.ErrCategoryNotFound)
+	suite.Nil(product)
+}
@@ -0,0 +1,318 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/quangdangfit/gocommon/logger"
+	"github.com/quangdangfit/gocommon/validation"
+
+	"goshop/internal/product/dto"
+	"goshop/internal/product/model"
+	"goshop/internal/product/repository"
+	"goshop/pkg/money"
+)
+
+var (
+	ErrInvalidOption    = errors.New("invalid option")
+	ErrInvalidVariant   = errors.New("invalid variant")
+	ErrDuplicateVariant = errors.New("a variant of the product already has these options")
+	ErrSKUTaken         = errors.New("sku is used by another variant")
+	ErrVariantNotFound  = errors.New("variant not found")
+	ErrHasVariants      = errors.New("product has variants, its stock is kept by variant")
+)
+
+//go:generate mockery --name=IVariantService
+type IVariantService interface {
+	AddOption(ctx context.Context, productID string, req *dto.CreateOptionReq) (*model.Product, error)
+	CreateVariant(ctx context.Context, productID string, req *dto.CreateVariantReq) (*model.ProductVariant, error)
+	UpdateVariant(ctx context.Context, productID, id string, req *dto.UpdateVariantReq) (*model.ProductVariant, error)
+	AdjustStock(ctx context.Context, productID, id, userID string, req *dto.AdjustStockReq) (*model.VariantStock, error)
+}
+
+type VariantService struct {
+	validator   validation.Validation
+	repo        repository.IVariantRepository
+	productRepo repository.IProductRepository
+}
+
+func NewVariantService(
+	validator validation.Validation,
+	repo repository.IVariantRepository,
+	productRepo repository.IProductRepository,
+) *VariantService {
+	return &VariantService{
+		validator:   validator,
+		repo:        repo,
+		productRepo: productRepo,
+	}
+}
+
+// AddOption adds an option type to the product, or adds values to the option type of the
+// same name. New option types can only be added to products without variants, which would
+// otherwise miss a value of them.
+func (s *VariantService) AddOption(ctx context.Context, productID string, req *dto.CreateOptionReq) (*model.Product, error) {
+	if err := s.validator.ValidateStruct(req); err != nil {
+		return nil, err
+	}
+
+	product, err := s.productRepo.GetProductByID(ctx, productID)
+	if err != nil {
+		logger.Errorf("AddOption.GetProductByID fail, id: %s, error: %s", productID, err)
+		return nil, err
+	}
+
+	name := strings.TrimSpace(req.Name)
+	var option *model.ProductOption
+	for _, existing := range product.Options {
+		if strings.EqualFold(existing.Name, name) {
+			option = existing
+		}
+	}
+
+	if option == nil {
+		if len(product.Variants) > 0 {
+			return nil, fmt.Errorf("%w: %s cannot be added to a product with variants", ErrInvalidOption, name)
+		}
+		option = &model.ProductOption{
+			ProductID: productID,
+			Name:      name,
+			Position:  len(product.Options),
+			Values:    newOptionValues("", nil, req.Values),
+		}
+		if err := s.repo.CreateOption(ctx, option); err != nil {
+			logger.Errorf("AddOption.CreateOption fail, id: %s, error: %s", productID, err)
+			return nil, err
+		}
+	} else {
+		values := newOptionValues(option.ID, option.Values, req.Values)
+		if len(values) > 0 {
+			if err := s.repo.CreateOptionValues(ctx, values); err != nil {
+				logger.Errorf("AddOption.CreateOptionValues fail, id: %s, error: %s", productID, err)
+				return nil, err
+			}
+		}
+	}
+
+	return s.productRepo.GetProductByID(ctx, productID)
+}
+
+func (s *VariantService) CreateVariant(ctx context.Context, productID string, req *dto.CreateVariantReq) (*model.ProductVariant, error) {
+	if err := s.validator.ValidateStruct(req); err != nil {
+		return nil, err
+	}
+
+	product, err := s.productRepo.GetProductByID(ctx, productID)
+	if err != nil {
+		logger.Errorf("CreateVariant.GetProductByID fail, id: %s, error: %s", productID, err)
+		return nil, err
+	}
+
+	variant, err := newVariant(product, req)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.checkSKU(ctx, variant.SKU, ""); err != nil {
+		return nil, err
+	}
+
+	if err := s.repo.CreateVariant(ctx, variant); err != nil {
+		logger.Errorf("CreateVariant fail, id: %s, error: %s", productID, err)
+		return nil, err
+	}
+
+	variant.ResolvePrice(product)
+	return variant, nil
+}
+
+func (s *VariantService) UpdateVariant(ctx context.Context, productID, id string, req *dto.UpdateVariantReq) (*model.ProductVariant, error) {
+	if err := s.validator.ValidateStruct(req); err != nil {
+		return nil, err
+	}
+
+	product, err := s.productRepo.GetProductByID(ctx, productID)
+	if err != nil {
+		logger.Errorf("UpdateVariant.GetProductByID fail, id: %s, error: %s", productID, err)
+		return nil, err
+	}
+
+	variant, err := s.repo.GetVariantByID(ctx, productID, id)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrVariantNotFound, id)
+	}
+
+	if req.SKU != "" && req.SKU != variant.SKU {
+		if err := s.checkSKU(ctx, req.SKU, id); err != nil {
+			return nil, err
+		}
+		variant.SKU = req.SKU
+	}
+	if req.Price != nil {
+		if err := checkVariantPrice(*req.Price); err != nil {
+			return nil, err
+		}
+		variant.PriceOverride = *req.Price
+	}
+	if req.Active != nil {
+		variant.Active = *req.Active
+	}
+
+	if err := s.repo.UpdateVariant(ctx, variant); err != nil {
+		logger.Errorf("UpdateVariant fail, id: %s, error: %s", id, err)
+		return nil, err
+	}
+
+	variant.ResolvePrice(product)
+	return variant, nil
+}
+
+func (s *VariantService) AdjustStock(ctx context.Context, productID, id, userID string, req *dto.AdjustStockReq) (*model.VariantStock, error) {
+	if err := s.validator.ValidateStruct(req); err != nil {
+		return nil, err
+	}
+
+	if _, err := s.repo.GetVariantByID(ctx, productID, id); err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrVariantNotFound, id)
+	}
+
+	movement := &model.StockMovement{
+		ProductID: productID,
+		VariantID: id,
+		Reason:    model.StockReason(req.Reason),
+		Quantity:  req.Quantity,
+		Note:      req.Note,
+		CreatedBy: userID,
+	}
+	stock, err := s.repo.AdjustStock(ctx, movement)
+	if err != nil {
+		logger.Errorf("AdjustStock fail, variant: %s, error: %s", id, err)
+		return nil, err
+	}
+
+	return stock, nil
+}
+
+func (s *VariantService) checkSKU(ctx context.Context, sku, excludeID string) error {
+	exists, err := s.repo.SKUExists(ctx, sku, excludeID)
+	if err != nil {
+		return err
+	}
+	if exists {
+		return fmt.Errorf("%w: %s", ErrSKUTaken, sku)
+	}
+	return nil
+}
+
+// newOptions returns the options of reqs for a new product
+func newOptions(reqs []*dto.CreateOptionReq) ([]*model.ProductOption, error) {
+	options := make([]*model.ProductOption, 0, len(reqs))
+	seen := make(map[string]bool, len(reqs))
+	for i, req := range reqs {
+		name := strings.TrimSpace(req.Name)
+		if seen[strings.ToLower(name)] {
+			return nil, fmt.Errorf("%w: %s is repeated", ErrInvalidOption, name)
+		}
+		seen[strings.ToLower(name)] = true
+
+		options = append(options, &model.ProductOption{
+			Name:     name,
+			Position: i,
+			Values:   newOptionValues("", nil, req.Values),
+		})
+	}
+
+	return options, nil
+}
+
+// newOptionValues returns the values which are not among existing, placed after them
+func newOptionValues(optionID string, existing []*model.ProductOptionValue, values []string) []*model.ProductOptionValue {
+	seen := make(map[string]bool, len(existing)+len(values))
+	for _, value := range existing {
+		seen[value.Value] = true
+	}
+
+	created := make([]*model.ProductOptionValue, 0, len(values))
+	for _, value := range values {
+		value = strings.TrimSpace(value)
+		if value == "" || seen[value] {
+			continue
+		}
+		seen[value] = true
+		created = append(created, &model.ProductOptionValue{
+			OptionID: optionID,
+			Value:    value,
+			Position: len(existing) + len(created),
+		})
+	}
+
+	return created
+}
+
+// newVariant returns the variant of req for product, which must take one of the values of
+// every option of the product and differ from the other variants by at least one of them
+func newVariant(product *model.Product, req *dto.CreateVariantReq) (*model.ProductVariant, error) {
+	if len(req.Options) != len(product.Options) {
+		return nil, fmt.Errorf("%w: %s must have a value for each of the %d options of the product",
+			ErrInvalidVariant, req.SKU, len(product.Options))
+	}
+
+	variant := &model.ProductVariant{
+		ProductID: product.ID,
+		SKU:       req.SKU,
+		Active:    req.Active == nil || *req.Active,
+		Options:   make([]*model.VariantOption, 0, len(product.Options)),
+	}
+	if req.Price != nil {
+		if err := checkVariantPrice(*req.Price); err != nil {
+			return nil, err
+		}
+		variant.PriceOverride = *req.Price
+	}
+
+	for _, option := range product.Options {
+		value, ok := req.Options[option.Name]
+		if !ok || !hasValue(option, value) {
+			return nil, fmt.Errorf("%w: %s has no valid value for %s", ErrInvalidVariant, req.SKU, option.Name)
+		}
+		variant.Options = append(variant.Options, &model.VariantOption{Name: option.Name, Value: value})
+	}
+
+	for _, other := range product.Variants {
+		if sameOptions(variant, other) {
+			return nil, fmt.Errorf("%w: %s", ErrDuplicateVariant, other.SKU)
+		}
+	}
+
+	return variant, nil
+}
+
+func hasValue(option *model.ProductOption, value string) bool {
+	for _, optionValue := range option.Values {
+		if optionValue.Value == value {
+			return true
+		}
+	}
+	return false
+}
+
+func sameOptions(a, b *model.ProductVariant) bool {
+	for _, option := range a.Options {
+		if b.Option(option.Name) != option.Value {
+			return false
+		}
+	}
+	return true
+}
+
+// checkVariantPrice accepts the zero price, which makes the variant sell at the product price
+func checkVariantPrice(price money.Money) error {
+	if price.IsZero() {
+		return nil
+	}
+	if _, err := money.Exponent(price.Currency); err != nil || price.Amount <= 0 {
+		return ErrInvalidPrice
+	}
+	return nil
+}
@@ -0,0 +1,287 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/quangdangfit/gocommon/logger"
+	"github.com/quangdangfit/gocommon/validation"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+
+	"goshop/internal/product/dto"
+	"goshop/internal/product/model"
+	"goshop/internal/product/repository/mocks"
+	"goshop/pkg/config"
+	"goshop/pkg/money"
+)
+
+type VariantServiceTestSuite struct {
+	suite.Suite
+	mockRepo        *mocks.IVariantRepository
+	mockProductRepo *mocks.IProductRepository
+	service         IVariantService
+}
+
+func (suite *VariantServiceTestSuite) SetupTest() {
+	logger.Initialize(config.ProductionEnv)
+
+	validator := validation.New()
+	suite.mockRepo = mocks.NewIVariantRepository(suite.T())
+	suite.mockProductRepo = mocks.NewIProductRepository(suite.T())
+	suite.service = NewVariantService(validator, suite.mockRepo, suite.mockProductRepo)
+}
+
+func TestVariantServiceTestSuite(t *testing.T) {
+	suite.Run(t, new(VariantServiceTestSuite))
+}
+
+// tee is a product with a size option and a variant of size S
+func tee() *model.Product {
+	return &model.Product{
+		ID:    "productId1",
+		Name:  "Tee",
+		Price: money.New(1000, "USD"),
+		Options: []*model.ProductOption{{
+			ID:     "optionId1",
+			Name:   "Size",
+			Values: []*model.ProductOptionValue{{OptionID: "optionId1", Value: "S"}, {OptionID: "optionId1", Value: "M", Position: 1}},
+		}},
+		Variants: []*model.ProductVariant{{
+			ID:      "variantId1",
+			SKU:     "TEE-S",
+			Active:  true,
+			Options: []*model.VariantOption{{Name: "Size", Value: "S"}},
+		}},
+	}
+}
+
+// AddOption
+// =================================================================
+
+func (suite *VariantServiceTestSuite) TestAddOptionNewSuccess() {
+	product := &model.Product{ID: "productId1"}
+	suite.mockProductRepo.On("GetProductByID", mock.Anything, "productId1").Return(product, nil).Times(2)
+	suite.mockRepo.On("CreateOption", mock.Anything, &model.ProductOption{
+		ProductID: "productId1",
+		Name:      "Size",
+		Values:    []*model.ProductOptionValue{{Value: "S"}, {Value: "M", Position: 1}},
+	}).Return(nil).Times(1)
+
+	res, err := suite.service.AddOption(context.Background(), "productId1", &dto.CreateOptionReq{
+		Name:   " Size ",
+		Values: []string{"S", "M", "S"},
+	})
+	suite.Nil(err)
+	suite.Equal(product, res)
+}
+
+func (suite *VariantServiceTestSuite) TestAddOptionValuesSuccess() {
+	suite.mockProductRepo.On("GetProductByID", mock.Anything, "productId1").Return(tee(), nil).Times(2)
+	suite.mockRepo.On("CreateOptionValues", mock.Anything, []*model.ProductOptionValue{
+		{OptionID: "optionId1", Value: "L", Position: 2},
+	}).Return(nil).Times(1)
+
+	res, err := suite.service.AddOption(context.Background(), "productId1", &dto.CreateOptionReq{
+		Name:   "size",
+		Values: []string{"M", "L"},
+	})
+	suite.Nil(err)
+	suite.NotNil(res)
+}
+
+func (suite *VariantServiceTestSuite) TestAddOptionToProductWithVariants() {
+	suite.mockProductRepo.On("GetProductByID", mock.Anything, "productId1").Return(tee(), nil).Times(1)
+
+	res, err := suite.service.AddOption(context.Background(), "productId1", &dto.CreateOptionReq{
+		Name:   "Colour",
+		Values: []string{"Red"},
+	})
+	suite.ErrorIs(err, ErrInvalidOption)
+	suite.Nil(res)
+}
+
+func (suite *VariantServiceTestSuite) TestAddOptionInvalidRequest() {
+	res, err := suite.service.AddOption(context.Background(), "productId1", &dto.CreateOptionReq{Name: "Size"})
+	suite.NotNil(err)
+	suite.Nil(res)
+}
+
+// CreateVariant
+// =================================================================
+
+func (suite *VariantServiceTestSuite) TestCreateVariantSuccess() {
+	suite.mockProductRepo.On("GetProductByID", mock.Anything, "productId1").Return(tee(), nil).Times(1)
+	suite.mockRepo.On("SKUExists", mock.Anything, "TEE-M", "").Return(false, nil).Times(1)
+	suite.mockRepo.On("CreateVariant", mock.Anything, mock.Anything).Return(nil).Times(1)
+
+	price := money.New(1200, "USD")
+	variant, err := suite.service.CreateVariant(context.Background(), "productId1", &dto.CreateVariantReq{
+		SKU:     "TEE-M",
+		Price:   &price,
+		Options: map[string]string{"Size": "M"},
+	})
+	suite.Nil(err)
+	suite.Equal("M", variant.Option("Size"))
+	suite.Equal(price, variant.Price)
+	suite.True(variant.Active)
+}
+
+func (suite *VariantServiceTestSuite) TestCreateVariantUsesProductPrice() {
+	suite.mockProductRepo.On("GetProductByID", mock.Anything, "productId1").Return(tee(), nil).Times(1)
+	suite.mockRepo.On("SKUExists", mock.Anything, "TEE-M", "").Return(false, nil).Times(1)
+	suite.mockRepo.On("CreateVariant", mock.Anything, mock.Anything).Return(nil).Times(1)
+
+	variant, err := suite.service.CreateVariant(context.Background(), "productId1", &dto.CreateVariantReq{
+		SKU:     "TEE-M",
+		Options: map[string]string{"Size": "M"},
+	})
+	suite.Nil(err)
+	suite.True(variant.PriceOverride.IsZero())
+	suite.Equal(money.New(1000, "USD"), variant.Price)
+}
+
+func (suite *VariantServiceTestSuite) TestCreateVariantUnknownValue() {
+	suite.mockProductRepo.On("GetProductByID", mock.Anything, "productId1").Return(tee(), nil).Times(1)
+
+	variant, err := suite.service.CreateVariant(context.Background(), "productId1", &dto.CreateVariantReq{
+		SKU:     "TEE-XL",
+		Options: map[string]string{"Size": "XL"},
+	})
+	suite.ErrorIs(err, ErrInvalidVariant)
+	suite.Nil(variant)
+}
+
+func (suite *VariantServiceTestSuite) TestCreateVariantMissingOption() {
+	suite.mockProductRepo.On("GetProductByID", mock.Anything, "productId1").Return(tee(), nil).Times(1)
+
+	variant, err := suite.service.CreateVariant(context.Background(), "productId1", &dto.CreateVariantReq{SKU: "TEE"})
+	suite.ErrorIs(err, ErrInvalidVariant)
+	suite.Nil(variant)
+}
+
+func (suite *VariantServiceTestSuite) TestCreateVariantDuplicate() {
+	suite.mockProductRepo.On("GetProductByID", mock.Anything, "productId1").Return(tee(), nil).Times(1)
+
+	variant, err := suite.service.CreateVariant(context.Background(), "productId1", &dto.CreateVariantReq{
+		SKU:     "TEE-S2",
+		Options: map[string]string{"Size": "S"},
+	})
+	suite.ErrorIs(err, ErrDuplicateVariant)
+	suite.Nil(variant)
+}
+
+func (suite *VariantServiceTestSuite) TestCreateVariantSKUTaken() {
+	suite.mockProductRepo.On("GetProductByID", mock.Anything, "productId1").Return(tee(), nil).Times(1)
+	suite.mockRepo.On("SKUExists", mock.Anything, "TEE-M", "").Return(true, nil).Times(1)
+
+	variant, err := suite.service.CreateVariant(context.Background(), "productId1", &dto.CreateVariantReq{
+		SKU:     "TEE-M",
+		Options: map[string]string{"Size": "M"},
+	})
+	suite.ErrorIs(err, ErrSKUTaken)
+	suite.Nil(variant)
+}
+
+func (suite *VariantServiceTestSuite) TestCreateVariantInvalidPrice() {
+	suite.mockProductRepo.On("GetProductByID", mock.Anything, "productId1").Return(tee(), nil).Times(1)
+
+	price := money.New(100, "ABC")
+	variant, err := suite.service.CreateVariant(context.Background(), "productId1", &dto.CreateVariantReq{
+		SKU:     "TEE-M",
+		Price:   &price,
+		Options: map[string]string{"Size": "M"},
+	})
+	suite.ErrorIs(err, ErrInvalidPrice)
+	suite.Nil(variant)
+}
+
+func (suite *VariantServiceTestSuite) TestCreateVariantFail() {
+	suite.mockProductRepo.On("GetProductByID", mock.Anything, "productId1").Return(tee(), nil).Times(1)
+	suite.mockRepo.On("SKUExists", mock.Anything, "TEE-M", "").Return(false, nil).Times(1)
+	suite.mockRepo.On("CreateVariant", mock.Anything, mock.Anything).Return(errors.New("error")).Times(1)
+
+	variant, err := suite.service.CreateVariant(context.Background(), "productId1", &dto.CreateVariantReq{
+		SKU:     "TEE-M",
+		Options: map[string]string{"Size": "M"},
+	})
+	suite.NotNil(err)
+	suite.Nil(variant)
+}
+
+// UpdateVariant
+// =================================================================
+
+func (suite *VariantServiceTestSuite) TestUpdateVariantSuccess() {
+	product := tee()
+	variant := product.Variants[0]
+	variant.PriceOverride = money.New(1500, "USD")
+	suite.mockProductRepo.On("GetProductByID", mock.Anything, "productId1").Return(product, nil).Times(1)
+	suite.mockRepo.On("GetVariantByID", mock.Anything, "productId1", "variantId1").Return(variant, nil).Times(1)
+	suite.mockRepo.On("SKUExists", mock.Anything, "TEE-SMALL", "variantId1").Return(false, nil).Times(1)
+	suite.mockRepo.On("UpdateVariant", mock.Anything, variant).Return(nil).Times(1)
+
+	active := false
+	res, err := suite.service.UpdateVariant(context.Background(), "productId1", "variantId1", &dto.UpdateVariantReq{
+		SKU:    "TEE-SMALL",
+		Price:  &money.Money{},
+		Active: &active,
+	})
+	suite.Nil(err)
+	suite.Equal("TEE-SMALL", res.SKU)
+	suite.False(res.Active)
+	suite.Equal(money.New(1000, "USD"), res.Price)
+}
+
+func (suite *VariantServiceTestSuite) TestUpdateVariantNotFound() {
+	suite.mockProductRepo.On("GetProductByID", mock.Anything, "productId1").Return(tee(), nil).Times(1)
+	suite.mockRepo.On("GetVariantByID", mock.Anything, "productId1", "variantId2").Return(nil, errors.New("error")).Times(1)
+
+	res, err := suite.service.UpdateVariant(context.Background(), "productId1", "variantId2", &dto.UpdateVariantReq{SKU: "TEE"})
+	suite.ErrorIs(err, ErrVariantNotFound)
+	suite.Nil(res)
+}
+
+func (suite *VariantServiceTestSuite) TestUpdateVariantSKUTaken() {
+	product := tee()
+	suite.mockProductRepo.On("GetProductByID", mock.Anything, "productId1").Return(product, nil).Times(1)
+	suite.mockRepo.On("GetVariantByID", mock.Anything, "productId1", "variantId1").Return(product.Variants[0], nil).Times(1)
+	suite.mockRepo.On("SKUExists", mock.Anything, "TEE-M", "variantId1").Return(true, nil).Times(1)
+
+	res, err := suite.service.UpdateVariant(context.Background(), "productId1", "variantId1", &dto.UpdateVariantReq{SKU: "TEE-M"})
+	suite.ErrorIs(err, ErrSKUTaken)
+	suite.Nil(res)
+}
+
+// AdjustStock
+// =================================================================
+
+func (suite *VariantServiceTestSuite) TestAdjustStockSuccess() {
+	suite.mockRepo.On("GetVariantByID", mock.Anything, "productId1", "variantId1").Return(tee().Variants[0], nil).Times(1)
+	suite.mockRepo.On("AdjustStock", mock.Anything, &model.StockMovement{
+		ProductID: "productId1",
+		VariantID: "variantId1",
+		Reason:    model.StockReasonRestock,
+		Quantity:  5,
+		CreatedBy: "userId1",
+	}).Return(&model.VariantStock{VariantID: "variantId1", OnHand: 5, Available: 5}, nil).Times(1)
+
+	stock, err := suite.service.AdjustStock(context.Background(), "productId1", "variantId1", "userId1", &dto.AdjustStockReq{
+		Quantity: 5,
+		Reason:   string(model.StockReasonRestock),
+	})
+	suite.Nil(err)
+	suite.Equal(int64(5), stock.OnHand)
+}
+
+func (suite *VariantServiceTestSuite) TestAdjustStockVariantNotFound() {
+	suite.mockRepo.On("GetVariantByID", mock.Anything, "productId1", "variantId2").Return(nil, errors.New("error")).Times(1)
+
+	stock, err := suite.service.AdjustStock(context.Background(), "productId1", "variantId2", "userId1", &dto.AdjustStockReq{
+		Quantity: 5,
+		Reason:   string(model.StockReasonRestock),
+	})
+	suite.ErrorIs(err, ErrVariantNotFound)
+	suite.Nil(stock)
+}
@@ -0,0 +1,77 @@
+package dto
+
+import (
+	"time"
+
+	"goshop/pkg/money"
+	"goshop/pkg/paging"
+)
+
+type Promotion struct {
+	ID             string            `json:"id"`
+	Code           string            `json:"code"`
+	Name           string            `json:"name"`
+	Type           string            `json:"type"`
+	Percentage     uint              `json:"percentage,omitempty"`
+	Discount       money.Money       `json:"discount"`
+	BuyQuantity    uint              `json:"buy_quantity,omitempty"`
+	GetQuantity    uint              `json:"get_quantity,omitempty"`
+	MinOrderValue  money.Money       `json:"min_order_value"`
+	UsageLimit     uint              `json:"usage_limit"`
+	UserUsageLimit uint              `json:"user_usage_limit"`
+	UsageCount     uint              `json:"usage_count"`
+	StartsAt       *time.Time        `json:"starts_at,omitempty"`
+	EndsAt         *time.Time        `json:"ends_at,omitempty"`
+	Active         bool              `json:"active"`
+	Scopes         []*PromotionScope `json:"scopes"`
+	CreatedAt      time.Time         `json:"created_at"`
+	UpdatedAt      time.Time         `json:"updated_at"`
+}
+
+type PromotionScope struct {
+	Type     string `json:"type" validate:"required,oneof=product category"`
+	TargetID string `json:"target_id" validate:"required"`
+}
+
+type ListPromotionReq struct {
+	Code      string `json:"code,omitempty" form:"code"`
+	Active    *bool  `json:"active,omitempty" form:"active"`
+	Page      int64  `json:"-" form:"page"`
+	Limit     int64  `json:"-" form:"limit"`
+	OrderBy   string `json:"-" form:"order_by" validate:"omitempty,oneof=created_at updated_at code name starts_at ends_at"`
+	OrderDesc bool   `json:"-" form:"order_desc"`
+}
+
+type ListPromotionRes struct {
+	Promotions []*Promotion       `json:"promotions"`
+	Pagination *paging.Pagination `json:"pagination"`
+}
+
+// CreatePromotionReq describes a promotion. Percentage is required by percentage promotions,
+// Discount by fixed ones and BuyQuantity and GetQuantity by buy_x_get_y ones.
+type CreatePromotionReq struct {
+	Code           string            `json:"code" validate:"required,max=64"`
+	Name           string            `json:"name" validate:"required"`
+	Type           string            `json:"type" validate:"required,oneof=percentage fixed buy_x_get_y"`
+	Percentage     uint              `json:"percentage,omitempty" validate:"lte=100"`
+	Discount       money.Money       `json:"discount"`
+	BuyQuantity    uint              `json:"buy_quantity,omitempty"`
+	GetQuantity    uint              `json:"get_quantity,omitempty"`
+	MinOrderValue  money.Money       `json:"min_order_value"`
+	UsageLimit     uint              `json:"usage_limit,omitempty"`
+	UserUsageLimit uint              `json:"user_usage_limit,omitempty"`
+	StartsAt       *time.Time        `json:"starts_at,omitempty"`
+	EndsAt         *time.Time        `json:"ends_at,omitempty"`
+	Active         *bool             `json:"active,omitempty"`
+	Scopes         []*PromotionScope `json:"scopes,omitempty" validate:"dive"`
+}
+
+type UpdatePromotionReq struct {
+	Name           string            `json:"name,omitempty"`
+	UsageLimit     *uint             `json:"usage_limit,omitempty"`
+	UserUsageLimit *uint             `json:"user_usage_limit,omitempty"`
+	StartsAt       *time.Time        `json:"starts_at,omitempty"`
+	EndsAt         *time.Time        `json:"ends_at,omitempty"`
+	Active         *bool             `json:"active,omitempty"`
+	Scopes         []*PromotionScope `json:"scopes,omitempty" validate:"dive"`
+}
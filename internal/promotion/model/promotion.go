@@ -0,0 +1,127 @@
+package model
+
+import (
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+
+	"goshop/pkg/money"
+)
+
+type PromotionType string
+
+const (
+	// PromotionTypePercentage takes Percentage percent off the lines in scope
+	PromotionTypePercentage PromotionType = "percentage"
+	// PromotionTypeFixed takes Discount off the lines in scope, shared by their price
+	PromotionTypeFixed PromotionType = "fixed"
+	// PromotionTypeBuyXGetY makes GetQuantity units free for every BuyQuantity units bought of a
+	// product in scope
+	PromotionTypeBuyXGetY PromotionType = "buy_x_get_y"
+)
+
+type ScopeType string
+
+const (
+	ScopeTypeProduct  ScopeType = "product"
+	ScopeTypeCategory ScopeType = "category"
+)
+
+// Promotion is a discount customers get by placing an order with its coupon code
+type Promotion struct {
+	ID          string        `json:"id" gorm:"unique;not null;index;primary_key"`
+	CreatedAt   time.Time     `json:"created_at"`
+	UpdatedAt   time.Time     `json:"updated_at"`
+	DeletedAt   *time.Time    `json:"deleted_at" gorm:"index"`
+	Code        string        `json:"code" gorm:"uniqueIndex:idx_promotion_code,not null"`
+	Name        string        `json:"name"`
+	Type        PromotionType `json:"type" gorm:"not null"`
+	Percentage  uint          `json:"percentage"`
+	Discount    money.Money   `json:"discount" gorm:"embedded;embeddedPrefix:discount_"`
+	BuyQuantity uint          `json:"buy_quantity"`
+	GetQuantity uint          `json:"get_quantity"`
+	// MinOrderValue is the price the order must reach before discounts, none when zero
+	MinOrderValue money.Money `json:"min_order_value" gorm:"embedded;embeddedPrefix:min_order_value_"`
+	// UsageLimit caps the redemptions of all users, UserUsageLimit the redemptions of each
+	// user. Zero is no limit.
+	UsageLimit     uint       `json:"usage_limit"`
+	UserUsageLimit uint       `json:"user_usage_limit"`
+	UsageCount     uint       `json:"usage_count" gorm:"<-:create"`
+	StartsAt       *time.Time `json:"starts_at"`
+	EndsAt         *time.Time `json:"ends_at"`
+	Active         bool       `json:"active"`
+	// Scopes restrict the promotion to some products or categories, it applies to every line
+	// without scope
+	Scopes []*PromotionScope `json:"scopes"`
+}
+
+func (m *Promotion) BeforeCreate(tx *gorm.DB) error {
+	m.ID = uuid.New().String()
+	m.Code = NormalizeCode(m.Code)
+	return nil
+}
+
+// ValidAt reports whether the promotion can be redeemed at t
+func (m *Promotion) ValidAt(t time.Time) bool {
+	if !m.Active {
+		return false
+	}
+	if m.StartsAt != nil && t.Before(*m.StartsAt) {
+		return false
+	}
+	if m.EndsAt != nil && !t.Before(*m.EndsAt) {
+		return false
+	}
+	return true
+}
+
+// InScope reports whether a product, belonging to categoryIDs, gets the promotion. Category
+// scopes cover the subcategories too, scopeCategoryIDs holds the categories of the scopes along
+// with their descendants.
+func (m *Promotion) InScope(productID string, categoryIDs []string, scopeCategoryIDs map[string]bool) bool {
+	if len(m.Scopes) == 0 {
+		return true
+	}
+
+	for _, scope := range m.Scopes {
+		if scope.Type == ScopeTypeProduct && scope.TargetID == productID {
+			return true
+		}
+	}
+	for _, categoryID := range categoryIDs {
+		if scopeCategoryIDs[categoryID] {
+			return true
+		}
+	}
+
+	return false
+}
+
+// PromotionScope is a product or a category a promotion applies to
+type PromotionScope struct {
+	PromotionID string    `json:"-" gorm:"primary_key"`
+	Type        ScopeType `json:"type" gorm:"primary_key"`
+	TargetID    string    `json:"target_id" gorm:"primary_key"`
+}
+
+// PromotionRedemption records a promotion used by an order
+type PromotionRedemption struct {
+	ID          string      `json:"id" gorm:"unique;not null;index;primary_key"`
+	CreatedAt   time.Time   `json:"created_at"`
+	PromotionID string      `json:"promotion_id" gorm:"not null;index"`
+	UserID      string      `json:"user_id" gorm:"not null"`
+	OrderID     string      `json:"order_id" gorm:"not null"`
+	Amount      money.Money `json:"amount" gorm:"embedded"`
+}
+
+func (m *PromotionRedemption) BeforeCreate(tx *gorm.DB) error {
+	m.ID = uuid.New().String()
+	return nil
+}
+
+// NormalizeCode returns code as stored, coupon codes are not case sensitive
+func NormalizeCode(code string) string {
+	return strings.ToUpper(strings.TrimSpace(code))
+}
@@ -0,0 +1,147 @@
+package http
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/quangdangfit/gocommon/logger"
+
+	"goshop/internal/promotion/dto"
+	"goshop/internal/promotion/service"
+	"goshop/pkg/response"
+	"goshop/pkg/utils"
+)
+
+type PromotionHandler struct {
+	service service.IPromotionService
+}
+
+func NewPromotionHandler(service service.IPromotionService) *PromotionHandler {
+	return &PromotionHandler{
+		service: service,
+	}
+}
+
+// GetPromotionByID godoc
+//
+//	@Summary	Get promotion by id
+//	@Tags		promotions
+//	@Produce	json
+//	@Security	ApiKeyAuth
+//	@Param		id	path		string	true	"Promotion ID"
+//	@Success	200	{object}	dto.Promotion
+//	@Router		/api/v1/promotions/{id} [get]
+func (h *PromotionHandler) GetPromotionByID(c *gin.Context) {
+	promotion, err := h.service.GetPromotionByID(c, c.Param("id"))
+	if err != nil {
+		logger.Error("Failed to get promotion detail: ", err)
+		response.Error(c, http.StatusNotFound, err, "Not found")
+		return
+	}
+
+	var res dto.Promotion
+	utils.Copy(&res, &promotion)
+	response.JSON(c, http.StatusOK, res)
+}
+
+// ListPromotions godoc
+//
+//	@Summary	Get list promotions
+//	@Tags		promotions
+//	@Produce	json
+//	@Security	ApiKeyAuth
+//	@Param		_	query		dto.ListPromotionReq	true	"Query"
+//	@Success	200	{object}	dto.ListPromotionRes
+//	@Router		/api/v1/promotions [get]
+func (h *PromotionHandler) ListPromotions(c *gin.Context) {
+	var req dto.ListPromotionReq
+	if err := c.ShouldBindQuery(&req); err != nil {
+		logger.Error("Failed to parse request query: ", err)
+		response.Error(c, http.StatusBadRequest, err, "Invalid parameters")
+		return
+	}
+
+	promotions, pagination, err := h.service.ListPromotions(c, &req)
+	if err != nil {
+		logger.Error("Failed to get list promotions: ", err)
+		if errors.Is(err, service.ErrInvalidFilter) {
+			response.Error(c, http.StatusBadRequest, err, err.Error())
+			return
+		}
+		response.Error(c, http.StatusInternalServerError, err, "Something went wrong")
+		return
+	}
+
+	var res dto.ListPromotionRes
+	utils.Copy(&res.Promotions, &promotions)
+	res.Pagination = pagination
+	response.JSON(c, http.StatusOK, res)
+}
+
+// CreatePromotion godoc
+//
+//	@Summary	create promotion
+//	@Tags		promotions
+//	@Produce	json
+//	@Security	ApiKeyAuth
+//	@Param		_	body		dto.CreatePromotionReq	true	"Body"
+//	@Success	200	{object}	dto.Promotion
+//	@Router		/api/v1/promotions [post]
+func (h *PromotionHandler) CreatePromotion(c *gin.Context) {
+	var req dto.CreatePromotionReq
+	if err := c.ShouldBindJSON(&req); c.Request.Body == nil || err != nil {
+		logger.Error("Failed to get body", err)
+		response.Error(c, http.StatusBadRequest, err, "Invalid parameters")
+		return
+	}
+
+	promotion, err := h.service.Create(c, &req)
+	if err != nil {
+		logger.Error("Failed to create promotion", err.Error())
+		if errors.Is(err, service.ErrInvalidPromotion) {
+			response.Error(c, http.StatusBadRequest, err, err.Error())
+			return
+		}
+		response.Error(c, http.StatusInternalServerError, err, "Something went wrong")
+		return
+	}
+
+	var res dto.Promotion
+	utils.Copy(&res, &promotion)
+	response.JSON(c, http.StatusOK, res)
+}
+
+// UpdatePromotion godoc
+//
+//	@Summary	update promotion
+//	@Tags		promotions
+//	@Produce	json
+//	@Security	ApiKeyAuth
+//	@Param		id	path		string					true	"Promotion ID"
+//	@Param		_	body		dto.UpdatePromotionReq	true	"Body"
+//	@Success	200	{object}	dto.Promotion
+//	@Router		/api/v1/promotions/{id} [put]
+func (h *PromotionHandler) UpdatePromotion(c *gin.Context) {
+	var req dto.UpdatePromotionReq
+	if err := c.ShouldBindJSON(&req); c.Request.Body == nil || err != nil {
+		logger.Error("Failed to get body", err)
+		response.Error(c, http.StatusBadRequest, err, "Invalid parameters")
+		return
+	}
+
+	promotion, err := h.service.Update(c, c.Param("id"), &req)
+	if err != nil {
+		logger.Error("Failed to update promotion", err.Error())
+		if errors.Is(err, service.ErrInvalidPromotion) {
+			response.Error(c, http.StatusBadRequest, err, err.Error())
+			return
+		}
+		response.Error(c, http.StatusInternalServerError, err, "Something went wrong")
+		return
+	}
+
+	var res dto.Promotion
+	utils.Copy(&res, &promotion)
+	response.JSON(c, http.StatusOK, res)
+}
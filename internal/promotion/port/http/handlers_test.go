@@ -0,0 +1,205 @@
+package http
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/quangdangfit/gocommon/logger"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+
+	"goshop/internal/promotion/dto"
+	"goshop/internal/promotion/model"
+	"goshop/internal/promotion/service"
+	srvMocks "goshop/internal/promotion/service/mocks"
+	"goshop/pkg/config"
+	"goshop/pkg/money"
+	"goshop/pkg/paging"
+	"goshop/pkg/response"
+	"goshop/pkg/utils"
+)
+
+type PromotionHandlerTestSuite struct {
+	suite.Suite
+	mockService *srvMocks.IPromotionService
+	handler     *PromotionHandler
+}
+
+func (suite *PromotionHandlerTestSuite) SetupTest() {
+	logger.Initialize(config.ProductionEnv)
+
+	suite.mockService = srvMocks.NewIPromotionService(suite.T())
+	suite.handler = NewPromotionHandler(suite.mockService)
+}
+
+func TestPromotionHandlerTestSuite(t *testing.T) {
+	suite.Run(t, new(PromotionHandlerTestSuite))
+}
+
+func (suite *PromotionHandlerTestSuite) prepareContext(path string, body any) (*gin.Context, *httptest.ResponseRecorder) {
+	requestBody, _ := json.Marshal(body)
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest("GET", path, bytes.NewBuffer(requestBody))
+	c, _ := gin.CreateTestContext(w)
+	c.Request = r
+
+	return c, w
+}
+
+// GetPromotionByID
+// =================================================================================================
+
+func (suite *PromotionHandlerTestSuite) TestGetPromotionByIDSuccess() {
+	ctx, writer := suite.prepareContext("/api/v1/promotions/promotionID", nil)
+	ctx.AddParam("id", "promotionID")
+
+	suite.mockService.On("GetPromotionByID", mock.Anything, "promotionID").
+		Return(&model.Promotion{
+			ID:       "promotionID",
+			Code:     "SAVE5",
+			Type:     model.PromotionTypeFixed,
+			Discount: money.New(500, "USD"),
+			Active:   true,
+		}, nil).Times(1)
+
+	suite.handler.GetPromotionByID(ctx)
+
+	var res response.Response
+	_ = json.Unmarshal(writer.Body.Bytes(), &res)
+	var promotion dto.Promotion
+	utils.Copy(&promotion, &res.Result)
+	suite.Equal(http.StatusOK, writer.Code)
+	suite.Equal("SAVE5", promotion.Code)
+	suite.Equal("fixed", promotion.Type)
+	suite.Equal(money.New(500, "USD"), promotion.Discount)
+}
+
+func (suite *PromotionHandlerTestSuite) TestGetPromotionByIDNotFound() {
+	ctx, writer := suite.prepareContext("/api/v1/promotions/promotionID", nil)
+	ctx.AddParam("id", "promotionID")
+
+	suite.mockService.On("GetPromotionByID", mock.Anything, "promotionID").
+		Return(nil, errors.New("error")).Times(1)
+
+	suite.handler.GetPromotionByID(ctx)
+	suite.Equal(http.StatusNotFound, writer.Code)
+}
+
+// ListPromotions
+// =================================================================================================
+
+func (suite *PromotionHandlerTestSuite) TestListPromotionsSuccess() {
+	ctx, writer := suite.prepareContext("/api/v1/promotions?code=save5&active=true", nil)
+
+	suite.mockService.On("ListPromotions", mock.Anything, mock.Anything).
+		Return([]*model.Promotion{{ID: "promotionID", Code: "SAVE5"}}, &paging.Pagination{Total: 1}, nil).Times(1)
+
+	suite.handler.ListPromotions(ctx)
+
+	var res response.Response
+	_ = json.Unmarshal(writer.Body.Bytes(), &res)
+	var list dto.ListPromotionRes
+	utils.Copy(&list, &res.Result)
+	suite.Equal(http.StatusOK, writer.Code)
+	suite.Equal(1, len(list.Promotions))
+	suite.Equal(int64(1), list.Pagination.Total)
+}
+
+func (suite *PromotionHandlerTestSuite) TestListPromotionsFail() {
+	ctx, writer := suite.prepareContext("/api/v1/promotions", nil)
+
+	suite.mockService.On("ListPromotions", mock.Anything, mock.Anything).
+		Return(nil, nil, errors.New("error")).Times(1)
+
+	suite.handler.ListPromotions(ctx)
+	suite.Equal(http.StatusInternalServerError, writer.Code)
+}
+
+func (suite *PromotionHandlerTestSuite) TestListPromotionsInvalidFilter() {
+	ctx, writer := suite.prepareContext("/api/v1/promotions?order_by=id;drop", nil)
+
+	suite.mockService.On("ListPromotions", mock.Anything, mock.Anything).
+		Return(nil, nil, service.ErrInvalidFilter).Times(1)
+
+	suite.handler.ListPromotions(ctx)
+	suite.Equal(http.StatusBadRequest, writer.Code)
+}
+
+// CreatePromotion
+// =================================================================================================
+
+func (suite *PromotionHandlerTestSuite) TestCreatePromotionSuccess() {
+	req := &dto.CreatePromotionReq{Code: "SAVE10", Name: "Save 10%", Type: "percentage", Percentage: 10}
+	ctx, writer := suite.prepareContext("/api/v1/promotions", req)
+
+	suite.mockService.On("Create", mock.Anything, req).
+		Return(&model.Promotion{ID: "promotionID", Code: "SAVE10"}, nil).Times(1)
+
+	suite.handler.CreatePromotion(ctx)
+	suite.Equal(http.StatusOK, writer.Code)
+}
+
+func (suite *PromotionHandlerTestSuite) TestCreatePromotionInvalidBody() {
+	ctx, writer := suite.prepareContext("/api/v1/promotions", map[string]any{"percentage": "ten"})
+
+	suite.handler.CreatePromotion(ctx)
+	suite.Equal(http.StatusBadRequest, writer.Code)
+}
+
+func (suite *PromotionHandlerTestSuite) TestCreatePromotionInvalidPromotion() {
+	req := &dto.CreatePromotionReq{Code: "SAVE10", Name: "Save 10%", Type: "percentage"}
+	ctx, writer := suite.prepareContext("/api/v1/promotions", req)
+
+	suite.mockService.On("Create", mock.Anything, req).
+		Return(nil, fmt.Errorf("%w: percentage must be between 1 and 100", service.ErrInvalidPromotion)).Times(1)
+
+	suite.handler.CreatePromotion(ctx)
+
+	var res map[string]map[string]string
+	_ = json.Unmarshal(writer.Body.Bytes(), &res)
+	suite.Equal(http.StatusBadRequest, writer.Code)
+	suite.Equal("invalid promotion: percentage must be between 1 and 100", res["error"]["message"])
+}
+
+func (suite *PromotionHandlerTestSuite) TestCreatePromotionFail() {
+	req := &dto.CreatePromotionReq{Code: "SAVE10", Name: "Save 10%", Type: "percentage", Percentage: 10}
+	ctx, writer := suite.prepareContext("/api/v1/promotions", req)
+
+	suite.mockService.On("Create", mock.Anything, req).Return(nil, errors.New("error")).Times(1)
+
+	suite.handler.CreatePromotion(ctx)
+	suite.Equal(http.StatusInternalServerError, writer.Code)
+}
+
+// UpdatePromotion
+// =================================================================================================
+
+func (suite *PromotionHandlerTestSuite) TestUpdatePromotionSuccess() {
+	active := false
+	req := &dto.UpdatePromotionReq{Active: &active}
+	ctx, writer := suite.prepareContext("/api/v1/promotions/promotionID", req)
+	ctx.AddParam("id", "promotionID")
+
+	suite.mockService.On("Update", mock.Anything, "promotionID", req).
+		Return(&model.Promotion{ID: "promotionID", Code: "SAVE10"}, nil).Times(1)
+
+	suite.handler.UpdatePromotion(ctx)
+	suite.Equal(http.StatusOK, writer.Code)
+}
+
+func (suite *PromotionHandlerTestSuite) TestUpdatePromotionFail() {
+	req := &dto.UpdatePromotionReq{Name: "Renamed"}
+	ctx, writer := suite.prepareContext("/api/v1/promotions/promotionID", req)
+	ctx.AddParam("id", "promotionID")
+
+	suite.mockService.On("Update", mock.Anything, "promotionID", req).Return(nil, errors.New("error")).Times(1)
+
+	suite.handler.UpdatePromotion(ctx)
+	suite.Equal(http.StatusInternalServerError, writer.Code)
+}
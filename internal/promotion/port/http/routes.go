@@ -0,0 +1,35 @@
+package http
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/quangdangfit/gocommon/validation"
+
+	"goshop/internal/promotion/repository"
+	"goshop/internal/promotion/service"
+	"goshop/pkg/dbs"
+	"goshop/pkg/idempotency"
+	"goshop/pkg/middleware"
+	"goshop/pkg/rbac"
+)
+
+func Routes(
+	r *gin.RouterGroup,
+	db dbs.IDatabase,
+	validator validation.Validation,
+	idempotencyStore idempotency.Store,
+) {
+	promotionRepo := repository.NewPromotionRepository(db)
+	promotionSvc := service.NewPromotionService(validator, promotionRepo)
+	promotionHandler := NewPromotionHandler(promotionSvc)
+
+	authMiddleware := middleware.JWTAuth()
+	idempotencyMiddleware := middleware.Idempotency(idempotencyStore)
+
+	promotionRoute := r.Group("/promotions", authMiddleware)
+	{
+		promotionRoute.GET("", middleware.RequirePermission(rbac.PermissionPromotionRead), promotionHandler.ListPromotions)
+		promotionRoute.POST("", idempotencyMiddleware, middleware.RequirePermission(rbac.PermissionPromotionWrite), promotionHandler.CreatePromotion)
+		promotionRoute.GET("/:id", middleware.RequirePermission(rbac.PermissionPromotionRead), promotionHandler.GetPromotionByID)
+		promotionRoute.PUT("/:id", idempotencyMiddleware, middleware.RequirePermission(rbac.PermissionPromotionWrite), promotionHandler.UpdatePromotion)
+	}
+}
@@ -0,0 +1,222 @@
+// Code generated by mockery v2.32.4. DO NOT EDIT.
+
+package mocks
+
+import (
+	context "context"
+	dto "goshop/internal/promotion/dto"
+
+	mock "github.com/stretchr/testify/mock"
+
+	model "goshop/internal/promotion/model"
+
+	paging "goshop/pkg/paging"
+)
+
+// IPromotionRepository is an autogenerated mock type for the IPromotionRepository type
+type IPromotionRepository struct {
+	mock.Mock
+}
+
+// CountUserRedemptions provides a mock function with given fields: ctx, promotionID, userID
+func (_m *IPromotionRepository) CountUserRedemptions(ctx context.Context, promotionID string, userID string) (int64, error) {
+	ret := _m.Called(ctx, promotionID, userID)
+
+	var r0 int64
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) (int64, error)); ok {
+		return rf(ctx, promotionID, userID)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) int64); ok {
+		r0 = rf(ctx, promotionID, userID)
+	} else {
+		r0 = ret.Get(0).(int64)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, string) error); ok {
+		r1 = rf(ctx, promotionID, userID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Create provides a mock function with given fields: ctx, promotion
+func (_m *IPromotionRepository) Create(ctx context.Context, promotion *model.Promotion) error {
+	ret := _m.Called(ctx, promotion)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *model.Promotion) error); ok {
+		r0 = rf(ctx, promotion)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// GetDescendantCategoryIDs provides a mock function with given fields: ctx, ids
+func (_m *IPromotionRepository) GetDescendantCategoryIDs(ctx context.Context, ids []string) ([]string, error) {
+	ret := _m.Called(ctx, ids)
+
+	var r0 []string
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, []string) ([]string, error)); ok {
+		return rf(ctx, ids)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, []string) []string); ok {
+		r0 = rf(ctx, ids)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]string)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, []string) error); ok {
+		r1 = rf(ctx, ids)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetPromotionByID provides a mock function with given fields: ctx, id
+func (_m *IPromotionRepository) GetPromotionByID(ctx context.Context, id string) (*model.Promotion, error) {
+	ret := _m.Called(ctx, id)
+
+	var r0 *model.Promotion
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) (*model.Promotion, error)); ok {
+		return rf(ctx, id)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string) *model.Promotion); ok {
+		r0 = rf(ctx, id)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*model.Promotion)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, id)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetPromotionsByCodes provides a mock function with given fields: ctx, codes
+func (_m *IPromotionRepository) GetPromotionsByCodes(ctx context.Context, codes []string) ([]*model.Promotion, error) {
+	ret := _m.Called(ctx, codes)
+
+	var r0 []*model.Promotion
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, []string) ([]*model.Promotion, error)); ok {
+		return rf(ctx, codes)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, []string) []*model.Promotion); ok {
+		r0 = rf(ctx, codes)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*model.Promotion)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, []string) error); ok {
+		r1 = rf(ctx, codes)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// ListPromotions provides a mock function with given fields: ctx, req
+func (_m *IPromotionRepository) ListPromotions(ctx context.Context, req *dto.ListPromotionReq) ([]*model.Promotion, *paging.Pagination, error) {
+	ret := _m.Called(ctx, req)
+
+	var r0 []*model.Promotion
+	var r1 *paging.Pagination
+	var r2 error
+	if rf, ok := ret.Get(0).(func(context.Context, *dto.ListPromotionReq) ([]*model.Promotion, *paging.Pagination, error)); ok {
+		return rf(ctx, req)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, *dto.ListPromotionReq) []*model.Promotion); ok {
+		r0 = rf(ctx, req)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*model.Promotion)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, *dto.ListPromotionReq) *paging.Pagination); ok {
+		r1 = rf(ctx, req)
+	} else {
+		if ret.Get(1) != nil {
+			r1 = ret.Get(1).(*paging.Pagination)
+		}
+	}
+
+	if rf, ok := ret.Get(2).(func(context.Context, *dto.ListPromotionReq) error); ok {
+		r2 = rf(ctx, req)
+	} else {
+		r2 = ret.Error(2)
+	}
+
+	return r0, r1, r2
+}
+
+// Redeem provides a mock function with given fields: ctx, redemption, userUsageLimit
+func (_m *IPromotionRepository) Redeem(ctx context.Context, redemption *model.PromotionRedemption, userUsageLimit uint) (bool, error) {
+	ret := _m.Called(ctx, redemption, userUsageLimit)
+
+	var r0 bool
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, *model.PromotionRedemption, uint) (bool, error)); ok {
+		return rf(ctx, redemption, userUsageLimit)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, *model.PromotionRedemption, uint) bool); ok {
+		r0 = rf(ctx, redemption, userUsageLimit)
+	} else {
+		r0 = ret.Get(0).(bool)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, *model.PromotionRedemption, uint) error); ok {
+		r1 = rf(ctx, redemption, userUsageLimit)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Update provides a mock function with given fields: ctx, promotion
+func (_m *IPromotionRepository) Update(ctx context.Context, promotion *model.Promotion) error {
+	ret := _m.Called(ctx, promotion)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *model.Promotion) error); ok {
+		r0 = rf(ctx, promotion)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// NewIPromotionRepository creates a new instance of IPromotionRepository. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewIPromotionRepository(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *IPromotionRepository {
+	mock := &IPromotionRepository{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
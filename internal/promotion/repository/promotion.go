@@ -0,0 +1,221 @@
+package repository
+
+import (
+	"context"
+	"errors"
+
+	"goshop/internal/promotion/dto"
+	"goshop/internal/promotion/model"
+	"goshop/pkg/dbs"
+	"goshop/pkg/paging"
+)
+
+// descendantCategoriesQuery selects the ids of categories and of their descendants. UNION drops
+// the rows already selected, so the query ends even if the categories somehow form a cycle.
+const descendantCategoriesQuery = "WITH RECURSIVE tree AS (" +
+	"SELECT id FROM categories WHERE id IN ? " +
+	"UNION SELECT categories.id FROM categories JOIN tree ON categories.parent_id = tree.id" +
+	") SELECT id FROM tree"
+
+//go:generate mockery --name=IPromotionRepository
+type IPromotionRepository interface {
+	Create(ctx context.Context, promotion *model.Promotion) error
+	Update(ctx context.Context, promotion *model.Promotion) error
+	GetPromotionByID(ctx context.Context, id string) (*model.Promotion, error)
+	GetPromotionsByCodes(ctx context.Context, codes []string) ([]*model.Promotion, error)
+	ListPromotions(ctx context.Context, req *dto.ListPromotionReq) ([]*model.Promotion, *paging.Pagination, error)
+	GetDescendantCategoryIDs(ctx context.Context, ids []string) ([]string, error)
+	CountUserRedemptions(ctx context.Context, promotionID, userID string) (int64, error)
+	Redeem(ctx context.Context, redemption *model.PromotionRedemption, userUsageLimit uint) (bool, error)
+}
+
+type PromotionRepo struct {
+	db dbs.IDatabase
+}
+
+func NewPromotionRepository(db dbs.IDatabase) *PromotionRepo {
+	return &PromotionRepo{db: db}
+}
+
+func (r *PromotionRepo) Create(ctx context.Context, promotion *model.Promotion) error {
+	return r.db.Create(ctx, promotion)
+}
+
+// Update saves the promotion and replaces its scopes
+func (r *PromotionRepo) Update(ctx context.Context, promotion *model.Promotion) error {
+	return r.db.WithTransaction(ctx, func(ctx context.Context) error {
+		if err := r.db.Update(ctx, promotion); err != nil {
+			return err
+		}
+
+		err := r.db.Delete(ctx, &model.PromotionScope{}, dbs.WithQuery(dbs.NewQuery("promotion_id = ?", promotion.ID)))
+		if err != nil {
+			return err
+		}
+
+		if len(promotion.Scopes) == 0 {
+			return nil
+		}
+		for _, scope := range promotion.Scopes {
+			scope.PromotionID = promotion.ID
+		}
+		return r.db.CreateInBatches(ctx, promotion.Scopes, len(promotion.Scopes))
+	})
+}
+
+func (r *PromotionRepo) GetPromotionByID(ctx context.Context, id string) (*model.Promotion, error) {
+	var promotion model.Promotion
+	opts := []dbs.FindOption{
+		dbs.WithQuery(dbs.NewQuery("id = ?", id)),
+		dbs.WithPreload([]string{"Scopes"}),
+	}
+	if err := r.db.FindOne(ctx, &promotion, opts...); err != nil {
+		return nil, err
+	}
+
+	return &promotion, nil
+}
+
+func (r *PromotionRepo) GetPromotionsByCodes(ctx context.Context, codes []string) ([]*model.Promotion, error) {
+	var promotions []*model.Promotion
+	opts := []dbs.FindOption{
+		dbs.WithQuery(dbs.NewQuery("code IN ?", codes)),
+		dbs.WithPreload([]string{"Scopes"}),
+	}
+	if err := r.db.Find(ctx, &promotions, opts...); err != nil {
+		return nil, err
+	}
+
+	return promotions, nil
+}
+
+// promotionSortColumns are the columns promotions can be sorted by, by their name in order_by
+var promotionSortColumns = map[string]string{
+	"created_at": "created_at",
+	"updated_at": "updated_at",
+	"code":       "code",
+	"name":       "name",
+	"starts_at":  "starts_at",
+	"ends_at":    "ends_at",
+}
+
+func (r *PromotionRepo) ListPromotions(ctx context.Context, req *dto.ListPromotionReq) ([]*model.Promotion, *paging.Pagination, error) {
+	query := make([]dbs.Query, 0)
+	if req.Code != "" {
+		query = append(query, dbs.NewQuery("code = ?", model.NormalizeCode(req.Code)))
+	}
+	if req.Active != nil {
+		query = append(query, dbs.NewQuery("active = ?", *req.Active))
+	}
+
+	order := "created_at"
+	if column, ok := promotionSortColumns[req.OrderBy]; ok {
+		order = column
+		if req.OrderDesc {
+			order += " DESC"
+		}
+	}
+
+	var total int64
+	if err := r.db.Count(ctx, &model.Promotion{}, &total, dbs.WithQuery(query...)); err != nil {
+		return nil, nil, err
+	}
+
+	pagination := paging.New(req.Page, req.Limit, total)
+
+	var promotions []*model.Promotion
+	if err := r.db.Find(
+		ctx,
+		&promotions,
+		dbs.WithQuery(query...),
+		dbs.WithLimit(int(pagination.Limit)),
+		dbs.WithOffset(int(pagination.Skip)),
+		dbs.WithOrder(order),
+		dbs.WithPreload([]string{"Scopes"}),
+	); err != nil {
+		return nil, nil, err
+	}
+
+	return promotions, pagination, nil
+}
+
+// GetDescendantCategoryIDs returns ids and the ids of all of the descendants of their categories
+func (r *PromotionRepo) GetDescendantCategoryIDs(ctx context.Context, ids []string) ([]string, error) {
+	var descendants []string
+	query := dbs.NewQuery("id IN ("+descendantCategoriesQuery+")", ids)
+	opts := []dbs.FindOption{
+		dbs.WithTable("categories"),
+		dbs.WithSelect("id"),
+		dbs.WithQuery(query),
+		dbs.WithLimit(-1),
+	}
+	if err := r.db.Find(ctx, &descendants, opts...); err != nil {
+		return nil, err
+	}
+
+	return descendants, nil
+}
+
+func (r *PromotionRepo) CountUserRedemptions(ctx context.Context, promotionID, userID string) (int64, error) {
+	var total int64
+	query := dbs.WithQuery(
+		dbs.NewQuery("promotion_id = ?", promotionID),
+		dbs.NewQuery("user_id = ?", userID),
+	)
+	if err := r.db.Count(ctx, &model.PromotionRedemption{}, &total, query); err != nil {
+		return 0, err
+	}
+
+	return total, nil
+}
+
+// errUserUsageLimitReached rolls back the use counted by Redeem
+var errUserUsageLimitReached = errors.New("user usage limit reached")
+
+// Redeem counts a use of the promotion and records it. The count is checked against the usage
+// limit by the database, so that concurrent orders cannot use the promotion more than allowed.
+// Counting the use locks the promotion until the end of the transaction, so the redemptions of
+// the user, limited by userUsageLimit, are counted once those of concurrent orders are recorded.
+// It reports false, recording nothing, when a limit was reached.
+func (r *PromotionRepo) Redeem(ctx context.Context, redemption *model.PromotionRedemption, userUsageLimit uint) (bool, error) {
+	redeemed := false
+	err := r.db.WithTransaction(ctx, func(ctx context.Context) error {
+		updated, err := r.db.UpdateColumns(
+			ctx,
+			&model.Promotion{},
+			map[string]any{"usage_count": dbs.Expr("usage_count + 1")},
+			dbs.WithQuery(
+				dbs.NewQuery("id = ?", redemption.PromotionID),
+				dbs.NewQuery("(usage_limit = 0 OR usage_count < usage_limit)"),
+			),
+		)
+		if err != nil || updated == 0 {
+			return err
+		}
+
+		if userUsageLimit > 0 {
+			count, err := r.CountUserRedemptions(ctx, redemption.PromotionID, redemption.UserID)
+			if err != nil {
+				return err
+			}
+			if count >= int64(userUsageLimit) {
+				return errUserUsageLimitReached
+			}
+		}
+
+		if err := r.db.Create(ctx, redemption); err != nil {
+			return err
+		}
+
+		redeemed = true
+		return nil
+	})
+	if errors.Is(err, errUserUsageLimitReached) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+
+	return redeemed, nil
+}
@@ -0,0 +1,275 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/quangdangfit/gocommon/logger"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+
+	"goshop/internal/promotion/dto"
+	"goshop/internal/promotion/model"
+	"goshop/pkg/config"
+	"goshop/pkg/dbs"
+	"goshop/pkg/dbs/mocks"
+	"goshop/pkg/money"
+)
+
+type PromotionRepositoryTestSuite struct {
+	suite.Suite
+	mockDB *mocks.IDatabase
+	repo   IPromotionRepository
+}
+
+func (suite *PromotionRepositoryTestSuite) SetupTest() {
+	logger.Initialize(config.ProductionEnv)
+
+	suite.mockDB = mocks.NewIDatabase(suite.T())
+	suite.repo = NewPromotionRepository(suite.mockDB)
+}
+
+func TestPromotionRepositoryTestSuite(t *testing.T) {
+	suite.Run(t, new(PromotionRepositoryTestSuite))
+}
+
+func (suite *PromotionRepositoryTestSuite) expectTransaction() {
+	suite.mockDB.On("WithTransaction", mock.Anything, mock.Anything).
+		Return(func(ctx context.Context, function func(ctx context.Context) error) error {
+			return function(ctx)
+		}).Times(1)
+}
+
+// Create
+// =================================================================
+
+func (suite *PromotionRepositoryTestSuite) TestCreateSuccessfully() {
+	promotion := &model.Promotion{Code: "SAVE10"}
+	suite.mockDB.On("Create", mock.Anything, promotion).Return(nil).Times(1)
+
+	err := suite.repo.Create(context.Background(), promotion)
+	suite.Nil(err)
+}
+
+// Update
+// =================================================================
+
+func (suite *PromotionRepositoryTestSuite) TestUpdateReplacesScopes() {
+	promotion := &model.Promotion{
+		ID:     "promotionID",
+		Scopes: []*model.PromotionScope{{Type: model.ScopeTypeProduct, TargetID: "productID"}},
+	}
+	suite.expectTransaction()
+	suite.mockDB.On("Update", mock.Anything, promotion).Return(nil).Times(1)
+	suite.mockDB.On("Delete", mock.Anything, &model.PromotionScope{}, mock.Anything).Return(nil).Times(1)
+	suite.mockDB.On("CreateInBatches", mock.Anything, promotion.Scopes, 1).Return(nil).Times(1)
+
+	err := suite.repo.Update(context.Background(), promotion)
+	suite.Nil(err)
+	suite.Equal("promotionID", promotion.Scopes[0].PromotionID)
+}
+
+func (suite *PromotionRepositoryTestSuite) TestUpdateWithoutScopes() {
+	promotion := &model.Promotion{ID: "promotionID"}
+	suite.expectTransaction()
+	suite.mockDB.On("Update", mock.Anything, promotion).Return(nil).Times(1)
+	suite.mockDB.On("Delete", mock.Anything, &model.PromotionScope{}, mock.Anything).Return(nil).Times(1)
+
+	err := suite.repo.Update(context.Background(), promotion)
+	suite.Nil(err)
+}
+
+func (suite *PromotionRepositoryTestSuite) TestUpdateFail() {
+	promotion := &model.Promotion{ID: "promotionID"}
+	suite.expectTransaction()
+	suite.mockDB.On("Update", mock.Anything, promotion).Return(errors.New("error")).Times(1)
+
+	err := suite.repo.Update(context.Background(), promotion)
+	suite.NotNil(err)
+}
+
+// GetPromotionByID
+// =================================================================
+
+func (suite *PromotionRepositoryTestSuite) TestGetPromotionByIDSuccessfully() {
+	suite.mockDB.On("FindOne", mock.Anything, &model.Promotion{}, mock.Anything, mock.Anything).
+		Return(nil).Times(1)
+
+	promotion, err := suite.repo.GetPromotionByID(context.Background(), "promotionID")
+	suite.Nil(err)
+	suite.NotNil(promotion)
+}
+
+func (suite *PromotionRepositoryTestSuite) TestGetPromotionByIDFail() {
+	suite.mockDB.On("FindOne", mock.Anything, &model.Promotion{}, mock.Anything, mock.Anything).
+		Return(errors.New("error")).Times(1)
+
+	promotion, err := suite.repo.GetPromotionByID(context.Background(), "promotionID")
+	suite.NotNil(err)
+	suite.Nil(promotion)
+}
+
+// GetPromotionsByCodes
+// =================================================================
+
+func (suite *PromotionRepositoryTestSuite) TestGetPromotionsByCodesFail() {
+	suite.mockDB.On("Find", mock.Anything, mock.Anything, mock.Anything, mock.Anything).
+		Return(errors.New("error")).Times(1)
+
+	promotions, err := suite.repo.GetPromotionsByCodes(context.Background(), []string{"SAVE10"})
+	suite.NotNil(err)
+	suite.Nil(promotions)
+}
+
+// ListPromotions
+// =================================================================
+
+func (suite *PromotionRepositoryTestSuite) TestListPromotionsSuccessfully() {
+	active := true
+	req := &dto.ListPromotionReq{
+		Code:      "save10",
+		Active:    &active,
+		Page:      2,
+		Limit:     10,
+		OrderBy:   "code",
+		OrderDesc: true,
+	}
+
+	suite.mockDB.On("Count", mock.Anything, mock.Anything, mock.Anything, mock.Anything).
+		Return(nil).Times(1)
+	suite.mockDB.On("Find", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything).
+		Return(nil).Times(1)
+
+	promotions, pagination, err := suite.repo.ListPromotions(context.Background(), req)
+	suite.Nil(err)
+	suite.Equal(0, len(promotions))
+	suite.NotNil(pagination)
+}
+
+func (suite *PromotionRepositoryTestSuite) TestListPromotionsUnknownOrder() {
+	req := &dto.ListPromotionReq{OrderBy: "created_at; DROP TABLE promotions", OrderDesc: true}
+
+	var opts []dbs.FindOption
+	suite.mockDB.On("Count", mock.Anything, mock.Anything, mock.Anything, mock.Anything).
+		Return(nil).Times(1)
+	suite.mockDB.On("Find", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything).
+		Run(func(args mock.Arguments) {
+			for _, arg := range args[2:] {
+				opts = append(opts, arg.(dbs.FindOption))
+			}
+		}).
+		Return(nil).Times(1)
+
+	_, _, err := suite.repo.ListPromotions(context.Background(), req)
+	suite.Nil(err)
+	suite.Contains(opts, dbs.WithOrder("created_at"))
+}
+
+func (suite *PromotionRepositoryTestSuite) TestListPromotionsCountFail() {
+	suite.mockDB.On("Count", mock.Anything, mock.Anything, mock.Anything, mock.Anything).
+		Return(errors.New("error")).Times(1)
+
+	promotions, pagination, err := suite.repo.ListPromotions(context.Background(), &dto.ListPromotionReq{})
+	suite.NotNil(err)
+	suite.Nil(promotions)
+	suite.Nil(pagination)
+}
+
+// GetDescendantCategoryIDs
+// =================================================================
+
+func (suite *PromotionRepositoryTestSuite) TestGetDescendantCategoryIDs() {
+	suite.mockDB.On("Find", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything).
+		Run(func(args mock.Arguments) {
+			*args.Get(1).(*[]string) = []string{"shoes", "boots"}
+		}).Return(nil).Times(1)
+
+	ids, err := suite.repo.GetDescendantCategoryIDs(context.Background(), []string{"shoes"})
+	suite.Nil(err)
+	suite.Equal([]string{"shoes", "boots"}, ids)
+}
+
+func (suite *PromotionRepositoryTestSuite) TestGetDescendantCategoryIDsFail() {
+	suite.mockDB.On("Find", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything).
+		Return(errors.New("error")).Times(1)
+
+	ids, err := suite.repo.GetDescendantCategoryIDs(context.Background(), []string{"shoes"})
+	suite.NotNil(err)
+	suite.Nil(ids)
+}
+
+// CountUserRedemptions
+// =================================================================
+
+func (suite *PromotionRepositoryTestSuite) TestCountUserRedemptions() {
+	suite.mockDB.On("Count", mock.Anything, &model.PromotionRedemption{}, mock.Anything, mock.Anything).
+		Return(func(ctx context.Context, model any, total *int64, opts ...dbs.FindOption) error {
+			*total = 2
+			return nil
+		}).Times(1)
+
+	count, err := suite.repo.CountUserRedemptions(context.Background(), "promotionID", "userID")
+	suite.Nil(err)
+	suite.Equal(int64(2), count)
+}
+
+// Redeem
+// =================================================================
+
+func (suite *PromotionRepositoryTestSuite) TestRedeemSuccessfully() {
+	redemption := &model.PromotionRedemption{
+		PromotionID: "promotionID",
+		UserID:      "userID",
+		OrderID:     "orderID",
+		Amount:      money.New(100, "USD"),
+	}
+	suite.expectTransaction()
+	suite.mockDB.On("UpdateColumns", mock.Anything, &model.Promotion{}, mock.Anything, mock.Anything).
+		Return(int64(1), nil).Times(1)
+	suite.mockDB.On("Count", mock.Anything, &model.PromotionRedemption{}, mock.Anything, mock.Anything).
+		Run(func(args mock.Arguments) {
+			*args.Get(2).(*int64) = 1
+		}).Return(nil).Times(1)
+	suite.mockDB.On("Create", mock.Anything, redemption).Return(nil).Times(1)
+
+	ok, err := suite.repo.Redeem(context.Background(), redemption, 2)
+	suite.Nil(err)
+	suite.True(ok)
+}
+
+func (suite *PromotionRepositoryTestSuite) TestRedeemLimitReached() {
+	suite.expectTransaction()
+	suite.mockDB.On("UpdateColumns", mock.Anything, &model.Promotion{}, mock.Anything, mock.Anything).
+		Return(int64(0), nil).Times(1)
+
+	ok, err := suite.repo.Redeem(context.Background(), &model.PromotionRedemption{PromotionID: "promotionID"}, 0)
+	suite.Nil(err)
+	suite.False(ok)
+}
+
+func (suite *PromotionRepositoryTestSuite) TestRedeemUserLimitReached() {
+	suite.expectTransaction()
+	suite.mockDB.On("UpdateColumns", mock.Anything, &model.Promotion{}, mock.Anything, mock.Anything).
+		Return(int64(1), nil).Times(1)
+	suite.mockDB.On("Count", mock.Anything, &model.PromotionRedemption{}, mock.Anything, mock.Anything).
+		Run(func(args mock.Arguments) {
+			*args.Get(2).(*int64) = 1
+		}).Return(nil).Times(1)
+
+	ok, err := suite.repo.Redeem(context.Background(),
+		&model.PromotionRedemption{PromotionID: "promotionID", UserID: "userID"}, 1)
+	suite.Nil(err)
+	suite.False(ok)
+	suite.mockDB.AssertNotCalled(suite.T(), "Create", mock.Anything, mock.Anything)
+}
+
+func (suite *PromotionRepositoryTestSuite) TestRedeemFail() {
+	suite.expectTransaction()
+	suite.mockDB.On("UpdateColumns", mock.Anything, &model.Promotion{}, mock.Anything, mock.Anything).
+		Return(int64(0), errors.New("error")).Times(1)
+
+	ok, err := suite.repo.Redeem(context.Background(), &model.PromotionRedemption{PromotionID: "promotionID"}, 0)
+	suite.NotNil(err)
+	suite.False(ok)
+}
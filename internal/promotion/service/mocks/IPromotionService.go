@@ -0,0 +1,188 @@
+// Code generated by mockery v2.32.4. DO NOT EDIT.
+
+package mocks
+
+import (
+	context "context"
+	dto "goshop/internal/promotion/dto"
+
+	mock "github.com/stretchr/testify/mock"
+
+	model "goshop/internal/promotion/model"
+
+	paging "goshop/pkg/paging"
+
+	service "goshop/internal/promotion/service"
+)
+
+// IPromotionService is an autogenerated mock type for the IPromotionService type
+type IPromotionService struct {
+	mock.Mock
+}
+
+// Apply provides a mock function with given fields: ctx, userID, codes, lines
+func (_m *IPromotionService) Apply(ctx context.Context, userID string, codes []string, lines []*service.Line) ([]*service.Discount, error) {
+	ret := _m.Called(ctx, userID, codes, lines)
+
+	var r0 []*service.Discount
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, []string, []*service.Line) ([]*service.Discount, error)); ok {
+		return rf(ctx, userID, codes, lines)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, []string, []*service.Line) []*service.Discount); ok {
+		r0 = rf(ctx, userID, codes, lines)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*service.Discount)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, []string, []*service.Line) error); ok {
+		r1 = rf(ctx, userID, codes, lines)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Create provides a mock function with given fields: ctx, req
+func (_m *IPromotionService) Create(ctx context.Context, req *dto.CreatePromotionReq) (*model.Promotion, error) {
+	ret := _m.Called(ctx, req)
+
+	var r0 *model.Promotion
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, *dto.CreatePromotionReq) (*model.Promotion, error)); ok {
+		return rf(ctx, req)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, *dto.CreatePromotionReq) *model.Promotion); ok {
+		r0 = rf(ctx, req)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*model.Promotion)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, *dto.CreatePromotionReq) error); ok {
+		r1 = rf(ctx, req)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetPromotionByID provides a mock function with given fields: ctx, id
+func (_m *IPromotionService) GetPromotionByID(ctx context.Context, id string) (*model.Promotion, error) {
+	ret := _m.Called(ctx, id)
+
+	var r0 *model.Promotion
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) (*model.Promotion, error)); ok {
+		return rf(ctx, id)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string) *model.Promotion); ok {
+		r0 = rf(ctx, id)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*model.Promotion)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, id)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// ListPromotions provides a mock function with given fields: ctx, req
+func (_m *IPromotionService) ListPromotions(ctx context.Context, req *dto.ListPromotionReq) ([]*model.Promotion, *paging.Pagination, error) {
+	ret := _m.Called(ctx, req)
+
+	var r0 []*model.Promotion
+	var r1 *paging.Pagination
+	var r2 error
+	if rf, ok := ret.Get(0).(func(context.Context, *dto.ListPromotionReq) ([]*model.Promotion, *paging.Pagination, error)); ok {
+		return rf(ctx, req)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, *dto.ListPromotionReq) []*model.Promotion); ok {
+		r0 = rf(ctx, req)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*model.Promotion)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, *dto.ListPromotionReq) *paging.Pagination); ok {
+		r1 = rf(ctx, req)
+	} else {
+		if ret.Get(1) != nil {
+			r1 = ret.Get(1).(*paging.Pagination)
+		}
+	}
+
+	if rf, ok := ret.Get(2).(func(context.Context, *dto.ListPromotionReq) error); ok {
+		r2 = rf(ctx, req)
+	} else {
+		r2 = ret.Error(2)
+	}
+
+	return r0, r1, r2
+}
+
+// Redeem provides a mock function with given fields: ctx, userID, orderID, discounts
+func (_m *IPromotionService) Redeem(ctx context.Context, userID string, orderID string, discounts []*service.Discount) error {
+	ret := _m.Called(ctx, userID, orderID, discounts)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, string, []*service.Discount) error); ok {
+		r0 = rf(ctx, userID, orderID, discounts)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// Update provides a mock function with given fields: ctx, id, req
+func (_m *IPromotionService) Update(ctx context.Context, id string, req *dto.UpdatePromotionReq) (*model.Promotion, error) {
+	ret := _m.Called(ctx, id, req)
+
+	var r0 *model.Promotion
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, *dto.UpdatePromotionReq) (*model.Promotion, error)); ok {
+		return rf(ctx, id, req)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, *dto.UpdatePromotionReq) *model.Promotion); ok {
+		r0 = rf(ctx, id, req)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*model.Promotion)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, *dto.UpdatePromotionReq) error); ok {
+		r1 = rf(ctx, id, req)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// NewIPromotionService creates a new instance of IPromotionService. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewIPromotionService(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *IPromotionService {
+	mock := &IPromotionService{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
@@ -0,0 +1,388 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/quangdangfit/gocommon/logger"
+	"github.com/quangdangfit/gocommon/validation"
+
+	"goshop/internal/promotion/dto"
+	"goshop/internal/promotion/model"
+	"goshop/internal/promotion/repository"
+	"goshop/pkg/money"
+	"goshop/pkg/paging"
+	"goshop/pkg/utils"
+)
+
+var (
+	ErrInvalidPromotion       = errors.New("invalid promotion")
+	ErrInvalidFilter          = errors.New("invalid filter")
+	ErrPromotionNotFound      = errors.New("promotion not found")
+	ErrPromotionInactive      = errors.New("promotion is not active")
+	ErrPromotionNotApplicable = errors.New("promotion does not apply to the order")
+	ErrMinOrderValue          = errors.New("order does not reach the minimum value of the promotion")
+	ErrUsageLimitReached      = errors.New("promotion usage limit reached")
+)
+
+// Line is an order line a promotion may discount
+type Line struct {
+	ProductID   string
+	CategoryIDs []string
+	Quantity    uint
+	// Price is the price of the whole line
+	Price money.Money
+	// Discount is taken off Price by the promotions applied so far
+	Discount money.Money
+}
+
+// Discount is the amount a promotion takes off an order
+type Discount struct {
+	Promotion *model.Promotion
+	Amount    money.Money
+}
+
+//go:generate mockery --name=IPromotionService
+type IPromotionService interface {
+	ListPromotions(ctx context.Context, req *dto.ListPromotionReq) ([]*model.Promotion, *paging.Pagination, error)
+	GetPromotionByID(ctx context.Context, id string) (*model.Promotion, error)
+	Create(ctx context.Context, req *dto.CreatePromotionReq) (*model.Promotion, error)
+	Update(ctx context.Context, id string, req *dto.UpdatePromotionReq) (*model.Promotion, error)
+	Apply(ctx context.Context, userID string, codes []string, lines []*Line) ([]*Discount, error)
+	Redeem(ctx context.Context, userID, orderID string, discounts []*Discount) error
+}
+
+type PromotionService struct {
+	validator validation.Validation
+	repo      repository.IPromotionRepository
+}
+
+func NewPromotionService(
+	validator validation.Validation,
+	repo repository.IPromotionRepository,
+) *PromotionService {
+	return &PromotionService{
+		validator: validator,
+		repo:      repo,
+	}
+}
+
+func (s *PromotionService) ListPromotions(ctx context.Context, req *dto.ListPromotionReq) ([]*model.Promotion, *paging.Pagination, error) {
+	if err := s.validator.ValidateStruct(req); err != nil {
+		return nil, nil, fmt.Errorf("%w: %s", ErrInvalidFilter, err)
+	}
+
+	promotions, pagination, err := s.repo.ListPromotions(ctx, req)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return promotions, pagination, nil
+}
+
+func (s *PromotionService) GetPromotionByID(ctx context.Context, id string) (*model.Promotion, error) {
+	promotion, err := s.repo.GetPromotionByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	return promotion, nil
+}
+
+func (s *PromotionService) Create(ctx context.Context, req *dto.CreatePromotionReq) (*model.Promotion, error) {
+	if err := s.validator.ValidateStruct(req); err != nil {
+		return nil, err
+	}
+
+	var promotion model.Promotion
+	utils.Copy(&promotion, req)
+	promotion.Active = req.Active == nil || *req.Active
+	if err := validate(&promotion); err != nil {
+		return nil, err
+	}
+
+	if err := s.repo.Create(ctx, &promotion); err != nil {
+		logger.Errorf("Create fail, error: %s", err)
+		return nil, err
+	}
+
+	return &promotion, nil
+}
+
+func (s *PromotionService) Update(ctx context.Context, id string, req *dto.UpdatePromotionReq) (*model.Promotion, error) {
+	if err := s.validator.ValidateStruct(req); err != nil {
+		return nil, err
+	}
+
+	promotion, err := s.repo.GetPromotionByID(ctx, id)
+	if err != nil {
+		logger.Errorf("Update.GetPromotionByID fail, id: %s, error: %s", id, err)
+		return nil, err
+	}
+
+	utils.Copy(promotion, req)
+	// An empty list of scopes removes them, the promotion then applies to every line
+	if req.Scopes != nil {
+		promotion.Scopes = make([]*model.PromotionScope, 0, len(req.Scopes))
+		utils.Copy(&promotion.Scopes, &req.Scopes)
+	}
+	if err := validate(promotion); err != nil {
+		return nil, err
+	}
+
+	if err := s.repo.Update(ctx, promotion); err != nil {
+		logger.Errorf("Update fail, id: %s, error: %s", id, err)
+		return nil, err
+	}
+
+	return promotion, nil
+}
+
+// validate checks the fields required by the type of the promotion
+func validate(promotion *model.Promotion) error {
+	switch promotion.Type {
+	case model.PromotionTypePercentage:
+		if promotion.Percentage == 0 || promotion.Percentage > 100 {
+			return fmt.Errorf("%w: percentage must be between 1 and 100", ErrInvalidPromotion)
+		}
+	case model.PromotionTypeFixed:
+		if _, err := money.Exponent(promotion.Discount.Currency); err != nil || promotion.Discount.Amount <= 0 {
+			return fmt.Errorf("%w: discount must be greater than 0 in a known currency", ErrInvalidPromotion)
+		}
+	case model.PromotionTypeBuyXGetY:
+		if promotion.BuyQuantity == 0 || promotion.GetQuantity == 0 {
+			return fmt.Errorf("%w: buy_quantity and get_quantity must be greater than 0", ErrInvalidPromotion)
+		}
+	default:
+		return fmt.Errorf("%w: unknown type %q", ErrInvalidPromotion, promotion.Type)
+	}
+
+	if promotion.MinOrderValue.IsNegative() {
+		return fmt.Errorf("%w: min_order_value must not be negative", ErrInvalidPromotion)
+	}
+	if promotion.StartsAt != nil && promotion.EndsAt != nil && !promotion.EndsAt.After(*promotion.StartsAt) {
+		return fmt.Errorf("%w: ends_at must be after starts_at", ErrInvalidPromotion)
+	}
+
+	return nil
+}
+
+// Apply checks the promotions of codes can be used by userID on lines and discounts the lines,
+// in the order of codes. Each promotion discounts what the previous ones left to pay, so the
+// discount of a line never exceeds its price.
+func (s *PromotionService) Apply(ctx context.Context, userID string, codes []string, lines []*Line) ([]*Discount, error) {
+	codes = normalizeCodes(codes)
+	if len(codes) == 0 {
+		return nil, nil
+	}
+
+	promotions, err := s.repo.GetPromotionsByCodes(ctx, codes)
+	if err != nil {
+		return nil, err
+	}
+	promotionMap := make(map[string]*model.Promotion, len(promotions))
+	for _, promotion := range promotions {
+		promotionMap[promotion.Code] = promotion
+	}
+
+	prices := make([]money.Money, 0, len(lines))
+	for _, line := range lines {
+		prices = append(prices, line.Price)
+	}
+	subtotal, err := money.Sum(prices...)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	discounts := make([]*Discount, 0, len(codes))
+	for _, code := range codes {
+		promotion, ok := promotionMap[code]
+		if !ok {
+			return nil, fmt.Errorf("%w: %s", ErrPromotionNotFound, code)
+		}
+		if err := s.checkUsable(ctx, userID, promotion, subtotal, now); err != nil {
+			return nil, err
+		}
+
+		scopeCategoryIDs, err := s.scopeCategoryIDs(ctx, promotion)
+		if err != nil {
+			return nil, err
+		}
+		amounts, err := allocate(promotion, scopeCategoryIDs, lines)
+		if err != nil {
+			return nil, err
+		}
+
+		var total money.Money
+		for i, line := range lines {
+			if amounts[i] == 0 {
+				continue
+			}
+			line.Discount = money.New(line.Discount.Amount+amounts[i], line.Price.Currency)
+			total = money.New(total.Amount+amounts[i], line.Price.Currency)
+		}
+		if total.IsZero() {
+			return nil, fmt.Errorf("%w: %s", ErrPromotionNotApplicable, code)
+		}
+
+		discounts = append(discounts, &Discount{Promotion: promotion, Amount: total})
+	}
+
+	return discounts, nil
+}
+
+func (s *PromotionService) checkUsable(ctx context.Context, userID string, promotion *model.Promotion, subtotal money.Money, now time.Time) error {
+	if !promotion.ValidAt(now) {
+		return fmt.Errorf("%w: %s", ErrPromotionInactive, promotion.Code)
+	}
+	if promotion.UsageLimit > 0 && promotion.UsageCount >= promotion.UsageLimit {
+		return fmt.Errorf("%w: %s", ErrUsageLimitReached, promotion.Code)
+	}
+
+	if promotion.UserUsageLimit > 0 {
+		count, err := s.repo.CountUserRedemptions(ctx, promotion.ID, userID)
+		if err != nil {
+			return err
+		}
+		if count >= int64(promotion.UserUsageLimit) {
+			return fmt.Errorf("%w: %s", ErrUsageLimitReached, promotion.Code)
+		}
+	}
+
+	if !promotion.MinOrderValue.IsZero() {
+		cmp, err := subtotal.Cmp(promotion.MinOrderValue)
+		if err != nil {
+			return fmt.Errorf("%w: %s: %s", ErrPromotionNotApplicable, promotion.Code, err)
+		}
+		if cmp < 0 {
+			return fmt.Errorf("%w: %s needs %s", ErrMinOrderValue, promotion.Code, promotion.MinOrderValue)
+		}
+	}
+
+	return nil
+}
+
+// scopeCategoryIDs returns the categories of the scopes of promotion along with their descendants
+func (s *PromotionService) scopeCategoryIDs(ctx context.Context, promotion *model.Promotion) (map[string]bool, error) {
+	var ids []string
+	for _, scope := range promotion.Scopes {
+		if scope.Type == model.ScopeTypeCategory {
+			ids = append(ids, scope.TargetID)
+		}
+	}
+	if len(ids) == 0 {
+		return nil, nil
+	}
+
+	descendants, err := s.repo.GetDescendantCategoryIDs(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+
+	scopeCategoryIDs := make(map[string]bool, len(descendants))
+	for _, id := range descendants {
+		scopeCategoryIDs[id] = true
+	}
+	return scopeCategoryIDs, nil
+}
+
+// allocate returns the discount of promotion on each line, in minor units
+func allocate(promotion *model.Promotion, scopeCategoryIDs map[string]bool, lines []*Line) ([]int64, error) {
+	amounts := make([]int64, len(lines))
+	remaining := make([]int64, len(lines))
+	var inScope []int
+	for i, line := range lines {
+		remaining[i] = line.Price.Amount - line.Discount.Amount
+		if remaining[i] > 0 && promotion.InScope(line.ProductID, line.CategoryIDs, scopeCategoryIDs) {
+			inScope = append(inScope, i)
+		}
+	}
+
+	switch promotion.Type {
+	case model.PromotionTypePercentage:
+		for _, i := range inScope {
+			amounts[i] = remaining[i] * int64(promotion.Percentage) / 100
+		}
+
+	case model.PromotionTypeFixed:
+		// The discount is shared by the lines in proportion to what is left to pay on them, the
+		// last line takes the rounding remainder
+		var total int64
+		for _, i := range inScope {
+			if lines[i].Price.Currency != promotion.Discount.Currency {
+				return nil, fmt.Errorf("%w: %s: %s", ErrPromotionNotApplicable, promotion.Code, money.ErrCurrencyMismatch)
+			}
+			total += remaining[i]
+		}
+		discount := promotion.Discount.Amount
+		if discount > total {
+			discount = total
+		}
+
+		left := discount
+		for n, i := range inScope {
+			if n == len(inScope)-1 {
+				amounts[i] = left
+				break
+			}
+			amounts[i] = discount * remaining[i] / total
+			left -= amounts[i]
+		}
+
+	case model.PromotionTypeBuyXGetY:
+		group := promotion.BuyQuantity + promotion.GetQuantity
+		for _, i := range inScope {
+			line := lines[i]
+			free := line.Quantity / group * promotion.GetQuantity
+			if free == 0 {
+				continue
+			}
+			amounts[i] = line.Price.Amount / int64(line.Quantity) * int64(free)
+			if amounts[i] > remaining[i] {
+				amounts[i] = remaining[i]
+			}
+		}
+	}
+
+	return amounts, nil
+}
+
+// Redeem counts the use of discounts by the order of userID. It fails with ErrUsageLimitReached
+// when a promotion ran out since Apply, run it in the transaction creating the order.
+func (s *PromotionService) Redeem(ctx context.Context, userID, orderID string, discounts []*Discount) error {
+	for _, discount := range discounts {
+		ok, err := s.repo.Redeem(ctx, &model.PromotionRedemption{
+			PromotionID: discount.Promotion.ID,
+			UserID:      userID,
+			OrderID:     orderID,
+			Amount:      discount.Amount,
+		}, discount.Promotion.UserUsageLimit)
+		if err != nil {
+			logger.Errorf("Redeem fail, promotion: %s, order: %s, error: %s", discount.Promotion.ID, orderID, err)
+			return err
+		}
+		if !ok {
+			return fmt.Errorf("%w: %s", ErrUsageLimitReached, discount.Promotion.Code)
+		}
+	}
+
+	return nil
+}
+
+// normalizeCodes normalizes codes and drops the empty and repeated ones
+func normalizeCodes(codes []string) []string {
+	seen := make(map[string]bool, len(codes))
+	normalized := make([]string, 0, len(codes))
+	for _, code := range codes {
+		code = model.NormalizeCode(code)
+		if code == "" || seen[code] {
+			continue
+		}
+		seen[code] = true
+		normalized = append(normalized, code)
+	}
+	return normalized
+}
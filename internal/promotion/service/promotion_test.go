@@ -0,0 +1,408 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/quangdangfit/gocommon/logger"
+	"github.com/quangdangfit/gocommon/validation"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+
+	"goshop/internal/promotion/dto"
+	"goshop/internal/promotion/model"
+	"goshop/internal/promotion/repository/mocks"
+	"goshop/pkg/config"
+	"goshop/pkg/money"
+)
+
+type PromotionServiceTestSuite struct {
+	suite.Suite
+	mockRepo *mocks.IPromotionRepository
+	service  IPromotionService
+}
+
+func (suite *PromotionServiceTestSuite) SetupTest() {
+	logger.Initialize(config.ProductionEnv)
+
+	suite.mockRepo = mocks.NewIPromotionRepository(suite.T())
+	suite.service = NewPromotionService(validation.New(), suite.mockRepo)
+}
+
+func TestPromotionServiceTestSuite(t *testing.T) {
+	suite.Run(t, new(PromotionServiceTestSuite))
+}
+
+func (suite *PromotionServiceTestSuite) expectPromotions(promotions ...*model.Promotion) {
+	suite.mockRepo.On("GetPromotionsByCodes", mock.Anything, mock.Anything).
+		Return(promotions, nil).Times(1)
+}
+
+func usd(amount int64) money.Money {
+	return money.New(amount, "USD")
+}
+
+// Create
+// =================================================================
+
+func (suite *PromotionServiceTestSuite) TestListPromotionsInvalidOrder() {
+	promotions, pagination, err := suite.service.ListPromotions(context.Background(), &dto.ListPromotionReq{OrderBy: "id; DROP TABLE promotions"})
+	suite.ErrorIs(err, ErrInvalidFilter)
+	suite.Nil(promotions)
+	suite.Nil(pagination)
+}
+
+func (suite *PromotionServiceTestSuite) TestCreateSuccess() {
+	req := &dto.CreatePromotionReq{
+		Code:       "save10",
+		Name:       "Save 10%",
+		Type:       "percentage",
+		Percentage: 10,
+		Scopes:     []*dto.PromotionScope{{Type: "product", TargetID: "productID"}},
+	}
+	suite.mockRepo.On("Create", mock.Anything, mock.Anything).Return(nil).Times(1)
+
+	promotion, err := suite.service.Create(context.Background(), req)
+	suite.Nil(err)
+	suite.Equal(model.PromotionTypePercentage, promotion.Type)
+	suite.Equal(uint(10), promotion.Percentage)
+	suite.True(promotion.Active)
+	suite.Equal(1, len(promotion.Scopes))
+	suite.Equal(model.ScopeTypeProduct, promotion.Scopes[0].Type)
+}
+
+func (suite *PromotionServiceTestSuite) TestCreateInvalidRequest() {
+	promotion, err := suite.service.Create(context.Background(), &dto.CreatePromotionReq{Code: "save10", Type: "free"})
+	suite.NotNil(err)
+	suite.Nil(promotion)
+}
+
+func (suite *PromotionServiceTestSuite) TestCreateMissingTypeFields() {
+	reqs := []*dto.CreatePromotionReq{
+		{Code: "a", Name: "a", Type: "percentage"},
+		{Code: "b", Name: "b", Type: "fixed"},
+		{Code: "c", Name: "c", Type: "fixed", Discount: money.New(100, "")},
+		{Code: "d", Name: "d", Type: "buy_x_get_y", BuyQuantity: 2},
+	}
+
+	for _, req := range reqs {
+		promotion, err := suite.service.Create(context.Background(), req)
+		suite.ErrorIs(err, ErrInvalidPromotion, req.Code)
+		suite.Nil(promotion)
+	}
+}
+
+func (suite *PromotionServiceTestSuite) TestCreateInvalidWindow() {
+	now := time.Now()
+	before := now.Add(-time.Hour)
+	req := &dto.CreatePromotionReq{
+		Code:       "save10",
+		Name:       "Save 10%",
+		Type:       "percentage",
+		Percentage: 10,
+		StartsAt:   &now,
+		EndsAt:     &before,
+	}
+
+	promotion, err := suite.service.Create(context.Background(), req)
+	suite.ErrorIs(err, ErrInvalidPromotion)
+	suite.Nil(promotion)
+}
+
+func (suite *PromotionServiceTestSuite) TestCreateFail() {
+	req := &dto.CreatePromotionReq{Code: "save5", Name: "Save 5", Type: "fixed", Discount: usd(500)}
+	suite.mockRepo.On("Create", mock.Anything, mock.Anything).Return(errors.New("error")).Times(1)
+
+	promotion, err := suite.service.Create(context.Background(), req)
+	suite.NotNil(err)
+	suite.Nil(promotion)
+}
+
+// Update
+// =================================================================
+
+func (suite *PromotionServiceTestSuite) TestUpdateSuccess() {
+	active := false
+	req := &dto.UpdatePromotionReq{
+		Name:   "Renamed",
+		Active: &active,
+		Scopes: []*dto.PromotionScope{},
+	}
+	suite.mockRepo.On("GetPromotionByID", mock.Anything, "promotionID").
+		Return(&model.Promotion{
+			ID:         "promotionID",
+			Name:       "Save 10%",
+			Type:       model.PromotionTypePercentage,
+			Percentage: 10,
+			Active:     true,
+			Scopes:     []*model.PromotionScope{{Type: model.ScopeTypeProduct, TargetID: "productID"}},
+		}, nil).Times(1)
+	suite.mockRepo.On("Update", mock.Anything, mock.Anything).Return(nil).Times(1)
+
+	promotion, err := suite.service.Update(context.Background(), "promotionID", req)
+	suite.Nil(err)
+	suite.Equal("Renamed", promotion.Name)
+	suite.False(promotion.Active)
+	suite.Equal(uint(10), promotion.Percentage)
+	suite.Equal(0, len(promotion.Scopes))
+}
+
+func (suite *PromotionServiceTestSuite) TestUpdateNotFound() {
+	suite.mockRepo.On("GetPromotionByID", mock.Anything, "promotionID").
+		Return(nil, errors.New("error")).Times(1)
+
+	promotion, err := suite.service.Update(context.Background(), "promotionID", &dto.UpdatePromotionReq{})
+	suite.NotNil(err)
+	suite.Nil(promotion)
+}
+
+// Apply
+// =================================================================
+
+func (suite *PromotionServiceTestSuite) TestApplyWithoutCodes() {
+	discounts, err := suite.service.Apply(context.Background(), "userID", []string{" "}, nil)
+	suite.Nil(err)
+	suite.Nil(discounts)
+}
+
+func (suite *PromotionServiceTestSuite) TestApplyPercentage() {
+	suite.expectPromotions(&model.Promotion{
+		ID: "promotionID", Code: "SAVE10", Type: model.PromotionTypePercentage, Percentage: 10, Active: true,
+	})
+	lines := []*Line{
+		{ProductID: "p1", Quantity: 1, Price: usd(1005)},
+		{ProductID: "p2", Quantity: 2, Price: usd(2000)},
+	}
+
+	discounts, err := suite.service.Apply(context.Background(), "userID", []string{"save10", "SAVE10"}, lines)
+	suite.Nil(err)
+	suite.Equal(1, len(discounts))
+	suite.Equal(usd(300), discounts[0].Amount)
+	suite.Equal(usd(100), lines[0].Discount)
+	suite.Equal(usd(200), lines[1].Discount)
+}
+
+func (suite *PromotionServiceTestSuite) TestApplyFixedSharedByLines() {
+	suite.expectPromotions(&model.Promotion{
+		ID: "promotionID", Code: "SAVE10", Type: model.PromotionTypeFixed, Discount: usd(1000), Active: true,
+	})
+	lines := []*Line{
+		{ProductID: "p1", Quantity: 1, Price: usd(1000)},
+		{ProductID: "p2", Quantity: 1, Price: usd(2000)},
+	}
+
+	discounts, err := suite.service.Apply(context.Background(), "userID", []string{"SAVE10"}, lines)
+	suite.Nil(err)
+	suite.Equal(usd(1000), discounts[0].Amount)
+	suite.Equal(usd(333), lines[0].Discount)
+	suite.Equal(usd(667), lines[1].Discount)
+}
+
+func (suite *PromotionServiceTestSuite) TestApplyFixedCappedAtPrice() {
+	suite.expectPromotions(&model.Promotion{
+		ID: "promotionID", Code: "SAVE50", Type: model.PromotionTypeFixed, Discount: usd(5000), Active: true,
+	})
+	lines := []*Line{{ProductID: "p1", Quantity: 1, Price: usd(1000)}}
+
+	discounts, err := suite.service.Apply(context.Background(), "userID", []string{"SAVE50"}, lines)
+	suite.Nil(err)
+	suite.Equal(usd(1000), discounts[0].Amount)
+	suite.Equal(usd(1000), lines[0].Discount)
+}
+
+func (suite *PromotionServiceTestSuite) TestApplyFixedOtherCurrency() {
+	suite.expectPromotions(&model.Promotion{
+		ID: "promotionID", Code: "SAVE10", Type: model.PromotionTypeFixed, Discount: money.New(1000, "EUR"), Active: true,
+	})
+	lines := []*Line{{ProductID: "p1", Quantity: 1, Price: usd(1000)}}
+
+	discounts, err := suite.service.Apply(context.Background(), "userID", []string{"SAVE10"}, lines)
+	suite.ErrorIs(err, ErrPromotionNotApplicable)
+	suite.Nil(discounts)
+}
+
+func (suite *PromotionServiceTestSuite) TestApplyBuyXGetY() {
+	suite.expectPromotions(&model.Promotion{
+		ID: "promotionID", Code: "B2G1", Type: model.PromotionTypeBuyXGetY, BuyQuantity: 2, GetQuantity: 1, Active: true,
+		Scopes: []*model.PromotionScope{{Type: model.ScopeTypeProduct, TargetID: "p1"}},
+	})
+	lines := []*Line{
+		{ProductID: "p1", Quantity: 7, Price: usd(7000)},
+		{ProductID: "p2", Quantity: 3, Price: usd(3000)},
+	}
+
+	discounts, err := suite.service.Apply(context.Background(), "userID", []string{"B2G1"}, lines)
+	suite.Nil(err)
+	suite.Equal(usd(2000), discounts[0].Amount)
+	suite.Equal(usd(2000), lines[0].Discount)
+	suite.True(lines[1].Discount.IsZero())
+}
+
+func (suite *PromotionServiceTestSuite) TestApplyCategoryScope() {
+	suite.expectPromotions(&model.Promotion{
+		ID: "promotionID", Code: "SHOES", Type: model.PromotionTypePercentage, Percentage: 50, Active: true,
+		Scopes: []*model.PromotionScope{{Type: model.ScopeTypeCategory, TargetID: "shoes"}},
+	})
+	suite.mockRepo.On("GetDescendantCategoryIDs", mock.Anything, []string{"shoes"}).
+		Return([]string{"shoes", "boots"}, nil).Times(1)
+	lines := []*Line{
+		{ProductID: "p1", CategoryIDs: []string{"shoes"}, Quantity: 1, Price: usd(1000)},
+		{ProductID: "p2", CategoryIDs: []string{"hats"}, Quantity: 1, Price: usd(1000)},
+		{ProductID: "p3", CategoryIDs: []string{"hats", "boots"}, Quantity: 1, Price: usd(1000)},
+	}
+
+	discounts, err := suite.service.Apply(context.Background(), "userID", []string{"SHOES"}, lines)
+	suite.Nil(err)
+	suite.Equal(usd(1000), discounts[0].Amount)
+	suite.Equal(usd(500), lines[0].Discount)
+	suite.True(lines[1].Discount.IsZero())
+	suite.Equal(usd(500), lines[2].Discount)
+}
+
+func (suite *PromotionServiceTestSuite) TestApplyCategoryScopeFail() {
+	suite.expectPromotions(&model.Promotion{
+		ID: "promotionID", Code: "SHOES", Type: model.PromotionTypePercentage, Percentage: 50, Active: true,
+		Scopes: []*model.PromotionScope{{Type: model.ScopeTypeCategory, TargetID: "shoes"}},
+	})
+	suite.mockRepo.On("GetDescendantCategoryIDs", mock.Anything, []string{"shoes"}).
+		Return(nil, errors.New("error")).Times(1)
+	lines := []*Line{{ProductID: "p1", CategoryIDs: []string{"shoes"}, Quantity: 1, Price: usd(1000)}}
+
+	discounts, err := suite.service.Apply(context.Background(), "userID", []string{"SHOES"}, lines)
+	suite.NotNil(err)
+	suite.Nil(discounts)
+}
+
+func (suite *PromotionServiceTestSuite) TestApplyStacksOnRemainingPrice() {
+	suite.expectPromotions(
+		&model.Promotion{ID: "p1", Code: "HALF", Type: model.PromotionTypePercentage, Percentage: 50, Active: true},
+		&model.Promotion{ID: "p2", Code: "SAVE10", Type: model.PromotionTypeFixed, Discount: usd(1000), Active: true},
+	)
+	lines := []*Line{{ProductID: "p1", Quantity: 1, Price: usd(1200)}}
+
+	discounts, err := suite.service.Apply(context.Background(), "userID", []string{"HALF", "SAVE10"}, lines)
+	suite.Nil(err)
+	suite.Equal(2, len(discounts))
+	suite.Equal(usd(600), discounts[0].Amount)
+	suite.Equal(usd(600), discounts[1].Amount)
+	suite.Equal(usd(1200), lines[0].Discount)
+}
+
+func (suite *PromotionServiceTestSuite) TestApplyNotFound() {
+	suite.expectPromotions()
+	lines := []*Line{{ProductID: "p1", Quantity: 1, Price: usd(1000)}}
+
+	discounts, err := suite.service.Apply(context.Background(), "userID", []string{"unknown"}, lines)
+	suite.ErrorIs(err, ErrPromotionNotFound)
+	suite.Nil(discounts)
+}
+
+func (suite *PromotionServiceTestSuite) TestApplyOutsideWindow() {
+	past := time.Now().Add(-time.Hour)
+	future := time.Now().Add(time.Hour)
+	promotions := []*model.Promotion{
+		{Code: "OFF", Type: model.PromotionTypePercentage, Percentage: 10},
+		{Code: "ENDED", Type: model.PromotionTypePercentage, Percentage: 10, Active: true, EndsAt: &past},
+		{Code: "SOON", Type: model.PromotionTypePercentage, Percentage: 10, Active: true, StartsAt: &future},
+	}
+	lines := []*Line{{ProductID: "p1", Quantity: 1, Price: usd(1000)}}
+
+	for _, promotion := range promotions {
+		suite.expectPromotions(promotion)
+		discounts, err := suite.service.Apply(context.Background(), "userID", []string{promotion.Code}, lines)
+		suite.ErrorIs(err, ErrPromotionInactive, promotion.Code)
+		suite.Nil(discounts)
+	}
+}
+
+func (suite *PromotionServiceTestSuite) TestApplyUsageLimitReached() {
+	suite.expectPromotions(&model.Promotion{
+		ID: "promotionID", Code: "ONCE", Type: model.PromotionTypePercentage, Percentage: 10, Active: true,
+		UsageLimit: 100, UsageCount: 100,
+	})
+	lines := []*Line{{ProductID: "p1", Quantity: 1, Price: usd(1000)}}
+
+	discounts, err := suite.service.Apply(context.Background(), "userID", []string{"ONCE"}, lines)
+	suite.ErrorIs(err, ErrUsageLimitReached)
+	suite.Nil(discounts)
+}
+
+func (suite *PromotionServiceTestSuite) TestApplyUserUsageLimitReached() {
+	suite.expectPromotions(&model.Promotion{
+		ID: "promotionID", Code: "WELCOME", Type: model.PromotionTypePercentage, Percentage: 10, Active: true,
+		UserUsageLimit: 1,
+	})
+	suite.mockRepo.On("CountUserRedemptions", mock.Anything, "promotionID", "userID").
+		Return(int64(1), nil).Times(1)
+	lines := []*Line{{ProductID: "p1", Quantity: 1, Price: usd(1000)}}
+
+	discounts, err := suite.service.Apply(context.Background(), "userID", []string{"WELCOME"}, lines)
+	suite.ErrorIs(err, ErrUsageLimitReached)
+	suite.Nil(discounts)
+}
+
+func (suite *PromotionServiceTestSuite) TestApplyBelowMinOrderValue() {
+	suite.expectPromotions(&model.Promotion{
+		ID: "promotionID", Code: "BIG", Type: model.PromotionTypeFixed, Discount: usd(500), Active: true,
+		MinOrderValue: usd(5000),
+	})
+	lines := []*Line{{ProductID: "p1", Quantity: 1, Price: usd(4999)}}
+
+	discounts, err := suite.service.Apply(context.Background(), "userID", []string{"BIG"}, lines)
+	suite.ErrorIs(err, ErrMinOrderValue)
+	suite.Nil(discounts)
+}
+
+func (suite *PromotionServiceTestSuite) TestApplyOutOfScope() {
+	suite.expectPromotions(&model.Promotion{
+		ID: "promotionID", Code: "P2", Type: model.PromotionTypePercentage, Percentage: 10, Active: true,
+		Scopes: []*model.PromotionScope{{Type: model.ScopeTypeProduct, TargetID: "p2"}},
+	})
+	lines := []*Line{{ProductID: "p1", Quantity: 1, Price: usd(1000)}}
+
+	discounts, err := suite.service.Apply(context.Background(), "userID", []string{"P2"}, lines)
+	suite.ErrorIs(err, ErrPromotionNotApplicable)
+	suite.Nil(discounts)
+}
+
+// Redeem
+// =================================================================
+
+func (suite *PromotionServiceTestSuite) TestRedeemSuccess() {
+	discounts := []*Discount{{Promotion: &model.Promotion{ID: "promotionID", Code: "SAVE10"}, Amount: usd(100)}}
+	suite.mockRepo.On("Redeem", mock.Anything, &model.PromotionRedemption{
+		PromotionID: "promotionID",
+		UserID:      "userID",
+		OrderID:     "orderID",
+		Amount:      usd(100),
+	}, uint(0)).Return(true, nil).Times(1)
+
+	err := suite.service.Redeem(context.Background(), "userID", "orderID", discounts)
+	suite.Nil(err)
+}
+
+func (suite *PromotionServiceTestSuite) TestRedeemPassesUserUsageLimit() {
+	discounts := []*Discount{{Promotion: &model.Promotion{ID: "promotionID", Code: "SAVE10", UserUsageLimit: 1}, Amount: usd(100)}}
+	suite.mockRepo.On("Redeem", mock.Anything, mock.Anything, uint(1)).Return(false, nil).Times(1)
+
+	err := suite.service.Redeem(context.Background(), "userID", "orderID", discounts)
+	suite.ErrorIs(err, ErrUsageLimitReached)
+}
+
+func (suite *PromotionServiceTestSuite) TestRedeemLimitReached() {
+	discounts := []*Discount{{Promotion: &model.Promotion{ID: "promotionID", Code: "SAVE10"}, Amount: usd(100)}}
+	suite.mockRepo.On("Redeem", mock.Anything, mock.Anything, mock.Anything).Return(false, nil).Times(1)
+
+	err := suite.service.Redeem(context.Background(), "userID", "orderID", discounts)
+	suite.ErrorIs(err, ErrUsageLimitReached)
+}
+
+func (suite *PromotionServiceTestSuite) TestRedeemFail() {
+	discounts := []*Discount{{Promotion: &model.Promotion{ID: "promotionID", Code: "SAVE10"}, Amount: usd(100)}}
+	suite.mockRepo.On("Redeem", mock.Anything, mock.Anything, mock.Anything).Return(false, errors.New("error")).Times(1)
+
+	err := suite.service.Redeem(context.Background(), "userID", "orderID", discounts)
+	suite.NotNil(err)
+}
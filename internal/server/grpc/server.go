@@ -13,6 +13,8 @@ import (
 	userGRPC "goshop/internal/user/port/grpc"
 	"goshop/pkg/config"
 	"goshop/pkg/dbs"
+	"goshop/pkg/idempotency"
+	"goshop/pkg/mailer"
 	"goshop/pkg/middleware"
 	"goshop/pkg/redis"
 )
@@ -23,14 +25,23 @@ type Server struct {
 	validator validation.Validation
 	db        dbs.IDatabase
 	cache     redis.IRedis
+	mailer    mailer.Mailer
 }
 
-func NewServer(validator validation.Validation, db dbs.IDatabase, cache redis.IRedis) *Server {
-	interceptor := middleware.NewAuthInterceptor(config.AuthIgnoreMethods)
+func NewServer(validator validation.Validation, db dbs.IDatabase, cache redis.IRedis, mail mailer.Mailer) *Server {
+	errorInterceptor := middleware.NewErrorInterceptor()
+	interceptor := middleware.NewAuthInterceptor(config.AuthIgnoreMethods, config.GuestMethods)
+	permissionInterceptor := middleware.NewPermissionInterceptor(config.GrpcMethodPermissions)
+	idempotencyInterceptor := middleware.NewIdempotencyInterceptor(
+		idempotency.NewStore(cache, db, config.GetConfig().IdempotencyKeyTTL),
+	)
 
 	grpcServer := grpc.NewServer(
 		grpc.ChainUnaryInterceptor(
+			errorInterceptor.Unary(),
 			interceptor.Unary(),
+			permissionInterceptor.Unary(),
+			idempotencyInterceptor.Unary(),
 		),
 	)
 
@@ -40,12 +51,13 @@ func NewServer(validator validation.Validation, db dbs.IDatabase, cache redis.IR
 		validator: validator,
 		db:        db,
 		cache:     cache,
+		mailer:    mail,
 	}
 }
 
 func (s Server) Run() error {
-	userGRPC.RegisterHandlers(s.engine, s.db, s.validator)
-	cartGRPC.RegisterHandlers(s.engine, s.db, s.validator)
+	userGRPC.RegisterHandlers(s.engine, s.db, s.validator, s.cache, s.mailer)
+	cartGRPC.RegisterHandlers(s.engine, s.db, s.validator, s.cache)
 
 	reflection.Register(s.engine)
 
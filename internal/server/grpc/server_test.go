@@ -7,6 +7,7 @@ import (
 	"github.com/stretchr/testify/assert"
 
 	dbMocks "goshop/pkg/dbs/mocks"
+	mailerMocks "goshop/pkg/mailer/mocks"
 	redisMocks "goshop/pkg/redis/mocks"
 )
 
@@ -14,6 +15,6 @@ func TestNewServer(t *testing.T) {
 	mockDB := dbMocks.NewIDatabase(t)
 	mockRedis := redisMocks.NewIRedis(t)
 
-	server := NewServer(validation.New(), mockDB, mockRedis)
+	server := NewServer(validation.New(), mockDB, mockRedis, mailerMocks.NewMailer(t))
 	assert.NotNil(t, server)
 }
@@ -12,11 +12,18 @@ import (
 	ginSwagger "github.com/swaggo/gin-swagger"
 
 	_ "goshop/docs"
+	cartHttp "goshop/internal/cart/port/http"
 	orderHttp "goshop/internal/order/port/http"
+	paymentHttp "goshop/internal/payment/port/http"
+	"goshop/internal/payment/provider"
 	productHttp "goshop/internal/product/port/http"
+	promotionHttp "goshop/internal/promotion/port/http"
 	userHttp "goshop/internal/user/port/http"
 	"goshop/pkg/config"
 	"goshop/pkg/dbs"
+	"goshop/pkg/idempotency"
+	"goshop/pkg/jtoken"
+	"goshop/pkg/mailer"
 	"goshop/pkg/redis"
 	"goshop/pkg/response"
 )
@@ -27,15 +34,21 @@ type Server struct {
 	validator validation.Validation
 	db        dbs.IDatabase
 	cache     redis.IRedis
+	mailer    mailer.Mailer
 }
 
-func NewServer(validator validation.Validation, db dbs.IDatabase, cache redis.IRedis) *Server {
+func NewServer(validator validation.Validation, db dbs.IDatabase, cache redis.IRedis, mail mailer.Mailer) *Server {
+	engine := gin.Default()
+	// Let handlers pass *gin.Context to the database so queries stop when the client goes away
+	engine.ContextWithFallback = true
+
 	return &Server{
-		engine:    gin.Default(),
+		engine:    engine,
 		cfg:       config.GetConfig(),
 		validator: validator,
 		db:        db,
 		cache:     cache,
+		mailer:    mail,
 	}
 }
 
@@ -67,10 +80,27 @@ func (s Server) GetEngine() *gin.Engine {
 	return s.engine
 }
 
+// jwks publishes the public keys verifying the tokens, for other services to authenticate users
+func jwks(c *gin.Context) {
+	c.Header("Cache-Control", "public, max-age=300")
+	c.JSON(http.StatusOK, jtoken.JWKS())
+}
+
 func (s Server) MapRoutes() error {
+	s.engine.GET("/.well-known/jwks.json", jwks)
+
 	v1 := s.engine.Group("/api/v1")
-	userHttp.Routes(v1, s.db, s.validator)
-	productHttp.Routes(v1, s.db, s.validator, s.cache)
-	orderHttp.Routes(v1, s.db, s.validator)
+	idempotencyStore := idempotency.NewStore(s.cache, s.db, s.cfg.IdempotencyKeyTTL)
+	userHttp.Routes(v1, s.db, s.validator, s.cache, s.mailer)
+	productHttp.Routes(v1, s.db, s.validator, s.cache, idempotencyStore)
+	orderHttp.Routes(v1, s.db, s.validator, idempotencyStore)
+	cartHttp.Routes(v1, s.db, s.validator, s.cache, idempotencyStore)
+	promotionHttp.Routes(v1, s.db, s.validator, idempotencyStore)
+
+	paymentProvider, err := provider.New(s.cfg.PaymentProvider, s.cfg.PaymentWebhookSecret)
+	if err != nil {
+		return err
+	}
+	paymentHttp.Routes(v1, s.db, s.validator, paymentProvider, idempotencyStore)
 	return nil
 }
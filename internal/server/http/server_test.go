@@ -1,12 +1,17 @@
 package http
 
 import (
+	"net/http"
+	"net/http/httptest"
 	"testing"
 
 	"github.com/quangdangfit/gocommon/validation"
 	"github.com/stretchr/testify/assert"
 
+	"goshop/internal/payment/provider"
+	"goshop/pkg/config"
 	dbMocks "goshop/pkg/dbs/mocks"
+	mailerMocks "goshop/pkg/mailer/mocks"
 	redisMocks "goshop/pkg/redis/mocks"
 )
 
@@ -14,7 +19,7 @@ func TestNewServer(t *testing.T) {
 	mockDB := dbMocks.NewIDatabase(t)
 	mockRedis := redisMocks.NewIRedis(t)
 
-	server := NewServer(validation.New(), mockDB, mockRedis)
+	server := NewServer(validation.New(), mockDB, mockRedis, mailerMocks.NewMailer(t))
 	assert.NotNil(t, server)
 }
 
@@ -22,7 +27,7 @@ func TestServer_GetEngine(t *testing.T) {
 	mockDB := dbMocks.NewIDatabase(t)
 	mockRedis := redisMocks.NewIRedis(t)
 
-	server := NewServer(validation.New(), mockDB, mockRedis)
+	server := NewServer(validation.New(), mockDB, mockRedis, mailerMocks.NewMailer(t))
 	assert.NotNil(t, server)
 
 	engine := server.GetEngine()
@@ -30,12 +35,36 @@ func TestServer_GetEngine(t *testing.T) {
 }
 
 func TestServer_MapRoutes(t *testing.T) {
+	config.GetConfig().PaymentWebhookSecret = "secret"
 	mockDB := dbMocks.NewIDatabase(t)
 	mockRedis := redisMocks.NewIRedis(t)
 
-	server := NewServer(validation.New(), mockDB, mockRedis)
+	server := NewServer(validation.New(), mockDB, mockRedis, mailerMocks.NewMailer(t))
 	assert.NotNil(t, server)
 
 	err := server.MapRoutes()
 	assert.Nil(t, err)
 }
+
+func TestServer_MapRoutesMissingWebhookSecret(t *testing.T) {
+	config.GetConfig().PaymentWebhookSecret = ""
+	mockDB := dbMocks.NewIDatabase(t)
+	mockRedis := redisMocks.NewIRedis(t)
+
+	server := NewServer(validation.New(), mockDB, mockRedis, mailerMocks.NewMailer(t))
+	assert.ErrorIs(t, server.MapRoutes(), provider.ErrMissingWebhookSecret)
+}
+
+func TestServer_JWKS(t *testing.T) {
+	config.GetConfig().PaymentWebhookSecret = "secret"
+	mockDB := dbMocks.NewIDatabase(t)
+	mockRedis := redisMocks.NewIRedis(t)
+
+	server := NewServer(validation.New(), mockDB, mockRedis, mailerMocks.NewMailer(t))
+	assert.Nil(t, server.MapRoutes())
+
+	writer := httptest.NewRecorder()
+	server.GetEngine().ServeHTTP(writer, httptest.NewRequest(http.MethodGet, "/.well-known/jwks.json", nil))
+	assert.Equal(t, http.StatusOK, writer.Code)
+	assert.JSONEq(t, `{"keys":[]}`, writer.Body.String())
+}
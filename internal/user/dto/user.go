@@ -5,15 +5,17 @@ import (
 )
 
 type User struct {
-	ID        string    `json:"id"`
-	Email     string    `json:"email"`
-	CreatedAt time.Time `json:"created_at"`
-	UpdatedAt time.Time `json:"updated_at"`
+	ID              string     `json:"id"`
+	Email           string     `json:"email"`
+	EmailVerifiedAt *time.Time `json:"email_verified_at"`
+	CreatedAt       time.Time  `json:"created_at"`
+	UpdatedAt       time.Time  `json:"updated_at"`
 }
 
 type RegisterReq struct {
-	Email    string `json:"email" validate:"required,email"`
-	Password string `json:"password" validate:"required,password"`
+	Email      string `json:"email" validate:"required,email"`
+	Password   string `json:"password" validate:"required,password"`
+	GuestToken string `json:"-"`
 }
 
 type RegisterRes struct {
@@ -21,8 +23,10 @@ type RegisterRes struct {
 }
 
 type LoginReq struct {
-	Email    string `json:"email" validate:"required,email"`
-	Password string `json:"password" validate:"required,password"`
+	Email      string `json:"email" validate:"required,email"`
+	Password   string `json:"password" validate:"required,password"`
+	GuestToken string `json:"-"`
+	IP         string `json:"-"`
 }
 
 type LoginRes struct {
@@ -36,10 +40,33 @@ type RefreshTokenReq struct {
 }
 
 type RefreshTokenRes struct {
-	AccessToken string `json:"access_token"`
+	AccessToken  string `json:"access_token"`
+	RefreshToken string `json:"refresh_token"`
 }
 
 type ChangePasswordReq struct {
 	Password    string `json:"password" validate:"required,password"`
 	NewPassword string `json:"new_password" validate:"required,password"`
 }
+
+type ForgotPasswordReq struct {
+	Email string `json:"email" validate:"required,email"`
+}
+
+type ResetPasswordReq struct {
+	Token       string `json:"token" validate:"required"`
+	NewPassword string `json:"new_password" validate:"required,password"`
+}
+
+type VerifyEmailReq struct {
+	Token string `json:"token" validate:"required"`
+}
+
+type ResendVerificationReq struct {
+	Email string `json:"email" validate:"required,email"`
+}
+
+type UnlockLoginReq struct {
+	Email string `json:"email" validate:"required_without=IP,omitempty,email"`
+	IP    string `json:"ip" validate:"required_without=Email,omitempty,ip"`
+}
@@ -0,0 +1,17 @@
+package model
+
+import (
+	"time"
+)
+
+// RefreshToken records a refresh token issued to a user, by the jti of the token. The tokens
+// rotated from the same login share a family, revoked as a whole when one of them is reused.
+type RefreshToken struct {
+	ID        string     `json:"id" gorm:"unique;not null;index;primary_key"`
+	CreatedAt time.Time  `json:"created_at"`
+	FamilyID  string     `json:"family_id" gorm:"not null;index"`
+	UserID    string     `json:"user_id" gorm:"not null;index"`
+	ExpiresAt time.Time  `json:"expires_at"`
+	UsedAt    *time.Time `json:"used_at"`
+	RevokedAt *time.Time `json:"revoked_at"`
+}
@@ -9,21 +9,29 @@ import (
 	"goshop/pkg/utils"
 )
 
+// UserRole is any role defined by the RBAC policy, the constants are the roles of the default policy
 type UserRole string
 
 const (
 	UserRoleAdmin    UserRole = "admin"
+	UserRoleStaff    UserRole = "staff"
 	UserRoleCustomer UserRole = "customer"
 )
 
 type User struct {
-	ID        string     `json:"id" gorm:"unique;not null;index;primary_key"`
-	CreatedAt time.Time  `json:"created_at"`
-	UpdatedAt time.Time  `json:"updated_at"`
-	DeletedAt *time.Time `json:"deleted_at" gorm:"index"`
-	Email     string     `json:"email" gorm:"unique;not null;index:idx_user_email"`
-	Password  string     `json:"password"`
-	Role      UserRole   `json:"role"`
+	ID              string     `json:"id" gorm:"unique;not null;index;primary_key"`
+	CreatedAt       time.Time  `json:"created_at"`
+	UpdatedAt       time.Time  `json:"updated_at"`
+	DeletedAt       *time.Time `json:"deleted_at" gorm:"index"`
+	Email           string     `json:"email" gorm:"unique;not null;index:idx_user_email"`
+	EmailVerifiedAt *time.Time `json:"email_verified_at"`
+	Password        string     `json:"password"`
+	Role            UserRole   `json:"role"`
+}
+
+// EmailVerified reports whether the user proved owning their email
+func (user *User) EmailVerified() bool {
+	return user.EmailVerifiedAt != nil
 }
 
 func (user *User) BeforeCreate(tx *gorm.DB) error {
@@ -0,0 +1,32 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type UserTokenPurpose string
+
+const (
+	UserTokenPasswordReset     UserTokenPurpose = "password_reset"
+	UserTokenEmailVerification UserTokenPurpose = "email_verification"
+)
+
+// UserToken is a single use token sent to a user by email. Only the hash of the token is stored,
+// so that the tokens cannot be used by whoever reads the database.
+type UserToken struct {
+	ID        string           `json:"id" gorm:"unique;not null;index;primary_key"`
+	CreatedAt time.Time        `json:"created_at"`
+	UserID    string           `json:"user_id" gorm:"not null;index"`
+	Purpose   UserTokenPurpose `json:"purpose" gorm:"not null"`
+	TokenHash string           `json:"-" gorm:"unique;not null"`
+	ExpiresAt time.Time        `json:"expires_at"`
+	UsedAt    *time.Time       `json:"used_at"`
+}
+
+func (m *UserToken) BeforeCreate(tx *gorm.DB) error {
+	m.ID = uuid.New().String()
+	return nil
+}
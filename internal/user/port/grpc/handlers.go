@@ -3,11 +3,17 @@ package grpc
 import (
 	"context"
 	"errors"
+	"net"
 
 	"github.com/quangdangfit/gocommon/logger"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
 
 	"goshop/internal/user/dto"
 	"goshop/internal/user/service"
+	"goshop/pkg/middleware"
 	"goshop/pkg/utils"
 	pb "goshop/proto/gen/go/user"
 )
@@ -26,12 +32,14 @@ func NewUserHandler(service service.IUserService) *UserHandler {
 
 func (h *UserHandler) Login(ctx context.Context, req *pb.LoginReq) (*pb.LoginRes, error) {
 	user, accessToken, refreshToken, err := h.service.Login(ctx, &dto.LoginReq{
-		Email:    req.Email,
-		Password: req.Password,
+		Email:      req.Email,
+		Password:   req.Password,
+		GuestToken: guestToken(ctx),
+		IP:         peerIP(ctx),
 	})
 	if err != nil {
-		logger.Error("Failed to register ", err)
-		return nil, err
+		logger.Error("Failed to login ", err)
+		return nil, loginError(err)
 	}
 
 	var res pb.LoginRes
@@ -43,8 +51,9 @@ func (h *UserHandler) Login(ctx context.Context, req *pb.LoginReq) (*pb.LoginRes
 
 func (h *UserHandler) Register(ctx context.Context, req *pb.RegisterReq) (*pb.RegisterRes, error) {
 	user, err := h.service.Register(ctx, &dto.RegisterReq{
-		Email:    req.Email,
-		Password: req.Password,
+		Email:      req.Email,
+		Password:   req.Password,
+		GuestToken: guestToken(ctx),
 	})
 	if err != nil {
 		logger.Error("Failed to register ", err)
@@ -73,22 +82,43 @@ func (h *UserHandler) GetMe(ctx context.Context, _ *pb.GetMeReq) (*pb.GetMeRes,
 	return &res, nil
 }
 
-func (h *UserHandler) RefreshToken(ctx context.Context, req *pb.RefreshTokenReq) (*pb.RefreshTokenRes, error) {
+// RefreshToken rotates the refresh token sent as token metadata
+func (h *UserHandler) RefreshToken(ctx context.Context, _ *pb.RefreshTokenReq) (*pb.RefreshTokenRes, error) {
+	accessToken, refreshToken, err := h.service.RefreshToken(ctx, token(ctx))
+	if err != nil {
+		logger.Error("Failed to refresh token ", err)
+		return nil, tokenError(err)
+	}
+
+	res := pb.RefreshTokenRes{
+		AccessToken:  accessToken,
+		RefreshToken: refreshToken,
+	}
+	return &res, nil
+}
+
+// Logout revokes the refresh token sent as token metadata and the tokens rotated along with it
+func (h *UserHandler) Logout(ctx context.Context, _ *pb.LogoutReq) (*pb.LogoutRes, error) {
+	if err := h.service.Logout(ctx, token(ctx)); err != nil {
+		logger.Error("Failed to logout ", err)
+		return nil, tokenError(err)
+	}
+
+	return &pb.LogoutRes{}, nil
+}
+
+func (h *UserHandler) LogoutAll(ctx context.Context, _ *pb.LogoutAllReq) (*pb.LogoutAllRes, error) {
 	userID, _ := ctx.Value("userId").(string)
 	if userID == "" {
 		return nil, errors.New("unauthorized")
 	}
 
-	accessToken, err := h.service.RefreshToken(ctx, userID)
-	if err != nil {
-		logger.Error("Failed to register ", err)
+	if err := h.service.LogoutAll(ctx, userID); err != nil {
+		logger.Error("Failed to logout everywhere ", err)
 		return nil, err
 	}
 
-	res := pb.RefreshTokenRes{
-		AccessToken: accessToken,
-	}
-	return &res, nil
+	return &pb.LogoutAllRes{}, nil
 }
 
 func (h *UserHandler) ChangePassword(ctx context.Context, req *pb.ChangePasswordReq) (*pb.ChangePasswordRes, error) {
@@ -108,3 +138,55 @@ func (h *UserHandler) ChangePassword(ctx context.Context, req *pb.ChangePassword
 
 	return &pb.ChangePasswordRes{}, nil
 }
+
+// token returns the token the call was authenticated with
+func token(ctx context.Context) string {
+	m, _ := metadata.FromIncomingContext(ctx)
+	if values := m.Get("token"); len(values) > 0 {
+		return values[0]
+	}
+	return ""
+}
+
+// tokenError returns err as an Unauthenticated status error when the refresh token was rejected
+func tokenError(err error) error {
+	if errors.Is(err, service.ErrInvalidRefreshToken) {
+		return status.Error(codes.Unauthenticated, err.Error())
+	}
+	return err
+}
+
+// loginError converts the errors of Login to statuses
+func loginError(err error) error {
+	switch {
+	case errors.Is(err, service.ErrInvalidCredentials):
+		return status.Error(codes.Unauthenticated, err.Error())
+	case errors.Is(err, service.ErrTooManyAttempts):
+		return status.Error(codes.ResourceExhausted, err.Error())
+	case errors.Is(err, service.ErrEmailNotVerified):
+		return status.Error(codes.PermissionDenied, err.Error())
+	}
+	return err
+}
+
+// peerIP returns the IP of the client, empty when unknown
+func peerIP(ctx context.Context) string {
+	p, ok := peer.FromContext(ctx)
+	if !ok || p.Addr == nil {
+		return ""
+	}
+	host, _, err := net.SplitHostPort(p.Addr.String())
+	if err != nil {
+		return ""
+	}
+	return host
+}
+
+// guestToken returns the guest token sent along with a login or registration, to merge the guest cart
+func guestToken(ctx context.Context) string {
+	m, _ := metadata.FromIncomingContext(ctx)
+	if values := m.Get(middleware.GuestTokenMetadata); len(values) > 0 {
+		return values[0]
+	}
+	return ""
+}
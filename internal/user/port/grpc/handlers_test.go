@@ -3,14 +3,21 @@ package grpc
 import (
 	"context"
 	"errors"
+	"net"
 	"testing"
+	"time"
 
 	"github.com/quangdangfit/gocommon/logger"
 	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/suite"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
 
 	"goshop/internal/user/dto"
 	"goshop/internal/user/model"
+	"goshop/internal/user/service"
 	"goshop/internal/user/service/mocks"
 	"goshop/pkg/config"
 	pb "goshop/proto/gen/go/user"
@@ -79,6 +86,58 @@ func (suite *UserHandlerTestSuite) TestUserAPI_LoginFail() {
 	suite.NotNil(err)
 }
 
+func (suite *UserHandlerTestSuite) TestUserAPI_LoginInvalidCredentials() {
+	req := &pb.LoginReq{
+		Email:    "login@test.com",
+		Password: "test123456",
+	}
+	ctx := peer.NewContext(context.Background(), &peer.Peer{
+		Addr: &net.TCPAddr{IP: net.ParseIP("192.0.2.1"), Port: 1234},
+	})
+
+	suite.mockService.On("Login", mock.Anything, &dto.LoginReq{
+		Email:    req.Email,
+		Password: req.Password,
+		IP:       "192.0.2.1",
+	}).Return(nil, "", "", service.ErrInvalidCredentials).Times(1)
+
+	res, err := suite.handler.Login(ctx, req)
+	suite.Nil(res)
+	suite.Equal(codes.Unauthenticated, status.Code(err))
+}
+
+func (suite *UserHandlerTestSuite) TestUserAPI_LoginLocked() {
+	req := &pb.LoginReq{
+		Email:    "login@test.com",
+		Password: "test123456",
+	}
+
+	suite.mockService.On("Login", mock.Anything, &dto.LoginReq{
+		Email:    req.Email,
+		Password: req.Password,
+	}).Return(nil, "", "", &service.LoginLockedError{RetryAfter: time.Minute}).Times(1)
+
+	res, err := suite.handler.Login(context.Background(), req)
+	suite.Nil(res)
+	suite.Equal(codes.ResourceExhausted, status.Code(err))
+}
+
+func (suite *UserHandlerTestSuite) TestUserAPI_LoginEmailNotVerified() {
+	req := &pb.LoginReq{
+		Email:    "login@test.com",
+		Password: "test123456",
+	}
+
+	suite.mockService.On("Login", mock.Anything, &dto.LoginReq{
+		Email:    req.Email,
+		Password: req.Password,
+	}).Return(nil, "", "", service.ErrEmailNotVerified).Times(1)
+
+	res, err := suite.handler.Login(context.Background(), req)
+	suite.Nil(res)
+	suite.Equal(codes.PermissionDenied, status.Code(err))
+}
+
 // Register
 // =================================================================================================
 
@@ -166,35 +225,88 @@ func (suite *UserHandlerTestSuite) TestUserAPI_GetMeFail() {
 //// =================================================================================================
 
 func (suite *UserHandlerTestSuite) TestUserAPI_RefreshTokenSuccess() {
-	userId := "123456"
-	ctx := context.WithValue(context.Background(), "userId", userId)
+	ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs("token", "refresh-token"))
 
-	suite.mockService.On("RefreshToken", mock.Anything, userId).
-		Return("access-token", nil).Times(1)
+	suite.mockService.On("RefreshToken", mock.Anything, "refresh-token").
+		Return("access-token", "new-refresh-token", nil).Times(1)
 
 	res, err := suite.handler.RefreshToken(ctx, &pb.RefreshTokenReq{})
 	suite.Nil(err)
 	suite.Equal("access-token", res.AccessToken)
+	suite.Equal("new-refresh-token", res.RefreshToken)
 }
 
-func (suite *UserHandlerTestSuite) TestUserAPI_RefreshTokenUnauthorized() {
+func (suite *UserHandlerTestSuite) TestUserAPI_RefreshTokenInvalid() {
+	suite.mockService.On("RefreshToken", mock.Anything, "").
+		Return("", "", service.ErrInvalidRefreshToken).Times(1)
+
 	res, err := suite.handler.RefreshToken(context.Background(), &pb.RefreshTokenReq{})
 	suite.Nil(res)
-	suite.NotNil(err)
+	suite.Equal(codes.Unauthenticated, status.Code(err))
 }
 
 func (suite *UserHandlerTestSuite) TestUserAPI_RefreshTokenFail() {
-	userId := "123456"
-	ctx := context.WithValue(context.Background(), "userId", userId)
+	ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs("token", "refresh-token"))
 
-	suite.mockService.On("RefreshToken", mock.Anything, "123456").
-		Return("", errors.New("error")).Times(1)
+	suite.mockService.On("RefreshToken", mock.Anything, "refresh-token").
+		Return("", "", errors.New("error")).Times(1)
 
 	res, err := suite.handler.RefreshToken(ctx, &pb.RefreshTokenReq{})
 	suite.Nil(res)
 	suite.NotNil(err)
 }
 
+//// Logout
+//// =================================================================================================
+
+func (suite *UserHandlerTestSuite) TestUserAPI_LogoutSuccess() {
+	ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs("token", "refresh-token"))
+
+	suite.mockService.On("Logout", mock.Anything, "refresh-token").
+		Return(nil).Times(1)
+
+	res, err := suite.handler.Logout(ctx, &pb.LogoutReq{})
+	suite.Nil(err)
+	suite.NotNil(res)
+}
+
+func (suite *UserHandlerTestSuite) TestUserAPI_LogoutInvalidToken() {
+	suite.mockService.On("Logout", mock.Anything, "").
+		Return(service.ErrInvalidRefreshToken).Times(1)
+
+	res, err := suite.handler.Logout(context.Background(), &pb.LogoutReq{})
+	suite.Nil(res)
+	suite.Equal(codes.Unauthenticated, status.Code(err))
+}
+
+func (suite *UserHandlerTestSuite) TestUserAPI_LogoutAllSuccess() {
+	ctx := context.WithValue(context.Background(), "userId", "123456")
+
+	suite.mockService.On("LogoutAll", mock.Anything, "123456").
+		Return(nil).Times(1)
+
+	res, err := suite.handler.LogoutAll(ctx, &pb.LogoutAllReq{})
+	suite.Nil(err)
+	suite.NotNil(res)
+}
+
+func (suite *UserHandlerTestSuite) TestUserAPI_LogoutAllUnauthorized() {
+	res, err := suite.handler.LogoutAll(context.Background(), &pb.LogoutAllReq{})
+	suite.Nil(res)
+	suite.NotNil(err)
+}
+
+func (suite *UserHandlerTestSuite) TestUserAPI_LogoutAllFail() {
+	ctx := context.WithValue(context.Background(), "userId", "123456")
+
+	suite.mockService.On("LogoutAll", mock.Anything, "123456").
+		Return(errors.New("error")).Times(1)
+
+	res, err := suite.handler.LogoutAll(ctx, &pb.LogoutAllReq{})
+	suite.Nil(res)
+	suite.NotNil(err)
+}
+
 //// Change Password
 //// =================================================================================================
 
@@ -4,15 +4,48 @@ import (
 	"github.com/quangdangfit/gocommon/validation"
 	"google.golang.org/grpc"
 
+	cartRepository "goshop/internal/cart/repository"
+	cartService "goshop/internal/cart/service"
 	"goshop/internal/user/repository"
 	"goshop/internal/user/service"
+	"goshop/pkg/config"
 	"goshop/pkg/dbs"
+	"goshop/pkg/mailer"
+	"goshop/pkg/redis"
 	pb "goshop/proto/gen/go/user"
 )
 
-func RegisterHandlers(svr *grpc.Server, db dbs.IDatabase, validator validation.Validation) {
+func RegisterHandlers(svr *grpc.Server, db dbs.IDatabase, validator validation.Validation, cache redis.IRedis, mail mailer.Mailer) {
+	cfg := config.GetConfig()
 	userRepo := repository.NewUserRepository(db)
-	userSvc := service.NewUserService(validator, userRepo)
+	userSvc := service.NewUserService(
+		validator,
+		db,
+		userRepo,
+		repository.NewRefreshTokenRepository(db),
+		repository.NewUserTokenRepository(db),
+		repository.NewLoginAttemptRepository(cache),
+		service.Options{
+			Mailer:               mail,
+			PasswordResetTTL:     cfg.PasswordResetTTL,
+			PasswordResetURL:     cfg.PasswordResetURL,
+			EmailVerificationTTL: cfg.EmailVerificationTTL,
+			EmailVerificationURL: cfg.EmailVerificationURL,
+			VerificationPolicy:   service.VerificationPolicy(cfg.EmailVerificationPolicy),
+			LoginLimits: service.LoginLimits{
+				MaxAttempts:   cfg.LoginMaxAttempts,
+				IPMaxAttempts: cfg.LoginIPMaxAttempts,
+				Backoff:       cfg.LoginBackoff,
+				Lockout:       cfg.LoginLockout,
+			},
+		},
+	)
+	cartMerger := cartService.NewGuestCartMerger(
+		cartRepository.NewCartRepository(db),
+		cartRepository.NewGuestCartRepository(cache, cfg.GuestCartTTL),
+		cartService.MergePolicy(cfg.CartMergePolicy),
+	)
+	userSvc.OnSignIn(cartMerger.MergeGuestCart)
 	userHandler := NewUserHandler(userSvc)
 
 	pb.RegisterUserServiceServer(svr, userHandler)
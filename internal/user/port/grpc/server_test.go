@@ -6,10 +6,12 @@ import (
 	"github.com/quangdangfit/gocommon/validation"
 	goGRPC "google.golang.org/grpc"
 
-	"goshop/pkg/dbs/mocks"
+	dbMocks "goshop/pkg/dbs/mocks"
+	mailerMocks "goshop/pkg/mailer/mocks"
+	redisMocks "goshop/pkg/redis/mocks"
 )
 
 func TestRegisterHandlers(t *testing.T) {
-	mockDB := mocks.NewIDatabase(t)
-	RegisterHandlers(goGRPC.NewServer(), mockDB, validation.New())
+	mockDB := dbMocks.NewIDatabase(t)
+	RegisterHandlers(goGRPC.NewServer(), mockDB, validation.New(), redisMocks.NewIRedis(t), mailerMocks.NewMailer(t))
 }
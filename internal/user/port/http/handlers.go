@@ -2,13 +2,16 @@ package http
 
 import (
 	"errors"
+	"math"
 	"net/http"
+	"strconv"
 
 	"github.com/gin-gonic/gin"
 	"github.com/quangdangfit/gocommon/logger"
 
 	"goshop/internal/user/dto"
 	"goshop/internal/user/service"
+	"goshop/pkg/middleware"
 	"goshop/pkg/response"
 	"goshop/pkg/utils"
 )
@@ -28,7 +31,8 @@ func NewUserHandler(service service.IUserService) *UserHandler {
 //	@Summary	Login
 //	@Tags		users
 //	@Produce	json
-//	@Param		_	body		dto.LoginReq	true	"Body"
+//	@Param		X-Guest-Token	header		string			false	"Guest token, to merge the guest cart"
+//	@Param		_				body		dto.LoginReq	true	"Body"
 //	@Success	200	{object}	dto.LoginRes
 //	@Router		/api/v1/auth/login [post]
 func (h *UserHandler) Login(c *gin.Context) {
@@ -38,10 +42,26 @@ func (h *UserHandler) Login(c *gin.Context) {
 		response.Error(c, http.StatusBadRequest, err, "Invalid parameters")
 		return
 	}
+	req.GuestToken = c.GetHeader(middleware.GuestTokenHeader)
+	req.IP = c.ClientIP()
 
 	user, accessToken, refreshToken, err := h.service.Login(c, &req)
 	if err != nil {
 		logger.Error("Failed to login ", err)
+		var lockedErr *service.LoginLockedError
+		if errors.As(err, &lockedErr) {
+			c.Header("Retry-After", strconv.Itoa(int(math.Ceil(lockedErr.RetryAfter.Seconds()))))
+			response.Error(c, http.StatusTooManyRequests, err, "Too many failed attempts, try again later")
+			return
+		}
+		if errors.Is(err, service.ErrInvalidCredentials) {
+			response.Error(c, http.StatusUnauthorized, err, "Invalid email or password")
+			return
+		}
+		if errors.Is(err, service.ErrEmailNotVerified) {
+			response.Error(c, http.StatusForbidden, err, "Email not verified")
+			return
+		}
 		response.Error(c, http.StatusInternalServerError, err, "Something went wrong")
 		return
 	}
@@ -58,7 +78,8 @@ func (h *UserHandler) Login(c *gin.Context) {
 //	@Summary	Register new user
 //	@Tags		users
 //	@Produce	json
-//	@Param		_	body		dto.RegisterReq	true	"Body"
+//	@Param		X-Guest-Token	header		string			false	"Guest token, to merge the guest cart"
+//	@Param		_				body		dto.RegisterReq	true	"Body"
 //	@Success	200	{object}	dto.RegisterRes
 //	@Router		/api/v1/auth/register [post]
 func (h *UserHandler) Register(c *gin.Context) {
@@ -68,6 +89,7 @@ func (h *UserHandler) Register(c *gin.Context) {
 		response.Error(c, http.StatusBadRequest, err, "Invalid parameters")
 		return
 	}
+	req.GuestToken = c.GetHeader(middleware.GuestTokenHeader)
 
 	user, err := h.service.Register(c, &req)
 	if err != nil {
@@ -108,26 +130,76 @@ func (h *UserHandler) GetMe(c *gin.Context) {
 	response.JSON(c, http.StatusOK, res)
 }
 
+// RefreshToken godoc
+//
+//	@Summary	rotates the refresh token sent as bearer token
+//	@Tags		users
+//	@Security	ApiKeyAuth
+//	@Produce	json
+//	@Success	200	{object}	dto.RefreshTokenRes
+//	@Router		/api/v1/auth/refresh [post]
 func (h *UserHandler) RefreshToken(c *gin.Context) {
-	userID := c.GetString("userId")
-	if userID == "" {
-		response.Error(c, http.StatusUnauthorized, errors.New("unauthorized"), "Unauthorized")
-		return
-	}
-
-	accessToken, err := h.service.RefreshToken(c, userID)
+	accessToken, refreshToken, err := h.service.RefreshToken(c, c.GetHeader("Authorization"))
 	if err != nil {
 		logger.Error("Failed to refresh token", err)
+		if errors.Is(err, service.ErrInvalidRefreshToken) {
+			response.Error(c, http.StatusUnauthorized, err, "Unauthorized")
+			return
+		}
 		response.Error(c, http.StatusInternalServerError, err, "Something went wrong")
 		return
 	}
 
 	res := dto.RefreshTokenRes{
-		AccessToken: accessToken,
+		AccessToken:  accessToken,
+		RefreshToken: refreshToken,
 	}
 	response.JSON(c, http.StatusOK, res)
 }
 
+// Logout godoc
+//
+//	@Summary	revokes the refresh token sent as bearer token and the tokens rotated along with it
+//	@Tags		users
+//	@Security	ApiKeyAuth
+//	@Produce	json
+//	@Router		/api/v1/auth/logout [post]
+func (h *UserHandler) Logout(c *gin.Context) {
+	err := h.service.Logout(c, c.GetHeader("Authorization"))
+	if err != nil {
+		logger.Error("Failed to logout", err)
+		if errors.Is(err, service.ErrInvalidRefreshToken) {
+			response.Error(c, http.StatusUnauthorized, err, "Unauthorized")
+			return
+		}
+		response.Error(c, http.StatusInternalServerError, err, "Something went wrong")
+		return
+	}
+	response.JSON(c, http.StatusOK, nil)
+}
+
+// LogoutAll godoc
+//
+//	@Summary	revokes every refresh token of the user
+//	@Tags		users
+//	@Security	ApiKeyAuth
+//	@Produce	json
+//	@Router		/api/v1/auth/logout-all [post]
+func (h *UserHandler) LogoutAll(c *gin.Context) {
+	userID := c.GetString("userId")
+	if userID == "" {
+		response.Error(c, http.StatusUnauthorized, errors.New("unauthorized"), "Unauthorized")
+		return
+	}
+
+	if err := h.service.LogoutAll(c, userID); err != nil {
+		logger.Error("Failed to logout everywhere", err)
+		response.Error(c, http.StatusInternalServerError, err, "Something went wrong")
+		return
+	}
+	response.JSON(c, http.StatusOK, nil)
+}
+
 // ChangePassword godoc
 //
 //	@Summary	changes the password
@@ -153,3 +225,127 @@ func (h *UserHandler) ChangePassword(c *gin.Context) {
 	}
 	response.JSON(c, http.StatusOK, nil)
 }
+
+// ForgotPassword godoc
+//
+//	@Summary	emails a password reset token, the response is the same whether the email is registered or not
+//	@Tags		users
+//	@Produce	json
+//	@Param		_	body	dto.ForgotPasswordReq	true	"Body"
+//	@Router		/api/v1/auth/forgot-password [post]
+func (h *UserHandler) ForgotPassword(c *gin.Context) {
+	var req dto.ForgotPasswordReq
+	if err := c.ShouldBindJSON(&req); c.Request.Body == nil || err != nil {
+		logger.Error("Failed to get body", err)
+		response.Error(c, http.StatusBadRequest, err, "Invalid parameters")
+		return
+	}
+
+	if err := h.service.ForgotPassword(c, &req); err != nil {
+		logger.Error(err.Error())
+		response.Error(c, http.StatusInternalServerError, err, "Something went wrong")
+		return
+	}
+	response.JSON(c, http.StatusOK, nil)
+}
+
+// ResetPassword godoc
+//
+//	@Summary	sets a new password with a reset token
+//	@Tags		users
+//	@Produce	json
+//	@Param		_	body	dto.ResetPasswordReq	true	"Body"
+//	@Router		/api/v1/auth/reset-password [post]
+func (h *UserHandler) ResetPassword(c *gin.Context) {
+	var req dto.ResetPasswordReq
+	if err := c.ShouldBindJSON(&req); c.Request.Body == nil || err != nil {
+		logger.Error("Failed to get body", err)
+		response.Error(c, http.StatusBadRequest, err, "Invalid parameters")
+		return
+	}
+
+	if err := h.service.ResetPassword(c, &req); err != nil {
+		logger.Error(err.Error())
+		if errors.Is(err, service.ErrInvalidResetToken) {
+			response.Error(c, http.StatusBadRequest, err, "Invalid or expired token")
+			return
+		}
+		response.Error(c, http.StatusInternalServerError, err, "Something went wrong")
+		return
+	}
+	response.JSON(c, http.StatusOK, nil)
+}
+
+// VerifyEmail godoc
+//
+//	@Summary	verifies the email of the user with the token sent to it
+//	@Tags		users
+//	@Produce	json
+//	@Param		_	body	dto.VerifyEmailReq	true	"Body"
+//	@Router		/api/v1/auth/verify-email [post]
+func (h *UserHandler) VerifyEmail(c *gin.Context) {
+	var req dto.VerifyEmailReq
+	if err := c.ShouldBindJSON(&req); c.Request.Body == nil || err != nil {
+		logger.Error("Failed to get body", err)
+		response.Error(c, http.StatusBadRequest, err, "Invalid parameters")
+		return
+	}
+
+	if err := h.service.VerifyEmail(c, &req); err != nil {
+		logger.Error(err.Error())
+		if errors.Is(err, service.ErrInvalidVerificationToken) {
+			response.Error(c, http.StatusBadRequest, err, "Invalid or expired token")
+			return
+		}
+		response.Error(c, http.StatusInternalServerError, err, "Something went wrong")
+		return
+	}
+	response.JSON(c, http.StatusOK, nil)
+}
+
+// ResendVerification godoc
+//
+//	@Summary	emails a new verification token, the response is the same whether the email is registered or not
+//	@Tags		users
+//	@Produce	json
+//	@Param		_	body	dto.ResendVerificationReq	true	"Body"
+//	@Router		/api/v1/auth/resend-verification [post]
+func (h *UserHandler) ResendVerification(c *gin.Context) {
+	var req dto.ResendVerificationReq
+	if err := c.ShouldBindJSON(&req); c.Request.Body == nil || err != nil {
+		logger.Error("Failed to get body", err)
+		response.Error(c, http.StatusBadRequest, err, "Invalid parameters")
+		return
+	}
+
+	if err := h.service.ResendVerification(c, &req); err != nil {
+		logger.Error(err.Error())
+		response.Error(c, http.StatusInternalServerError, err, "Something went wrong")
+		return
+	}
+	response.JSON(c, http.StatusOK, nil)
+}
+
+// UnlockLogin godoc
+//
+//	@Summary	unlocks the logins of an account or an IP locked after failed attempts
+//	@Tags		users
+//	@Security	ApiKeyAuth
+//	@Produce	json
+//	@Param		_	body	dto.UnlockLoginReq	true	"Body"
+//	@Router		/api/v1/users/unlock [post]
+func (h *UserHandler) UnlockLogin(c *gin.Context) {
+	var req dto.UnlockLoginReq
+	if err := c.ShouldBindJSON(&req); c.Request.Body == nil || err != nil {
+		logger.Error("Failed to get body", err)
+		response.Error(c, http.StatusBadRequest, err, "Invalid parameters")
+		return
+	}
+
+	if err := h.service.UnlockLogin(c, &req); err != nil {
+		logger.Error(err.Error())
+		response.Error(c, http.StatusInternalServerError, err, "Something went wrong")
+		return
+	}
+	response.JSON(c, http.StatusOK, nil)
+}
@@ -7,6 +7,7 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/quangdangfit/gocommon/logger"
@@ -15,6 +16,7 @@ import (
 
 	"goshop/internal/user/dto"
 	"goshop/internal/user/model"
+	"goshop/internal/user/service"
 	"goshop/internal/user/service/mocks"
 	"goshop/pkg/config"
 	"goshop/pkg/response"
@@ -55,6 +57,7 @@ func (suite *UserHandlerTestSuite) TestLoginSuccess() {
 	req := &dto.LoginReq{
 		Email:    "login@test.com",
 		Password: "test123456",
+		IP:       "192.0.2.1",
 	}
 
 	ctx, writer := suite.prepareContext(req)
@@ -119,6 +122,7 @@ func (suite *UserHandlerTestSuite) TestLoginFail() {
 	req := &dto.LoginReq{
 		Email:    "login@test.com",
 		Password: "test123456",
+		IP:       "192.0.2.1",
 	}
 
 	ctx, writer := suite.prepareContext(req)
@@ -134,6 +138,64 @@ func (suite *UserHandlerTestSuite) TestLoginFail() {
 	suite.Equal("Something went wrong", res["error"]["message"])
 }
 
+func (suite *UserHandlerTestSuite) TestLoginInvalidCredentials() {
+	req := &dto.LoginReq{
+		Email:    "login@test.com",
+		Password: "test123456",
+		IP:       "192.0.2.1",
+	}
+
+	ctx, writer := suite.prepareContext(req)
+
+	suite.mockService.On("Login", mock.Anything, req).
+		Return(nil, "", "", service.ErrInvalidCredentials).Times(1)
+
+	suite.handler.Login(ctx)
+
+	var res map[string]map[string]string
+	_ = json.Unmarshal(writer.Body.Bytes(), &res)
+	suite.Equal(http.StatusUnauthorized, writer.Code)
+	suite.Equal("Invalid email or password", res["error"]["message"])
+}
+
+func (suite *UserHandlerTestSuite) TestLoginLocked() {
+	req := &dto.LoginReq{
+		Email:    "login@test.com",
+		Password: "test123456",
+		IP:       "192.0.2.1",
+	}
+
+	ctx, writer := suite.prepareContext(req)
+
+	suite.mockService.On("Login", mock.Anything, req).
+		Return(nil, "", "", &service.LoginLockedError{RetryAfter: 1500 * time.Millisecond}).Times(1)
+
+	suite.handler.Login(ctx)
+
+	suite.Equal(http.StatusTooManyRequests, writer.Code)
+	suite.Equal("2", writer.Header().Get("Retry-After"))
+}
+
+func (suite *UserHandlerTestSuite) TestLoginEmailNotVerified() {
+	req := &dto.LoginReq{
+		Email:    "login@test.com",
+		Password: "test123456",
+		IP:       "192.0.2.1",
+	}
+
+	ctx, writer := suite.prepareContext(req)
+
+	suite.mockService.On("Login", mock.Anything, req).
+		Return(nil, "", "", service.ErrEmailNotVerified).Times(1)
+
+	suite.handler.Login(ctx)
+
+	var res map[string]map[string]string
+	_ = json.Unmarshal(writer.Body.Bytes(), &res)
+	suite.Equal(http.StatusForbidden, writer.Code)
+	suite.Equal("Email not verified", res["error"]["message"])
+}
+
 // Register
 // =================================================================================================
 
@@ -275,24 +337,29 @@ func (suite *UserHandlerTestSuite) TestGetMeFail() {
 
 func (suite *UserHandlerTestSuite) TestRefreshTokenSuccess() {
 	ctx, writer := suite.prepareContext(nil)
-	ctx.Set("userId", "123456")
+	ctx.Request.Header.Set("Authorization", "Bearer refresh-token")
 
-	suite.mockService.On("RefreshToken", mock.Anything, "123456").
-		Return("access-token", nil).Times(1)
+	suite.mockService.On("RefreshToken", mock.Anything, "Bearer refresh-token").
+		Return("access-token", "new-refresh-token", nil).Times(1)
 
 	suite.handler.RefreshToken(ctx)
 
 	var res response.Response
-	var getMeRes dto.RefreshTokenRes
+	var refreshRes dto.RefreshTokenRes
 
 	_ = json.Unmarshal(writer.Body.Bytes(), &res)
-	utils.Copy(&getMeRes, &res.Result)
+	utils.Copy(&refreshRes, &res.Result)
 	suite.Equal(http.StatusOK, writer.Code)
-	suite.Equal("access-token", getMeRes.AccessToken)
+	suite.Equal("access-token", refreshRes.AccessToken)
+	suite.Equal("new-refresh-token", refreshRes.RefreshToken)
 }
 
-func (suite *UserHandlerTestSuite) TestRefreshTokenUnauthorized() {
+func (suite *UserHandlerTestSuite) TestRefreshTokenInvalid() {
 	ctx, writer := suite.prepareContext(nil)
+
+	suite.mockService.On("RefreshToken", mock.Anything, "").
+		Return("", "", service.ErrInvalidRefreshToken).Times(1)
+
 	suite.handler.RefreshToken(ctx)
 
 	var res map[string]map[string]string
@@ -303,10 +370,10 @@ func (suite *UserHandlerTestSuite) TestRefreshTokenUnauthorized() {
 
 func (suite *UserHandlerTestSuite) TestRefreshTokenFail() {
 	ctx, writer := suite.prepareContext(nil)
-	ctx.Set("userId", "123456")
+	ctx.Request.Header.Set("Authorization", "Bearer refresh-token")
 
-	suite.mockService.On("RefreshToken", mock.Anything, "123456").
-		Return("", errors.New("error")).Times(1)
+	suite.mockService.On("RefreshToken", mock.Anything, "Bearer refresh-token").
+		Return("", "", errors.New("error")).Times(1)
 
 	suite.handler.RefreshToken(ctx)
 
@@ -316,6 +383,69 @@ func (suite *UserHandlerTestSuite) TestRefreshTokenFail() {
 	suite.Equal("Something went wrong", res["error"]["message"])
 }
 
+// Logout
+// =================================================================================================
+
+func (suite *UserHandlerTestSuite) TestLogoutSuccess() {
+	ctx, writer := suite.prepareContext(nil)
+	ctx.Request.Header.Set("Authorization", "Bearer refresh-token")
+
+	suite.mockService.On("Logout", mock.Anything, "Bearer refresh-token").
+		Return(nil).Times(1)
+
+	suite.handler.Logout(ctx)
+	suite.Equal(http.StatusOK, writer.Code)
+}
+
+func (suite *UserHandlerTestSuite) TestLogoutInvalidToken() {
+	ctx, writer := suite.prepareContext(nil)
+
+	suite.mockService.On("Logout", mock.Anything, "").
+		Return(service.ErrInvalidRefreshToken).Times(1)
+
+	suite.handler.Logout(ctx)
+	suite.Equal(http.StatusUnauthorized, writer.Code)
+}
+
+func (suite *UserHandlerTestSuite) TestLogoutFail() {
+	ctx, writer := suite.prepareContext(nil)
+
+	suite.mockService.On("Logout", mock.Anything, "").
+		Return(errors.New("error")).Times(1)
+
+	suite.handler.Logout(ctx)
+	suite.Equal(http.StatusInternalServerError, writer.Code)
+}
+
+func (suite *UserHandlerTestSuite) TestLogoutAllSuccess() {
+	ctx, writer := suite.prepareContext(nil)
+	ctx.Set("userId", "123456")
+
+	suite.mockService.On("LogoutAll", mock.Anything, "123456").
+		Return(nil).Times(1)
+
+	suite.handler.LogoutAll(ctx)
+	suite.Equal(http.StatusOK, writer.Code)
+}
+
+func (suite *UserHandlerTestSuite) TestLogoutAllUnauthorized() {
+	ctx, writer := suite.prepareContext(nil)
+
+	suite.handler.LogoutAll(ctx)
+	suite.Equal(http.StatusUnauthorized, writer.Code)
+}
+
+func (suite *UserHandlerTestSuite) TestLogoutAllFail() {
+	ctx, writer := suite.prepareContext(nil)
+	ctx.Set("userId", "123456")
+
+	suite.mockService.On("LogoutAll", mock.Anything, "123456").
+		Return(errors.New("error")).Times(1)
+
+	suite.handler.LogoutAll(ctx)
+	suite.Equal(http.StatusInternalServerError, writer.Code)
+}
+
 // Change Password
 // =================================================================================================
 
@@ -370,3 +500,193 @@ func (suite *UserHandlerTestSuite) TestChangePasswordFail() {
 	suite.Equal(http.StatusInternalServerError, writer.Code)
 	suite.Equal("Something went wrong", res["error"]["message"])
 }
+
+// Forgot Password
+// =================================================================================================
+
+func (suite *UserHandlerTestSuite) TestForgotPasswordSuccess() {
+	req := &dto.ForgotPasswordReq{Email: "test@test.com"}
+
+	ctx, writer := suite.prepareContext(req)
+	suite.mockService.On("ForgotPassword", mock.Anything, req).
+		Return(nil).Times(1)
+
+	suite.handler.ForgotPassword(ctx)
+	suite.Equal(http.StatusOK, writer.Code)
+}
+
+func (suite *UserHandlerTestSuite) TestForgotPasswordInvalidEmailType() {
+	ctx, writer := suite.prepareContext(map[string]interface{}{"email": 12345})
+
+	suite.handler.ForgotPassword(ctx)
+	suite.Equal(http.StatusBadRequest, writer.Code)
+}
+
+func (suite *UserHandlerTestSuite) TestForgotPasswordFail() {
+	req := &dto.ForgotPasswordReq{Email: "test@test.com"}
+
+	ctx, writer := suite.prepareContext(req)
+	suite.mockService.On("ForgotPassword", mock.Anything, req).
+		Return(errors.New("error")).Times(1)
+
+	suite.handler.ForgotPassword(ctx)
+	suite.Equal(http.StatusInternalServerError, writer.Code)
+}
+
+// Reset Password
+// =================================================================================================
+
+func (suite *UserHandlerTestSuite) TestResetPasswordSuccess() {
+	req := &dto.ResetPasswordReq{Token: "token", NewPassword: "new-test123456"}
+
+	ctx, writer := suite.prepareContext(req)
+	suite.mockService.On("ResetPassword", mock.Anything, req).
+		Return(nil).Times(1)
+
+	suite.handler.ResetPassword(ctx)
+	suite.Equal(http.StatusOK, writer.Code)
+}
+
+func (suite *UserHandlerTestSuite) TestResetPasswordInvalidToken() {
+	req := &dto.ResetPasswordReq{Token: "token", NewPassword: "new-test123456"}
+
+	ctx, writer := suite.prepareContext(req)
+	suite.mockService.On("ResetPassword", mock.Anything, req).
+		Return(service.ErrInvalidResetToken).Times(1)
+
+	suite.handler.ResetPassword(ctx)
+
+	var res map[string]map[string]string
+	_ = json.Unmarshal(writer.Body.Bytes(), &res)
+	suite.Equal(http.StatusBadRequest, writer.Code)
+	suite.Equal("Invalid or expired token", res["error"]["message"])
+}
+
+func (suite *UserHandlerTestSuite) TestResetPasswordInvalidBody() {
+	ctx, writer := suite.prepareContext(map[string]interface{}{"token": 12345})
+
+	suite.handler.ResetPassword(ctx)
+	suite.Equal(http.StatusBadRequest, writer.Code)
+}
+
+func (suite *UserHandlerTestSuite) TestResetPasswordFail() {
+	req := &dto.ResetPasswordReq{Token: "token", NewPassword: "new-test123456"}
+
+	ctx, writer := suite.prepareContext(req)
+	suite.mockService.On("ResetPassword", mock.Anything, req).
+		Return(errors.New("error")).Times(1)
+
+	suite.handler.ResetPassword(ctx)
+	suite.Equal(http.StatusInternalServerError, writer.Code)
+}
+
+// Verify Email
+// =================================================================================================
+
+func (suite *UserHandlerTestSuite) TestVerifyEmailSuccess() {
+	req := &dto.VerifyEmailReq{Token: "token"}
+
+	ctx, writer := suite.prepareContext(req)
+	suite.mockService.On("VerifyEmail", mock.Anything, req).
+		Return(nil).Times(1)
+
+	suite.handler.VerifyEmail(ctx)
+	suite.Equal(http.StatusOK, writer.Code)
+}
+
+func (suite *UserHandlerTestSuite) TestVerifyEmailInvalidToken() {
+	req := &dto.VerifyEmailReq{Token: "token"}
+
+	ctx, writer := suite.prepareContext(req)
+	suite.mockService.On("VerifyEmail", mock.Anything, req).
+		Return(service.ErrInvalidVerificationToken).Times(1)
+
+	suite.handler.VerifyEmail(ctx)
+
+	var res map[string]map[string]string
+	_ = json.Unmarshal(writer.Body.Bytes(), &res)
+	suite.Equal(http.StatusBadRequest, writer.Code)
+	suite.Equal("Invalid or expired token", res["error"]["message"])
+}
+
+func (suite *UserHandlerTestSuite) TestVerifyEmailInvalidBody() {
+	ctx, writer := suite.prepareContext(map[string]interface{}{"token": 12345})
+
+	suite.handler.VerifyEmail(ctx)
+	suite.Equal(http.StatusBadRequest, writer.Code)
+}
+
+func (suite *UserHandlerTestSuite) TestVerifyEmailFail() {
+	req := &dto.VerifyEmailReq{Token: "token"}
+
+	ctx, writer := suite.prepareContext(req)
+	suite.mockService.On("VerifyEmail", mock.Anything, req).
+		Return(errors.New("error")).Times(1)
+
+	suite.handler.VerifyEmail(ctx)
+	suite.Equal(http.StatusInternalServerError, writer.Code)
+}
+
+// Resend Verification
+// =================================================================================================
+
+func (suite *UserHandlerTestSuite) TestResendVerificationSuccess() {
+	req := &dto.ResendVerificationReq{Email: "test@test.com"}
+
+	ctx, writer := suite.prepareContext(req)
+	suite.mockService.On("ResendVerification", mock.Anything, req).
+		Return(nil).Times(1)
+
+	suite.handler.ResendVerification(ctx)
+	suite.Equal(http.StatusOK, writer.Code)
+}
+
+func (suite *UserHandlerTestSuite) TestResendVerificationInvalidEmailType() {
+	ctx, writer := suite.prepareContext(map[string]interface{}{"email": 12345})
+
+	suite.handler.ResendVerification(ctx)
+	suite.Equal(http.StatusBadRequest, writer.Code)
+}
+
+func (suite *UserHandlerTestSuite) TestResendVerificationFail() {
+	req := &dto.ResendVerificationReq{Email: "test@test.com"}
+
+	ctx, writer := suite.prepareContext(req)
+	suite.mockService.On("ResendVerification", mock.Anything, req).
+		Return(errors.New("error")).Times(1)
+
+	suite.handler.ResendVerification(ctx)
+	suite.Equal(http.StatusInternalServerError, writer.Code)
+}
+
+// Unlock Login
+// =================================================================================================
+
+func (suite *UserHandlerTestSuite) TestUnlockLoginSuccess() {
+	req := &dto.UnlockLoginReq{Email: "test@test.com"}
+
+	ctx, writer := suite.prepareContext(req)
+	suite.mockService.On("UnlockLogin", mock.Anything, req).
+		Return(nil).Times(1)
+
+	suite.handler.UnlockLogin(ctx)
+	suite.Equal(http.StatusOK, writer.Code)
+}
+
+func (suite *UserHandlerTestSuite) TestUnlockLoginInvalidBody() {
+	ctx, writer := suite.prepareContext(map[string]interface{}{"ip": 12345})
+
+	suite.handler.UnlockLogin(ctx)
+	suite.Equal(http.StatusBadRequest, writer.Code)
+}
+
+func (suite *UserHandlerTestSuite) TestUnlockLoginFail() {
+	req := &dto.UnlockLoginReq{IP: "192.0.2.1"}
+
+	ctx, writer := suite.prepareContext(req)
+	suite.mockService.On("UnlockLogin", mock.Anything, req).
+		Return(errors.New("error")).Times(1)
+
+	suite.handler.UnlockLogin(ctx)
+	suite.Equal(http.StatusInternalServerError, writer.Code)
+}
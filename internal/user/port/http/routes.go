@@ -4,15 +4,49 @@ import (
 	"github.com/gin-gonic/gin"
 	"github.com/quangdangfit/gocommon/validation"
 
+	cartRepository "goshop/internal/cart/repository"
+	cartService "goshop/internal/cart/service"
 	"goshop/internal/user/repository"
 	"goshop/internal/user/service"
+	"goshop/pkg/config"
 	"goshop/pkg/dbs"
+	"goshop/pkg/mailer"
 	"goshop/pkg/middleware"
+	"goshop/pkg/rbac"
+	"goshop/pkg/redis"
 )
 
-func Routes(r *gin.RouterGroup, sqlDB dbs.IDatabase, validator validation.Validation) {
+func Routes(r *gin.RouterGroup, sqlDB dbs.IDatabase, validator validation.Validation, cache redis.IRedis, mail mailer.Mailer) {
+	cfg := config.GetConfig()
 	userRepo := repository.NewUserRepository(sqlDB)
-	userSvc := service.NewUserService(validator, userRepo)
+	userSvc := service.NewUserService(
+		validator,
+		sqlDB,
+		userRepo,
+		repository.NewRefreshTokenRepository(sqlDB),
+		repository.NewUserTokenRepository(sqlDB),
+		repository.NewLoginAttemptRepository(cache),
+		service.Options{
+			Mailer:               mail,
+			PasswordResetTTL:     cfg.PasswordResetTTL,
+			PasswordResetURL:     cfg.PasswordResetURL,
+			EmailVerificationTTL: cfg.EmailVerificationTTL,
+			EmailVerificationURL: cfg.EmailVerificationURL,
+			VerificationPolicy:   service.VerificationPolicy(cfg.EmailVerificationPolicy),
+			LoginLimits: service.LoginLimits{
+				MaxAttempts:   cfg.LoginMaxAttempts,
+				IPMaxAttempts: cfg.LoginIPMaxAttempts,
+				Backoff:       cfg.LoginBackoff,
+				Lockout:       cfg.LoginLockout,
+			},
+		},
+	)
+	cartMerger := cartService.NewGuestCartMerger(
+		cartRepository.NewCartRepository(sqlDB),
+		cartRepository.NewGuestCartRepository(cache, cfg.GuestCartTTL),
+		cartService.MergePolicy(cfg.CartMergePolicy),
+	)
+	userSvc.OnSignIn(cartMerger.MergeGuestCart)
 	userHandler := NewUserHandler(userSvc)
 
 	authMiddleware := middleware.JWTAuth()
@@ -21,8 +55,19 @@ func Routes(r *gin.RouterGroup, sqlDB dbs.IDatabase, validator validation.Valida
 	{
 		authRoute.POST("/register", userHandler.Register)
 		authRoute.POST("/login", userHandler.Login)
+		authRoute.POST("/forgot-password", userHandler.ForgotPassword)
+		authRoute.POST("/reset-password", userHandler.ResetPassword)
+		authRoute.POST("/verify-email", userHandler.VerifyEmail)
+		authRoute.POST("/resend-verification", userHandler.ResendVerification)
 		authRoute.POST("/refresh", refreshAuthMiddleware, userHandler.RefreshToken)
+		authRoute.POST("/logout", refreshAuthMiddleware, userHandler.Logout)
+		authRoute.POST("/logout-all", authMiddleware, userHandler.LogoutAll)
 		authRoute.GET("/me", authMiddleware, userHandler.GetMe)
 		authRoute.PUT("/change-password", authMiddleware, userHandler.ChangePassword)
 	}
+
+	userRoute := r.Group("/users", authMiddleware, middleware.RequirePermission(rbac.PermissionUserManage))
+	{
+		userRoute.POST("/unlock", userHandler.UnlockLogin)
+	}
 }
@@ -6,10 +6,12 @@ import (
 	"github.com/gin-gonic/gin"
 	"github.com/quangdangfit/gocommon/validation"
 
-	"goshop/pkg/dbs/mocks"
+	dbMocks "goshop/pkg/dbs/mocks"
+	mailerMocks "goshop/pkg/mailer/mocks"
+	redisMocks "goshop/pkg/redis/mocks"
 )
 
 func TestRoutes(t *testing.T) {
-	mockDB := mocks.NewIDatabase(t)
-	Routes(gin.New().Group("/"), mockDB, validation.New())
+	mockDB := dbMocks.NewIDatabase(t)
+	Routes(gin.New().Group("/"), mockDB, validation.New(), redisMocks.NewIRedis(t), mailerMocks.NewMailer(t))
 }
@@ -0,0 +1,57 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"goshop/pkg/redis"
+)
+
+const (
+	loginFailuresKeyPrefix = "login_failures:"
+	loginLockKeyPrefix     = "login_lock:"
+)
+
+// ILoginAttemptRepository counts the failed logins of a key, an account or an IP, and locks it
+//
+//go:generate mockery --name=ILoginAttemptRepository
+type ILoginAttemptRepository interface {
+	LockedFor(ctx context.Context, key string) (time.Duration, error)
+	AddFailure(ctx context.Context, key string, window time.Duration) (int64, error)
+	Lock(ctx context.Context, key string, duration time.Duration) error
+	Reset(ctx context.Context, keys ...string) error
+}
+
+type LoginAttemptRepo struct {
+	cache redis.IRedis
+}
+
+func NewLoginAttemptRepository(cache redis.IRedis) *LoginAttemptRepo {
+	return &LoginAttemptRepo{cache: cache}
+}
+
+// LockedFor returns how long key stays locked, 0 when it is not
+func (r *LoginAttemptRepo) LockedFor(_ context.Context, key string) (time.Duration, error) {
+	return r.cache.TTL(loginLockKeyPrefix + key)
+}
+
+// AddFailure counts a failed login of key and returns the failures in a row, forgotten once
+// window passed without failure
+func (r *LoginAttemptRepo) AddFailure(_ context.Context, key string, window time.Duration) (int64, error) {
+	return r.cache.Incr(loginFailuresKeyPrefix+key, window)
+}
+
+// Lock refuses the logins of key for duration
+func (r *LoginAttemptRepo) Lock(_ context.Context, key string, duration time.Duration) error {
+	return r.cache.SetWithExpiration(loginLockKeyPrefix+key, true, duration)
+}
+
+// Reset forgets the failures of keys and unlocks them
+func (r *LoginAttemptRepo) Reset(_ context.Context, keys ...string) error {
+	redisKeys := make([]string, 0, 2*len(keys))
+	for _, key := range keys {
+		redisKeys = append(redisKeys, loginFailuresKeyPrefix+key, loginLockKeyPrefix+key)
+	}
+
+	return r.cache.Remove(redisKeys...)
+}
@@ -0,0 +1,46 @@
+package repository
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	redisMocks "goshop/pkg/redis/mocks"
+)
+
+func TestLoginAttemptLockedFor(t *testing.T) {
+	mockRedis := redisMocks.NewIRedis(t)
+	mockRedis.On("TTL", "login_lock:account:test@test.com").Return(time.Minute, nil).Times(1)
+
+	wait, err := NewLoginAttemptRepository(mockRedis).LockedFor(context.Background(), "account:test@test.com")
+	assert.Nil(t, err)
+	assert.Equal(t, time.Minute, wait)
+}
+
+func TestLoginAttemptAddFailure(t *testing.T) {
+	mockRedis := redisMocks.NewIRedis(t)
+	mockRedis.On("Incr", "login_failures:ip:127.0.0.1", 15*time.Minute).Return(int64(3), nil).Times(1)
+
+	failures, err := NewLoginAttemptRepository(mockRedis).AddFailure(context.Background(), "ip:127.0.0.1", 15*time.Minute)
+	assert.Nil(t, err)
+	assert.Equal(t, int64(3), failures)
+}
+
+func TestLoginAttemptLock(t *testing.T) {
+	mockRedis := redisMocks.NewIRedis(t)
+	mockRedis.On("SetWithExpiration", "login_lock:ip:127.0.0.1", true, time.Minute).Return(nil).Times(1)
+
+	err := NewLoginAttemptRepository(mockRedis).Lock(context.Background(), "ip:127.0.0.1", time.Minute)
+	assert.Nil(t, err)
+}
+
+func TestLoginAttemptReset(t *testing.T) {
+	mockRedis := redisMocks.NewIRedis(t)
+	mockRedis.On("Remove", "login_failures:account:test@test.com", "login_lock:account:test@test.com").
+		Return(nil).Times(1)
+
+	err := NewLoginAttemptRepository(mockRedis).Reset(context.Background(), "account:test@test.com")
+	assert.Nil(t, err)
+}
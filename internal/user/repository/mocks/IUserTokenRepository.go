@@ -0,0 +1,69 @@
+// Code generated by mockery v2.32.4. DO NOT EDIT.
+
+package mocks
+
+import (
+	context "context"
+	model "goshop/internal/user/model"
+
+	mock "github.com/stretchr/testify/mock"
+)
+
+// IUserTokenRepository is an autogenerated mock type for the IUserTokenRepository type
+type IUserTokenRepository struct {
+	mock.Mock
+}
+
+// Create provides a mock function with given fields: ctx, token
+func (_m *IUserTokenRepository) Create(ctx context.Context, token *model.UserToken) error {
+	ret := _m.Called(ctx, token)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *model.UserToken) error); ok {
+		r0 = rf(ctx, token)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// Use provides a mock function with given fields: ctx, purpose, tokenHash
+func (_m *IUserTokenRepository) Use(ctx context.Context, purpose model.UserTokenPurpose, tokenHash string) (*model.UserToken, error) {
+	ret := _m.Called(ctx, purpose, tokenHash)
+
+	var r0 *model.UserToken
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, model.UserTokenPurpose, string) (*model.UserToken, error)); ok {
+		return rf(ctx, purpose, tokenHash)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, model.UserTokenPurpose, string) *model.UserToken); ok {
+		r0 = rf(ctx, purpose, tokenHash)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*model.UserToken)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, model.UserTokenPurpose, string) error); ok {
+		r1 = rf(ctx, purpose, tokenHash)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// NewIUserTokenRepository creates a new instance of IUserTokenRepository. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewIUserTokenRepository(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *IUserTokenRepository {
+	mock := &IUserTokenRepository{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
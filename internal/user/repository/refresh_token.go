@@ -0,0 +1,72 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"goshop/internal/user/model"
+	"goshop/pkg/dbs"
+)
+
+//go:generate mockery --name=IRefreshTokenRepository
+type IRefreshTokenRepository interface {
+	Create(ctx context.Context, token *model.RefreshToken) error
+	Use(ctx context.Context, id string) (bool, error)
+	RevokeFamily(ctx context.Context, familyID string) error
+	RevokeUserTokens(ctx context.Context, userID string) error
+}
+
+type RefreshTokenRepo struct {
+	db dbs.IDatabase
+}
+
+func NewRefreshTokenRepository(db dbs.IDatabase) *RefreshTokenRepo {
+	return &RefreshTokenRepo{db: db}
+}
+
+func (r *RefreshTokenRepo) Create(ctx context.Context, token *model.RefreshToken) error {
+	return r.db.Create(ctx, token)
+}
+
+// Use marks the token used. The check is made by the database, so that a token refreshed
+// concurrently is only used once. It reports false, marking nothing, when the token was used,
+// revoked or expired, or is unknown.
+func (r *RefreshTokenRepo) Use(ctx context.Context, id string) (bool, error) {
+	now := time.Now()
+	updated, err := r.db.UpdateColumns(
+		ctx,
+		&model.RefreshToken{},
+		map[string]any{"used_at": now},
+		dbs.WithQuery(
+			dbs.NewQuery("id = ?", id),
+			dbs.NewQuery("used_at IS NULL"),
+			dbs.NewQuery("revoked_at IS NULL"),
+			dbs.NewQuery("expires_at > ?", now),
+		),
+	)
+	if err != nil {
+		return false, err
+	}
+
+	return updated > 0, nil
+}
+
+// RevokeFamily revokes the tokens of the family, past and rotated ones alike
+func (r *RefreshTokenRepo) RevokeFamily(ctx context.Context, familyID string) error {
+	return r.revoke(ctx, dbs.NewQuery("family_id = ?", familyID))
+}
+
+// RevokeUserTokens revokes every token of the user, logging them out of all their sessions
+func (r *RefreshTokenRepo) RevokeUserTokens(ctx context.Context, userID string) error {
+	return r.revoke(ctx, dbs.NewQuery("user_id = ?", userID))
+}
+
+func (r *RefreshTokenRepo) revoke(ctx context.Context, query dbs.Query) error {
+	_, err := r.db.UpdateColumns(
+		ctx,
+		&model.RefreshToken{},
+		map[string]any{"revoked_at": time.Now()},
+		dbs.WithQuery(query, dbs.NewQuery("revoked_at IS NULL")),
+	)
+	return err
+}
@@ -0,0 +1,93 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/quangdangfit/gocommon/logger"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+
+	"goshop/internal/user/model"
+	"goshop/pkg/config"
+	"goshop/pkg/dbs/mocks"
+)
+
+type RefreshTokenRepositoryTestSuite struct {
+	suite.Suite
+	mockDB *mocks.IDatabase
+	repo   IRefreshTokenRepository
+}
+
+func (suite *RefreshTokenRepositoryTestSuite) SetupTest() {
+	logger.Initialize(config.ProductionEnv)
+
+	suite.mockDB = mocks.NewIDatabase(suite.T())
+	suite.repo = NewRefreshTokenRepository(suite.mockDB)
+}
+
+func TestRefreshTokenRepositoryTestSuite(t *testing.T) {
+	suite.Run(t, new(RefreshTokenRepositoryTestSuite))
+}
+
+// Create
+// =================================================================
+
+func (suite *RefreshTokenRepositoryTestSuite) TestCreateSuccessfully() {
+	token := &model.RefreshToken{ID: "jti", FamilyID: "family", UserID: "userID"}
+	suite.mockDB.On("Create", mock.Anything, token).
+		Return(nil).Times(1)
+
+	err := suite.repo.Create(context.Background(), token)
+	suite.Nil(err)
+}
+
+// Use
+// =================================================================
+
+func (suite *RefreshTokenRepositoryTestSuite) TestUseSuccessfully() {
+	suite.mockDB.On("UpdateColumns", mock.Anything, &model.RefreshToken{}, mock.Anything, mock.Anything).
+		Return(int64(1), nil).Times(1)
+
+	ok, err := suite.repo.Use(context.Background(), "jti")
+	suite.Nil(err)
+	suite.True(ok)
+}
+
+func (suite *RefreshTokenRepositoryTestSuite) TestUseAlreadyUsed() {
+	suite.mockDB.On("UpdateColumns", mock.Anything, &model.RefreshToken{}, mock.Anything, mock.Anything).
+		Return(int64(0), nil).Times(1)
+
+	ok, err := suite.repo.Use(context.Background(), "jti")
+	suite.Nil(err)
+	suite.False(ok)
+}
+
+func (suite *RefreshTokenRepositoryTestSuite) TestUseFail() {
+	suite.mockDB.On("UpdateColumns", mock.Anything, &model.RefreshToken{}, mock.Anything, mock.Anything).
+		Return(int64(0), errors.New("error")).Times(1)
+
+	ok, err := suite.repo.Use(context.Background(), "jti")
+	suite.NotNil(err)
+	suite.False(ok)
+}
+
+// Revoke
+// =================================================================
+
+func (suite *RefreshTokenRepositoryTestSuite) TestRevokeFamilySuccessfully() {
+	suite.mockDB.On("UpdateColumns", mock.Anything, &model.RefreshToken{}, mock.Anything, mock.Anything).
+		Return(int64(2), nil).Times(1)
+
+	err := suite.repo.RevokeFamily(context.Background(), "family")
+	suite.Nil(err)
+}
+
+func (suite *RefreshTokenRepositoryTestSuite) TestRevokeUserTokensFail() {
+	suite.mockDB.On("UpdateColumns", mock.Anything, &model.RefreshToken{}, mock.Anything, mock.Anything).
+		Return(int64(0), errors.New("error")).Times(1)
+
+	err := suite.repo.RevokeUserTokens(context.Background(), "userID")
+	suite.NotNil(err)
+}
@@ -11,6 +11,7 @@ import (
 
 	"goshop/internal/user/model"
 	"goshop/pkg/config"
+	"goshop/pkg/dbs"
 	"goshop/pkg/dbs/mocks"
 )
 
@@ -112,7 +113,7 @@ func (suite *UserRepositoryTestSuite) TestFindByIdFail() {
 // =================================================================
 
 func (suite *UserRepositoryTestSuite) TestGetUserByEmailSuccessfully() {
-	suite.mockDB.On("FindOne", mock.Anything, &model.User{}, mock.AnythingOfType("dbs.optionFn")).
+	suite.mockDB.On("FindOne", mock.Anything, &model.User{}, dbs.WithQuery(dbs.NewQuery("email = ?", "email@test.com"))).
 		Return(nil).Times(1)
 
 	user, err := suite.repo.GetUserByEmail(context.Background(), "email@test.com")
@@ -121,7 +122,7 @@ func (suite *UserRepositoryTestSuite) TestGetUserByEmailSuccessfully() {
 }
 
 func (suite *UserRepositoryTestSuite) TestGetUserByEmailFail() {
-	suite.mockDB.On("FindOne", mock.Anything, &model.User{}, mock.AnythingOfType("dbs.optionFn")).
+	suite.mockDB.On("FindOne", mock.Anything, &model.User{}, dbs.WithQuery(dbs.NewQuery("email = ?", "email@test.com"))).
 		Return(errors.New("error")).Times(1)
 
 	user, err := suite.repo.GetUserByEmail(context.Background(), "email@test.com")
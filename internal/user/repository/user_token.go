@@ -0,0 +1,68 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"goshop/internal/user/model"
+	"goshop/pkg/dbs"
+)
+
+//go:generate mockery --name=IUserTokenRepository
+type IUserTokenRepository interface {
+	Create(ctx context.Context, token *model.UserToken) error
+	Use(ctx context.Context, purpose model.UserTokenPurpose, tokenHash string) (*model.UserToken, error)
+}
+
+type UserTokenRepo struct {
+	db dbs.IDatabase
+}
+
+func NewUserTokenRepository(db dbs.IDatabase) *UserTokenRepo {
+	return &UserTokenRepo{db: db}
+}
+
+func (r *UserTokenRepo) Create(ctx context.Context, token *model.UserToken) error {
+	return r.db.Create(ctx, token)
+}
+
+// Use marks the token of purpose with the hash used and returns it. It returns nil, marking
+// nothing, when there is no such token or it was used or expired. The token is marked by the
+// database, so that a token sent twice concurrently is only used once.
+func (r *UserTokenRepo) Use(ctx context.Context, purpose model.UserTokenPurpose, tokenHash string) (*model.UserToken, error) {
+	now := time.Now()
+	var tokens []*model.UserToken
+	if err := r.db.Find(
+		ctx,
+		&tokens,
+		dbs.WithQuery(
+			dbs.NewQuery("purpose = ?", purpose),
+			dbs.NewQuery("token_hash = ?", tokenHash),
+			dbs.NewQuery("used_at IS NULL"),
+			dbs.NewQuery("expires_at > ?", now),
+		),
+		dbs.WithLimit(1),
+	); err != nil {
+		return nil, err
+	}
+	if len(tokens) == 0 {
+		return nil, nil
+	}
+
+	token := tokens[0]
+	updated, err := r.db.UpdateColumns(
+		ctx,
+		&model.UserToken{},
+		map[string]any{"used_at": now},
+		dbs.WithQuery(dbs.NewQuery("id = ?", token.ID), dbs.NewQuery("used_at IS NULL")),
+	)
+	if err != nil {
+		return nil, err
+	}
+	if updated == 0 {
+		return nil, nil
+	}
+
+	token.UsedAt = &now
+	return token, nil
+}
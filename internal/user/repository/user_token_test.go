@@ -0,0 +1,96 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/quangdangfit/gocommon/logger"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+
+	"goshop/internal/user/model"
+	"goshop/pkg/config"
+	"goshop/pkg/dbs/mocks"
+)
+
+type UserTokenRepositoryTestSuite struct {
+	suite.Suite
+	mockDB *mocks.IDatabase
+	repo   IUserTokenRepository
+}
+
+func (suite *UserTokenRepositoryTestSuite) SetupTest() {
+	logger.Initialize(config.ProductionEnv)
+
+	suite.mockDB = mocks.NewIDatabase(suite.T())
+	suite.repo = NewUserTokenRepository(suite.mockDB)
+}
+
+func TestUserTokenRepositoryTestSuite(t *testing.T) {
+	suite.Run(t, new(UserTokenRepositoryTestSuite))
+}
+
+// Create
+// =================================================================
+
+func (suite *UserTokenRepositoryTestSuite) TestCreateSuccessfully() {
+	token := &model.UserToken{UserID: "userID", Purpose: model.UserTokenPasswordReset, TokenHash: "hash"}
+	suite.mockDB.On("Create", mock.Anything, token).
+		Return(nil).Times(1)
+
+	err := suite.repo.Create(context.Background(), token)
+	suite.Nil(err)
+}
+
+// Use
+// =================================================================
+
+func (suite *UserTokenRepositoryTestSuite) TestUseSuccessfully() {
+	suite.mockDB.On("Find", mock.Anything, mock.Anything, mock.Anything, mock.Anything).
+		Run(func(args mock.Arguments) {
+			tokens := args.Get(1).(*[]*model.UserToken)
+			*tokens = []*model.UserToken{{ID: "tokenID", UserID: "userID"}}
+		}).
+		Return(nil).Times(1)
+	suite.mockDB.On("UpdateColumns", mock.Anything, &model.UserToken{}, mock.Anything, mock.Anything).
+		Return(int64(1), nil).Times(1)
+
+	token, err := suite.repo.Use(context.Background(), model.UserTokenPasswordReset, "hash")
+	suite.Nil(err)
+	suite.Equal("userID", token.UserID)
+	suite.NotNil(token.UsedAt)
+}
+
+func (suite *UserTokenRepositoryTestSuite) TestUseNotFound() {
+	suite.mockDB.On("Find", mock.Anything, mock.Anything, mock.Anything, mock.Anything).
+		Return(nil).Times(1)
+
+	token, err := suite.repo.Use(context.Background(), model.UserTokenPasswordReset, "hash")
+	suite.Nil(err)
+	suite.Nil(token)
+}
+
+func (suite *UserTokenRepositoryTestSuite) TestUseConcurrentlyUsed() {
+	suite.mockDB.On("Find", mock.Anything, mock.Anything, mock.Anything, mock.Anything).
+		Run(func(args mock.Arguments) {
+			tokens := args.Get(1).(*[]*model.UserToken)
+			*tokens = []*model.UserToken{{ID: "tokenID", UserID: "userID"}}
+		}).
+		Return(nil).Times(1)
+	suite.mockDB.On("UpdateColumns", mock.Anything, &model.UserToken{}, mock.Anything, mock.Anything).
+		Return(int64(0), nil).Times(1)
+
+	token, err := suite.repo.Use(context.Background(), model.UserTokenPasswordReset, "hash")
+	suite.Nil(err)
+	suite.Nil(token)
+}
+
+func (suite *UserTokenRepositoryTestSuite) TestUseFindFail() {
+	suite.mockDB.On("Find", mock.Anything, mock.Anything, mock.Anything, mock.Anything).
+		Return(errors.New("error")).Times(1)
+
+	token, err := suite.repo.Use(context.Background(), model.UserTokenPasswordReset, "hash")
+	suite.NotNil(err)
+	suite.Nil(token)
+}
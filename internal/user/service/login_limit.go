@@ -0,0 +1,160 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/quangdangfit/gocommon/logger"
+	"golang.org/x/crypto/bcrypt"
+
+	"goshop/internal/user/dto"
+	"goshop/pkg/utils"
+)
+
+var (
+	ErrInvalidCredentials = errors.New("invalid email or password")
+	ErrTooManyAttempts    = errors.New("too many failed login attempts")
+)
+
+// LoginLockedError is returned by Login while the account or the IP is locked after failed logins
+type LoginLockedError struct {
+	RetryAfter time.Duration
+}
+
+func (e *LoginLockedError) Error() string {
+	return fmt.Sprintf("%s, retry after %s", ErrTooManyAttempts, e.RetryAfter.Round(time.Second))
+}
+
+func (e *LoginLockedError) Unwrap() error {
+	return ErrTooManyAttempts
+}
+
+// LoginLimits protects Login against guessing passwords. Each failed login of an account locks
+// it for Backoff, twice longer after each other failure in a row, and MaxAttempts failures lock
+// it for Lockout. IPs are only locked, for Lockout after IPMaxAttempts failures, so that users
+// sharing an IP are not slowed down by each other's typos. Failures are forgotten once Lockout
+// passed without any.
+type LoginLimits struct {
+	MaxAttempts   int
+	IPMaxAttempts int
+	Backoff       time.Duration
+	Lockout       time.Duration
+}
+
+// accountLock returns how long an account is locked after failures in a row
+func (l LoginLimits) accountLock(failures int64) time.Duration {
+	if l.MaxAttempts > 0 && failures >= int64(l.MaxAttempts) {
+		return l.Lockout
+	}
+	if l.Backoff <= 0 || failures < 1 {
+		return 0
+	}
+
+	lock := l.Lockout
+	if shift := failures - 1; shift < 32 && l.Backoff<<shift < l.Lockout {
+		lock = l.Backoff << shift
+	}
+	return lock
+}
+
+func accountKey(email string) string {
+	return "account:" + strings.ToLower(email)
+}
+
+func ipKey(ip string) string {
+	return "ip:" + ip
+}
+
+// lockedFor returns how long logins of the account and IP are refused. Errors are logged and
+// ignored, logins keep working while the counters are unavailable.
+func (s *UserService) lockedFor(ctx context.Context, email, ip string) time.Duration {
+	keys := []string{accountKey(email)}
+	if ip != "" {
+		keys = append(keys, ipKey(ip))
+	}
+
+	var wait time.Duration
+	for _, key := range keys {
+		locked, err := s.attempts.LockedFor(ctx, key)
+		if err != nil {
+			logger.Errorf("Login.LockedFor fail, key: %s, error: %s", key, err)
+			continue
+		}
+		if locked > wait {
+			wait = locked
+		}
+	}
+
+	return wait
+}
+
+// loginFailed counts the failed login of req, locking its account or IP as needed, and returns
+// ErrInvalidCredentials
+func (s *UserService) loginFailed(ctx context.Context, req *dto.LoginReq) error {
+	limits := s.opts.LoginLimits
+
+	account := accountKey(req.Email)
+	if failures, err := s.attempts.AddFailure(ctx, account, limits.Lockout); err != nil {
+		logger.Errorf("Login.AddFailure fail, key: %s, error: %s", account, err)
+	} else if lock := limits.accountLock(failures); lock > 0 {
+		if limits.MaxAttempts > 0 && failures >= int64(limits.MaxAttempts) {
+			logger.Warnf("Login of %s locked for %s after %d failed attempts", req.Email, lock, failures)
+		}
+		if err := s.attempts.Lock(ctx, account, lock); err != nil {
+			logger.Errorf("Login.Lock fail, key: %s, error: %s", account, err)
+		}
+	}
+
+	if req.IP != "" && limits.IPMaxAttempts > 0 {
+		ip := ipKey(req.IP)
+		if failures, err := s.attempts.AddFailure(ctx, ip, limits.Lockout); err != nil {
+			logger.Errorf("Login.AddFailure fail, key: %s, error: %s", ip, err)
+		} else if failures >= int64(limits.IPMaxAttempts) {
+			logger.Warnf("Logins from %s locked for %s after %d failed attempts", req.IP, limits.Lockout, failures)
+			if err := s.attempts.Lock(ctx, ip, limits.Lockout); err != nil {
+				logger.Errorf("Login.Lock fail, key: %s, error: %s", ip, err)
+			}
+		}
+	}
+
+	return ErrInvalidCredentials
+}
+
+// UnlockLogin forgets the failed logins of the account and IP of req, unlocking them
+func (s *UserService) UnlockLogin(ctx context.Context, req *dto.UnlockLoginReq) error {
+	if err := s.validator.ValidateStruct(req); err != nil {
+		return err
+	}
+
+	var keys []string
+	if req.Email != "" {
+		keys = append(keys, accountKey(req.Email))
+	}
+	if req.IP != "" {
+		keys = append(keys, ipKey(req.IP))
+	}
+	if err := s.attempts.Reset(ctx, keys...); err != nil {
+		logger.Errorf("UnlockLogin.Reset fail, email: %s, ip: %s, error: %s", req.Email, req.IP, err)
+		return err
+	}
+
+	return nil
+}
+
+var (
+	dummyHashOnce sync.Once
+	dummyHash     []byte
+)
+
+// compareDummyPassword compares password with a hash as wrong passwords are, so that unknown
+// emails take as long to be rejected as known ones
+func compareDummyPassword(password string) {
+	dummyHashOnce.Do(func() {
+		dummyHash = []byte(utils.HashAndSalt([]byte("dummy password")))
+	})
+	_ = bcrypt.CompareHashAndPassword(dummyHash, []byte(password))
+}
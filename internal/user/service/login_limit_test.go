@@ -0,0 +1,140 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"gorm.io/gorm"
+
+	"goshop/internal/user/dto"
+	"goshop/internal/user/model"
+	"goshop/pkg/utils"
+)
+
+func TestLoginLimitsAccountLock(t *testing.T) {
+	limits := LoginLimits{MaxAttempts: 5, Backoff: time.Second, Lockout: 15 * time.Minute}
+	assert.Equal(t, time.Second, limits.accountLock(1))
+	assert.Equal(t, 2*time.Second, limits.accountLock(2))
+	assert.Equal(t, 8*time.Second, limits.accountLock(4))
+	assert.Equal(t, 15*time.Minute, limits.accountLock(5))
+	assert.Equal(t, 15*time.Minute, limits.accountLock(100))
+
+	// The backoff never locks longer than the lockout
+	limits = LoginLimits{Backoff: time.Minute, Lockout: 15 * time.Minute}
+	assert.Equal(t, 8*time.Minute, limits.accountLock(4))
+	assert.Equal(t, 15*time.Minute, limits.accountLock(5))
+	assert.Equal(t, 15*time.Minute, limits.accountLock(64))
+
+	assert.Equal(t, time.Duration(0), LoginLimits{Lockout: 15 * time.Minute}.accountLock(1))
+}
+
+// Login limits
+// =================================================================
+
+func (suite *UserServiceTestSuite) TestLoginAccountLocked() {
+	req := &dto.LoginReq{Email: "Test@test.com", Password: "test123456"}
+	suite.mockAttempts.On("LockedFor", mock.Anything, "account:test@test.com").
+		Return(30*time.Second, nil).Times(1)
+
+	user, _, _, err := suite.service.Login(context.Background(), req)
+	suite.Nil(user)
+	suite.ErrorIs(err, ErrTooManyAttempts)
+	var lockedErr *LoginLockedError
+	suite.ErrorAs(err, &lockedErr)
+	suite.Equal(30*time.Second, lockedErr.RetryAfter)
+}
+
+func (suite *UserServiceTestSuite) TestLoginIPLocked() {
+	req := &dto.LoginReq{Email: "test@test.com", Password: "test123456", IP: "192.0.2.1"}
+	suite.expectNotLocked(req.Email)
+	suite.mockAttempts.On("LockedFor", mock.Anything, "ip:192.0.2.1").
+		Return(time.Minute, nil).Times(1)
+
+	user, _, _, err := suite.service.Login(context.Background(), req)
+	suite.Nil(user)
+	suite.ErrorIs(err, ErrTooManyAttempts)
+}
+
+func (suite *UserServiceTestSuite) TestLoginUnknownEmail() {
+	req := &dto.LoginReq{Email: "unknown@test.com", Password: "test123456"}
+	suite.expectNotLocked(req.Email)
+	suite.mockRepo.On("GetUserByEmail", mock.Anything, req.Email).
+		Return(nil, gorm.ErrRecordNotFound).Times(1)
+	suite.mockAttempts.On("AddFailure", mock.Anything, "account:unknown@test.com", 15*time.Minute).
+		Return(int64(1), nil).Times(1)
+	suite.mockAttempts.On("Lock", mock.Anything, "account:unknown@test.com", time.Second).
+		Return(nil).Times(1)
+
+	user, _, _, err := suite.service.Login(context.Background(), req)
+	suite.Nil(user)
+	suite.ErrorIs(err, ErrInvalidCredentials)
+}
+
+func (suite *UserServiceTestSuite) TestLoginWrongPasswordLocksAccountAndIP() {
+	req := &dto.LoginReq{Email: "test@test.com", Password: "test123456", IP: "192.0.2.1"}
+	suite.expectNotLocked(req.Email)
+	suite.mockAttempts.On("LockedFor", mock.Anything, "ip:192.0.2.1").
+		Return(time.Duration(0), nil).Times(1)
+	suite.mockRepo.On("GetUserByEmail", mock.Anything, req.Email).
+		Return(&model.User{Email: req.Email, Password: utils.HashAndSalt([]byte("password"))}, nil).Times(1)
+	suite.mockAttempts.On("AddFailure", mock.Anything, "account:test@test.com", 15*time.Minute).
+		Return(int64(5), nil).Times(1)
+	suite.mockAttempts.On("Lock", mock.Anything, "account:test@test.com", 15*time.Minute).
+		Return(nil).Times(1)
+	suite.mockAttempts.On("AddFailure", mock.Anything, "ip:192.0.2.1", 15*time.Minute).
+		Return(int64(20), nil).Times(1)
+	suite.mockAttempts.On("Lock", mock.Anything, "ip:192.0.2.1", 15*time.Minute).
+		Return(nil).Times(1)
+
+	user, _, _, err := suite.service.Login(context.Background(), req)
+	suite.Nil(user)
+	suite.ErrorIs(err, ErrInvalidCredentials)
+}
+
+func (suite *UserServiceTestSuite) TestLoginAttemptsUnavailable() {
+	req := &dto.LoginReq{Email: "test@test.com", Password: "test123456"}
+	suite.mockAttempts.On("LockedFor", mock.Anything, "account:test@test.com").
+		Return(time.Duration(0), errors.New("error")).Times(1)
+	suite.mockRepo.On("GetUserByEmail", mock.Anything, req.Email).
+		Return(&model.User{Email: req.Email, Password: utils.HashAndSalt([]byte("password"))}, nil).Times(1)
+	suite.mockAttempts.On("AddFailure", mock.Anything, "account:test@test.com", 15*time.Minute).
+		Return(int64(0), errors.New("error")).Times(1)
+
+	// Logins are still checked, without limit
+	user, _, _, err := suite.service.Login(context.Background(), req)
+	suite.Nil(user)
+	suite.ErrorIs(err, ErrInvalidCredentials)
+}
+
+// UnlockLogin
+// =================================================================
+
+func (suite *UserServiceTestSuite) TestUnlockLoginSuccess() {
+	req := &dto.UnlockLoginReq{Email: "Test@test.com", IP: "192.0.2.1"}
+	suite.mockAttempts.On("Reset", mock.Anything, "account:test@test.com", "ip:192.0.2.1").
+		Return(nil).Times(1)
+
+	err := suite.service.UnlockLogin(context.Background(), req)
+	suite.Nil(err)
+}
+
+func (suite *UserServiceTestSuite) TestUnlockLoginMissRequiredField() {
+	err := suite.service.UnlockLogin(context.Background(), &dto.UnlockLoginReq{})
+	suite.NotNil(err)
+
+	err = suite.service.UnlockLogin(context.Background(), &dto.UnlockLoginReq{IP: "not an ip"})
+	suite.NotNil(err)
+}
+
+func (suite *UserServiceTestSuite) TestUnlockLoginFail() {
+	req := &dto.UnlockLoginReq{IP: "192.0.2.1"}
+	suite.mockAttempts.On("Reset", mock.Anything, "ip:192.0.2.1").
+		Return(errors.New("error")).Times(1)
+
+	err := suite.service.UnlockLogin(context.Background(), req)
+	suite.NotNil(err)
+}
@@ -30,6 +30,20 @@ func (_m *IUserService) ChangePassword(ctx context.Context, id string, req *dto.
 	return r0
 }
 
+// ForgotPassword provides a mock function with given fields: ctx, req
+func (_m *IUserService) ForgotPassword(ctx context.Context, req *dto.ForgotPasswordReq) error {
+	ret := _m.Called(ctx, req)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *dto.ForgotPasswordReq) error); ok {
+		r0 = rf(ctx, req)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // GetUserByID provides a mock function with given fields: ctx, id
 func (_m *IUserService) GetUserByID(ctx context.Context, id string) (*model.User, error) {
 	ret := _m.Called(ctx, id)
@@ -96,28 +110,63 @@ func (_m *IUserService) Login(ctx context.Context, req *dto.LoginReq) (*model.Us
 	return r0, r1, r2, r3
 }
 
-// RefreshToken provides a mock function with given fields: ctx, userID
-func (_m *IUserService) RefreshToken(ctx context.Context, userID string) (string, error) {
+// Logout provides a mock function with given fields: ctx, refreshToken
+func (_m *IUserService) Logout(ctx context.Context, refreshToken string) error {
+	ret := _m.Called(ctx, refreshToken)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) error); ok {
+		r0 = rf(ctx, refreshToken)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// LogoutAll provides a mock function with given fields: ctx, userID
+func (_m *IUserService) LogoutAll(ctx context.Context, userID string) error {
 	ret := _m.Called(ctx, userID)
 
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) error); ok {
+		r0 = rf(ctx, userID)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// RefreshToken provides a mock function with given fields: ctx, refreshToken
+func (_m *IUserService) RefreshToken(ctx context.Context, refreshToken string) (string, string, error) {
+	ret := _m.Called(ctx, refreshToken)
+
 	var r0 string
-	var r1 error
-	if rf, ok := ret.Get(0).(func(context.Context, string) (string, error)); ok {
-		return rf(ctx, userID)
+	var r1 string
+	var r2 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) (string, string, error)); ok {
+		return rf(ctx, refreshToken)
 	}
 	if rf, ok := ret.Get(0).(func(context.Context, string) string); ok {
-		r0 = rf(ctx, userID)
+		r0 = rf(ctx, refreshToken)
 	} else {
 		r0 = ret.Get(0).(string)
 	}
 
-	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
-		r1 = rf(ctx, userID)
+	if rf, ok := ret.Get(1).(func(context.Context, string) string); ok {
+		r1 = rf(ctx, refreshToken)
 	} else {
-		r1 = ret.Error(1)
+		r1 = ret.Get(1).(string)
 	}
 
-	return r0, r1
+	if rf, ok := ret.Get(2).(func(context.Context, string) error); ok {
+		r2 = rf(ctx, refreshToken)
+	} else {
+		r2 = ret.Error(2)
+	}
+
+	return r0, r1, r2
 }
 
 // Register provides a mock function with given fields: ctx, req
@@ -146,6 +195,62 @@ func (_m *IUserService) Register(ctx context.Context, req *dto.RegisterReq) (*mo
 	return r0, r1
 }
 
+// ResendVerification provides a mock function with given fields: ctx, req
+func (_m *IUserService) ResendVerification(ctx context.Context, req *dto.ResendVerificationReq) error {
+	ret := _m.Called(ctx, req)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *dto.ResendVerificationReq) error); ok {
+		r0 = rf(ctx, req)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// ResetPassword provides a mock function with given fields: ctx, req
+func (_m *IUserService) ResetPassword(ctx context.Context, req *dto.ResetPasswordReq) error {
+	ret := _m.Called(ctx, req)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *dto.ResetPasswordReq) error); ok {
+		r0 = rf(ctx, req)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// UnlockLogin provides a mock function with given fields: ctx, req
+func (_m *IUserService) UnlockLogin(ctx context.Context, req *dto.UnlockLoginReq) error {
+	ret := _m.Called(ctx, req)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *dto.UnlockLoginReq) error); ok {
+		r0 = rf(ctx, req)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// VerifyEmail provides a mock function with given fields: ctx, req
+func (_m *IUserService) VerifyEmail(ctx context.Context, req *dto.VerifyEmailReq) error {
+	ret := _m.Called(ctx, req)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *dto.VerifyEmailReq) error); ok {
+		r0 = rf(ctx, req)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // NewIUserService creates a new instance of IUserService. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
 // The first argument is typically a *testing.T value.
 func NewIUserService(t interface {
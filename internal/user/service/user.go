@@ -2,64 +2,153 @@ package service
 
 import (
 	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
 	"errors"
+	"net/url"
+	"strings"
+	"time"
 
+	"github.com/google/uuid"
 	"github.com/quangdangfit/gocommon/logger"
 	"github.com/quangdangfit/gocommon/validation"
 	"golang.org/x/crypto/bcrypt"
+	"gorm.io/gorm"
 
 	"goshop/internal/user/dto"
 	"goshop/internal/user/model"
 	"goshop/internal/user/repository"
+	"goshop/pkg/dbs"
 	"goshop/pkg/jtoken"
+	"goshop/pkg/mailer"
 	"goshop/pkg/utils"
 )
 
+var (
+	ErrInvalidRefreshToken = errors.New("invalid refresh token")
+	ErrInvalidResetToken   = errors.New("invalid or expired reset token")
+)
+
 //go:generate mockery --name=IUserService
 type IUserService interface {
 	Login(ctx context.Context, req *dto.LoginReq) (*model.User, string, string, error)
 	Register(ctx context.Context, req *dto.RegisterReq) (*model.User, error)
 	GetUserByID(ctx context.Context, id string) (*model.User, error)
-	RefreshToken(ctx context.Context, userID string) (string, error)
+	RefreshToken(ctx context.Context, refreshToken string) (string, string, error)
+	Logout(ctx context.Context, refreshToken string) error
+	LogoutAll(ctx context.Context, userID string) error
 	ChangePassword(ctx context.Context, id string, req *dto.ChangePasswordReq) error
+	ForgotPassword(ctx context.Context, req *dto.ForgotPasswordReq) error
+	ResetPassword(ctx context.Context, req *dto.ResetPasswordReq) error
+	VerifyEmail(ctx context.Context, req *dto.VerifyEmailReq) error
+	ResendVerification(ctx context.Context, req *dto.ResendVerificationReq) error
+	UnlockLogin(ctx context.Context, req *dto.UnlockLoginReq) error
+}
+
+// Options configures the emails sent by UserService
+type Options struct {
+	Mailer           mailer.Mailer
+	PasswordResetTTL time.Duration
+	// PasswordResetURL is the page of the client choosing a new password, linked in reset emails
+	// with the token as query parameter
+	PasswordResetURL     string
+	EmailVerificationTTL time.Duration
+	// EmailVerificationURL is the page of the client verifying emails, linked in verification
+	// emails with the token as query parameter
+	EmailVerificationURL string
+	VerificationPolicy   VerificationPolicy
+	LoginLimits          LoginLimits
 }
 
+// SignInHook is called after a user logged in or registered, with the guest token the client
+// sent along, if any
+type SignInHook func(ctx context.Context, userID, guestToken string) error
+
 type UserService struct {
-	validator validation.Validation
-	repo      repository.IUserRepository
+	validator  validation.Validation
+	db         dbs.IDatabase
+	repo       repository.IUserRepository
+	tokenRepo  repository.IRefreshTokenRepository
+	userTokens repository.IUserTokenRepository
+	attempts   repository.ILoginAttemptRepository
+	opts       Options
+	hooks      []SignInHook
 }
 
 func NewUserService(
 	validator validation.Validation,
-	repo repository.IUserRepository) *UserService {
+	db dbs.IDatabase,
+	repo repository.IUserRepository,
+	tokenRepo repository.IRefreshTokenRepository,
+	userTokens repository.IUserTokenRepository,
+	attempts repository.ILoginAttemptRepository,
+	opts Options) *UserService {
 	return &UserService{
-		validator: validator,
-		repo:      repo,
+		validator:  validator,
+		db:         db,
+		repo:       repo,
+		tokenRepo:  tokenRepo,
+		userTokens: userTokens,
+		attempts:   attempts,
+		opts:       opts,
 	}
 }
 
+// OnSignIn registers hook to be called after every login and registration. Hook errors are
+// logged and do not fail the sign in.
+func (s *UserService) OnSignIn(hook SignInHook) {
+	s.hooks = append(s.hooks, hook)
+}
+
+func (s *UserService) signedIn(ctx context.Context, userID, guestToken string) {
+	for _, hook := range s.hooks {
+		if err := hook(ctx, userID, guestToken); err != nil {
+			logger.Errorf("SignIn hook fail, userID: %s, error: %s", userID, err)
+		}
+	}
+}
+
+// Login returns the user of req and their tokens. Logins are limited by Options.LoginLimits, and
+// whatever makes the credentials wrong, Login returns ErrInvalidCredentials.
 func (s *UserService) Login(ctx context.Context, req *dto.LoginReq) (*model.User, string, string, error) {
 	if err := s.validator.ValidateStruct(req); err != nil {
-		return nil, "", "", err
+		return nil, "", "", ErrInvalidCredentials
+	}
+
+	if wait := s.lockedFor(ctx, req.Email, req.IP); wait > 0 {
+		return nil, "", "", &LoginLockedError{RetryAfter: wait}
 	}
 
+	// Unknown emails and wrong passwords fail alike, so that callers cannot tell which emails
+	// are registered
 	user, err := s.repo.GetUserByEmail(ctx, req.Email)
 	if err != nil {
-		logger.Errorf("Login.GetUserByEmail fail, email: %s, error: %s", req.Email, err)
-		return nil, "", "", err
+		if !errors.Is(err, gorm.ErrRecordNotFound) {
+			logger.Errorf("Login.GetUserByEmail fail, email: %s, error: %s", req.Email, err)
+			return nil, "", "", err
+		}
+		compareDummyPassword(req.Password)
+		return nil, "", "", s.loginFailed(ctx, req)
 	}
 
 	if err = bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(req.Password)); err != nil {
-		return nil, "", "", errors.New("wrong password")
+		return nil, "", "", s.loginFailed(ctx, req)
+	}
+	if err := s.attempts.Reset(ctx, accountKey(req.Email)); err != nil {
+		logger.Errorf("Login.Reset fail, email: %s, error: %s", req.Email, err)
+	}
+	if s.opts.VerificationPolicy == VerificationPolicyLogin && !user.EmailVerified() {
+		return nil, "", "", ErrEmailNotVerified
 	}
 
-	tokenData := map[string]interface{}{
-		"id":    user.ID,
-		"email": user.Email,
-		"role":  user.Role,
+	accessToken, refreshToken, err := s.issueTokens(ctx, user, uuid.New().String())
+	if err != nil {
+		logger.Errorf("Login.issueTokens fail, email: %s, error: %s", req.Email, err)
+		return nil, "", "", err
 	}
-	accessToken := jtoken.GenerateAccessToken(tokenData)
-	refreshToken := jtoken.GenerateRefreshToken(tokenData)
+	s.signedIn(ctx, user.ID, req.GuestToken)
 	return user, accessToken, refreshToken, nil
 }
 
@@ -75,6 +164,11 @@ func (s *UserService) Register(ctx context.Context, req *dto.RegisterReq) (*mode
 		logger.Errorf("Register.Create fail, email: %s, error: %s", req.Email, err)
 		return nil, err
 	}
+	// The account is created anyway, the user can ask for another email
+	if err := s.sendVerificationEmail(ctx, &user); err != nil {
+		logger.Errorf("Register.sendVerificationEmail fail, email: %s, error: %s", req.Email, err)
+	}
+	s.signedIn(ctx, user.ID, req.GuestToken)
 	return &user, nil
 }
 
@@ -88,20 +182,114 @@ func (s *UserService) GetUserByID(ctx context.Context, id string) (*model.User,
 	return user, nil
 }
 
-func (s *UserService) RefreshToken(ctx context.Context, userID string) (string, error) {
+// RefreshToken rotates refreshToken, returning a new access token and the refresh token
+// replacing it. A refresh token is used once, using it again revokes the whole family of tokens
+// rotated from the same login, since it was stolen either by the caller or by the one who used
+// it first.
+func (s *UserService) RefreshToken(ctx context.Context, refreshToken string) (string, string, error) {
+	userID, jti, familyID, err := parseRefreshToken(refreshToken)
+	if err != nil {
+		return "", "", err
+	}
+
+	ok, err := s.tokenRepo.Use(ctx, jti)
+	if err != nil {
+		logger.Errorf("RefreshToken.Use fail, id: %s, error: %s", jti, err)
+		return "", "", err
+	}
+	if !ok {
+		logger.Warnf("Refresh token %s reused or revoked, revoking family %s of user %s", jti, familyID, userID)
+		if err := s.tokenRepo.RevokeFamily(ctx, familyID); err != nil {
+			logger.Errorf("RefreshToken.RevokeFamily fail, family: %s, error: %s", familyID, err)
+			return "", "", err
+		}
+		return "", "", ErrInvalidRefreshToken
+	}
+
 	user, err := s.repo.GetUserByID(ctx, userID)
 	if err != nil {
 		logger.Errorf("RefreshToken.GetUserByID fail, id: %s, error: %s", userID, err)
-		return "", err
+		return "", "", err
+	}
+
+	accessToken, newRefreshToken, err := s.issueTokens(ctx, user, familyID)
+	if err != nil {
+		logger.Errorf("RefreshToken.issueTokens fail, id: %s, error: %s", userID, err)
+		return "", "", err
+	}
+
+	return accessToken, newRefreshToken, nil
+}
+
+// Logout revokes refreshToken and the tokens rotated along with it, ending the session
+func (s *UserService) Logout(ctx context.Context, refreshToken string) error {
+	_, _, familyID, err := parseRefreshToken(refreshToken)
+	if err != nil {
+		return err
+	}
+
+	if err := s.tokenRepo.RevokeFamily(ctx, familyID); err != nil {
+		logger.Errorf("Logout.RevokeFamily fail, family: %s, error: %s", familyID, err)
+		return err
+	}
+
+	return nil
+}
+
+// LogoutAll revokes every refresh token of the user, ending all their sessions. Access tokens
+// already issued stay valid until they expire.
+func (s *UserService) LogoutAll(ctx context.Context, userID string) error {
+	if err := s.tokenRepo.RevokeUserTokens(ctx, userID); err != nil {
+		logger.Errorf("LogoutAll.RevokeUserTokens fail, id: %s, error: %s", userID, err)
+		return err
+	}
+
+	return nil
+}
+
+// issueTokens returns an access token and a refresh token of the family for user, recording
+// the refresh token
+func (s *UserService) issueTokens(ctx context.Context, user *model.User, familyID string) (string, string, error) {
+	token := &model.RefreshToken{
+		ID:        uuid.New().String(),
+		FamilyID:  familyID,
+		UserID:    user.ID,
+		ExpiresAt: time.Now().Add(time.Second * jtoken.RefreshTokenExpiredTime),
+	}
+	if err := s.tokenRepo.Create(ctx, token); err != nil {
+		return "", "", err
 	}
 
-	tokenData := map[string]interface{}{
+	accessToken := jtoken.GenerateAccessToken(map[string]interface{}{
 		"id":    user.ID,
 		"email": user.Email,
 		"role":  user.Role,
+	})
+	refreshToken := jtoken.GenerateRefreshToken(map[string]interface{}{
+		"id":     user.ID,
+		"email":  user.Email,
+		"role":   user.Role,
+		"jti":    token.ID,
+		"family": token.FamilyID,
+	})
+	return accessToken, refreshToken, nil
+}
+
+// parseRefreshToken returns the user, jti and family of refreshToken
+func parseRefreshToken(refreshToken string) (string, string, string, error) {
+	payload, err := jtoken.ValidateToken(refreshToken)
+	if err != nil || payload["type"] != jtoken.RefreshTokenType {
+		return "", "", "", ErrInvalidRefreshToken
 	}
-	accessToken := jtoken.GenerateAccessToken(tokenData)
-	return accessToken, nil
+
+	userID, _ := payload["id"].(string)
+	jti, _ := payload["jti"].(string)
+	familyID, _ := payload["family"].(string)
+	if userID == "" || jti == "" || familyID == "" {
+		return "", "", "", ErrInvalidRefreshToken
+	}
+
+	return userID, jti, familyID, nil
 }
 
 func (s *UserService) ChangePassword(ctx context.Context, id string, req *dto.ChangePasswordReq) error {
@@ -127,3 +315,110 @@ func (s *UserService) ChangePassword(ctx context.Context, id string, req *dto.Ch
 
 	return nil
 }
+
+// ForgotPassword emails a password reset token to the user of req.Email. Unknown emails are
+// ignored, so that callers cannot tell which emails are registered.
+func (s *UserService) ForgotPassword(ctx context.Context, req *dto.ForgotPasswordReq) error {
+	if err := s.validator.ValidateStruct(req); err != nil {
+		return err
+	}
+
+	user, err := s.repo.GetUserByEmail(ctx, req.Email)
+	if err != nil {
+		logger.Infof("ForgotPassword.GetUserByEmail fail, email: %s, error: %s", req.Email, err)
+		return nil
+	}
+
+	// Failures are only logged, answering like for unknown emails not to tell which are registered
+	token, err := s.createUserToken(ctx, user.ID, model.UserTokenPasswordReset, s.opts.PasswordResetTTL)
+	if err != nil {
+		logger.Errorf("ForgotPassword.createUserToken fail, id: %s, error: %s", user.ID, err)
+		return nil
+	}
+
+	body := "We received a request to reset the password of your account. Use this token to choose a new password:\n\n" + token
+	if s.opts.PasswordResetURL != "" {
+		body += "\n\nOr open " + withToken(s.opts.PasswordResetURL, token)
+	}
+	body += "\n\nIf you did not ask for it, you can ignore this email."
+	if err := s.opts.Mailer.Send(ctx, &mailer.Message{To: user.Email, Subject: "Reset your password", Body: body}); err != nil {
+		logger.Errorf("ForgotPassword.Send fail, id: %s, error: %s", user.ID, err)
+		return nil
+	}
+
+	return nil
+}
+
+// ResetPassword sets the password of the user the reset token was sent to, and logs them out of
+// all their sessions. The token cannot be used again.
+func (s *UserService) ResetPassword(ctx context.Context, req *dto.ResetPasswordReq) error {
+	if err := s.validator.ValidateStruct(req); err != nil {
+		return err
+	}
+
+	// The token is only used up if the password is changed
+	return s.db.WithTransaction(ctx, func(ctx context.Context) error {
+		token, err := s.userTokens.Use(ctx, model.UserTokenPasswordReset, hashToken(req.Token))
+		if err != nil {
+			logger.Errorf("ResetPassword.Use fail, error: %s", err)
+			return err
+		}
+		if token == nil {
+			return ErrInvalidResetToken
+		}
+
+		user, err := s.repo.GetUserByID(ctx, token.UserID)
+		if err != nil {
+			logger.Errorf("ResetPassword.GetUserByID fail, id: %s, error: %s", token.UserID, err)
+			return err
+		}
+
+		user.Password = utils.HashAndSalt([]byte(req.NewPassword))
+		if err := s.repo.Update(ctx, user); err != nil {
+			logger.Errorf("ResetPassword.Update fail, id: %s, error: %s", user.ID, err)
+			return err
+		}
+
+		if err := s.tokenRepo.RevokeUserTokens(ctx, user.ID); err != nil {
+			logger.Errorf("ResetPassword.RevokeUserTokens fail, id: %s, error: %s", user.ID, err)
+			return err
+		}
+
+		return nil
+	})
+}
+
+// createUserToken records a token of purpose for the user, valid for ttl, and returns it
+func (s *UserService) createUserToken(ctx context.Context, userID string, purpose model.UserTokenPurpose, ttl time.Duration) (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	token := base64.RawURLEncoding.EncodeToString(b)
+
+	err := s.userTokens.Create(ctx, &model.UserToken{
+		UserID:    userID,
+		Purpose:   purpose,
+		TokenHash: hashToken(token),
+		ExpiresAt: time.Now().Add(ttl),
+	})
+	if err != nil {
+		return "", err
+	}
+
+	return token, nil
+}
+
+func hashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// withToken returns link with token added as query parameter
+func withToken(link, token string) string {
+	separator := "?"
+	if strings.Contains(link, "?") {
+		separator = "&"
+	}
+	return link + separator + "token=" + url.QueryEscape(token)
+}
@@ -3,32 +3,74 @@ package service
 import (
 	"context"
 	"errors"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/quangdangfit/gocommon/logger"
 	"github.com/quangdangfit/gocommon/validation"
 	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/suite"
+	"golang.org/x/crypto/bcrypt"
 
 	"goshop/internal/user/dto"
 	"goshop/internal/user/model"
 	"goshop/internal/user/repository/mocks"
 	"goshop/pkg/config"
+	dbMocks "goshop/pkg/dbs/mocks"
+	"goshop/pkg/jtoken"
+	"goshop/pkg/mailer"
+	mailerMocks "goshop/pkg/mailer/mocks"
 	"goshop/pkg/utils"
 )
 
 type UserServiceTestSuite struct {
 	suite.Suite
-	mockRepo *mocks.IUserRepository
-	service  IUserService
+	mockDB         *dbMocks.IDatabase
+	mockRepo       *mocks.IUserRepository
+	mockTokenRepo  *mocks.IRefreshTokenRepository
+	mockUserTokens *mocks.IUserTokenRepository
+	mockAttempts   *mocks.ILoginAttemptRepository
+	mockMailer     *mailerMocks.Mailer
+	service        IUserService
 }
 
 func (suite *UserServiceTestSuite) SetupTest() {
 	logger.Initialize(config.ProductionEnv)
 
 	validator := validation.New()
+	suite.mockDB = dbMocks.NewIDatabase(suite.T())
 	suite.mockRepo = mocks.NewIUserRepository(suite.T())
-	suite.service = NewUserService(validator, suite.mockRepo)
+	suite.mockTokenRepo = mocks.NewIRefreshTokenRepository(suite.T())
+	suite.mockUserTokens = mocks.NewIUserTokenRepository(suite.T())
+	suite.mockAttempts = mocks.NewILoginAttemptRepository(suite.T())
+	suite.mockMailer = mailerMocks.NewMailer(suite.T())
+	suite.service = NewUserService(validator, suite.mockDB, suite.mockRepo, suite.mockTokenRepo, suite.mockUserTokens, suite.mockAttempts, Options{
+		Mailer:               suite.mockMailer,
+		PasswordResetTTL:     time.Hour,
+		PasswordResetURL:     "https://shop.test/reset-password",
+		EmailVerificationTTL: 48 * time.Hour,
+		EmailVerificationURL: "https://shop.test/verify-email",
+		VerificationPolicy:   VerificationPolicyNone,
+		LoginLimits: LoginLimits{
+			MaxAttempts:   5,
+			IPMaxAttempts: 20,
+			Backoff:       time.Second,
+			Lockout:       15 * time.Minute,
+		},
+	})
+}
+
+// expectNotLocked expects the account of email to be checked and found unlocked
+func (suite *UserServiceTestSuite) expectNotLocked(email string) {
+	suite.mockAttempts.On("LockedFor", mock.Anything, "account:"+email).
+		Return(time.Duration(0), nil).Times(1)
+}
+
+// expectLoggedIn expects the failures of the account of email to be forgotten
+func (suite *UserServiceTestSuite) expectLoggedIn(email string) {
+	suite.mockAttempts.On("Reset", mock.Anything, "account:"+email).
+		Return(nil).Times(1)
 }
 
 func TestUserServiceTestSuite(t *testing.T) {
@@ -43,6 +85,7 @@ func (suite *UserServiceTestSuite) TestLoginGetUserByEmailFail() {
 		Email:    "test@test.com",
 		Password: "test123456",
 	}
+	suite.expectNotLocked(req.Email)
 	suite.mockRepo.On("GetUserByEmail", mock.Anything, req.Email).
 		Return(nil, errors.New("error")).Times(1)
 
@@ -51,6 +94,7 @@ func (suite *UserServiceTestSuite) TestLoginGetUserByEmailFail() {
 	suite.Empty(accessToken)
 	suite.Empty(refreshToken)
 	suite.NotNil(err)
+	suite.NotErrorIs(err, ErrInvalidCredentials)
 }
 
 func (suite *UserServiceTestSuite) TestLoginInvalidEmailFormat() {
@@ -63,7 +107,7 @@ func (suite *UserServiceTestSuite) TestLoginInvalidEmailFormat() {
 	suite.Nil(user)
 	suite.Empty(accessToken)
 	suite.Empty(refreshToken)
-	suite.NotNil(err)
+	suite.ErrorIs(err, ErrInvalidCredentials)
 }
 
 func (suite *UserServiceTestSuite) TestLoginWrongPassword() {
@@ -72,6 +116,11 @@ func (suite *UserServiceTestSuite) TestLoginWrongPassword() {
 		Password: "test123456",
 	}
 
+	suite.expectNotLocked(req.Email)
+	suite.mockAttempts.On("AddFailure", mock.Anything, "account:test@test.com", 15*time.Minute).
+		Return(int64(1), nil).Times(1)
+	suite.mockAttempts.On("Lock", mock.Anything, "account:test@test.com", time.Second).
+		Return(nil).Times(1)
 	suite.mockRepo.On("GetUserByEmail", mock.Anything, req.Email).
 		Return(&model.User{
 			Email:    "test@test.com",
@@ -82,7 +131,7 @@ func (suite *UserServiceTestSuite) TestLoginWrongPassword() {
 	suite.Nil(user)
 	suite.Empty(accessToken)
 	suite.Empty(refreshToken)
-	suite.NotNil(err)
+	suite.ErrorIs(err, ErrInvalidCredentials)
 }
 
 func (suite *UserServiceTestSuite) TestLoginSuccess() {
@@ -90,6 +139,8 @@ func (suite *UserServiceTestSuite) TestLoginSuccess() {
 		Email:    "test@test.com",
 		Password: "test123456",
 	}
+	suite.expectNotLocked(req.Email)
+	suite.expectLoggedIn(req.Email)
 	suite.mockRepo.On("GetUserByEmail", mock.Anything, req.Email).
 		Return(
 			&model.User{
@@ -98,6 +149,8 @@ func (suite *UserServiceTestSuite) TestLoginSuccess() {
 			},
 			nil,
 		).Times(1)
+	suite.mockTokenRepo.On("Create", mock.Anything, mock.Anything).
+		Return(nil).Times(1)
 
 	user, accessToken, refreshToken, err := suite.service.Login(context.Background(), req)
 	suite.NotNil(user)
@@ -107,6 +160,113 @@ func (suite *UserServiceTestSuite) TestLoginSuccess() {
 	suite.Nil(err)
 }
 
+func (suite *UserServiceTestSuite) TestLoginEmailNotVerified() {
+	suite.service.(*UserService).opts.VerificationPolicy = VerificationPolicyLogin
+	req := &dto.LoginReq{
+		Email:    "test@test.com",
+		Password: "test123456",
+	}
+	suite.expectNotLocked(req.Email)
+	suite.expectLoggedIn(req.Email)
+	suite.mockRepo.On("GetUserByEmail", mock.Anything, req.Email).
+		Return(
+			&model.User{
+				Email:    "test@test.com",
+				Password: utils.HashAndSalt([]byte("test123456")),
+			},
+			nil,
+		).Times(1)
+
+	user, accessToken, refreshToken, err := suite.service.Login(context.Background(), req)
+	suite.Nil(user)
+	suite.Empty(accessToken)
+	suite.Empty(refreshToken)
+	suite.ErrorIs(err, ErrEmailNotVerified)
+}
+
+func (suite *UserServiceTestSuite) TestLoginEmailVerified() {
+	suite.service.(*UserService).opts.VerificationPolicy = VerificationPolicyLogin
+	verifiedAt := time.Now()
+	req := &dto.LoginReq{
+		Email:    "test@test.com",
+		Password: "test123456",
+	}
+	suite.expectNotLocked(req.Email)
+	suite.expectLoggedIn(req.Email)
+	suite.mockRepo.On("GetUserByEmail", mock.Anything, req.Email).
+		Return(
+			&model.User{
+				Email:           "test@test.com",
+				EmailVerifiedAt: &verifiedAt,
+				Password:        utils.HashAndSalt([]byte("test123456")),
+			},
+			nil,
+		).Times(1)
+	suite.mockTokenRepo.On("Create", mock.Anything, mock.Anything).
+		Return(nil).Times(1)
+
+	user, _, _, err := suite.service.Login(context.Background(), req)
+	suite.NotNil(user)
+	suite.Nil(err)
+}
+
+func (suite *UserServiceTestSuite) TestLoginCreateRefreshTokenFail() {
+	req := &dto.LoginReq{
+		Email:    "test@test.com",
+		Password: "test123456",
+	}
+	suite.expectNotLocked(req.Email)
+	suite.expectLoggedIn(req.Email)
+	suite.mockRepo.On("GetUserByEmail", mock.Anything, req.Email).
+		Return(
+			&model.User{
+				Email:    "test@test.com",
+				Password: utils.HashAndSalt([]byte("test123456")),
+			},
+			nil,
+		).Times(1)
+	suite.mockTokenRepo.On("Create", mock.Anything, mock.Anything).
+		Return(errors.New("error")).Times(1)
+
+	user, accessToken, refreshToken, err := suite.service.Login(context.Background(), req)
+	suite.Nil(user)
+	suite.Empty(accessToken)
+	suite.Empty(refreshToken)
+	suite.NotNil(err)
+}
+
+func (suite *UserServiceTestSuite) TestLoginCallsSignInHooks() {
+	req := &dto.LoginReq{
+		Email:      "test@test.com",
+		Password:   "test123456",
+		GuestToken: "guestToken",
+	}
+	suite.expectNotLocked(req.Email)
+	suite.expectLoggedIn(req.Email)
+	suite.mockRepo.On("GetUserByEmail", mock.Anything, req.Email).
+		Return(
+			&model.User{
+				ID:       "userID",
+				Email:    "test@test.com",
+				Password: utils.HashAndSalt([]byte("test123456")),
+			},
+			nil,
+		).Times(1)
+	suite.mockTokenRepo.On("Create", mock.Anything, mock.Anything).
+		Return(nil).Times(1)
+
+	var calls []string
+	suite.service.(*UserService).OnSignIn(func(ctx context.Context, userID, guestToken string) error {
+		calls = append(calls, userID+":"+guestToken)
+		return errors.New("error")
+	})
+
+	user, _, _, err := suite.service.Login(context.Background(), req)
+	suite.NotNil(user)
+	suite.Nil(err)
+	suite.Equal([]string{"userID:guestToken"}, calls)
+}
+
 // Register
 // =================================================================
 
@@ -117,10 +277,59 @@ func (suite *UserServiceTestSuite) TestRegisterSuccess() {
 	}
 	suite.mockRepo.On("Create", mock.Anything, mock.Anything).
 		Return(nil).Times(1)
+	suite.mockUserTokens.On("Create", mock.Anything, mock.MatchedBy(func(token *model.UserToken) bool {
+		return token.Purpose == model.UserTokenEmailVerification
+	})).Return(nil).Times(1)
+	suite.mockMailer.On("Send", mock.Anything, mock.MatchedBy(func(msg *mailer.Message) bool {
+		return msg.To == req.Email
+	})).Return(nil).Times(1)
+
+	user, err := suite.service.Register(context.Background(), req)
+	suite.NotNil(user)
+	suite.Nil(err)
+	suite.Nil(user.EmailVerifiedAt)
+}
+
+func (suite *UserServiceTestSuite) TestRegisterSendVerificationFail() {
+	req := &dto.RegisterReq{
+		Email:    "test@test.com",
+		Password: "test123456",
+	}
+	suite.mockRepo.On("Create", mock.Anything, mock.Anything).
+		Return(nil).Times(1)
+	suite.mockUserTokens.On("Create", mock.Anything, mock.Anything).
+		Return(nil).Times(1)
+	suite.mockMailer.On("Send", mock.Anything, mock.Anything).
+		Return(mailer.ErrOutboxFull).Times(1)
+
+	user, err := suite.service.Register(context.Background(), req)
+	suite.NotNil(user)
+	suite.Nil(err)
+}
+
+func (suite *UserServiceTestSuite) TestRegisterCallsSignInHooks() {
+	req := &dto.RegisterReq{
+		Email:      "test@test.com",
+		Password:   "test123456",
+		GuestToken: "guestToken",
+	}
+	suite.mockRepo.On("Create", mock.Anything, mock.Anything).
+		Return(nil).Times(1)
+	suite.mockUserTokens.On("Create", mock.Anything, mock.Anything).
+		Return(nil).Times(1)
+	suite.mockMailer.On("Send", mock.Anything, mock.Anything).
+		Return(nil).Times(1)
+
+	var tokens []string
+	suite.service.(*UserService).OnSignIn(func(ctx context.Context, userID, guestToken string) error {
+		tokens = append(tokens, guestToken)
+		return nil
+	})
 
 	user, err := suite.service.Register(context.Background(), req)
 	suite.NotNil(user)
 	suite.Nil(err)
+	suite.Equal([]string{"guestToken"}, tokens)
 }
 
 func (suite *UserServiceTestSuite) TestRegisterCreateUserFail() {
@@ -183,6 +392,9 @@ func (suite *UserServiceTestSuite) TestGetUserByIDFail() {
 
 func (suite *UserServiceTestSuite) TestRefreshTokenSuccess() {
 	userID := "userID"
+	token := refreshToken(userID, "jti", "family")
+	suite.mockTokenRepo.On("Use", mock.Anything, "jti").
+		Return(true, nil).Times(1)
 	suite.mockRepo.On("GetUserByID", mock.Anything, userID).
 		Return(
 			&model.User{
@@ -190,22 +402,144 @@ func (suite *UserServiceTestSuite) TestRefreshTokenSuccess() {
 				Email: "test@test.com",
 			}, nil,
 		).Times(1)
+	suite.mockTokenRepo.On("Create", mock.Anything, mock.MatchedBy(func(token *model.RefreshToken) bool {
+		return token.ID != "jti" && token.FamilyID == "family" && token.UserID == userID
+	})).Return(nil).Times(1)
 
-	refreshToken, err := suite.service.RefreshToken(context.Background(), userID)
-	suite.NotEmpty(refreshToken)
+	accessToken, newRefreshToken, err := suite.service.RefreshToken(context.Background(), token)
+	suite.NotEmpty(accessToken)
+	suite.NotEmpty(newRefreshToken)
+	suite.NotEqual(token, newRefreshToken)
+	suite.Nil(err)
+
+	payload, err := jtoken.ValidateToken(newRefreshToken)
 	suite.Nil(err)
+	suite.Equal("family", payload["family"])
+	suite.NotEqual("jti", payload["jti"])
+}
+
+func (suite *UserServiceTestSuite) TestRefreshTokenReusedRevokesFamily() {
+	token := refreshToken("userID", "jti", "family")
+	suite.mockTokenRepo.On("Use", mock.Anything, "jti").
+		Return(false, nil).Times(1)
+	suite.mockTokenRepo.On("RevokeFamily", mock.Anything, "family").
+		Return(nil).Times(1)
+
+	accessToken, newRefreshToken, err := suite.service.RefreshToken(context.Background(), token)
+	suite.Empty(accessToken)
+	suite.Empty(newRefreshToken)
+	suite.ErrorIs(err, ErrInvalidRefreshToken)
+}
+
+func (suite *UserServiceTestSuite) TestRefreshTokenRevokeFamilyFail() {
+	token := refreshToken("userID", "jti", "family")
+	suite.mockTokenRepo.On("Use", mock.Anything, "jti").
+		Return(false, nil).Times(1)
+	suite.mockTokenRepo.On("RevokeFamily", mock.Anything, "family").
+		Return(errors.New("error")).Times(1)
+
+	_, _, err := suite.service.RefreshToken(context.Background(), token)
+	suite.NotNil(err)
+	suite.NotErrorIs(err, ErrInvalidRefreshToken)
+}
+
+func (suite *UserServiceTestSuite) TestRefreshTokenUseFail() {
+	token := refreshToken("userID", "jti", "family")
+	suite.mockTokenRepo.On("Use", mock.Anything, "jti").
+		Return(false, errors.New("error")).Times(1)
+
+	_, _, err := suite.service.RefreshToken(context.Background(), token)
+	suite.NotNil(err)
+}
+
+func (suite *UserServiceTestSuite) TestRefreshTokenWithoutJTI() {
+	token := jtoken.GenerateRefreshToken(map[string]interface{}{"id": "userID"})
+
+	_, _, err := suite.service.RefreshToken(context.Background(), token)
+	suite.ErrorIs(err, ErrInvalidRefreshToken)
+}
+
+func (suite *UserServiceTestSuite) TestRefreshTokenWithAccessToken() {
+	token := jtoken.GenerateAccessToken(map[string]interface{}{"id": "userID", "jti": "jti", "family": "family"})
+
+	_, _, err := suite.service.RefreshToken(context.Background(), token)
+	suite.ErrorIs(err, ErrInvalidRefreshToken)
 }
 
 func (suite *UserServiceTestSuite) TestRefreshTokenGetUserByIDFail() {
 	userID := "userID"
+	suite.mockTokenRepo.On("Use", mock.Anything, "jti").
+		Return(true, nil).Times(1)
 	suite.mockRepo.On("GetUserByID", mock.Anything, userID).
 		Return(nil, errors.New("error")).Times(1)
 
-	refreshToken, err := suite.service.RefreshToken(context.Background(), userID)
-	suite.Empty(refreshToken)
+	accessToken, newRefreshToken, err := suite.service.RefreshToken(context.Background(), refreshToken(userID, "jti", "family"))
+	suite.Empty(accessToken)
+	suite.Empty(newRefreshToken)
+	suite.NotNil(err)
+}
+
+func (suite *UserServiceTestSuite) TestRefreshTokenCreateFail() {
+	userID := "userID"
+	suite.mockTokenRepo.On("Use", mock.Anything, "jti").
+		Return(true, nil).Times(1)
+	suite.mockRepo.On("GetUserByID", mock.Anything, userID).
+		Return(&model.User{ID: userID}, nil).Times(1)
+	suite.mockTokenRepo.On("Create", mock.Anything, mock.Anything).
+		Return(errors.New("error")).Times(1)
+
+	_, _, err := suite.service.RefreshToken(context.Background(), refreshToken(userID, "jti", "family"))
+	suite.NotNil(err)
+}
+
+// Logout
+// =================================================================
+
+func (suite *UserServiceTestSuite) TestLogoutSuccess() {
+	suite.mockTokenRepo.On("RevokeFamily", mock.Anything, "family").
+		Return(nil).Times(1)
+
+	err := suite.service.Logout(context.Background(), refreshToken("userID", "jti", "family"))
+	suite.Nil(err)
+}
+
+func (suite *UserServiceTestSuite) TestLogoutInvalidToken() {
+	err := suite.service.Logout(context.Background(), "token")
+	suite.ErrorIs(err, ErrInvalidRefreshToken)
+}
+
+func (suite *UserServiceTestSuite) TestLogoutFail() {
+	suite.mockTokenRepo.On("RevokeFamily", mock.Anything, "family").
+		Return(errors.New("error")).Times(1)
+
+	err := suite.service.Logout(context.Background(), refreshToken("userID", "jti", "family"))
+	suite.NotNil(err)
+}
+
+func (suite *UserServiceTestSuite) TestLogoutAllSuccess() {
+	suite.mockTokenRepo.On("RevokeUserTokens", mock.Anything, "userID").
+		Return(nil).Times(1)
+
+	err := suite.service.LogoutAll(context.Background(), "userID")
+	suite.Nil(err)
+}
+
+func (suite *UserServiceTestSuite) TestLogoutAllFail() {
+	suite.mockTokenRepo.On("RevokeUserTokens", mock.Anything, "userID").
+		Return(errors.New("error")).Times(1)
+
+	err := suite.service.LogoutAll(context.Background(), "userID")
 	suite.NotNil(err)
 }
 
+func refreshToken(userID, jti, familyID string) string {
+	return jtoken.GenerateRefreshToken(map[string]interface{}{
+		"id":     userID,
+		"jti":    jti,
+		"family": familyID,
+	})
+}
+
 // ChangePassword
 // =================================================================
 
@@ -297,3 +631,145 @@ func (suite *UserServiceTestSuite) TestChangePasswordUpdateUserFail() {
 	err := suite.service.ChangePassword(context.Background(), userID, req)
 	suite.NotNil(err)
 }
+
+// ForgotPassword
+// =================================================================
+
+func (suite *UserServiceTestSuite) TestForgotPasswordSuccess() {
+	req := &dto.ForgotPasswordReq{Email: "test@test.com"}
+	suite.mockRepo.On("GetUserByEmail", mock.Anything, req.Email).
+		Return(&model.User{ID: "userID", Email: req.Email}, nil).Times(1)
+
+	var stored *model.UserToken
+	suite.mockUserTokens.On("Create", mock.Anything, mock.Anything).
+		Run(func(args mock.Arguments) {
+			stored = args.Get(1).(*model.UserToken)
+		}).
+		Return(nil).Times(1)
+
+	var msg *mailer.Message
+	suite.mockMailer.On("Send", mock.Anything, mock.Anything).
+		Run(func(args mock.Arguments) {
+			msg = args.Get(1).(*mailer.Message)
+		}).
+		Return(nil).Times(1)
+
+	err := suite.service.ForgotPassword(context.Background(), req)
+	suite.Nil(err)
+	suite.Equal("userID", stored.UserID)
+	suite.Equal(model.UserTokenPasswordReset, stored.Purpose)
+	suite.WithinDuration(time.Now().Add(time.Hour), stored.ExpiresAt, time.Minute)
+	suite.Equal(req.Email, msg.To)
+
+	// The mail carries the token whose hash is stored, and a link to the reset page
+	link := msg.Body[strings.Index(msg.Body, "https://shop.test/reset-password?token="):]
+	token := strings.Fields(strings.TrimPrefix(link, "https://shop.test/reset-password?token="))[0]
+	suite.Equal(hashToken(token), stored.TokenHash)
+	suite.NotContains(msg.Body, stored.TokenHash)
+}
+
+func (suite *UserServiceTestSuite) TestForgotPasswordUnknownEmail() {
+	req := &dto.ForgotPasswordReq{Email: "unknown@test.com"}
+	suite.mockRepo.On("GetUserByEmail", mock.Anything, req.Email).
+		Return(nil, errors.New("record not found")).Times(1)
+
+	err := suite.service.ForgotPassword(context.Background(), req)
+	suite.Nil(err)
+}
+
+func (suite *UserServiceTestSuite) TestForgotPasswordInvalidEmail() {
+	err := suite.service.ForgotPassword(context.Background(), &dto.ForgotPasswordReq{Email: "email"})
+	suite.NotNil(err)
+}
+
+func (suite *UserServiceTestSuite) TestForgotPasswordCreateFail() {
+	req := &dto.ForgotPasswordReq{Email: "test@test.com"}
+	suite.mockRepo.On("GetUserByEmail", mock.Anything, req.Email).
+		Return(&model.User{ID: "userID", Email: req.Email}, nil).Times(1)
+	suite.mockUserTokens.On("Create", mock.Anything, mock.Anything).
+		Return(errors.New("error")).Times(1)
+
+	// Registered emails are answered like unknown ones
+	err := suite.service.ForgotPassword(context.Background(), req)
+	suite.Nil(err)
+}
+
+func (suite *UserServiceTestSuite) TestForgotPasswordSendFail() {
+	req := &dto.ForgotPasswordReq{Email: "test@test.com"}
+	suite.mockRepo.On("GetUserByEmail", mock.Anything, req.Email).
+		Return(&model.User{ID: "userID", Email: req.Email}, nil).Times(1)
+	suite.mockUserTokens.On("Create", mock.Anything, mock.Anything).
+		Return(nil).Times(1)
+	suite.mockMailer.On("Send", mock.Anything, mock.Anything).
+		Return(mailer.ErrOutboxFull).Times(1)
+
+	err := suite.service.ForgotPassword(context.Background(), req)
+	suite.Nil(err)
+}
+
+// ResetPassword
+// =================================================================
+
+func (suite *UserServiceTestSuite) expectTransaction() {
+	suite.mockDB.On("WithTransaction", mock.Anything, mock.Anything).
+		Return(func(ctx context.Context, fn func(context.Context) error) error {
+			return fn(ctx)
+		}).Times(1)
+}
+
+func (suite *UserServiceTestSuite) TestResetPasswordSuccess() {
+	req := &dto.ResetPasswordReq{Token: "token", NewPassword: "newPassword"}
+	suite.expectTransaction()
+	suite.mockUserTokens.On("Use", mock.Anything, model.UserTokenPasswordReset, hashToken("token")).
+		Return(&model.UserToken{UserID: "userID"}, nil).Times(1)
+	suite.mockRepo.On("GetUserByID", mock.Anything, "userID").
+		Return(&model.User{ID: "userID", Password: utils.HashAndSalt([]byte("password"))}, nil).Times(1)
+	suite.mockRepo.On("Update", mock.Anything, mock.MatchedBy(func(user *model.User) bool {
+		return bcrypt.CompareHashAndPassword([]byte(user.Password), []byte("newPassword")) == nil
+	})).Return(nil).Times(1)
+	suite.mockTokenRepo.On("RevokeUserTokens", mock.Anything, "userID").
+		Return(nil).Times(1)
+
+	err := suite.service.ResetPassword(context.Background(), req)
+	suite.Nil(err)
+}
+
+func (suite *UserServiceTestSuite) TestResetPasswordInvalidToken() {
+	req := &dto.ResetPasswordReq{Token: "token", NewPassword: "newPassword"}
+	suite.expectTransaction()
+	suite.mockUserTokens.On("Use", mock.Anything, model.UserTokenPasswordReset, hashToken("token")).
+		Return(nil, nil).Times(1)
+
+	err := suite.service.ResetPassword(context.Background(), req)
+	suite.ErrorIs(err, ErrInvalidResetToken)
+}
+
+func (suite *UserServiceTestSuite) TestResetPasswordMissRequiredField() {
+	err := suite.service.ResetPassword(context.Background(), &dto.ResetPasswordReq{NewPassword: "newPassword"})
+	suite.NotNil(err)
+}
+
+func (suite *UserServiceTestSuite) TestResetPasswordUseFail() {
+	req := &dto.ResetPasswordReq{Token: "token", NewPassword: "newPassword"}
+	suite.expectTransaction()
+	suite.mockUserTokens.On("Use", mock.Anything, model.UserTokenPasswordReset, hashToken("token")).
+		Return(nil, errors.New("error")).Times(1)
+
+	err := suite.service.ResetPassword(context.Background(), req)
+	suite.NotNil(err)
+	suite.NotErrorIs(err, ErrInvalidResetToken)
+}
+
+func (suite *UserServiceTestSuite) TestResetPasswordUpdateFail() {
+	req := &dto.ResetPasswordReq{Token: "token", NewPassword: "newPassword"}
+	suite.expectTransaction()
+	suite.mockUserTokens.On("Use", mock.Anything, model.UserTokenPasswordReset, hashToken("token")).
+		Return(&model.UserToken{UserID: "userID"}, nil).Times(1)
+	suite.mockRepo.On("GetUserByID", mock.Anything, "userID").
+		Return(&model.User{ID: "userID"}, nil).Times(1)
+	suite.mockRepo.On("Update", mock.Anything, mock.Anything).
+		Return(errors.New("error")).Times(1)
+
+	err := suite.service.ResetPassword(context.Background(), req)
+	suite.NotNil(err)
+}
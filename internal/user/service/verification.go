@@ -0,0 +1,115 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/quangdangfit/gocommon/logger"
+
+	"goshop/internal/user/dto"
+	"goshop/internal/user/model"
+	"goshop/pkg/mailer"
+)
+
+var (
+	ErrEmailNotVerified         = errors.New("email not verified")
+	ErrInvalidVerificationToken = errors.New("invalid or expired verification token")
+)
+
+// VerificationPolicy decides what users cannot do before verifying their email
+type VerificationPolicy string
+
+const (
+	// VerificationPolicyNone lets users do everything with an unverified email
+	VerificationPolicyNone VerificationPolicy = "none"
+	// VerificationPolicyLogin refuses to log in users with an unverified email
+	VerificationPolicyLogin VerificationPolicy = "login"
+	// VerificationPolicyOrder refuses orders of users with an unverified email
+	VerificationPolicyOrder VerificationPolicy = "order"
+)
+
+func ParseVerificationPolicy(text string) (VerificationPolicy, error) {
+	switch policy := VerificationPolicy(text); policy {
+	case VerificationPolicyNone, VerificationPolicyLogin, VerificationPolicyOrder:
+		return policy, nil
+	}
+
+	return "", fmt.Errorf("invalid email verification policy: %q", text)
+}
+
+// VerifyEmail marks the email of the user the verification token was sent to as verified. The
+// token cannot be used again.
+func (s *UserService) VerifyEmail(ctx context.Context, req *dto.VerifyEmailReq) error {
+	if err := s.validator.ValidateStruct(req); err != nil {
+		return err
+	}
+
+	token, err := s.userTokens.Use(ctx, model.UserTokenEmailVerification, hashToken(req.Token))
+	if err != nil {
+		logger.Errorf("VerifyEmail.Use fail, error: %s", err)
+		return err
+	}
+	if token == nil {
+		return ErrInvalidVerificationToken
+	}
+
+	user, err := s.repo.GetUserByID(ctx, token.UserID)
+	if err != nil {
+		logger.Errorf("VerifyEmail.GetUserByID fail, id: %s, error: %s", token.UserID, err)
+		return err
+	}
+	if user.EmailVerified() {
+		return nil
+	}
+
+	now := time.Now()
+	user.EmailVerifiedAt = &now
+	if err := s.repo.Update(ctx, user); err != nil {
+		logger.Errorf("VerifyEmail.Update fail, id: %s, error: %s", user.ID, err)
+		return err
+	}
+
+	return nil
+}
+
+// ResendVerification emails a new verification token to the user of req.Email. Unknown and
+// verified emails are ignored, so that callers cannot tell which emails are registered.
+func (s *UserService) ResendVerification(ctx context.Context, req *dto.ResendVerificationReq) error {
+	if err := s.validator.ValidateStruct(req); err != nil {
+		return err
+	}
+
+	user, err := s.repo.GetUserByEmail(ctx, req.Email)
+	if err != nil {
+		logger.Infof("ResendVerification.GetUserByEmail fail, email: %s, error: %s", req.Email, err)
+		return nil
+	}
+	if user.EmailVerified() {
+		return nil
+	}
+
+	return s.sendVerificationEmail(ctx, user)
+}
+
+// sendVerificationEmail emails a verification token to user
+func (s *UserService) sendVerificationEmail(ctx context.Context, user *model.User) error {
+	token, err := s.createUserToken(ctx, user.ID, model.UserTokenEmailVerification, s.opts.EmailVerificationTTL)
+	if err != nil {
+		logger.Errorf("sendVerificationEmail.createUserToken fail, id: %s, error: %s", user.ID, err)
+		return err
+	}
+
+	body := "Welcome! Use this token to verify your email:\n\n" + token
+	if s.opts.EmailVerificationURL != "" {
+		body += "\n\nOr open " + withToken(s.opts.EmailVerificationURL, token)
+	}
+	body += "\n\nIf you did not create an account, you can ignore this email."
+	if err := s.opts.Mailer.Send(ctx, &mailer.Message{To: user.Email, Subject: "Verify your email", Body: body}); err != nil {
+		logger.Errorf("sendVerificationEmail.Send fail, id: %s, error: %s", user.ID, err)
+		return err
+	}
+
+	return nil
+}
@@ -0,0 +1,146 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	"goshop/internal/user/dto"
+	"goshop/internal/user/model"
+	"goshop/pkg/mailer"
+)
+
+func TestParseVerificationPolicy(t *testing.T) {
+	policy, err := ParseVerificationPolicy("order")
+	assert.Nil(t, err)
+	assert.Equal(t, VerificationPolicyOrder, policy)
+
+	_, err = ParseVerificationPolicy("")
+	assert.NotNil(t, err)
+}
+
+// VerifyEmail
+// =================================================================
+
+func (suite *UserServiceTestSuite) TestVerifyEmailSuccess() {
+	req := &dto.VerifyEmailReq{Token: "token"}
+	suite.mockUserTokens.On("Use", mock.Anything, model.UserTokenEmailVerification, hashToken("token")).
+		Return(&model.UserToken{UserID: "userID"}, nil).Times(1)
+	suite.mockRepo.On("GetUserByID", mock.Anything, "userID").
+		Return(&model.User{ID: "userID"}, nil).Times(1)
+	suite.mockRepo.On("Update", mock.Anything, mock.MatchedBy(func(user *model.User) bool {
+		return user.EmailVerified()
+	})).Return(nil).Times(1)
+
+	err := suite.service.VerifyEmail(context.Background(), req)
+	suite.Nil(err)
+}
+
+func (suite *UserServiceTestSuite) TestVerifyEmailAlreadyVerified() {
+	verifiedAt := time.Now()
+	req := &dto.VerifyEmailReq{Token: "token"}
+	suite.mockUserTokens.On("Use", mock.Anything, model.UserTokenEmailVerification, hashToken("token")).
+		Return(&model.UserToken{UserID: "userID"}, nil).Times(1)
+	suite.mockRepo.On("GetUserByID", mock.Anything, "userID").
+		Return(&model.User{ID: "userID", EmailVerifiedAt: &verifiedAt}, nil).Times(1)
+
+	err := suite.service.VerifyEmail(context.Background(), req)
+	suite.Nil(err)
+}
+
+func (suite *UserServiceTestSuite) TestVerifyEmailInvalidToken() {
+	req := &dto.VerifyEmailReq{Token: "token"}
+	suite.mockUserTokens.On("Use", mock.Anything, model.UserTokenEmailVerification, hashToken("token")).
+		Return(nil, nil).Times(1)
+
+	err := suite.service.VerifyEmail(context.Background(), req)
+	suite.ErrorIs(err, ErrInvalidVerificationToken)
+}
+
+func (suite *UserServiceTestSuite) TestVerifyEmailMissRequiredField() {
+	err := suite.service.VerifyEmail(context.Background(), &dto.VerifyEmailReq{})
+	suite.NotNil(err)
+}
+
+func (suite *UserServiceTestSuite) TestVerifyEmailUpdateFail() {
+	req := &dto.VerifyEmailReq{Token: "token"}
+	suite.mockUserTokens.On("Use", mock.Anything, model.UserTokenEmailVerification, hashToken("token")).
+		Return(&model.UserToken{UserID: "userID"}, nil).Times(1)
+	suite.mockRepo.On("GetUserByID", mock.Anything, "userID").
+		Return(&model.User{ID: "userID"}, nil).Times(1)
+	suite.mockRepo.On("Update", mock.Anything, mock.Anything).
+		Return(errors.New("error")).Times(1)
+
+	err := suite.service.VerifyEmail(context.Background(), req)
+	suite.NotNil(err)
+}
+
+// ResendVerification
+// =================================================================
+
+func (suite *UserServiceTestSuite) TestResendVerificationSuccess() {
+	req := &dto.ResendVerificationReq{Email: "test@test.com"}
+	suite.mockRepo.On("GetUserByEmail", mock.Anything, req.Email).
+		Return(&model.User{ID: "userID", Email: req.Email}, nil).Times(1)
+
+	var stored *model.UserToken
+	suite.mockUserTokens.On("Create", mock.Anything, mock.Anything).
+		Run(func(args mock.Arguments) {
+			stored = args.Get(1).(*model.UserToken)
+		}).
+		Return(nil).Times(1)
+
+	var msg *mailer.Message
+	suite.mockMailer.On("Send", mock.Anything, mock.Anything).
+		Run(func(args mock.Arguments) {
+			msg = args.Get(1).(*mailer.Message)
+		}).
+		Return(nil).Times(1)
+
+	err := suite.service.ResendVerification(context.Background(), req)
+	suite.Nil(err)
+	suite.Equal(model.UserTokenEmailVerification, stored.Purpose)
+	suite.WithinDuration(time.Now().Add(48*time.Hour), stored.ExpiresAt, time.Minute)
+	suite.Equal(req.Email, msg.To)
+
+	link := msg.Body[strings.Index(msg.Body, "https://shop.test/verify-email?token="):]
+	token := strings.Fields(strings.TrimPrefix(link, "https://shop.test/verify-email?token="))[0]
+	suite.Equal(hashToken(token), stored.TokenHash)
+}
+
+func (suite *UserServiceTestSuite) TestResendVerificationAlreadyVerified() {
+	verifiedAt := time.Now()
+	req := &dto.ResendVerificationReq{Email: "test@test.com"}
+	suite.mockRepo.On("GetUserByEmail", mock.Anything, req.Email).
+		Return(&model.User{ID: "userID", Email: req.Email, EmailVerifiedAt: &verifiedAt}, nil).Times(1)
+
+	err := suite.service.ResendVerification(context.Background(), req)
+	suite.Nil(err)
+}
+
+func (suite *UserServiceTestSuite) TestResendVerificationUnknownEmail() {
+	req := &dto.ResendVerificationReq{Email: "unknown@test.com"}
+	suite.mockRepo.On("GetUserByEmail", mock.Anything, req.Email).
+		Return(nil, errors.New("record not found")).Times(1)
+
+	err := suite.service.ResendVerification(context.Background(), req)
+	suite.Nil(err)
+}
+
+func (suite *UserServiceTestSuite) TestResendVerificationSendFail() {
+	req := &dto.ResendVerificationReq{Email: "test@test.com"}
+	suite.mockRepo.On("GetUserByEmail", mock.Anything, req.Email).
+		Return(&model.User{ID: "userID", Email: req.Email}, nil).Times(1)
+	suite.mockUserTokens.On("Create", mock.Anything, mock.Anything).
+		Return(nil).Times(1)
+	suite.mockMailer.On("Send", mock.Anything, mock.Anything).
+		Return(mailer.ErrOutboxFull).Times(1)
+
+	err := suite.service.ResendVerification(context.Background(), req)
+	suite.ErrorIs(err, mailer.ErrOutboxFull)
+}
@@ -8,29 +8,80 @@ import (
 
 	"github.com/caarlos0/env"
 	"github.com/joho/godotenv"
+
+	"goshop/pkg/rbac"
 )
 
 const (
 	ProductionEnv = "production"
 
-	DatabaseTimeout    = 5 * time.Second
 	ProductCachingTime = 1 * time.Minute
 )
 
 var AuthIgnoreMethods = []string{
 	"/user.UserService/Login",
 	"/user.UserService/Register",
+	// Called with a refresh token, validated by the service
+	"/user.UserService/RefreshToken",
+	"/user.UserService/Logout",
+}
+
+// GuestMethods may be called without token by guests, identified by the guest-token metadata
+var GuestMethods = []string{
+	"/cart.CartService/GetCart",
+	"/cart.CartService/AddProduct",
+	"/cart.CartService/RemoveProduct",
+}
+
+// GrpcMethodPermissions is the RBAC permission required to call each gRPC method
+var GrpcMethodPermissions = map[string]string{
+	"/user.UserService/GetMe":          rbac.PermissionUserRead,
+	"/user.UserService/ChangePassword": rbac.PermissionUserWrite,
+	"/user.UserService/LogoutAll":      rbac.PermissionUserWrite,
+	"/cart.CartService/GetCart":        rbac.PermissionCartRead,
+	"/cart.CartService/AddProduct":     rbac.PermissionCartWrite,
+	"/cart.CartService/RemoveProduct":  rbac.PermissionCartWrite,
+	"/cart.CartService/Checkout":       rbac.PermissionOrderWrite,
 }
 
 type Schema struct {
-	Environment   string `env:"environment"`
-	HttpPort      int    `env:"http_port"`
-	GrpcPort      int    `env:"grpc_port"`
-	AuthSecret    string `env:"auth_secret"`
-	DatabaseURI   string `env:"database_uri"`
-	RedisURI      string `env:"redis_uri"`
-	RedisPassword string `env:"redis_password"`
-	RedisDB       int    `env:"redis_db"`
+	Environment             string        `env:"environment"`
+	HttpPort                int           `env:"http_port"`
+	GrpcPort                int           `env:"grpc_port"`
+	AuthSecret              string        `env:"auth_secret"`
+	AuthKeysDir             string        `env:"auth_keys_dir"`
+	AuthSigningKeyID        string        `env:"auth_signing_kid"`
+	AuthIssuer              string        `env:"auth_issuer" envDefault:"goshop"`
+	AuthAudience            string        `env:"auth_audience" envDefault:"goshop"`
+	CursorSecret            string        `env:"cursor_secret"`
+	DatabaseURI             string        `env:"database_uri"`
+	DatabaseReadTimeout     time.Duration `env:"database_read_timeout" envDefault:"5s"`
+	DatabaseWriteTimeout    time.Duration `env:"database_write_timeout" envDefault:"5s"`
+	RedisURI                string        `env:"redis_uri"`
+	RedisPassword           string        `env:"redis_password"`
+	RedisDB                 int           `env:"redis_db"`
+	RBACPolicy              string        `env:"rbac_policy"`
+	PaymentProvider         string        `env:"payment_provider" envDefault:"fake"`
+	PaymentWebhookSecret    string        `env:"payment_webhook_secret"`
+	IdempotencyKeyTTL       time.Duration `env:"idempotency_key_ttl" envDefault:"24h"`
+	GuestCartTTL            time.Duration `env:"guest_cart_ttl" envDefault:"168h"`
+	CartMergePolicy         string        `env:"cart_merge_policy" envDefault:"sum"`
+	MailProvider            string        `env:"mail_provider" envDefault:"log"`
+	MailFrom                string        `env:"mail_from" envDefault:"goshop@localhost"`
+	MailDir                 string        `env:"mail_dir" envDefault:"mails"`
+	SMTPHost                string        `env:"smtp_host"`
+	SMTPPort                int           `env:"smtp_port" envDefault:"587"`
+	SMTPUsername            string        `env:"smtp_username"`
+	SMTPPassword            string        `env:"smtp_password"`
+	PasswordResetTTL        time.Duration `env:"password_reset_ttl" envDefault:"1h"`
+	PasswordResetURL        string        `env:"password_reset_url"`
+	EmailVerificationTTL    time.Duration `env:"email_verification_ttl" envDefault:"48h"`
+	EmailVerificationURL    string        `env:"email_verification_url"`
+	EmailVerificationPolicy string        `env:"email_verification_policy" envDefault:"none"`
+	LoginMaxAttempts        int           `env:"login_max_attempts" envDefault:"5"`
+	LoginIPMaxAttempts      int           `env:"login_ip_max_attempts" envDefault:"20"`
+	LoginBackoff            time.Duration `env:"login_backoff" envDefault:"1s"`
+	LoginLockout            time.Duration `env:"login_lockout" envDefault:"15m"`
 }
 
 var (
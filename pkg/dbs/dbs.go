@@ -2,28 +2,37 @@ package dbs
 
 import (
 	"context"
+	"database/sql"
 	"time"
 
 	"gorm.io/driver/postgres"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 	gormLogger "gorm.io/gorm/logger"
 )
 
-const DatabaseTimeout = 5 * time.Second
+const (
+	DefaultReadTimeout  = 5 * time.Second
+	DefaultWriteTimeout = 5 * time.Second
+)
 
 //go:generate mockery --name=IDatabase
 type IDatabase interface {
 	GetDB() *gorm.DB
 	AutoMigrate(models ...any) error
-	WithTransaction(function func() error) error
+	WithTransaction(ctx context.Context, function func(ctx context.Context) error) error
+	WithIsolationLevel(ctx context.Context, level sql.IsolationLevel, function func(ctx context.Context) error) error
 	Create(ctx context.Context, doc any) error
 	CreateInBatches(ctx context.Context, docs any, batchSize int) error
 	Update(ctx context.Context, doc any) error
+	Upsert(ctx context.Context, docs any, conflictColumns []string, updateColumns []string) error
+	UpdateColumns(ctx context.Context, model any, values map[string]any, opts ...FindOption) (int64, error)
 	Delete(ctx context.Context, value any, opts ...FindOption) error
 	FindById(ctx context.Context, id string, result any) error
 	FindOne(ctx context.Context, result any, opts ...FindOption) error
 	Find(ctx context.Context, result any, opts ...FindOption) error
 	Count(ctx context.Context, model any, total *int64, opts ...FindOption) error
+	Lock(ctx context.Context, key int64) error
 }
 
 type Query struct {
@@ -38,12 +47,33 @@ func NewQuery(query string, args ...any) Query {
 	}
 }
 
+// Expr is a SQL expression used as a column value in UpdateColumns, e.g. Expr("stock + ?", 1)
+func Expr(expr string, args ...any) any {
+	return gorm.Expr(expr, args...)
+}
+
+// OrderExpr is a SQL expression to order by in WithOrder, e.g. OrderExpr("similarity(name, ?) DESC", name)
+func OrderExpr(expr string, args ...any) any {
+	return clause.OrderBy{Expression: clause.Expr{SQL: expr, Vars: args}}
+}
+
+type txKey struct{}
+
+// Config database
+type Config struct {
+	URI          string
+	ReadTimeout  time.Duration
+	WriteTimeout time.Duration
+}
+
 type Database struct {
-	db *gorm.DB
+	db           *gorm.DB
+	readTimeout  time.Duration
+	writeTimeout time.Duration
 }
 
-func NewDatabase(uri string) (*Database, error) {
-	database, err := gorm.Open(postgres.Open(uri), &gorm.Config{
+func NewDatabase(config Config) (*Database, error) {
+	database, err := gorm.Open(postgres.Open(config.URI), &gorm.Config{
 		Logger: gormLogger.Default.LogMode(gormLogger.Warn),
 	})
 	if err != nil {
@@ -58,8 +88,19 @@ func NewDatabase(uri string) (*Database, error) {
 	sqlDB.SetMaxIdleConns(20)
 	sqlDB.SetMaxOpenConns(200)
 
+	readTimeout := config.ReadTimeout
+	if readTimeout <= 0 {
+		readTimeout = DefaultReadTimeout
+	}
+	writeTimeout := config.WriteTimeout
+	if writeTimeout <= 0 {
+		writeTimeout = DefaultWriteTimeout
+	}
+
 	return &Database{
-		db: database,
+		db:           database,
+		readTimeout:  readTimeout,
+		writeTimeout: writeTimeout,
 	}, nil
 }
 
@@ -67,22 +108,25 @@ func (d *Database) AutoMigrate(models ...any) error {
 	return d.db.AutoMigrate(models...)
 }
 
-func (d *Database) WithTransaction(function func() error) error {
-	callback := func(db *gorm.DB) error {
-		return function()
-	}
+// WithTransaction runs function in a transaction carried by the context passed to it.
+// Every method called with that context runs in the transaction. Nested calls create
+// a savepoint in the outer transaction instead of opening a new one.
+func (d *Database) WithTransaction(ctx context.Context, function func(ctx context.Context) error) error {
+	return d.transaction(ctx, function)
+}
 
-	tx := d.db.Begin()
-	if err := callback(tx); err != nil {
-		tx.Rollback()
-		return err
-	}
+// WithIsolationLevel is like WithTransaction but opens the transaction with the given
+// isolation level. The level is ignored when ctx already carries a transaction.
+func (d *Database) WithIsolationLevel(ctx context.Context, level sql.IsolationLevel, function func(ctx context.Context) error) error {
+	return d.transaction(ctx, function, &sql.TxOptions{Isolation: level})
+}
 
-	if err := tx.Commit().Error; err != nil {
-		return err
-	}
+func (d *Database) transaction(ctx context.Context, function func(ctx context.Context) error, opts ...*sql.TxOptions) error {
+	err := d.getDB(ctx).Transaction(func(tx *gorm.DB) error {
+		return function(context.WithValue(ctx, txKey{}, tx))
+	}, opts...)
 
-	return nil
+	return wrapError(ctx, err)
 }
 
 func (d *Database) Preload(query string, args ...interface{}) IDatabase {
@@ -91,90 +135,153 @@ func (d *Database) Preload(query string, args ...interface{}) IDatabase {
 }
 
 func (d *Database) Create(ctx context.Context, doc any) error {
-	ctx, cancel := context.WithTimeout(ctx, DatabaseTimeout)
+	ctx, cancel := context.WithTimeout(ctx, d.writeTimeout)
 	defer cancel()
 
-	return d.db.Create(doc).Error
+	return wrapError(ctx, d.getDB(ctx).Create(doc).Error)
 }
 
 func (d *Database) CreateInBatches(ctx context.Context, docs any, batchSize int) error {
-	ctx, cancel := context.WithTimeout(ctx, DatabaseTimeout)
+	ctx, cancel := context.WithTimeout(ctx, d.writeTimeout)
 	defer cancel()
 
-	return d.db.CreateInBatches(docs, batchSize).Error
+	return wrapError(ctx, d.getDB(ctx).CreateInBatches(docs, batchSize).Error)
 }
 
+// Update saves all fields of doc. Associations of doc are not saved.
 func (d *Database) Update(ctx context.Context, doc any) error {
-	ctx, cancel := context.WithTimeout(ctx, DatabaseTimeout)
+	ctx, cancel := context.WithTimeout(ctx, d.writeTimeout)
+	defer cancel()
+
+	return wrapError(ctx, d.getDB(ctx).Omit(clause.Associations).Save(doc).Error)
+}
+
+// Upsert inserts docs, updating updateColumns of the rows that conflict on conflictColumns.
+// Associations of docs are not saved.
+func (d *Database) Upsert(ctx context.Context, docs any, conflictColumns []string, updateColumns []string) error {
+	ctx, cancel := context.WithTimeout(ctx, d.writeTimeout)
+	defer cancel()
+
+	columns := make([]clause.Column, 0, len(conflictColumns))
+	for _, column := range conflictColumns {
+		columns = append(columns, clause.Column{Name: column})
+	}
+
+	err := d.getDB(ctx).Omit(clause.Associations).Clauses(clause.OnConflict{
+		Columns:   columns,
+		DoUpdates: clause.AssignmentColumns(updateColumns),
+	}).Create(docs).Error
+
+	return wrapError(ctx, err)
+}
+
+// UpdateColumns sets values on the rows of model matching opts and returns the number of rows updated.
+// Values may be Expr so that the update is computed atomically by the database.
+func (d *Database) UpdateColumns(ctx context.Context, model any, values map[string]any, opts ...FindOption) (int64, error) {
+	ctx, cancel := context.WithTimeout(ctx, d.writeTimeout)
 	defer cancel()
 
-	return d.db.Save(doc).Error
+	query := d.applyOptions(ctx, opts...)
+	result := query.Model(model).Updates(values)
+	return result.RowsAffected, wrapError(ctx, result.Error)
 }
 
 func (d *Database) Delete(ctx context.Context, value any, opts ...FindOption) error {
-	ctx, cancel := context.WithTimeout(ctx, DatabaseTimeout)
+	ctx, cancel := context.WithTimeout(ctx, d.writeTimeout)
 	defer cancel()
 
-	query := d.applyOptions(opts...)
-	return query.Delete(value).Error
+	query := d.applyOptions(ctx, opts...)
+	return wrapError(ctx, query.Delete(value).Error)
 }
 
 func (d *Database) FindById(ctx context.Context, id string, result any) error {
-	ctx, cancel := context.WithTimeout(ctx, DatabaseTimeout)
+	ctx, cancel := context.WithTimeout(ctx, d.readTimeout)
 	defer cancel()
 
-	if err := d.db.Where("id = ? ", id).First(result).Error; err != nil {
-		return err
+	if err := d.getDB(ctx).Where("id = ? ", id).First(result).Error; err != nil {
+		return wrapError(ctx, err)
 	}
 
 	return nil
 }
 
 func (d *Database) FindOne(ctx context.Context, result any, opts ...FindOption) error {
-	ctx, cancel := context.WithTimeout(ctx, DatabaseTimeout)
+	ctx, cancel := context.WithTimeout(ctx, d.readTimeout)
 	defer cancel()
 
-	query := d.applyOptions(opts...)
+	query := d.applyOptions(ctx, opts...)
 	if err := query.First(result).Error; err != nil {
-		return err
+		return wrapError(ctx, err)
 	}
 
 	return nil
 }
 
 func (d *Database) Find(ctx context.Context, result any, opts ...FindOption) error {
-	ctx, cancel := context.WithTimeout(ctx, DatabaseTimeout)
+	ctx, cancel := context.WithTimeout(ctx, d.readTimeout)
 	defer cancel()
 
-	query := d.applyOptions(opts...)
+	query := d.applyOptions(ctx, opts...)
 	if err := query.Find(result).Error; err != nil {
-		return err
+		return wrapError(ctx, err)
 	}
 
 	return nil
 }
 
 func (d *Database) Count(ctx context.Context, model any, total *int64, opts ...FindOption) error {
-	ctx, cancel := context.WithTimeout(ctx, DatabaseTimeout)
+	ctx, cancel := context.WithTimeout(ctx, d.readTimeout)
 	defer cancel()
 
-	query := d.applyOptions(opts...)
+	query := d.applyOptions(ctx, opts...)
 	if err := query.Model(model).Count(total).Error; err != nil {
-		return err
+		return wrapError(ctx, err)
 	}
 
 	return nil
 }
 
+// Lock takes the advisory lock key until the end of the transaction in ctx, waiting for the
+// transaction holding it if any. Outside of a transaction the lock is released right away.
+func (d *Database) Lock(ctx context.Context, key int64) error {
+	ctx, cancel := context.WithTimeout(ctx, d.writeTimeout)
+	defer cancel()
+
+	return wrapError(ctx, d.getDB(ctx).Exec("SELECT pg_advisory_xact_lock(?)", key).Error)
+}
+
 func (d *Database) GetDB() *gorm.DB {
 	return d.db
 }
 
-func (d *Database) applyOptions(opts ...FindOption) *gorm.DB {
-	query := d.db
+// getDB returns the transaction carried by ctx, or the root connection when there is none,
+// bound to ctx so that queries are aborted when it is cancelled.
+func (d *Database) getDB(ctx context.Context) *gorm.DB {
+	db := d.db
+	if tx, ok := ctx.Value(txKey{}).(*gorm.DB); ok {
+		db = tx
+	}
+
+	return db.WithContext(ctx)
+}
+
+func (d *Database) applyOptions(ctx context.Context, opts ...FindOption) *gorm.DB {
+	query := d.getDB(ctx)
 
 	opt := getOption(opts...)
 
+	if opt.table != "" {
+		query = query.Table(opt.table)
+	}
+
+	if opt.selects != nil {
+		query = query.Select(opt.selects.Query, opt.selects.Args...)
+	}
+
+	for _, join := range opt.joins {
+		query = query.Joins(join.Query, join.Args...)
+	}
+
 	if len(opt.preloads) != 0 {
 		for _, preload := range opt.preloads {
 			query = query.Preload(preload)
@@ -183,11 +290,21 @@ func (d *Database) applyOptions(opts ...FindOption) *gorm.DB {
 
 	if opt.query != nil {
 		for _, q := range opt.query {
-			query = query.Where(q.Query, q.Args)
+			query = query.Where(q.Query, q.Args...)
 		}
 	}
 
-	if opt.order != "" {
+	if opt.cursor != nil {
+		query = query.Where(opt.cursor.Query, opt.cursor.Args...)
+	}
+
+	if opt.group != "" {
+		query = query.Group(opt.group)
+	}
+
+	if order, ok := opt.order.(clause.OrderBy); ok {
+		query = query.Clauses(order)
+	} else if opt.order != "" {
 		query = query.Order(opt.order)
 	}
 
@@ -199,5 +316,9 @@ func (d *Database) applyOptions(opts ...FindOption) *gorm.DB {
 		query = query.Limit(opt.limit)
 	}
 
+	if opt.lock {
+		query = query.Clauses(clause.Locking{Strength: "UPDATE"})
+	}
+
 	return query
 }
@@ -0,0 +1,42 @@
+package dbs
+
+import (
+	"context"
+	"errors"
+)
+
+// CancelledError is returned when a query is aborted because its context was cancelled
+// or its deadline was exceeded.
+type CancelledError struct {
+	Err error
+}
+
+func (e *CancelledError) Error() string {
+	return "query cancelled: " + e.Err.Error()
+}
+
+func (e *CancelledError) Unwrap() error {
+	return e.Err
+}
+
+// Timeout reports whether the query ran out of time rather than being cancelled by the caller.
+func (e *CancelledError) Timeout() bool {
+	return errors.Is(e.Err, context.DeadlineExceeded)
+}
+
+func wrapError(ctx context.Context, err error) error {
+	if err == nil {
+		return nil
+	}
+
+	var cancelled *CancelledError
+	if errors.As(err, &cancelled) {
+		return err
+	}
+
+	if ctxErr := ctx.Err(); ctxErr != nil {
+		return &CancelledError{Err: ctxErr}
+	}
+
+	return err
+}
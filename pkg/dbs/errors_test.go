@@ -0,0 +1,52 @@
+package dbs
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWrapErrorNil(t *testing.T) {
+	assert.Nil(t, wrapError(context.Background(), nil))
+}
+
+func TestWrapErrorNotCancelled(t *testing.T) {
+	err := errors.New("error")
+	assert.Equal(t, err, wrapError(context.Background(), err))
+}
+
+func TestWrapErrorCancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	err := wrapError(ctx, errors.New("error"))
+
+	var cancelled *CancelledError
+	assert.True(t, errors.As(err, &cancelled))
+	assert.False(t, cancelled.Timeout())
+	assert.True(t, errors.Is(err, context.Canceled))
+}
+
+func TestWrapErrorDeadlineExceeded(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Nanosecond)
+	defer cancel()
+	<-ctx.Done()
+
+	err := wrapError(ctx, errors.New("error"))
+
+	var cancelled *CancelledError
+	assert.True(t, errors.As(err, &cancelled))
+	assert.True(t, cancelled.Timeout())
+	assert.True(t, errors.Is(err, context.DeadlineExceeded))
+}
+
+func TestWrapErrorAlreadyWrapped(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	err := &CancelledError{Err: context.DeadlineExceeded}
+	assert.Equal(t, err, wrapError(ctx, err))
+}
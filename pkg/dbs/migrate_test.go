@@ -0,0 +1,91 @@
+package dbs
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"testing/fstest"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLoadMigrations(t *testing.T) {
+	source := fstest.MapFS{
+		"000002_add_index.up.sql":      {Data: []byte("CREATE INDEX")},
+		"000002_add_index.down.sql":    {Data: []byte("DROP INDEX")},
+		"000001_create_table.up.sql":   {Data: []byte("CREATE TABLE")},
+		"000001_create_table.down.sql": {Data: []byte("DROP TABLE")},
+	}
+
+	migrations, err := LoadMigrations(source)
+	assert.Nil(t, err)
+	assert.Equal(t, 2, len(migrations))
+	assert.Equal(t, int64(1), migrations[0].Version)
+	assert.Equal(t, "create_table", migrations[0].Name)
+	assert.Equal(t, "CREATE TABLE", migrations[0].Up)
+	assert.Equal(t, "DROP TABLE", migrations[0].Down)
+	assert.Equal(t, int64(2), migrations[1].Version)
+}
+
+func TestLoadMigrationsInvalidFileName(t *testing.T) {
+	source := fstest.MapFS{
+		"create_table.sql": {Data: []byte("CREATE TABLE")},
+	}
+
+	migrations, err := LoadMigrations(source)
+	assert.NotNil(t, err)
+	assert.Nil(t, migrations)
+}
+
+func TestLoadMigrationsMissingDown(t *testing.T) {
+	source := fstest.MapFS{
+		"000001_create_table.up.sql": {Data: []byte("CREATE TABLE")},
+	}
+
+	migrations, err := LoadMigrations(source)
+	assert.NotNil(t, err)
+	assert.Nil(t, migrations)
+}
+
+func TestLoadMigrationsDuplicateVersion(t *testing.T) {
+	source := fstest.MapFS{
+		"000001_create_table.up.sql":   {Data: []byte("CREATE TABLE")},
+		"000001_create_table.down.sql": {Data: []byte("DROP TABLE")},
+		"000001_add_index.up.sql":      {Data: []byte("CREATE INDEX")},
+		"000001_add_index.down.sql":    {Data: []byte("DROP INDEX")},
+	}
+
+	migrations, err := LoadMigrations(source)
+	assert.NotNil(t, err)
+	assert.Nil(t, migrations)
+}
+
+func TestEmbeddedMigrations(t *testing.T) {
+	migrations, err := LoadMigrations(Migrations())
+	assert.Nil(t, err)
+	assert.NotEmpty(t, migrations)
+	for i, migration := range migrations {
+		assert.Equal(t, int64(i+1), migration.Version)
+	}
+}
+
+func TestCreateMigration(t *testing.T) {
+	dir := t.TempDir()
+
+	upFile, downFile, err := CreateMigration(dir, "Create Users")
+	assert.Nil(t, err)
+	assert.Equal(t, filepath.Join(dir, "000001_create_users.up.sql"), upFile)
+	assert.Equal(t, filepath.Join(dir, "000001_create_users.down.sql"), downFile)
+
+	upFile, _, err = CreateMigration(dir, "add_index")
+	assert.Nil(t, err)
+	assert.Equal(t, filepath.Join(dir, "000002_add_index.up.sql"), upFile)
+
+	_, err = os.Stat(upFile)
+	assert.Nil(t, err)
+}
+
+func TestCreateMigrationInvalidName(t *testing.T) {
+	_, _, err := CreateMigration(t.TempDir(), "drop-table")
+	assert.NotNil(t, err)
+}
@@ -0,0 +1,14 @@
+CREATE TABLE IF NOT EXISTS "users" (
+    "id"         text NOT NULL UNIQUE,
+    "created_at" timestamptz,
+    "updated_at" timestamptz,
+    "deleted_at" timestamptz,
+    "email"      text NOT NULL UNIQUE,
+    "password"   text,
+    "role"       text,
+    PRIMARY KEY ("id")
+);
+
+CREATE INDEX IF NOT EXISTS "idx_user_email" ON "users" ("email");
+CREATE INDEX IF NOT EXISTS "idx_users_deleted_at" ON "users" ("deleted_at");
+CREATE INDEX IF NOT EXISTS "idx_users_id" ON "users" ("id");
@@ -0,0 +1,17 @@
+CREATE TABLE IF NOT EXISTS "products" (
+    "id"          text NOT NULL UNIQUE,
+    "created_at"  timestamptz,
+    "updated_at"  timestamptz,
+    "deleted_at"  timestamptz,
+    "code"        text,
+    "name"        text,
+    "description" text,
+    "price"       decimal,
+    "active"      boolean DEFAULT true,
+    PRIMARY KEY ("id")
+);
+
+CREATE INDEX IF NOT EXISTS "idx_products_id" ON "products" ("id");
+CREATE UNIQUE INDEX IF NOT EXISTS "idx_product_name" ON "products" ("name");
+CREATE UNIQUE INDEX IF NOT EXISTS "idx_product_code" ON "products" ("code");
+CREATE INDEX IF NOT EXISTS "idx_products_deleted_at" ON "products" ("deleted_at");
@@ -0,0 +1,32 @@
+CREATE TABLE IF NOT EXISTS "orders" (
+    "id"          text NOT NULL UNIQUE,
+    "created_at"  timestamptz,
+    "updated_at"  timestamptz,
+    "deleted_at"  timestamptz,
+    "code"        text,
+    "user_id"     text,
+    "total_price" decimal,
+    "status"      text,
+    PRIMARY KEY ("id"),
+    CONSTRAINT "fk_orders_user" FOREIGN KEY ("user_id") REFERENCES "users" ("id")
+);
+
+CREATE INDEX IF NOT EXISTS "idx_orders_deleted_at" ON "orders" ("deleted_at");
+CREATE INDEX IF NOT EXISTS "idx_orders_id" ON "orders" ("id");
+
+CREATE TABLE IF NOT EXISTS "order_lines" (
+    "id"         text NOT NULL UNIQUE,
+    "created_at" timestamptz,
+    "updated_at" timestamptz,
+    "deleted_at" timestamptz,
+    "order_id"   text,
+    "product_id" text,
+    "quantity"   bigint,
+    "price"      decimal,
+    PRIMARY KEY ("id"),
+    CONSTRAINT "fk_order_lines_product" FOREIGN KEY ("product_id") REFERENCES "products" ("id"),
+    CONSTRAINT "fk_orders_lines" FOREIGN KEY ("order_id") REFERENCES "orders" ("id")
+);
+
+CREATE INDEX IF NOT EXISTS "idx_order_lines_deleted_at" ON "order_lines" ("deleted_at");
+CREATE INDEX IF NOT EXISTS "idx_order_lines_id" ON "order_lines" ("id");
@@ -0,0 +1,28 @@
+CREATE TABLE IF NOT EXISTS "carts" (
+    "id"         text NOT NULL UNIQUE,
+    "created_at" timestamptz,
+    "updated_at" timestamptz,
+    "deleted_at" timestamptz,
+    "user_id"    text NOT NULL UNIQUE,
+    PRIMARY KEY ("id"),
+    CONSTRAINT "fk_carts_user" FOREIGN KEY ("user_id") REFERENCES "users" ("id")
+);
+
+CREATE INDEX IF NOT EXISTS "idx_carts_user_id" ON "carts" ("user_id");
+CREATE INDEX IF NOT EXISTS "idx_carts_deleted_at" ON "carts" ("deleted_at");
+CREATE INDEX IF NOT EXISTS "idx_carts_id" ON "carts" ("id");
+
+CREATE TABLE IF NOT EXISTS "cart_lines" (
+    "id"         text NOT NULL UNIQUE,
+    "created_at" timestamptz,
+    "updated_at" timestamptz,
+    "cart_id"    text NOT NULL,
+    "product_id" text NOT NULL,
+    "quantity"   bigint,
+    PRIMARY KEY ("id"),
+    CONSTRAINT "fk_cart_lines_product" FOREIGN KEY ("product_id") REFERENCES "products" ("id"),
+    CONSTRAINT "fk_carts_lines" FOREIGN KEY ("cart_id") REFERENCES "carts" ("id") ON DELETE CASCADE
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS "idx_cart_line_product" ON "cart_lines" ("cart_id", "product_id");
+CREATE INDEX IF NOT EXISTS "idx_cart_lines_id" ON "cart_lines" ("id");
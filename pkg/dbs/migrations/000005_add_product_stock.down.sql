@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS "stock_movements";
+DROP FUNCTION IF EXISTS "stock_movements_append_only"();
+DROP TABLE IF EXISTS "product_stocks";
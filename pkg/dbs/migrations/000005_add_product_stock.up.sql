@@ -0,0 +1,43 @@
+CREATE TABLE IF NOT EXISTS "product_stocks" (
+    "product_id" text NOT NULL,
+    "updated_at" timestamptz,
+    "on_hand"    bigint NOT NULL DEFAULT 0,
+    "reserved"   bigint NOT NULL DEFAULT 0,
+    PRIMARY KEY ("product_id"),
+    CONSTRAINT "fk_products_stock" FOREIGN KEY ("product_id") REFERENCES "products" ("id") ON DELETE CASCADE,
+    CONSTRAINT "chk_product_stocks_quantity" CHECK ("reserved" >= 0 AND "on_hand" >= "reserved")
+);
+
+INSERT INTO "product_stocks" ("product_id", "updated_at")
+SELECT "id", now() FROM "products"
+ON CONFLICT DO NOTHING;
+
+CREATE TABLE IF NOT EXISTS "stock_movements" (
+    "id"         text NOT NULL UNIQUE,
+    "created_at" timestamptz,
+    "product_id" text NOT NULL,
+    "order_id"   text,
+    "type"       text NOT NULL,
+    "reason"     text NOT NULL,
+    "quantity"   bigint NOT NULL,
+    "note"       text,
+    "created_by" text,
+    PRIMARY KEY ("id"),
+    CONSTRAINT "fk_stock_movements_product" FOREIGN KEY ("product_id") REFERENCES "products" ("id")
+);
+
+CREATE INDEX IF NOT EXISTS "idx_stock_movements_id" ON "stock_movements" ("id");
+CREATE INDEX IF NOT EXISTS "idx_stock_movements_product_id" ON "stock_movements" ("product_id");
+CREATE INDEX IF NOT EXISTS "idx_stock_movements_order_id" ON "stock_movements" ("order_id");
+
+-- The ledger is append-only
+CREATE OR REPLACE FUNCTION "stock_movements_append_only"() RETURNS trigger AS
+$$
+BEGIN
+    RAISE EXCEPTION 'stock_movements is append-only';
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER "trg_stock_movements_append_only"
+    BEFORE UPDATE OR DELETE ON "stock_movements"
+    FOR EACH ROW EXECUTE FUNCTION "stock_movements_append_only"();
@@ -0,0 +1,21 @@
+CREATE TABLE IF NOT EXISTS "order_status_history" (
+    "id"          text NOT NULL UNIQUE,
+    "created_at"  timestamptz,
+    "order_id"    text NOT NULL,
+    "from_status" text,
+    "to_status"   text NOT NULL,
+    "changed_by"  text,
+    "note"        text,
+    PRIMARY KEY ("id"),
+    CONSTRAINT "fk_order_status_history_order" FOREIGN KEY ("order_id") REFERENCES "orders" ("id") ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS "idx_order_status_history_id" ON "order_status_history" ("id");
+CREATE INDEX IF NOT EXISTS "idx_order_status_history_order_id" ON "order_status_history" ("order_id");
+
+-- Orders placed before the history was recorded start their timeline at their creation
+INSERT INTO "order_status_history" ("id", "created_at", "order_id", "from_status", "to_status", "changed_by")
+SELECT gen_random_uuid()::text, "created_at", "id", '', 'new', "user_id" FROM "orders";
+
+INSERT INTO "order_status_history" ("id", "created_at", "order_id", "from_status", "to_status")
+SELECT gen_random_uuid()::text, "updated_at", "id", 'new', "status" FROM "orders" WHERE "status" <> 'new';
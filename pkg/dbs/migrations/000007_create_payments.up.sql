@@ -0,0 +1,22 @@
+ALTER TABLE "orders" ADD COLUMN IF NOT EXISTS "payment_status" text NOT NULL DEFAULT 'unpaid';
+
+CREATE TABLE IF NOT EXISTS "payments" (
+    "id"              text NOT NULL UNIQUE,
+    "created_at"      timestamptz,
+    "updated_at"      timestamptz,
+    "order_id"        text NOT NULL,
+    "user_id"         text,
+    "provider"        text NOT NULL,
+    "reference"       text,
+    "status"          text NOT NULL,
+    "amount"          decimal NOT NULL,
+    "captured_amount" decimal NOT NULL DEFAULT 0,
+    "refunded_amount" decimal NOT NULL DEFAULT 0,
+    "failure_reason"  text,
+    PRIMARY KEY ("id"),
+    CONSTRAINT "fk_payments_order" FOREIGN KEY ("order_id") REFERENCES "orders" ("id") ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS "idx_payments_id" ON "payments" ("id");
+CREATE INDEX IF NOT EXISTS "idx_payments_order_id" ON "payments" ("order_id");
+CREATE UNIQUE INDEX IF NOT EXISTS "idx_payments_provider_reference" ON "payments" ("provider", "reference") WHERE "reference" <> '';
@@ -0,0 +1,13 @@
+CREATE TABLE IF NOT EXISTS "idempotency_keys" (
+    "key"          text NOT NULL,
+    "created_at"   timestamptz,
+    "expires_at"   timestamptz NOT NULL,
+    "fingerprint"  text NOT NULL,
+    "completed"    boolean NOT NULL DEFAULT false,
+    "status_code"  bigint,
+    "content_type" text,
+    "body"         bytea,
+    PRIMARY KEY ("key")
+);
+
+CREATE INDEX IF NOT EXISTS "idx_idempotency_keys_expires_at" ON "idempotency_keys" ("expires_at");
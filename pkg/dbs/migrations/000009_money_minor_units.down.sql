@@ -0,0 +1,24 @@
+ALTER TABLE "payments" DROP COLUMN IF EXISTS "refunded_currency";
+ALTER TABLE "payments" DROP COLUMN IF EXISTS "captured_currency";
+ALTER TABLE "payments" DROP COLUMN IF EXISTS "currency";
+ALTER TABLE "payments" ALTER COLUMN "refunded_amount" TYPE decimal USING "refunded_amount" / 100;
+ALTER TABLE "payments" ALTER COLUMN "captured_amount" TYPE decimal USING "captured_amount" / 100;
+ALTER TABLE "payments" ALTER COLUMN "amount" TYPE decimal USING "amount" / 100;
+
+ALTER TABLE "order_lines" DROP COLUMN IF EXISTS "price_currency";
+ALTER TABLE "order_lines" ALTER COLUMN "price_amount" DROP NOT NULL;
+ALTER TABLE "order_lines" ALTER COLUMN "price_amount" DROP DEFAULT;
+ALTER TABLE "order_lines" ALTER COLUMN "price_amount" TYPE decimal USING "price_amount" / 100;
+ALTER TABLE "order_lines" RENAME COLUMN "price_amount" TO "price";
+
+ALTER TABLE "orders" DROP COLUMN IF EXISTS "total_price_currency";
+ALTER TABLE "orders" ALTER COLUMN "total_price_amount" DROP NOT NULL;
+ALTER TABLE "orders" ALTER COLUMN "total_price_amount" DROP DEFAULT;
+ALTER TABLE "orders" ALTER COLUMN "total_price_amount" TYPE decimal USING "total_price_amount" / 100;
+ALTER TABLE "orders" RENAME COLUMN "total_price_amount" TO "total_price";
+
+ALTER TABLE "products" DROP COLUMN IF EXISTS "price_currency";
+ALTER TABLE "products" ALTER COLUMN "price_amount" DROP NOT NULL;
+ALTER TABLE "products" ALTER COLUMN "price_amount" DROP DEFAULT;
+ALTER TABLE "products" ALTER COLUMN "price_amount" TYPE decimal USING "price_amount" / 100;
+ALTER TABLE "products" RENAME COLUMN "price_amount" TO "price";
@@ -0,0 +1,26 @@
+-- Prices become integer minor units plus an ISO 4217 currency. Existing rows are taken to be USD,
+-- which has 2 minor unit digits.
+ALTER TABLE "products" RENAME COLUMN "price" TO "price_amount";
+ALTER TABLE "products" ALTER COLUMN "price_amount" TYPE numeric(19,0) USING round(coalesce("price_amount", 0) * 100);
+ALTER TABLE "products" ALTER COLUMN "price_amount" SET NOT NULL;
+ALTER TABLE "products" ALTER COLUMN "price_amount" SET DEFAULT 0;
+ALTER TABLE "products" ADD COLUMN IF NOT EXISTS "price_currency" char(3) NOT NULL DEFAULT 'USD';
+
+ALTER TABLE "orders" RENAME COLUMN "total_price" TO "total_price_amount";
+ALTER TABLE "orders" ALTER COLUMN "total_price_amount" TYPE numeric(19,0) USING round(coalesce("total_price_amount", 0) * 100);
+ALTER TABLE "orders" ALTER COLUMN "total_price_amount" SET NOT NULL;
+ALTER TABLE "orders" ALTER COLUMN "total_price_amount" SET DEFAULT 0;
+ALTER TABLE "orders" ADD COLUMN IF NOT EXISTS "total_price_currency" char(3) NOT NULL DEFAULT 'USD';
+
+ALTER TABLE "order_lines" RENAME COLUMN "price" TO "price_amount";
+ALTER TABLE "order_lines" ALTER COLUMN "price_amount" TYPE numeric(19,0) USING round(coalesce("price_amount", 0) * 100);
+ALTER TABLE "order_lines" ALTER COLUMN "price_amount" SET NOT NULL;
+ALTER TABLE "order_lines" ALTER COLUMN "price_amount" SET DEFAULT 0;
+ALTER TABLE "order_lines" ADD COLUMN IF NOT EXISTS "price_currency" char(3) NOT NULL DEFAULT 'USD';
+
+ALTER TABLE "payments" ALTER COLUMN "amount" TYPE numeric(19,0) USING round("amount" * 100);
+ALTER TABLE "payments" ALTER COLUMN "captured_amount" TYPE numeric(19,0) USING round("captured_amount" * 100);
+ALTER TABLE "payments" ALTER COLUMN "refunded_amount" TYPE numeric(19,0) USING round("refunded_amount" * 100);
+ALTER TABLE "payments" ADD COLUMN IF NOT EXISTS "currency" char(3) NOT NULL DEFAULT 'USD';
+ALTER TABLE "payments" ADD COLUMN IF NOT EXISTS "captured_currency" char(3) NOT NULL DEFAULT 'USD';
+ALTER TABLE "payments" ADD COLUMN IF NOT EXISTS "refunded_currency" char(3) NOT NULL DEFAULT 'USD';
@@ -0,0 +1,8 @@
+DROP TABLE IF EXISTS "order_discounts";
+ALTER TABLE "order_lines" DROP COLUMN IF EXISTS "discount_currency";
+ALTER TABLE "order_lines" DROP COLUMN IF EXISTS "discount_amount";
+ALTER TABLE "orders" DROP COLUMN IF EXISTS "discount_currency";
+ALTER TABLE "orders" DROP COLUMN IF EXISTS "discount_amount";
+DROP TABLE IF EXISTS "promotion_redemptions";
+DROP TABLE IF EXISTS "promotion_scopes";
+DROP TABLE IF EXISTS "promotions";
@@ -0,0 +1,71 @@
+CREATE TABLE IF NOT EXISTS "promotions" (
+    "id"                       text NOT NULL UNIQUE,
+    "created_at"               timestamptz,
+    "updated_at"               timestamptz,
+    "deleted_at"               timestamptz,
+    "code"                     text NOT NULL,
+    "name"                     text,
+    "type"                     text NOT NULL,
+    "percentage"               bigint NOT NULL DEFAULT 0,
+    "discount_amount"          numeric(19,0) NOT NULL DEFAULT 0,
+    "discount_currency"        text NOT NULL DEFAULT '',
+    "buy_quantity"             bigint NOT NULL DEFAULT 0,
+    "get_quantity"             bigint NOT NULL DEFAULT 0,
+    "min_order_value_amount"   numeric(19,0) NOT NULL DEFAULT 0,
+    "min_order_value_currency" text NOT NULL DEFAULT '',
+    "usage_limit"              bigint NOT NULL DEFAULT 0,
+    "user_usage_limit"         bigint NOT NULL DEFAULT 0,
+    "usage_count"              bigint NOT NULL DEFAULT 0,
+    "starts_at"                timestamptz,
+    "ends_at"                  timestamptz,
+    "active"                   boolean NOT NULL DEFAULT true,
+    PRIMARY KEY ("id"),
+    CONSTRAINT "chk_promotions_usage" CHECK ("usage_limit" = 0 OR "usage_count" <= "usage_limit")
+);
+
+CREATE INDEX IF NOT EXISTS "idx_promotions_deleted_at" ON "promotions" ("deleted_at");
+CREATE INDEX IF NOT EXISTS "idx_promotions_id" ON "promotions" ("id");
+CREATE UNIQUE INDEX IF NOT EXISTS "idx_promotion_code" ON "promotions" ("code");
+
+CREATE TABLE IF NOT EXISTS "promotion_scopes" (
+    "promotion_id" text NOT NULL,
+    "type"         text NOT NULL,
+    "target_id"    text NOT NULL,
+    PRIMARY KEY ("promotion_id", "type", "target_id"),
+    CONSTRAINT "fk_promotions_scopes" FOREIGN KEY ("promotion_id") REFERENCES "promotions" ("id") ON DELETE CASCADE
+);
+
+CREATE TABLE IF NOT EXISTS "promotion_redemptions" (
+    "id"           text NOT NULL UNIQUE,
+    "created_at"   timestamptz,
+    "promotion_id" text NOT NULL,
+    "user_id"      text NOT NULL,
+    "order_id"     text NOT NULL,
+    "amount"       numeric(19,0) NOT NULL,
+    "currency"     char(3) NOT NULL,
+    PRIMARY KEY ("id"),
+    CONSTRAINT "fk_promotion_redemptions_promotion" FOREIGN KEY ("promotion_id") REFERENCES "promotions" ("id"),
+    CONSTRAINT "fk_promotion_redemptions_order" FOREIGN KEY ("order_id") REFERENCES "orders" ("id") ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS "idx_promotion_redemptions_promotion_user" ON "promotion_redemptions" ("promotion_id", "user_id");
+
+ALTER TABLE "orders" ADD COLUMN IF NOT EXISTS "discount_amount" numeric(19,0) NOT NULL DEFAULT 0;
+ALTER TABLE "orders" ADD COLUMN IF NOT EXISTS "discount_currency" text NOT NULL DEFAULT '';
+ALTER TABLE "order_lines" ADD COLUMN IF NOT EXISTS "discount_amount" numeric(19,0) NOT NULL DEFAULT 0;
+ALTER TABLE "order_lines" ADD COLUMN IF NOT EXISTS "discount_currency" text NOT NULL DEFAULT '';
+
+CREATE TABLE IF NOT EXISTS "order_discounts" (
+    "id"           text NOT NULL UNIQUE,
+    "created_at"   timestamptz,
+    "order_id"     text NOT NULL,
+    "promotion_id" text NOT NULL,
+    "code"         text NOT NULL,
+    "amount"       numeric(19,0) NOT NULL,
+    "currency"     char(3) NOT NULL,
+    PRIMARY KEY ("id"),
+    CONSTRAINT "fk_orders_discounts" FOREIGN KEY ("order_id") REFERENCES "orders" ("id") ON DELETE CASCADE,
+    CONSTRAINT "fk_order_discounts_promotion" FOREIGN KEY ("promotion_id") REFERENCES "promotions" ("id")
+);
+
+CREATE INDEX IF NOT EXISTS "idx_order_discounts_order_id" ON "order_discounts" ("order_id");
@@ -0,0 +1,28 @@
+CREATE TABLE IF NOT EXISTS "categories" (
+    "id"         text NOT NULL UNIQUE,
+    "created_at" timestamptz,
+    "updated_at" timestamptz,
+    "deleted_at" timestamptz,
+    "parent_id"  text,
+    "name"       text NOT NULL,
+    "slug"       text NOT NULL,
+    "position"   bigint NOT NULL DEFAULT 0,
+    PRIMARY KEY ("id"),
+    CONSTRAINT "fk_categories_parent" FOREIGN KEY ("parent_id") REFERENCES "categories" ("id"),
+    CONSTRAINT "chk_categories_parent" CHECK ("parent_id" <> "id")
+);
+
+CREATE INDEX IF NOT EXISTS "idx_categories_id" ON "categories" ("id");
+CREATE INDEX IF NOT EXISTS "idx_categories_deleted_at" ON "categories" ("deleted_at");
+CREATE INDEX IF NOT EXISTS "idx_categories_parent_id" ON "categories" ("parent_id");
+CREATE UNIQUE INDEX IF NOT EXISTS "idx_category_slug" ON "categories" ("slug");
+
+CREATE TABLE IF NOT EXISTS "product_categories" (
+    "product_id"  text NOT NULL,
+    "category_id" text NOT NULL,
+    PRIMARY KEY ("product_id", "category_id"),
+    CONSTRAINT "fk_product_categories_product" FOREIGN KEY ("product_id") REFERENCES "products" ("id") ON DELETE CASCADE,
+    CONSTRAINT "fk_product_categories_category" FOREIGN KEY ("category_id") REFERENCES "categories" ("id") ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS "idx_product_categories_category_id" ON "product_categories" ("category_id");
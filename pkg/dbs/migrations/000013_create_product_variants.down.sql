@@ -0,0 +1,15 @@
+DROP INDEX IF EXISTS "idx_cart_line_product";
+DELETE FROM "cart_lines" WHERE "variant_id" <> '';
+CREATE UNIQUE INDEX IF NOT EXISTS "idx_cart_line_product" ON "cart_lines" ("cart_id", "product_id");
+ALTER TABLE "cart_lines" DROP COLUMN IF EXISTS "variant_id";
+
+ALTER TABLE "order_lines" DROP COLUMN IF EXISTS "variant_id";
+
+DROP INDEX IF EXISTS "idx_stock_movements_variant_id";
+ALTER TABLE "stock_movements" DROP COLUMN IF EXISTS "variant_id";
+
+DROP TABLE IF EXISTS "variant_stocks";
+DROP TABLE IF EXISTS "variant_options";
+DROP TABLE IF EXISTS "product_variants";
+DROP TABLE IF EXISTS "product_option_values";
+DROP TABLE IF EXISTS "product_options";
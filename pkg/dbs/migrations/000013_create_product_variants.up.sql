@@ -0,0 +1,67 @@
+CREATE TABLE IF NOT EXISTS "product_options" (
+    "id"         text NOT NULL UNIQUE,
+    "product_id" text NOT NULL,
+    "name"       text NOT NULL,
+    "position"   bigint NOT NULL DEFAULT 0,
+    PRIMARY KEY ("id"),
+    CONSTRAINT "fk_products_options" FOREIGN KEY ("product_id") REFERENCES "products" ("id") ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS "idx_product_options_id" ON "product_options" ("id");
+CREATE UNIQUE INDEX IF NOT EXISTS "idx_product_option_name" ON "product_options" ("product_id", "name");
+
+CREATE TABLE IF NOT EXISTS "product_option_values" (
+    "option_id" text NOT NULL,
+    "value"     text NOT NULL,
+    "position"  bigint NOT NULL DEFAULT 0,
+    PRIMARY KEY ("option_id", "value"),
+    CONSTRAINT "fk_product_options_values" FOREIGN KEY ("option_id") REFERENCES "product_options" ("id") ON DELETE CASCADE
+);
+
+CREATE TABLE IF NOT EXISTS "product_variants" (
+    "id"             text NOT NULL UNIQUE,
+    "created_at"     timestamptz,
+    "updated_at"     timestamptz,
+    "deleted_at"     timestamptz,
+    "product_id"     text NOT NULL,
+    "sku"            text NOT NULL,
+    "price_amount"   numeric(19,0) NOT NULL DEFAULT 0,
+    "price_currency" text NOT NULL DEFAULT '',
+    "active"         boolean NOT NULL DEFAULT true,
+    PRIMARY KEY ("id"),
+    CONSTRAINT "fk_products_variants" FOREIGN KEY ("product_id") REFERENCES "products" ("id") ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS "idx_product_variants_id" ON "product_variants" ("id");
+CREATE INDEX IF NOT EXISTS "idx_product_variants_deleted_at" ON "product_variants" ("deleted_at");
+CREATE INDEX IF NOT EXISTS "idx_product_variants_product_id" ON "product_variants" ("product_id");
+CREATE UNIQUE INDEX IF NOT EXISTS "idx_product_variant_sku" ON "product_variants" ("sku");
+
+CREATE TABLE IF NOT EXISTS "variant_options" (
+    "variant_id" text NOT NULL,
+    "name"       text NOT NULL,
+    "value"      text NOT NULL,
+    PRIMARY KEY ("variant_id", "name"),
+    CONSTRAINT "fk_product_variants_options" FOREIGN KEY ("variant_id") REFERENCES "product_variants" ("id") ON DELETE CASCADE
+);
+
+CREATE TABLE IF NOT EXISTS "variant_stocks" (
+    "variant_id" text NOT NULL,
+    "updated_at" timestamptz,
+    "on_hand"    bigint NOT NULL DEFAULT 0,
+    "reserved"   bigint NOT NULL DEFAULT 0,
+    PRIMARY KEY ("variant_id"),
+    CONSTRAINT "fk_product_variants_stock" FOREIGN KEY ("variant_id") REFERENCES "product_variants" ("id") ON DELETE CASCADE,
+    CONSTRAINT "chk_variant_stocks_quantity" CHECK ("reserved" >= 0 AND "on_hand" >= "reserved")
+);
+
+ALTER TABLE "stock_movements" ADD COLUMN IF NOT EXISTS "variant_id" text;
+CREATE INDEX IF NOT EXISTS "idx_stock_movements_variant_id" ON "stock_movements" ("variant_id");
+
+-- Lines of products without variants have an empty variant_id, so that the unique index
+-- of cart lines still holds for them
+ALTER TABLE "order_lines" ADD COLUMN IF NOT EXISTS "variant_id" text NOT NULL DEFAULT '';
+
+ALTER TABLE "cart_lines" ADD COLUMN IF NOT EXISTS "variant_id" text NOT NULL DEFAULT '';
+DROP INDEX IF EXISTS "idx_cart_line_product";
+CREATE UNIQUE INDEX IF NOT EXISTS "idx_cart_line_product" ON "cart_lines" ("cart_id", "product_id", "variant_id");
@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS "idx_variant_option_value";
+DROP INDEX IF EXISTS "idx_product_created_at";
+DROP INDEX IF EXISTS "idx_product_price";
@@ -0,0 +1,3 @@
+CREATE INDEX IF NOT EXISTS "idx_product_price" ON "products" ("price_currency", "price_amount");
+CREATE INDEX IF NOT EXISTS "idx_product_created_at" ON "products" ("created_at");
+CREATE INDEX IF NOT EXISTS "idx_variant_option_value" ON "variant_options" ("name", "value");
@@ -0,0 +1,15 @@
+CREATE TABLE IF NOT EXISTS "refresh_tokens" (
+    "id"         text NOT NULL UNIQUE,
+    "created_at" timestamptz,
+    "family_id"  text NOT NULL,
+    "user_id"    text NOT NULL,
+    "expires_at" timestamptz NOT NULL,
+    "used_at"    timestamptz,
+    "revoked_at" timestamptz,
+    PRIMARY KEY ("id"),
+    CONSTRAINT "fk_users_refresh_tokens" FOREIGN KEY ("user_id") REFERENCES "users" ("id") ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS "idx_refresh_tokens_id" ON "refresh_tokens" ("id");
+CREATE INDEX IF NOT EXISTS "idx_refresh_tokens_family_id" ON "refresh_tokens" ("family_id");
+CREATE INDEX IF NOT EXISTS "idx_refresh_tokens_user_id" ON "refresh_tokens" ("user_id");
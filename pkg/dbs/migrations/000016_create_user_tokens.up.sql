@@ -0,0 +1,15 @@
+CREATE TABLE IF NOT EXISTS "user_tokens" (
+    "id"         text NOT NULL UNIQUE,
+    "created_at" timestamptz,
+    "user_id"    text NOT NULL,
+    "purpose"    text NOT NULL,
+    "token_hash" text NOT NULL,
+    "expires_at" timestamptz NOT NULL,
+    "used_at"    timestamptz,
+    PRIMARY KEY ("id"),
+    CONSTRAINT "fk_users_tokens" FOREIGN KEY ("user_id") REFERENCES "users" ("id") ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS "idx_user_tokens_id" ON "user_tokens" ("id");
+CREATE INDEX IF NOT EXISTS "idx_user_tokens_user_id" ON "user_tokens" ("user_id");
+CREATE UNIQUE INDEX IF NOT EXISTS "idx_user_tokens_token_hash" ON "user_tokens" ("token_hash");
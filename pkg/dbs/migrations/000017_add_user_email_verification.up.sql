@@ -0,0 +1,4 @@
+ALTER TABLE "users" ADD COLUMN IF NOT EXISTS "email_verified_at" timestamptz;
+
+-- Accounts created before verification existed are not locked out by the verification policy
+UPDATE "users" SET "email_verified_at" = "created_at" WHERE "email_verified_at" IS NULL;
@@ -9,6 +9,8 @@ import (
 	gorm "gorm.io/gorm"
 
 	mock "github.com/stretchr/testify/mock"
+
+	sql "database/sql"
 )
 
 // IDatabase is an autogenerated mock type for the IDatabase type
@@ -174,6 +176,20 @@ func (_m *IDatabase) GetDB() *gorm.DB {
 	return r0
 }
 
+// Lock provides a mock function with given fields: ctx, key
+func (_m *IDatabase) Lock(ctx context.Context, key int64) error {
+	ret := _m.Called(ctx, key)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, int64) error); ok {
+		r0 = rf(ctx, key)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // Update provides a mock function with given fields: ctx, doc
 func (_m *IDatabase) Update(ctx context.Context, doc interface{}) error {
 	ret := _m.Called(ctx, doc)
@@ -188,13 +204,72 @@ func (_m *IDatabase) Update(ctx context.Context, doc interface{}) error {
 	return r0
 }
 
-// WithTransaction provides a mock function with given fields: function
-func (_m *IDatabase) WithTransaction(function func() error) error {
-	ret := _m.Called(function)
+// UpdateColumns provides a mock function with given fields: ctx, model, values, opts
+func (_m *IDatabase) UpdateColumns(ctx context.Context, model interface{}, values map[string]interface{}, opts ...dbs.FindOption) (int64, error) {
+	_va := make([]interface{}, len(opts))
+	for _i := range opts {
+		_va[_i] = opts[_i]
+	}
+	var _ca []interface{}
+	_ca = append(_ca, ctx, model, values)
+	_ca = append(_ca, _va...)
+	ret := _m.Called(_ca...)
+
+	var r0 int64
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, interface{}, map[string]interface{}, ...dbs.FindOption) (int64, error)); ok {
+		return rf(ctx, model, values, opts...)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, interface{}, map[string]interface{}, ...dbs.FindOption) int64); ok {
+		r0 = rf(ctx, model, values, opts...)
+	} else {
+		r0 = ret.Get(0).(int64)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, interface{}, map[string]interface{}, ...dbs.FindOption) error); ok {
+		r1 = rf(ctx, model, values, opts...)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Upsert provides a mock function with given fields: ctx, docs, conflictColumns, updateColumns
+func (_m *IDatabase) Upsert(ctx context.Context, docs interface{}, conflictColumns []string, updateColumns []string) error {
+	ret := _m.Called(ctx, docs, conflictColumns, updateColumns)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, interface{}, []string, []string) error); ok {
+		r0 = rf(ctx, docs, conflictColumns, updateColumns)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// WithIsolationLevel provides a mock function with given fields: ctx, level, function
+func (_m *IDatabase) WithIsolationLevel(ctx context.Context, level sql.IsolationLevel, function func(context.Context) error) error {
+	ret := _m.Called(ctx, level, function)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, sql.IsolationLevel, func(context.Context) error) error); ok {
+		r0 = rf(ctx, level, function)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// WithTransaction provides a mock function with given fields: ctx, function
+func (_m *IDatabase) WithTransaction(ctx context.Context, function func(context.Context) error) error {
+	ret := _m.Called(ctx, function)
 
 	var r0 error
-	if rf, ok := ret.Get(0).(func(func() error) error); ok {
-		r0 = rf(function)
+	if rf, ok := ret.Get(0).(func(context.Context, func(context.Context) error) error); ok {
+		r0 = rf(ctx, function)
 	} else {
 		r0 = ret.Error(0)
 	}
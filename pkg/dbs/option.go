@@ -1,5 +1,7 @@
 package dbs
 
+import "fmt"
+
 type FindOption interface {
 	apply(*option)
 }
@@ -10,6 +12,12 @@ type option struct {
 	offset   int
 	limit    int
 	preloads []string
+	table    string
+	selects  *Query
+	joins    []Query
+	group    string
+	cursor   *Query
+	lock     bool
 }
 
 type optionFn func(*option)
@@ -18,10 +26,29 @@ func (f optionFn) apply(opt *option) {
 	f(opt)
 }
 
+// queryOption and orderOption are values rather than functions so that tests can compare them
+type queryOption []Query
+
+func (o queryOption) apply(opt *option) {
+	opt.query = o
+}
+
+type orderOption struct {
+	order any
+}
+
+func (o orderOption) apply(opt *option) {
+	opt.order = o.order
+}
+
+type lockOption struct{}
+
+func (lockOption) apply(opt *option) {
+	opt.lock = true
+}
+
 func WithQuery(query ...Query) FindOption {
-	return optionFn(func(opt *option) {
-		opt.query = query
-	})
+	return queryOption(query)
 }
 
 func WithOffset(offset int) FindOption {
@@ -37,9 +64,7 @@ func WithLimit(limit int) FindOption {
 }
 
 func WithOrder(order interface{}) FindOption {
-	return optionFn(func(opt *option) {
-		opt.order = order
-	})
+	return orderOption{order: order}
 }
 
 func WithPreload(preloads []string) FindOption {
@@ -48,6 +73,70 @@ func WithPreload(preloads []string) FindOption {
 	})
 }
 
+// WithTable reads from table instead of the table of the result, for results that are not models
+func WithTable(table string) FindOption {
+	return optionFn(func(opt *option) {
+		opt.table = table
+	})
+}
+
+// WithSelect reads the columns of query instead of all the columns
+func WithSelect(query string, args ...any) FindOption {
+	return optionFn(func(opt *option) {
+		selects := NewQuery(query, args...)
+		opt.selects = &selects
+	})
+}
+
+func WithJoins(joins ...Query) FindOption {
+	return optionFn(func(opt *option) {
+		opt.joins = joins
+	})
+}
+
+func WithGroup(group string) FindOption {
+	return optionFn(func(opt *option) {
+		opt.group = group
+	})
+}
+
+// WithLock locks the rows read FOR UPDATE until the end of the transaction in ctx, so that
+// concurrent transactions reading them with WithLock wait for it
+func WithLock() FindOption {
+	return lockOption{}
+}
+
+// Cursor is a keyset position in rows sorted by Column then id: the row having Key in Column
+// and ID. An empty ID is the start of the rows.
+type Cursor struct {
+	Column string
+	Desc   bool
+	Key    any
+	ID     string
+	// Before reads the rows before the position, last first
+	Before bool
+}
+
+// WithCursor reads the rows after cursor, or before it, in the order of its column. It replaces
+// WithOffset, which scans and skips rows, for pages deep in large tables.
+func WithCursor(cursor Cursor) FindOption {
+	return optionFn(func(opt *option) {
+		direction, operator := "", ">"
+		if cursor.Desc != cursor.Before {
+			direction, operator = " DESC", "<"
+		}
+
+		opt.order = fmt.Sprintf("%s%s, id%s", cursor.Column, direction, direction)
+		opt.cursor = nil
+		if cursor.ID != "" {
+			opt.cursor = &Query{
+				Query: fmt.Sprintf("(%s, id) %s (?, ?)", cursor.Column, operator),
+				Args:  []any{cursor.Key, cursor.ID},
+			}
+		}
+	})
+}
+
 func getOption(opts ...FindOption) option {
 	opt := option{
 		query:  []Query{},
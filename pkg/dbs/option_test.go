@@ -0,0 +1,42 @@
+package dbs
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWithCursorStart(t *testing.T) {
+	opt := getOption(WithCursor(Cursor{Column: "created_at", Desc: true}))
+	assert.Equal(t, "created_at DESC, id DESC", opt.order)
+	assert.Nil(t, opt.cursor)
+}
+
+func TestWithCursorAfter(t *testing.T) {
+	opt := getOption(WithCursor(Cursor{Column: "price_amount", Key: "100", ID: "p1"}))
+	assert.Equal(t, "price_amount, id", opt.order)
+	assert.Equal(t, &Query{Query: "(price_amount, id) > (?, ?)", Args: []any{"100", "p1"}}, opt.cursor)
+}
+
+func TestWithCursorBefore(t *testing.T) {
+	opt := getOption(WithCursor(Cursor{Column: "name", Key: "b", ID: "p1", Before: true}))
+	assert.Equal(t, "name DESC, id DESC", opt.order)
+	assert.Equal(t, "(name, id) < (?, ?)", opt.cursor.Query)
+
+	opt = getOption(WithCursor(Cursor{Column: "name", Desc: true, Key: "b", ID: "p1", Before: true}))
+	assert.Equal(t, "name, id", opt.order)
+	assert.Equal(t, "(name, id) > (?, ?)", opt.cursor.Query)
+}
+
+func TestWithOrder(t *testing.T) {
+	assert.Equal(t, "id", getOption().order)
+	assert.Equal(t, "product_id", getOption(WithOrder("product_id")).order)
+	assert.Equal(t, WithOrder("product_id"), WithOrder("product_id"))
+	assert.Equal(t, WithQuery(NewQuery("id = ?", "1")), WithQuery(NewQuery("id = ?", "1")))
+}
+
+func TestWithLock(t *testing.T) {
+	assert.False(t, getOption().lock)
+	assert.True(t, getOption(WithLock()).lock)
+	assert.Equal(t, WithLock(), WithLock())
+}
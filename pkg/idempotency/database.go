@@ -0,0 +1,96 @@
+package idempotency
+
+import (
+	"context"
+	"time"
+
+	"goshop/pkg/dbs"
+)
+
+// IdempotencyKey is the row storing a record in the database
+type IdempotencyKey struct {
+	Key         string `gorm:"primary_key"`
+	CreatedAt   time.Time
+	ExpiresAt   time.Time
+	Fingerprint string
+	Completed   bool
+	StatusCode  int
+	ContentType string
+	Body        []byte
+}
+
+func (k *IdempotencyKey) record() *Record {
+	return &Record{
+		Fingerprint: k.Fingerprint,
+		Completed:   k.Completed,
+		StatusCode:  k.StatusCode,
+		ContentType: k.ContentType,
+		Body:        k.Body,
+	}
+}
+
+type databaseStore struct {
+	db  dbs.IDatabase
+	ttl time.Duration
+}
+
+// NewDatabaseStore returns a store keeping records in the idempotency_keys table for ttl
+func NewDatabaseStore(db dbs.IDatabase, ttl time.Duration) Store {
+	return &databaseStore{db: db, ttl: ttl}
+}
+
+func (s *databaseStore) Begin(ctx context.Context, key, fingerprint string) (*Record, bool, error) {
+	row := &IdempotencyKey{
+		Key:         key,
+		ExpiresAt:   time.Now().Add(s.ttl),
+		Fingerprint: fingerprint,
+	}
+
+	// The primary key makes the insert fail when the key was already claimed
+	err := s.db.Create(ctx, row)
+	if err == nil {
+		return row.record(), true, nil
+	}
+
+	var existing IdempotencyKey
+	query := dbs.WithQuery(dbs.NewQuery("key = ?", key))
+	if findErr := s.db.FindOne(ctx, &existing, query, dbs.WithOrder("key")); findErr != nil {
+		return nil, false, err
+	}
+
+	if existing.ExpiresAt.After(time.Now()) {
+		return existing.record(), false, nil
+	}
+
+	// Expired keys are claimed again
+	if err := s.db.Delete(ctx, &IdempotencyKey{}, dbs.WithQuery(
+		dbs.NewQuery("key = ?", key),
+		dbs.NewQuery("expires_at <= ?", time.Now()),
+	)); err != nil {
+		return nil, false, err
+	}
+	if err := s.db.Create(ctx, row); err != nil {
+		return nil, false, err
+	}
+
+	return row.record(), true, nil
+}
+
+func (s *databaseStore) Complete(ctx context.Context, key string, record *Record) error {
+	row := &IdempotencyKey{
+		Key:         key,
+		ExpiresAt:   time.Now().Add(s.ttl),
+		Fingerprint: record.Fingerprint,
+		Completed:   record.Completed,
+		StatusCode:  record.StatusCode,
+		ContentType: record.ContentType,
+		Body:        record.Body,
+	}
+
+	return s.db.Upsert(ctx, row, []string{"key"},
+		[]string{"expires_at", "fingerprint", "completed", "status_code", "content_type", "body"})
+}
+
+func (s *databaseStore) Release(ctx context.Context, key string) error {
+	return s.db.Delete(ctx, &IdempotencyKey{}, dbs.WithQuery(dbs.NewQuery("key = ?", key)))
+}
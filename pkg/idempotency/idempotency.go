@@ -0,0 +1,109 @@
+package idempotency
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"time"
+
+	"github.com/quangdangfit/gocommon/logger"
+
+	"goshop/pkg/dbs"
+	"goshop/pkg/redis"
+)
+
+const (
+	// DefaultTTL is how long keys are remembered when no TTL is configured
+	DefaultTTL = 24 * time.Hour
+
+	keyPrefix = "idempotency:"
+)
+
+// Record is what is stored under an idempotency key: the fingerprint of the first
+// request using it and, once it completed, its response.
+type Record struct {
+	Fingerprint string `json:"fingerprint"`
+	Completed   bool   `json:"completed"`
+	StatusCode  int    `json:"status_code,omitempty"`
+	ContentType string `json:"content_type,omitempty"`
+	Body        []byte `json:"body,omitempty"`
+}
+
+// Store keeps idempotency records
+//
+//go:generate mockery --name=Store
+type Store interface {
+	// Begin claims key for a request with fingerprint. When key was claimed before,
+	// it returns the record of the earlier request and false.
+	Begin(ctx context.Context, key, fingerprint string) (*Record, bool, error)
+	// Complete stores the response of the request that claimed key
+	Complete(ctx context.Context, key string, record *Record) error
+	// Release forgets key so that the request may be sent again
+	Release(ctx context.Context, key string) error
+}
+
+// Fingerprint identifies a request by hashing its parts
+func Fingerprint(parts ...[]byte) string {
+	hash := sha256.New()
+	for _, part := range parts {
+		hash.Write(part)
+		hash.Write([]byte{0})
+	}
+
+	return hex.EncodeToString(hash.Sum(nil))
+}
+
+// NewStore returns a store keeping records in redis, falling back to the database
+// when redis cannot be reached. cache may be nil to only use the database.
+func NewStore(cache redis.IRedis, db dbs.IDatabase, ttl time.Duration) Store {
+	if ttl <= 0 {
+		ttl = DefaultTTL
+	}
+
+	store := &fallbackStore{fallback: NewDatabaseStore(db, ttl)}
+	if cache != nil {
+		store.primary = NewRedisStore(cache, ttl)
+	}
+
+	return store
+}
+
+type fallbackStore struct {
+	primary  Store
+	fallback Store
+}
+
+func (s *fallbackStore) Begin(ctx context.Context, key, fingerprint string) (*Record, bool, error) {
+	if s.primary != nil {
+		record, claimed, err := s.primary.Begin(ctx, key, fingerprint)
+		if err == nil {
+			return record, claimed, nil
+		}
+		logger.Error("Failed to claim idempotency key in redis, using the database: ", err)
+	}
+
+	return s.fallback.Begin(ctx, key, fingerprint)
+}
+
+func (s *fallbackStore) Complete(ctx context.Context, key string, record *Record) error {
+	if s.primary != nil {
+		err := s.primary.Complete(ctx, key, record)
+		if err == nil {
+			return nil
+		}
+		logger.Error("Failed to store idempotent response in redis, using the database: ", err)
+	}
+
+	return s.fallback.Complete(ctx, key, record)
+}
+
+// Release forgets key in both stores, since it may have been claimed in either
+func (s *fallbackStore) Release(ctx context.Context, key string) error {
+	if s.primary != nil {
+		if err := s.primary.Release(ctx, key); err != nil {
+			logger.Error("Failed to release idempotency key in redis: ", err)
+		}
+	}
+
+	return s.fallback.Release(ctx, key)
+}
@@ -0,0 +1,112 @@
+package idempotency
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/quangdangfit/gocommon/logger"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	"goshop/pkg/config"
+	dbMocks "goshop/pkg/dbs/mocks"
+	redisMocks "goshop/pkg/redis/mocks"
+)
+
+func init() {
+	logger.Initialize(config.ProductionEnv)
+}
+
+func TestFingerprint(t *testing.T) {
+	assert.Equal(t, Fingerprint([]byte("POST"), []byte("/orders")), Fingerprint([]byte("POST"), []byte("/orders")))
+	assert.NotEqual(t, Fingerprint([]byte("POST"), []byte("/orders")), Fingerprint([]byte("POST"), []byte("/order"), []byte("s")))
+}
+
+func TestRedisStoreBeginClaimed(t *testing.T) {
+	mockRedis := redisMocks.NewIRedis(t)
+	mockRedis.On("SetNX", "idempotency:user:key", &Record{Fingerprint: "fp"}, time.Hour).Return(true, nil).Times(1)
+
+	record, claimed, err := NewRedisStore(mockRedis, time.Hour).Begin(context.Background(), "user:key", "fp")
+	assert.Nil(t, err)
+	assert.True(t, claimed)
+	assert.Equal(t, "fp", record.Fingerprint)
+	assert.False(t, record.Completed)
+}
+
+func TestRedisStoreBeginExisting(t *testing.T) {
+	mockRedis := redisMocks.NewIRedis(t)
+	mockRedis.On("SetNX", "idempotency:user:key", mock.Anything, time.Hour).Return(false, nil).Times(1)
+	mockRedis.On("Get", "idempotency:user:key", mock.Anything).
+		Run(func(args mock.Arguments) {
+			record := args.Get(1).(*Record)
+			record.Fingerprint = "fp"
+			record.Completed = true
+			record.StatusCode = 200
+		}).
+		Return(nil).Times(1)
+
+	record, claimed, err := NewRedisStore(mockRedis, time.Hour).Begin(context.Background(), "user:key", "fp")
+	assert.Nil(t, err)
+	assert.False(t, claimed)
+	assert.True(t, record.Completed)
+	assert.Equal(t, 200, record.StatusCode)
+}
+
+func TestDatabaseStoreBeginExisting(t *testing.T) {
+	mockDB := dbMocks.NewIDatabase(t)
+	mockDB.On("Create", mock.Anything, mock.Anything).Return(errors.New("duplicate key")).Times(1)
+	mockDB.On("FindOne", mock.Anything, mock.Anything, mock.Anything, mock.Anything).
+		Run(func(args mock.Arguments) {
+			row := args.Get(1).(*IdempotencyKey)
+			row.Fingerprint = "other"
+			row.ExpiresAt = time.Now().Add(time.Hour)
+		}).
+		Return(nil).Times(1)
+
+	record, claimed, err := NewDatabaseStore(mockDB, time.Hour).Begin(context.Background(), "user:key", "fp")
+	assert.Nil(t, err)
+	assert.False(t, claimed)
+	assert.Equal(t, "other", record.Fingerprint)
+}
+
+func TestDatabaseStoreBeginExpired(t *testing.T) {
+	mockDB := dbMocks.NewIDatabase(t)
+	mockDB.On("Create", mock.Anything, mock.Anything).Return(errors.New("duplicate key")).Times(1)
+	mockDB.On("FindOne", mock.Anything, mock.Anything, mock.Anything, mock.Anything).
+		Run(func(args mock.Arguments) {
+			row := args.Get(1).(*IdempotencyKey)
+			row.ExpiresAt = time.Now().Add(-time.Minute)
+		}).
+		Return(nil).Times(1)
+	mockDB.On("Delete", mock.Anything, mock.Anything, mock.Anything).Return(nil).Times(1)
+	mockDB.On("Create", mock.Anything, mock.Anything).Return(nil).Times(1)
+
+	record, claimed, err := NewDatabaseStore(mockDB, time.Hour).Begin(context.Background(), "user:key", "fp")
+	assert.Nil(t, err)
+	assert.True(t, claimed)
+	assert.Equal(t, "fp", record.Fingerprint)
+}
+
+func TestStoreFallsBackToDatabase(t *testing.T) {
+	mockRedis := redisMocks.NewIRedis(t)
+	mockRedis.On("SetNX", mock.Anything, mock.Anything, mock.Anything).Return(false, errors.New("connection refused")).Times(1)
+	mockDB := dbMocks.NewIDatabase(t)
+	mockDB.On("Create", mock.Anything, mock.Anything).Return(nil).Times(1)
+
+	record, claimed, err := NewStore(mockRedis, mockDB, 0).Begin(context.Background(), "user:key", "fp")
+	assert.Nil(t, err)
+	assert.True(t, claimed)
+	assert.Equal(t, "fp", record.Fingerprint)
+}
+
+func TestStoreReleaseBoth(t *testing.T) {
+	mockRedis := redisMocks.NewIRedis(t)
+	mockRedis.On("Remove", "idempotency:user:key").Return(nil).Times(1)
+	mockDB := dbMocks.NewIDatabase(t)
+	mockDB.On("Delete", mock.Anything, mock.Anything, mock.Anything).Return(nil).Times(1)
+
+	err := NewStore(mockRedis, mockDB, time.Hour).Release(context.Background(), "user:key")
+	assert.Nil(t, err)
+}
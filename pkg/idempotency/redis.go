@@ -0,0 +1,44 @@
+package idempotency
+
+import (
+	"context"
+	"time"
+
+	"goshop/pkg/redis"
+)
+
+type redisStore struct {
+	cache redis.IRedis
+	ttl   time.Duration
+}
+
+// NewRedisStore returns a store keeping records in redis for ttl
+func NewRedisStore(cache redis.IRedis, ttl time.Duration) Store {
+	return &redisStore{cache: cache, ttl: ttl}
+}
+
+func (s *redisStore) Begin(ctx context.Context, key, fingerprint string) (*Record, bool, error) {
+	record := &Record{Fingerprint: fingerprint}
+	claimed, err := s.cache.SetNX(keyPrefix+key, record, s.ttl)
+	if err != nil {
+		return nil, false, err
+	}
+	if claimed {
+		return record, true, nil
+	}
+
+	var existing Record
+	if err := s.cache.Get(keyPrefix+key, &existing); err != nil {
+		return nil, false, err
+	}
+
+	return &existing, false, nil
+}
+
+func (s *redisStore) Complete(ctx context.Context, key string, record *Record) error {
+	return s.cache.SetWithExpiration(keyPrefix+key, record, s.ttl)
+}
+
+func (s *redisStore) Release(ctx context.Context, key string) error {
+	return s.cache.Remove(keyPrefix + key)
+}
@@ -1,6 +1,7 @@
 package jtoken
 
 import (
+	"errors"
 	"strings"
 	"time"
 
@@ -11,6 +12,8 @@ import (
 	"goshop/pkg/utils"
 )
 
+var ErrInvalidClaims = errors.New("token issued by another issuer or for another audience")
+
 const (
 	AccessTokenExpiredTime  = 5 * 60 * 60 // 5 hours
 	RefreshTokenExpiredTime = 30 * 24 * 3600
@@ -19,14 +22,8 @@ const (
 )
 
 func GenerateAccessToken(payload map[string]interface{}) string {
-	cfg := config.GetConfig()
 	payload["type"] = AccessTokenType
-	tokenContent := jwt.MapClaims{
-		"payload": payload,
-		"exp":     time.Now().Add(time.Second * AccessTokenExpiredTime).Unix(),
-	}
-	jwtToken := jwt.NewWithClaims(jwt.GetSigningMethod("HS256"), tokenContent)
-	token, err := jwtToken.SignedString([]byte(cfg.AuthSecret))
+	token, err := generateToken(payload, AccessTokenExpiredTime)
 	if err != nil {
 		logger.Error("Failed to generate access token: ", err)
 		return ""
@@ -36,14 +33,8 @@ func GenerateAccessToken(payload map[string]interface{}) string {
 }
 
 func GenerateRefreshToken(payload map[string]interface{}) string {
-	cfg := config.GetConfig()
 	payload["type"] = RefreshTokenType
-	tokenContent := jwt.MapClaims{
-		"payload": payload,
-		"exp":     time.Now().Add(time.Second * RefreshTokenExpiredTime).Unix(),
-	}
-	jwtToken := jwt.NewWithClaims(jwt.GetSigningMethod("HS256"), tokenContent)
-	token, err := jwtToken.SignedString([]byte(cfg.AuthSecret))
+	token, err := generateToken(payload, RefreshTokenExpiredTime)
 	if err != nil {
 		logger.Error("Failed to generate refresh token: ", err)
 		return ""
@@ -52,14 +43,52 @@ func GenerateRefreshToken(payload map[string]interface{}) string {
 	return token
 }
 
+// generateToken signs payload with the signing key, the token expiring in ttl seconds
+func generateToken(payload map[string]interface{}, ttl int64) (string, error) {
+	cfg := config.GetConfig()
+	key := getKeys().signing
+	tokenContent := jwt.MapClaims{
+		"payload": payload,
+		"exp":     time.Now().Add(time.Second * time.Duration(ttl)).Unix(),
+	}
+	if cfg.AuthIssuer != "" {
+		tokenContent["iss"] = cfg.AuthIssuer
+	}
+	if cfg.AuthAudience != "" {
+		tokenContent["aud"] = cfg.AuthAudience
+	}
+
+	jwtToken := jwt.NewWithClaims(key.Method, tokenContent)
+	if key.ID != "" {
+		jwtToken.Header["kid"] = key.ID
+	}
+	return jwtToken.SignedString(key.private)
+}
+
+// ValidateToken returns the payload of jwtToken, verified with the key of its kid, when it was
+// issued by auth_issuer for auth_audience
 func ValidateToken(jwtToken string) (map[string]interface{}, error) {
 	cfg := config.GetConfig()
+	keySet := getKeys()
 	cleanJWT := strings.Replace(jwtToken, "Bearer ", "", -1)
 	tokenData := jwt.MapClaims{}
 	token, err := jwt.ParseWithClaims(cleanJWT, tokenData, func(token *jwt.Token) (interface{}, error) {
-		return []byte(cfg.AuthSecret), nil
+		kid, _ := token.Header["kid"].(string)
+		key, err := keySet.Key(kid)
+		if err != nil {
+			return nil, err
+		}
+		// The algorithm is the one of the key, whatever the token claims
+		if token.Method.Alg() != key.Method.Alg() {
+			return nil, jwt.ErrInvalidKeyType
+		}
+		return key.public, nil
 	})
 
+	var validationErr *jwt.ValidationError
+	if errors.As(err, &validationErr) && validationErr.Inner != nil {
+		return nil, validationErr.Inner
+	}
 	if err != nil {
 		return nil, err
 	}
@@ -68,6 +97,11 @@ func ValidateToken(jwtToken string) (map[string]interface{}, error) {
 		return nil, jwt.ErrInvalidKey
 	}
 
+	if !tokenData.VerifyIssuer(cfg.AuthIssuer, cfg.AuthIssuer != "") ||
+		!tokenData.VerifyAudience(cfg.AuthAudience, cfg.AuthAudience != "") {
+		return nil, ErrInvalidClaims
+	}
+
 	var data map[string]interface{}
 	utils.Copy(&data, tokenData["payload"])
 
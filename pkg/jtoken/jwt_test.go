@@ -0,0 +1,207 @@
+package jtoken
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/golang-jwt/jwt"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"goshop/pkg/config"
+)
+
+// keepConfig restores the configuration and HS256 signing after the test
+func keepConfig(t *testing.T) {
+	cfg := config.GetConfig()
+	saved := *cfg
+	t.Cleanup(func() {
+		*cfg = saved
+		require.Nil(t, LoadKeys())
+	})
+}
+
+// useKeys configures the keys of dir
+func useKeys(t *testing.T, dir, signingKID string) {
+	cfg := config.GetConfig()
+	cfg.AuthKeysDir = dir
+	cfg.AuthSigningKeyID = signingKID
+	cfg.AuthIssuer = "goshop"
+	cfg.AuthAudience = "goshop"
+	require.Nil(t, LoadKeys())
+}
+
+func writePEM(t *testing.T, dir, name, blockType string, der []byte) {
+	data := pem.EncodeToMemory(&pem.Block{Type: blockType, Bytes: der})
+	require.Nil(t, os.WriteFile(filepath.Join(dir, name), data, 0o600))
+}
+
+func writeRSAKey(t *testing.T, dir, kid string) *rsa.PrivateKey {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.Nil(t, err)
+	writePEM(t, dir, kid+".pem", "RSA PRIVATE KEY", x509.MarshalPKCS1PrivateKey(key))
+	return key
+}
+
+func writeEdKey(t *testing.T, dir, kid string) ed25519.PrivateKey {
+	_, key, err := ed25519.GenerateKey(rand.Reader)
+	require.Nil(t, err)
+	der, err := x509.MarshalPKCS8PrivateKey(key)
+	require.Nil(t, err)
+	writePEM(t, dir, kid+".pem", "PRIVATE KEY", der)
+	return key
+}
+
+func TestTokenHS256(t *testing.T) {
+	token := GenerateAccessToken(map[string]interface{}{"id": "userID"})
+
+	payload, err := ValidateToken("Bearer " + token)
+	assert.Nil(t, err)
+	assert.Equal(t, "userID", payload["id"])
+	assert.Equal(t, AccessTokenType, payload["type"])
+	assert.Empty(t, JWKS().Keys)
+}
+
+func TestTokenRS256(t *testing.T) {
+	keepConfig(t)
+	dir := t.TempDir()
+	writeRSAKey(t, dir, "2024-01")
+	useKeys(t, dir, "")
+
+	token := GenerateRefreshToken(map[string]interface{}{"id": "userID"})
+	parsed, _, err := new(jwt.Parser).ParseUnverified(token, jwt.MapClaims{})
+	require.Nil(t, err)
+	assert.Equal(t, "RS256", parsed.Method.Alg())
+	assert.Equal(t, "2024-01", parsed.Header["kid"])
+
+	payload, err := ValidateToken(token)
+	assert.Nil(t, err)
+	assert.Equal(t, "userID", payload["id"])
+}
+
+func TestTokenEdDSA(t *testing.T) {
+	keepConfig(t)
+	dir := t.TempDir()
+	writeEdKey(t, dir, "ed")
+	useKeys(t, dir, "")
+
+	token := GenerateAccessToken(map[string]interface{}{"id": "userID"})
+	payload, err := ValidateToken(token)
+	assert.Nil(t, err)
+	assert.Equal(t, "userID", payload["id"])
+}
+
+func TestTokenRotation(t *testing.T) {
+	keepConfig(t)
+	dir := t.TempDir()
+	old := writeRSAKey(t, dir, "2024-01")
+	writeEdKey(t, dir, "2024-02")
+
+	// The newest key signs by default
+	useKeys(t, dir, "")
+	token := GenerateAccessToken(map[string]interface{}{"id": "userID"})
+	parsed, _, _ := new(jwt.Parser).ParseUnverified(token, jwt.MapClaims{})
+	assert.Equal(t, "2024-02", parsed.Header["kid"])
+
+	// auth_signing_kid picks another one, tokens of the other keys still verify
+	useKeys(t, dir, "2024-01")
+	oldToken := GenerateAccessToken(map[string]interface{}{"id": "userID"})
+	_, err := ValidateToken(token)
+	assert.Nil(t, err)
+
+	// A retired key only kept as public key still verifies the tokens it signed
+	require.Nil(t, os.Remove(filepath.Join(dir, "2024-01.pem")))
+	der, err := x509.MarshalPKIXPublicKey(&old.PublicKey)
+	require.Nil(t, err)
+	writePEM(t, dir, "2024-01.pub.pem", "PUBLIC KEY", der)
+	useKeys(t, dir, "")
+	_, err = ValidateToken(oldToken)
+	assert.Nil(t, err)
+
+	jwks := JWKS()
+	require.Len(t, jwks.Keys, 2)
+	assert.Equal(t, JSONWebKey{Kty: "RSA", Kid: "2024-01", Use: "sig", Alg: "RS256",
+		N: encodeSegment(old.N.Bytes()), E: "AQAB"}, jwks.Keys[0])
+	assert.Equal(t, "OKP", jwks.Keys[1].Kty)
+	assert.Equal(t, "Ed25519", jwks.Keys[1].Crv)
+	assert.Equal(t, "EdDSA", jwks.Keys[1].Alg)
+}
+
+func TestNewKeySetWithoutSigningKey(t *testing.T) {
+	dir := t.TempDir()
+	key := writeRSAKey(t, dir, "a")
+	require.Nil(t, os.Remove(filepath.Join(dir, "a.pem")))
+	der, _ := x509.MarshalPKIXPublicKey(&key.PublicKey)
+	writePEM(t, dir, "a.pub.pem", "PUBLIC KEY", der)
+
+	_, err := NewKeySet(&config.Schema{AuthKeysDir: dir})
+	assert.ErrorIs(t, err, ErrNoKey)
+
+	_, err = NewKeySet(&config.Schema{AuthKeysDir: dir, AuthSigningKeyID: "a"})
+	assert.ErrorIs(t, err, ErrNoKey)
+}
+
+func TestNewKeySetInvalidKey(t *testing.T) {
+	dir := t.TempDir()
+	require.Nil(t, os.WriteFile(filepath.Join(dir, "bad.pem"), []byte("bad"), 0o600))
+
+	_, err := NewKeySet(&config.Schema{AuthKeysDir: dir})
+	assert.NotNil(t, err)
+}
+
+func TestValidateTokenUnknownKid(t *testing.T) {
+	keepConfig(t)
+	dir := t.TempDir()
+	writeRSAKey(t, dir, "a")
+	useKeys(t, dir, "")
+	token := GenerateAccessToken(map[string]interface{}{"id": "userID"})
+
+	other := t.TempDir()
+	writeRSAKey(t, other, "b")
+	useKeys(t, other, "")
+
+	_, err := ValidateToken(token)
+	assert.ErrorIs(t, err, ErrUnknownKey)
+}
+
+func TestValidateTokenAlgorithmOfKey(t *testing.T) {
+	keepConfig(t)
+	dir := t.TempDir()
+	key := writeRSAKey(t, dir, "a")
+	useKeys(t, dir, "")
+
+	// An HS256 token signed with the public key of an RSA kid must not verify
+	publicPEM, _ := x509.MarshalPKIXPublicKey(&key.PublicKey)
+	forged := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
+		"payload": map[string]interface{}{"id": "admin"}, "iss": "goshop", "aud": "goshop",
+	})
+	forged.Header["kid"] = "a"
+	token, err := forged.SignedString(pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: publicPEM}))
+	require.Nil(t, err)
+
+	_, err = ValidateToken(token)
+	assert.NotNil(t, err)
+}
+
+func TestValidateTokenOtherAudience(t *testing.T) {
+	keepConfig(t)
+	dir := t.TempDir()
+	writeEdKey(t, dir, "a")
+	useKeys(t, dir, "")
+	token := GenerateAccessToken(map[string]interface{}{"id": "userID"})
+
+	config.GetConfig().AuthAudience = "other"
+	_, err := ValidateToken(token)
+	assert.ErrorIs(t, err, ErrInvalidClaims)
+
+	config.GetConfig().AuthAudience = "goshop"
+	config.GetConfig().AuthIssuer = "other"
+	_, err = ValidateToken(token)
+	assert.ErrorIs(t, err, ErrInvalidClaims)
+}
@@ -0,0 +1,174 @@
+package jtoken
+
+import (
+	"crypto/ed25519"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/golang-jwt/jwt"
+	"github.com/quangdangfit/gocommon/logger"
+
+	"goshop/pkg/config"
+)
+
+const (
+	privateKeyExt = ".pem"
+	publicKeyExt  = ".pub.pem"
+)
+
+var (
+	ErrUnknownKey = errors.New("unknown signing key")
+	ErrNoKey      = errors.New("no signing key")
+)
+
+// Key signs and verifies tokens. Keys loaded from a public key file only verify them.
+type Key struct {
+	ID     string
+	Method jwt.SigningMethod
+	// private signs tokens, nil for keys only verifying them
+	private interface{}
+	public  interface{}
+}
+
+// KeySet holds the keys verifying tokens, by kid, and the one signing them
+type KeySet struct {
+	keys    map[string]*Key
+	signing *Key
+}
+
+var (
+	keysMu sync.RWMutex
+	keys   *KeySet
+)
+
+// LoadKeys loads the keys configured by auth_keys_dir, replacing the keys in use. Without a key
+// directory tokens are signed with HS256 and auth_secret.
+func LoadKeys() error {
+	keySet, err := NewKeySet(config.GetConfig())
+	if err != nil {
+		return err
+	}
+
+	keysMu.Lock()
+	keys = keySet
+	keysMu.Unlock()
+	return nil
+}
+
+// NewKeySet returns the keys configured by cfg. Every <kid>.pem file of the key directory holds
+// the RSA or Ed25519 private key of kid, and every <kid>.pub.pem file the public key of a retired
+// key still verifying the tokens it signed. auth_signing_kid names the key signing tokens, the
+// last private key by kid does when it is empty.
+func NewKeySet(cfg *config.Schema) (*KeySet, error) {
+	if cfg.AuthKeysDir == "" {
+		key := &Key{
+			Method:  jwt.SigningMethodHS256,
+			private: []byte(cfg.AuthSecret),
+			public:  []byte(cfg.AuthSecret),
+		}
+		return &KeySet{keys: map[string]*Key{"": key}, signing: key}, nil
+	}
+
+	files, err := filepath.Glob(filepath.Join(cfg.AuthKeysDir, "*"+privateKeyExt))
+	if err != nil {
+		return nil, err
+	}
+
+	keySet := &KeySet{keys: make(map[string]*Key, len(files))}
+	var signingIDs []string
+	for _, file := range files {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			return nil, err
+		}
+
+		name := filepath.Base(file)
+		var key *Key
+		if strings.HasSuffix(name, publicKeyExt) {
+			key, err = parsePublicKey(strings.TrimSuffix(name, publicKeyExt), data)
+		} else {
+			key, err = parsePrivateKey(strings.TrimSuffix(name, privateKeyExt), data)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", file, err)
+		}
+		if _, ok := keySet.keys[key.ID]; ok {
+			return nil, fmt.Errorf("%s: duplicate key %q", file, key.ID)
+		}
+		keySet.keys[key.ID] = key
+		if key.private != nil {
+			signingIDs = append(signingIDs, key.ID)
+		}
+	}
+
+	signingID := cfg.AuthSigningKeyID
+	if signingID == "" && len(signingIDs) > 0 {
+		sort.Strings(signingIDs)
+		signingID = signingIDs[len(signingIDs)-1]
+	}
+	signing, ok := keySet.keys[signingID]
+	if !ok || signing.private == nil {
+		return nil, fmt.Errorf("%w in %s: %q", ErrNoKey, cfg.AuthKeysDir, signingID)
+	}
+	keySet.signing = signing
+
+	return keySet, nil
+}
+
+// Key returns the key of kid
+func (ks *KeySet) Key(kid string) (*Key, error) {
+	key, ok := ks.keys[kid]
+	if !ok {
+		return nil, fmt.Errorf("%w: %q", ErrUnknownKey, kid)
+	}
+
+	return key, nil
+}
+
+// getKeys returns the keys in use, loading them on first use
+func getKeys() *KeySet {
+	keysMu.RLock()
+	keySet := keys
+	keysMu.RUnlock()
+	if keySet != nil {
+		return keySet
+	}
+
+	if err := LoadKeys(); err != nil {
+		logger.Fatal("Failed to load signing keys: ", err)
+	}
+
+	keysMu.RLock()
+	defer keysMu.RUnlock()
+	return keys
+}
+
+func parsePrivateKey(kid string, data []byte) (*Key, error) {
+	if key, err := jwt.ParseRSAPrivateKeyFromPEM(data); err == nil {
+		return &Key{ID: kid, Method: jwt.SigningMethodRS256, private: key, public: &key.PublicKey}, nil
+	}
+
+	key, err := jwt.ParseEdPrivateKeyFromPEM(data)
+	if err != nil {
+		return nil, errors.New("not an RSA or Ed25519 private key")
+	}
+	edKey := key.(ed25519.PrivateKey)
+	return &Key{ID: kid, Method: jwt.SigningMethodEdDSA, private: edKey, public: edKey.Public()}, nil
+}
+
+func parsePublicKey(kid string, data []byte) (*Key, error) {
+	if key, err := jwt.ParseRSAPublicKeyFromPEM(data); err == nil {
+		return &Key{ID: kid, Method: jwt.SigningMethodRS256, public: key}, nil
+	}
+
+	key, err := jwt.ParseEdPublicKeyFromPEM(data)
+	if err != nil {
+		return nil, errors.New("not an RSA or Ed25519 public key")
+	}
+	return &Key{ID: kid, Method: jwt.SigningMethodEdDSA, public: key}, nil
+}
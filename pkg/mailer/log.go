@@ -0,0 +1,19 @@
+package mailer
+
+import (
+	"context"
+
+	"github.com/quangdangfit/gocommon/logger"
+)
+
+// LogMailer logs messages instead of sending them, for local development
+type LogMailer struct{}
+
+func NewLogMailer() *LogMailer {
+	return &LogMailer{}
+}
+
+func (m *LogMailer) Send(_ context.Context, msg *Message) error {
+	logger.Infof("Mail to %s, subject: %s\n%s", msg.To, msg.Subject, msg.Body)
+	return nil
+}
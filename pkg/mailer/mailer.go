@@ -0,0 +1,51 @@
+package mailer
+
+import (
+	"context"
+	"fmt"
+)
+
+const (
+	LogMailerName  = "log"
+	FileMailerName = "file"
+	SMTPMailerName = "smtp"
+)
+
+type Message struct {
+	To      string
+	Subject string
+	Body    string
+}
+
+// Mailer sends emails to users
+//
+//go:generate mockery --name=Mailer
+type Mailer interface {
+	Send(ctx context.Context, msg *Message) error
+}
+
+type Config struct {
+	// Provider is the name of the mailer, the log mailer when empty
+	Provider string
+	From     string
+	// Dir is the directory the file mailer writes messages to
+	Dir          string
+	SMTPHost     string
+	SMTPPort     int
+	SMTPUsername string
+	SMTPPassword string
+}
+
+// New returns the mailer configured by cfg
+func New(cfg Config) (Mailer, error) {
+	switch cfg.Provider {
+	case "", LogMailerName:
+		return NewLogMailer(), nil
+	case FileMailerName:
+		return NewFileMailer(cfg.Dir, cfg.From)
+	case SMTPMailerName:
+		return NewSMTPMailer(cfg.SMTPHost, cfg.SMTPPort, cfg.SMTPUsername, cfg.SMTPPassword, cfg.From), nil
+	default:
+		return nil, fmt.Errorf("unknown mailer: %s", cfg.Provider)
+	}
+}
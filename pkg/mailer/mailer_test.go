@@ -0,0 +1,93 @@
+package mailer
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/quangdangfit/gocommon/logger"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"goshop/pkg/config"
+)
+
+func TestNew(t *testing.T) {
+	mailer, err := New(Config{})
+	assert.Nil(t, err)
+	assert.IsType(t, &LogMailer{}, mailer)
+
+	mailer, err = New(Config{Provider: FileMailerName, Dir: t.TempDir()})
+	assert.Nil(t, err)
+	assert.IsType(t, &FileMailer{}, mailer)
+
+	mailer, err = New(Config{Provider: SMTPMailerName, SMTPHost: "localhost", SMTPPort: 25})
+	assert.Nil(t, err)
+	assert.IsType(t, &SMTPMailer{}, mailer)
+
+	_, err = New(Config{Provider: "unknown"})
+	assert.NotNil(t, err)
+}
+
+func TestFileMailer(t *testing.T) {
+	dir := t.TempDir()
+	mailer, err := NewFileMailer(dir, "shop@test.com")
+	require.Nil(t, err)
+
+	err = mailer.Send(context.Background(), &Message{
+		To:      "user@test.com",
+		Subject: "Hello\r\nBcc: other@test.com",
+		Body:    "line 1\nline 2",
+	})
+	require.Nil(t, err)
+
+	files, _ := filepath.Glob(filepath.Join(dir, "*.eml"))
+	require.Len(t, files, 1)
+	data, _ := os.ReadFile(files[0])
+	assert.Equal(t, "From: shop@test.com\r\n"+
+		"To: user@test.com\r\n"+
+		"Subject: HelloBcc: other@test.com\r\n"+
+		"MIME-Version: 1.0\r\n"+
+		"Content-Type: text/plain; charset=UTF-8\r\n"+
+		"\r\n"+
+		"line 1\r\nline 2", string(data))
+}
+
+type recordingMailer struct {
+	sent chan *Message
+}
+
+func (m *recordingMailer) Send(_ context.Context, msg *Message) error {
+	m.sent <- msg
+	return nil
+}
+
+func TestOutbox(t *testing.T) {
+	logger.Initialize(config.ProductionEnv)
+	recorder := &recordingMailer{sent: make(chan *Message)}
+	outbox := NewOutbox(recorder, 1)
+
+	msg := &Message{To: "user@test.com"}
+	assert.Nil(t, outbox.Send(context.Background(), msg))
+	select {
+	case sent := <-recorder.sent:
+		assert.Equal(t, msg, sent)
+	case <-time.After(time.Second):
+		t.Fatal("message not sent")
+	}
+}
+
+func TestOutboxFull(t *testing.T) {
+	// The mailer never returns, so that the message taken by the outbox stays in flight
+	recorder := &recordingMailer{sent: make(chan *Message)}
+	outbox := NewOutbox(recorder, 1)
+
+	assert.Nil(t, outbox.Send(context.Background(), &Message{}))
+	var err error
+	for i := 0; i < 3 && err == nil; i++ {
+		err = outbox.Send(context.Background(), &Message{})
+	}
+	assert.ErrorIs(t, err, ErrOutboxFull)
+}
@@ -0,0 +1,46 @@
+package mailer
+
+import (
+	"context"
+	"errors"
+
+	"github.com/quangdangfit/gocommon/logger"
+)
+
+var ErrOutboxFull = errors.New("mail outbox is full")
+
+// Outbox queues messages and sends them with a mailer in the background, so that requests do not
+// wait for the mail server, nor take longer depending on whether a mail is sent. Messages still
+// queued are lost when the process stops.
+type Outbox struct {
+	mailer Mailer
+	queue  chan *Message
+}
+
+// NewOutbox starts sending the messages queued, up to size at a time, with mailer
+func NewOutbox(mailer Mailer, size int) *Outbox {
+	outbox := &Outbox{
+		mailer: mailer,
+		queue:  make(chan *Message, size),
+	}
+	go outbox.run()
+	return outbox
+}
+
+// Send queues msg, failing with ErrOutboxFull instead of waiting when the queue is full
+func (o *Outbox) Send(_ context.Context, msg *Message) error {
+	select {
+	case o.queue <- msg:
+		return nil
+	default:
+		return ErrOutboxFull
+	}
+}
+
+func (o *Outbox) run() {
+	for msg := range o.queue {
+		if err := o.mailer.Send(context.Background(), msg); err != nil {
+			logger.Errorf("Failed to send mail to %s, subject: %s, error: %s", msg.To, msg.Subject, err)
+		}
+	}
+}
@@ -6,6 +6,7 @@ import (
 	"github.com/gin-gonic/gin"
 
 	"goshop/pkg/jtoken"
+	"goshop/pkg/rbac"
 )
 
 func JWTAuth() gin.HandlerFunc {
@@ -36,3 +37,17 @@ func JWT(tokenType string) gin.HandlerFunc {
 		c.Next()
 	}
 }
+
+// RequirePermission aborts with 403 unless the role of the authenticated user is granted
+// permission by the RBAC policy. It must run after JWTAuth.
+func RequirePermission(permission string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !rbac.GetPolicy().Can(c.GetString("role"), permission) {
+			c.JSON(http.StatusForbidden, nil)
+			c.Abort()
+			return
+		}
+
+		c.Next()
+	}
+}
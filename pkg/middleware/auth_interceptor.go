@@ -11,15 +11,20 @@ import (
 	"google.golang.org/grpc/status"
 
 	"goshop/pkg/jtoken"
+	"goshop/pkg/rbac"
 )
 
 type AuthInterceptor struct {
 	ignoredMethods []string
+	guestMethods   []string
 }
 
-func NewAuthInterceptor(ignoredMethods []string) *AuthInterceptor {
+// NewAuthInterceptor authenticates every method but ignoredMethods. guestMethods may also be
+// called without token, by guests identified by the guest-token metadata like in JWTOrGuestAuth.
+func NewAuthInterceptor(ignoredMethods, guestMethods []string) *AuthInterceptor {
 	return &AuthInterceptor{
 		ignoredMethods: ignoredMethods,
+		guestMethods:   guestMethods,
 	}
 }
 
@@ -36,38 +41,64 @@ func (ai *AuthInterceptor) Unary() grpc.UnaryServerInterceptor {
 			}
 		}
 
-		ctx, userID, err := ai.authorize(ctx)
+		m, _ := metadata.FromIncomingContext(ctx)
+		if len(m["token"]) == 0 && ai.isGuestMethod(info.FullMethod) {
+			token := ""
+			if len(m[GuestTokenMetadata]) > 0 {
+				token = m[GuestTokenMetadata][0]
+			}
+			token = guestToken(token)
+			_ = grpc.SetHeader(ctx, metadata.Pairs(GuestTokenMetadata, token))
+
+			ctx = context.WithValue(ctx, "guestToken", token)
+			ctx = context.WithValue(ctx, "role", rbac.RoleGuest)
+			return handler(ctx, req)
+		}
+
+		ctx, payload, err := ai.authorize(ctx)
 		if err != nil {
 			return nil, status.New(codes.Internal, err.Error()).Err()
 		}
 
-		// attach "userId" to context
-		ctx = context.WithValue(ctx, "userId", userID)
+		// attach "userId" and "role" to context
+		ctx = context.WithValue(ctx, "userId", payload["id"])
+		ctx = context.WithValue(ctx, "role", payload["role"])
 
 		return handler(ctx, req)
 	}
 }
 
-func (ai *AuthInterceptor) authorize(ctx context.Context) (context.Context, string, error) {
+func (ai *AuthInterceptor) isGuestMethod(method string) bool {
+	for _, m := range ai.guestMethods {
+		if method == m {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (ai *AuthInterceptor) authorize(ctx context.Context) (context.Context, map[string]interface{}, error) {
 	m, ok := metadata.FromIncomingContext(ctx)
 	if !ok || len(m["token"]) == 0 {
-		return ctx, "", status.New(codes.Unauthenticated, "missing token").Err()
+		return ctx, nil, status.New(codes.Unauthenticated, "missing token").Err()
 	}
 
+	// Refresh tokens only authenticate RefreshToken and Logout, which validate them themselves
 	payload, err := jtoken.ValidateToken(m["token"][0])
-	if err != nil {
-		return ctx, "", status.New(codes.Unauthenticated, "unauthorized").Err()
+	if err != nil || payload["type"] != jtoken.AccessTokenType {
+		return ctx, nil, status.New(codes.Unauthenticated, "unauthorized").Err()
 	}
 
 	var meta map[string]interface{}
 	b, err := json.Marshal(payload)
 	if err != nil {
-		return ctx, "", status.New(codes.Unauthenticated, "unauthorized").Err()
+		return ctx, nil, status.New(codes.Unauthenticated, "unauthorized").Err()
 	} else {
 		if err := json.Unmarshal(b, &meta); err != nil {
 			log.Println("Error while unmarshalling auth data", err)
 		}
 	}
 
-	return ctx, payload["id"].(string), nil
+	return ctx, payload, nil
 }
@@ -0,0 +1,40 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"goshop/pkg/dbs"
+)
+
+type ErrorInterceptor struct{}
+
+func NewErrorInterceptor() *ErrorInterceptor {
+	return &ErrorInterceptor{}
+}
+
+// Unary converts cancelled database queries to DeadlineExceeded or Canceled status errors
+func (ei *ErrorInterceptor) Unary() grpc.UnaryServerInterceptor {
+	return func(
+		ctx context.Context,
+		req interface{},
+		info *grpc.UnaryServerInfo,
+		handler grpc.UnaryHandler,
+	) (interface{}, error) {
+		res, err := handler(ctx, req)
+
+		var cancelled *dbs.CancelledError
+		if errors.As(err, &cancelled) {
+			if cancelled.Timeout() {
+				return nil, status.Error(codes.DeadlineExceeded, err.Error())
+			}
+			return nil, status.Error(codes.Canceled, err.Error())
+		}
+
+		return res, err
+	}
+}
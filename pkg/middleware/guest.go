@@ -0,0 +1,42 @@
+package middleware
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+
+	"goshop/pkg/rbac"
+)
+
+const (
+	GuestTokenHeader   = "X-Guest-Token"
+	GuestTokenMetadata = "guest-token"
+)
+
+// JWTOrGuestAuth authenticates requests with an Authorization header like JWTAuth. Other requests
+// are served to the guest identified by the X-Guest-Token header, with the guest role. A new token
+// is issued when none or an invalid one was sent; the token is always returned in the header.
+func JWTOrGuestAuth() gin.HandlerFunc {
+	jwtAuth := JWTAuth()
+	return func(c *gin.Context) {
+		if c.GetHeader("Authorization") != "" {
+			jwtAuth(c)
+			return
+		}
+
+		token := guestToken(c.GetHeader(GuestTokenHeader))
+		c.Set("guestToken", token)
+		c.Set("role", rbac.RoleGuest)
+		c.Header(GuestTokenHeader, token)
+		c.Next()
+	}
+}
+
+// guestToken returns token when it is valid, a new token otherwise
+func guestToken(token string) string {
+	parsed, err := uuid.Parse(token)
+	if err != nil {
+		return uuid.New().String()
+	}
+
+	return parsed.String()
+}
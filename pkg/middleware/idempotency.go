@@ -0,0 +1,120 @@
+package middleware
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/quangdangfit/gocommon/logger"
+
+	"goshop/pkg/idempotency"
+	"goshop/pkg/response"
+)
+
+const (
+	IdempotencyKeyHeader      = "Idempotency-Key"
+	IdempotentReplayedHeader  = "Idempotent-Replayed"
+	maxIdempotencyKeyLength   = 255
+	idempotencyInProgressText = "A request with this Idempotency-Key is in progress"
+	idempotencyMismatchText   = "Idempotency-Key was used with a different request"
+)
+
+type idempotencyRecorder struct {
+	gin.ResponseWriter
+	body bytes.Buffer
+}
+
+func (w *idempotencyRecorder) Write(data []byte) (int, error) {
+	w.body.Write(data)
+	return w.ResponseWriter.Write(data)
+}
+
+func (w *idempotencyRecorder) WriteString(s string) (int, error) {
+	w.body.WriteString(s)
+	return w.ResponseWriter.WriteString(s)
+}
+
+// Idempotency makes requests carrying an Idempotency-Key header safe to retry. The response
+// of the first request with a key is stored and replayed to the requests repeating it; a key
+// sent again with another method, path or body is rejected. Responses with a 5xx status are
+// not stored, so that the request can be retried. Keys belong to the authenticated user,
+// so it must run after JWTAuth. Requests with safe methods are ignored.
+func Idempotency(store idempotency.Store) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		key := c.GetHeader(IdempotencyKeyHeader)
+		if key == "" || c.Request.Method == http.MethodGet || c.Request.Method == http.MethodHead || c.Request.Method == http.MethodOptions {
+			c.Next()
+			return
+		}
+
+		if len(key) > maxIdempotencyKeyLength {
+			response.Error(c, http.StatusBadRequest, errors.New("bad request"), "Invalid Idempotency-Key")
+			c.Abort()
+			return
+		}
+
+		body, err := io.ReadAll(c.Request.Body)
+		if err != nil {
+			response.Error(c, http.StatusBadRequest, err, "Invalid parameters")
+			c.Abort()
+			return
+		}
+		c.Request.Body = io.NopCloser(bytes.NewReader(body))
+
+		key = c.GetString("userId") + ":" + key
+		fingerprint := idempotency.Fingerprint([]byte(c.Request.Method), []byte(c.Request.URL.Path), body)
+
+		record, claimed, err := store.Begin(c, key, fingerprint)
+		if err != nil {
+			logger.Error("Failed to claim idempotency key: ", err)
+			response.Error(c, http.StatusInternalServerError, err, "Something went wrong")
+			c.Abort()
+			return
+		}
+
+		if !claimed {
+			switch {
+			case record.Fingerprint != fingerprint:
+				response.Error(c, http.StatusUnprocessableEntity, errors.New("idempotency key mismatch"), idempotencyMismatchText)
+			case !record.Completed:
+				response.Error(c, http.StatusConflict, errors.New("idempotency key in progress"), idempotencyInProgressText)
+			default:
+				c.Header(IdempotentReplayedHeader, "true")
+				c.Data(record.StatusCode, record.ContentType, record.Body)
+			}
+			c.Abort()
+			return
+		}
+
+		// The store is updated even when the client went away, the key would stay in progress otherwise
+		stored := false
+		defer func() {
+			if !stored {
+				if err := store.Release(context.Background(), key); err != nil {
+					logger.Error("Failed to release idempotency key: ", err)
+				}
+			}
+		}()
+
+		recorder := &idempotencyRecorder{ResponseWriter: c.Writer}
+		c.Writer = recorder
+		c.Next()
+
+		if recorder.Status() >= http.StatusInternalServerError {
+			return
+		}
+
+		record.Completed = true
+		record.StatusCode = recorder.Status()
+		record.ContentType = recorder.Header().Get("Content-Type")
+		record.Body = recorder.body.Bytes()
+		if err := store.Complete(context.Background(), key, record); err != nil {
+			logger.Error("Failed to store idempotent response: ", err)
+			return
+		}
+		stored = true
+	}
+}
@@ -0,0 +1,133 @@
+package middleware
+
+import (
+	"context"
+
+	"github.com/quangdangfit/gocommon/logger"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/anypb"
+
+	"goshop/pkg/idempotency"
+)
+
+const (
+	IdempotencyKeyMetadata     = "idempotency-key"
+	IdempotentReplayedMetadata = "idempotent-replayed"
+)
+
+// IdempotencyInterceptor does for gRPC what the Idempotency middleware does for HTTP,
+// reading the key from the idempotency-key metadata. Only successful responses are stored.
+// It must run after AuthInterceptor.
+type IdempotencyInterceptor struct {
+	store idempotency.Store
+}
+
+func NewIdempotencyInterceptor(store idempotency.Store) *IdempotencyInterceptor {
+	return &IdempotencyInterceptor{
+		store: store,
+	}
+}
+
+func (ii *IdempotencyInterceptor) Unary() grpc.UnaryServerInterceptor {
+	return func(
+		ctx context.Context,
+		req interface{},
+		info *grpc.UnaryServerInfo,
+		handler grpc.UnaryHandler,
+	) (interface{}, error) {
+		m, _ := metadata.FromIncomingContext(ctx)
+		message, ok := req.(proto.Message)
+		if len(m[IdempotencyKeyMetadata]) == 0 || m[IdempotencyKeyMetadata][0] == "" || !ok {
+			return handler(ctx, req)
+		}
+
+		key := m[IdempotencyKeyMetadata][0]
+		if len(key) > maxIdempotencyKeyLength {
+			return nil, status.New(codes.InvalidArgument, "invalid idempotency key").Err()
+		}
+
+		body, err := proto.MarshalOptions{Deterministic: true}.Marshal(message)
+		if err != nil {
+			return nil, status.New(codes.InvalidArgument, err.Error()).Err()
+		}
+
+		userID, _ := ctx.Value("userId").(string)
+		key = userID + ":" + key
+		fingerprint := idempotency.Fingerprint([]byte(info.FullMethod), body)
+
+		record, claimed, err := ii.store.Begin(ctx, key, fingerprint)
+		if err != nil {
+			logger.Error("Failed to claim idempotency key: ", err)
+			return nil, status.New(codes.Internal, "something went wrong").Err()
+		}
+
+		if !claimed {
+			switch {
+			case record.Fingerprint != fingerprint:
+				return nil, status.New(codes.InvalidArgument, "idempotency key was used with a different request").Err()
+			case !record.Completed:
+				return nil, status.New(codes.Aborted, "a request with this idempotency key is in progress").Err()
+			default:
+				return replay(ctx, record)
+			}
+		}
+
+		res, err := handler(ctx, req)
+		if err != nil {
+			if releaseErr := ii.store.Release(context.Background(), key); releaseErr != nil {
+				logger.Error("Failed to release idempotency key: ", releaseErr)
+			}
+			return res, err
+		}
+
+		if err := ii.complete(key, record, res); err != nil {
+			logger.Error("Failed to store idempotent response: ", err)
+			if releaseErr := ii.store.Release(context.Background(), key); releaseErr != nil {
+				logger.Error("Failed to release idempotency key: ", releaseErr)
+			}
+		}
+
+		return res, nil
+	}
+}
+
+func (ii *IdempotencyInterceptor) complete(key string, record *idempotency.Record, res interface{}) error {
+	message, ok := res.(proto.Message)
+	if !ok {
+		return status.New(codes.Internal, "response is not a protobuf message").Err()
+	}
+
+	// Any keeps the type of the response, needed to decode it on replay
+	packed, err := anypb.New(message)
+	if err != nil {
+		return err
+	}
+
+	body, err := proto.Marshal(packed)
+	if err != nil {
+		return err
+	}
+
+	record.Completed = true
+	record.Body = body
+	return ii.store.Complete(context.Background(), key, record)
+}
+
+func replay(ctx context.Context, record *idempotency.Record) (interface{}, error) {
+	var packed anypb.Any
+	if err := proto.Unmarshal(record.Body, &packed); err != nil {
+		return nil, status.New(codes.Internal, err.Error()).Err()
+	}
+
+	res, err := packed.UnmarshalNew()
+	if err != nil {
+		return nil, status.New(codes.Internal, err.Error()).Err()
+	}
+
+	_ = grpc.SetHeader(ctx, metadata.Pairs(IdempotentReplayedMetadata, "true"))
+	return res, nil
+}
@@ -0,0 +1,44 @@
+package middleware
+
+import (
+	"context"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"goshop/pkg/rbac"
+)
+
+// PermissionInterceptor checks the permission required by each method against the role
+// attached to the context by AuthInterceptor. Methods without a permission are not checked.
+type PermissionInterceptor struct {
+	methodPermissions map[string]string
+}
+
+func NewPermissionInterceptor(methodPermissions map[string]string) *PermissionInterceptor {
+	return &PermissionInterceptor{
+		methodPermissions: methodPermissions,
+	}
+}
+
+func (pi *PermissionInterceptor) Unary() grpc.UnaryServerInterceptor {
+	return func(
+		ctx context.Context,
+		req interface{},
+		info *grpc.UnaryServerInfo,
+		handler grpc.UnaryHandler,
+	) (interface{}, error) {
+		permission, ok := pi.methodPermissions[info.FullMethod]
+		if !ok {
+			return handler(ctx, req)
+		}
+
+		role, _ := ctx.Value("role").(string)
+		if !rbac.GetPolicy().Can(role, permission) {
+			return nil, status.New(codes.PermissionDenied, "permission denied").Err()
+		}
+
+		return handler(ctx, req)
+	}
+}
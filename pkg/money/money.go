@@ -0,0 +1,214 @@
+// Package money keeps amounts as integer minor units of an ISO 4217 currency, so that sums of
+// prices are exact.
+package money
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+var (
+	ErrCurrencyMismatch = errors.New("currency mismatch")
+	ErrUnknownCurrency  = errors.New("unknown currency")
+	ErrInvalidAmount    = errors.New("invalid amount")
+)
+
+// exponents are the number of minor unit digits of the supported currencies
+var exponents = map[string]int{
+	"AUD": 2,
+	"CAD": 2,
+	"CHF": 2,
+	"CNY": 2,
+	"EUR": 2,
+	"GBP": 2,
+	"HKD": 2,
+	"IDR": 2,
+	"INR": 2,
+	"JPY": 0,
+	"KRW": 0,
+	"KWD": 3,
+	"SGD": 2,
+	"THB": 2,
+	"USD": 2,
+	"VND": 0,
+}
+
+// Money is an amount of a currency. It is stored as an amount column of minor units and a
+// currency column, embed it with a prefix naming them, like gorm:"embedded;embeddedPrefix:price_".
+// In JSON the amount is a decimal string in major units: {"amount": "12.34", "currency": "USD"}.
+type Money struct {
+	// Amount is in minor units of the currency, cents for USD
+	Amount   int64  `gorm:"column:amount;type:numeric(19,0)" validate:"gte=0"`
+	Currency string `gorm:"column:currency"`
+}
+
+// New returns amount minor units of currency
+func New(amount int64, currency string) Money {
+	return Money{Amount: amount, Currency: currency}
+}
+
+// Parse reads a decimal amount in major units of currency, like "12.34" USD
+func Parse(amount, currency string) (Money, error) {
+	exponent, err := Exponent(currency)
+	if err != nil {
+		return Money{}, err
+	}
+
+	text := strings.TrimSpace(amount)
+	negative := strings.HasPrefix(text, "-")
+	text = strings.TrimPrefix(text, "-")
+
+	units, fraction, _ := strings.Cut(text, ".")
+	if units == "" || len(fraction) > exponent {
+		return Money{}, fmt.Errorf("%w: %q in %s", ErrInvalidAmount, amount, currency)
+	}
+	fraction += strings.Repeat("0", exponent-len(fraction))
+
+	minor, err := strconv.ParseInt(units+fraction, 10, 64)
+	if err != nil {
+		return Money{}, fmt.Errorf("%w: %q in %s", ErrInvalidAmount, amount, currency)
+	}
+	if negative {
+		minor = -minor
+	}
+
+	return New(minor, currency), nil
+}
+
+// Exponent returns the number of minor unit digits of currency
+func Exponent(currency string) (int, error) {
+	exponent, ok := exponents[currency]
+	if !ok {
+		return 0, fmt.Errorf("%w: %q", ErrUnknownCurrency, currency)
+	}
+	return exponent, nil
+}
+
+// IsZero reports whether m is no money at all, whatever its currency
+func (m Money) IsZero() bool {
+	return m.Amount == 0
+}
+
+func (m Money) IsNegative() bool {
+	return m.Amount < 0
+}
+
+// Mul returns m times n, the price of n items priced m
+func (m Money) Mul(n int64) Money {
+	return New(m.Amount*n, m.Currency)
+}
+
+// Add returns m + other. A zero amount without currency takes the currency of the other operand.
+func (m Money) Add(other Money) (Money, error) {
+	currency, err := m.currencyWith(other)
+	if err != nil {
+		return Money{}, err
+	}
+	return New(m.Amount+other.Amount, currency), nil
+}
+
+// Sub returns m - other, following the currency rules of Add
+func (m Money) Sub(other Money) (Money, error) {
+	currency, err := m.currencyWith(other)
+	if err != nil {
+		return Money{}, err
+	}
+	return New(m.Amount-other.Amount, currency), nil
+}
+
+// Cmp compares m and other, returning -1, 0 or +1 like strings.Compare
+func (m Money) Cmp(other Money) (int, error) {
+	if _, err := m.currencyWith(other); err != nil {
+		return 0, err
+	}
+
+	switch {
+	case m.Amount < other.Amount:
+		return -1, nil
+	case m.Amount > other.Amount:
+		return 1, nil
+	default:
+		return 0, nil
+	}
+}
+
+func (m Money) currencyWith(other Money) (string, error) {
+	switch {
+	case m.Currency == other.Currency:
+		return m.Currency, nil
+	case m.Currency == "" && m.IsZero():
+		return other.Currency, nil
+	case other.Currency == "" && other.IsZero():
+		return m.Currency, nil
+	default:
+		return "", fmt.Errorf("%w: %s and %s", ErrCurrencyMismatch, m.Currency, other.Currency)
+	}
+}
+
+// Sum adds amounts, all in the same currency. The sum of nothing is a zero Money without currency.
+func Sum(amounts ...Money) (Money, error) {
+	var total Money
+	for _, amount := range amounts {
+		var err error
+		if total, err = total.Add(amount); err != nil {
+			return Money{}, err
+		}
+	}
+	return total, nil
+}
+
+// Decimal returns the amount in major units, like "12.34"
+func (m Money) Decimal() string {
+	exponent, err := Exponent(m.Currency)
+	if err != nil || exponent == 0 {
+		return strconv.FormatInt(m.Amount, 10)
+	}
+
+	sign := ""
+	amount := m.Amount
+	if amount < 0 {
+		sign = "-"
+		amount = -amount
+	}
+
+	digits := fmt.Sprintf("%0*d", exponent+1, amount)
+	return sign + digits[:len(digits)-exponent] + "." + digits[len(digits)-exponent:]
+}
+
+func (m Money) String() string {
+	return strings.TrimSpace(m.Decimal() + " " + m.Currency)
+}
+
+type jsonMoney struct {
+	Amount   string `json:"amount"`
+	Currency string `json:"currency"`
+}
+
+func (m Money) MarshalJSON() ([]byte, error) {
+	return json.Marshal(jsonMoney{Amount: m.Decimal(), Currency: m.Currency})
+}
+
+func (m *Money) UnmarshalJSON(data []byte) error {
+	var value jsonMoney
+	if err := json.Unmarshal(data, &value); err != nil {
+		return err
+	}
+
+	// The zero Money has no currency
+	if value.Currency == "" {
+		if amount, err := strconv.ParseInt(value.Amount, 10, 64); err == nil && amount == 0 {
+			*m = Money{}
+			return nil
+		}
+	}
+
+	parsed, err := Parse(value.Amount, value.Currency)
+	if err != nil {
+		return err
+	}
+	*m = parsed
+	return nil
+}
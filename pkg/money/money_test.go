@@ -0,0 +1,89 @@
+package money
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParse(t *testing.T) {
+	m, err := Parse("12.34", "USD")
+	assert.Nil(t, err)
+	assert.Equal(t, New(1234, "USD"), m)
+
+	m, err = Parse("12.5", "USD")
+	assert.Nil(t, err)
+	assert.Equal(t, int64(1250), m.Amount)
+
+	m, err = Parse("-0.05", "EUR")
+	assert.Nil(t, err)
+	assert.Equal(t, int64(-5), m.Amount)
+
+	m, err = Parse("15000", "VND")
+	assert.Nil(t, err)
+	assert.Equal(t, int64(15000), m.Amount)
+
+	_, err = Parse("12.345", "USD")
+	assert.ErrorIs(t, err, ErrInvalidAmount)
+
+	_, err = Parse("1.5", "JPY")
+	assert.ErrorIs(t, err, ErrInvalidAmount)
+
+	_, err = Parse("abc", "USD")
+	assert.ErrorIs(t, err, ErrInvalidAmount)
+
+	_, err = Parse("1", "XXX")
+	assert.ErrorIs(t, err, ErrUnknownCurrency)
+}
+
+func TestDecimal(t *testing.T) {
+	assert.Equal(t, "12.34", New(1234, "USD").Decimal())
+	assert.Equal(t, "0.05", New(5, "USD").Decimal())
+	assert.Equal(t, "-0.05", New(-5, "USD").Decimal())
+	assert.Equal(t, "1.005", New(1005, "KWD").Decimal())
+	assert.Equal(t, "15000", New(15000, "VND").Decimal())
+	assert.Equal(t, "12.34 USD", New(1234, "USD").String())
+}
+
+func TestArithmetic(t *testing.T) {
+	total, err := Sum(New(10, "USD"), New(20, "USD"), New(1, "USD").Mul(3))
+	assert.Nil(t, err)
+	assert.Equal(t, New(33, "USD"), total)
+
+	total, err = Sum()
+	assert.Nil(t, err)
+	assert.True(t, total.IsZero())
+
+	_, err = Sum(New(10, "USD"), New(10, "EUR"))
+	assert.ErrorIs(t, err, ErrCurrencyMismatch)
+
+	left, err := New(100, "USD").Sub(New(30, "USD"))
+	assert.Nil(t, err)
+	assert.Equal(t, New(70, "USD"), left)
+
+	cmp, err := New(100, "USD").Cmp(New(30, "USD"))
+	assert.Nil(t, err)
+	assert.Equal(t, 1, cmp)
+
+	cmp, err = Money{}.Cmp(New(30, "USD"))
+	assert.Nil(t, err)
+	assert.Equal(t, -1, cmp)
+}
+
+func TestJSON(t *testing.T) {
+	data, err := json.Marshal(New(1050, "USD"))
+	assert.Nil(t, err)
+	assert.JSONEq(t, `{"amount": "10.50", "currency": "USD"}`, string(data))
+
+	var m Money
+	assert.Nil(t, json.Unmarshal(data, &m))
+	assert.Equal(t, New(1050, "USD"), m)
+
+	data, _ = json.Marshal(Money{})
+	assert.Nil(t, json.Unmarshal(data, &m))
+	assert.Equal(t, Money{}, m)
+
+	assert.NotNil(t, json.Unmarshal([]byte(`{"amount": "10.50"}`), &m))
+	assert.NotNil(t, json.Unmarshal([]byte(`10.5`), &m))
+}
@@ -0,0 +1,63 @@
+package paging
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"strings"
+
+	"goshop/pkg/config"
+)
+
+var ErrInvalidCursor = errors.New("invalid cursor")
+
+// Cursor is the position of a row in a list sorted by Sort, the row having the sort key Key
+// and the ID. Before reads the page before the row instead of the page after it.
+type Cursor struct {
+	Sort   string `json:"s"`
+	Key    string `json:"k"`
+	ID     string `json:"i"`
+	Before bool   `json:"b,omitempty"`
+}
+
+// EncodeCursor returns the opaque token of cursor, signed so that clients cannot forge positions
+func EncodeCursor(cursor Cursor) string {
+	payload, _ := json.Marshal(cursor)
+	encoded := base64.RawURLEncoding.EncodeToString(payload)
+	return encoded + "." + sign(encoded)
+}
+
+// DecodeCursor returns the cursor of token, failing with ErrInvalidCursor when it is not signed
+// or was issued for a list sorted otherwise than by sort
+func DecodeCursor(token, sort string) (*Cursor, error) {
+	encoded, signature, ok := strings.Cut(token, ".")
+	if !ok || !hmac.Equal([]byte(signature), []byte(sign(encoded))) {
+		return nil, ErrInvalidCursor
+	}
+
+	payload, err := base64.RawURLEncoding.DecodeString(encoded)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+
+	var cursor Cursor
+	if err := json.Unmarshal(payload, &cursor); err != nil || cursor.ID == "" || cursor.Sort != sort {
+		return nil, ErrInvalidCursor
+	}
+
+	return &cursor, nil
+}
+
+func sign(encoded string) string {
+	cfg := config.GetConfig()
+	secret := cfg.CursorSecret
+	if secret == "" {
+		secret = cfg.AuthSecret
+	}
+
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(encoded))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
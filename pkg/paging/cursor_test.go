@@ -0,0 +1,86 @@
+package paging
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCursorRoundTrip(t *testing.T) {
+	token := EncodeCursor(Cursor{Sort: "price desc", Key: "1200", ID: "p1", Before: true})
+
+	cursor, err := DecodeCursor(token, "price desc")
+	assert.Nil(t, err)
+	assert.Equal(t, &Cursor{Sort: "price desc", Key: "1200", ID: "p1", Before: true}, cursor)
+}
+
+func TestDecodeCursorOtherSort(t *testing.T) {
+	token := EncodeCursor(Cursor{Sort: "created_at", Key: "2024-01-02T00:00:00Z", ID: "p1"})
+
+	cursor, err := DecodeCursor(token, "name")
+	assert.ErrorIs(t, err, ErrInvalidCursor)
+	assert.Nil(t, cursor)
+}
+
+func TestDecodeCursorForged(t *testing.T) {
+	token := EncodeCursor(Cursor{Sort: "name", Key: "a", ID: "p1"})
+	encoded, signature, _ := strings.Cut(token, ".")
+	forged := EncodeCursor(Cursor{Sort: "name", Key: "z", ID: "p9"})
+	forgedEncoded, _, _ := strings.Cut(forged, ".")
+
+	for _, token := range []string{"", "abc", encoded, forgedEncoded + "." + signature, "!." + signature} {
+		cursor, err := DecodeCursor(token, "name")
+		assert.ErrorIs(t, err, ErrInvalidCursor)
+		assert.Nil(t, cursor)
+	}
+}
+
+func key(n int) (string, string) {
+	return string(rune('a' + n)), string(rune('A' + n))
+}
+
+func TestPageByOffset(t *testing.T) {
+	p := New(2, 2, 5)
+	rows := Page(p, []int{2, 3, 4}, "name", nil, key)
+	assert.Equal(t, []int{2, 3}, rows)
+
+	next, err := DecodeCursor(p.NextCursor, "name")
+	assert.Nil(t, err)
+	assert.Equal(t, &Cursor{Sort: "name", Key: "d", ID: "D"}, next)
+
+	prev, err := DecodeCursor(p.PrevCursor, "name")
+	assert.Nil(t, err)
+	assert.Equal(t, &Cursor{Sort: "name", Key: "c", ID: "C", Before: true}, prev)
+}
+
+func TestPageFirstAndLast(t *testing.T) {
+	p := New(1, 2, 2)
+	rows := Page(p, []int{0, 1}, "name", nil, key)
+	assert.Equal(t, []int{0, 1}, rows)
+	assert.Empty(t, p.NextCursor)
+	assert.Empty(t, p.PrevCursor)
+}
+
+func TestPageAfterCursor(t *testing.T) {
+	p := NewCursor(2, 5)
+	rows := Page(p, []int{3, 4}, "name", &Cursor{Sort: "name", Key: "c", ID: "C"}, key)
+	assert.Equal(t, []int{3, 4}, rows)
+	assert.Equal(t, int64(0), p.CurrentPage)
+	assert.Empty(t, p.NextCursor)
+	assert.NotEmpty(t, p.PrevCursor)
+}
+
+func TestPageBeforeCursor(t *testing.T) {
+	p := NewCursor(2, 5)
+	rows := Page(p, []int{2, 1, 0}, "name", &Cursor{Sort: "name", Key: "d", ID: "D", Before: true}, key)
+	assert.Equal(t, []int{1, 2}, rows)
+
+	next, err := DecodeCursor(p.NextCursor, "name")
+	assert.Nil(t, err)
+	assert.Equal(t, "c", next.Key)
+	prev, err := DecodeCursor(p.PrevCursor, "name")
+	assert.Nil(t, err)
+	assert.Equal(t, "b", prev.Key)
+	assert.True(t, prev.Before)
+}
@@ -15,6 +15,9 @@ type Pagination struct {
 	TotalPage   int64 `json:"total_page"`
 	Limit       int64 `json:"limit"`
 	Skip        int64 `json:"skip"`
+	// NextCursor and PrevCursor are the cursors of the pages after and before this one
+	NextCursor string `json:"next_cursor,omitempty"`
+	PrevCursor string `json:"prev_cursor,omitempty"`
 }
 
 // New paging object
@@ -38,3 +41,47 @@ func New(page int64, pageSize int64, total int64) *Pagination {
 	pageInfo.Skip = (page - 1) * pageInfo.Limit
 	return &pageInfo
 }
+
+// NewCursor paging object, for pages read from a cursor. They hold pageSize rows like the
+// pages of New but have no number.
+func NewCursor(pageSize int64, total int64) *Pagination {
+	pageInfo := New(1, pageSize, total)
+	pageInfo.CurrentPage = 0
+	return pageInfo
+}
+
+// Page trims rows, read with a limit one greater than the page size to tell whether more rows
+// follow, to the page and sets the cursors of the pages around it. cursor is the position the
+// rows were read from, nil for pages read by offset, and key returns the sort key and ID of a row.
+func Page[T any](p *Pagination, rows []T, sort string, cursor *Cursor, key func(T) (string, string)) []T {
+	more := int64(len(rows)) > p.Limit
+	if more {
+		rows = rows[:p.Limit]
+	}
+
+	hasNext, hasPrev := more, p.Skip > 0
+	if cursor != nil {
+		hasNext, hasPrev = more, true
+		if cursor.Before {
+			// The rows before the cursor are read backwards
+			for i, j := 0, len(rows)-1; i < j; i, j = i+1, j-1 {
+				rows[i], rows[j] = rows[j], rows[i]
+			}
+			hasNext, hasPrev = true, more
+		}
+	}
+	if len(rows) == 0 {
+		return rows
+	}
+
+	if hasNext {
+		k, id := key(rows[len(rows)-1])
+		p.NextCursor = EncodeCursor(Cursor{Sort: sort, Key: k, ID: id})
+	}
+	if hasPrev {
+		k, id := key(rows[0])
+		p.PrevCursor = EncodeCursor(Cursor{Sort: sort, Key: k, ID: id, Before: true})
+	}
+
+	return rows
+}
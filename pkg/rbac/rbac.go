@@ -0,0 +1,116 @@
+package rbac
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"sync/atomic"
+)
+
+// Permissions are written as <resource>:<action>. "<resource>:*" grants every action
+// on the resource and "*" grants everything.
+const (
+	PermissionProductWrite   = "product:write"
+	PermissionStockRead      = "stock:read"
+	PermissionStockWrite     = "stock:write"
+	PermissionOrderRead      = "order:read"
+	PermissionOrderWrite     = "order:write"
+	PermissionOrderManage    = "order:manage"
+	PermissionPaymentRead    = "payment:read"
+	PermissionPaymentWrite   = "payment:write"
+	PermissionPaymentManage  = "payment:manage"
+	PermissionCartRead       = "cart:read"
+	PermissionCartWrite      = "cart:write"
+	PermissionUserRead       = "user:read"
+	PermissionUserWrite      = "user:write"
+	PermissionUserManage     = "user:manage"
+	PermissionPromotionRead  = "promotion:read"
+	PermissionPromotionWrite = "promotion:write"
+	PermissionCategoryWrite  = "category:write"
+)
+
+// RoleGuest is the role of anonymous shoppers, identified by a guest token instead of a user
+const RoleGuest = "guest"
+
+// DefaultPolicy is used when no policy is configured
+const DefaultPolicy = "admin=*;" +
+	"staff=product:write,category:write,stock:*,order:*,payment:*,cart:*,user:*,promotion:*;" +
+	"customer=order:read,order:write,payment:read,payment:write,cart:*,user:read,user:write;" +
+	"guest=cart:*"
+
+const wildcard = "*"
+
+var permissionRegex = regexp.MustCompile(`^(\*|[a-z_]+:(\*|[a-z_]+))$`)
+
+var current atomic.Pointer[Policy]
+
+// Policy maps roles to the permissions they are granted
+type Policy struct {
+	roles map[string][]string
+}
+
+// ParsePolicy parses a policy written as role=permission,permission;role=permission
+func ParsePolicy(text string) (*Policy, error) {
+	policy := &Policy{roles: make(map[string][]string)}
+	for _, entry := range strings.Split(text, ";") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+
+		role, permissions, ok := strings.Cut(entry, "=")
+		role = strings.TrimSpace(role)
+		if !ok || role == "" {
+			return nil, fmt.Errorf("invalid policy entry: %q", entry)
+		}
+		if _, ok := policy.roles[role]; ok {
+			return nil, fmt.Errorf("duplicate policy role: %s", role)
+		}
+
+		policy.roles[role] = []string{}
+		for _, permission := range strings.Split(permissions, ",") {
+			permission = strings.TrimSpace(permission)
+			if permission == "" {
+				continue
+			}
+			if !permissionRegex.MatchString(permission) {
+				return nil, fmt.Errorf("invalid permission %q of role %s", permission, role)
+			}
+			policy.roles[role] = append(policy.roles[role], permission)
+		}
+	}
+
+	if len(policy.roles) == 0 {
+		return nil, fmt.Errorf("policy defines no roles")
+	}
+
+	return policy, nil
+}
+
+// Can reports whether role is granted permission
+func (p *Policy) Can(role, permission string) bool {
+	resource, _, _ := strings.Cut(permission, ":")
+	for _, granted := range p.roles[role] {
+		if granted == wildcard || granted == permission || granted == resource+":"+wildcard {
+			return true
+		}
+	}
+
+	return false
+}
+
+// SetPolicy replaces the policy returned by GetPolicy
+func SetPolicy(policy *Policy) {
+	current.Store(policy)
+}
+
+// GetPolicy returns the policy set by SetPolicy, or DefaultPolicy when none was set
+func GetPolicy() *Policy {
+	if policy := current.Load(); policy != nil {
+		return policy
+	}
+
+	policy, _ := ParsePolicy(DefaultPolicy)
+	current.CompareAndSwap(nil, policy)
+	return current.Load()
+}
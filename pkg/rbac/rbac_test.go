@@ -0,0 +1,55 @@
+package rbac
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParsePolicy(t *testing.T) {
+	policy, err := ParsePolicy(" admin = * ; support=order:read, user:* ;guest=")
+	assert.Nil(t, err)
+
+	assert.True(t, policy.Can("admin", PermissionProductWrite))
+	assert.True(t, policy.Can("support", PermissionOrderRead))
+	assert.True(t, policy.Can("support", PermissionUserWrite))
+	assert.False(t, policy.Can("support", PermissionOrderWrite))
+	assert.False(t, policy.Can("guest", PermissionCartRead))
+	assert.False(t, policy.Can("unknown", PermissionCartRead))
+}
+
+func TestParsePolicyInvalid(t *testing.T) {
+	for _, text := range []string{
+		"",
+		"admin",
+		"=product:write",
+		"admin=*;admin=product:write",
+		"admin=product",
+		"admin=Product:Write",
+	} {
+		_, err := ParsePolicy(text)
+		assert.NotNil(t, err, text)
+	}
+}
+
+func TestDefaultPolicy(t *testing.T) {
+	policy := GetPolicy()
+	assert.True(t, policy.Can("admin", PermissionOrderManage))
+	assert.True(t, policy.Can("staff", PermissionStockWrite))
+	assert.True(t, policy.Can("customer", PermissionCartWrite))
+	assert.False(t, policy.Can("customer", PermissionProductWrite))
+	assert.False(t, policy.Can("customer", PermissionStockWrite))
+	assert.False(t, policy.Can("customer", PermissionOrderManage))
+	assert.True(t, policy.Can("customer", PermissionPaymentWrite))
+	assert.False(t, policy.Can("customer", PermissionPaymentManage))
+	assert.True(t, policy.Can(RoleGuest, PermissionCartWrite))
+	assert.False(t, policy.Can(RoleGuest, PermissionOrderWrite))
+}
+
+func TestSetPolicy(t *testing.T) {
+	defer SetPolicy(nil)
+
+	policy, _ := ParsePolicy("customer=product:write")
+	SetPolicy(policy)
+	assert.True(t, GetPolicy().Can("customer", PermissionProductWrite))
+}
@@ -27,6 +27,50 @@ func (_m *IRedis) Get(key string, value interface{}) error {
 	return r0
 }
 
+// Incr provides a mock function with given fields: key, expiration
+func (_m *IRedis) Incr(key string, expiration time.Duration) (int64, error) {
+	ret := _m.Called(key, expiration)
+
+	var r0 int64
+	var r1 error
+	if rf, ok := ret.Get(0).(func(string, time.Duration) (int64, error)); ok {
+		return rf(key, expiration)
+	}
+	if rf, ok := ret.Get(0).(func(string, time.Duration) int64); ok {
+		r0 = rf(key, expiration)
+	} else {
+		r0 = ret.Get(0).(int64)
+	}
+
+	if rf, ok := ret.Get(1).(func(string, time.Duration) error); ok {
+		r1 = rf(key, expiration)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// InvalidateTags provides a mock function with given fields: tags
+func (_m *IRedis) InvalidateTags(tags ...string) error {
+	_va := make([]interface{}, len(tags))
+	for _i := range tags {
+		_va[_i] = tags[_i]
+	}
+	var _ca []interface{}
+	_ca = append(_ca, _va...)
+	ret := _m.Called(_ca...)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(...string) error); ok {
+		r0 = rf(tags...)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // IsConnected provides a mock function with given fields:
 func (_m *IRedis) IsConnected() bool {
 	ret := _m.Called()
@@ -115,6 +159,30 @@ func (_m *IRedis) Set(key string, value interface{}) error {
 	return r0
 }
 
+// SetNX provides a mock function with given fields: key, value, expiration
+func (_m *IRedis) SetNX(key string, value interface{}, expiration time.Duration) (bool, error) {
+	ret := _m.Called(key, value, expiration)
+
+	var r0 bool
+	var r1 error
+	if rf, ok := ret.Get(0).(func(string, interface{}, time.Duration) (bool, error)); ok {
+		return rf(key, value, expiration)
+	}
+	if rf, ok := ret.Get(0).(func(string, interface{}, time.Duration) bool); ok {
+		r0 = rf(key, value, expiration)
+	} else {
+		r0 = ret.Get(0).(bool)
+	}
+
+	if rf, ok := ret.Get(1).(func(string, interface{}, time.Duration) error); ok {
+		r1 = rf(key, value, expiration)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // SetWithExpiration provides a mock function with given fields: key, value, expiration
 func (_m *IRedis) SetWithExpiration(key string, value interface{}, expiration time.Duration) error {
 	ret := _m.Called(key, value, expiration)
@@ -129,6 +197,51 @@ func (_m *IRedis) SetWithExpiration(key string, value interface{}, expiration ti
 	return r0
 }
 
+// SetWithTags provides a mock function with given fields: key, value, expiration, tags
+func (_m *IRedis) SetWithTags(key string, value interface{}, expiration time.Duration, tags ...string) error {
+	_va := make([]interface{}, len(tags))
+	for _i := range tags {
+		_va[_i] = tags[_i]
+	}
+	var _ca []interface{}
+	_ca = append(_ca, key, value, expiration)
+	_ca = append(_ca, _va...)
+	ret := _m.Called(_ca...)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(string, interface{}, time.Duration, ...string) error); ok {
+		r0 = rf(key, value, expiration, tags...)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// TTL provides a mock function with given fields: key
+func (_m *IRedis) TTL(key string) (time.Duration, error) {
+	ret := _m.Called(key)
+
+	var r0 time.Duration
+	var r1 error
+	if rf, ok := ret.Get(0).(func(string) (time.Duration, error)); ok {
+		return rf(key)
+	}
+	if rf, ok := ret.Get(0).(func(string) time.Duration); ok {
+		r0 = rf(key)
+	} else {
+		r0 = ret.Get(0).(time.Duration)
+	}
+
+	if rf, ok := ret.Get(1).(func(string) error); ok {
+		r1 = rf(key)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // NewIRedis creates a new instance of IRedis. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
 // The first argument is typically a *testing.T value.
 func NewIRedis(t interface {
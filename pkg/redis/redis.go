@@ -12,8 +12,16 @@ import (
 
 const (
 	Timeout = 1
+
+	// tagKeyPrefix prefixes the sets of keys tracked for each tag
+	tagKeyPrefix = "tag:"
+	// scanCount is the number of keys examined by each SCAN of RemovePattern and Keys
+	scanCount = 1000
 )
 
+// ErrNotFound is returned by Get when key does not exist
+var ErrNotFound = goredis.Nil
+
 // IRedis interface
 //
 //go:generate mockery --name=IRedis
@@ -22,7 +30,12 @@ type IRedis interface {
 	Get(key string, value interface{}) error
 	Set(key string, value interface{}) error
 	SetWithExpiration(key string, value interface{}, expiration time.Duration) error
+	SetNX(key string, value interface{}, expiration time.Duration) (bool, error)
+	SetWithTags(key string, value interface{}, expiration time.Duration, tags ...string) error
+	Incr(key string, expiration time.Duration) (int64, error)
+	TTL(key string) (time.Duration, error)
 	Remove(keys ...string) error
+	InvalidateTags(tags ...string) error
 	Keys(pattern string) ([]string, error)
 	RemovePattern(pattern string) error
 }
@@ -105,6 +118,15 @@ func (r *redis) SetWithExpiration(key string, value interface{}, expiration time
 	return nil
 }
 
+// SetNX sets key only if it does not exist yet and reports whether it was set
+func (r *redis) SetNX(key string, value interface{}, expiration time.Duration) (bool, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), Timeout*time.Second)
+	defer cancel()
+
+	bData, _ := json.Marshal(value)
+	return r.cmd.SetNX(ctx, key, bData, expiration).Result()
+}
+
 func (r *redis) Set(key string, value interface{}) error {
 	ctx, cancel := context.WithTimeout(context.Background(), Timeout*time.Second)
 	defer cancel()
@@ -118,6 +140,41 @@ func (r *redis) Set(key string, value interface{}) error {
 	return nil
 }
 
+// Incr increments the counter of key and returns its value, counting from 0 when key does not
+// exist. The counter expires after expiration, renewed by each increment.
+func (r *redis) Incr(key string, expiration time.Duration) (int64, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), Timeout*time.Second)
+	defer cancel()
+
+	var incr *goredis.IntCmd
+	_, err := r.cmd.TxPipelined(ctx, func(pipe goredis.Pipeliner) error {
+		incr = pipe.Incr(ctx, key)
+		pipe.Expire(ctx, key, expiration)
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	return incr.Val(), nil
+}
+
+// TTL returns how long key lives before expiring, 0 when it does not exist or never expires
+func (r *redis) TTL(key string) (time.Duration, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), Timeout*time.Second)
+	defer cancel()
+
+	ttl, err := r.cmd.TTL(ctx, key).Result()
+	if err != nil {
+		return 0, err
+	}
+	if ttl < 0 {
+		return 0, nil
+	}
+
+	return ttl, nil
+}
+
 func (r *redis) Remove(keys ...string) error {
 	ctx, cancel := context.WithTimeout(context.Background(), Timeout*time.Second)
 	defer cancel()
@@ -130,32 +187,100 @@ func (r *redis) Remove(keys ...string) error {
 	return nil
 }
 
-func (r *redis) Keys(pattern string) ([]string, error) {
+// SetWithTags is SetWithExpiration also tracking key in the sets of tags, for InvalidateTags to
+// remove it. A set expires with the last key added to it, so the keys of a tag should share
+// their expiration.
+func (r *redis) SetWithTags(key string, value interface{}, expiration time.Duration, tags ...string) error {
 	ctx, cancel := context.WithTimeout(context.Background(), Timeout*time.Second)
 	defer cancel()
 
-	keys, err := r.cmd.Keys(ctx, pattern).Result()
-	if err != nil {
-		return nil, err
-	}
+	bData, _ := json.Marshal(value)
+	_, err := r.cmd.TxPipelined(ctx, func(pipe goredis.Pipeliner) error {
+		pipe.Set(ctx, key, bData, expiration)
+		for _, tag := range tags {
+			pipe.SAdd(ctx, tagKeyPrefix+tag, key)
+			if expiration > 0 {
+				pipe.Expire(ctx, tagKeyPrefix+tag, expiration)
+			}
+		}
+		return nil
+	})
 
-	return keys, nil
+	return err
 }
 
-func (r *redis) RemovePattern(pattern string) error {
-	keys, err := r.Keys(pattern)
+// InvalidateTags removes the keys set with any of tags, touching no other key. Keys tagged
+// while it runs are tracked in new sets and kept.
+func (r *redis) InvalidateTags(tags ...string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), Timeout*time.Second)
+	defer cancel()
+
+	members := make([]*goredis.StringSliceCmd, 0, len(tags))
+	_, err := r.cmd.TxPipelined(ctx, func(pipe goredis.Pipeliner) error {
+		for _, tag := range tags {
+			members = append(members, pipe.SMembers(ctx, tagKeyPrefix+tag))
+			pipe.Del(ctx, tagKeyPrefix+tag)
+		}
+		return nil
+	})
 	if err != nil {
 		return err
 	}
 
+	var keys []string
+	for _, cmd := range members {
+		keys = append(keys, cmd.Val()...)
+	}
 	if len(keys) == 0 {
 		return nil
 	}
 
-	err = r.Remove(keys...)
+	return r.cmd.Del(ctx, keys...).Err()
+}
+
+// Keys returns the keys matching pattern, scanning the keyspace in batches instead of
+// blocking redis with KEYS
+func (r *redis) Keys(pattern string) ([]string, error) {
+	var keys []string
+	err := r.scan(pattern, func(batch []string) error {
+		keys = append(keys, batch...)
+		return nil
+	})
 	if err != nil {
-		return err
+		return nil, err
 	}
 
-	return nil
+	return keys, nil
+}
+
+// RemovePattern removes the keys matching pattern, batch by batch as the keyspace is scanned.
+// It examines every key, prefer InvalidateTags for keys that can be tagged.
+func (r *redis) RemovePattern(pattern string) error {
+	return r.scan(pattern, func(keys []string) error {
+		return r.Remove(keys...)
+	})
+}
+
+// scan calls fn with the keys matching pattern, a batch at a time
+func (r *redis) scan(pattern string, fn func(keys []string) error) error {
+	var cursor uint64
+	for {
+		ctx, cancel := context.WithTimeout(context.Background(), Timeout*time.Second)
+		keys, next, err := r.cmd.Scan(ctx, cursor, pattern, scanCount).Result()
+		cancel()
+		if err != nil {
+			return err
+		}
+
+		if len(keys) > 0 {
+			if err := fn(keys); err != nil {
+				return err
+			}
+		}
+
+		cursor = next
+		if cursor == 0 {
+			return nil
+		}
+	}
 }
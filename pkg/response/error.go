@@ -1,16 +1,41 @@
 package response
 
 import (
+	"errors"
+	"net/http"
+
 	"github.com/gin-gonic/gin"
 
 	"goshop/pkg/config"
+	"goshop/pkg/dbs"
 )
 
+// StatusClientClosedRequest is returned when the client went away before the request completed
+const StatusClientClosedRequest = 499
+
 func Error(c *gin.Context, status int, err error, message string) {
+	ErrorWithDetails(c, status, err, message, nil)
+}
+
+// ErrorWithDetails is like Error, with details telling the client what to fix
+func ErrorWithDetails(c *gin.Context, status int, err error, message string, details interface{}) {
 	cfg := config.GetConfig()
+
+	var cancelled *dbs.CancelledError
+	if errors.As(err, &cancelled) {
+		if cancelled.Timeout() {
+			status, message = http.StatusGatewayTimeout, "Request timeout"
+		} else {
+			status, message = StatusClientClosedRequest, "Request cancelled"
+		}
+	}
+
 	errorRes := map[string]interface{}{
 		"message": message,
 	}
+	if details != nil {
+		errorRes["details"] = details
+	}
 
 	if cfg.Environment != config.ProductionEnv {
 		errorRes["debug"] = err.Error()
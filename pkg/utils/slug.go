@@ -0,0 +1,14 @@
+package utils
+
+import (
+	"strings"
+	"unicode"
+)
+
+// Slugify returns text as a lowercase URL path segment, words joined by dashes
+func Slugify(text string) string {
+	words := strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	return strings.Join(words, "-")
+}
@@ -25,6 +25,7 @@ type CartServiceClient interface {
 	AddProduct(ctx context.Context, in *AddProductReq, opts ...grpc.CallOption) (*AddProductRes, error)
 	RemoveProduct(ctx context.Context, in *RemoveProductReq, opts ...grpc.CallOption) (*RemoveProductRes, error)
 	GetCart(ctx context.Context, in *GetCartReq, opts ...grpc.CallOption) (*GetCartRes, error)
+	Checkout(ctx context.Context, in *CheckoutReq, opts ...grpc.CallOption) (*CheckoutRes, error)
 }
 
 type cartServiceClient struct {
@@ -62,6 +63,15 @@ func (c *cartServiceClient) GetCart(ctx context.Context, in *GetCartReq, opts ..
 	return out, nil
 }
 
+func (c *cartServiceClient) Checkout(ctx context.Context, in *CheckoutReq, opts ...grpc.CallOption) (*CheckoutRes, error) {
+	out := new(CheckoutRes)
+	err := c.cc.Invoke(ctx, "/cart.CartService/Checkout", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // CartServiceServer is the server API for CartService service.
 // All implementations must embed UnimplementedCartServiceServer
 // for forward compatibility
@@ -69,6 +79,7 @@ type CartServiceServer interface {
 	AddProduct(context.Context, *AddProductReq) (*AddProductRes, error)
 	RemoveProduct(context.Context, *RemoveProductReq) (*RemoveProductRes, error)
 	GetCart(context.Context, *GetCartReq) (*GetCartRes, error)
+	Checkout(context.Context, *CheckoutReq) (*CheckoutRes, error)
 	mustEmbedUnimplementedCartServiceServer()
 }
 
@@ -85,6 +96,9 @@ func (UnimplementedCartServiceServer) RemoveProduct(context.Context, *RemoveProd
 func (UnimplementedCartServiceServer) GetCart(context.Context, *GetCartReq) (*GetCartRes, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method GetCart not implemented")
 }
+func (UnimplementedCartServiceServer) Checkout(context.Context, *CheckoutReq) (*CheckoutRes, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Checkout not implemented")
+}
 func (UnimplementedCartServiceServer) mustEmbedUnimplementedCartServiceServer() {}
 
 // UnsafeCartServiceServer may be embedded to opt out of forward compatibility for this service.
@@ -152,6 +166,24 @@ func _CartService_GetCart_Handler(srv interface{}, ctx context.Context, dec func
 	return interceptor(ctx, in, info, handler)
 }
 
+func _CartService_Checkout_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CheckoutReq)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CartServiceServer).Checkout(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/cart.CartService/Checkout",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CartServiceServer).Checkout(ctx, req.(*CheckoutReq))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 // CartService_ServiceDesc is the grpc.ServiceDesc for CartService service.
 // It's only intended for direct use with grpc.RegisterService,
 // and not to be introspected or modified (even as a copy)
@@ -171,6 +203,10 @@ var CartService_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "GetCart",
 			Handler:    _CartService_GetCart_Handler,
 		},
+		{
+			MethodName: "Checkout",
+			Handler:    _CartService_Checkout_Handler,
+		},
 	},
 	Streams:  []grpc.StreamDesc{},
 	Metadata: "cart/cart.proto",
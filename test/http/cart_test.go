@@ -0,0 +1,233 @@
+package http
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"goshop/internal/cart/dto"
+	orderDto "goshop/internal/order/dto"
+	orderModel "goshop/internal/order/model"
+	productModel "goshop/internal/product/model"
+	userDto "goshop/internal/user/dto"
+	"goshop/pkg/middleware"
+	"goshop/pkg/money"
+)
+
+func createCartProduct(t *testing.T) productModel.Product {
+	p := productModel.Product{
+		Name:        "test-product-1",
+		Description: "test-product-1",
+		Price:       money.New(100, "USD"),
+	}
+	assert.Nil(t, dbTest.Create(context.Background(), &p))
+	return p
+}
+
+func TestCartAPI_GetCartEmpty(t *testing.T) {
+	defer cleanData()
+
+	writer := makeRequest("GET", "/api/v1/cart", nil, accessToken())
+	var res dto.Cart
+	parseResponseResult(writer.Body.Bytes(), &res)
+	assert.Equal(t, http.StatusOK, writer.Code)
+	assert.NotEmpty(t, res.ID)
+	assert.Equal(t, 0, len(res.Lines))
+}
+
+func TestCartAPI_GetCartGuest(t *testing.T) {
+	writer := makeRequest("GET", "/api/v1/cart", nil, "")
+	var res dto.Cart
+	parseResponseResult(writer.Body.Bytes(), &res)
+	assert.Equal(t, http.StatusOK, writer.Code)
+	assert.NotEmpty(t, writer.Header().Get(middleware.GuestTokenHeader))
+	assert.Equal(t, 0, len(res.Lines))
+}
+
+func TestCartAPI_GuestCartMergedOnLogin(t *testing.T) {
+	defer cleanData()
+
+	token := accessToken()
+	p := createCartProduct(t)
+	writer := makeRequest("POST", "/api/v1/cart/lines", &dto.CartLineReq{ProductID: p.ID, Quantity: 1}, token)
+	assert.Equal(t, http.StatusOK, writer.Code)
+
+	writer = makeRequest("POST", "/api/v1/cart/lines", &dto.CartLineReq{ProductID: p.ID, Quantity: 2}, "")
+	assert.Equal(t, http.StatusOK, writer.Code)
+	guestToken := writer.Header().Get(middleware.GuestTokenHeader)
+	assert.NotEmpty(t, guestToken)
+
+	headers := map[string]string{middleware.GuestTokenHeader: guestToken}
+	writer = makeRequestWithHeaders("GET", "/api/v1/cart", nil, "", headers)
+	var res dto.Cart
+	parseResponseResult(writer.Body.Bytes(), &res)
+	assert.Equal(t, 1, len(res.Lines))
+	assert.Equal(t, uint(2), res.Lines[0].Quantity)
+
+	login := userDto.LoginReq{Email: "test@test.com", Password: "test123456"}
+	writer = makeRequestWithHeaders("POST", "/api/v1/auth/login", login, "", headers)
+	assert.Equal(t, http.StatusOK, writer.Code)
+
+	writer = makeRequest("GET", "/api/v1/cart", nil, token)
+	res = dto.Cart{}
+	parseResponseResult(writer.Body.Bytes(), &res)
+	assert.Equal(t, 1, len(res.Lines))
+	assert.Equal(t, uint(3), res.Lines[0].Quantity)
+
+	writer = makeRequestWithHeaders("GET", "/api/v1/cart", nil, "", headers)
+	res = dto.Cart{}
+	parseResponseResult(writer.Body.Bytes(), &res)
+	assert.Equal(t, 0, len(res.Lines))
+}
+
+func TestCartAPI_AddUpdateRemoveProduct(t *testing.T) {
+	defer cleanData()
+
+	token := accessToken()
+	p := createCartProduct(t)
+
+	writer := makeRequest("POST", "/api/v1/cart/lines", &dto.CartLineReq{ProductID: p.ID, Quantity: 2}, token)
+	var res dto.Cart
+	parseResponseResult(writer.Body.Bytes(), &res)
+	assert.Equal(t, http.StatusOK, writer.Code)
+	assert.Equal(t, 1, len(res.Lines))
+	assert.Equal(t, uint(2), res.Lines[0].Quantity)
+
+	writer = makeRequest("PUT", "/api/v1/cart/lines/"+p.ID, &dto.UpdateQuantityReq{Quantity: 5}, token)
+	res = dto.Cart{}
+	parseResponseResult(writer.Body.Bytes(), &res)
+	assert.Equal(t, http.StatusOK, writer.Code)
+	assert.Equal(t, uint(5), res.Lines[0].Quantity)
+
+	writer = makeRequest("GET", "/api/v1/cart", nil, token)
+	res = dto.Cart{}
+	parseResponseResult(writer.Body.Bytes(), &res)
+	assert.Equal(t, http.StatusOK, writer.Code)
+	assert.Equal(t, uint(5), res.Lines[0].Quantity)
+
+	writer = makeRequest("DELETE", "/api/v1/cart/lines/"+p.ID, nil, token)
+	res = dto.Cart{}
+	parseResponseResult(writer.Body.Bytes(), &res)
+	assert.Equal(t, http.StatusOK, writer.Code)
+	assert.Equal(t, 0, len(res.Lines))
+}
+
+func TestCartAPI_UpdateQuantityNotInCart(t *testing.T) {
+	defer cleanData()
+
+	writer := makeRequest("PUT", "/api/v1/cart/lines/notfound", &dto.UpdateQuantityReq{Quantity: 5}, accessToken())
+	assert.Equal(t, http.StatusNotFound, writer.Code)
+}
+
+func TestCartAPI_CheckoutSuccess(t *testing.T) {
+	defer cleanData()
+
+	token := accessToken()
+	p := productModel.Product{
+		Name:        "test-product-1",
+		Description: "test-product-1",
+		Price:       money.New(200, "USD"),
+		Stock:       &productModel.ProductStock{OnHand: 10},
+	}
+	dbTest.Create(context.Background(), &p)
+
+	writer := makeRequest("POST", "/api/v1/cart/lines", &dto.CartLineReq{ProductID: p.ID, Quantity: 3}, token)
+	assert.Equal(t, http.StatusOK, writer.Code)
+
+	// The order is priced at the current price of the product
+	dbTest.GetDB().Model(&productModel.Product{}).Where("id = ?", p.ID).Update("price_amount", 500)
+
+	writer = makeRequest("POST", "/api/v1/cart/checkout", nil, token)
+	var order orderDto.Order
+	parseResponseResult(writer.Body.Bytes(), &order)
+	assert.Equal(t, http.StatusOK, writer.Code)
+	assert.Equal(t, "new", order.Status)
+	assert.Equal(t, money.New(1500, "USD"), order.TotalPrice)
+	assert.Equal(t, 1, len(order.Lines))
+
+	var stock productModel.ProductStock
+	dbTest.GetDB().Where("product_id = ?", p.ID).First(&stock)
+	assert.Equal(t, int64(3), stock.Reserved)
+
+	writer = makeRequest("GET", "/api/v1/cart", nil, token)
+	var cart dto.Cart
+	parseResponseResult(writer.Body.Bytes(), &cart)
+	assert.Equal(t, 0, len(cart.Lines))
+}
+
+func TestCartAPI_CheckoutIdempotent(t *testing.T) {
+	defer cleanData()
+
+	token := accessToken()
+	p := productModel.Product{
+		Name:        "test-product-1",
+		Description: "test-product-1",
+		Price:       money.New(200, "USD"),
+		Stock:       &productModel.ProductStock{OnHand: 10},
+	}
+	dbTest.Create(context.Background(), &p)
+
+	writer := makeRequest("POST", "/api/v1/cart/lines", &dto.CartLineReq{ProductID: p.ID, Quantity: 3}, token)
+	assert.Equal(t, http.StatusOK, writer.Code)
+
+	headers := map[string]string{"Idempotency-Key": "checkout-1"}
+	writer = makeRequestWithHeaders("POST", "/api/v1/cart/checkout", nil, token, headers)
+	var first orderDto.Order
+	parseResponseResult(writer.Body.Bytes(), &first)
+	assert.Equal(t, http.StatusOK, writer.Code)
+
+	// The cart is empty now, the retry gets the order placed by the first checkout
+	writer = makeRequestWithHeaders("POST", "/api/v1/cart/checkout", nil, token, headers)
+	var second orderDto.Order
+	parseResponseResult(writer.Body.Bytes(), &second)
+	assert.Equal(t, http.StatusOK, writer.Code)
+	assert.Equal(t, "true", writer.Header().Get("Idempotent-Replayed"))
+	assert.Equal(t, first.ID, second.ID)
+
+	var count int64
+	dbTest.GetDB().Model(&orderModel.Order{}).Count(&count)
+	assert.Equal(t, int64(1), count)
+}
+
+func TestCartAPI_CheckoutEmptyCart(t *testing.T) {
+	defer cleanData()
+
+	writer := makeRequest("POST", "/api/v1/cart/checkout", nil, accessToken())
+	assert.Equal(t, http.StatusBadRequest, writer.Code)
+}
+
+func TestCartAPI_CheckoutUnavailableProduct(t *testing.T) {
+	defer cleanData()
+
+	token := accessToken()
+	p := productModel.Product{
+		Name:        "test-product-1",
+		Description: "test-product-1",
+		Price:       money.New(200, "USD"),
+		Stock:       &productModel.ProductStock{OnHand: 1},
+	}
+	dbTest.Create(context.Background(), &p)
+
+	writer := makeRequest("POST", "/api/v1/cart/lines", &dto.CartLineReq{ProductID: p.ID, Quantity: 3}, token)
+	assert.Equal(t, http.StatusOK, writer.Code)
+
+	writer = makeRequest("POST", "/api/v1/cart/checkout", nil, token)
+	var response map[string]struct {
+		Details []map[string]string `json:"details"`
+	}
+	_ = json.Unmarshal(writer.Body.Bytes(), &response)
+	assert.Equal(t, http.StatusBadRequest, writer.Code)
+	assert.Equal(t, []map[string]string{{"product_id": p.ID, "reason": "insufficient stock"}}, response["error"].Details)
+
+	var count int64
+	dbTest.GetDB().Model(&orderModel.Order{}).Count(&count)
+	assert.Equal(t, int64(0), count)
+
+	writer = makeRequest("GET", "/api/v1/cart", nil, token)
+	var cart dto.Cart
+	parseResponseResult(writer.Body.Bytes(), &cart)
+	assert.Equal(t, 1, len(cart.Lines))
+}
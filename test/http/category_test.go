@@ -0,0 +1,143 @@
+package http
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"goshop/internal/product/dto"
+	productModel "goshop/internal/product/model"
+	"goshop/pkg/money"
+)
+
+func createCategory(t *testing.T, req *dto.CreateCategoryReq) *dto.Category {
+	writer := makeRequest("POST", "/api/v1/categories", req, adminToken())
+	assert.Equal(t, http.StatusOK, writer.Code)
+
+	var res dto.Category
+	parseResponseResult(writer.Body.Bytes(), &res)
+	return &res
+}
+
+// Create Category
+// =================================================================================================
+
+func TestCategoryAPI_CreateCategoryTree(t *testing.T) {
+	defer cleanData()
+
+	shoes := createCategory(t, &dto.CreateCategoryReq{Name: "Shoes"})
+	assert.Equal(t, "shoes", shoes.Slug)
+	boots := createCategory(t, &dto.CreateCategoryReq{Name: "Hiking Boots", ParentID: &shoes.ID})
+	assert.Equal(t, "hiking-boots", boots.Slug)
+
+	writer := makeRequest("GET", "/api/v1/categories", nil, "")
+	var res dto.ListCategoryRes
+	parseResponseResult(writer.Body.Bytes(), &res)
+	assert.Equal(t, http.StatusOK, writer.Code)
+	assert.Equal(t, 1, len(res.Categories))
+	assert.Equal(t, 1, len(res.Categories[0].Children))
+	assert.Equal(t, boots.ID, res.Categories[0].Children[0].ID)
+
+	writer = makeRequest("GET", "/api/v1/categories/hiking-boots", nil, "")
+	assert.Equal(t, http.StatusOK, writer.Code)
+}
+
+func TestCategoryAPI_CreateCategorySlugTaken(t *testing.T) {
+	defer cleanData()
+
+	createCategory(t, &dto.CreateCategoryReq{Name: "Shoes"})
+
+	writer := makeRequest("POST", "/api/v1/categories", &dto.CreateCategoryReq{Name: "Other", Slug: "shoes"}, adminToken())
+	assert.Equal(t, http.StatusBadRequest, writer.Code)
+}
+
+func TestCategoryAPI_CreateCategoryForbidden(t *testing.T) {
+	writer := makeRequest("POST", "/api/v1/categories", &dto.CreateCategoryReq{Name: "Shoes"}, accessToken())
+	assert.Equal(t, http.StatusForbidden, writer.Code)
+}
+
+// Update Category
+// =================================================================================================
+
+func TestCategoryAPI_UpdateCategoryCycle(t *testing.T) {
+	defer cleanData()
+
+	shoes := createCategory(t, &dto.CreateCategoryReq{Name: "Shoes"})
+	boots := createCategory(t, &dto.CreateCategoryReq{Name: "Boots", ParentID: &shoes.ID})
+
+	req := &dto.UpdateCategoryReq{ParentID: &boots.ID}
+	writer := makeRequest("PUT", fmt.Sprintf("/api/v1/categories/%s", shoes.ID), req, adminToken())
+	assert.Equal(t, http.StatusBadRequest, writer.Code)
+}
+
+// Delete Category
+// =================================================================================================
+
+func TestCategoryAPI_DeleteCategoryWithChildren(t *testing.T) {
+	defer cleanData()
+
+	shoes := createCategory(t, &dto.CreateCategoryReq{Name: "Shoes"})
+	boots := createCategory(t, &dto.CreateCategoryReq{Name: "Boots", ParentID: &shoes.ID})
+
+	writer := makeRequest("DELETE", fmt.Sprintf("/api/v1/categories/%s", shoes.ID), nil, adminToken())
+	assert.Equal(t, http.StatusBadRequest, writer.Code)
+
+	writer = makeRequest("DELETE", fmt.Sprintf("/api/v1/categories/%s", boots.ID), nil, adminToken())
+	assert.Equal(t, http.StatusOK, writer.Code)
+}
+
+// List Category Products
+// =================================================================================================
+
+func TestCategoryAPI_ListCategoryProductsIncludesSubcategories(t *testing.T) {
+	defer cleanData()
+
+	shoes := createCategory(t, &dto.CreateCategoryReq{Name: "Shoes"})
+	boots := createCategory(t, &dto.CreateCategoryReq{Name: "Boots", ParentID: &shoes.ID})
+
+	p1 := productModel.Product{
+		Name:        "test-product-1",
+		Description: "test-product-1",
+		Price:       money.New(1000, "USD"),
+	}
+	dbTest.Create(context.Background(), &p1)
+
+	req := &dto.SetProductCategoriesReq{CategoryIDs: []string{boots.ID}}
+	writer := makeRequest("PUT", fmt.Sprintf("/api/v1/products/%s/categories", p1.ID), req, adminToken())
+	var product dto.Product
+	parseResponseResult(writer.Body.Bytes(), &product)
+	assert.Equal(t, http.StatusOK, writer.Code)
+	assert.Equal(t, 1, len(product.Categories))
+
+	writer = makeRequest("GET", "/api/v1/categories/shoes/products", nil, "")
+	var res dto.ListProductRes
+	parseResponseResult(writer.Body.Bytes(), &res)
+	assert.Equal(t, http.StatusOK, writer.Code)
+	assert.Equal(t, 1, len(res.Products))
+
+	writer = makeRequest("GET", "/api/v1/products?category=shoes", nil, "")
+	parseResponseResult(writer.Body.Bytes(), &res)
+	assert.Equal(t, http.StatusOK, writer.Code)
+	assert.Equal(t, 0, len(res.Products))
+
+	writer = makeRequest("GET", "/api/v1/categories/unknown/products", nil, "")
+	assert.Equal(t, http.StatusNotFound, writer.Code)
+}
+
+func TestCategoryAPI_SetProductCategoriesUnknownCategory(t *testing.T) {
+	defer cleanData()
+
+	p1 := productModel.Product{
+		Name:        "test-product-1",
+		Description: "test-product-1",
+		Price:       money.New(1000, "USD"),
+	}
+	dbTest.Create(context.Background(), &p1)
+
+	req := &dto.SetProductCategoriesReq{CategoryIDs: []string{"unknown"}}
+	writer := makeRequest("PUT", fmt.Sprintf("/api/v1/products/%s/categories", p1.ID), req, adminToken())
+	assert.Equal(t, http.StatusBadRequest, writer.Code)
+}
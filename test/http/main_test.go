@@ -7,29 +7,63 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"os"
+	"sync"
 	"testing"
 
 	"github.com/gin-gonic/gin"
 	"github.com/quangdangfit/gocommon/logger"
 	"github.com/quangdangfit/gocommon/validation"
 
+	cartModel "goshop/internal/cart/model"
 	orderModel "goshop/internal/order/model"
+	paymentModel "goshop/internal/payment/model"
 	productModel "goshop/internal/product/model"
+	promotionModel "goshop/internal/promotion/model"
 	httpServer "goshop/internal/server/http"
 	"goshop/internal/user/dto"
 	userModel "goshop/internal/user/model"
 	"goshop/pkg/config"
 	"goshop/pkg/dbs"
+	"goshop/pkg/idempotency"
+	"goshop/pkg/jtoken"
+	"goshop/pkg/mailer"
 	"goshop/pkg/redis"
 	"goshop/pkg/utils"
 )
 
 var (
 	testRouter *gin.Engine
-	dbTest     dbs.IDatabase
+	dbTest     *dbs.Database
+	migrator   *dbs.Migrator
 	testCache  redis.IRedis
+	testMail   = &testMailer{}
 )
 
+// testMailer keeps the emails sent during the tests instead of sending them
+type testMailer struct {
+	mu       sync.Mutex
+	messages []*mailer.Message
+}
+
+func (m *testMailer) Send(_ context.Context, msg *mailer.Message) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.messages = append(m.messages, msg)
+	return nil
+}
+
+// last returns the last email sent to to, nil when there is none
+func (m *testMailer) last(to string) *mailer.Message {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for i := len(m.messages) - 1; i >= 0; i-- {
+		if m.messages[i].To == to {
+			return m.messages[i]
+		}
+	}
+	return nil
+}
+
 func TestMain(m *testing.M) {
 	gin.SetMode(gin.TestMode)
 	setup()
@@ -42,15 +76,31 @@ func TestMain(m *testing.M) {
 func setup() {
 	cfg := config.LoadConfig()
 	logger.Initialize(config.ProductionEnv)
+	if cfg.PaymentWebhookSecret == "" {
+		cfg.PaymentWebhookSecret = "payment_webhook_secret"
+	}
 
 	var err error
-	dbTest, err = dbs.NewDatabase(cfg.DatabaseURI)
+	dbTest, err = dbs.NewDatabase(dbs.Config{
+		URI:          cfg.DatabaseURI,
+		ReadTimeout:  cfg.DatabaseReadTimeout,
+		WriteTimeout: cfg.DatabaseWriteTimeout,
+	})
 	if err != nil {
 		logger.Fatal("Cannot connect to database", err)
 	}
 
-	err = dbTest.AutoMigrate(&userModel.User{}, &productModel.Product{}, orderModel.Order{}, orderModel.OrderLine{})
+	sqlDB, err := dbTest.GetDB().DB()
 	if err != nil {
+		logger.Fatal("Cannot connect to database", err)
+	}
+
+	migrator, err = dbs.NewMigrator(sqlDB, dbs.Migrations())
+	if err != nil {
+		logger.Fatal("Cannot load migrations", err)
+	}
+
+	if _, err = migrator.Up(context.Background()); err != nil {
 		logger.Fatal("Database migration fail", err)
 	}
 
@@ -61,8 +111,10 @@ func setup() {
 		Database: cfg.RedisDB,
 	})
 
-	server := httpServer.NewServer(validator, dbTest, testCache)
-	_ = server.MapRoutes()
+	server := httpServer.NewServer(validator, dbTest, testCache, testMail)
+	if err := server.MapRoutes(); err != nil {
+		logger.Fatal("Cannot map routes", err)
+	}
 	testRouter = server.GetEngine()
 
 	dbTest.Create(context.Background(), &userModel.User{
@@ -72,16 +124,23 @@ func setup() {
 }
 
 func teardown() {
-	migrator := dbTest.GetDB().Migrator()
-	migrator.DropTable(&userModel.User{}, &productModel.Product{}, &orderModel.Order{}, &orderModel.OrderLine{})
+	statuses, _ := migrator.Status(context.Background())
+	_, _ = migrator.Down(context.Background(), len(statuses))
 }
 
 func makeRequest(method, url string, body interface{}, token string) *httptest.ResponseRecorder {
+	return makeRequestWithHeaders(method, url, body, token, nil)
+}
+
+func makeRequestWithHeaders(method, url string, body interface{}, token string, headers map[string]string) *httptest.ResponseRecorder {
 	requestBody, _ := json.Marshal(body)
 	request, _ := http.NewRequest(method, url, bytes.NewBuffer(requestBody))
 	if token != "" {
 		request.Header.Add("Authorization", "Bearer "+token)
 	}
+	for key, value := range headers {
+		request.Header.Add(key, value)
+	}
 	writer := httptest.NewRecorder()
 	testRouter.ServeHTTP(writer, request)
 	return writer
@@ -99,6 +158,10 @@ func accessToken() string {
 	return response["result"]["access_token"]
 }
 
+func adminToken() string {
+	return jtoken.GenerateAccessToken(map[string]interface{}{"id": "admin", "role": "admin"})
+}
+
 func refreshToken() string {
 	user := dto.LoginReq{
 		Email:    "test@test.com",
@@ -118,9 +181,20 @@ func parseResponseResult(resData []byte, result interface{}) {
 }
 
 func cleanData(records ...interface{}) {
+	// The stock ledger rejects DELETE, only TRUNCATE clears it
+	dbTest.GetDB().Exec("TRUNCATE stock_movements")
+	dbTest.GetDB().Where("1 = 1").Delete(&cartModel.CartLine{})
+	dbTest.GetDB().Where("1 = 1").Delete(&cartModel.Cart{})
+	dbTest.GetDB().Where("1 = 1").Delete(&paymentModel.Payment{})
 	dbTest.GetDB().Where("1 = 1").Delete(&orderModel.OrderLine{})
 	dbTest.GetDB().Where("1 = 1").Delete(&productModel.Product{})
+	// Categories reference their parent, TRUNCATE removes the whole tree at once
+	dbTest.GetDB().Exec("TRUNCATE categories CASCADE")
 	dbTest.GetDB().Where("1 = 1").Delete(&orderModel.Order{})
+	dbTest.GetDB().Where("1 = 1").Delete(&promotionModel.Promotion{})
+	dbTest.GetDB().Where("1 = 1").Delete(&idempotency.IdempotencyKey{})
+	dbTest.GetDB().Where("1 = 1").Delete(&userModel.RefreshToken{})
+	dbTest.GetDB().Where("1 = 1").Delete(&userModel.UserToken{})
 
 	for _, record := range records {
 		dbTest.Delete(context.Background(), record)
@@ -14,6 +14,7 @@ import (
 	productModel "goshop/internal/product/model"
 	userModel "goshop/internal/user/model"
 	"goshop/pkg/jtoken"
+	"goshop/pkg/money"
 )
 
 // Place Order
@@ -25,14 +26,16 @@ func TestOrderAPI_PlaceOrderSuccess(t *testing.T) {
 	p1 := productModel.Product{
 		Name:        "test-product-1",
 		Description: "test-product-1",
-		Price:       1,
+		Price:       money.New(100, "USD"),
+		Stock:       &productModel.ProductStock{OnHand: 10},
 	}
 	dbTest.Create(context.Background(), &p1)
 
 	p2 := productModel.Product{
 		Name:        "test-product-2",
 		Description: "test-product-2",
-		Price:       2,
+		Price:       money.New(200, "USD"),
+		Stock:       &productModel.ProductStock{OnHand: 10},
 	}
 	dbTest.Create(context.Background(), &p2)
 
@@ -53,15 +56,108 @@ func TestOrderAPI_PlaceOrderSuccess(t *testing.T) {
 	parseResponseResult(writer.Body.Bytes(), &res)
 	assert.Equal(t, http.StatusOK, writer.Code)
 	assert.Equal(t, "new", res.Status)
-	assert.Equal(t, float64(8), res.TotalPrice)
+	assert.Equal(t, money.New(800, "USD"), res.TotalPrice)
 	assert.Equal(t, 2, len(res.Lines))
 	assert.Equal(t, req.Lines[0].ProductID, res.Lines[0].Product.ID)
 	assert.Equal(t, req.Lines[0].Quantity, res.Lines[0].Quantity)
-	assert.Equal(t, float64(2), res.Lines[0].Price)
+	assert.Equal(t, money.New(200, "USD"), res.Lines[0].Price)
 
 	assert.Equal(t, req.Lines[1].ProductID, res.Lines[1].Product.ID)
 	assert.Equal(t, req.Lines[1].Quantity, res.Lines[1].Quantity)
-	assert.Equal(t, float64(6), res.Lines[1].Price)
+	assert.Equal(t, money.New(600, "USD"), res.Lines[1].Price)
+}
+
+func TestOrderAPI_PlaceOrderInsufficientStock(t *testing.T) {
+	defer cleanData()
+
+	p1 := productModel.Product{
+		Name:        "test-product-1",
+		Description: "test-product-1",
+		Price:       money.New(100, "USD"),
+		Stock:       &productModel.ProductStock{OnHand: 1},
+	}
+	dbTest.Create(context.Background(), &p1)
+
+	req := &dto.PlaceOrderReq{
+		Lines: []dto.PlaceOrderLineReq{
+			{
+				ProductID: p1.ID,
+				Quantity:  2,
+			},
+		},
+	}
+	writer := makeRequest("POST", "/api/v1/orders", req, accessToken())
+	assert.Equal(t, http.StatusBadRequest, writer.Code)
+
+	var stock productModel.ProductStock
+	dbTest.GetDB().Where("product_id = ?", p1.ID).First(&stock)
+	assert.Equal(t, int64(0), stock.Reserved)
+}
+
+func TestOrderAPI_PlaceOrderIdempotent(t *testing.T) {
+	defer cleanData()
+
+	p1 := productModel.Product{
+		Name:        "test-product-1",
+		Description: "test-product-1",
+		Price:       money.New(100, "USD"),
+		Stock:       &productModel.ProductStock{OnHand: 10},
+	}
+	dbTest.Create(context.Background(), &p1)
+
+	token := accessToken()
+	headers := map[string]string{"Idempotency-Key": "place-order-1"}
+	req := &dto.PlaceOrderReq{
+		Lines: []dto.PlaceOrderLineReq{{ProductID: p1.ID, Quantity: 2}},
+	}
+
+	writer := makeRequestWithHeaders("POST", "/api/v1/orders", req, token, headers)
+	var first dto.Order
+	parseResponseResult(writer.Body.Bytes(), &first)
+	assert.Equal(t, http.StatusOK, writer.Code)
+
+	writer = makeRequestWithHeaders("POST", "/api/v1/orders", req, token, headers)
+	var second dto.Order
+	parseResponseResult(writer.Body.Bytes(), &second)
+	assert.Equal(t, http.StatusOK, writer.Code)
+	assert.Equal(t, "true", writer.Header().Get("Idempotent-Replayed"))
+	assert.Equal(t, first.ID, second.ID)
+
+	var count int64
+	dbTest.GetDB().Model(&model.Order{}).Count(&count)
+	assert.Equal(t, int64(1), count)
+
+	var stock productModel.ProductStock
+	dbTest.GetDB().Where("product_id = ?", p1.ID).First(&stock)
+	assert.Equal(t, int64(2), stock.Reserved)
+}
+
+func TestOrderAPI_PlaceOrderIdempotencyKeyReused(t *testing.T) {
+	defer cleanData()
+
+	p1 := productModel.Product{
+		Name:        "test-product-1",
+		Description: "test-product-1",
+		Price:       money.New(100, "USD"),
+		Stock:       &productModel.ProductStock{OnHand: 10},
+	}
+	dbTest.Create(context.Background(), &p1)
+
+	token := accessToken()
+	headers := map[string]string{"Idempotency-Key": "place-order-2"}
+	req := &dto.PlaceOrderReq{
+		Lines: []dto.PlaceOrderLineReq{{ProductID: p1.ID, Quantity: 2}},
+	}
+
+	writer := makeRequestWithHeaders("POST", "/api/v1/orders", req, token, headers)
+	assert.Equal(t, http.StatusOK, writer.Code)
+
+	req.Lines[0].Quantity = 3
+	writer = makeRequestWithHeaders("POST", "/api/v1/orders", req, token, headers)
+	var response map[string]map[string]string
+	_ = json.Unmarshal(writer.Body.Bytes(), &response)
+	assert.Equal(t, http.StatusUnprocessableEntity, writer.Code)
+	assert.Equal(t, "Idempotency-Key was used with a different request", response["error"]["message"])
 }
 
 func TestOrderAPI_PlaceOrderInvalidFieldType(t *testing.T) {
@@ -70,14 +166,16 @@ func TestOrderAPI_PlaceOrderInvalidFieldType(t *testing.T) {
 	p1 := productModel.Product{
 		Name:        "test-product-1",
 		Description: "test-product-1",
-		Price:       1,
+		Price:       money.New(100, "USD"),
+		Stock:       &productModel.ProductStock{OnHand: 10},
 	}
 	dbTest.Create(context.Background(), &p1)
 
 	p2 := productModel.Product{
 		Name:        "test-product-2",
 		Description: "test-product-2",
-		Price:       2,
+		Price:       money.New(200, "USD"),
+		Stock:       &productModel.ProductStock{OnHand: 10},
 	}
 	dbTest.Create(context.Background(), &p2)
 
@@ -106,14 +204,16 @@ func TestOrderAPI_PlaceOrderInvalidMissProductID(t *testing.T) {
 	p1 := productModel.Product{
 		Name:        "test-product-1",
 		Description: "test-product-1",
-		Price:       1,
+		Price:       money.New(100, "USD"),
+		Stock:       &productModel.ProductStock{OnHand: 10},
 	}
 	dbTest.Create(context.Background(), &p1)
 
 	p2 := productModel.Product{
 		Name:        "test-product-2",
 		Description: "test-product-2",
-		Price:       2,
+		Price:       money.New(200, "USD"),
+		Stock:       &productModel.ProductStock{OnHand: 10},
 	}
 	dbTest.Create(context.Background(), &p2)
 
@@ -141,14 +241,16 @@ func TestOrderAPI_PlaceOrderInvalidMissQuantity(t *testing.T) {
 	p1 := productModel.Product{
 		Name:        "test-product-1",
 		Description: "test-product-1",
-		Price:       1,
+		Price:       money.New(100, "USD"),
+		Stock:       &productModel.ProductStock{OnHand: 10},
 	}
 	dbTest.Create(context.Background(), &p1)
 
 	p2 := productModel.Product{
 		Name:        "test-product-2",
 		Description: "test-product-2",
-		Price:       2,
+		Price:       money.New(200, "USD"),
+		Stock:       &productModel.ProductStock{OnHand: 10},
 	}
 	dbTest.Create(context.Background(), &p2)
 
@@ -176,14 +278,16 @@ func TestOrderAPI_PlaceOrderInvalidProductNotFound(t *testing.T) {
 	p1 := productModel.Product{
 		Name:        "test-product-1",
 		Description: "test-product-1",
-		Price:       1,
+		Price:       money.New(100, "USD"),
+		Stock:       &productModel.ProductStock{OnHand: 10},
 	}
 	dbTest.Create(context.Background(), &p1)
 
 	p2 := productModel.Product{
 		Name:        "test-product-2",
 		Description: "test-product-2",
-		Price:       2,
+		Price:       money.New(200, "USD"),
+		Stock:       &productModel.ProductStock{OnHand: 10},
 	}
 	dbTest.Create(context.Background(), &p2)
 
@@ -212,14 +316,16 @@ func TestOrderAPI_PlaceOrderUnauthorized(t *testing.T) {
 	p1 := productModel.Product{
 		Name:        "test-product-1",
 		Description: "test-product-1",
-		Price:       1,
+		Price:       money.New(100, "USD"),
+		Stock:       &productModel.ProductStock{OnHand: 10},
 	}
 	dbTest.Create(context.Background(), &p1)
 
 	p2 := productModel.Product{
 		Name:        "test-product-2",
 		Description: "test-product-2",
-		Price:       2,
+		Price:       money.New(200, "USD"),
+		Stock:       &productModel.ProductStock{OnHand: 10},
 	}
 	dbTest.Create(context.Background(), &p2)
 
@@ -249,20 +355,22 @@ func TestOrderAPI_GetOrderByIDSuccess(t *testing.T) {
 		Password: "test123456",
 	}
 	dbTest.Create(context.Background(), &u)
-	token := jtoken.GenerateAccessToken(map[string]interface{}{"id": u.ID})
+	token := jtoken.GenerateAccessToken(map[string]interface{}{"id": u.ID, "role": "customer"})
 	defer cleanData(&u)
 
 	p1 := productModel.Product{
 		Name:        "test-product-1",
 		Description: "test-product-1",
-		Price:       1,
+		Price:       money.New(100, "USD"),
+		Stock:       &productModel.ProductStock{OnHand: 10},
 	}
 	dbTest.Create(context.Background(), &p1)
 
 	p2 := productModel.Product{
 		Name:        "test-product-2",
 		Description: "test-product-2",
-		Price:       2,
+		Price:       money.New(200, "USD"),
+		Stock:       &productModel.ProductStock{OnHand: 10},
 	}
 	dbTest.Create(context.Background(), &p2)
 
@@ -311,20 +419,22 @@ func TestOrderAPI_CancelOrderSuccess(t *testing.T) {
 		Password: "test123456",
 	}
 	dbTest.Create(context.Background(), &u)
-	token := jtoken.GenerateAccessToken(map[string]interface{}{"id": u.ID})
+	token := jtoken.GenerateAccessToken(map[string]interface{}{"id": u.ID, "role": "customer"})
 	defer cleanData(&u)
 
 	p1 := productModel.Product{
 		Name:        "test-product-1",
 		Description: "test-product-1",
-		Price:       1,
+		Price:       money.New(100, "USD"),
+		Stock:       &productModel.ProductStock{OnHand: 10},
 	}
 	dbTest.Create(context.Background(), &p1)
 
 	p2 := productModel.Product{
 		Name:        "test-product-2",
 		Description: "test-product-2",
-		Price:       2,
+		Price:       money.New(200, "USD"),
+		Stock:       &productModel.ProductStock{OnHand: 10},
 	}
 	dbTest.Create(context.Background(), &p2)
 
@@ -361,20 +471,22 @@ func TestOrderAPI_CancelOrderStatusDone(t *testing.T) {
 		Password: "test123456",
 	}
 	dbTest.Create(context.Background(), &u)
-	token := jtoken.GenerateAccessToken(map[string]interface{}{"id": u.ID})
+	token := jtoken.GenerateAccessToken(map[string]interface{}{"id": u.ID, "role": "customer"})
 	defer cleanData(&u)
 
 	p1 := productModel.Product{
 		Name:        "test-product-1",
 		Description: "test-product-1",
-		Price:       1,
+		Price:       money.New(100, "USD"),
+		Stock:       &productModel.ProductStock{OnHand: 10},
 	}
 	dbTest.Create(context.Background(), &p1)
 
 	p2 := productModel.Product{
 		Name:        "test-product-2",
 		Description: "test-product-2",
-		Price:       2,
+		Price:       money.New(200, "USD"),
+		Stock:       &productModel.ProductStock{OnHand: 10},
 	}
 	dbTest.Create(context.Background(), &p2)
 
@@ -397,8 +509,8 @@ func TestOrderAPI_CancelOrderStatusDone(t *testing.T) {
 	writer := makeRequest("PUT", fmt.Sprintf("/api/v1/orders/%s/cancel", o.ID), nil, token)
 	var response map[string]map[string]string
 	_ = json.Unmarshal(writer.Body.Bytes(), &response)
-	assert.Equal(t, http.StatusInternalServerError, writer.Code)
-	assert.Equal(t, "Something went wrong", response["error"]["message"])
+	assert.Equal(t, http.StatusBadRequest, writer.Code)
+	assert.Equal(t, "invalid order status transition: done to cancelled", response["error"]["message"])
 }
 
 func TestOrderAPI_CancelOrderStatusCancelled(t *testing.T) {
@@ -407,20 +519,22 @@ func TestOrderAPI_CancelOrderStatusCancelled(t *testing.T) {
 		Password: "test123456",
 	}
 	dbTest.Create(context.Background(), &u)
-	token := jtoken.GenerateAccessToken(map[string]interface{}{"id": u.ID})
+	token := jtoken.GenerateAccessToken(map[string]interface{}{"id": u.ID, "role": "customer"})
 	defer cleanData(&u)
 
 	p1 := productModel.Product{
 		Name:        "test-product-1",
 		Description: "test-product-1",
-		Price:       1,
+		Price:       money.New(100, "USD"),
+		Stock:       &productModel.ProductStock{OnHand: 10},
 	}
 	dbTest.Create(context.Background(), &p1)
 
 	p2 := productModel.Product{
 		Name:        "test-product-2",
 		Description: "test-product-2",
-		Price:       2,
+		Price:       money.New(200, "USD"),
+		Stock:       &productModel.ProductStock{OnHand: 10},
 	}
 	dbTest.Create(context.Background(), &p2)
 
@@ -443,8 +557,8 @@ func TestOrderAPI_CancelOrderStatusCancelled(t *testing.T) {
 	writer := makeRequest("PUT", fmt.Sprintf("/api/v1/orders/%s/cancel", o.ID), nil, token)
 	var response map[string]map[string]string
 	_ = json.Unmarshal(writer.Body.Bytes(), &response)
-	assert.Equal(t, http.StatusInternalServerError, writer.Code)
-	assert.Equal(t, "Something went wrong", response["error"]["message"])
+	assert.Equal(t, http.StatusBadRequest, writer.Code)
+	assert.Equal(t, "invalid order status transition: cancelled to cancelled", response["error"]["message"])
 }
 
 func TestOrderAPI_CancelOrderNotMine(t *testing.T) {
@@ -458,14 +572,16 @@ func TestOrderAPI_CancelOrderNotMine(t *testing.T) {
 	p1 := productModel.Product{
 		Name:        "test-product-1",
 		Description: "test-product-1",
-		Price:       1,
+		Price:       money.New(100, "USD"),
+		Stock:       &productModel.ProductStock{OnHand: 10},
 	}
 	dbTest.Create(context.Background(), &p1)
 
 	p2 := productModel.Product{
 		Name:        "test-product-2",
 		Description: "test-product-2",
-		Price:       2,
+		Price:       money.New(200, "USD"),
+		Stock:       &productModel.ProductStock{OnHand: 10},
 	}
 	dbTest.Create(context.Background(), &p2)
 
@@ -495,26 +611,112 @@ func TestOrderAPI_CancelOrderNotMine(t *testing.T) {
 // List My Orders
 // =================================================================================================
 
+func placeOrder(t *testing.T, token string) dto.Order {
+	p := productModel.Product{
+		Name:        "test-product-1",
+		Description: "test-product-1",
+		Price:       money.New(100, "USD"),
+		Stock:       &productModel.ProductStock{OnHand: 10},
+	}
+	dbTest.Create(context.Background(), &p)
+
+	req := &dto.PlaceOrderReq{
+		Lines: []dto.PlaceOrderLineReq{
+			{
+				ProductID: p.ID,
+				Quantity:  2,
+			},
+		},
+	}
+	writer := makeRequest("POST", "/api/v1/orders", req, token)
+	var res dto.Order
+	parseResponseResult(writer.Body.Bytes(), &res)
+	assert.Equal(t, http.StatusOK, writer.Code)
+	return res
+}
+
+func TestOrderAPI_UpdateOrderStatusSuccess(t *testing.T) {
+	defer cleanData()
+
+	token := accessToken()
+	order := placeOrder(t, token)
+
+	req := &dto.UpdateOrderStatusReq{Status: "in-progress", Note: "packing"}
+	writer := makeRequest("PUT", fmt.Sprintf("/api/v1/orders/%s/status", order.ID), req, adminToken())
+	var res dto.Order
+	parseResponseResult(writer.Body.Bytes(), &res)
+	assert.Equal(t, http.StatusOK, writer.Code)
+	assert.Equal(t, "in-progress", res.Status)
+
+	writer = makeRequest("GET", fmt.Sprintf("/api/v1/orders/%s/history", order.ID), nil, token)
+	var history []*dto.OrderStatusHistory
+	parseResponseResult(writer.Body.Bytes(), &history)
+	assert.Equal(t, http.StatusOK, writer.Code)
+	assert.Equal(t, 2, len(history))
+	assert.Equal(t, "", history[0].FromStatus)
+	assert.Equal(t, "new", history[0].ToStatus)
+	assert.Equal(t, "new", history[1].FromStatus)
+	assert.Equal(t, "in-progress", history[1].ToStatus)
+	assert.Equal(t, "admin", history[1].ChangedBy)
+	assert.Equal(t, "packing", history[1].Note)
+}
+
+func TestOrderAPI_UpdateOrderStatusInvalidTransition(t *testing.T) {
+	defer cleanData()
+
+	order := placeOrder(t, accessToken())
+
+	req := &dto.UpdateOrderStatusReq{Status: "done"}
+	writer := makeRequest("PUT", fmt.Sprintf("/api/v1/orders/%s/status", order.ID), req, adminToken())
+	var response map[string]map[string]string
+	_ = json.Unmarshal(writer.Body.Bytes(), &response)
+	assert.Equal(t, http.StatusBadRequest, writer.Code)
+	assert.Equal(t, "invalid order status transition: new to done", response["error"]["message"])
+}
+
+func TestOrderAPI_UpdateOrderStatusForbidden(t *testing.T) {
+	defer cleanData()
+
+	token := accessToken()
+	order := placeOrder(t, token)
+
+	req := &dto.UpdateOrderStatusReq{Status: "in-progress"}
+	writer := makeRequest("PUT", fmt.Sprintf("/api/v1/orders/%s/status", order.ID), req, token)
+	assert.Equal(t, http.StatusForbidden, writer.Code)
+}
+
+func TestOrderAPI_GetOrderHistoryNotMine(t *testing.T) {
+	defer cleanData()
+
+	order := placeOrder(t, accessToken())
+
+	token := jtoken.GenerateAccessToken(map[string]interface{}{"id": "other", "role": "customer"})
+	writer := makeRequest("GET", fmt.Sprintf("/api/v1/orders/%s/history", order.ID), nil, token)
+	assert.Equal(t, http.StatusNotFound, writer.Code)
+}
+
 func TestOrderAPI_ListOrdersSuccess(t *testing.T) {
 	u := userModel.User{
 		Email:    "test1@test.com",
 		Password: "test123456",
 	}
 	dbTest.Create(context.Background(), &u)
-	token := jtoken.GenerateAccessToken(map[string]interface{}{"id": u.ID})
+	token := jtoken.GenerateAccessToken(map[string]interface{}{"id": u.ID, "role": "customer"})
 	defer cleanData(&u)
 
 	p1 := productModel.Product{
 		Name:        "test-product-1",
 		Description: "test-product-1",
-		Price:       1,
+		Price:       money.New(100, "USD"),
+		Stock:       &productModel.ProductStock{OnHand: 10},
 	}
 	dbTest.Create(context.Background(), &p1)
 
 	p2 := productModel.Product{
 		Name:        "test-product-2",
 		Description: "test-product-2",
-		Price:       2,
+		Price:       money.New(200, "USD"),
+		Stock:       &productModel.ProductStock{OnHand: 10},
 	}
 	dbTest.Create(context.Background(), &p2)
 
@@ -582,20 +784,22 @@ func TestOrderAPI_ListMyOrdersFindByStatusSuccess(t *testing.T) {
 		Password: "test123456",
 	}
 	dbTest.Create(context.Background(), &u)
-	token := jtoken.GenerateAccessToken(map[string]interface{}{"id": u.ID})
+	token := jtoken.GenerateAccessToken(map[string]interface{}{"id": u.ID, "role": "customer"})
 	defer cleanData(&u)
 
 	p1 := productModel.Product{
 		Name:        "test-product-1",
 		Description: "test-product-1",
-		Price:       1,
+		Price:       money.New(100, "USD"),
+		Stock:       &productModel.ProductStock{OnHand: 10},
 	}
 	dbTest.Create(context.Background(), &p1)
 
 	p2 := productModel.Product{
 		Name:        "test-product-2",
 		Description: "test-product-2",
-		Price:       2,
+		Price:       money.New(200, "USD"),
+		Stock:       &productModel.ProductStock{OnHand: 10},
 	}
 	dbTest.Create(context.Background(), &p2)
 
@@ -642,20 +846,22 @@ func TestOrderAPI_ListOrdersFindByStatusNotFound(t *testing.T) {
 		Password: "test123456",
 	}
 	dbTest.Create(context.Background(), &u)
-	token := jtoken.GenerateAccessToken(map[string]interface{}{"id": u.ID})
+	token := jtoken.GenerateAccessToken(map[string]interface{}{"id": u.ID, "role": "customer"})
 	defer cleanData(&u)
 
 	p1 := productModel.Product{
 		Name:        "test-product-1",
 		Description: "test-product-1",
-		Price:       1,
+		Price:       money.New(100, "USD"),
+		Stock:       &productModel.ProductStock{OnHand: 10},
 	}
 	dbTest.Create(context.Background(), &p1)
 
 	p2 := productModel.Product{
 		Name:        "test-product-2",
 		Description: "test-product-2",
-		Price:       2,
+		Price:       money.New(200, "USD"),
+		Stock:       &productModel.ProductStock{OnHand: 10},
 	}
 	dbTest.Create(context.Background(), &p2)
 
@@ -696,20 +902,22 @@ func TestOrderAPI_ListOrdersFindByCodeSuccess(t *testing.T) {
 		Password: "test123456",
 	}
 	dbTest.Create(context.Background(), &u)
-	token := jtoken.GenerateAccessToken(map[string]interface{}{"id": u.ID})
+	token := jtoken.GenerateAccessToken(map[string]interface{}{"id": u.ID, "role": "customer"})
 	defer cleanData(&u)
 
 	p1 := productModel.Product{
 		Name:        "test-product-1",
 		Description: "test-product-1",
-		Price:       1,
+		Price:       money.New(100, "USD"),
+		Stock:       &productModel.ProductStock{OnHand: 10},
 	}
 	dbTest.Create(context.Background(), &p1)
 
 	p2 := productModel.Product{
 		Name:        "test-product-2",
 		Description: "test-product-2",
-		Price:       2,
+		Price:       money.New(200, "USD"),
+		Stock:       &productModel.ProductStock{OnHand: 10},
 	}
 	dbTest.Create(context.Background(), &p2)
 
@@ -756,20 +964,22 @@ func TestOrderAPI_ListOrdersFindByCodeNotFound(t *testing.T) {
 		Password: "test123456",
 	}
 	dbTest.Create(context.Background(), &u)
-	token := jtoken.GenerateAccessToken(map[string]interface{}{"id": u.ID})
+	token := jtoken.GenerateAccessToken(map[string]interface{}{"id": u.ID, "role": "customer"})
 	defer cleanData(&u)
 
 	p1 := productModel.Product{
 		Name:        "test-product-1",
 		Description: "test-product-1",
-		Price:       1,
+		Price:       money.New(100, "USD"),
+		Stock:       &productModel.ProductStock{OnHand: 10},
 	}
 	dbTest.Create(context.Background(), &p1)
 
 	p2 := productModel.Product{
 		Name:        "test-product-2",
 		Description: "test-product-2",
-		Price:       2,
+		Price:       money.New(200, "USD"),
+		Stock:       &productModel.ProductStock{OnHand: 10},
 	}
 	dbTest.Create(context.Background(), &p2)
 
@@ -810,20 +1020,22 @@ func TestOrderAPI_ListOrdersWithPagination(t *testing.T) {
 		Password: "test123456",
 	}
 	dbTest.Create(context.Background(), &u)
-	token := jtoken.GenerateAccessToken(map[string]interface{}{"id": u.ID})
+	token := jtoken.GenerateAccessToken(map[string]interface{}{"id": u.ID, "role": "customer"})
 	defer cleanData(&u)
 
 	p1 := productModel.Product{
 		Name:        "test-product-1",
 		Description: "test-product-1",
-		Price:       1,
+		Price:       money.New(100, "USD"),
+		Stock:       &productModel.ProductStock{OnHand: 10},
 	}
 	dbTest.Create(context.Background(), &p1)
 
 	p2 := productModel.Product{
 		Name:        "test-product-2",
 		Description: "test-product-2",
-		Price:       2,
+		Price:       money.New(200, "USD"),
+		Stock:       &productModel.ProductStock{OnHand: 10},
 	}
 	dbTest.Create(context.Background(), &p2)
 
@@ -870,20 +1082,22 @@ func TestOrderAPI_ListOrdersWithOrder(t *testing.T) {
 		Password: "test123456",
 	}
 	dbTest.Create(context.Background(), &u)
-	token := jtoken.GenerateAccessToken(map[string]interface{}{"id": u.ID})
+	token := jtoken.GenerateAccessToken(map[string]interface{}{"id": u.ID, "role": "customer"})
 	defer cleanData(&u)
 
 	p1 := productModel.Product{
 		Name:        "test-product-1",
 		Description: "test-product-1",
-		Price:       1,
+		Price:       money.New(100, "USD"),
+		Stock:       &productModel.ProductStock{OnHand: 10},
 	}
 	dbTest.Create(context.Background(), &p1)
 
 	p2 := productModel.Product{
 		Name:        "test-product-2",
 		Description: "test-product-2",
-		Price:       2,
+		Price:       money.New(200, "USD"),
+		Stock:       &productModel.ProductStock{OnHand: 10},
 	}
 	dbTest.Create(context.Background(), &p2)
 
@@ -938,14 +1152,16 @@ func TestOrderAPI_GetMyOrdersNotMine(t *testing.T) {
 	p1 := productModel.Product{
 		Name:        "test-product-1",
 		Description: "test-product-1",
-		Price:       1,
+		Price:       money.New(100, "USD"),
+		Stock:       &productModel.ProductStock{OnHand: 10},
 	}
 	dbTest.Create(context.Background(), &p1)
 
 	p2 := productModel.Product{
 		Name:        "test-product-2",
 		Description: "test-product-2",
-		Price:       2,
+		Price:       money.New(200, "USD"),
+		Stock:       &productModel.ProductStock{OnHand: 10},
 	}
 	dbTest.Create(context.Background(), &p2)
 
@@ -0,0 +1,150 @@
+package http
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	orderModel "goshop/internal/order/model"
+	"goshop/internal/payment/dto"
+	paymentHttp "goshop/internal/payment/port/http"
+	"goshop/internal/payment/provider"
+	"goshop/pkg/config"
+	"goshop/pkg/money"
+)
+
+func authorizePayment(t *testing.T, orderID, token string) dto.Payment {
+	writer := makeRequest("POST", "/api/v1/payments", &dto.AuthorizePaymentReq{OrderID: orderID}, token)
+	var res dto.Payment
+	parseResponseResult(writer.Body.Bytes(), &res)
+	assert.Equal(t, http.StatusOK, writer.Code)
+	return res
+}
+
+func orderPaymentStatus(orderID string) string {
+	var order orderModel.Order
+	_ = dbTest.FindById(context.Background(), orderID, &order)
+	return order.PaymentStatus
+}
+
+func sendWebhook(event *provider.Event, signature string) *httptest.ResponseRecorder {
+	payload, _ := json.Marshal(event)
+	if signature == "" {
+		signature = provider.NewFakeProvider(config.GetConfig().PaymentWebhookSecret).Sign(payload)
+	}
+
+	request, _ := http.NewRequest("POST", "/api/v1/payments/webhook", bytes.NewBuffer(payload))
+	request.Header.Set(paymentHttp.SignatureHeader, signature)
+	writer := httptest.NewRecorder()
+	testRouter.ServeHTTP(writer, request)
+	return writer
+}
+
+// Authorize Payment
+// =================================================================================================
+
+func TestPaymentAPI_AuthorizeCaptureRefundSuccess(t *testing.T) {
+	defer cleanData()
+
+	order := placeOrder(t, accessToken())
+	payment := authorizePayment(t, order.ID, accessToken())
+	assert.Equal(t, "authorized", payment.Status)
+	assert.Equal(t, order.TotalPrice, payment.Amount)
+	assert.Equal(t, "fake_"+payment.ID, payment.Reference)
+	assert.Equal(t, "authorized", orderPaymentStatus(order.ID))
+
+	writer := makeRequest("PUT", fmt.Sprintf("/api/v1/payments/%s/capture", payment.ID), nil, adminToken())
+	parseResponseResult(writer.Body.Bytes(), &payment)
+	assert.Equal(t, http.StatusOK, writer.Code)
+	assert.Equal(t, "captured", payment.Status)
+	assert.Equal(t, "paid", orderPaymentStatus(order.ID))
+
+	amount := money.New(100, "USD")
+	writer = makeRequest("PUT", fmt.Sprintf("/api/v1/payments/%s/refund", payment.ID), &dto.RefundPaymentReq{Amount: &amount}, adminToken())
+	parseResponseResult(writer.Body.Bytes(), &payment)
+	assert.Equal(t, http.StatusOK, writer.Code)
+	assert.Equal(t, "partially-refunded", payment.Status)
+	assert.Equal(t, money.New(100, "USD"), payment.RefundedAmount)
+	assert.Equal(t, "partially-refunded", orderPaymentStatus(order.ID))
+
+	writer = makeRequest("GET", fmt.Sprintf("/api/v1/payments/%s", payment.ID), nil, accessToken())
+	parseResponseResult(writer.Body.Bytes(), &payment)
+	assert.Equal(t, http.StatusOK, writer.Code)
+	assert.Equal(t, "partially-refunded", payment.Status)
+}
+
+func TestPaymentAPI_AuthorizePaymentTwice(t *testing.T) {
+	defer cleanData()
+
+	order := placeOrder(t, accessToken())
+	authorizePayment(t, order.ID, accessToken())
+
+	writer := makeRequest("POST", "/api/v1/payments", &dto.AuthorizePaymentReq{OrderID: order.ID}, accessToken())
+	assert.Equal(t, http.StatusBadRequest, writer.Code)
+}
+
+func TestPaymentAPI_VoidThenPayAgain(t *testing.T) {
+	defer cleanData()
+
+	order := placeOrder(t, accessToken())
+	payment := authorizePayment(t, order.ID, accessToken())
+
+	writer := makeRequest("PUT", fmt.Sprintf("/api/v1/payments/%s/void", payment.ID), nil, adminToken())
+	assert.Equal(t, http.StatusOK, writer.Code)
+	assert.Equal(t, "unpaid", orderPaymentStatus(order.ID))
+
+	authorizePayment(t, order.ID, accessToken())
+}
+
+func TestPaymentAPI_CapturePaymentForbidden(t *testing.T) {
+	defer cleanData()
+
+	order := placeOrder(t, accessToken())
+	payment := authorizePayment(t, order.ID, accessToken())
+
+	writer := makeRequest("PUT", fmt.Sprintf("/api/v1/payments/%s/capture", payment.ID), nil, accessToken())
+	assert.Equal(t, http.StatusForbidden, writer.Code)
+}
+
+// Webhook
+// =================================================================================================
+
+func TestPaymentAPI_WebhookCaptured(t *testing.T) {
+	defer cleanData()
+
+	order := placeOrder(t, accessToken())
+	payment := authorizePayment(t, order.ID, accessToken())
+	event := &provider.Event{
+		ID:        "evt1",
+		Type:      provider.EventCaptured,
+		Reference: payment.Reference,
+		Amount:    payment.Amount,
+	}
+
+	writer := sendWebhook(event, "")
+	parseResponseResult(writer.Body.Bytes(), &payment)
+	assert.Equal(t, http.StatusOK, writer.Code)
+	assert.Equal(t, "captured", payment.Status)
+	assert.Equal(t, "paid", orderPaymentStatus(order.ID))
+
+	// Deliveries of the same event are acknowledged without changes
+	writer = sendWebhook(event, "")
+	assert.Equal(t, http.StatusOK, writer.Code)
+}
+
+func TestPaymentAPI_WebhookInvalidSignature(t *testing.T) {
+	defer cleanData()
+
+	order := placeOrder(t, accessToken())
+	payment := authorizePayment(t, order.ID, accessToken())
+
+	writer := sendWebhook(&provider.Event{Type: provider.EventCaptured, Reference: payment.Reference}, "invalid")
+	assert.Equal(t, http.StatusUnauthorized, writer.Code)
+	assert.Equal(t, "authorized", orderPaymentStatus(order.ID))
+}
@@ -11,6 +11,7 @@ import (
 
 	"goshop/internal/product/dto"
 	"goshop/internal/product/model"
+	"goshop/pkg/money"
 )
 
 // Get Product Detail
@@ -22,7 +23,7 @@ func TestProductAPI_GetProductByIDSuccess(t *testing.T) {
 	p := model.Product{
 		Name:        "test-product",
 		Description: "test-product",
-		Price:       1,
+		Price:       money.New(100, "USD"),
 	}
 	dbTest.Create(context.Background(), &p)
 
@@ -32,7 +33,7 @@ func TestProductAPI_GetProductByIDSuccess(t *testing.T) {
 	assert.Equal(t, http.StatusOK, writer.Code)
 	assert.Equal(t, "test-product", res.Name)
 	assert.Equal(t, "test-product", res.Description)
-	assert.Equal(t, float64(1), res.Price)
+	assert.Equal(t, money.New(100, "USD"), res.Price)
 }
 
 func TestProductAPI_GetProductByIDSuccessFromCache(t *testing.T) {
@@ -41,7 +42,7 @@ func TestProductAPI_GetProductByIDSuccessFromCache(t *testing.T) {
 	p := model.Product{
 		Name:        "test-product",
 		Description: "test-product",
-		Price:       1,
+		Price:       money.New(100, "USD"),
 	}
 	dbTest.Create(context.Background(), &p)
 
@@ -51,14 +52,14 @@ func TestProductAPI_GetProductByIDSuccessFromCache(t *testing.T) {
 	assert.Equal(t, http.StatusOK, writer.Code)
 	assert.Equal(t, "test-product", res.Name)
 	assert.Equal(t, "test-product", res.Description)
-	assert.Equal(t, float64(1), res.Price)
+	assert.Equal(t, money.New(100, "USD"), res.Price)
 
 	writer = makeRequest("GET", fmt.Sprintf("/api/v1/products/%s", p.ID), nil, accessToken())
 	parseResponseResult(writer.Body.Bytes(), &res)
 	assert.Equal(t, http.StatusOK, writer.Code)
 	assert.Equal(t, "test-product", res.Name)
 	assert.Equal(t, "test-product", res.Description)
-	assert.Equal(t, float64(1), res.Price)
+	assert.Equal(t, money.New(100, "USD"), res.Price)
 }
 
 func TestProductAPI_GetProductByIDNotFound(t *testing.T) {
@@ -79,7 +80,7 @@ func TestProductAPI_ListProductsSuccess(t *testing.T) {
 	p := model.Product{
 		Name:        "test-product",
 		Description: "test-product",
-		Price:       1,
+		Price:       money.New(100, "USD"),
 	}
 	dbTest.Create(context.Background(), &p)
 
@@ -94,7 +95,7 @@ func TestProductAPI_ListProductsSuccess(t *testing.T) {
 	assert.Equal(t, 1, len(res.Products))
 	assert.Equal(t, "test-product", res.Products[0].Name)
 	assert.Equal(t, "test-product", res.Products[0].Description)
-	assert.Equal(t, float64(1), res.Products[0].Price)
+	assert.Equal(t, money.New(100, "USD"), res.Products[0].Price)
 }
 
 func TestProductAPI_ListProductsSuccessFromCache(t *testing.T) {
@@ -103,7 +104,7 @@ func TestProductAPI_ListProductsSuccessFromCache(t *testing.T) {
 	p := model.Product{
 		Name:        "test-product",
 		Description: "test-product",
-		Price:       1,
+		Price:       money.New(100, "USD"),
 	}
 	dbTest.Create(context.Background(), &p)
 
@@ -118,7 +119,7 @@ func TestProductAPI_ListProductsSuccessFromCache(t *testing.T) {
 	assert.Equal(t, 1, len(res.Products))
 	assert.Equal(t, "test-product", res.Products[0].Name)
 	assert.Equal(t, "test-product", res.Products[0].Description)
-	assert.Equal(t, float64(1), res.Products[0].Price)
+	assert.Equal(t, money.New(100, "USD"), res.Products[0].Price)
 
 	writer = makeRequest("GET", "/api/v1/products", nil, accessToken())
 	parseResponseResult(writer.Body.Bytes(), &res)
@@ -130,7 +131,7 @@ func TestProductAPI_ListProductsSuccessFromCache(t *testing.T) {
 	assert.Equal(t, 1, len(res.Products))
 	assert.Equal(t, "test-product", res.Products[0].Name)
 	assert.Equal(t, "test-product", res.Products[0].Description)
-	assert.Equal(t, float64(1), res.Products[0].Price)
+	assert.Equal(t, money.New(100, "USD"), res.Products[0].Price)
 }
 
 func TestProductAPI_ListProductsNotFound(t *testing.T) {
@@ -157,7 +158,7 @@ func TestProductAPI_ListProductsFindByNameSuccess(t *testing.T) {
 	p := model.Product{
 		Name:        "test-product",
 		Description: "test-product",
-		Price:       1,
+		Price:       money.New(100, "USD"),
 	}
 	dbTest.Create(context.Background(), &p)
 
@@ -172,7 +173,7 @@ func TestProductAPI_ListProductsFindByNameSuccess(t *testing.T) {
 	assert.Equal(t, 1, len(res.Products))
 	assert.Equal(t, "test-product", res.Products[0].Name)
 	assert.Equal(t, "test-product", res.Products[0].Description)
-	assert.Equal(t, float64(1), res.Products[0].Price)
+	assert.Equal(t, money.New(100, "USD"), res.Products[0].Price)
 }
 
 func TestProductAPI_ListProductsFindByNameNotFound(t *testing.T) {
@@ -181,7 +182,7 @@ func TestProductAPI_ListProductsFindByNameNotFound(t *testing.T) {
 	p := model.Product{
 		Name:        "test-product",
 		Description: "test-product",
-		Price:       1,
+		Price:       money.New(100, "USD"),
 	}
 	dbTest.Create(context.Background(), &p)
 
@@ -198,7 +199,7 @@ func TestProductAPI_ListProductsFindByCodeSuccess(t *testing.T) {
 	p := model.Product{
 		Name:        "test-product",
 		Description: "test-product",
-		Price:       1,
+		Price:       money.New(100, "USD"),
 	}
 	dbTest.Create(context.Background(), &p)
 
@@ -213,7 +214,7 @@ func TestProductAPI_ListProductsFindByCodeSuccess(t *testing.T) {
 	assert.Equal(t, 1, len(res.Products))
 	assert.Equal(t, "test-product", res.Products[0].Name)
 	assert.Equal(t, "test-product", res.Products[0].Description)
-	assert.Equal(t, float64(1), res.Products[0].Price)
+	assert.Equal(t, money.New(100, "USD"), res.Products[0].Price)
 }
 
 func TestProductAPI_ListProductsFindByCodeNotFound(t *testing.T) {
@@ -222,7 +223,7 @@ func TestProductAPI_ListProductsFindByCodeNotFound(t *testing.T) {
 	p := model.Product{
 		Name:        "test-product",
 		Description: "test-product",
-		Price:       1,
+		Price:       money.New(100, "USD"),
 	}
 	dbTest.Create(context.Background(), &p)
 
@@ -239,21 +240,21 @@ func TestProductAPI_ListProductsWithPagination(t *testing.T) {
 	p1 := model.Product{
 		Name:        "test-product-1",
 		Description: "test-product-1",
-		Price:       1,
+		Price:       money.New(100, "USD"),
 	}
 	dbTest.Create(context.Background(), &p1)
 
 	p2 := model.Product{
 		Name:        "test-product-2",
 		Description: "test-product-2",
-		Price:       2,
+		Price:       money.New(200, "USD"),
 	}
 	dbTest.Create(context.Background(), &p2)
 
 	p3 := model.Product{
 		Name:        "test-product-3",
 		Description: "test-product-3",
-		Price:       3,
+		Price:       money.New(300, "USD"),
 	}
 	dbTest.Create(context.Background(), &p3)
 
@@ -268,7 +269,7 @@ func TestProductAPI_ListProductsWithPagination(t *testing.T) {
 	assert.Equal(t, 1, len(res.Products))
 	assert.Equal(t, "test-product-3", res.Products[0].Name)
 	assert.Equal(t, "test-product-3", res.Products[0].Description)
-	assert.Equal(t, float64(3), res.Products[0].Price)
+	assert.Equal(t, money.New(300, "USD"), res.Products[0].Price)
 }
 
 func TestProductAPI_ListProductsWithOrder(t *testing.T) {
@@ -277,21 +278,21 @@ func TestProductAPI_ListProductsWithOrder(t *testing.T) {
 	p1 := model.Product{
 		Name:        "test-product-1",
 		Description: "test-product-1",
-		Price:       1,
+		Price:       money.New(100, "USD"),
 	}
 	dbTest.Create(context.Background(), &p1)
 
 	p2 := model.Product{
 		Name:        "test-product-2",
 		Description: "test-product-2",
-		Price:       2,
+		Price:       money.New(200, "USD"),
 	}
 	dbTest.Create(context.Background(), &p2)
 
 	p3 := model.Product{
 		Name:        "test-product-3",
 		Description: "test-product-3",
-		Price:       3,
+		Price:       money.New(300, "USD"),
 	}
 	dbTest.Create(context.Background(), &p3)
 
@@ -306,7 +307,105 @@ func TestProductAPI_ListProductsWithOrder(t *testing.T) {
 	assert.Equal(t, 3, len(res.Products))
 	assert.Equal(t, "test-product-3", res.Products[0].Name)
 	assert.Equal(t, "test-product-3", res.Products[0].Description)
-	assert.Equal(t, float64(3), res.Products[0].Price)
+	assert.Equal(t, money.New(300, "USD"), res.Products[0].Price)
+}
+
+func TestProductAPI_ListProductsByCursor(t *testing.T) {
+	defer cleanData()
+
+	for i := int64(1); i <= 5; i++ {
+		p := model.Product{
+			Name:        fmt.Sprintf("test-product-%d", i),
+			Description: "test-product",
+			Price:       money.New(i*100, "USD"),
+		}
+		dbTest.Create(context.Background(), &p)
+	}
+
+	writer := makeRequest("GET", "/api/v1/products?limit=2&order_by=price", nil, accessToken())
+	var res dto.ListProductRes
+	parseResponseResult(writer.Body.Bytes(), &res)
+	assert.Equal(t, http.StatusOK, writer.Code)
+	assert.Equal(t, "test-product-1", res.Products[0].Name)
+	assert.Empty(t, res.Pagination.PrevCursor)
+
+	writer = makeRequest("GET", "/api/v1/products?limit=2&order_by=price&cursor="+res.Pagination.NextCursor, nil, accessToken())
+	res = dto.ListProductRes{}
+	parseResponseResult(writer.Body.Bytes(), &res)
+	assert.Equal(t, http.StatusOK, writer.Code)
+	assert.Equal(t, 2, len(res.Products))
+	assert.Equal(t, "test-product-3", res.Products[0].Name)
+	assert.Equal(t, "test-product-4", res.Products[1].Name)
+	assert.Equal(t, int64(5), res.Pagination.Total)
+
+	writer = makeRequest("GET", "/api/v1/products?limit=2&order_by=price&cursor="+res.Pagination.NextCursor, nil, accessToken())
+	res = dto.ListProductRes{}
+	parseResponseResult(writer.Body.Bytes(), &res)
+	assert.Equal(t, 1, len(res.Products))
+	assert.Equal(t, "test-product-5", res.Products[0].Name)
+	assert.Empty(t, res.Pagination.NextCursor)
+
+	writer = makeRequest("GET", "/api/v1/products?limit=2&order_by=price&cursor="+res.Pagination.PrevCursor, nil, accessToken())
+	res = dto.ListProductRes{}
+	parseResponseResult(writer.Body.Bytes(), &res)
+	assert.Equal(t, 2, len(res.Products))
+	assert.Equal(t, "test-product-3", res.Products[0].Name)
+	assert.Equal(t, "test-product-4", res.Products[1].Name)
+
+	// Cursors are only valid for the sort they were issued for
+	writer = makeRequest("GET", "/api/v1/products?limit=2&order_by=name&cursor="+res.Pagination.PrevCursor, nil, accessToken())
+	assert.Equal(t, http.StatusBadRequest, writer.Code)
+}
+
+func TestProductAPI_ListProductsWithPriceRange(t *testing.T) {
+	defer cleanData()
+
+	for i := int64(1); i <= 3; i++ {
+		p := model.Product{
+			Name:        fmt.Sprintf("test-product-%d", i),
+			Description: "test-product",
+			Price:       money.New(i*100, "USD"),
+		}
+		dbTest.Create(context.Background(), &p)
+	}
+
+	writer := makeRequest("GET", "/api/v1/products?min_price=1.50&max_price=3&currency=USD&order_by=price&order_desc=true", nil, accessToken())
+	var res dto.ListProductRes
+	parseResponseResult(writer.Body.Bytes(), &res)
+	assert.Equal(t, http.StatusOK, writer.Code)
+	assert.Equal(t, int64(2), res.Pagination.Total)
+	assert.Equal(t, "test-product-3", res.Products[0].Name)
+	assert.Equal(t, "test-product-2", res.Products[1].Name)
+}
+
+func TestProductAPI_ListProductsWithOptionFacets(t *testing.T) {
+	defer cleanData()
+
+	createTee(t)
+
+	writer := makeRequest("GET", "/api/v1/products?option=Size:M", nil, accessToken())
+	var res dto.ListProductRes
+	parseResponseResult(writer.Body.Bytes(), &res)
+	assert.Equal(t, http.StatusOK, writer.Code)
+	assert.Equal(t, int64(1), res.Pagination.Total)
+	assert.Equal(t, 1, len(res.Facets.Options))
+	assert.Equal(t, "Size", res.Facets.Options[0].Name)
+	assert.Equal(t, 2, len(res.Facets.Options[0].Values))
+
+	writer = makeRequest("GET", "/api/v1/products?option=Size:XL", nil, accessToken())
+	parseResponseResult(writer.Body.Bytes(), &res)
+	assert.Equal(t, http.StatusOK, writer.Code)
+	assert.Equal(t, int64(0), res.Pagination.Total)
+}
+
+func TestProductAPI_ListProductsInvalidOrder(t *testing.T) {
+	writer := makeRequest("GET", "/api/v1/products?order_by=id;DROP%20TABLE%20products", nil, accessToken())
+	assert.Equal(t, http.StatusBadRequest, writer.Code)
+}
+
+func TestProductAPI_ListProductsPriceWithoutCurrency(t *testing.T) {
+	writer := makeRequest("GET", "/api/v1/products?min_price=10", nil, accessToken())
+	assert.Equal(t, http.StatusBadRequest, writer.Code)
 }
 
 // Create Product
@@ -318,15 +417,25 @@ func TestProductAPI_CreateProductSuccess(t *testing.T) {
 	p := &dto.CreateProductReq{
 		Name:        "test-product",
 		Description: "test-product",
-		Price:       1,
+		Price:       money.New(100, "USD"),
 	}
-	writer := makeRequest("POST", "/api/v1/products", p, accessToken())
+	writer := makeRequest("POST", "/api/v1/products", p, adminToken())
 	var res model.Product
 	parseResponseResult(writer.Body.Bytes(), &res)
 	assert.Equal(t, http.StatusOK, writer.Code)
 	assert.Equal(t, "test-product", res.Name)
 	assert.Equal(t, "test-product", res.Description)
-	assert.Equal(t, float64(1), res.Price)
+	assert.Equal(t, money.New(100, "USD"), res.Price)
+}
+
+func TestProductAPI_CreateProductForbidden(t *testing.T) {
+	p := &dto.CreateProductReq{
+		Name:        "test-product",
+		Description: "test-product",
+		Price:       money.New(100, "USD"),
+	}
+	writer := makeRequest("POST", "/api/v1/products", p, accessToken())
+	assert.Equal(t, http.StatusForbidden, writer.Code)
 }
 
 func TestProductAPI_CreateProductInvalidFieldType(t *testing.T) {
@@ -337,7 +446,7 @@ func TestProductAPI_CreateProductInvalidFieldType(t *testing.T) {
 		"description": "test-product",
 		"price":       "1",
 	}
-	writer := makeRequest("POST", "/api/v1/products", p, accessToken())
+	writer := makeRequest("POST", "/api/v1/products", p, adminToken())
 	var response map[string]map[string]string
 	_ = json.Unmarshal(writer.Body.Bytes(), &response)
 	assert.Equal(t, http.StatusBadRequest, writer.Code)
@@ -349,9 +458,9 @@ func TestProductAPI_CreateProductMissingName(t *testing.T) {
 
 	p := &dto.CreateProductReq{
 		Description: "test-product",
-		Price:       1,
+		Price:       money.New(100, "USD"),
 	}
-	writer := makeRequest("POST", "/api/v1/products", p, accessToken())
+	writer := makeRequest("POST", "/api/v1/products", p, adminToken())
 	var response map[string]map[string]string
 	_ = json.Unmarshal(writer.Body.Bytes(), &response)
 	assert.Equal(t, http.StatusInternalServerError, writer.Code)
@@ -363,9 +472,9 @@ func TestProductAPI_CreateProductMissingDescription(t *testing.T) {
 
 	p := &dto.CreateProductReq{
 		Name:  "test-product",
-		Price: 1,
+		Price: money.New(100, "USD"),
 	}
-	writer := makeRequest("POST", "/api/v1/products", p, accessToken())
+	writer := makeRequest("POST", "/api/v1/products", p, adminToken())
 	var response map[string]map[string]string
 	_ = json.Unmarshal(writer.Body.Bytes(), &response)
 	assert.Equal(t, http.StatusInternalServerError, writer.Code)
@@ -378,9 +487,9 @@ func TestProductAPI_CreateProductPriceLessThanZero(t *testing.T) {
 	p := &dto.CreateProductReq{
 		Name:        "test-product",
 		Description: "test-product",
-		Price:       -1,
+		Price:       money.New(-100, "USD"),
 	}
-	writer := makeRequest("POST", "/api/v1/products", p, accessToken())
+	writer := makeRequest("POST", "/api/v1/products", p, adminToken())
 	var response map[string]map[string]string
 	_ = json.Unmarshal(writer.Body.Bytes(), &response)
 	assert.Equal(t, http.StatusInternalServerError, writer.Code)
@@ -393,9 +502,9 @@ func TestProductAPI_CreateProductPriceEqualZero(t *testing.T) {
 	p := &dto.CreateProductReq{
 		Name:        "test-product",
 		Description: "test-product",
-		Price:       0,
+		Price:       money.New(0, "USD"),
 	}
-	writer := makeRequest("POST", "/api/v1/products", p, accessToken())
+	writer := makeRequest("POST", "/api/v1/products", p, adminToken())
 	var response map[string]map[string]string
 	_ = json.Unmarshal(writer.Body.Bytes(), &response)
 	assert.Equal(t, http.StatusInternalServerError, writer.Code)
@@ -408,11 +517,11 @@ func TestProductAPI_CreateProductDuplicateName(t *testing.T) {
 	p := model.Product{
 		Name:        "test-product",
 		Description: "test-product",
-		Price:       1,
+		Price:       money.New(100, "USD"),
 	}
 	dbTest.Create(context.Background(), &p)
 
-	writer := makeRequest("POST", "/api/v1/products", p, accessToken())
+	writer := makeRequest("POST", "/api/v1/products", p, adminToken())
 	var response map[string]map[string]string
 	_ = json.Unmarshal(writer.Body.Bytes(), &response)
 	assert.Equal(t, http.StatusInternalServerError, writer.Code)
@@ -428,20 +537,53 @@ func TestProductAPI_UpdateProductSuccess(t *testing.T) {
 	p := model.Product{
 		Name:        "test-product",
 		Description: "test-product",
-		Price:       1,
+		Price:       money.New(100, "USD"),
 	}
 	dbTest.Create(context.Background(), &p)
 
 	update := &dto.UpdateProductReq{
 		Name: "update-test-product",
 	}
-	writer := makeRequest("PUT", fmt.Sprintf("/api/v1/products/%s", p.ID), update, accessToken())
+	writer := makeRequest("PUT", fmt.Sprintf("/api/v1/products/%s", p.ID), update, adminToken())
 	var res model.Product
 	parseResponseResult(writer.Body.Bytes(), &res)
 	assert.Equal(t, http.StatusOK, writer.Code)
 	assert.Equal(t, "update-test-product", res.Name)
 	assert.Equal(t, "test-product", res.Description)
-	assert.Equal(t, float64(1), res.Price)
+	assert.Equal(t, money.New(100, "USD"), res.Price)
+}
+
+func TestProductAPI_UpdateProductInvalidatesCache(t *testing.T) {
+	defer cleanData()
+
+	p1 := model.Product{Name: "test-product-1", Description: "test-product", Price: money.New(100, "USD")}
+	dbTest.Create(context.Background(), &p1)
+	p2 := model.Product{Name: "test-product-2", Description: "test-product", Price: money.New(200, "USD")}
+	dbTest.Create(context.Background(), &p2)
+
+	// Cache the details of both products and the list
+	makeRequest("GET", fmt.Sprintf("/api/v1/products/%s", p1.ID), nil, "")
+	makeRequest("GET", fmt.Sprintf("/api/v1/products/%s", p2.ID), nil, "")
+	makeRequest("GET", "/api/v1/products", nil, "")
+
+	update := &dto.UpdateProductReq{Name: "update-test-product"}
+	writer := makeRequest("PUT", fmt.Sprintf("/api/v1/products/%s", p1.ID), update, adminToken())
+	assert.Equal(t, http.StatusOK, writer.Code)
+
+	var product dto.Product
+	writer = makeRequest("GET", fmt.Sprintf("/api/v1/products/%s", p1.ID), nil, "")
+	parseResponseResult(writer.Body.Bytes(), &product)
+	assert.Equal(t, "update-test-product", product.Name)
+
+	var list dto.ListProductRes
+	writer = makeRequest("GET", "/api/v1/products", nil, "")
+	parseResponseResult(writer.Body.Bytes(), &list)
+	assert.Equal(t, 2, len(list.Products))
+
+	// The details of the other product stay cached
+	var cached dto.Product
+	assert.Nil(t, testCache.Get(fmt.Sprintf("/api/v1/products/%s", p2.ID), &cached))
+	assert.Equal(t, "test-product-2", cached.Name)
 }
 
 func TestProductAPI_UpdateProductInvalidFieldType(t *testing.T) {
@@ -450,14 +592,14 @@ func TestProductAPI_UpdateProductInvalidFieldType(t *testing.T) {
 	p := model.Product{
 		Name:        "test-product",
 		Description: "test-product",
-		Price:       1,
+		Price:       money.New(100, "USD"),
 	}
 	dbTest.Create(context.Background(), &p)
 
 	update := map[string]interface{}{
 		"price": "1",
 	}
-	writer := makeRequest("PUT", fmt.Sprintf("/api/v1/products/%s", p.ID), update, accessToken())
+	writer := makeRequest("PUT", fmt.Sprintf("/api/v1/products/%s", p.ID), update, adminToken())
 	var response map[string]map[string]string
 	_ = json.Unmarshal(writer.Body.Bytes(), &response)
 	assert.Equal(t, http.StatusBadRequest, writer.Code)
@@ -470,14 +612,15 @@ func TestProductAPI_UpdateProductPriceLessThanZero(t *testing.T) {
 	p := model.Product{
 		Name:        "test-product",
 		Description: "test-product",
-		Price:       1,
+		Price:       money.New(100, "USD"),
 	}
 	dbTest.Create(context.Background(), &p)
 
+	price := money.New(-100, "USD")
 	update := &dto.UpdateProductReq{
-		Price: -1,
+		Price: &price,
 	}
-	writer := makeRequest("PUT", fmt.Sprintf("/api/v1/products/%s", p.ID), update, accessToken())
+	writer := makeRequest("PUT", fmt.Sprintf("/api/v1/products/%s", p.ID), update, adminToken())
 	var response map[string]map[string]string
 	_ = json.Unmarshal(writer.Body.Bytes(), &response)
 	assert.Equal(t, http.StatusInternalServerError, writer.Code)
@@ -486,12 +629,141 @@ func TestProductAPI_UpdateProductPriceLessThanZero(t *testing.T) {
 
 func TestProductAPI_UpdateProductNotFound(t *testing.T) {
 	defer cleanData()
+	price := money.New(100, "USD")
 	update := &dto.UpdateProductReq{
-		Price: 1,
+		Price: &price,
 	}
-	writer := makeRequest("PUT", "/api/v1/products/notfound", update, accessToken())
+	writer := makeRequest("PUT", "/api/v1/products/notfound", update, adminToken())
 	var response map[string]map[string]string
 	_ = json.Unmarshal(writer.Body.Bytes(), &response)
 	assert.Equal(t, http.StatusInternalServerError, writer.Code)
 	assert.Equal(t, "Something went wrong", response["error"]["message"])
 }
+
+// Adjust Stock
+// =================================================================================================
+
+func TestProductAPI_AdjustStockSuccess(t *testing.T) {
+	defer cleanData()
+
+	p := model.Product{
+		Name:        "test-product",
+		Description: "test-product",
+		Price:       money.New(100, "USD"),
+	}
+	dbTest.Create(context.Background(), &p)
+
+	token := adminToken()
+	req := &dto.AdjustStockReq{
+		Quantity: 5,
+		Reason:   "restock",
+	}
+	writer := makeRequest("PUT", fmt.Sprintf("/api/v1/products/%s/stock", p.ID), req, token)
+	var res dto.ProductStock
+	parseResponseResult(writer.Body.Bytes(), &res)
+	assert.Equal(t, http.StatusOK, writer.Code)
+	assert.Equal(t, int64(5), res.OnHand)
+	assert.Equal(t, int64(5), res.Available)
+
+	writer = makeRequest("GET", fmt.Sprintf("/api/v1/products/%s/stock/movements", p.ID), nil, token)
+	var movements dto.ListStockMovementRes
+	parseResponseResult(writer.Body.Bytes(), &movements)
+	assert.Equal(t, http.StatusOK, writer.Code)
+	assert.Equal(t, 1, len(movements.Movements))
+	assert.Equal(t, "restock", movements.Movements[0].Reason)
+}
+
+func TestProductAPI_AdjustStockBelowReserved(t *testing.T) {
+	defer cleanData()
+
+	p := model.Product{
+		Name:        "test-product",
+		Description: "test-product",
+		Price:       money.New(100, "USD"),
+		Stock:       &model.ProductStock{OnHand: 2},
+	}
+	dbTest.Create(context.Background(), &p)
+
+	token := adminToken()
+	req := &dto.AdjustStockReq{
+		Quantity: -3,
+		Reason:   "damaged",
+	}
+	writer := makeRequest("PUT", fmt.Sprintf("/api/v1/products/%s/stock", p.ID), req, token)
+	assert.Equal(t, http.StatusBadRequest, writer.Code)
+}
+
+func TestProductAPI_AdjustStockForbidden(t *testing.T) {
+	req := &dto.AdjustStockReq{
+		Quantity: 5,
+		Reason:   "restock",
+	}
+	writer := makeRequest("PUT", "/api/v1/products/notfound/stock", req, accessToken())
+	assert.Equal(t, http.StatusForbidden, writer.Code)
+}
+
+// Search Products
+// =================================================================================================
+
+func TestProductAPI_SearchProductsRanksNameMatchesFirst(t *testing.T) {
+	defer cleanData()
+
+	token := adminToken()
+	for _, p := range []*dto.CreateProductReq{
+		{Name: "Leather wallet", Description: "Goes well with running shoes", Price: money.New(100, "USD")},
+		{Name: "Running shoes", Description: "Light and breathable", Price: money.New(200, "USD")},
+		{Name: "Coffee mug", Description: "Ceramic", Price: money.New(300, "USD")},
+	} {
+		writer := makeRequest("POST", "/api/v1/products", p, token)
+		assert.Equal(t, http.StatusOK, writer.Code)
+	}
+
+	writer := makeRequest("GET", "/api/v1/products/search?q=runn", nil, "")
+	var res dto.ListProductRes
+	parseResponseResult(writer.Body.Bytes(), &res)
+	assert.Equal(t, http.StatusOK, writer.Code)
+	assert.Equal(t, int64(2), res.Pagination.Total)
+	assert.Equal(t, "Running shoes", res.Products[0].Name)
+	assert.Equal(t, "Leather wallet", res.Products[1].Name)
+}
+
+func TestProductAPI_SearchProductsToleratesTypos(t *testing.T) {
+	defer cleanData()
+
+	writer := makeRequest("POST", "/api/v1/products", &dto.CreateProductReq{
+		Name:        "Coffee mug",
+		Description: "Ceramic",
+		Price:       money.New(300, "USD"),
+	}, adminToken())
+	assert.Equal(t, http.StatusOK, writer.Code)
+
+	writer = makeRequest("GET", "/api/v1/products/search?q=cofee", nil, "")
+	var res dto.ListProductRes
+	parseResponseResult(writer.Body.Bytes(), &res)
+	assert.Equal(t, http.StatusOK, writer.Code)
+	assert.Equal(t, 1, len(res.Products))
+	assert.Equal(t, "Coffee mug", res.Products[0].Name)
+}
+
+func TestProductAPI_SearchProductsFindsUpdatedName(t *testing.T) {
+	defer cleanData()
+
+	token := adminToken()
+	writer := makeRequest("POST", "/api/v1/products", &dto.CreateProductReq{
+		Name:        "Coffee mug",
+		Description: "Ceramic",
+		Price:       money.New(300, "USD"),
+	}, token)
+	var product dto.Product
+	parseResponseResult(writer.Body.Bytes(), &product)
+
+	writer = makeRequest("PUT", fmt.Sprintf("/api/v1/products/%s", product.ID), &dto.UpdateProductReq{Name: "Tea cup"}, token)
+	assert.Equal(t, http.StatusOK, writer.Code)
+
+	writer = makeRequest("GET", "/api/v1/products/search?q=tea", nil, "")
+	var res dto.ListProductRes
+	parseResponseResult(writer.Body.Bytes(), &res)
+	assert.Equal(t, http.StatusOK, writer.Code)
+	assert.Equal(t, 1, len(res.Products))
+	assert.Equal(t, "Tea cup", res.Products[0].Name)
+}
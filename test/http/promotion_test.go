@@ -0,0 +1,111 @@
+package http
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	orderDto "goshop/internal/order/dto"
+	productModel "goshop/internal/product/model"
+	"goshop/internal/promotion/dto"
+	"goshop/pkg/money"
+)
+
+// Create Promotion
+// =================================================================================================
+
+func TestPromotionAPI_CreatePromotionSuccess(t *testing.T) {
+	defer cleanData()
+
+	req := &dto.CreatePromotionReq{
+		Code:       "save10",
+		Name:       "Save 10%",
+		Type:       "percentage",
+		Percentage: 10,
+	}
+	writer := makeRequest("POST", "/api/v1/promotions", req, adminToken())
+	var res dto.Promotion
+	parseResponseResult(writer.Body.Bytes(), &res)
+	assert.Equal(t, http.StatusOK, writer.Code)
+	assert.Equal(t, "SAVE10", res.Code)
+	assert.True(t, res.Active)
+
+	writer = makeRequest("GET", fmt.Sprintf("/api/v1/promotions/%s", res.ID), nil, adminToken())
+	assert.Equal(t, http.StatusOK, writer.Code)
+}
+
+func TestPromotionAPI_CreatePromotionForbidden(t *testing.T) {
+	req := &dto.CreatePromotionReq{
+		Code:       "save10",
+		Name:       "Save 10%",
+		Type:       "percentage",
+		Percentage: 10,
+	}
+	writer := makeRequest("POST", "/api/v1/promotions", req, accessToken())
+	assert.Equal(t, http.StatusForbidden, writer.Code)
+}
+
+// Place Order with coupons
+// =================================================================================================
+
+func TestPromotionAPI_PlaceOrderWithCoupon(t *testing.T) {
+	defer cleanData()
+
+	p1 := productModel.Product{
+		Name:        "test-product-1",
+		Description: "test-product-1",
+		Price:       money.New(1000, "USD"),
+		Stock:       &productModel.ProductStock{OnHand: 10},
+	}
+	dbTest.Create(context.Background(), &p1)
+
+	promotion := &dto.CreatePromotionReq{
+		Code:           "welcome",
+		Name:           "Welcome",
+		Type:           "fixed",
+		Discount:       money.New(500, "USD"),
+		UserUsageLimit: 1,
+	}
+	writer := makeRequest("POST", "/api/v1/promotions", promotion, adminToken())
+	assert.Equal(t, http.StatusOK, writer.Code)
+
+	req := &orderDto.PlaceOrderReq{
+		Lines:       []orderDto.PlaceOrderLineReq{{ProductID: p1.ID, Quantity: 2}},
+		CouponCodes: []string{"WELCOME"},
+	}
+	writer = makeRequest("POST", "/api/v1/orders", req, accessToken())
+	var res orderDto.Order
+	parseResponseResult(writer.Body.Bytes(), &res)
+	assert.Equal(t, http.StatusOK, writer.Code)
+	assert.Equal(t, money.New(1500, "USD"), res.TotalPrice)
+	assert.Equal(t, money.New(500, "USD"), res.Discount)
+	assert.Equal(t, money.New(500, "USD"), res.Lines[0].Discount)
+	assert.Equal(t, 1, len(res.Discounts))
+	assert.Equal(t, "WELCOME", res.Discounts[0].Code)
+
+	// The promotion can only be used once per user
+	writer = makeRequest("POST", "/api/v1/orders", req, accessToken())
+	assert.Equal(t, http.StatusBadRequest, writer.Code)
+}
+
+func TestPromotionAPI_PlaceOrderUnknownCoupon(t *testing.T) {
+	defer cleanData()
+
+	p1 := productModel.Product{
+		Name:        "test-product-1",
+		Description: "test-product-1",
+		Price:       money.New(1000, "USD"),
+		Stock:       &productModel.ProductStock{OnHand: 10},
+	}
+	dbTest.Create(context.Background(), &p1)
+
+	req := &orderDto.PlaceOrderReq{
+		Lines:       []orderDto.PlaceOrderLineReq{{ProductID: p1.ID, Quantity: 1}},
+		CouponCodes: []string{"UNKNOWN"},
+	}
+	writer := makeRequest("POST", "/api/v1/orders", req, accessToken())
+	assert.Equal(t, http.StatusBadRequest, writer.Code)
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"encoding/json"
 	"net/http"
+	"strings"
 	"testing"
 
 	"goshop/internal/user/dto"
@@ -11,6 +12,7 @@ import (
 	"goshop/pkg/jtoken"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 // Login
@@ -43,6 +45,8 @@ func TestUserAPI_LoginInvalidFieldType(t *testing.T) {
 }
 
 func TestUserAPI_LoginInvalidEmailFormat(t *testing.T) {
+	defer cleanData()
+
 	user := &dto.LoginReq{
 		Email:    "invalid",
 		Password: "test123456",
@@ -50,11 +54,13 @@ func TestUserAPI_LoginInvalidEmailFormat(t *testing.T) {
 	writer := makeRequest("POST", "/api/v1/auth/login", user, "")
 	var response map[string]map[string]string
 	_ = json.Unmarshal(writer.Body.Bytes(), &response)
-	assert.Equal(t, http.StatusInternalServerError, writer.Code)
-	assert.Equal(t, "Something went wrong", response["error"]["message"])
+	assert.Equal(t, http.StatusUnauthorized, writer.Code)
+	assert.Equal(t, "Invalid email or password", response["error"]["message"])
 }
 
 func TestUserAPI_LoginInvalidPassword(t *testing.T) {
+	defer cleanData()
+
 	user := &dto.LoginReq{
 		Email:    "test@test.com",
 		Password: "test",
@@ -62,11 +68,13 @@ func TestUserAPI_LoginInvalidPassword(t *testing.T) {
 	writer := makeRequest("POST", "/api/v1/auth/login", user, "")
 	var response map[string]map[string]string
 	_ = json.Unmarshal(writer.Body.Bytes(), &response)
-	assert.Equal(t, http.StatusInternalServerError, writer.Code)
-	assert.Equal(t, "Something went wrong", response["error"]["message"])
+	assert.Equal(t, http.StatusUnauthorized, writer.Code)
+	assert.Equal(t, "Invalid email or password", response["error"]["message"])
 }
 
 func TestUserAPI_LoginUserNotFound(t *testing.T) {
+	defer cleanData()
+
 	user := &dto.LoginReq{
 		Email:    "notfound@test.com",
 		Password: "test123456",
@@ -74,11 +82,13 @@ func TestUserAPI_LoginUserNotFound(t *testing.T) {
 	writer := makeRequest("POST", "/api/v1/auth/login", user, "")
 	var response map[string]map[string]string
 	_ = json.Unmarshal(writer.Body.Bytes(), &response)
-	assert.Equal(t, http.StatusInternalServerError, writer.Code)
-	assert.Equal(t, "Something went wrong", response["error"]["message"])
+	assert.Equal(t, http.StatusUnauthorized, writer.Code)
+	assert.Equal(t, "Invalid email or password", response["error"]["message"])
 }
 
 func TestUserAPI_LoginUserWrongPassword(t *testing.T) {
+	defer cleanData()
+
 	user := &dto.LoginReq{
 		Email:    "test@test.com",
 		Password: "test1234567",
@@ -86,8 +96,40 @@ func TestUserAPI_LoginUserWrongPassword(t *testing.T) {
 	writer := makeRequest("POST", "/api/v1/auth/login", user, "")
 	var response map[string]map[string]string
 	_ = json.Unmarshal(writer.Body.Bytes(), &response)
-	assert.Equal(t, http.StatusInternalServerError, writer.Code)
-	assert.Equal(t, "Something went wrong", response["error"]["message"])
+	assert.Equal(t, http.StatusUnauthorized, writer.Code)
+	assert.Equal(t, "Invalid email or password", response["error"]["message"])
+}
+
+func TestUserAPI_LoginLockedAndUnlocked(t *testing.T) {
+	defer cleanData()
+
+	user := &dto.LoginReq{
+		Email:    "test@test.com",
+		Password: "test1234567",
+	}
+	writer := makeRequest("POST", "/api/v1/auth/login", user, "")
+	require.Equal(t, http.StatusUnauthorized, writer.Code)
+
+	// The failure locks the account for the backoff, even with the right password
+	user.Password = "test123456"
+	writer = makeRequest("POST", "/api/v1/auth/login", user, "")
+	var response map[string]map[string]string
+	_ = json.Unmarshal(writer.Body.Bytes(), &response)
+	assert.Equal(t, http.StatusTooManyRequests, writer.Code)
+	assert.Equal(t, "Too many failed attempts, try again later", response["error"]["message"])
+	assert.NotEmpty(t, writer.Header().Get("Retry-After"))
+
+	// Customers can't unlock logins
+	unlock := &dto.UnlockLoginReq{Email: "test@test.com"}
+	customerToken := jtoken.GenerateAccessToken(map[string]interface{}{"id": "customer", "role": "customer"})
+	writer = makeRequest("POST", "/api/v1/users/unlock", unlock, customerToken)
+	assert.Equal(t, http.StatusForbidden, writer.Code)
+
+	writer = makeRequest("POST", "/api/v1/users/unlock", unlock, adminToken())
+	assert.Equal(t, http.StatusOK, writer.Code)
+
+	writer = makeRequest("POST", "/api/v1/auth/login", user, "")
+	assert.Equal(t, http.StatusOK, writer.Code)
 }
 
 // Register
@@ -220,7 +262,7 @@ func TestUserAPI_RefreshTokenInvalidTokenType(t *testing.T) {
 	assert.Equal(t, http.StatusUnauthorized, writer.Code)
 }
 
-func TestUserAPI_RefreshTokenUserNotFound(t *testing.T) {
+func TestUserAPI_RefreshTokenWithoutJTI(t *testing.T) {
 	token := jtoken.GenerateRefreshToken(map[string]interface{}{
 		"id": "user-not-found",
 	})
@@ -228,8 +270,210 @@ func TestUserAPI_RefreshTokenUserNotFound(t *testing.T) {
 	writer := makeRequest("POST", "/api/v1/auth/refresh", nil, token)
 	var response map[string]map[string]string
 	_ = json.Unmarshal(writer.Body.Bytes(), &response)
-	assert.Equal(t, http.StatusInternalServerError, writer.Code)
-	assert.Equal(t, "Something went wrong", response["error"]["message"])
+	assert.Equal(t, http.StatusUnauthorized, writer.Code)
+	assert.Equal(t, "Unauthorized", response["error"]["message"])
+}
+
+func TestUserAPI_RefreshTokenRotates(t *testing.T) {
+	defer cleanData()
+
+	token := refreshToken()
+	writer := makeRequest("POST", "/api/v1/auth/refresh", nil, token)
+	var response map[string]map[string]string
+	_ = json.Unmarshal(writer.Body.Bytes(), &response)
+	assert.Equal(t, http.StatusOK, writer.Code)
+	rotated := response["result"]["refresh_token"]
+	assert.NotEmpty(t, rotated)
+	assert.NotEqual(t, token, rotated)
+
+	writer = makeRequest("POST", "/api/v1/auth/refresh", nil, rotated)
+	assert.Equal(t, http.StatusOK, writer.Code)
+}
+
+func TestUserAPI_RefreshTokenReuseRevokesFamily(t *testing.T) {
+	defer cleanData()
+
+	token := refreshToken()
+	writer := makeRequest("POST", "/api/v1/auth/refresh", nil, token)
+	var response map[string]map[string]string
+	_ = json.Unmarshal(writer.Body.Bytes(), &response)
+	assert.Equal(t, http.StatusOK, writer.Code)
+	rotated := response["result"]["refresh_token"]
+
+	writer = makeRequest("POST", "/api/v1/auth/refresh", nil, token)
+	assert.Equal(t, http.StatusUnauthorized, writer.Code)
+
+	// The token rotated from the reused one is revoked along with it
+	writer = makeRequest("POST", "/api/v1/auth/refresh", nil, rotated)
+	assert.Equal(t, http.StatusUnauthorized, writer.Code)
+}
+
+// Logout
+// =================================================================================================
+
+func TestUserAPI_LogoutSuccess(t *testing.T) {
+	defer cleanData()
+
+	token := refreshToken()
+	other := refreshToken()
+	writer := makeRequest("POST", "/api/v1/auth/logout", nil, token)
+	assert.Equal(t, http.StatusOK, writer.Code)
+
+	writer = makeRequest("POST", "/api/v1/auth/refresh", nil, token)
+	assert.Equal(t, http.StatusUnauthorized, writer.Code)
+
+	// Other sessions are not logged out
+	writer = makeRequest("POST", "/api/v1/auth/refresh", nil, other)
+	assert.Equal(t, http.StatusOK, writer.Code)
+}
+
+func TestUserAPI_LogoutWithAccessToken(t *testing.T) {
+	writer := makeRequest("POST", "/api/v1/auth/logout", nil, accessToken())
+	assert.Equal(t, http.StatusUnauthorized, writer.Code)
+}
+
+func TestUserAPI_LogoutAllSuccess(t *testing.T) {
+	defer cleanData()
+
+	first := refreshToken()
+	second := refreshToken()
+	writer := makeRequest("POST", "/api/v1/auth/logout-all", nil, accessToken())
+	assert.Equal(t, http.StatusOK, writer.Code)
+
+	writer = makeRequest("POST", "/api/v1/auth/refresh", nil, first)
+	assert.Equal(t, http.StatusUnauthorized, writer.Code)
+	writer = makeRequest("POST", "/api/v1/auth/refresh", nil, second)
+	assert.Equal(t, http.StatusUnauthorized, writer.Code)
+}
+
+func TestUserAPI_LogoutAllUnauthorized(t *testing.T) {
+	writer := makeRequest("POST", "/api/v1/auth/logout-all", nil, "")
+	assert.Equal(t, http.StatusUnauthorized, writer.Code)
+}
+
+// Password Reset
+// =================================================================================================
+
+// mailToken returns the token of the last email sent to email
+func mailToken(t *testing.T, email string) string {
+	msg := testMail.last(email)
+	require.NotNil(t, msg)
+	return strings.Split(msg.Body, "\n\n")[1]
+}
+
+func TestUserAPI_ResetPasswordSuccess(t *testing.T) {
+	defer cleanData()
+
+	writer := makeRequest("POST", "/api/v1/auth/forgot-password", dto.ForgotPasswordReq{Email: "test@test.com"}, "")
+	assert.Equal(t, http.StatusOK, writer.Code)
+	token := mailToken(t, "test@test.com")
+
+	session := refreshToken()
+	req := dto.ResetPasswordReq{Token: token, NewPassword: "test123456"}
+	writer = makeRequest("POST", "/api/v1/auth/reset-password", req, "")
+	assert.Equal(t, http.StatusOK, writer.Code)
+
+	// Sessions are logged out, and the token cannot be used again
+	writer = makeRequest("POST", "/api/v1/auth/refresh", nil, session)
+	assert.Equal(t, http.StatusUnauthorized, writer.Code)
+	writer = makeRequest("POST", "/api/v1/auth/reset-password", req, "")
+	assert.Equal(t, http.StatusBadRequest, writer.Code)
+
+	assert.NotEmpty(t, accessToken())
+}
+
+func TestUserAPI_ForgotPasswordUnknownEmail(t *testing.T) {
+	known := makeRequest("POST", "/api/v1/auth/forgot-password", dto.ForgotPasswordReq{Email: "test@test.com"}, "")
+	unknown := makeRequest("POST", "/api/v1/auth/forgot-password", dto.ForgotPasswordReq{Email: "unknown@test.com"}, "")
+	assert.Equal(t, http.StatusOK, unknown.Code)
+	assert.Equal(t, known.Body.String(), unknown.Body.String())
+	assert.Nil(t, testMail.last("unknown@test.com"))
+}
+
+func TestUserAPI_ResetPasswordInvalidToken(t *testing.T) {
+	req := dto.ResetPasswordReq{Token: "invalid", NewPassword: "test123456"}
+	writer := makeRequest("POST", "/api/v1/auth/reset-password", req, "")
+	var response map[string]map[string]string
+	_ = json.Unmarshal(writer.Body.Bytes(), &response)
+	assert.Equal(t, http.StatusBadRequest, writer.Code)
+	assert.Equal(t, "Invalid or expired token", response["error"]["message"])
+}
+
+// Email Verification
+// =================================================================================================
+
+func TestUserAPI_VerifyEmailSuccess(t *testing.T) {
+	defer cleanData()
+
+	user := &dto.RegisterReq{
+		Email:    "verify@test.com",
+		Password: "test123456",
+	}
+	writer := makeRequest("POST", "/api/v1/auth/register", user, "")
+	assert.Equal(t, http.StatusOK, writer.Code)
+	var registered dto.RegisterRes
+	parseResponseResult(writer.Body.Bytes(), &registered)
+	assert.Nil(t, registered.User.EmailVerifiedAt)
+
+	req := dto.VerifyEmailReq{Token: mailToken(t, user.Email)}
+	writer = makeRequest("POST", "/api/v1/auth/verify-email", req, "")
+	assert.Equal(t, http.StatusOK, writer.Code)
+	writer = makeRequest("POST", "/api/v1/auth/verify-email", req, "")
+	assert.Equal(t, http.StatusBadRequest, writer.Code)
+
+	writer = makeRequest("POST", "/api/v1/auth/login", dto.LoginReq{Email: user.Email, Password: user.Password}, "")
+	var login dto.LoginRes
+	parseResponseResult(writer.Body.Bytes(), &login)
+	assert.NotNil(t, login.User.EmailVerifiedAt)
+}
+
+func TestUserAPI_ResendVerification(t *testing.T) {
+	defer cleanData()
+
+	user := &dto.RegisterReq{
+		Email:    "resend@test.com",
+		Password: "test123456",
+	}
+	writer := makeRequest("POST", "/api/v1/auth/register", user, "")
+	assert.Equal(t, http.StatusOK, writer.Code)
+	first := mailToken(t, user.Email)
+
+	writer = makeRequest("POST", "/api/v1/auth/resend-verification", dto.ResendVerificationReq{Email: user.Email}, "")
+	assert.Equal(t, http.StatusOK, writer.Code)
+	second := mailToken(t, user.Email)
+	assert.NotEqual(t, first, second)
+
+	writer = makeRequest("POST", "/api/v1/auth/verify-email", dto.VerifyEmailReq{Token: second}, "")
+	assert.Equal(t, http.StatusOK, writer.Code)
+
+	// Verified and unknown emails get no email, with the same response
+	writer = makeRequest("POST", "/api/v1/auth/resend-verification", dto.ResendVerificationReq{Email: user.Email}, "")
+	assert.Equal(t, http.StatusOK, writer.Code)
+	assert.Equal(t, second, mailToken(t, user.Email))
+	writer = makeRequest("POST", "/api/v1/auth/resend-verification", dto.ResendVerificationReq{Email: "unknown@test.com"}, "")
+	assert.Equal(t, http.StatusOK, writer.Code)
+	assert.Nil(t, testMail.last("unknown@test.com"))
+}
+
+func TestUserAPI_VerifyEmailInvalidToken(t *testing.T) {
+	writer := makeRequest("POST", "/api/v1/auth/verify-email", dto.VerifyEmailReq{Token: "invalid"}, "")
+	var response map[string]map[string]string
+	_ = json.Unmarshal(writer.Body.Bytes(), &response)
+	assert.Equal(t, http.StatusBadRequest, writer.Code)
+	assert.Equal(t, "Invalid or expired token", response["error"]["message"])
+}
+
+// JWKS
+// =================================================================================================
+
+func TestUserAPI_JWKS(t *testing.T) {
+	writer := makeRequest("GET", "/.well-known/jwks.json", nil, "")
+	var response map[string][]map[string]string
+	_ = json.Unmarshal(writer.Body.Bytes(), &response)
+	assert.Equal(t, http.StatusOK, writer.Code)
+	// Tokens of the tests are signed with auth_secret, which is never published
+	assert.NotNil(t, response["keys"])
+	assert.Empty(t, response["keys"])
 }
 
 // Change Password
@@ -0,0 +1,142 @@
+package http
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	orderDto "goshop/internal/order/dto"
+	"goshop/internal/product/dto"
+	"goshop/pkg/money"
+)
+
+// createTee creates a tee sold in sizes S and M, the M one costing more
+func createTee(t *testing.T) *dto.Product {
+	price := money.New(1500, "USD")
+	req := &dto.CreateProductReq{
+		Name:        "Tee",
+		Description: "Cotton tee",
+		Price:       money.New(1200, "USD"),
+		Options:     []*dto.CreateOptionReq{{Name: "Size", Values: []string{"S", "M"}}},
+		Variants: []*dto.CreateVariantReq{
+			{SKU: "TEE-S", Options: map[string]string{"Size": "S"}},
+			{SKU: "TEE-M", Price: &price, Options: map[string]string{"Size": "M"}},
+		},
+	}
+	writer := makeRequest("POST", "/api/v1/products", req, adminToken())
+	assert.Equal(t, http.StatusOK, writer.Code)
+
+	var res dto.Product
+	parseResponseResult(writer.Body.Bytes(), &res)
+	return &res
+}
+
+// Create Product With Variants
+// =================================================================================================
+
+func TestVariantAPI_CreateProductWithVariants(t *testing.T) {
+	defer cleanData()
+
+	tee := createTee(t)
+
+	writer := makeRequest("GET", fmt.Sprintf("/api/v1/products/%s", tee.ID), nil, "")
+	var res dto.Product
+	parseResponseResult(writer.Body.Bytes(), &res)
+	assert.Equal(t, http.StatusOK, writer.Code)
+	assert.Equal(t, 1, len(res.Options))
+	assert.Equal(t, "M", res.Options[0].Values[1].Value)
+	assert.Equal(t, 2, len(res.Variants))
+	for _, variant := range res.Variants {
+		switch variant.SKU {
+		case "TEE-S":
+			assert.Equal(t, money.New(1200, "USD"), variant.Price)
+		case "TEE-M":
+			assert.Equal(t, money.New(1500, "USD"), variant.Price)
+		}
+	}
+}
+
+func TestVariantAPI_CreateVariantSKUTaken(t *testing.T) {
+	defer cleanData()
+
+	tee := createTee(t)
+
+	writer := makeRequest("POST", fmt.Sprintf("/api/v1/products/%s/options", tee.ID),
+		&dto.CreateOptionReq{Name: "Size", Values: []string{"L"}}, adminToken())
+	assert.Equal(t, http.StatusOK, writer.Code)
+
+	req := &dto.CreateVariantReq{SKU: "TEE-S", Options: map[string]string{"Size": "L"}}
+	writer = makeRequest("POST", fmt.Sprintf("/api/v1/products/%s/variants", tee.ID), req, adminToken())
+	assert.Equal(t, http.StatusBadRequest, writer.Code)
+}
+
+func TestVariantAPI_CreateVariantForbidden(t *testing.T) {
+	req := &dto.CreateVariantReq{SKU: "TEE-L", Options: map[string]string{"Size": "L"}}
+	writer := makeRequest("POST", "/api/v1/products/productId/variants", req, accessToken())
+	assert.Equal(t, http.StatusForbidden, writer.Code)
+}
+
+// Variant Stock
+// =================================================================================================
+
+func TestVariantAPI_ProductStockKeptByVariant(t *testing.T) {
+	defer cleanData()
+
+	tee := createTee(t)
+	req := &dto.AdjustStockReq{Quantity: 5, Reason: "restock"}
+
+	writer := makeRequest("PUT", fmt.Sprintf("/api/v1/products/%s/stock", tee.ID), req, adminToken())
+	assert.Equal(t, http.StatusBadRequest, writer.Code)
+
+	writer = makeRequest("PUT", fmt.Sprintf("/api/v1/products/%s/variants/%s/stock", tee.ID, tee.Variants[0].ID), req, adminToken())
+	var stock dto.ProductStock
+	parseResponseResult(writer.Body.Bytes(), &stock)
+	assert.Equal(t, http.StatusOK, writer.Code)
+	assert.Equal(t, int64(5), stock.Available)
+}
+
+// Place Order With Variants
+// =================================================================================================
+
+func TestVariantAPI_PlaceOrderForVariant(t *testing.T) {
+	defer cleanData()
+
+	tee := createTee(t)
+	var medium *dto.Variant
+	for _, variant := range tee.Variants {
+		if variant.SKU == "TEE-M" {
+			medium = variant
+		}
+	}
+	stockReq := &dto.AdjustStockReq{Quantity: 2, Reason: "restock"}
+	writer := makeRequest("PUT", fmt.Sprintf("/api/v1/products/%s/variants/%s/stock", tee.ID, medium.ID), stockReq, adminToken())
+	assert.Equal(t, http.StatusOK, writer.Code)
+
+	req := &orderDto.PlaceOrderReq{
+		Lines: []orderDto.PlaceOrderLineReq{{ProductID: tee.ID, VariantID: medium.ID, Quantity: 2}},
+	}
+	writer = makeRequest("POST", "/api/v1/orders", req, accessToken())
+	var order orderDto.Order
+	parseResponseResult(writer.Body.Bytes(), &order)
+	assert.Equal(t, http.StatusOK, writer.Code)
+	assert.Equal(t, money.New(3000, "USD"), order.TotalPrice)
+	assert.Equal(t, "TEE-M", order.Lines[0].Variant.SKU)
+
+	// Its stock is all reserved
+	writer = makeRequest("POST", "/api/v1/orders", req, accessToken())
+	assert.Equal(t, http.StatusBadRequest, writer.Code)
+}
+
+func TestVariantAPI_PlaceOrderWithoutVariant(t *testing.T) {
+	defer cleanData()
+
+	tee := createTee(t)
+
+	req := &orderDto.PlaceOrderReq{
+		Lines: []orderDto.PlaceOrderLineReq{{ProductID: tee.ID, Quantity: 1}},
+	}
+	writer := makeRequest("POST", "/api/v1/orders", req, accessToken())
+	assert.Equal(t, http.StatusBadRequest, writer.Code)
+}